@@ -0,0 +1,318 @@
+//go:build integration
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/events"
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/maltedev/amazon-size-scraper/internal/schema"
+	"github.com/maltedev/amazon-size-scraper/internal/scraperclient"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	e2eStream = "stream:product_lifecycle"
+	e2eGroup  = "lifecycle-consumer-group"
+)
+
+// productFixture stands in for the product table owned by the product lifecycle service,
+// which migration 004 alters
+const productFixture = `
+CREATE TABLE IF NOT EXISTS product (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	asin VARCHAR(20) UNIQUE NOT NULL,
+	title TEXT,
+	status VARCHAR(20),
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW()
+)`
+
+type e2eEnv struct {
+	db     *database.DB
+	pool   *pgxpool.Pool
+	redis  *redis.Client
+	logger *slog.Logger
+}
+
+func startPostgres(ctx context.Context, t *testing.T) (string, int) {
+	t.Helper()
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "postgres:16-alpine",
+			ExposedPorts: []string{"5432/tcp"},
+			Env: map[string]string{
+				"POSTGRES_USER":     "postgres",
+				"POSTGRES_PASSWORD": "postgres",
+				"POSTGRES_DB":       "tall_affiliate_test",
+			},
+			WaitingFor: wait.ForLog("database system is ready to accept connections").
+				WithOccurrence(2).
+				WithStartupTimeout(60 * time.Second),
+		},
+		Started: true,
+	})
+	testcontainers.CleanupContainer(t, container)
+	require.NoError(t, err)
+
+	host, err := container.Host(ctx)
+	require.NoError(t, err)
+	port, err := container.MappedPort(ctx, "5432/tcp")
+	require.NoError(t, err)
+
+	return host, port.Int()
+}
+
+func startRedis(ctx context.Context, t *testing.T) string {
+	t.Helper()
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "redis:7-alpine",
+			ExposedPorts: []string{"6379/tcp"},
+			WaitingFor:   wait.ForListeningPort("6379/tcp"),
+		},
+		Started: true,
+	})
+	testcontainers.CleanupContainer(t, container)
+	require.NoError(t, err)
+
+	endpoint, err := container.Endpoint(ctx, "")
+	require.NoError(t, err)
+
+	return endpoint
+}
+
+// applyMigrations runs all numbered up migrations in order
+func applyMigrations(ctx context.Context, t *testing.T, db *database.DB) {
+	t.Helper()
+
+	_, err := db.Exec(ctx, productFixture)
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join("..", "..", "migrations", "[0-9]*.up.sql"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	sort.Strings(files)
+
+	for _, file := range files {
+		sql, err := os.ReadFile(file)
+		require.NoError(t, err)
+		_, err = db.Exec(ctx, string(sql))
+		require.NoError(t, err, "migration %s", filepath.Base(file))
+	}
+}
+
+func setupE2E(t *testing.T) *e2eEnv {
+	t.Helper()
+	ctx := context.Background()
+
+	host, port := startPostgres(ctx, t)
+	redisAddr := startRedis(ctx, t)
+
+	db, err := database.New(ctx, database.Config{
+		Host:     host,
+		Port:     port,
+		User:     "postgres",
+		Password: "postgres",
+		Database: "tall_affiliate_test",
+		MaxConns: 5,
+		MinConns: 1,
+	})
+	require.NoError(t, err)
+	t.Cleanup(db.Close)
+
+	applyMigrations(ctx, t, db)
+
+	rdb := redis.NewClient(&redis.Options{Addr: redisAddr})
+	t.Cleanup(func() { rdb.Close() })
+	require.NoError(t, rdb.Ping(ctx).Err())
+
+	return &e2eEnv{
+		db:     db,
+		pool:   db.Pool(),
+		redis:  rdb,
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+}
+
+// stubScraper serves a fixed size chart, answering 503 while down is set
+func stubScraper(t *testing.T, down *atomic.Bool) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/scraper/size-chart" {
+			http.NotFound(w, r)
+			return
+		}
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		json.NewEncoder(w).Encode(SizeChartResponse{
+			SizeChartFound: true,
+			SizeTable: &SizeTableData{
+				Sizes: []string{"M", "L", "XL"},
+				Measurements: map[string]map[string]float64{
+					"M":  {"chest": 104, "length": 74},
+					"L":  {"chest": 110, "length": 76},
+					"XL": {"chest": 116, "length": 78},
+				},
+				Unit: "cm",
+			},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func (env *e2eEnv) start(t *testing.T, scraperURL string) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	relay := database.NewRelay(env.db, env.redis, env.logger, database.RelayConfig{
+		PollInterval:  100 * time.Millisecond,
+		BatchSize:     10,
+		SchemaVersion: schema.CurrentVersion,
+	})
+	go relay.Start(ctx)
+
+	clientCfg := scraperclient.DefaultConfig(scraperURL)
+	clientCfg.MaxAttempts = 1
+	clientCfg.FailureThreshold = 1
+	clientCfg.Cooldown = 500 * time.Millisecond
+
+	consumer := &Consumer{
+		redis:     env.redis,
+		db:        env.pool,
+		scraper:   scraperclient.New(clientCfg, env.logger),
+		parkDelay: 500 * time.Millisecond,
+		validator: database.DefaultSizeTableValidator(),
+		logger:    env.logger,
+	}
+	go consumer.Run(ctx)
+
+	t.Cleanup(cancel)
+}
+
+func (env *e2eEnv) publish(ctx context.Context, t *testing.T, asin string) {
+	t.Helper()
+
+	publisher := events.NewPublisher(env.db, env.logger)
+	require.NoError(t, publisher.PublishNewProductDetected(ctx, &events.NewProductDetectedPayload{
+		ASIN:          asin,
+		Title:         "Tall Fit T-Shirt",
+		Brand:         "TestBrand",
+		DetailPageURL: fmt.Sprintf("https://www.amazon.de/dp/%s", asin),
+	}))
+}
+
+func (env *e2eEnv) productStatus(ctx context.Context, asin string) string {
+	var status string
+	env.pool.QueryRow(ctx, "SELECT status FROM products WHERE asin = $1", asin).Scan(&status)
+	return status
+}
+
+func (env *e2eEnv) productCreatedPublished(ctx context.Context, asin string) bool {
+	messages, err := env.redis.XRange(ctx, e2eStream, "-", "+").Result()
+	if err != nil {
+		return false
+	}
+	for _, msg := range messages {
+		if msg.Values["event_type"] == schema.EventProductCreated && msg.Values["asin"] == asin {
+			return true
+		}
+	}
+	return false
+}
+
+func TestE2E_ProductDetectedToProductCreated(t *testing.T) {
+	env := setupE2E(t)
+	ctx := context.Background()
+
+	var down atomic.Bool
+	server := stubScraper(t, &down)
+	env.start(t, server.URL)
+
+	asin := "B0E2ETEST1"
+	env.publish(ctx, t, asin)
+
+	require.Eventually(t, func() bool {
+		return env.productStatus(ctx, asin) == "active"
+	}, 30*time.Second, 200*time.Millisecond, "product was not activated")
+
+	assert.Eventually(t, func() bool {
+		return env.productCreatedPublished(ctx, asin)
+	}, 10*time.Second, 200*time.Millisecond, "PRODUCT_CREATED not published")
+
+	// Outbox entry is marked processed by the relay
+	var outboxStatus string
+	require.NoError(t, env.pool.QueryRow(ctx,
+		"SELECT status FROM outbox_event WHERE aggregate_id = $1", asin).Scan(&outboxStatus))
+	assert.Equal(t, "processed", outboxStatus)
+
+	// Validation report and quality score are stored with the product
+	var score float64
+	require.NoError(t, env.pool.QueryRow(ctx,
+		"SELECT quality_score FROM products WHERE asin = $1", asin).Scan(&score))
+	assert.Equal(t, 1.0, score)
+
+	// Consumer acknowledged everything it read, including its own PRODUCT_CREATED
+	assert.Eventually(t, func() bool {
+		pending, err := env.redis.XPending(ctx, e2eStream, e2eGroup).Result()
+		return err == nil && pending.Count == 0
+	}, 10*time.Second, 200*time.Millisecond, "messages left pending")
+}
+
+func TestE2E_ParksEventsWhileScraperDown(t *testing.T) {
+	env := setupE2E(t)
+	ctx := context.Background()
+
+	var down atomic.Bool
+	down.Store(true)
+	server := stubScraper(t, &down)
+	env.start(t, server.URL)
+
+	asin := "B0E2ETEST2"
+	env.publish(ctx, t, asin)
+
+	// The event is read but stays pending instead of being dropped
+	require.Eventually(t, func() bool {
+		pending, err := env.redis.XPending(ctx, e2eStream, e2eGroup).Result()
+		return err == nil && pending.Count == 1
+	}, 30*time.Second, 200*time.Millisecond, "event was not parked")
+	assert.Equal(t, "pending", env.productStatus(ctx, asin))
+
+	// Once the scraper is back the parked event is replayed
+	down.Store(false)
+
+	require.Eventually(t, func() bool {
+		return env.productStatus(ctx, asin) == "active"
+	}, 30*time.Second, 200*time.Millisecond, "parked event was not replayed")
+
+	assert.Eventually(t, func() bool {
+		pending, err := env.redis.XPending(ctx, e2eStream, e2eGroup).Result()
+		return err == nil && pending.Count == 0
+	}, 10*time.Second, 200*time.Millisecond, "parked event was not acknowledged")
+}
@@ -1,116 +1,132 @@
 package main
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"log/slog"
-	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/maltedev/amazon-size-scraper/internal/lifecycle"
+	"github.com/maltedev/amazon-size-scraper/internal/redisconn"
+	"github.com/maltedev/amazon-size-scraper/internal/schema"
+	"github.com/maltedev/amazon-size-scraper/internal/scrapeerr"
+	"github.com/maltedev/amazon-size-scraper/internal/scraperclient"
+	"github.com/maltedev/amazon-size-scraper/internal/subscription"
+	logging "github.com/maltedev/amazon-size-scraper/pkg/logger"
 	"github.com/redis/go-redis/v9"
 )
 
-// Event struct matches tall-affiliate-common Event structure
-type Event struct {
-	ID            string         `json:"id"`
-	Type          string         `json:"type"`
-	AggregateType string         `json:"aggregate_type"`
-	AggregateID   string         `json:"aggregate_id"`
-	Payload       any            `json:"payload"`
-	Timestamp     time.Time      `json:"timestamp"`
-	Metadata      map[string]any `json:"metadata,omitempty"`
-}
-
-// ProductCreatedPayload matches tall-affiliate-common ProductCreatedPayload
-type ProductCreatedPayload struct {
-	ASIN           string   `json:"asin"`
-	Title          string   `json:"title"`
-	Brand          string   `json:"brand,omitempty"`
-	Category       string   `json:"category,omitempty"`
-	Gender         string   `json:"gender,omitempty"`
-	CurrentPrice   float64  `json:"current_price,omitempty"`
-	Currency       string   `json:"currency,omitempty"`
-	DetailPageURL  string   `json:"detail_page_url,omitempty"`
-	ImageUrls      []string `json:"image_urls,omitempty"`
-	Features       []string `json:"features,omitempty"`
-	BrowseNodeID   string   `json:"browse_node_id,omitempty"`
-	BrowseNodeTags []string `json:"browse_node_tags,omitempty"`
-}
-
-// Event constants from tall-affiliate-common
-const (
-	EVENT_01_PRODUCT_DETECTED     = "01_PRODUCT_DETECTED"
-	EVENT_NEW_PRODUCT_DETECTED    = "NEW_PRODUCT_DETECTED"
-	EVENT_02A_PRODUCT_VALIDATED   = "02A_PRODUCT_VALIDATED"
-)
-
-// ParsePayload matches tall-affiliate-common helper function
-func ParsePayload(payload interface{}, target interface{}) error {
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("failed to marshal payload: %w", err)
-	}
-	if err := json.Unmarshal(jsonData, target); err != nil {
-		return fmt.Errorf("failed to unmarshal payload: %w", err)
-	}
-	return nil
-}
-
 func main() {
-	// Setup logger
-	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
-	}))
+	// Setup logger, records carry the trace ID and ASIN of the message being processed
+	logger := logging.New(getEnv("LOG_LEVEL", "info"), getEnv("LOG_FORMAT", "json"))
 	slog.SetDefault(logger)
 
-	// Redis connection
-	redisAddr := os.Getenv("REDIS_ADDR")
-	if redisAddr == "" {
-		redisAddr = "localhost:6379"
+	// Redis connection, standalone, Sentinel or Cluster depending on REDIS_MODE
+	redisCfg := redisconn.FromEnv()
+	rdb, err := redisconn.New(redisCfg)
+	if err != nil {
+		log.Fatalf("Invalid Redis config: %v", err)
 	}
 
-	rdb := redis.NewClient(&redis.Options{
-		Addr: redisAddr,
-	})
-
 	// Test Redis connection
 	ctx := context.Background()
 	if err := rdb.Ping(ctx).Err(); err != nil {
 		log.Fatalf("Failed to connect to Redis: %v", err)
 	}
-	logger.Info("Connected to Redis", "addr", redisAddr)
-
-	// Database connection
-	dbURL := fmt.Sprintf("postgres://postgres:%s@localhost:%s/tall_affiliate?sslmode=disable",
-		getEnv("DB_PASSWORD", "postgres"),
-		getEnv("DB_PORT", "5433"),
-	)
-	
-	db, err := pgxpool.New(ctx, dbURL)
+	logger.InfoContext(ctx, "Connected to Redis", "redis", redisCfg.String())
+
+	// Database connection, retried with backoff so a restarting database does not stop the consumer
+	store, err := database.New(ctx, database.Config{
+		Host:     getEnv("DB_HOST", "localhost"),
+		Port:     int(getEnvInt64("DB_PORT", 5433)),
+		User:     getEnv("DB_USER", "postgres"),
+		Password: getEnv("DB_PASSWORD", "postgres"),
+		Database: getEnv("DB_NAME", "tall_affiliate"),
+		MaxConns: int32(getEnvInt64("DB_MAX_CONNS", 4)),
+
+		StatementTimeout:  getEnvDuration("DB_STATEMENT_TIMEOUT", time.Minute),
+		HealthCheckPeriod: getEnvDuration("DB_HEALTH_CHECK_PERIOD", 30*time.Second),
+		ConnectRetries:    int(getEnvInt64("DB_CONNECT_RETRIES", 5)),
+		ConnectBackoff:    getEnvDuration("DB_CONNECT_BACKOFF", time.Second),
+	})
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
-	defer db.Close()
-	
-	if err := db.Ping(ctx); err != nil {
-		log.Fatalf("Failed to ping database: %v", err)
+	defer store.Close()
+	db := store.Pool()
+	logger.InfoContext(ctx, "Connected to database")
+
+	// Scraper client with retries and circuit breaker
+	clientCfg := scraperclient.DefaultConfig(getEnv("SCRAPER_URL", "http://localhost:8084"))
+	clientCfg.Timeout = getEnvDuration("SCRAPER_TIMEOUT", clientCfg.Timeout)
+	clientCfg.MaxAttempts = int(getEnvInt64("SCRAPER_MAX_ATTEMPTS", int64(clientCfg.MaxAttempts)))
+	clientCfg.Backoff = getEnvDuration("SCRAPER_BACKOFF", clientCfg.Backoff)
+	clientCfg.MaxBackoff = getEnvDuration("SCRAPER_MAX_BACKOFF", clientCfg.MaxBackoff)
+	clientCfg.Jitter = getEnvFloat("SCRAPER_JITTER", clientCfg.Jitter)
+	clientCfg.FailureThreshold = int(getEnvInt64("SCRAPER_BREAKER_THRESHOLD", int64(clientCfg.FailureThreshold)))
+	clientCfg.Cooldown = getEnvDuration("SCRAPER_BREAKER_COOLDOWN", clientCfg.Cooldown)
+	clientCfg.MaxRetryAfter = getEnvDuration("SCRAPER_MAX_RETRY_AFTER", clientCfg.MaxRetryAfter)
+	if clientCfg.RetryStatuses, err = getEnvInts("SCRAPER_RETRY_STATUSES"); err != nil {
+		log.Fatalf("Invalid SCRAPER_RETRY_STATUSES: %v", err)
+	}
+
+	// Which size tables keep a product, only a length is required by default
+	policy := database.MeasurementPolicy{
+		RequireLength: getEnvBool("MEASUREMENT_REQUIRE_LENGTH", true),
+		RequireChest:  getEnvBool("MEASUREMENT_REQUIRE_CHEST", false),
+		AcceptPartial: getEnvBool("MEASUREMENT_ACCEPT_PARTIAL", false),
 	}
-	logger.Info("Connected to database")
 
 	// Create consumer
 	consumer := &Consumer{
-		redis:      rdb,
-		db:         db,
-		httpClient: &http.Client{Timeout: 30 * time.Second},
-		scraperURL: getEnv("SCRAPER_URL", "http://localhost:8084"),
-		logger:     logger,
+		redis:     rdb,
+		db:        db,
+		scraper:   scraperclient.New(clientCfg, logger),
+		parkDelay: getEnvDuration("SCRAPER_PARK_DELAY", 5*time.Second),
+		maxLen:    getEnvInt64("REDIS_STREAM_MAXLEN", 100000),
+		workers:   int(getEnvInt64("CONSUMER_WORKERS", 4)),
+		batchSize: getEnvInt64("CONSUMER_BATCH_SIZE", 10),
+		validator: database.DefaultSizeTableValidator(),
+		policy:    &policy,
+		logger:    logger,
+	}
+
+	// Subscriptions route events to handlers, the default extracts sizes of detected and validated products
+	subs := defaultSubscriptions()
+	if path := getEnv("CONSUMER_SUBSCRIPTIONS_FILE", ""); path != "" {
+		if subs, err = subscription.Load(path); err != nil {
+			log.Fatalf("Failed to load subscriptions: %v", err)
+		}
+	}
+	if consumer.subs, err = consumer.newDispatcher(subs); err != nil {
+		log.Fatalf("Invalid subscriptions: %v", err)
+	}
+	logger.InfoContext(ctx, "Loaded subscriptions", "count", len(subs))
+
+	// Status transitions, the default moves pending products to active or rejected
+	transitions := lifecycle.DefaultTransitions()
+	if path := getEnv("CONSUMER_TRANSITIONS_FILE", ""); path != "" {
+		if transitions, err = lifecycle.Load(path); err != nil {
+			log.Fatalf("Failed to load transitions: %v", err)
+		}
+	}
+	if consumer.states, err = lifecycle.New(transitions); err != nil {
+		log.Fatalf("Invalid transitions: %v", err)
+	}
+	if err := consumer.states.Validate(schema.NewRegistry().Known); err != nil {
+		log.Fatalf("Invalid transitions: %v", err)
 	}
 
 	// Setup graceful shutdown
@@ -121,7 +137,7 @@ func main() {
 	ctx, cancel := context.WithCancel(ctx)
 	go func() {
 		<-sigChan
-		logger.Info("Shutting down...")
+		logger.InfoContext(ctx, "Shutting down...")
 		cancel()
 	}()
 
@@ -132,11 +148,40 @@ func main() {
 }
 
 type Consumer struct {
-	redis      *redis.Client
-	db         *pgxpool.Pool
-	httpClient *http.Client
-	scraperURL string
-	logger     *slog.Logger
+	redis     redis.UniversalClient
+	db        *pgxpool.Pool
+	scraper   *scraperclient.Client
+	parkDelay time.Duration // Minimum wait before replaying parked messages
+	maxLen    int64         // Approximate MAXLEN for published streams, 0 disables trimming
+	workers   int           // Messages processed concurrently, per ASIN in stream order
+	batchSize int64         // Messages read per XREADGROUP, below 1 reads one
+	validator *database.SizeTableValidator
+	policy    *database.MeasurementPolicy // Size tables that keep a product, nil uses database.LengthMeasurementPolicy
+	subs      *subscription.Dispatcher    // nil uses defaultSubscriptions
+	states    *lifecycle.Machine          // Status transitions, nil uses lifecycle.Default
+	logger    *slog.Logger
+}
+
+// defaultSubscriptions hands detected and validated products to the size extraction
+func defaultSubscriptions() []subscription.Subscription {
+	return []subscription.Subscription{{
+		Name:       "size-extraction",
+		EventTypes: []string{schema.EventProductValidated, schema.EventProductDetected, schema.EventNewProductDetected},
+		Handler:    "extract_sizes",
+	}}
+}
+
+// newDispatcher registers the consumer's handlers for subs and checks them against the event registry
+func (c *Consumer) newDispatcher(subs []subscription.Subscription) (*subscription.Dispatcher, error) {
+	d, err := subscription.NewDispatcher(subs)
+	if err != nil {
+		return nil, err
+	}
+	d.Register("extract_sizes", c.extractSizes)
+	if err := d.Validate(schema.NewRegistry().Known); err != nil {
+		return nil, err
+	}
+	return d, nil
 }
 
 func getEnv(key, defaultValue string) string {
@@ -146,28 +191,103 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getEnvInt64(key string, defaultValue int64) int64 {
+	if value, err := strconv.ParseInt(os.Getenv(key), 10, 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvInts parses a comma separated list of integers, nil if the variable is empty
+func getEnvInts(key string) ([]int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil, nil
+	}
+	var ints []int
+	for _, field := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", field)
+		}
+		ints = append(ints, n)
+	}
+	return ints, nil
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
 func (c *Consumer) Run(ctx context.Context) error {
 	// Check for stream override from environment
 	streamKey := getEnv("REDIS_STREAM", "stream:product_lifecycle")
 	consumerGroup := "lifecycle-consumer-group"
 	consumerName := "consumer-1"
 
+	if c.subs == nil {
+		subs, err := c.newDispatcher(defaultSubscriptions())
+		if err != nil {
+			return err
+		}
+		c.subs = subs
+	}
+	if c.states == nil {
+		c.states = lifecycle.Default()
+	}
+	if c.policy == nil {
+		policy := database.LengthMeasurementPolicy()
+		c.policy = &policy
+	}
+
 	// Create consumer group (ignore error if already exists)
 	c.redis.XGroupCreate(ctx, streamKey, consumerGroup, "0").Err()
 
-	c.logger.Info("Starting consumer", "stream", streamKey, "group", consumerGroup)
+	batchSize := c.batchSize
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	c.logger.InfoContext(ctx, "Starting consumer", "stream", streamKey, "group", consumerGroup,
+		"workers", max(c.workers, 1), "batch_size", batchSize)
+
+	// Replay messages left pending by a previous run or parked while the scraper was down
+	replaying := true
+	pendingCursor := "0"
 
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 		default:
+			readID := ">"
+			if replaying {
+				readID = pendingCursor
+			}
+
 			// Read from stream
 			streams, err := c.redis.XReadGroup(ctx, &redis.XReadGroupArgs{
 				Group:    consumerGroup,
 				Consumer: consumerName,
-				Streams:  []string{streamKey, ">"},
-				Count:    1,
+				Streams:  []string{streamKey, readID},
+				Count:    batchSize,
 				Block:    5 * time.Second,
 				NoAck:    false, // Auto-acknowledge for testing
 			}).Result()
@@ -176,23 +296,43 @@ func (c *Consumer) Run(ctx context.Context) error {
 				if err == redis.Nil {
 					continue // No new messages
 				}
-				c.logger.Error("Failed to read from stream", "error", err)
+				c.logger.ErrorContext(ctx, "Failed to read from stream", "error", err)
 				time.Sleep(1 * time.Second)
 				continue
 			}
 
-			// Process messages
+			if replaying && (len(streams) == 0 || len(streams[0].Messages) == 0) {
+				replaying = false
+				pendingCursor = "0"
+				continue
+			}
+
+			// Process messages, the batch is finished before the next read so per-ASIN order holds across batches
+			parked := false
 			for _, stream := range streams {
-				for _, message := range stream.Messages {
-					if err := c.processMessage(ctx, message); err != nil {
-						c.logger.Error("Failed to process message", "id", message.ID, "error", err)
-						continue
-					}
-
-					// Acknowledge message
-					if err := c.redis.XAck(ctx, streamKey, consumerGroup, message.ID).Err(); err != nil {
-						c.logger.Error("Failed to acknowledge message", "id", message.ID, "error", err)
-					}
+				if len(stream.Messages) == 0 {
+					continue
+				}
+				if replaying {
+					pendingCursor = stream.Messages[len(stream.Messages)-1].ID
+				}
+				if c.processBatch(ctx, streamKey, consumerGroup, stream.Messages) {
+					parked = true
+				}
+			}
+
+			if parked {
+				replaying = true
+				pendingCursor = "0"
+
+				wait := c.scraper.Breaker().RetryAfter()
+				if wait < c.parkDelay {
+					wait = c.parkDelay
+				}
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(wait):
 				}
 			}
 		}
@@ -201,7 +341,7 @@ func (c *Consumer) Run(ctx context.Context) error {
 
 func (c *Consumer) processMessage(ctx context.Context, msg redis.XMessage) error {
 	// DEBUG: Log full message structure
-	c.logger.Info("DEBUG: Processing message",
+	c.logger.InfoContext(ctx, "DEBUG: Processing message",
 		"message_id", msg.ID,
 		"keys", func() []string {
 			keys := make([]string, 0, len(msg.Values))
@@ -215,7 +355,7 @@ func (c *Consumer) processMessage(ctx context.Context, msg redis.XMessage) error
 	)
 
 	// DEBUG: Log specific fields we care about
-	c.logger.Info("DEBUG: Event type detection",
+	c.logger.InfoContext(ctx, "DEBUG: Event type detection",
 		"has_event_type", msg.Values["event_type"] != nil,
 		"event_type", fmt.Sprintf("%v", msg.Values["event_type"]),
 		"has_type_field", msg.Values["type"] != nil,
@@ -224,127 +364,77 @@ func (c *Consumer) processMessage(ctx context.Context, msg redis.XMessage) error
 		"expected_event_2", "NEW_PRODUCT_DETECTED",
 	)
 
-	// Parse the event using tall-affiliate-common structure
-	var event Event
-
-	// Method 1: Try to extract from "data" field (Relay format)
-	if dataStr, hasData := msg.Values["data"].(string); hasData {
-		if err := json.Unmarshal([]byte(dataStr), &event); err == nil {
-			c.logger.Info("DEBUG: Parsed event from data field",
-				"type", event.Type,
-				"aggregate_id", event.AggregateID,
-			)
-		} else {
-			c.logger.Info("DEBUG: Failed to parse data field", "error", err)
-		}
+	// Decode the event, accepting both v1 (flat) and v2 (data envelope) stream layouts
+	event, err := schema.DecodeStreamMessage(msg.Values)
+	if err != nil {
+		return fmt.Errorf("failed to decode stream message: %w", err)
 	}
+	if traceID := event.TraceID(); traceID != "" {
+		ctx = logging.WithTraceID(ctx, traceID)
+	}
+	ctx = logging.EnsureTraceID(ctx)
 
-	// Method 2: Fallback to direct field extraction
-	if event.Type == "" {
-		// Try event_type field first (from Relay)
-		event.Type, _ = msg.Values["event_type"].(string)
-		if event.Type == "" {
-			// Try type field
-			event.Type, _ = msg.Values["type"].(string)
-		}
-
-		event.AggregateID, _ = msg.Values["aggregate_id"].(string)
-		event.AggregateType, _ = msg.Values["aggregate_type"].(string)
-		event.Payload = msg.Values["payload"]
-
-		// Parse timestamp if available
-		if timestampStr, ok := msg.Values["timestamp"].(string); ok {
-			if timestamp, err := time.Parse(time.RFC3339, timestampStr); err == nil {
-				event.Timestamp = timestamp
-			}
-		}
+	c.logger.InfoContext(ctx, "Processing event",
+		"event_type", event.Type,
+		"message_id", msg.ID,
+		"schema_version", event.SchemaVersion,
+		"content_encoding", msg.Values[schema.MetadataContentEncoding],
+	)
 
-		c.logger.Info("DEBUG: Used fallback field extraction",
-			"type", event.Type,
+	handled, err := c.subs.Dispatch(ctx, event)
+	if handled == 0 {
+		c.logger.InfoContext(ctx, "Skipping event without subscription",
+			"event_type", event.Type,
+			"aggregate_type", event.AggregateType,
 			"aggregate_id", event.AggregateID,
 		)
 	}
+	return err
+}
 
-	// Check if this is a product event we should process
-	if event.Type != EVENT_02A_PRODUCT_VALIDATED &&
-	   event.Type != EVENT_01_PRODUCT_DETECTED &&
-	   event.Type != EVENT_NEW_PRODUCT_DETECTED {
-		c.logger.Info("Skipping non-product event",
-			"event_type", event.Type,
+// extractSizes creates a pending product, extracts its size chart and publishes PRODUCT_CREATED once a
+// length was found
+func (c *Consumer) extractSizes(ctx context.Context, event *schema.Event) error {
+	// Parse payload to get product details
+	productPayload, err := schema.DecodeProductPayload(event.SchemaVersion, event.Payload)
+	if err != nil {
+		c.logger.WarnContext(ctx, "Failed to parse product payload, proceeding with ASIN only",
 			"aggregate_id", event.AggregateID,
+			"error", err,
 		)
-		return nil
+		productPayload = &schema.ProductPayload{}
 	}
 
-	c.logger.Info("Processing valid event",
-		"event_type", event.Type,
-		"message_id", msg.ID,
-	)
-
-	// DEBUG: Check all possible ASIN sources
-	c.logger.Info("DEBUG: ASIN extraction",
-		"has_aggregate_id", event.AggregateID != "",
-		"aggregate_id", event.AggregateID,
-		"payload_type", fmt.Sprintf("%T", event.Payload),
-	)
-
 	// Get ASIN from aggregate_id (standard tall-affiliate-common pattern)
 	asin := event.AggregateID
 	if asin == "" {
-		// Fallback: try to parse ASIN from payload
-		var payload ProductCreatedPayload
-		if err := ParsePayload(event.Payload, &payload); err == nil {
-			c.logger.Info("DEBUG: Parsed payload", "asin", payload.ASIN, "title", payload.Title)
-			asin = payload.ASIN
-		} else {
-			c.logger.Info("DEBUG: Failed to parse payload", "error", err)
-		}
+		asin = productPayload.ASIN
 	}
-
 	if asin == "" {
-		c.logger.Error("DEBUG: ASIN extraction failed",
-			"event_type", event.Type,
-			"aggregate_id", event.AggregateID,
-			"payload_keys", func() []string {
-				if payloadMap, ok := event.Payload.(map[string]interface{}); ok {
-					keys := make([]string, 0, len(payloadMap))
-					for k := range payloadMap {
-						keys = append(keys, k)
-					}
-					return keys
-				}
-				return []string{fmt.Sprintf("%T", event.Payload)}
-			}(),
-		)
 		return fmt.Errorf("missing ASIN in payload/event")
 	}
+	ctx = logging.WithASIN(ctx, asin)
 
-	c.logger.Info("Processing validated product",
-		"message_id", msg.ID,
+	// Use minimal info if the payload did not carry product details
+	if productPayload.ASIN == "" {
+		productPayload.ASIN = asin
+	}
+	if productPayload.Title == "" {
+		productPayload.Title = "Unknown Product"
+	}
+
+	c.logger.InfoContext(ctx, "Processing validated product",
 		"event_type", event.Type,
 		"asin", asin,
 		"aggregate_type", event.AggregateType,
 	)
 
-	// Parse payload to get product details
-	var productPayload ProductCreatedPayload
-	if err := ParsePayload(event.Payload, &productPayload); err != nil {
-		c.logger.Warn("Failed to parse product payload, proceeding with ASIN only",
-			"asin", asin,
-			"error", err,
-		)
-		// Use minimal info if payload parsing fails
-		productPayload = ProductCreatedPayload{
-			ASIN:          asin,
-			Title:         "Unknown Product",
-			DetailPageURL: fmt.Sprintf("https://www.amazon.de/dp/%s", asin),
-		}
-	}
-
 	// Check if product exists and is still pending
-	var status string
-	var dbErr error
-	dbErr = c.db.QueryRow(ctx, "SELECT status FROM products WHERE asin = $1", asin).Scan(&status)
+	var status database.ProductStatus
+	dbErr := c.db.QueryRow(ctx, "SELECT status FROM products WHERE asin = $1", asin).Scan(&status)
+	if dbErr != nil && !errors.Is(dbErr, pgx.ErrNoRows) {
+		return fmt.Errorf("failed to get product status: %w", dbErr)
+	}
 	if dbErr != nil {
 		// Product doesn't exist, create it
 		url := productPayload.DetailPageURL
@@ -353,24 +443,25 @@ func (c *Consumer) processMessage(ctx context.Context, msg redis.XMessage) error
 		}
 
 		insertQuery := `INSERT INTO products (asin, title, url, brand, status)
-		                VALUES ($1, $2, $3, $4, 'pending')
+		                VALUES ($1, $2, $3, $4, $5)
 		                ON CONFLICT (asin) DO NOTHING`
 		_, insertErr := c.db.Exec(ctx, insertQuery,
 			productPayload.ASIN,
 			productPayload.Title,
 			url,
 			productPayload.Brand,
+			database.StatusPending,
 		)
 		if insertErr != nil {
-			c.logger.Error("Failed to insert product", "asin", asin, "error", insertErr)
+			c.logger.ErrorContext(ctx, "Failed to insert product", "asin", asin, "error", insertErr)
 			return nil
 		}
-		c.logger.Info("Created new product", "asin", asin, "title", productPayload.Title)
-		status = "pending"
+		c.logger.InfoContext(ctx, "Created new product", "asin", asin, "title", productPayload.Title)
+		status = database.StatusPending
 	}
 
-	if status != "pending" {
-		c.logger.Info("Skipping non-pending product", "asin", asin, "status", status)
+	if !c.states.Can(status, lifecycle.SizeChartFound) && !c.states.Can(status, lifecycle.SizeChartMissing) {
+		c.logger.InfoContext(ctx, "Skipping product, its status takes no size chart", "asin", asin, "status", status)
 		return nil
 	}
 
@@ -380,26 +471,27 @@ func (c *Consumer) processMessage(ctx context.Context, msg redis.XMessage) error
 		return fmt.Errorf("failed to extract size data: %w", err)
 	}
 
-	// Update database based on dimensions
-	if err := c.updateProduct(ctx, asin, dimensions); err != nil {
-		return fmt.Errorf("failed to update product: %w", err)
+	// Only a page without size chart makes the chart missing, a blocked or failed page is retried later
+	// and a deleted listing has nothing to extract
+	scrapeErr := scrapeerr.FromCode(dimensions.ErrorCode, dimensions.Error)
+	switch {
+	case errors.Is(scrapeErr, scrapeerr.ErrBlocked), errors.Is(scrapeErr, scrapeerr.ErrNavigation):
+		return fmt.Errorf("failed to extract size data: %w", scrapeErr)
+	case errors.Is(scrapeErr, scrapeerr.ErrProductNotFound):
+		c.logger.WarnContext(ctx, "Skipping product, listing gone on Amazon", "asin", asin, "error", scrapeErr)
+		return nil
 	}
 
-	// Check if any size has length measurement
-	hasLength := false
-	if dimensions.SizeTable != nil {
-		for _, measurements := range dimensions.SizeTable.Measurements {
-			if length, ok := measurements["length"]; ok && length > 0 {
-				hasLength = true
-				break
-			}
-		}
+	// Update database based on dimensions
+	transition, err := c.updateProduct(ctx, asin, status, dimensions)
+	if err != nil {
+		return fmt.Errorf("failed to update product: %w", err)
 	}
 
-	// Publish PRODUCT_CREATED if has length
-	if hasLength {
+	// Publish PRODUCT_CREATED if the transition emits it, by default once a length was found
+	if slices.Contains(transition.Emits, schema.EventProductCreated) {
 		if err := c.publishProductCreated(ctx, asin, dimensions); err != nil {
-			c.logger.Error("Failed to publish PRODUCT_CREATED", "asin", asin, "error", err)
+			c.logger.ErrorContext(ctx, "Failed to publish PRODUCT_CREATED", "asin", asin, "error", err)
 		}
 	}
 
@@ -410,6 +502,15 @@ func (c *Consumer) processMessage(ctx context.Context, msg redis.XMessage) error
 type SizeChartResponse struct {
 	SizeChartFound bool           `json:"size_chart_found"`
 	SizeTable      *SizeTableData `json:"size_table,omitempty"`
+	Diagnostics    *Diagnostics   `json:"diagnostics,omitempty"`
+	Error          string         `json:"error,omitempty"`
+	ErrorCode      string         `json:"error_code,omitempty"` // One of the scrapeerr codes
+}
+
+// Diagnostics references the screenshot and DOM snippet captured by the scraper on failure
+type Diagnostics struct {
+	ScreenshotPath string `json:"screenshot_path,omitempty"`
+	DOMSnippetPath string `json:"dom_snippet_path,omitempty"`
 }
 
 // SizeTableData represents the complete size table
@@ -420,47 +521,13 @@ type SizeTableData struct {
 }
 
 func (c *Consumer) extractSizeData(ctx context.Context, asin string) (*SizeChartResponse, error) {
-	url := fmt.Sprintf("%s/api/v1/scraper/size-chart", c.scraperURL)
-	
-	reqBody := map[string]string{"asin": asin}
-	jsonData, _ := json.Marshal(reqBody)
-	
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Content-Type", "application/json")
-	
-	// Retry logic
-	var resp *http.Response
-	for attempts := 0; attempts < 3; attempts++ {
-		resp, err = c.httpClient.Do(req)
-		if err == nil && resp.StatusCode == 200 {
-			break
-		}
-		if resp != nil {
-			resp.Body.Close()
-		}
-		if attempts < 2 {
-			time.Sleep(time.Duration(attempts+1) * time.Second)
-		}
-	}
-	
-	if err != nil {
-		return nil, fmt.Errorf("HTTP request failed: %w", err)
-	}
-	defer resp.Body.Close()
-	
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
-	}
-	
+	// The client builds a fresh request per attempt and fails fast while the circuit is open
 	var dimensions SizeChartResponse
-	if err := json.NewDecoder(resp.Body).Decode(&dimensions); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := c.scraper.PostJSON(ctx, "/api/v1/scraper/size-chart", map[string]string{"asin": asin}, &dimensions); err != nil {
+		return nil, fmt.Errorf("size chart request failed: %w", err)
 	}
 	
-	c.logger.Info("Extracted dimensions", 
+	c.logger.InfoContext(ctx, "Extracted dimensions", 
 		"asin", asin,
 		"found", dimensions.SizeChartFound,
 		"hasSizeTable", dimensions.SizeTable != nil,
@@ -475,24 +542,18 @@ func (c *Consumer) extractSizeData(ctx context.Context, asin string) (*SizeChart
 	return &dimensions, nil
 }
 
-func (c *Consumer) updateProduct(ctx context.Context, asin string, dimensions *SizeChartResponse) error {
-	var status string
-	hasLength := false
+// updateProduct stores the size chart and moves the product from status along the state machine
+func (c *Consumer) updateProduct(ctx context.Context, asin string, from database.ProductStatus, dimensions *SizeChartResponse) (lifecycle.Transition, error) {
+	// The product is kept if its size table has the measurements the policy requires
+	st := responseSizeTable(dimensions)
+	accepted := c.policy.Accepts(st)
+	hasLength := database.LengthMeasurementPolicy().Satisfied(st)
 	
-	// Check if any size has length measurement
-	if dimensions.SizeTable != nil {
-		for _, measurements := range dimensions.SizeTable.Measurements {
-			if length, ok := measurements["length"]; ok && length > 0 {
-				hasLength = true
-				break
-			}
-		}
-	}
-	
-	if hasLength {
-		status = "active"
-	} else {
-		status = "rejected"
+	trigger := lifecycle.SizeChartMissing
+	var policyJSON []byte
+	if accepted {
+		trigger = lifecycle.SizeChartFound
+		policyJSON = c.policy.Apply(st).JSON()
 	}
 	
 	// Convert SizeTableData to database.SizeTable if available
@@ -506,25 +567,79 @@ func (c *Consumer) updateProduct(ctx context.Context, asin string, dimensions *S
 		var err error
 		sizeTableJSON, err = json.Marshal(sizeTable)
 		if err != nil {
-			return fmt.Errorf("failed to marshal size table: %w", err)
+			return lifecycle.Transition{}, fmt.Errorf("failed to marshal size table: %w", err)
 		}
 	}
 	
+	report := c.validate(st)
+	reportJSON, err := json.Marshal(report)
+	if err != nil {
+		return lifecycle.Transition{}, fmt.Errorf("failed to marshal validation report: %w", err)
+	}
+
+	// Keep failure diagnostics with rejected products so they can be inspected later
+	var errorMsg, screenshot, domSnippet string
+	if !accepted {
+		errorMsg = dimensions.Error
+		if errorMsg == "" {
+			errorMsg = "Size table missing required measurements"
+		}
+		if dimensions.Diagnostics != nil {
+			screenshot = dimensions.Diagnostics.ScreenshotPath
+			domSnippet = dimensions.Diagnostics.DOMSnippetPath
+		}
+	}
+
+	transition, err := c.states.Fire(from, trigger, lifecycle.Facts{HasLength: hasLength, Accepted: accepted, Reason: errorMsg})
+	if err != nil {
+		return lifecycle.Transition{}, err
+	}
+
 	query := `
 		UPDATE products 
 		SET size_table = $2,
 		    status = $3,
+		    error_message = NULLIF($4, ''),
+		    error_screenshot = NULLIF($5, ''),
+		    error_dom_snippet = NULLIF($6, ''),
+		    validation_report = $7,
+		    quality_score = $8,
+		    measurement_policy = $10,
 		    scraped_at = CURRENT_TIMESTAMP,
 		    updated_at = CURRENT_TIMESTAMP
-		WHERE asin = $1`
+		WHERE asin = $1 AND status = $9`
 	
-	_, err := c.db.Exec(ctx, query, asin, sizeTableJSON, status)
+	tag, err := c.db.Exec(ctx, query, asin, sizeTableJSON, transition.To, errorMsg, screenshot, domSnippet, reportJSON, report.Score, from, policyJSON)
 	if err != nil {
-		return fmt.Errorf("failed to update product: %w", err)
+		return lifecycle.Transition{}, fmt.Errorf("failed to update product: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return lifecycle.Transition{}, fmt.Errorf("%w: status of %s changed from %q", lifecycle.ErrIllegalTransition, asin, from)
 	}
 	
-	c.logger.Info("Updated product", "asin", asin, "status", status, "hasSizeTable", dimensions.SizeTable != nil, "hasLength", hasLength)
-	return nil
+	c.logger.InfoContext(ctx, "Updated product", "asin", asin, "status", transition.To, "hasSizeTable", dimensions.SizeTable != nil, "hasLength", hasLength, "accepted", accepted)
+	return transition, nil
+}
+
+// validate runs the size table validation rules against a size table, requiring the measurements of the policy
+func (c *Consumer) validate(st *database.SizeTable) *database.ValidationReport {
+	policy := database.LengthMeasurementPolicy()
+	if c.policy != nil {
+		policy = *c.policy
+	}
+	return c.validator.WithPolicy(policy).Validate(st)
+}
+
+// responseSizeTable returns the size table of the scraper response, nil if it has none
+func responseSizeTable(dimensions *SizeChartResponse) *database.SizeTable {
+	if dimensions.SizeTable == nil {
+		return nil
+	}
+	return &database.SizeTable{
+		Sizes:        dimensions.SizeTable.Sizes,
+		Measurements: dimensions.SizeTable.Measurements,
+		Unit:         dimensions.SizeTable.Unit,
+	}
 }
 
 func (c *Consumer) publishProductCreated(ctx context.Context, asin string, dimensions *SizeChartResponse) error {
@@ -542,12 +657,12 @@ func (c *Consumer) publishProductCreated(ctx context.Context, asin string, dimen
 	// Create event payload
 	eventPayload := map[string]interface{}{
 		"event_id":    fmt.Sprintf("%d", time.Now().UnixNano()),
-		"event_type":  "PRODUCT_CREATED",
+		"event_type":  schema.EventProductCreated,
 		"timestamp":   time.Now().Format(time.RFC3339),
 		"asin":        asin,
 		"title":       title,
 		"url":         url,
-		"quality_score": 3.0, // Simple score if has length
+		"quality_score": c.validate(responseSizeTable(dimensions)).Score * 5, // Validation score scaled to 0-5
 	}
 	
 	// Add brand if not NULL
@@ -570,11 +685,14 @@ func (c *Consumer) publishProductCreated(ctx context.Context, asin string, dimen
 	streamKey := "stream:product_lifecycle"
 	err = c.redis.XAdd(ctx, &redis.XAddArgs{
 		Stream: streamKey,
+		MaxLen: c.maxLen,
+		Approx: c.maxLen > 0,
 		Values: map[string]interface{}{
-			"event_type": "PRODUCT_CREATED",
-			"event_id":   eventPayload["event_id"],
-			"asin":       asin,
-			"payload":    string(payloadJSON),
+			"event_type":     schema.EventProductCreated,
+			"event_id":       eventPayload["event_id"],
+			"asin":           asin,
+			"payload":        string(payloadJSON),
+			"schema_version": schema.VersionV1,
 		},
 	}).Err()
 	
@@ -582,6 +700,6 @@ func (c *Consumer) publishProductCreated(ctx context.Context, asin string, dimen
 		return fmt.Errorf("failed to publish event: %w", err)
 	}
 	
-	c.logger.Info("Published PRODUCT_CREATED", "asin", asin)
+	c.logger.InfoContext(ctx, "Published PRODUCT_CREATED", "asin", asin)
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/maltedev/amazon-size-scraper/internal/schema"
+	"github.com/maltedev/amazon-size-scraper/internal/scrapeerr"
+	"github.com/maltedev/amazon-size-scraper/internal/scraperclient"
+	"github.com/redis/go-redis/v9"
+)
+
+// messageKey returns the ASIN a message belongs to, the message ID if it carries none
+func messageKey(msg redis.XMessage) string {
+	if event, err := schema.DecodeStreamMessage(msg.Values); err == nil && event.AggregateID != "" {
+		return event.AggregateID
+	}
+	return msg.ID
+}
+
+// partition splits messages into one lane per worker. Messages of the same ASIN share a lane and keep
+// their stream order, so events of one product are never processed concurrently or out of order.
+func partition(messages []redis.XMessage, workers int) [][]redis.XMessage {
+	if workers < 1 {
+		workers = 1
+	}
+	lanes := make([][]redis.XMessage, workers)
+	for _, msg := range messages {
+		h := fnv.New32a()
+		h.Write([]byte(messageKey(msg)))
+		lane := int(h.Sum32() % uint32(workers))
+		lanes[lane] = append(lanes[lane], msg)
+	}
+	return lanes
+}
+
+// processBatch processes the lanes of a batch concurrently and waits for all of them. A lane stops at
+// the first message parked because the scraper is unavailable, its later messages stay pending with it.
+// It reports whether any message was parked.
+func (c *Consumer) processBatch(ctx context.Context, streamKey, group string, messages []redis.XMessage) bool {
+	var parked atomic.Bool
+	var wg sync.WaitGroup
+	for _, lane := range partition(messages, c.workers) {
+		if len(lane) == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, message := range lane {
+				if err := c.processMessage(ctx, message); err != nil {
+					if errors.Is(err, scraperclient.ErrUnavailable) || errors.Is(err, scrapeerr.ErrBlocked) {
+						// Leave the message pending and replay it once the scraper is back or Amazon
+						// serves pages again
+						c.logger.WarnContext(ctx, "Scraper unavailable or blocked, parking message",
+							"id", message.ID,
+							"breaker", c.scraper.Breaker().State(),
+							"error", err,
+						)
+						parked.Store(true)
+						return
+					}
+					c.logger.ErrorContext(ctx, "Failed to process message", "id", message.ID, "error", err)
+					continue
+				}
+
+				// Acknowledge message
+				if err := c.redis.XAck(ctx, streamKey, group, message.ID).Err(); err != nil {
+					c.logger.ErrorContext(ctx, "Failed to acknowledge message", "id", message.ID, "error", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return parked.Load()
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestPartition_KeepsASINOrderInOneLane(t *testing.T) {
+	var messages []redis.XMessage
+	for i := range 30 {
+		messages = append(messages, redis.XMessage{
+			ID:     fmt.Sprintf("%d-0", i),
+			Values: map[string]interface{}{"event_type": "01_PRODUCT_DETECTED", "aggregate_id": fmt.Sprintf("B0ASIN%04d", i%5)},
+		})
+	}
+
+	lanes := partition(messages, 4)
+	laneOf := map[string]int{}
+	total := 0
+	for i, lane := range lanes {
+		last := -1
+		for _, msg := range lane {
+			asin := msg.Values["aggregate_id"].(string)
+			if l, ok := laneOf[asin]; ok && l != i {
+				t.Errorf("ASIN %s in lanes %d and %d", asin, l, i)
+			}
+			laneOf[asin] = i
+
+			var seq int
+			fmt.Sscanf(msg.ID, "%d-0", &seq)
+			if seq <= last {
+				t.Errorf("lane %d out of order: %s after %d", i, msg.ID, last)
+			}
+			last = seq
+			total++
+		}
+	}
+	if total != len(messages) {
+		t.Errorf("partition() kept %d of %d messages", total, len(messages))
+	}
+}
+
+func TestPartition_SingleWorker(t *testing.T) {
+	messages := []redis.XMessage{{ID: "1-0"}, {ID: "2-0", Values: map[string]interface{}{"asin": "B0TEST0001"}}}
+	for _, workers := range []int{0, 1} {
+		if lanes := partition(messages, workers); len(lanes) != 1 || len(lanes[0]) != 2 {
+			t.Errorf("partition(%d workers) = %v", workers, lanes)
+		}
+	}
+}
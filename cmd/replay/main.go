@@ -0,0 +1,12 @@
+// Command replay publishes processed outbox events again, the same as "scraper replay".
+package main
+
+import (
+	"os"
+
+	"github.com/maltedev/amazon-size-scraper/internal/cli"
+)
+
+func main() {
+	os.Exit(cli.Execute(append([]string{"replay"}, os.Args[1:]...)))
+}
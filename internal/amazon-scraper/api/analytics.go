@@ -0,0 +1,291 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/jobs"
+	"github.com/maltedev/amazon-size-scraper/internal/analytics"
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/maltedev/amazon-size-scraper/internal/taxonomy"
+)
+
+// defaultCoverageSample is the sample size of a coverage analysis that sets none
+const defaultCoverageSample = 200
+
+// CoverageRequest starts a coverage analysis of a category code, all categories if it is empty
+type CoverageRequest struct {
+	Category   string `json:"category"`
+	SampleSize int    `json:"sample_size"` // Defaults to 200, at most jobs.MaxCoverageSample
+}
+
+// CoverageReportResponse is a coverage report with the shares of the sample
+type CoverageReportResponse struct {
+	*database.CoverageReport
+	Rates analytics.Rates `json:"rates"`
+}
+
+// CoverageReportsResponse lists coverage reports, newest first
+type CoverageReportsResponse struct {
+	Reports []CoverageReportResponse `json:"reports"`
+}
+
+// AnalyzeCoverage handles running a size chart coverage analysis over a random product sample
+func (h *Handlers) AnalyzeCoverage(w http.ResponseWriter, r *http.Request) {
+	var req CoverageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.Category = strings.TrimSpace(req.Category)
+	if req.Category != "" && !taxonomy.Valid(req.Category) {
+		h.respondError(w, http.StatusBadRequest, "unknown category code: "+req.Category)
+		return
+	}
+	if req.SampleSize == 0 {
+		req.SampleSize = defaultCoverageSample
+	}
+	if req.SampleSize < 0 || req.SampleSize > jobs.MaxCoverageSample {
+		h.respondError(w, http.StatusBadRequest, "sample_size must be between 1 and "+strconv.Itoa(jobs.MaxCoverageSample))
+		return
+	}
+
+	_, report, err := h.jobs.AnalyzeCoverage(r.Context(), req.Category, req.SampleSize)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to analyze coverage", "error", err, "category", req.Category)
+		h.respondError(w, http.StatusInternalServerError, "failed to analyze coverage")
+		return
+	}
+	h.respondJSON(w, http.StatusCreated, CoverageReportResponse{CoverageReport: report, Rates: report.Rates()})
+}
+
+// ListCoverageReports handles listing the coverage reports of a category over time, ?category=,
+// ?since=<RFC 3339> and ?limit= (default 50, at most 500)
+func (h *Handlers) ListCoverageReports(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var since time.Time
+	if v := query.Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "since must be an RFC 3339 timestamp")
+			return
+		}
+		since = t
+	}
+	limit := 50
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > 500 {
+			h.respondError(w, http.StatusBadRequest, "limit must be between 1 and 500")
+			return
+		}
+		limit = n
+	}
+
+	reports, err := h.jobs.ListCoverageReports(r.Context(), strings.TrimSpace(query.Get("category")), since, limit)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to list coverage reports", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "failed to list coverage reports")
+		return
+	}
+
+	resp := CoverageReportsResponse{Reports: make([]CoverageReportResponse, len(reports))}
+	for i, report := range reports {
+		resp.Reports[i] = CoverageReportResponse{CoverageReport: report, Rates: report.Rates()}
+	}
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+// SizeAnalysisRequest queues a size table analysis of search results
+type SizeAnalysisRequest struct {
+	SearchQuery string `json:"search_query"`
+	Category    string `json:"category"`    // Amazon search category, e.g. fashion
+	MaxPages    int    `json:"max_pages"`   // Result pages to collect products from, defaults to 1
+	SampleSize  int    `json:"sample_size"` // Defaults to jobs.DefaultAnalysisSample, at most jobs.MaxAnalysisSample
+}
+
+// SizeAnalysisJobResponse is a queued size analysis and its job
+type SizeAnalysisJobResponse struct {
+	Job      *jobs.Job              `json:"job"`
+	Analysis *database.SizeAnalysis `json:"analysis"`
+}
+
+// SizeAnalysisResponse is a size analysis with its analyzed products
+type SizeAnalysisResponse struct {
+	*database.SizeAnalysis
+	Results []analytics.Result `json:"results"`
+}
+
+// SizeAnalysesResponse lists size analyses, newest first
+type SizeAnalysesResponse struct {
+	Analyses []*database.SizeAnalysis `json:"analyses"`
+}
+
+// sizeAnalysisStatuses are the result statuses accepted by ?status=
+var sizeAnalysisStatuses = []string{
+	analytics.StatusComplete, analytics.StatusMissingLength, analytics.StatusMissingWidth,
+	analytics.StatusMissingBoth, analytics.StatusNoTable, analytics.StatusError,
+}
+
+// QueueSizeAnalysis handles queueing a size analysis job, which the worker runs like a crawl job
+func (h *Handlers) QueueSizeAnalysis(w http.ResponseWriter, r *http.Request) {
+	var req SizeAnalysisRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if strings.TrimSpace(req.SearchQuery) == "" {
+		h.respondError(w, http.StatusBadRequest, "search_query is required")
+		return
+	}
+	if req.MaxPages < 0 {
+		h.respondError(w, http.StatusBadRequest, "max_pages must be positive")
+		return
+	}
+	if req.SampleSize < 0 || req.SampleSize > jobs.MaxAnalysisSample {
+		h.respondError(w, http.StatusBadRequest, "sample_size must be between 1 and "+strconv.Itoa(jobs.MaxAnalysisSample))
+		return
+	}
+
+	job, analysis, err := h.jobs.QueueSizeAnalysis(r.Context(), jobs.SizeAnalysisOptions{
+		SearchQuery: req.SearchQuery,
+		Category:    strings.TrimSpace(req.Category),
+		MaxPages:    req.MaxPages,
+		SampleSize:  req.SampleSize,
+	})
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to queue size analysis", "error", err, "query", req.SearchQuery)
+		h.respondError(w, http.StatusInternalServerError, "failed to queue size analysis")
+		return
+	}
+	h.respondJSON(w, http.StatusCreated, SizeAnalysisJobResponse{Job: job, Analysis: analysis})
+}
+
+// ListSizeAnalyses handles listing the latest size analyses, ?limit= (default 50, at most 500)
+func (h *Handlers) ListSizeAnalyses(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > 500 {
+			h.respondError(w, http.StatusBadRequest, "limit must be between 1 and 500")
+			return
+		}
+		limit = n
+	}
+
+	analyses, err := h.jobs.ListSizeAnalyses(r.Context(), limit)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to list size analyses", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "failed to list size analyses")
+		return
+	}
+	h.respondJSON(w, http.StatusOK, SizeAnalysesResponse{Analyses: analyses})
+}
+
+// GetSizeAnalysis handles returning a size analysis with its results, ?status= selects results of one
+// status, e.g. complete
+func (h *Handlers) GetSizeAnalysis(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "analysisID")
+	if _, err := uuid.Parse(id); err != nil {
+		h.respondError(w, http.StatusBadRequest, "analysis ID must be a UUID")
+		return
+	}
+	status := r.URL.Query().Get("status")
+	if status != "" && !slices.Contains(sizeAnalysisStatuses, status) {
+		h.respondError(w, http.StatusBadRequest, "status must be one of "+strings.Join(sizeAnalysisStatuses, ", "))
+		return
+	}
+
+	analysis, results, err := h.jobs.GetSizeAnalysis(r.Context(), id, status)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to get size analysis", "error", err, "id", id)
+		h.respondError(w, http.StatusInternalServerError, "failed to get size analysis")
+		return
+	}
+	if analysis == nil {
+		h.respondError(w, http.StatusNotFound, "size analysis not found")
+		return
+	}
+	h.respondJSON(w, http.StatusOK, SizeAnalysisResponse{SizeAnalysis: analysis, Results: results})
+}
+
+// overlapJob resolves one side of an overlap request, a job ID in ?<side>= or the latest completed job
+// of the template in ?<side>_template=
+func (h *Handlers) overlapJob(r *http.Request, side string) (string, int, error) {
+	query := r.URL.Query()
+	jobID, templateID := strings.TrimSpace(query.Get(side)), strings.TrimSpace(query.Get(side+"_template"))
+	if (jobID == "") == (templateID == "") {
+		return "", http.StatusBadRequest, errors.New("either " + side + " or " + side + "_template is required")
+	}
+	id := jobID
+	if templateID != "" {
+		id = templateID
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return "", http.StatusBadRequest, errors.New(side + " must be a UUID")
+	}
+	if jobID != "" {
+		return jobID, 0, nil
+	}
+
+	jobID, err := h.jobs.LatestTemplateJob(r.Context(), templateID)
+	if errors.Is(err, jobs.ErrJobNotFound) {
+		return "", http.StatusNotFound, err
+	}
+	if err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+	return jobID, 0, nil
+}
+
+// CompareJobs handles comparing the ASIN sets of two jobs or saved searches: ?left= and ?right= name
+// jobs, ?left_template= and ?right_template= the latest completed job of a template. ?limit= caps each
+// product list (default 100, at most 1000), the counts cover all ASINs.
+func (h *Handlers) CompareJobs(w http.ResponseWriter, r *http.Request) {
+	limit := 100
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > 1000 {
+			h.respondError(w, http.StatusBadRequest, "limit must be between 1 and 1000")
+			return
+		}
+		limit = n
+	}
+
+	var ids [2]string
+	for i, side := range []string{"left", "right"} {
+		id, status, err := h.overlapJob(r, side)
+		if err != nil {
+			if status == http.StatusInternalServerError {
+				h.logger.ErrorContext(r.Context(), "failed to resolve template job", "error", err, "side", side)
+				err = errors.New("failed to resolve template job")
+			}
+			h.respondError(w, status, err.Error())
+			return
+		}
+		ids[i] = id
+	}
+
+	overlap, err := h.jobs.CompareJobs(r.Context(), ids[0], ids[1])
+	if errors.Is(err, jobs.ErrJobNotFound) {
+		h.respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to compare jobs", "error", err, "left", ids[0], "right", ids[1])
+		h.respondError(w, http.StatusInternalServerError, "failed to compare jobs")
+		return
+	}
+
+	overlap.SharedProducts = overlap.SharedProducts[:min(limit, len(overlap.SharedProducts))]
+	overlap.OnlyLeft = overlap.OnlyLeft[:min(limit, len(overlap.OnlyLeft))]
+	overlap.OnlyRight = overlap.OnlyRight[:min(limit, len(overlap.OnlyRight))]
+	h.respondJSON(w, http.StatusOK, overlap)
+}
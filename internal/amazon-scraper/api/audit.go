@@ -0,0 +1,159 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	logging "github.com/maltedev/amazon-size-scraper/pkg/logger"
+)
+
+// Actors of audit log entries besides API keys
+const (
+	AuditActorAdmin     = "admin"     // Authenticated with the admin token
+	AuditActorAnonymous = "anonymous" // Neither admin token nor API key
+)
+
+type adminKey struct{}
+
+type auditResourceKey struct{}
+
+// setAuditResource names the resource of an audited request that is only known once the handler ran,
+// e.g. the job created by an import
+func setAuditResource(ctx context.Context, id string) {
+	if resource, ok := ctx.Value(auditResourceKey{}).(*string); ok {
+		*resource = id
+	}
+}
+
+// auditActor returns who sent an audited request. API keys are recorded as a prefix of their SHA-256
+// hash, never in clear.
+func auditActor(r *http.Request) string {
+	if admin, _ := r.Context().Value(adminKey{}).(bool); admin {
+		return AuditActorAdmin
+	}
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		sum := sha256.Sum256([]byte(key))
+		return "api_key:" + hex.EncodeToString(sum[:])[:12]
+	}
+	return AuditActorAnonymous
+}
+
+// Audit records every request of the route in the audit log once it was answered, with its actor,
+// action, resource and response status. The resource ID is the URL parameter idParam, if set, or the
+// ID the handler named. Without a database requests pass unrecorded.
+func (h *Handlers) Audit(action, resourceType, idParam string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if h.db == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			resourceID := ""
+			if idParam != "" {
+				resourceID = chi.URLParam(r, idParam)
+			}
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r.WithContext(context.WithValue(r.Context(), auditResourceKey{}, &resourceID)))
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			entry := &database.AuditEntry{
+				Actor:        auditActor(r),
+				Action:       action,
+				ResourceType: resourceType,
+				ResourceID:   resourceID,
+				Method:       r.Method,
+				Path:         r.URL.Path,
+				Status:       status,
+				RequestID:    middleware.GetReqID(r.Context()),
+				TraceID:      logging.TraceID(r.Context()),
+			}
+			// Recorded even if the client went away, the action may have taken effect
+			ctx := context.WithoutCancel(r.Context())
+			if err := h.db.InsertAuditEntry(ctx, entry); err != nil {
+				h.logger.ErrorContext(ctx, "failed to record audit entry", "error", err, "action", action, "resource_id", resourceID)
+			}
+		})
+	}
+}
+
+// AuditLogResponse is a page of audit log entries
+type AuditLogResponse struct {
+	Entries []database.AuditEntry `json:"entries"`
+	Total   int                   `json:"total"`
+	Limit   int                   `json:"limit"`
+	Offset  int                   `json:"offset"`
+}
+
+// auditFilter reads the filters of an audit log listing
+func auditFilter(query url.Values) (database.AuditFilter, error) {
+	get := func(key string) string {
+		return strings.TrimSpace(query.Get(key))
+	}
+
+	filter := database.AuditFilter{
+		Actor:        get("actor"),
+		Action:       get("action"),
+		ResourceType: get("resource_type"),
+		ResourceID:   get("resource_id"),
+	}
+	for key, target := range map[string]*time.Time{"created_after": &filter.CreatedAfter, "created_before": &filter.CreatedBefore} {
+		if v := get(key); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return filter, errors.New(key + " must be an RFC 3339 timestamp")
+			}
+			*target = t
+		}
+	}
+	return filter, nil
+}
+
+// ListAuditLog handles listing audit log entries by actor, action, resource and time, newest first
+func (h *Handlers) ListAuditLog(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil {
+		h.respondError(w, http.StatusServiceUnavailable, "audit log needs a database")
+		return
+	}
+
+	query := r.URL.Query()
+	filter, err := auditFilter(query)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	limit, offset := 50, 0
+	if v := query.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 || limit > 500 {
+			h.respondError(w, http.StatusBadRequest, "limit must be between 1 and 500")
+			return
+		}
+	}
+	if v := query.Get("offset"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+			h.respondError(w, http.StatusBadRequest, "offset must be a non-negative integer")
+			return
+		}
+	}
+
+	entries, total, err := h.db.ListAuditEntries(r.Context(), filter, limit, offset)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to list audit entries", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "failed to list audit entries")
+		return
+	}
+	h.respondJSON(w, http.StatusOK, AuditLogResponse{Entries: entries, Total: total, Limit: limit, Offset: offset})
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditActor(t *testing.T) {
+	var admin string
+	AdminAuth("secret")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		admin = auditActor(r)
+	})).ServeHTTP(httptest.NewRecorder(), func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/outbox/replay", nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		req.Header.Set("X-API-Key", "key-1")
+		return req
+	}())
+	if admin != AuditActorAdmin {
+		t.Errorf("actor with admin token = %q, want %q", admin, AuditActorAdmin)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/scraper/imports", nil)
+	// An unverified bearer token is not an admin
+	req.Header.Set("Authorization", "Bearer secret")
+	if got := auditActor(req); got != AuditActorAnonymous {
+		t.Errorf("actor without API key = %q, want %q", got, AuditActorAnonymous)
+	}
+
+	req.Header.Set("X-API-Key", "key-1")
+	got := auditActor(req)
+	if !strings.HasPrefix(got, "api_key:") || len(got) != len("api_key:")+12 || strings.Contains(got, "key-1") {
+		t.Errorf("actor with API key = %q, want a hash prefix of the key", got)
+	}
+}
+
+func TestSetAuditResource(t *testing.T) {
+	resource := ""
+	ctx := context.WithValue(context.Background(), auditResourceKey{}, &resource)
+	setAuditResource(ctx, "6f1c0a44-3b5e-4d8f-9a0e-2f0c8a1b7c55")
+	if resource != "6f1c0a44-3b5e-4d8f-9a0e-2f0c8a1b7c55" {
+		t.Errorf("resource = %q, want the job ID", resource)
+	}
+
+	// Handlers outside an audited route name no resource
+	setAuditResource(context.Background(), "ignored")
+}
+
+func TestAuditFilter(t *testing.T) {
+	query, _ := url.ParseQuery("actor=admin&action=job.cancel&resource_type=job&created_after=2024-03-01T00:00:00Z")
+	filter, err := auditFilter(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if filter.Actor != "admin" || filter.Action != "job.cancel" || filter.ResourceType != "job" || filter.ResourceID != "" {
+		t.Errorf("unexpected filter: %+v", filter)
+	}
+	if !filter.CreatedAfter.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) || !filter.CreatedBefore.IsZero() {
+		t.Errorf("unexpected created range: %v - %v", filter.CreatedAfter, filter.CreatedBefore)
+	}
+
+	query, _ = url.ParseQuery("created_before=yesterday")
+	if _, err := auditFilter(query); err == nil {
+		t.Error("expected an error for an invalid timestamp")
+	}
+}
@@ -0,0 +1,306 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/events"
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/scraper"
+	"github.com/maltedev/amazon-size-scraper/internal/schema"
+	"github.com/maltedev/amazon-size-scraper/internal/scrapeerr"
+)
+
+// maxBatchItems limits the products of one batch request
+const maxBatchItems = 100
+
+// ndjsonKeepAlive is how often a batch stream without a finished item sends a heartbeat line
+const ndjsonKeepAlive = 15 * time.Second
+
+// ContentTypeNDJSON is requested in Accept to receive batch results as newline delimited JSON
+const ContentTypeNDJSON = "application/x-ndjson"
+
+// Types of NDJSON batch lines
+const (
+	BatchLineResult    = "result"
+	BatchLineHeartbeat = "heartbeat"
+	BatchLineSummary   = "summary" // Last line, once every item finished
+)
+
+// SetBatchWorkers sets how many items of a batch request are scraped in parallel, at least one
+func (h *Handlers) SetBatchWorkers(n int) {
+	h.batchWorkers = max(n, 1)
+}
+
+// BatchSizeChartRequest extracts the size charts of several products
+type BatchSizeChartRequest struct {
+	Items []SizeChartRequest `json:"items"`
+}
+
+// BatchProductItem is a product of a batch scrape, either asin or url must be set
+type BatchProductItem struct {
+	ASIN string `json:"asin"`
+	URL  string `json:"url"`
+}
+
+// BatchProductRequest scrapes and stores several products like a job would, without creating a job
+type BatchProductRequest struct {
+	Items    []BatchProductItem `json:"items"`
+	Metadata map[string]string  `json:"metadata,omitempty"` // Caller metadata of every product and its event
+}
+
+// BatchProductResponse is the outcome of one product of a batch scrape
+type BatchProductResponse struct {
+	Result        string `json:"result,omitempty"` // saved, unchanged or duplicate
+	CanonicalASIN string `json:"canonical_asin,omitempty"`
+	Error         string `json:"error,omitempty"`
+	ErrorCode     string `json:"error_code,omitempty"`
+}
+
+// BatchResult is the outcome of one item of a batch request
+type BatchResult struct {
+	Type   string `json:"type,omitempty"` // BatchLineResult in NDJSON streams
+	Index  int    `json:"index"`          // Position of the item in the request
+	ASIN   string `json:"asin,omitempty"`
+	Cached bool   `json:"cached,omitempty"`
+	Result any    `json:"result"` // SizeChartResponse or BatchProductResponse
+
+	ok bool
+}
+
+// BatchSummary counts the items of a finished batch
+type BatchSummary struct {
+	Type       string `json:"type,omitempty"` // BatchLineSummary in NDJSON streams
+	Total      int    `json:"total"`
+	Succeeded  int    `json:"succeeded"`
+	Failed     int    `json:"failed"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// BatchResponse is the JSON answer of a batch request, results in request order
+type BatchResponse struct {
+	Results []BatchResult `json:"results"`
+	BatchSummary
+}
+
+// batchHeartbeat keeps an NDJSON stream open while items are scraped
+type batchHeartbeat struct {
+	Type      string `json:"type"`
+	Completed int    `json:"completed"`
+	Total     int    `json:"total"`
+}
+
+// BatchSizeCharts extracts the size charts of up to maxBatchItems products. With Accept:
+// application/x-ndjson every result is streamed as a line once its product finished.
+func (h *Handlers) BatchSizeCharts(w http.ResponseWriter, r *http.Request) {
+	var req BatchSizeChartRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validateBatchSize(len(req.Items)); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.runBatch(w, r, len(req.Items), func(ctx context.Context, i int) BatchResult {
+		return h.batchSizeChart(ctx, req.Items[i])
+	})
+}
+
+// batchSizeChart extracts the size chart of one batch item like GetSizeChart, answering from the
+// size chart cache when it can
+func (h *Handlers) batchSizeChart(ctx context.Context, item SizeChartRequest) BatchResult {
+	if item.ASIN == "" && item.URL == "" {
+		return BatchResult{Result: SizeChartResponse{Error: "either asin or url is required"}}
+	}
+	target, err := h.productTarget(ctx, item.ASIN, item.URL)
+	if err != nil {
+		return BatchResult{ASIN: item.ASIN, Result: SizeChartResponse{Error: err.Error()}}
+	}
+	item.ASIN = target.ASIN
+	if target.ASIN != "" && item.URL != "" {
+		item.URL = target.URL()
+	}
+
+	cacheKey := ""
+	if h.sizeCache != nil && target.ASIN != "" {
+		cacheKey = target.String()
+		if item.BypassCache {
+			h.sizeCache.Bypass()
+		} else if cached, _, ok := h.sizeCache.Get(ctx, cacheKey); ok {
+			var resp SizeChartResponse
+			if err := json.Unmarshal(cached, &resp); err == nil {
+				return BatchResult{ASIN: item.ASIN, Cached: true, Result: resp, ok: true}
+			}
+		}
+	}
+
+	resp, err := h.extractSizeChart(ctx, item, cacheKey)
+	if err != nil {
+		resp = SizeChartResponse{Error: err.Error(), ErrorCode: scrapeerr.Code(err), FailureCategory: scraper.FailureCategory(err)}
+	}
+	return BatchResult{ASIN: item.ASIN, Result: resp, ok: resp.Error == ""}
+}
+
+// BatchScrapeProducts scrapes and stores up to maxBatchItems products, each like a SCRAPE_ASIN command.
+// With Accept: application/x-ndjson every result is streamed as a line once its product finished.
+func (h *Handlers) BatchScrapeProducts(w http.ResponseWriter, r *http.Request) {
+	var req BatchProductRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validateBatchSize(len(req.Items)); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := schema.ValidateMetadata(req.Metadata); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.runBatch(w, r, len(req.Items), func(ctx context.Context, i int) BatchResult {
+		return h.batchProduct(events.WithMetadata(ctx, req.Metadata), req.Items[i])
+	})
+}
+
+// batchProduct scrapes and stores one product of a batch
+func (h *Handlers) batchProduct(ctx context.Context, item BatchProductItem) BatchResult {
+	if item.ASIN == "" && item.URL == "" {
+		return BatchResult{Result: BatchProductResponse{Error: "either asin or url is required"}}
+	}
+	target, err := h.productTarget(ctx, item.ASIN, item.URL)
+	if err != nil {
+		return BatchResult{ASIN: item.ASIN, Result: BatchProductResponse{Error: err.Error()}}
+	}
+	if target.ASIN == "" {
+		return BatchResult{Result: BatchProductResponse{Error: "url is not a product page"}}
+	}
+
+	scrape, err := h.jobs.ScrapeProduct(ctx, target.ASIN, target.URL())
+	if err != nil {
+		return BatchResult{ASIN: target.ASIN, Result: BatchProductResponse{Error: err.Error(), ErrorCode: scrapeerr.Code(err)}}
+	}
+	return BatchResult{
+		ASIN:   target.ASIN,
+		Result: BatchProductResponse{Result: scrape.Result, CanonicalASIN: scrape.CanonicalASIN},
+		ok:     true,
+	}
+}
+
+// validateBatchSize rejects empty and oversized batches
+func validateBatchSize(n int) error {
+	if n == 0 {
+		return errors.New("items are required")
+	}
+	if n > maxBatchItems {
+		return fmt.Errorf("at most %d items are allowed per batch", maxBatchItems)
+	}
+	return nil
+}
+
+// acceptsNDJSON reports whether the client asked for a newline delimited JSON stream
+func acceptsNDJSON(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), ContentTypeNDJSON)
+}
+
+// runBatch scrapes the n items of a batch request with the batch workers. A JSON client receives all
+// results in request order once the last one finished; an NDJSON client receives each result as a line
+// when it finished, heartbeat lines while nothing finishes and a summary line at the end.
+func (h *Handlers) runBatch(w http.ResponseWriter, r *http.Request, n int, scrape func(ctx context.Context, i int) BatchResult) {
+	ctx := r.Context()
+	start := time.Now()
+
+	items := make(chan int, n)
+	for i := range n {
+		items <- i
+	}
+	close(items)
+
+	// Buffered for every item, so workers never block on a client that went away
+	finished := make(chan BatchResult, n)
+	for range min(max(h.batchWorkers, 1), n) {
+		go func() {
+			for i := range items {
+				if ctx.Err() != nil {
+					return
+				}
+				result := scrape(ctx, i)
+				result.Index = i
+				finished <- result
+			}
+		}()
+	}
+
+	summary := BatchSummary{Total: n}
+	count := func(result BatchResult) {
+		if result.ok {
+			summary.Succeeded++
+		} else {
+			summary.Failed++
+		}
+	}
+
+	if !acceptsNDJSON(r) {
+		results := make([]BatchResult, n)
+		for range n {
+			select {
+			case <-ctx.Done():
+				return
+			case result := <-finished:
+				results[result.Index] = result
+				count(result)
+			}
+		}
+		summary.DurationMs = time.Since(start).Milliseconds()
+		h.respondJSON(w, http.StatusOK, BatchResponse{Results: results, BatchSummary: summary})
+		return
+	}
+
+	// The stream outlives the server's write timeout
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", ContentTypeNDJSON)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(w)
+	send := func(line any) bool {
+		if err := enc.Encode(line); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+
+	keepAlive := time.NewTicker(ndjsonKeepAlive)
+	defer keepAlive.Stop()
+
+	for completed := 0; completed < n; {
+		select {
+		case <-ctx.Done():
+			return
+		case result := <-finished:
+			completed++
+			count(result)
+			result.Type = BatchLineResult
+			if !send(result) {
+				return
+			}
+		case <-keepAlive.C:
+			if !send(batchHeartbeat{Type: BatchLineHeartbeat, Completed: completed, Total: n}) {
+				return
+			}
+		}
+	}
+
+	summary.Type = BatchLineSummary
+	summary.DurationMs = time.Since(start).Milliseconds()
+	send(summary)
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeBatch finishes item i after (n-i)*20 milliseconds, so later items finish first, and fails item 1
+func fakeBatch(n int) func(ctx context.Context, i int) BatchResult {
+	return func(ctx context.Context, i int) BatchResult {
+		time.Sleep(time.Duration(n-i) * 20 * time.Millisecond)
+		if i == 1 {
+			return BatchResult{Result: SizeChartResponse{Error: "captcha"}}
+		}
+		return BatchResult{Result: SizeChartResponse{SizeChartFound: true}, ok: true}
+	}
+}
+
+func TestRunBatchJSON(t *testing.T) {
+	h := &Handlers{logger: slog.New(slog.DiscardHandler), batchWorkers: 3}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/scraper/size-chart/batch", nil)
+	rec := httptest.NewRecorder()
+
+	h.runBatch(rec, req, 3, fakeBatch(3))
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	var resp BatchResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if resp.Total != 3 || resp.Succeeded != 2 || resp.Failed != 1 {
+		t.Errorf("summary = %+v, want 3 total, 2 succeeded, 1 failed", resp.BatchSummary)
+	}
+	for i, result := range resp.Results {
+		if result.Index != i || result.Type != "" {
+			t.Errorf("result %d = %+v, want request order without type", i, result)
+		}
+	}
+}
+
+func TestRunBatchNDJSON(t *testing.T) {
+	h := &Handlers{logger: slog.New(slog.DiscardHandler), batchWorkers: 3}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/scraper/size-chart/batch", nil)
+	req.Header.Set("Accept", ContentTypeNDJSON)
+	rec := httptest.NewRecorder()
+
+	h.runBatch(rec, req, 3, fakeBatch(3))
+
+	if ct := rec.Header().Get("Content-Type"); ct != ContentTypeNDJSON {
+		t.Errorf("Content-Type = %q, want %s", ct, ContentTypeNDJSON)
+	}
+	var lines []map[string]any
+	scanner := bufio.NewScanner(strings.NewReader(rec.Body.String()))
+	for scanner.Scan() {
+		var line map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("invalid line %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+
+	if len(lines) != 4 {
+		t.Fatalf("got %d lines, want 3 results and a summary:\n%s", len(lines), rec.Body.String())
+	}
+	// Results are written as they finish, the last item first
+	if lines[0]["type"] != BatchLineResult || lines[0]["index"] != float64(2) {
+		t.Errorf("first line = %v, want result of item 2", lines[0])
+	}
+	last := lines[3]
+	if last["type"] != BatchLineSummary || last["succeeded"] != float64(2) || last["failed"] != float64(1) {
+		t.Errorf("last line = %v, want summary with 2 succeeded and 1 failed", last)
+	}
+}
+
+func TestValidateBatchSize(t *testing.T) {
+	for n, wantErr := range map[int]bool{0: true, 1: false, maxBatchItems: false, maxBatchItems + 1: true} {
+		if err := validateBatchSize(n); (err != nil) != wantErr {
+			t.Errorf("validateBatchSize(%d) error = %v, want error %v", n, err, wantErr)
+		}
+	}
+}
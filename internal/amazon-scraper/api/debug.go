@@ -0,0 +1,189 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/maltedev/amazon-size-scraper/internal/browser"
+)
+
+// SetDebugSessions enables the debug session routes, nil disables them
+func (h *Handlers) SetDebugSessions(d *browser.DebugSessions) {
+	h.debug = d
+}
+
+// DebugSessionRequest opens a debug session on the product page of an ASIN or product URL
+type DebugSessionRequest struct {
+	ASIN string `json:"asin"`
+	URL  string `json:"url"`
+}
+
+// DebugSessionResponse is an open debug session with the routes to inspect it
+type DebugSessionResponse struct {
+	*browser.DebugSession
+	CDPURL string `json:"cdp_url"`           // DevTools endpoint, e.g. for Playwright's connectOverCDP with the admin token
+	VNCURL string `json:"vnc_url,omitempty"` // noVNC page of the display of headed sessions
+}
+
+func (h *Handlers) newDebugSessionResponse(r *http.Request, s *browser.DebugSession) DebugSessionResponse {
+	return DebugSessionResponse{
+		DebugSession: s,
+		CDPURL:       requestScheme(r) + "://" + r.Host + debugCDPPath(s.ID),
+		VNCURL:       h.debug.VNCURL(),
+	}
+}
+
+// debugCDPPath is the route proxying the DevTools endpoint of a session
+func debugCDPPath(id string) string {
+	return "/api/v1/debug/sessions/" + id + "/cdp"
+}
+
+// requestScheme returns http or https as seen by the client, behind a proxy from X-Forwarded-Proto
+func requestScheme(r *http.Request) string {
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+		return proto
+	}
+	if r.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// StartDebugSession handles opening a debug browser on one product page, only one session is open at a time
+func (h *Handlers) StartDebugSession(w http.ResponseWriter, r *http.Request) {
+	if h.debug == nil {
+		h.respondError(w, http.StatusNotFound, "debug sessions are disabled, set SCRAPER_DEBUG_SESSIONS")
+		return
+	}
+
+	var req DebugSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.ASIN == "" && req.URL == "" {
+		h.respondError(w, http.StatusBadRequest, "asin or url is required")
+		return
+	}
+	target, err := h.productTarget(r.Context(), req.ASIN, req.URL)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if target.ASIN == "" {
+		h.respondError(w, http.StatusBadRequest, "url does not link to a product")
+		return
+	}
+
+	session, err := h.debug.Start(r.Context(), target.ASIN, target.URL())
+	if errors.Is(err, browser.ErrDebugSessionActive) {
+		h.respondError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to start debug session", "error", err, "asin", target.ASIN)
+		h.respondError(w, http.StatusInternalServerError, "failed to start debug session: "+err.Error())
+		return
+	}
+	h.respondJSON(w, http.StatusCreated, h.newDebugSessionResponse(r, session))
+}
+
+// GetDebugSession handles retrieving an open debug session
+func (h *Handlers) GetDebugSession(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.debugSession(w, r)
+	if !ok {
+		return
+	}
+	h.respondJSON(w, http.StatusOK, h.newDebugSessionResponse(r, session))
+}
+
+// CloseDebugSession handles closing a debug session and its browser
+func (h *Handlers) CloseDebugSession(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.debugSession(w, r)
+	if !ok {
+		return
+	}
+	if err := h.debug.Close(session.ID); err != nil && !errors.Is(err, browser.ErrDebugSessionNotFound) {
+		h.logger.WarnContext(r.Context(), "failed to close debug browser", "error", err, "id", session.ID)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ProxyDebugSession forwards DevTools requests and WebSocket connections to the session's browser,
+// which only listens on localhost. Endpoints in DevTools listings are rewritten to this route.
+func (h *Handlers) ProxyDebugSession(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.debugSession(w, r)
+	if !ok {
+		return
+	}
+	endpoint, err := url.Parse(session.Endpoint())
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "invalid debug endpoint")
+		return
+	}
+	public := r.Host + debugCDPPath(session.ID)
+	wsScheme := "ws"
+	if requestScheme(r) == "https" {
+		wsScheme = "wss"
+	}
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(endpoint)
+			pr.Out.URL.Path = "/" + chi.URLParam(r, "*")
+			pr.Out.URL.RawPath = ""
+			// Chromium refuses DevTools WebSockets from foreign origins, the admin token guards this route
+			pr.Out.Header.Del("Origin")
+			pr.Out.Header.Del("Authorization")
+		},
+		ModifyResponse: func(resp *http.Response) error {
+			if !strings.HasPrefix(resp.Request.URL.Path, "/json") {
+				return nil
+			}
+			body, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				return err
+			}
+			body = rewriteDevToolsEndpoints(body, endpoint.Host, public, wsScheme)
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+			resp.ContentLength = int64(len(body))
+			resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			h.logger.WarnContext(r.Context(), "debug session proxy failed", "error", err, "id", session.ID)
+			h.respondError(w, http.StatusBadGateway, "debug browser is not reachable")
+		},
+	}
+	proxy.ServeHTTP(w, r)
+}
+
+// rewriteDevToolsEndpoints points the WebSocket URLs of a DevTools listing, e.g. /json/version, at the
+// public route instead of the browser's localhost port
+func rewriteDevToolsEndpoints(body []byte, local, public, wsScheme string) []byte {
+	body = bytes.ReplaceAll(body, []byte("ws://"+local), []byte(wsScheme+"://"+public))
+	return bytes.ReplaceAll(body, []byte("ws="+local), []byte(wsScheme+"="+public))
+}
+
+// debugSession looks up the session of the request, responding with an error if there is none
+func (h *Handlers) debugSession(w http.ResponseWriter, r *http.Request) (*browser.DebugSession, bool) {
+	if h.debug == nil {
+		h.respondError(w, http.StatusNotFound, "debug sessions are disabled, set SCRAPER_DEBUG_SESSIONS")
+		return nil, false
+	}
+	session, err := h.debug.Get(chi.URLParam(r, "sessionID"))
+	if err != nil {
+		h.respondError(w, http.StatusNotFound, err.Error())
+		return nil, false
+	}
+	return session, true
+}
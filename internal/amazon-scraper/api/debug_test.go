@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRewriteDevToolsEndpoints(t *testing.T) {
+	body := []byte(`{"webSocketDebuggerUrl": "ws://127.0.0.1:9222/devtools/browser/abc", ` +
+		`"devtoolsFrontendUrl": "/devtools/inspector.html?ws=127.0.0.1:9222/devtools/page/def"}`)
+
+	got := string(rewriteDevToolsEndpoints(body, "127.0.0.1:9222", "scraper.example.com/api/v1/debug/sessions/1a2b/cdp", "wss"))
+	want := `{"webSocketDebuggerUrl": "wss://scraper.example.com/api/v1/debug/sessions/1a2b/cdp/devtools/browser/abc", ` +
+		`"devtoolsFrontendUrl": "/devtools/inspector.html?wss=scraper.example.com/api/v1/debug/sessions/1a2b/cdp/devtools/page/def"}`
+	if got != want {
+		t.Errorf("rewriteDevToolsEndpoints() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRequestScheme(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/debug/sessions/1a2b", nil)
+	if got := requestScheme(req); got != "http" {
+		t.Errorf("requestScheme() = %q, want http", got)
+	}
+	req.Header.Set("X-Forwarded-Proto", "https")
+	if got := requestScheme(req); got != "https" {
+		t.Errorf("requestScheme() behind proxy = %q, want https", got)
+	}
+}
+
+func TestDebugSessionsDisabled(t *testing.T) {
+	h := &Handlers{}
+	rec := httptest.NewRecorder()
+	h.GetDebugSession(rec, httptest.NewRequest(http.MethodGet, "/api/v1/debug/sessions/1a2b", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
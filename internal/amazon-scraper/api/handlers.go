@@ -1,40 +1,122 @@
 package api
 
 import (
+	"context"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/jobs"
 	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/scraper"
+	"github.com/maltedev/amazon-size-scraper/internal/asin"
+	"github.com/maltedev/amazon-size-scraper/internal/brand"
+	"github.com/maltedev/amazon-size-scraper/internal/browser"
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/maltedev/amazon-size-scraper/internal/importer"
+	"github.com/maltedev/amazon-size-scraper/internal/quota"
+	"github.com/maltedev/amazon-size-scraper/internal/reviewsummary"
+	"github.com/maltedev/amazon-size-scraper/internal/schema"
+	"github.com/maltedev/amazon-size-scraper/internal/scrapeerr"
+	"github.com/maltedev/amazon-size-scraper/internal/signedurl"
+	"github.com/maltedev/amazon-size-scraper/internal/sizecache"
+	"github.com/maltedev/amazon-size-scraper/internal/taxonomy"
 )
 
 type Handlers struct {
-	scraper *scraper.Service
-	jobs    *jobs.Manager
-	logger  *slog.Logger
+	scraper     *scraper.Service
+	jobs        *jobs.Manager
+	logger      *slog.Logger
+	db          *database.DB     // Pool statistics for /metrics, nil omits them
+	relay       *database.Relay  // Relay leadership for /metrics, nil omits it
+	sizeCache   *sizecache.Cache // Recent size chart responses, nil disables caching
+	backfilling atomic.Bool      // A backfill runs in the background, only one at a time
+	resolver    *asin.Resolver
+
+	screenshotSigner *signedurl.Signer // Signs screenshot URLs, nil disables the screenshot endpoint
+	screenshotTTL    time.Duration     // Default lifetime of signed screenshot URLs
+
+	debug *browser.DebugSessions // Debug browsers exposed through the admin routes, nil disables them
+
+	batchWorkers int // Items of a batch request scraped in parallel
 }
 
 func NewHandlers(scraper *scraper.Service, jobs *jobs.Manager, logger *slog.Logger) *Handlers {
 	return &Handlers{
-		scraper: scraper,
-		jobs:    jobs,
-		logger:  logger,
+		scraper:  scraper,
+		jobs:     jobs,
+		logger:   logger,
+		resolver:     asin.NewResolver(10 * time.Second),
+		batchWorkers: 1,
+	}
+}
+
+// productTarget normalizes the ASIN of a request, or resolves the product URL when only that is given.
+// The marketplace is taken from the URL. URLs that do not resolve return an empty product, they are
+// navigated to as given.
+func (h *Handlers) productTarget(ctx context.Context, id, productURL string) (asin.Product, error) {
+	if id == "" {
+		target, _ := h.resolver.ResolveProduct(ctx, productURL, asin.DefaultMarketplace)
+		return target, nil
+	}
+	normalized, ok := asin.Normalize(id)
+	if !ok {
+		return asin.Product{}, fmt.Errorf("%w: %q", asin.ErrInvalid, id)
+	}
+	target, _ := asin.Parse(productURL, asin.DefaultMarketplace)
+	if target.Marketplace == "" {
+		target.Marketplace = asin.DefaultMarketplace
 	}
+	return asin.Product{ASIN: normalized, Marketplace: target.Marketplace}, nil
+}
+
+// SetDatabase exports the connection pool statistics of db on /metrics
+func (h *Handlers) SetDatabase(db *database.DB) {
+	h.db = db
+}
+
+// SetRelay exports the leadership of the outbox relay in /metrics
+func (h *Handlers) SetRelay(relay *database.Relay) {
+	h.relay = relay
+}
+
+// SetSizeChartCache answers repeated size chart requests from c within its TTL, nil disables caching
+func (h *Handlers) SetSizeChartCache(c *sizecache.Cache) {
+	h.sizeCache = c
+}
+
+// SetScreenshotSigner enables the screenshot endpoint, its URLs are signed by s and valid for ttl by default
+func (h *Handlers) SetScreenshotSigner(s *signedurl.Signer, ttl time.Duration) {
+	h.screenshotSigner = s
+	h.screenshotTTL = ttl
 }
 
 // SizeChartRequest represents the request for size chart data
 type SizeChartRequest struct {
-	ASIN string `json:"asin"`
-	URL  string `json:"url"`
+	ASIN        string `json:"asin"`
+	URL         string `json:"url"`
+	BypassCache bool   `json:"bypass_cache"` // Scrape even if a cached response exists, the fresh one replaces it
 }
 
 // SizeChartResponse represents the size chart data response
 type SizeChartResponse struct {
-	SizeChartFound bool           `json:"size_chart_found"`
-	SizeTable      *SizeTableData `json:"size_table,omitempty"`
-	Error          string         `json:"error,omitempty"`
+	SizeChartFound  bool                 `json:"size_chart_found"`
+	SizeTable       *SizeTableData       `json:"size_table,omitempty"`
+	Diagnostics     *browser.Diagnostics `json:"diagnostics,omitempty"`
+	Error           string               `json:"error,omitempty"`
+	ErrorCode       string               `json:"error_code,omitempty"`       // One of the scrapeerr codes, e.g. captcha or no_size_table
+	FailureCategory string               `json:"failure_category,omitempty"` // timeout or error
 }
 
 // SizeTableData represents the complete size table
@@ -42,6 +124,8 @@ type SizeTableData struct {
 	Sizes        []string                       `json:"sizes"`
 	Measurements map[string]map[string]float64  `json:"measurements"`
 	Unit         string                        `json:"unit"`
+	Source       string                        `json:"source,omitempty"`
+	Confidence   float64                       `json:"confidence,omitempty"`
 }
 
 // GetSizeChart handles size chart extraction requests (Oxylabs replacement)
@@ -56,20 +140,69 @@ func (h *Handlers) GetSizeChart(w http.ResponseWriter, r *http.Request) {
 		h.respondError(w, http.StatusBadRequest, "either asin or url is required")
 		return
 	}
+	target, err := h.productTarget(r.Context(), req.ASIN, req.URL)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	req.ASIN = target.ASIN
+	if target.ASIN != "" && req.URL != "" {
+		// Ad redirects, ref tags and short links are replaced by the product page
+		req.URL = target.URL()
+	}
+
+	cacheKey := ""
+	if h.sizeCache != nil && target.ASIN != "" {
+		cacheKey = target.String()
+		if req.BypassCache {
+			h.sizeCache.Bypass()
+			w.Header().Set("X-Cache", "BYPASS")
+		} else if cached, layer, ok := h.sizeCache.Get(r.Context(), cacheKey); ok {
+			h.logger.DebugContext(r.Context(), "size chart served from cache", "asin", req.ASIN, "layer", layer)
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("X-Cache", "HIT")
+			w.WriteHeader(http.StatusOK)
+			w.Write(cached)
+			return
+		} else {
+			w.Header().Set("X-Cache", "MISS")
+		}
+	}
+
+	resp, err := h.extractSizeChart(r.Context(), req, cacheKey)
+	if errors.Is(err, quota.ErrBudgetExceeded) {
+		h.respondBudgetExceeded(w, err)
+		return
+	}
+	if errors.Is(err, browser.ErrMarketplaceCooldown) {
+		h.respondCooldown(w, err)
+		return
+	}
+	h.respondJSON(w, http.StatusOK, resp)
+}
 
+// extractSizeChart scrapes the size chart of a resolved request and caches a successful response under
+// cacheKey, if set. Exhausted budgets and marketplace cooldowns are returned, other failures are
+// reported in the response.
+func (h *Handlers) extractSizeChart(ctx context.Context, req SizeChartRequest, cacheKey string) (SizeChartResponse, error) {
 	// Extract size chart data
-	dimensions, err := h.scraper.ExtractSizeChart(r.Context(), req.ASIN, req.URL)
+	dimensions, err := h.scraper.ExtractSizeChart(ctx, req.ASIN, req.URL)
+	if errors.Is(err, quota.ErrBudgetExceeded) || errors.Is(err, browser.ErrMarketplaceCooldown) {
+		return SizeChartResponse{}, err
+	}
 	if err != nil {
-		h.logger.Error("failed to extract size chart", "error", err, "asin", req.ASIN)
-		h.respondJSON(w, http.StatusOK, SizeChartResponse{
-			SizeChartFound: false,
-			Error:          err.Error(),
-		})
-		return
+		h.logger.ErrorContext(ctx, "failed to extract size chart", "error", err, "asin", req.ASIN)
+		return SizeChartResponse{
+			SizeChartFound:  false,
+			Error:           err.Error(),
+			ErrorCode:       scrapeerr.Code(err),
+			FailureCategory: scraper.FailureCategory(err),
+		}, nil
 	}
 
 	resp := SizeChartResponse{
 		SizeChartFound: dimensions.Found,
+		Diagnostics:    dimensions.Diagnostics,
 	}
 
 	// Include complete size table if available
@@ -78,10 +211,20 @@ func (h *Handlers) GetSizeChart(w http.ResponseWriter, r *http.Request) {
 			Sizes:        dimensions.SizeTable.Sizes,
 			Measurements: dimensions.SizeTable.Measurements,
 			Unit:         dimensions.SizeTable.Unit,
+			Source:       dimensions.SizeTable.Source,
+			Confidence:   dimensions.SizeTable.Confidence,
 		}
 	}
 
-	h.respondJSON(w, http.StatusOK, resp)
+	// Failed extractions are not cached, a retry may succeed
+	if cacheKey != "" {
+		if data, err := json.Marshal(resp); err == nil {
+			if err := h.sizeCache.Set(ctx, cacheKey, append(data, '\n')); err != nil {
+				h.logger.WarnContext(ctx, "failed to cache size chart", "error", err, "asin", req.ASIN)
+			}
+		}
+	}
+	return resp, nil
 }
 
 // ReviewsRequest represents the request for product reviews
@@ -92,10 +235,12 @@ type ReviewsRequest struct {
 
 // ReviewsResponse represents the reviews data response
 type ReviewsResponse struct {
-	Reviews       []Review `json:"reviews"`
-	AverageRating float64  `json:"average_rating"`
-	TotalReviews  int      `json:"total_reviews"`
-	Error         string   `json:"error,omitempty"`
+	Reviews         []Review `json:"reviews"`
+	AverageRating   float64  `json:"average_rating"`
+	TotalReviews    int      `json:"total_reviews"`
+	Error           string   `json:"error,omitempty"`
+	ErrorCode       string   `json:"error_code,omitempty"`
+	FailureCategory string   `json:"failure_category,omitempty"` // timeout or error
 }
 
 type Review struct {
@@ -120,17 +265,42 @@ func (h *Handlers) GetReviews(w http.ResponseWriter, r *http.Request) {
 		h.respondError(w, http.StatusBadRequest, "either asin or url is required")
 		return
 	}
+	target, err := h.productTarget(r.Context(), req.ASIN, req.URL)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	req.ASIN = target.ASIN
+	if target.ASIN != "" && req.URL != "" {
+		// Ad redirects, ref tags and short links are replaced by the product page
+		req.URL = target.URL()
+	}
 
 	// Extract reviews data
 	reviewData, err := h.scraper.ExtractReviews(r.Context(), req.ASIN, req.URL)
+	if errors.Is(err, quota.ErrBudgetExceeded) {
+		h.respondBudgetExceeded(w, err)
+		return
+	}
+	if errors.Is(err, browser.ErrMarketplaceCooldown) {
+		h.respondCooldown(w, err)
+		return
+	}
 	if err != nil {
-		h.logger.Error("failed to extract reviews", "error", err, "asin", req.ASIN)
+		h.logger.ErrorContext(r.Context(), "failed to extract reviews", "error", err, "asin", req.ASIN)
 		h.respondJSON(w, http.StatusOK, ReviewsResponse{
-			Error: err.Error(),
+			Error:           err.Error(),
+			ErrorCode:       scrapeerr.Code(err),
+			FailureCategory: scraper.FailureCategory(err),
 		})
 		return
 	}
 
+	if req.ASIN != "" && len(reviewData.Reviews) > 0 && h.jobs.ReviewSummariesEnabled() {
+		// The summary waits for the language model, the stored reviews are summarized in the background
+		go h.summarizeReviews(context.WithoutCancel(r.Context()), req.ASIN)
+	}
+
 	// Convert to API response format
 	reviews := make([]Review, len(reviewData.Reviews))
 	for i, r := range reviewData.Reviews {
@@ -154,9 +324,11 @@ func (h *Handlers) GetReviews(w http.ResponseWriter, r *http.Request) {
 
 // CreateJobRequest represents a new scraping job request
 type CreateJobRequest struct {
-	SearchQuery string `json:"search_query"`
-	Category    string `json:"category"`
-	MaxPages    int    `json:"max_pages"`
+	SearchQuery   string              `json:"search_query"`
+	Category      string              `json:"category"`
+	MaxPages      int                 `json:"max_pages"`
+	ProductFilter *jobs.ProductFilter `json:"product_filter,omitempty"`
+	Metadata      map[string]string   `json:"metadata,omitempty"` // e.g. {"campaign_id": "summer-24"}, carried in the job's events
 }
 
 // CreateJobResponse represents the job creation response
@@ -183,18 +355,37 @@ func (h *Handlers) CreateJob(w http.ResponseWriter, r *http.Request) {
 		req.MaxPages = 10
 	}
 
+	if err := req.ProductFilter.Validate(); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := schema.ValidateMetadata(req.Metadata); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	// Create job
-	job, err := h.jobs.CreateJob(r.Context(), req.SearchQuery, req.Category, req.MaxPages)
+	job, err := h.jobs.CreateJob(r.Context(), req.SearchQuery, req.Category, req.MaxPages, req.ProductFilter, req.Metadata)
 	if err != nil {
-		h.logger.Error("failed to create job", "error", err)
+		h.logger.ErrorContext(r.Context(), "failed to create job", "error", err)
 		h.respondError(w, http.StatusInternalServerError, "failed to create job")
 		return
 	}
 
-	h.respondJSON(w, http.StatusCreated, CreateJobResponse{
+	h.respondJobCreated(w, job, "Job created successfully")
+}
+
+// respondJobCreated answers 201 for a new job and 200 when an identical job was returned instead
+func (h *Handlers) respondJobCreated(w http.ResponseWriter, job *jobs.Job, message string) {
+	status := http.StatusCreated
+	if job.Deduplicated {
+		status, message = http.StatusOK, "Identical job already exists"
+	}
+
+	h.respondJSON(w, status, CreateJobResponse{
 		JobID:   job.ID,
 		Status:  job.Status,
-		Message: "Job created successfully",
+		Message: message,
 	})
 }
 
@@ -215,12 +406,78 @@ func (h *Handlers) GetJob(w http.ResponseWriter, r *http.Request) {
 	h.respondJSON(w, http.StatusOK, job)
 }
 
-// ListJobs handles listing all jobs
+// CancelJob handles cancelling a pending or running job, like the CANCEL_JOB control command
+func (h *Handlers) CancelJob(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "jobID")
+	if _, err := uuid.Parse(jobID); err != nil {
+		h.respondError(w, http.StatusBadRequest, "job ID must be a UUID")
+		return
+	}
+
+	job, err := h.jobs.CancelJob(r.Context(), jobID)
+	if errors.Is(err, jobs.ErrJobNotFound) {
+		h.respondError(w, http.StatusNotFound, "job not found")
+		return
+	}
+	if errors.Is(err, jobs.ErrJobFinished) {
+		h.respondError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to cancel job", "error", err, "id", jobID)
+		h.respondError(w, http.StatusInternalServerError, "failed to cancel job")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, job)
+}
+
+// GetJobReport handles downloading the summary report of a job, ?format=html (default) or pdf
+func (h *Handlers) GetJobReport(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "jobID")
+	if jobID == "" {
+		h.respondError(w, http.StatusBadRequest, "job ID is required")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = jobs.ReportHTML
+	}
+	if format != jobs.ReportHTML && format != jobs.ReportPDF {
+		h.respondError(w, http.StatusBadRequest, "format must be html or pdf")
+		return
+	}
+
+	job, err := h.jobs.GetJob(r.Context(), jobID)
+	if err != nil {
+		h.respondError(w, http.StatusNotFound, "job not found")
+		return
+	}
+
+	data, err := h.jobs.Report(r.Context(), job, format)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to generate job report", "job_id", jobID, "format", format, "error", err)
+		h.respondError(w, http.StatusInternalServerError, "failed to generate report")
+		return
+	}
+
+	contentType := "text/html; charset=utf-8"
+	if format == jobs.ReportPDF {
+		contentType = "application/pdf"
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"job-%s.pdf\"", job.ID))
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// ListJobs handles listing all jobs, optionally only those created from ?template_id=
 func (h *Handlers) ListJobs(w http.ResponseWriter, r *http.Request) {
 	// TODO: Add pagination
-	jobs, err := h.jobs.ListJobs(r.Context())
+	jobs, err := h.jobs.ListJobs(r.Context(), r.URL.Query().Get("template_id"))
 	if err != nil {
-		h.logger.Error("failed to list jobs", "error", err)
+		h.logger.ErrorContext(r.Context(), "failed to list jobs", "error", err)
 		h.respondError(w, http.StatusInternalServerError, "failed to list jobs")
 		return
 	}
@@ -228,6 +485,222 @@ func (h *Handlers) ListJobs(w http.ResponseWriter, r *http.Request) {
 	h.respondJSON(w, http.StatusOK, jobs)
 }
 
+// CreateTemplate handles saving a new job template
+func (h *Handlers) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	var t jobs.Template
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	template, err := h.jobs.CreateTemplate(r.Context(), &t)
+	if err != nil {
+		h.respondTemplateError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, template)
+}
+
+// ListTemplates handles listing all job templates
+func (h *Handlers) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := h.jobs.ListTemplates(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to list job templates", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "failed to list job templates")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, templates)
+}
+
+// GetTemplate handles job template retrieval
+func (h *Handlers) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	template, err := h.jobs.GetTemplate(r.Context(), chi.URLParam(r, "templateID"))
+	if err != nil {
+		h.respondTemplateError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, template)
+}
+
+// UpdateTemplate handles replacing a job template
+func (h *Handlers) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	var t jobs.Template
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	t.ID = chi.URLParam(r, "templateID")
+
+	template, err := h.jobs.UpdateTemplate(r.Context(), &t)
+	if err != nil {
+		h.respondTemplateError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, template)
+}
+
+// DeleteTemplate handles job template deletion
+func (h *Handlers) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	if err := h.jobs.DeleteTemplate(r.Context(), chi.URLParam(r, "templateID")); err != nil {
+		h.respondTemplateError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RunTemplate handles creating a job from a template
+func (h *Handlers) RunTemplate(w http.ResponseWriter, r *http.Request) {
+	job, err := h.jobs.RunTemplate(r.Context(), chi.URLParam(r, "templateID"))
+	if err != nil {
+		h.respondTemplateError(w, err)
+		return
+	}
+
+	h.respondJobCreated(w, job, "Job created from template")
+}
+
+// respondTemplateError maps job template errors to HTTP status codes
+func (h *Handlers) respondTemplateError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, jobs.ErrTemplateNotFound):
+		h.respondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, jobs.ErrTemplateExists):
+		h.respondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, jobs.ErrInvalidTemplate):
+		h.respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		h.logger.Error("job template request failed", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "job template request failed")
+	}
+}
+
+// TaxonomyResponse lists the internal category codes and the mappings that assign them
+type TaxonomyResponse struct {
+	Categories []string           `json:"categories"`
+	Mappings   []taxonomy.Mapping `json:"mappings"`
+}
+
+// GetTaxonomy handles listing the category codes with the stored and built-in mappings
+func (h *Handlers) GetTaxonomy(w http.ResponseWriter, r *http.Request) {
+	mappings, err := h.jobs.ListCategoryMappings(r.Context())
+	if err != nil {
+		h.respondTaxonomyError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, TaxonomyResponse{Categories: taxonomy.Categories, Mappings: mappings})
+}
+
+// SaveCategoryMapping handles creating or replacing a category mapping
+func (h *Handlers) SaveCategoryMapping(w http.ResponseWriter, r *http.Request) {
+	var mapping taxonomy.Mapping
+	if err := json.NewDecoder(r.Body).Decode(&mapping); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.jobs.SaveCategoryMapping(r.Context(), &mapping); err != nil {
+		h.respondTaxonomyError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, mapping)
+}
+
+// DeleteCategoryMapping handles removing a stored category mapping
+func (h *Handlers) DeleteCategoryMapping(w http.ResponseWriter, r *http.Request) {
+	if err := h.jobs.DeleteCategoryMapping(r.Context(), chi.URLParam(r, "kind"), chi.URLParam(r, "value")); err != nil {
+		h.respondTaxonomyError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemapCategories handles applying the current mappings to all stored products
+func (h *Handlers) RemapCategories(w http.ResponseWriter, r *http.Request) {
+	changed, err := h.jobs.RemapCategories(r.Context())
+	if err != nil {
+		h.respondTaxonomyError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]int{"changed": changed})
+}
+
+// respondTaxonomyError maps category mapping errors to HTTP status codes
+func (h *Handlers) respondTaxonomyError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, jobs.ErrMappingNotFound):
+		h.respondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, jobs.ErrInvalidMapping):
+		h.respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		h.logger.Error("taxonomy request failed", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "taxonomy request failed")
+	}
+}
+
+// BrandAliasesResponse lists the stored brand aliases
+type BrandAliasesResponse struct {
+	Aliases []brand.Alias `json:"aliases"`
+}
+
+// ListBrandAliases handles listing the brand aliases
+func (h *Handlers) ListBrandAliases(w http.ResponseWriter, r *http.Request) {
+	aliases, err := h.jobs.ListBrandAliases(r.Context())
+	if err != nil {
+		h.respondBrandError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, BrandAliasesResponse{Aliases: aliases})
+}
+
+// SaveBrandAlias handles creating or replacing a brand alias
+func (h *Handlers) SaveBrandAlias(w http.ResponseWriter, r *http.Request) {
+	var alias brand.Alias
+	if err := json.NewDecoder(r.Body).Decode(&alias); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.jobs.SaveBrandAlias(r.Context(), &alias); err != nil {
+		h.respondBrandError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, alias)
+}
+
+// DeleteBrandAlias handles removing a brand alias
+func (h *Handlers) DeleteBrandAlias(w http.ResponseWriter, r *http.Request) {
+	if err := h.jobs.DeleteBrandAlias(r.Context(), chi.URLParam(r, "alias")); err != nil {
+		h.respondBrandError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// respondBrandError maps brand alias errors to HTTP status codes
+func (h *Handlers) respondBrandError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, jobs.ErrAliasNotFound):
+		h.respondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, jobs.ErrInvalidAlias):
+		h.respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		h.logger.Error("brand alias request failed", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "brand alias request failed")
+	}
+}
+
 // GetJobProducts handles retrieving products found by a job
 func (h *Handlers) GetJobProducts(w http.ResponseWriter, r *http.Request) {
 	jobID := chi.URLParam(r, "jobID")
@@ -238,7 +711,7 @@ func (h *Handlers) GetJobProducts(w http.ResponseWriter, r *http.Request) {
 
 	products, err := h.jobs.GetJobProducts(r.Context(), jobID)
 	if err != nil {
-		h.logger.Error("failed to get job products", "error", err)
+		h.logger.ErrorContext(r.Context(), "failed to get job products", "error", err)
 		h.respondError(w, http.StatusInternalServerError, "failed to get products")
 		return
 	}
@@ -246,11 +719,756 @@ func (h *Handlers) GetJobProducts(w http.ResponseWriter, r *http.Request) {
 	h.respondJSON(w, http.StatusOK, products)
 }
 
+// sseKeepAlive is how often an idle event stream sends a comment so proxies keep it open
+const sseKeepAlive = 15 * time.Second
+
+// StreamJobEvents streams the progress of a job as Server-Sent Events. The first event is a snapshot
+// of the job, followed by page_completed, product_saved, product_skipped and job_requeued events
+// until job_finished ends the stream. Live events only come from jobs run by this instance's worker.
+func (h *Handlers) StreamJobEvents(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "jobID")
+	if jobID == "" {
+		h.respondError(w, http.StatusBadRequest, "job ID is required")
+		return
+	}
+
+	// Subscribe before reading the snapshot so no event in between is lost
+	events, unsubscribe := h.jobs.SubscribeProgress(jobID)
+	defer unsubscribe()
+
+	job, err := h.jobs.GetJob(r.Context(), jobID)
+	if err != nil {
+		h.respondError(w, http.StatusNotFound, "job not found")
+		return
+	}
+
+	// The stream outlives the server's write timeout
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	id := 0
+	send := func(ev jobs.ProgressEvent) bool {
+		data, err := json.Marshal(ev)
+		if err != nil {
+			h.logger.ErrorContext(r.Context(), "failed to marshal job event", "error", err)
+			return true
+		}
+		id++
+		if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", id, ev.Type, data); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+
+	snapshot := jobs.ProgressEvent{
+		Type:             jobs.EventSnapshot,
+		JobID:            job.ID,
+		Status:           job.Status,
+		ProductsFound:    job.ProductsFound,
+		ProductsFiltered: job.ProductsFiltered,
+		Job:              job,
+		Time:             time.Now(),
+	}
+	if !send(snapshot) {
+		return
+	}
+	if job.Status == "completed" || job.Status == "failed" {
+		send(jobs.ProgressEvent{Type: jobs.EventJobFinished, JobID: job.ID, Status: job.Status, Error: job.Error, Time: time.Now()})
+		return
+	}
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev := <-events:
+			if !send(ev) || ev.Type == jobs.EventJobFinished {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil || rc.Flush() != nil {
+				return
+			}
+		}
+	}
+}
+
+// ImportRequest is a JSON ASIN list import, either asins or url must be set
+type ImportRequest struct {
+	ASINs       []string `json:"asins"`       // ASINs or product URLs
+	URL         string   `json:"url"`         // CSV, URL list or Google Sheets link to fetch
+	Source      string   `json:"source"`      // Name recorded on the import job, defaults to url
+	Marketplace string   `json:"marketplace"` // Defaults to amazon.de
+	CreateJob   bool     `json:"create_job"`
+}
+
+// ImportResponse reports the outcome of an import
+type ImportResponse struct {
+	JobID      string             `json:"job_id,omitempty"`
+	Created    int                `json:"created"`
+	Existing   int                `json:"existing"`
+	Duplicates int                `json:"duplicates"`
+	Invalid    []importer.Invalid `json:"invalid,omitempty"`
+}
+
+// maxImportBody limits uploaded ASIN lists
+const maxImportBody = 10 << 20
+
+// ImportProducts creates pending products from an ASIN list. JSON bodies follow ImportRequest, a
+// text/csv or text/plain body is parsed as list with ?source=, ?marketplace= and ?create_job=true.
+func (h *Handlers) ImportProducts(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBody)
+
+	var req ImportRequest
+	var list *importer.List
+	var err error
+
+	if mediaType := strings.TrimSpace(strings.Split(r.Header.Get("Content-Type"), ";")[0]); mediaType == "text/csv" || mediaType == "text/plain" {
+		query := r.URL.Query()
+		req.Source = query.Get("source")
+		req.Marketplace = query.Get("marketplace")
+		req.CreateJob, _ = strconv.ParseBool(query.Get("create_job"))
+		if list, err = importer.Parse(r.Body); err != nil {
+			h.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	} else {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.respondError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+
+		switch {
+		case len(req.ASINs) > 0:
+			list = importer.FromValues(req.ASINs)
+		case strings.HasPrefix(req.URL, "http://") || strings.HasPrefix(req.URL, "https://"):
+			list, err = h.fetchImportList(r, req.URL)
+			if err != nil {
+				h.respondError(w, http.StatusBadGateway, err.Error())
+				return
+			}
+			if req.Source == "" {
+				req.Source = req.URL
+			}
+		default:
+			h.respondError(w, http.StatusBadRequest, "asins or an http(s) url is required")
+			return
+		}
+	}
+
+	list.ResolveShortLinks(r.Context(), h.resolver)
+
+	if len(list.ASINs) == 0 {
+		h.respondJSON(w, http.StatusUnprocessableEntity, ImportResponse{Duplicates: list.Duplicates, Invalid: list.Invalid})
+		return
+	}
+	if req.Source == "" {
+		req.Source = "api"
+	}
+
+	job, result, err := h.jobs.ImportProducts(r.Context(), req.Source, req.Marketplace, list.ASINs, req.CreateJob)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to import products", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "failed to import products")
+		return
+	}
+
+	resp := ImportResponse{
+		Created:    result.Created,
+		Existing:   result.Existing,
+		Duplicates: list.Duplicates,
+		Invalid:    list.Invalid,
+	}
+	if job != nil {
+		resp.JobID = job.ID
+		setAuditResource(r.Context(), job.ID)
+	}
+	h.respondJSON(w, http.StatusCreated, resp)
+}
+
+// fetchImportList downloads and parses the list at url
+func (h *Handlers) fetchImportList(r *http.Request, url string) (*importer.List, error) {
+	body, err := importer.Open(r.Context(), url)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return importer.Parse(io.LimitReader(body, maxImportBody))
+}
+
+// SizeTableImportResponse is the stored size table of an import, or the validation report of a rejected one
+type SizeTableImportResponse struct {
+	ASIN             string                     `json:"asin"`
+	SizeTable        *database.SizeTable        `json:"size_table,omitempty"`
+	ValidationReport *database.ValidationReport `json:"validation_report,omitempty"`
+	Error            string                     `json:"error,omitempty"`
+}
+
+// ImportSizeTable replaces the size table of a product with a corrected CSV or XLSX table, sent as
+// body or as "file" field of a multipart form. The table is read like a size chart: sizes in the
+// header row and measurements in rows, or the other way round.
+func (h *Handlers) ImportSizeTable(w http.ResponseWriter, r *http.Request) {
+	id, ok := asin.Normalize(chi.URLParam(r, "asin"))
+	if !ok {
+		h.respondError(w, http.StatusBadRequest, "invalid asin")
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBody)
+
+	body := io.Reader(r.Body)
+	if mediaType := strings.TrimSpace(strings.Split(r.Header.Get("Content-Type"), ";")[0]); mediaType == "multipart/form-data" {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "file is required")
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	table, err := importer.ReadTable(body)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	st, report, err := h.jobs.ImportSizeTable(r.Context(), id, table)
+	switch {
+	case errors.Is(err, jobs.ErrInvalidSizeTable):
+		h.respondJSON(w, http.StatusUnprocessableEntity, SizeTableImportResponse{ASIN: id, ValidationReport: report, Error: err.Error()})
+		return
+	case errors.Is(err, database.ErrProductNotFound):
+		h.respondError(w, http.StatusNotFound, "product not found")
+		return
+	case err != nil && st == nil:
+		h.logger.ErrorContext(r.Context(), "failed to import size table", "asin", id, "error", err)
+		h.respondError(w, http.StatusInternalServerError, "failed to import size table")
+		return
+	case err != nil:
+		// The table is stored, only the event is missing
+		h.logger.ErrorContext(r.Context(), "failed to publish size table update", "asin", id, "error", err)
+	}
+
+	h.respondJSON(w, http.StatusOK, SizeTableImportResponse{ASIN: id, SizeTable: st, ValidationReport: report})
+}
+
+// ProductResponse represents a product with its failure diagnostics
+type ProductResponse struct {
+	ASIN          string               `json:"asin"`
+	Title         string               `json:"title"`
+	URL           string               `json:"url"`
+	Status        string               `json:"status"`
+	Category      string               `json:"category,omitempty"`
+	CategoryCode  string               `json:"category_code,omitempty"` // Internal category, see GET /taxonomy
+	Error         string               `json:"error,omitempty"`
+	Diagnostics   *browser.Diagnostics `json:"diagnostics,omitempty"`
+	ScreenshotURL string               `json:"screenshot_url,omitempty"`
+	CanonicalASIN string               `json:"canonical_asin,omitempty"` // Set when the product duplicates another ASIN
+	SizePrices    json.RawMessage      `json:"size_prices,omitempty"`    // Price and availability per size
+	Provenance    json.RawMessage      `json:"provenance,omitempty"`     // Extraction source and time per major field
+	Attributes    json.RawMessage      `json:"attributes,omitempty"`     // Product overview attributes, e.g. material and fit
+	Metadata      json.RawMessage      `json:"metadata,omitempty"`       // Caller metadata of the jobs and requests that stored it
+}
+
+// GetProduct handles retrieving a product including failure diagnostics
+func (h *Handlers) GetProduct(w http.ResponseWriter, r *http.Request) {
+	asin := chi.URLParam(r, "asin")
+	if asin == "" {
+		h.respondError(w, http.StatusBadRequest, "asin is required")
+		return
+	}
+
+	product, err := h.scraper.GetProduct(r.Context(), asin)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to get product", "error", err, "asin", asin)
+		h.respondError(w, http.StatusInternalServerError, "failed to get product")
+		return
+	}
+	if product == nil {
+		h.respondError(w, http.StatusNotFound, "product not found")
+		return
+	}
+
+	resp := ProductResponse{
+		ASIN:   product.ASIN,
+		Title:  product.Title,
+		URL:    product.URL,
+		Status:       string(product.Status),
+		Category:     product.Category.String,
+		CategoryCode: product.CategoryCode.String,
+		Error:        product.ErrorMessage.String,
+	}
+	if len(product.SizePrices) > 0 {
+		resp.SizePrices = product.SizePrices
+	}
+	if len(product.Provenance) > 0 {
+		resp.Provenance = product.Provenance
+	}
+	if len(product.Attributes) > 0 {
+		resp.Attributes = product.Attributes
+	}
+	if len(product.Metadata) > 0 {
+		resp.Metadata = product.Metadata
+	}
+	if product.Screenshot.Valid || product.DOMSnippet.Valid {
+		resp.Diagnostics = &browser.Diagnostics{
+			ScreenshotPath: product.Screenshot.String,
+			DOMSnippetPath: product.DOMSnippet.String,
+		}
+	}
+	if product.Screenshot.Valid {
+		resp.ScreenshotURL = fmt.Sprintf("%s/screenshot", r.URL.Path)
+	}
+	if group, err := h.scraper.GetProductGroup(r.Context(), asin); err != nil {
+		h.logger.WarnContext(r.Context(), "failed to get product group", "error", err, "asin", asin)
+	} else if group.CanonicalASIN != asin {
+		resp.CanonicalASIN = group.CanonicalASIN
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+// ResolveRequest asks for the product a link points to
+type ResolveRequest struct {
+	URL string `json:"url"`
+}
+
+// ResolveResponse is the ASIN and marketplace of a product link and its canonical product page
+type ResolveResponse struct {
+	ASIN        string `json:"asin"`
+	Marketplace string `json:"marketplace"`
+	URL         string `json:"url"`
+}
+
+// ResolveProductURL handles resolving /gp/product/, ref-tagged, sponsored and short links of any
+// marketplace to an ASIN and marketplace, without visiting the product
+func (h *Handlers) ResolveProductURL(w http.ResponseWriter, r *http.Request) {
+	var req ResolveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.URL == "" {
+		h.respondError(w, http.StatusBadRequest, "url is required")
+		return
+	}
+
+	target, err := h.resolver.ResolveProduct(r.Context(), req.URL, asin.DefaultMarketplace)
+	if err != nil {
+		h.respondError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	h.respondJSON(w, http.StatusOK, ResolveResponse{ASIN: target.ASIN, Marketplace: target.Marketplace, URL: target.URL()})
+}
+
+// GetProductGroup handles retrieving the canonical ASIN and duplicates of a product
+func (h *Handlers) GetProductGroup(w http.ResponseWriter, r *http.Request) {
+	asin := chi.URLParam(r, "asin")
+	if asin == "" {
+		h.respondError(w, http.StatusBadRequest, "asin is required")
+		return
+	}
+
+	group, err := h.scraper.GetProductGroup(r.Context(), asin)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to get product group", "error", err, "asin", asin)
+		h.respondError(w, http.StatusInternalServerError, "failed to get product group")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, group)
+}
+
+// ProductHistoryResponse is the current version of a product and a page of its previous versions
+type ProductHistoryResponse struct {
+	ASIN    string                    `json:"asin"`
+	Current *database.ProductVersion  `json:"current"`
+	History []database.ProductVersion `json:"history"`
+	Limit   int                       `json:"limit"`
+	Offset  int                       `json:"offset"`
+}
+
+// GetProductHistory handles retrieving the previous titles, prices, statuses and size tables of a
+// product, or with ?at=<RFC 3339 time> the version that was valid at that time
+func (h *Handlers) GetProductHistory(w http.ResponseWriter, r *http.Request) {
+	asin := chi.URLParam(r, "asin")
+	query := r.URL.Query()
+
+	if v := query.Get("at"); v != "" {
+		at, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "at must be an RFC 3339 time")
+			return
+		}
+		version, err := h.scraper.GetProductVersionAt(r.Context(), asin, at)
+		if err != nil {
+			h.logger.ErrorContext(r.Context(), "failed to get product version", "error", err, "asin", asin)
+			h.respondError(w, http.StatusInternalServerError, "failed to get product version")
+			return
+		}
+		if version == nil {
+			h.respondError(w, http.StatusNotFound, "product did not exist at that time")
+			return
+		}
+		h.respondJSON(w, http.StatusOK, version)
+		return
+	}
+
+	limit, offset := 50, 0
+	var err error
+	if v := query.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 || limit > 500 {
+			h.respondError(w, http.StatusBadRequest, "limit must be between 1 and 500")
+			return
+		}
+	}
+	if v := query.Get("offset"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+			h.respondError(w, http.StatusBadRequest, "offset must be a non-negative integer")
+			return
+		}
+	}
+
+	current, history, err := h.scraper.GetProductHistory(r.Context(), asin, limit, offset)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to get product history", "error", err, "asin", asin)
+		h.respondError(w, http.StatusInternalServerError, "failed to get product history")
+		return
+	}
+	if current == nil {
+		h.respondError(w, http.StatusNotFound, "product not found")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, ProductHistoryResponse{ASIN: asin, Current: current, History: history, Limit: limit, Offset: offset})
+}
+
+// summarizeReviews runs the review summary stage for a product whose reviews were just stored
+func (h *Handlers) summarizeReviews(ctx context.Context, asin string) {
+	if _, err := h.jobs.SummarizeReviews(ctx, asin); err != nil {
+		h.logger.WarnContext(ctx, "failed to summarize reviews", "error", err, "asin", asin)
+	}
+}
+
+// GetFitSummary handles retrieving the fit summary derived from the reviews of a product
+func (h *Handlers) GetFitSummary(w http.ResponseWriter, r *http.Request) {
+	asin := chi.URLParam(r, "asin")
+
+	summary, err := h.jobs.FitSummary(r.Context(), asin)
+	if err != nil {
+		h.respondFitSummaryError(w, err)
+		return
+	}
+	if summary == nil {
+		h.respondError(w, http.StatusNotFound, "fit summary not found")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, summary)
+}
+
+// SummarizeReviews handles deriving a new fit summary from the stored reviews of a product
+func (h *Handlers) SummarizeReviews(w http.ResponseWriter, r *http.Request) {
+	summary, err := h.jobs.SummarizeReviews(r.Context(), chi.URLParam(r, "asin"))
+	if err != nil && summary == nil {
+		h.respondFitSummaryError(w, err)
+		return
+	}
+	if err != nil {
+		// The summary is stored, only the event is missing
+		h.logger.ErrorContext(r.Context(), "failed to publish fit summary", "error", err)
+	}
+
+	h.respondJSON(w, http.StatusOK, summary)
+}
+
+// respondFitSummaryError maps review summary errors to HTTP status codes
+func (h *Handlers) respondFitSummaryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, jobs.ErrSummariesDisabled):
+		h.respondError(w, http.StatusServiceUnavailable, err.Error())
+	case errors.Is(err, reviewsummary.ErrNoReviews):
+		h.respondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, reviewsummary.ErrInvalidSummary):
+		h.logger.Error("language model returned an invalid fit summary", "error", err)
+		h.respondError(w, http.StatusBadGateway, err.Error())
+	default:
+		h.logger.Error("fit summary request failed", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "fit summary request failed")
+	}
+}
+
+// GetProductScreenshot serves the screenshot captured when a product failed
+func (h *Handlers) GetProductScreenshot(w http.ResponseWriter, r *http.Request) {
+	asin := chi.URLParam(r, "asin")
+
+	product, err := h.scraper.GetProduct(r.Context(), asin)
+	if err != nil || product == nil || !product.Screenshot.Valid {
+		h.respondError(w, http.StatusNotFound, "screenshot not found")
+		return
+	}
+
+	// Only serve files from the diagnostics directory
+	dir, err := filepath.Abs(h.scraper.GetBrowser().DiagnosticsDir())
+	if err != nil || dir == "" {
+		h.respondError(w, http.StatusNotFound, "screenshot not found")
+		return
+	}
+	path, err := filepath.Abs(product.Screenshot.String)
+	if err != nil {
+		h.respondError(w, http.StatusNotFound, "screenshot not found")
+		return
+	}
+	if rel, err := filepath.Rel(dir, path); err != nil || strings.HasPrefix(rel, "..") {
+		h.respondError(w, http.StatusForbidden, "screenshot outside diagnostics directory")
+		return
+	}
+
+	http.ServeFile(w, r, path)
+}
+
+// maxScreenshotURLTTL caps the lifetime of signed screenshot URLs
+const maxScreenshotURLTTL = 7 * 24 * time.Hour
+
+// ScreenshotSignRequest asks for a signed screenshot URL of a product
+type ScreenshotSignRequest struct {
+	ASIN       string `json:"asin"`
+	URL        string `json:"url"`
+	Region     string `json:"region"`      // viewport (default) or full
+	Format     string `json:"format"`      // png (default) or webp
+	TTLSeconds int    `json:"ttl_seconds"` // 0 uses the configured default
+}
+
+// ScreenshotSignResponse is a signed screenshot URL, relative to the API host
+type ScreenshotSignResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// screenshotOptions validates the region and format parameters of a screenshot
+func screenshotOptions(region, format string) (browser.ScreenshotOptions, error) {
+	var opts browser.ScreenshotOptions
+	switch region {
+	case "", "viewport":
+	case "full":
+		opts.FullPage = true
+	default:
+		return opts, fmt.Errorf("invalid region %q, use viewport or full", region)
+	}
+	switch format {
+	case "", browser.ScreenshotPNG:
+		opts.Format = browser.ScreenshotPNG
+	case browser.ScreenshotWebP:
+		opts.Format = browser.ScreenshotWebP
+	default:
+		return opts, fmt.Errorf("invalid format %q, use png or webp", format)
+	}
+	return opts, nil
+}
+
+// SignScreenshotURL handles requests for signed screenshot URLs that can be opened without an API key
+func (h *Handlers) SignScreenshotURL(w http.ResponseWriter, r *http.Request) {
+	if h.screenshotSigner == nil {
+		h.respondError(w, http.StatusNotFound, "screenshot service not configured")
+		return
+	}
+
+	var req ScreenshotSignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	target, err := h.productTarget(r.Context(), req.ASIN, req.URL)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	id := target.ASIN
+	if id == "" {
+		h.respondError(w, http.StatusBadRequest, "either asin or url is required")
+		return
+	}
+	if _, err := screenshotOptions(req.Region, req.Format); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ttl := h.screenshotTTL
+	if req.TTLSeconds > 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+	ttl = min(ttl, maxScreenshotURLTTL)
+
+	query := url.Values{"asin": {id}}
+	if req.Region != "" {
+		query.Set("region", req.Region)
+	}
+	if req.Format != "" {
+		query.Set("format", req.Format)
+	}
+	path := strings.TrimSuffix(r.URL.Path, "/sign")
+	signed := h.screenshotSigner.Sign(path, query, ttl)
+	expires, _ := strconv.ParseInt(signed.Get(signedurl.ExpiresParam), 10, 64)
+
+	h.respondJSON(w, http.StatusOK, ScreenshotSignResponse{
+		URL:       path + "?" + signed.Encode(),
+		ExpiresAt: time.Unix(expires, 0).UTC(),
+	})
+}
+
+// GetScreenshot serves a PNG or WebP screenshot of a product for a signed URL, navigating to the
+// product unless a fresh screenshot is archived
+func (h *Handlers) GetScreenshot(w http.ResponseWriter, r *http.Request) {
+	if h.screenshotSigner == nil {
+		h.respondError(w, http.StatusNotFound, "screenshot service not configured")
+		return
+	}
+
+	query := r.URL.Query()
+	if err := h.screenshotSigner.Verify(r.URL.Path, query); err != nil {
+		h.respondError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	id, ok := asin.Normalize(query.Get("asin"))
+	if !ok {
+		h.respondError(w, http.StatusBadRequest, "invalid asin")
+		return
+	}
+	opts, err := screenshotOptions(query.Get("region"), query.Get("format"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	shot, err := h.scraper.ProductScreenshot(r.Context(), id, opts)
+	switch {
+	case errors.Is(err, quota.ErrBudgetExceeded):
+		h.respondBudgetExceeded(w, err)
+		return
+	case errors.Is(err, browser.ErrMarketplaceCooldown):
+		h.respondCooldown(w, err)
+		return
+	case errors.Is(err, browser.ErrUnsupportedScreenshotFormat):
+		h.respondError(w, http.StatusNotImplemented, err.Error())
+		return
+	case err != nil:
+		h.logger.ErrorContext(r.Context(), "failed to capture screenshot", "error", err, "asin", id)
+		h.respondError(w, http.StatusBadGateway, "failed to capture screenshot")
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/"+shot.Format)
+	w.Header().Set("Cache-Control", "private, no-transform")
+	w.Header().Set("Last-Modified", shot.CapturedAt.UTC().Format(http.TimeFormat))
+	w.Header().Set("X-Screenshot-Archived", strconv.FormatBool(shot.Archived))
+	w.Write(shot.Image)
+}
+
+// exportPage reads the limit and offset of an export request, responding with an error if they are invalid
+func (h *Handlers) exportPage(w http.ResponseWriter, query url.Values) (limit, offset int, ok bool) {
+	limit = 1000
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > 10000 {
+			h.respondError(w, http.StatusBadRequest, "limit must be between 1 and 10000")
+			return 0, 0, false
+		}
+		limit = n
+	}
+	if v := query.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			h.respondError(w, http.StatusBadRequest, "offset must be a non-negative integer")
+			return 0, 0, false
+		}
+		offset = n
+	}
+	return limit, offset, true
+}
+
+// ExportSizeMeasurements exports normalized per-size rows as JSON or CSV (?format=csv)
+func (h *Handlers) ExportSizeMeasurements(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit, offset, ok := h.exportPage(w, query)
+	if !ok {
+		return
+	}
+
+	rows, err := h.scraper.ListSizeMeasurements(r.Context(), query.Get("asin"), limit, offset)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to list size measurements", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "failed to list size measurements")
+		return
+	}
+
+	if query.Get("format") != "csv" {
+		h.respondJSON(w, http.StatusOK, rows)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="size_measurements.csv"`)
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"asin", "size_label", "canonical_size", "measurement", "value_cm", "interpolated"})
+	for _, row := range rows {
+		cw.Write([]string{
+			row.ASIN,
+			row.SizeLabel,
+			row.CanonicalSize,
+			row.Measurement,
+			strconv.FormatFloat(row.ValueCM, 'f', 1, 64),
+			strconv.FormatBool(row.Interpolated),
+		})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to write csv", "error", err)
+	}
+}
+
+// ExportSizeConversions exports international size mappings as JSON or CSV (?format=csv), filtered
+// by ?asin= and ?system=
+func (h *Handlers) ExportSizeConversions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit, offset, ok := h.exportPage(w, query)
+	if !ok {
+		return
+	}
+
+	rows, err := h.scraper.ListSizeConversions(r.Context(), query.Get("asin"), strings.ToUpper(query.Get("system")), limit, offset)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to list size conversions", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "failed to list size conversions")
+		return
+	}
+
+	if query.Get("format") != "csv" {
+		h.respondJSON(w, http.StatusOK, rows)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="size_conversions.csv"`)
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"asin", "size_label", "canonical_size", "system", "size"})
+	for _, row := range rows {
+		cw.Write([]string{row.ASIN, row.SizeLabel, row.CanonicalSize, row.System, row.Size})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to write csv", "error", err)
+	}
+}
+
 // GetStats handles statistics retrieval
 func (h *Handlers) GetStats(w http.ResponseWriter, r *http.Request) {
 	stats, err := h.jobs.GetStats(r.Context())
 	if err != nil {
-		h.logger.Error("failed to get stats", "error", err)
+		h.logger.ErrorContext(r.Context(), "failed to get stats", "error", err)
 		h.respondError(w, http.StatusInternalServerError, "failed to get stats")
 		return
 	}
@@ -258,6 +1476,107 @@ func (h *Handlers) GetStats(w http.ResponseWriter, r *http.Request) {
 	h.respondJSON(w, http.StatusOK, stats)
 }
 
+// BackfillRequest selects the products whose NEW_PRODUCT_DETECTED events are re-emitted
+type BackfillRequest struct {
+	Filter     database.BackfillFilter `json:"filter"`
+	BatchSize  int                     `json:"batch_size"`  // Defaults to 100
+	ThrottleMs int                     `json:"throttle_ms"` // Pause between batches
+	Limit      int                     `json:"limit"`       // 0 is unlimited
+	After      string                  `json:"after"`       // Resume after this ASIN
+	DryRun     bool                    `json:"dry_run"`     // Count matching products, answered synchronously
+}
+
+// Backfill re-emits NEW_PRODUCT_DETECTED events from stored products. The backfill runs in the
+// background and reports its progress in the logs, a dry run returns the count directly.
+func (h *Handlers) Backfill(w http.ResponseWriter, r *http.Request) {
+	var req BackfillRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.BatchSize < 0 || req.ThrottleMs < 0 || req.Limit < 0 {
+		h.respondError(w, http.StatusBadRequest, "batch_size, throttle_ms and limit must not be negative")
+		return
+	}
+
+	opts := jobs.BackfillOptions{
+		Filter:    req.Filter,
+		BatchSize: req.BatchSize,
+		Throttle:  time.Duration(req.ThrottleMs) * time.Millisecond,
+		Limit:     req.Limit,
+		After:     req.After,
+		DryRun:    req.DryRun,
+	}
+
+	if req.DryRun {
+		result, err := h.jobs.Backfill(r.Context(), opts)
+		if err != nil {
+			h.logger.ErrorContext(r.Context(), "failed to count backfill products", "error", err)
+			h.respondError(w, http.StatusInternalServerError, "failed to count products")
+			return
+		}
+		h.respondJSON(w, http.StatusOK, result)
+		return
+	}
+
+	if !h.backfilling.CompareAndSwap(false, true) {
+		h.respondError(w, http.StatusConflict, "a backfill is already running")
+		return
+	}
+
+	// The backfill outlives the request but keeps its log correlation
+	ctx := context.WithoutCancel(r.Context())
+	go func() {
+		defer h.backfilling.Store(false)
+		result, err := h.jobs.Backfill(ctx, opts)
+		if err != nil {
+			h.logger.ErrorContext(ctx, "backfill failed", "error", err, "result", result)
+			return
+		}
+		h.logger.InfoContext(ctx, "backfill completed", "result", result)
+	}()
+
+	h.respondJSON(w, http.StatusAccepted, map[string]string{"status": "started"})
+}
+
+// GetSessionScorecard returns anti-detection signals aggregated per fingerprint and proxy
+func (h *Handlers) GetSessionScorecard(w http.ResponseWriter, r *http.Request) {
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"sessions": h.scraper.GetBrowser().SessionMetrics().Scorecard(),
+	})
+}
+
+// Metrics exposes quota usage and browser session signals in the Prometheus text format
+func (h *Handlers) Metrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	if t := h.scraper.Quota(); t != nil {
+		if err := t.WriteMetrics(r.Context(), w); err != nil {
+			h.logger.ErrorContext(r.Context(), "failed to write metrics", "error", err)
+			return
+		}
+	}
+	h.scraper.GetBrowser().SessionMetrics().WriteMetrics(w)
+	h.scraper.GetBrowser().MarketplaceBreaker().WriteMetrics(w)
+	if h.db != nil {
+		h.db.WriteMetrics(w)
+	}
+	if h.relay != nil {
+		h.relay.WriteMetrics(w)
+	}
+	if h.sizeCache != nil {
+		h.sizeCache.WriteMetrics(w)
+	}
+}
+
+// QuotaSubject charges the page fetches of a request to the API key in the X-API-Key header
+func QuotaSubject(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		subject := quota.APISubject(r.Header.Get("X-API-Key"))
+		next.ServeHTTP(w, r.WithContext(quota.WithSubject(r.Context(), subject)))
+	})
+}
+
 // Helper methods
 func (h *Handlers) respondJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
@@ -269,4 +1588,24 @@ func (h *Handlers) respondJSON(w http.ResponseWriter, status int, data interface
 
 func (h *Handlers) respondError(w http.ResponseWriter, status int, message string) {
 	h.respondJSON(w, status, map[string]string{"error": message})
-}
\ No newline at end of file
+}
+
+// respondBudgetExceeded rejects a request whose API key used up its daily fetch budget
+func (h *Handlers) respondBudgetExceeded(w http.ResponseWriter, err error) {
+	if t := h.scraper.Quota(); t != nil {
+		w.Header().Set("Retry-After", strconv.Itoa(int(t.ResetIn().Seconds())+1))
+	}
+	h.respondError(w, http.StatusTooManyRequests, err.Error())
+}
+
+// respondCooldown rejects a request for a marketplace whose circuit breaker is open
+func (h *Handlers) respondCooldown(w http.ResponseWriter, err error) {
+	wait := time.Minute
+	for _, s := range h.scraper.GetBrowser().MarketplaceBreaker().States() {
+		if s.State == browser.BreakerOpen {
+			wait = min(wait, h.scraper.GetBrowser().MarketplaceBreaker().RetryAfter(s.Marketplace))
+		}
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+	h.respondError(w, http.StatusServiceUnavailable, err.Error())
+}
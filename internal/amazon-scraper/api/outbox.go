@@ -0,0 +1,258 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+)
+
+// AdminAuth lets requests through that carry the admin token as bearer token, the audit log records them
+// as admin. Without a configured token the routes are disabled.
+func AdminAuth(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if token == "" {
+				w.WriteHeader(http.StatusForbidden)
+				json.NewEncoder(w).Encode(map[string]string{"error": "admin API is disabled, set ADMIN_TOKEN"})
+				return
+			}
+			given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				w.WriteHeader(http.StatusUnauthorized)
+				json.NewEncoder(w).Encode(map[string]string{"error": "invalid admin token"})
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), adminKey{}, true)))
+		})
+	}
+}
+
+// OutboxEventResponse is an outbox event, the payload only in the detail view
+type OutboxEventResponse struct {
+	ID            string                      `json:"id"`
+	AggregateType string                      `json:"aggregate_type"`
+	AggregateID   string                      `json:"aggregate_id"`
+	EventType     string                      `json:"event_type"`
+	TargetStream  string                      `json:"target_stream"`
+	Status        string                      `json:"status"`
+	RetryCount    int                         `json:"retry_count"`
+	LastError     *string                     `json:"last_error,omitempty"`
+	TraceID       string                      `json:"trace_id,omitempty"`
+	CreatedAt     time.Time                   `json:"created_at"`
+	ProcessedAt   *time.Time                  `json:"processed_at,omitempty"`
+	NextRetryAt   *time.Time                  `json:"next_retry_at,omitempty"`
+	PayloadSize   int                         `json:"payload_size"`
+	Payload       json.RawMessage             `json:"payload,omitempty"`
+	Errors        []database.OutboxEventError `json:"errors,omitempty"` // Every failed publish attempt
+}
+
+// OutboxEventsResponse is a page of outbox events
+type OutboxEventsResponse struct {
+	Events []OutboxEventResponse `json:"events"`
+	Total  int                   `json:"total"`
+	Limit  int                   `json:"limit"`
+	Offset int                   `json:"offset"`
+}
+
+func newOutboxEventResponse(e *database.OutboxEvent) OutboxEventResponse {
+	return OutboxEventResponse{
+		ID:            e.ID.String(),
+		AggregateType: e.AggregateType,
+		AggregateID:   e.AggregateID,
+		EventType:     e.EventType,
+		TargetStream:  e.TargetStream,
+		Status:        e.Status,
+		RetryCount:    e.RetryCount,
+		LastError:     e.ErrorMessage,
+		TraceID:       e.TraceID,
+		CreatedAt:     e.CreatedAt,
+		ProcessedAt:   e.ProcessedAt,
+		NextRetryAt:   e.NextRetryAt,
+		PayloadSize:   len(e.Payload),
+	}
+}
+
+// outboxFilter reads the filters of an outbox event listing
+func outboxFilter(query map[string][]string) (database.OutboxFilter, error) {
+	get := func(key string) string {
+		if v := query[key]; len(v) > 0 {
+			return strings.TrimSpace(v[0])
+		}
+		return ""
+	}
+
+	filter := database.OutboxFilter{
+		Status:        get("status"),
+		EventType:     get("event_type"),
+		AggregateID:   get("aggregate_id"),
+		PayloadSearch: get("q"),
+	}
+	switch filter.Status {
+	case "", database.OutboxStatusPending, database.OutboxStatusProcessed, database.OutboxStatusFailed, database.OutboxStatusDeadLetter:
+	default:
+		return filter, errors.New("status must be pending, processed, failed or dead_letter")
+	}
+
+	for key, target := range map[string]*time.Time{"created_after": &filter.CreatedAfter, "created_before": &filter.CreatedBefore} {
+		if v := get(key); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return filter, errors.New(key + " must be an RFC 3339 timestamp")
+			}
+			*target = t
+		}
+	}
+	return filter, nil
+}
+
+// ListOutboxEvents handles listing outbox events by status, event type, aggregate, creation time and
+// payload text, newest first
+func (h *Handlers) ListOutboxEvents(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil {
+		h.respondError(w, http.StatusServiceUnavailable, "outbox inspection needs a database")
+		return
+	}
+
+	query := r.URL.Query()
+	filter, err := outboxFilter(query)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	limit, offset := 50, 0
+	if v := query.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 || limit > 500 {
+			h.respondError(w, http.StatusBadRequest, "limit must be between 1 and 500")
+			return
+		}
+	}
+	if v := query.Get("offset"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+			h.respondError(w, http.StatusBadRequest, "offset must be a non-negative integer")
+			return
+		}
+	}
+
+	events, total, err := database.NewOutboxRepository(h.db).List(r.Context(), filter, limit, offset)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to list outbox events", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "failed to list outbox events")
+		return
+	}
+
+	resp := OutboxEventsResponse{Events: make([]OutboxEventResponse, len(events)), Total: total, Limit: limit, Offset: offset}
+	for i, e := range events {
+		resp.Events[i] = newOutboxEventResponse(e)
+	}
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+// GetOutboxEvent handles retrieving an outbox event with its full payload and error history
+func (h *Handlers) GetOutboxEvent(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil {
+		h.respondError(w, http.StatusServiceUnavailable, "outbox inspection needs a database")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "eventID"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "event ID must be a UUID")
+		return
+	}
+
+	event, history, err := database.NewOutboxRepository(h.db).Get(r.Context(), id)
+	if errors.Is(err, database.ErrOutboxEventNotFound) {
+		h.respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to get outbox event", "error", err, "id", id)
+		h.respondError(w, http.StatusInternalServerError, "failed to get outbox event")
+		return
+	}
+
+	resp := newOutboxEventResponse(event)
+	resp.Payload = event.Payload
+	resp.Errors = history
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+// maxReplayEvents caps the events one replay request publishes, larger replays run through "scraper replay"
+const maxReplayEvents = 10000
+
+// ReplayOutboxRequest selects processed outbox events to publish again
+type ReplayOutboxRequest struct {
+	EventType     string    `json:"event_type"`
+	AggregateIDs  []string  `json:"aggregate_ids"`
+	CreatedAfter  time.Time `json:"created_after"`
+	CreatedBefore time.Time `json:"created_before"`
+	Target        string    `json:"target"` // Empty publishes each event to its original target
+	Limit         int       `json:"limit"`  // Up to maxReplayEvents, 0 is the maximum
+	DryRun        bool      `json:"dry_run"`
+}
+
+// options validates the request, at least one filter is required so a replay never floods the targets
+// with the whole outbox by accident
+func (req ReplayOutboxRequest) options() (database.ReplayOptions, error) {
+	opts := database.ReplayOptions{
+		Filter: database.OutboxFilter{
+			EventType:     strings.TrimSpace(req.EventType),
+			AggregateIDs:  req.AggregateIDs,
+			CreatedAfter:  req.CreatedAfter,
+			CreatedBefore: req.CreatedBefore,
+		},
+		Target: strings.TrimSpace(req.Target),
+		Limit:  req.Limit,
+		DryRun: req.DryRun,
+	}
+	f := opts.Filter
+	if f.EventType == "" && len(f.AggregateIDs) == 0 && f.CreatedAfter.IsZero() && f.CreatedBefore.IsZero() {
+		return opts, errors.New("event_type, aggregate_ids, created_after or created_before is required")
+	}
+	if opts.Limit < 0 || opts.Limit > maxReplayEvents {
+		return opts, fmt.Errorf("limit must be between 0 and %d", maxReplayEvents)
+	}
+	if opts.Limit == 0 {
+		opts.Limit = maxReplayEvents
+	}
+	return opts, nil
+}
+
+// ReplayOutboxEvents handles publishing processed outbox events again, oldest first
+func (h *Handlers) ReplayOutboxEvents(w http.ResponseWriter, r *http.Request) {
+	if h.relay == nil {
+		h.respondError(w, http.StatusServiceUnavailable, "outbox replay needs the relay")
+		return
+	}
+
+	var req ReplayOutboxRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	opts, err := req.options()
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := h.relay.Replay(r.Context(), opts)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to replay outbox events", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "failed to replay outbox events: "+err.Error())
+		return
+	}
+	h.respondJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestAdminAuth(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+
+	tests := map[string]struct {
+		token  string
+		header string
+		want   int
+	}{
+		"valid token":    {"secret", "Bearer secret", http.StatusNoContent},
+		"wrong token":    {"secret", "Bearer guess", http.StatusUnauthorized},
+		"missing header": {"secret", "", http.StatusUnauthorized},
+		"disabled":       {"", "Bearer ", http.StatusForbidden},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/outbox/events", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			AdminAuth(tt.token)(ok).ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutboxFilter(t *testing.T) {
+	query, _ := url.ParseQuery("status=dead_letter&event_type=NEW_PRODUCT_DETECTED&aggregate_id=B08N5WRWNW&created_after=2024-03-01T00:00:00Z&q=size_table")
+	filter, err := outboxFilter(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if filter.Status != "dead_letter" || filter.EventType != "NEW_PRODUCT_DETECTED" || filter.AggregateID != "B08N5WRWNW" || filter.PayloadSearch != "size_table" {
+		t.Errorf("unexpected filter: %+v", filter)
+	}
+	if !filter.CreatedAfter.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) || !filter.CreatedBefore.IsZero() {
+		t.Errorf("unexpected created range: %v - %v", filter.CreatedAfter, filter.CreatedBefore)
+	}
+
+	for _, raw := range []string{"status=done", "created_before=yesterday"} {
+		query, _ := url.ParseQuery(raw)
+		if _, err := outboxFilter(query); err == nil {
+			t.Errorf("%s: expected an error", raw)
+		}
+	}
+}
+
+func TestReplayOutboxRequest_Options(t *testing.T) {
+	opts, err := ReplayOutboxRequest{EventType: " NEW_PRODUCT_DETECTED ", Target: "redis:stream:rebuild"}.options()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.Filter.EventType != "NEW_PRODUCT_DETECTED" || opts.Target != "redis:stream:rebuild" || opts.Limit != maxReplayEvents {
+		t.Errorf("unexpected options: %+v", opts)
+	}
+
+	invalid := map[string]ReplayOutboxRequest{
+		"no filter":      {Target: "redis:stream:rebuild"},
+		"negative limit": {AggregateIDs: []string{"B08N5WRWNW"}, Limit: -1},
+		"limit too high": {CreatedAfter: time.Now(), Limit: maxReplayEvents + 1},
+	}
+	for name, req := range invalid {
+		if _, err := req.options(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+
+	"github.com/maltedev/amazon-size-scraper/internal/redisconn"
 )
 
 type Config struct {
@@ -11,10 +13,16 @@ type Config struct {
 	Database DatabaseConfig
 	Redis    RedisConfig
 	Scraper  ScraperConfig
+	Events   EventsConfig
+	Chaos    ChaosConfig
+	LLM      LLMConfig
+	PAAPI    PAAPIConfig
 }
 
 type ServerConfig struct {
-	Port int
+	Port        int
+	Environment string
+	AdminToken  string // Bearer token of the admin routes, empty disables them
 }
 
 type DatabaseConfig struct {
@@ -24,26 +32,133 @@ type DatabaseConfig struct {
 	Password string
 	Name     string
 	MaxConns int32
+
+	StatementTimeout  int // Seconds
+	HealthCheckPeriod int // Seconds
+	ConnectRetries    int
+	ConnectBackoffMS  int
+
+	ReplicaDSNs     []string // Read replica connection strings
+	ReplicaCooldown int      // Seconds an unavailable replica is skipped
 }
 
 type RedisConfig struct {
-	Addr     string
-	Password string
-	DB       int
+	Conn         redisconn.Config
+	StreamMaxLen int64
+	MaxBacklog   int64
+	MaxLag       int64
 }
 
 type ScraperConfig struct {
-	Headless           bool
-	TimeoutSeconds     int
-	ConcurrentWorkers  int
-	RateLimitSeconds   int
-	MaxRetries         int
+	Headless            bool
+	TimeoutSeconds      int
+	TaskTimeoutSeconds  int
+	ConcurrentWorkers   int
+	RateLimitSeconds    int
+	ScheduleFile        string
+	NightCrawl          bool
+	MaxRetries          int
+	Marketplace         string
+	LabelsFile          string
+	DiagnosticsDir      string
+	ReportDir           string
+	ScreenshotDir       string
+	ScreenshotMaxAge    int // Seconds
+	ScreenshotSecret    string
+	ScreenshotURLTTL    int // Seconds
+	ValidationFile      string
+	OCREngine           string
+	OCRLanguages        string
+	Navigation          string
+	NavigationOverrides string
+	NavigationEscalate  bool
+	BlockResources      bool
+	ResourcePolicies    string
+	DownloadImages      bool
+	SkipStages          string
+	RequireLength       bool // Measurement policy of size tables, see database.MeasurementPolicy
+	RequireChest        bool
+	AcceptPartial       bool
+	Humanize            string
+	HumanizeProfiles    string
+	SizeChartTimeout    int
+	SizeChartWaitIdle   bool
+	SizeChartLayouts    string
+	Proxy               string
+	ProxyPool           string
+	Fingerprint         string
+	AgeGate             string
+	BreakerErrorRate    float64
+	BreakerWindow       int
+	BreakerMinRequests  int
+	BreakerCooldown     int
+	ReportingCurrency   string
+	FXRates             string
+	QuotaDailyBudget    int
+	QuotaBudgets        string
+	QuotaAction         string
+	JobDedupWindow      int // Seconds an identical search job is returned instead of creating one, 0 disables it
+	SizeChartCacheTTL   int // Seconds, 0 disables the size chart cache
+	SizeChartCacheSize  int
+	SizeChartCacheRedis bool
+
+	DebugSessions bool // Admin routes open a debug browser with remote debugging on one product page
+	DebugHeaded   bool
+	DebugPort     int
+	DebugTTL      int // Seconds
+	DebugVNCURL   string
+}
+
+type EventsConfig struct {
+	SchemaVersion      int
+	PayloadCompression string
+	CompressThreshold  int
+	MaxPayloadSize     int
+	Routes             string
+	DefaultTarget      string
+	KafkaRESTURL       string
+	WebhookTimeout     int // Seconds
+
+	RelayLeaderElection bool   // Only one instance publishes the outbox, elected with a Postgres advisory lock
+	RelayLockKey        int    // Advisory lock key, instances sharing an outbox must use the same one
+	InstanceID          string // Names this instance in relay logs, health and metrics
+
+	ControlStream  string // Redis stream of orchestrator commands, empty disables the control consumer
+	ControlGroup   string // Consumer group shared by all instances, each command is executed once
+	ControlWorkers int    // Commands executed in parallel per instance
+}
+
+// LLMConfig configures the language model used by the review summary stage, no base URL disables it
+type LLMConfig struct {
+	BaseURL string
+	APIKey  string
+	Model   string
+	Timeout int // Seconds
+}
+
+// PAAPIConfig configures the Product Advertising API fallback for blocked product pages, it is disabled
+// without credentials
+type PAAPIConfig struct {
+	AccessKey  string
+	SecretKey  string
+	PartnerTag string
+	Timeout    int // Seconds
+}
+
+type ChaosConfig struct {
+	Enabled            bool
+	PublishFailureRate float64
+	DuplicateRate      float64
+	MaxDelayMillis     int
+	Seed               int64
 }
 
 func Load() (*Config, error) {
 	cfg := &Config{
 		Server: ServerConfig{
-			Port: getEnvInt("PORT", 8084),
+			Port:        getEnvInt("PORT", 8084),
+			Environment: getEnv("APP_ENV", "development"),
+			AdminToken:  getEnv("ADMIN_TOKEN", ""),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -52,18 +167,116 @@ func Load() (*Config, error) {
 			Password: getEnv("DB_PASSWORD", ""),
 			Name:     getEnv("DB_NAME", "tall_affiliate"),
 			MaxConns: int32(getEnvInt("DB_MAX_CONNS", 20)),
+
+			StatementTimeout:  getEnvInt("DB_STATEMENT_TIMEOUT", 60),
+			HealthCheckPeriod: getEnvInt("DB_HEALTH_CHECK_PERIOD", 30),
+			ConnectRetries:    getEnvInt("DB_CONNECT_RETRIES", 5),
+			ConnectBackoffMS:  getEnvInt("DB_CONNECT_BACKOFF_MS", 1000),
+
+			ReplicaDSNs:     redisconn.SplitAddrs(getEnv("DB_REPLICA_DSNS", "")),
+			ReplicaCooldown: getEnvInt("DB_REPLICA_COOLDOWN", 30),
 		},
 		Redis: RedisConfig{
-			Addr:     getEnv("REDIS_ADDR", "localhost:6379"),
-			Password: getEnv("REDIS_PASSWORD", ""),
-			DB:       getEnvInt("REDIS_DB", 0),
+			Conn:         redisconn.FromEnv(),
+			StreamMaxLen: int64(getEnvInt("REDIS_STREAM_MAXLEN", 100000)),
+			MaxBacklog:   int64(getEnvInt("REDIS_STREAM_MAX_BACKLOG", 0)),
+			MaxLag:       int64(getEnvInt("REDIS_STREAM_MAX_LAG", 10000)),
 		},
 		Scraper: ScraperConfig{
-			Headless:          getEnvBool("SCRAPER_HEADLESS", true),
-			TimeoutSeconds:    getEnvInt("SCRAPER_TIMEOUT", 30),
-			ConcurrentWorkers: getEnvInt("SCRAPER_WORKERS", 2),
-			RateLimitSeconds:  getEnvInt("SCRAPER_RATE_LIMIT", 3),
-			MaxRetries:        getEnvInt("SCRAPER_MAX_RETRIES", 3),
+			Headless:            getEnvBool("SCRAPER_HEADLESS", true),
+			TimeoutSeconds:      getEnvInt("SCRAPER_TIMEOUT", 30),
+			TaskTimeoutSeconds:  getEnvInt("SCRAPER_TASK_TIMEOUT", 90),
+			ConcurrentWorkers:   getEnvInt("SCRAPER_WORKERS", 2),
+			RateLimitSeconds:    getEnvInt("SCRAPER_RATE_LIMIT", 3),
+			ScheduleFile:        getEnv("SCRAPER_SCHEDULE_FILE", ""),
+			NightCrawl:          getEnvBool("SCRAPER_NIGHT_CRAWL", false),
+			MaxRetries:          getEnvInt("SCRAPER_MAX_RETRIES", 3),
+			Marketplace:         getEnv("SCRAPER_MARKETPLACE", "amazon.de"),
+			LabelsFile:          getEnv("SCRAPER_LABELS_FILE", ""),
+			DiagnosticsDir:      getEnv("SCRAPER_DIAGNOSTICS_DIR", "diagnostics"),
+			ReportDir:           getEnv("SCRAPER_REPORT_DIR", "reports"),
+			ScreenshotDir:       getEnv("SCRAPER_SCREENSHOT_DIR", "screenshots"),
+			ScreenshotMaxAge:    getEnvInt("SCRAPER_SCREENSHOT_MAX_AGE", 86400),
+			ScreenshotSecret:    getEnv("SCRAPER_SCREENSHOT_SECRET", ""),
+			ScreenshotURLTTL:    getEnvInt("SCRAPER_SCREENSHOT_URL_TTL", 3600),
+			ValidationFile:      getEnv("SCRAPER_VALIDATION_FILE", ""),
+			OCREngine:           getEnv("SCRAPER_OCR_ENGINE", ""),
+			OCRLanguages:        getEnv("SCRAPER_OCR_LANGUAGES", "deu+eng"),
+			Navigation:          getEnv("SCRAPER_NAVIGATION", "direct"),
+			NavigationOverrides: getEnv("SCRAPER_NAVIGATION_OVERRIDES", ""),
+			NavigationEscalate:  getEnvBool("SCRAPER_NAVIGATION_ESCALATE", true),
+			BlockResources:      getEnvBool("SCRAPER_BLOCK_RESOURCES", true),
+			ResourcePolicies:    getEnv("SCRAPER_RESOURCE_POLICIES", ""),
+			DownloadImages:      getEnvBool("SCRAPER_DOWNLOAD_IMAGES", false),
+			SkipStages:          getEnv("SCRAPER_SKIP_STAGES", ""),
+			RequireLength:       getEnvBool("MEASUREMENT_REQUIRE_LENGTH", true),
+			RequireChest:        getEnvBool("MEASUREMENT_REQUIRE_CHEST", true),
+			AcceptPartial:       getEnvBool("MEASUREMENT_ACCEPT_PARTIAL", false),
+			Humanize:            getEnv("SCRAPER_HUMANIZE", "normal"),
+			HumanizeProfiles:    getEnv("SCRAPER_HUMANIZE_PROFILES", ""),
+			SizeChartTimeout:    getEnvInt("SCRAPER_SIZE_CHART_TIMEOUT", 10),
+			SizeChartWaitIdle:   getEnvBool("SCRAPER_SIZE_CHART_NETWORK_IDLE", false),
+			SizeChartLayouts:    getEnv("SCRAPER_SIZE_CHART_LAYOUTS", ""),
+			Proxy:               getEnv("SCRAPER_PROXY", ""),
+			ProxyPool:           getEnv("SCRAPER_PROXY_POOL", ""),
+			Fingerprint:         getEnv("SCRAPER_FINGERPRINT", ""),
+			AgeGate:             getEnv("SCRAPER_AGE_GATE", "skip"),
+			BreakerErrorRate:    getEnvFloat("SCRAPER_BREAKER_ERROR_RATE", 0.5),
+			BreakerWindow:       getEnvInt("SCRAPER_BREAKER_WINDOW", 20),
+			BreakerMinRequests:  getEnvInt("SCRAPER_BREAKER_MIN_REQUESTS", 10),
+			BreakerCooldown:     getEnvInt("SCRAPER_BREAKER_COOLDOWN", 300),
+			ReportingCurrency:   getEnv("SCRAPER_REPORTING_CURRENCY", ""),
+			FXRates:             getEnv("SCRAPER_FX_RATES", ""),
+			QuotaDailyBudget:    getEnvInt("SCRAPER_QUOTA_DAILY_BUDGET", 0),
+			QuotaBudgets:        getEnv("SCRAPER_QUOTA_BUDGETS", ""),
+			QuotaAction:         getEnv("SCRAPER_QUOTA_ACTION", "queue"),
+			JobDedupWindow:      getEnvInt("SCRAPER_JOB_DEDUP_WINDOW", 600),
+			SizeChartCacheTTL:   getEnvInt("SCRAPER_SIZE_CHART_CACHE_TTL", 600),
+			SizeChartCacheSize:  getEnvInt("SCRAPER_SIZE_CHART_CACHE_SIZE", 1000),
+			SizeChartCacheRedis: getEnvBool("SCRAPER_SIZE_CHART_CACHE_REDIS", false),
+
+			DebugSessions: getEnvBool("SCRAPER_DEBUG_SESSIONS", false),
+			DebugHeaded:   getEnvBool("SCRAPER_DEBUG_HEADED", true),
+			DebugPort:     getEnvInt("SCRAPER_DEBUG_PORT", 9222),
+			DebugTTL:      getEnvInt("SCRAPER_DEBUG_TTL", 900),
+			DebugVNCURL:   getEnv("SCRAPER_DEBUG_VNC_URL", ""),
+		},
+		Events: EventsConfig{
+			SchemaVersion:      getEnvInt("EVENT_SCHEMA_VERSION", 2),
+			PayloadCompression: getEnv("EVENT_PAYLOAD_COMPRESSION", ""),
+			CompressThreshold:  getEnvInt("EVENT_COMPRESS_THRESHOLD", 16384),
+			MaxPayloadSize:     getEnvInt("EVENT_MAX_PAYLOAD_SIZE", 1048576),
+			Routes:             getEnv("EVENT_ROUTES", ""),
+			DefaultTarget:      getEnv("EVENT_DEFAULT_TARGET", "stream:product_lifecycle"),
+			KafkaRESTURL:       getEnv("KAFKA_REST_URL", ""),
+			WebhookTimeout:     getEnvInt("EVENT_WEBHOOK_TIMEOUT", 10),
+
+			RelayLeaderElection: getEnvBool("RELAY_LEADER_ELECTION", true),
+			RelayLockKey:        getEnvInt("RELAY_LOCK_KEY", 0),
+			InstanceID:          getEnv("INSTANCE_ID", hostname()),
+
+			ControlStream:  getEnv("SCRAPER_CONTROL_STREAM", "stream:scraper_commands"),
+			ControlGroup:   getEnv("SCRAPER_CONTROL_GROUP", "scraper-control"),
+			ControlWorkers: getEnvInt("SCRAPER_CONTROL_WORKERS", 2),
+		},
+		Chaos: ChaosConfig{
+			Enabled:            getEnvBool("CHAOS_ENABLED", false),
+			PublishFailureRate: getEnvFloat("CHAOS_PUBLISH_FAILURE_RATE", 0.1),
+			DuplicateRate:      getEnvFloat("CHAOS_DUPLICATE_RATE", 0.05),
+			MaxDelayMillis:     getEnvInt("CHAOS_MAX_DELAY_MS", 2000),
+			Seed:               int64(getEnvInt("CHAOS_SEED", 0)),
+		},
+		LLM: LLMConfig{
+			BaseURL: getEnv("LLM_BASE_URL", ""),
+			APIKey:  getEnv("LLM_API_KEY", ""),
+			Model:   getEnv("LLM_MODEL", "gpt-4o-mini"),
+			Timeout: getEnvInt("LLM_TIMEOUT", 60),
+		},
+		PAAPI: PAAPIConfig{
+			AccessKey:  getEnv("PAAPI_ACCESS_KEY", ""),
+			SecretKey:  getEnv("PAAPI_SECRET_KEY", ""),
+			PartnerTag: getEnv("PAAPI_PARTNER_TAG", ""),
+			Timeout:    getEnvInt("PAAPI_TIMEOUT", 10),
 		},
 	}
 
@@ -87,13 +300,138 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("database name is required")
 	}
 
+	if c.Database.StatementTimeout < 0 || c.Database.ConnectRetries < 0 {
+		return fmt.Errorf("database statement timeout and connect retries must not be negative")
+	}
+
+	if c.Database.ReplicaCooldown < 0 {
+		return fmt.Errorf("database replica cooldown must not be negative")
+	}
+
 	if c.Scraper.ConcurrentWorkers < 1 {
 		return fmt.Errorf("at least 1 concurrent worker is required")
 	}
 
+	if c.Scraper.TaskTimeoutSeconds < 0 {
+		return fmt.Errorf("task timeout must not be negative")
+	}
+
+	if c.Scraper.SizeChartTimeout < 1 {
+		return fmt.Errorf("size chart timeout must be at least 1 second")
+	}
+
+	switch c.Scraper.Humanize {
+	case "off", "conservative", "normal", "aggressive":
+	default:
+		return fmt.Errorf("unsupported humanize profile: %s", c.Scraper.Humanize)
+	}
+
+	if c.Scraper.AgeGate != "skip" && c.Scraper.AgeGate != "bypass" {
+		return fmt.Errorf("age gate must be skip or bypass: %s", c.Scraper.AgeGate)
+	}
+	if c.Scraper.ScreenshotMaxAge < 0 {
+		return fmt.Errorf("screenshot max age must not be negative")
+	}
+
+	if c.LLM.BaseURL != "" && (c.LLM.Model == "" || c.LLM.Timeout < 1) {
+		return fmt.Errorf("llm model is required and llm timeout must be at least 1 second")
+	}
+
+	if (c.PAAPI.AccessKey != "") != (c.PAAPI.SecretKey != "") || (c.PAAPI.AccessKey != "" && c.PAAPI.PartnerTag == "") {
+		return fmt.Errorf("pa-api fallback needs access key, secret key and partner tag")
+	}
+
+	if c.Scraper.ScreenshotURLTTL < 1 {
+		return fmt.Errorf("screenshot url ttl must be at least 1 second")
+	}
+
+	if c.Scraper.BreakerErrorRate < 0 || c.Scraper.BreakerErrorRate > 1 {
+		return fmt.Errorf("breaker error rate must be between 0 and 1: %v", c.Scraper.BreakerErrorRate)
+	}
+
+	if c.Scraper.BreakerCooldown < 0 {
+		return fmt.Errorf("breaker cooldown must not be negative")
+	}
+
+	if c.Scraper.QuotaDailyBudget < 0 {
+		return fmt.Errorf("daily fetch budget must not be negative")
+	}
+
+	switch c.Scraper.QuotaAction {
+	case "reject", "queue":
+	default:
+		return fmt.Errorf("unsupported quota action: %s", c.Scraper.QuotaAction)
+	}
+
+	if c.Scraper.JobDedupWindow < 0 {
+		return fmt.Errorf("job dedup window must not be negative")
+	}
+
+	if c.Scraper.SizeChartCacheTTL < 0 || c.Scraper.SizeChartCacheSize < 0 {
+		return fmt.Errorf("size chart cache ttl and size must not be negative")
+	}
+
+	// The DevTools endpoint controls a browser with the scraper's proxy and cookies, it is only exposed
+	// behind the admin token
+	if c.Scraper.DebugSessions {
+		if c.Server.AdminToken == "" {
+			return fmt.Errorf("debug sessions need an admin token")
+		}
+		if c.Scraper.DebugPort <= 0 || c.Scraper.DebugPort > 65535 || c.Scraper.DebugPort == c.Server.Port {
+			return fmt.Errorf("invalid debug port: %d", c.Scraper.DebugPort)
+		}
+		if c.Scraper.DebugTTL < 1 {
+			return fmt.Errorf("debug session ttl must be at least 1 second")
+		}
+	}
+
+	if err := c.Redis.Conn.Validate(); err != nil {
+		return err
+	}
+
+	if c.Redis.StreamMaxLen < 0 || c.Redis.MaxBacklog < 0 || c.Redis.MaxLag < 0 {
+		return fmt.Errorf("redis stream limits must not be negative")
+	}
+
+	if c.Events.SchemaVersion != 1 && c.Events.SchemaVersion != 2 {
+		return fmt.Errorf("unsupported event schema version: %d", c.Events.SchemaVersion)
+	}
+
+	switch c.Events.PayloadCompression {
+	case "", "gzip", "zstd":
+	default:
+		return fmt.Errorf("unsupported event payload compression: %s", c.Events.PayloadCompression)
+	}
+
+	if c.Events.CompressThreshold < 0 || c.Events.MaxPayloadSize < 0 {
+		return fmt.Errorf("event payload limits must not be negative")
+	}
+
+	if c.Events.ControlStream != "" {
+		if c.Events.ControlGroup == "" {
+			return fmt.Errorf("control stream consumer group must not be empty")
+		}
+		if c.Events.ControlWorkers < 1 {
+			return fmt.Errorf("control stream workers must be at least 1")
+		}
+	}
+
+	// Fault injection exists to test consumers, never to disturb real traffic
+	if c.Chaos.Enabled && c.Server.Environment == "production" {
+		return fmt.Errorf("chaos mode must not be enabled in production")
+	}
+
 	return nil
 }
 
+// hostname is the default instance ID, unique per container
+func hostname() string {
+	if name, err := os.Hostname(); err == nil && name != "" {
+		return name
+	}
+	return "scraper"
+}
+
 func getEnv(key, defaultValue string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
@@ -110,6 +448,15 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value, exists := os.LookupEnv(key); exists {
+		if floatVal, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatVal
+		}
+	}
+	return defaultValue
+}
+
 func getEnvBool(key string, defaultValue bool) bool {
 	if value, exists := os.LookupEnv(key); exists {
 		if boolVal, err := strconv.ParseBool(value); err == nil {
@@ -117,4 +464,4 @@ func getEnvBool(key string, defaultValue bool) bool {
 		}
 	}
 	return defaultValue
-}
\ No newline at end of file
+}
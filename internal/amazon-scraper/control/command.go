@@ -0,0 +1,106 @@
+// Package control lets the orchestrator drive the scraper through the event bus: commands are read from
+// a Redis stream and each one is acknowledged with a result event.
+package control
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/maltedev/amazon-size-scraper/internal/asin"
+	"github.com/maltedev/amazon-size-scraper/internal/schema"
+)
+
+// Commands accepted on the control stream
+const (
+	CommandScrapeASIN     = "SCRAPE_ASIN"     // Scrape a product by ASIN or URL, known or not
+	CommandRefreshProduct = "REFRESH_PRODUCT" // Scrape a stored product again from its stored product page
+	CommandCancelJob      = "CANCEL_JOB"      // Cancel a pending or running crawl job
+)
+
+// ErrInvalidCommand is returned for stream entries that are not a valid command
+var ErrInvalidCommand = errors.New("invalid command")
+
+// Command is a decoded control stream entry. Its fields are flat stream fields: command, command_id,
+// asin, url, marketplace, job_id, trace_id and metadata, a JSON object of strings.
+type Command struct {
+	MessageID string // Stream entry ID
+	ID        string // command_id, the stream entry ID if the sender set none
+	Type      string // e.g. CommandScrapeASIN
+	ASIN      string
+	URL       string // Product page to scrape, SCRAPE_ASIN only
+	JobID     string
+	TraceID   string
+	Metadata  map[string]string // Caller metadata, stored with the product and carried in the events
+}
+
+// ParseCommand decodes and validates a control stream entry. A SCRAPE_ASIN with a URL takes ASIN and
+// marketplace from the URL, one with an ASIN scrapes the product page on marketplace, the default
+// marketplace if it is empty.
+func ParseCommand(messageID string, values map[string]any) (*Command, error) {
+	cmd := &Command{
+		MessageID: messageID,
+		ID:        field(values, "command_id"),
+		Type:      strings.ToUpper(field(values, "command")),
+		JobID:     field(values, "job_id"),
+		TraceID:   field(values, "trace_id"),
+	}
+	if cmd.ID == "" {
+		cmd.ID = messageID
+	}
+	rawASIN, rawURL := field(values, "asin"), field(values, "url")
+
+	if raw := field(values, "metadata"); raw != "" {
+		var metadata map[string]string
+		if err := json.Unmarshal([]byte(raw), &metadata); err != nil {
+			return cmd, fmt.Errorf("%w: metadata must be a JSON object of strings", ErrInvalidCommand)
+		}
+		if err := schema.ValidateMetadata(metadata); err != nil {
+			return cmd, fmt.Errorf("%w: %v", ErrInvalidCommand, err)
+		}
+		cmd.Metadata = metadata
+	}
+
+	switch cmd.Type {
+	case CommandScrapeASIN:
+		marketplace := field(values, "marketplace")
+		if marketplace == "" {
+			marketplace = asin.DefaultMarketplace
+		}
+		value := rawASIN
+		if rawURL != "" {
+			value = rawURL
+		}
+		target, ok := asin.Parse(value, marketplace)
+		if !ok {
+			return cmd, fmt.Errorf("%w: %s needs a valid asin or url", ErrInvalidCommand, cmd.Type)
+		}
+		cmd.ASIN, cmd.URL = target.ASIN, target.URL()
+	case CommandRefreshProduct:
+		normalized, ok := asin.Normalize(rawASIN)
+		if !ok {
+			return cmd, fmt.Errorf("%w: %s needs a valid asin", ErrInvalidCommand, cmd.Type)
+		}
+		cmd.ASIN = normalized
+	case CommandCancelJob:
+		if _, err := uuid.Parse(cmd.JobID); err != nil {
+			return cmd, fmt.Errorf("%w: %s needs a valid job_id", ErrInvalidCommand, cmd.Type)
+		}
+	case "":
+		return cmd, fmt.Errorf("%w: missing command field", ErrInvalidCommand)
+	default:
+		return cmd, fmt.Errorf("%w: unknown command %q", ErrInvalidCommand, cmd.Type)
+	}
+	return cmd, nil
+}
+
+// field returns a stream field as trimmed string, empty if it is missing
+func field(values map[string]any, key string) string {
+	v, ok := values[key]
+	if !ok || v == nil {
+		return ""
+	}
+	return strings.TrimSpace(fmt.Sprint(v))
+}
@@ -0,0 +1,87 @@
+package control
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[string]any
+		want   Command
+	}{
+		{
+			name:   "scrape bare asin",
+			values: map[string]any{"command": "SCRAPE_ASIN", "asin": " b08n5wrwnw", "command_id": "c1"},
+			want:   Command{ID: "c1", Type: CommandScrapeASIN, ASIN: "B08N5WRWNW", URL: "https://www.amazon.de/dp/B08N5WRWNW"},
+		},
+		{
+			name:   "scrape asin on marketplace",
+			values: map[string]any{"command": "scrape_asin", "asin": "B08N5WRWNW", "marketplace": "amazon.co.uk"},
+			want:   Command{ID: "1-0", Type: CommandScrapeASIN, ASIN: "B08N5WRWNW", URL: "https://www.amazon.co.uk/dp/B08N5WRWNW"},
+		},
+		{
+			name:   "scrape url",
+			values: map[string]any{"command": "SCRAPE_ASIN", "url": "https://www.amazon.fr/Some-Shirt/dp/B08N5WRWNW?ref=sr_1_1"},
+			want:   Command{ID: "1-0", Type: CommandScrapeASIN, ASIN: "B08N5WRWNW", URL: "https://www.amazon.fr/dp/B08N5WRWNW"},
+		},
+		{
+			name:   "refresh",
+			values: map[string]any{"command": "REFRESH_PRODUCT", "asin": "B08N5WRWNW", "trace_id": "abc"},
+			want:   Command{ID: "1-0", Type: CommandRefreshProduct, ASIN: "B08N5WRWNW", TraceID: "abc"},
+		},
+		{
+			name:   "cancel",
+			values: map[string]any{"command": "CANCEL_JOB", "job_id": "6f1c0a44-3b5e-4d8f-9a0e-2f0c8a1b7c55"},
+			want:   Command{ID: "1-0", Type: CommandCancelJob, JobID: "6f1c0a44-3b5e-4d8f-9a0e-2f0c8a1b7c55"},
+		},
+		{
+			name:   "scrape with metadata",
+			values: map[string]any{"command": "SCRAPE_ASIN", "asin": "B08N5WRWNW", "metadata": `{"campaign":"spring","tenant":"42"}`},
+			want: Command{ID: "1-0", Type: CommandScrapeASIN, ASIN: "B08N5WRWNW", URL: "https://www.amazon.de/dp/B08N5WRWNW",
+				Metadata: map[string]string{"campaign": "spring", "tenant": "42"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCommand("1-0", tt.values)
+			if err != nil {
+				t.Fatalf("ParseCommand() error = %v", err)
+			}
+			tt.want.MessageID = "1-0"
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("ParseCommand() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCommandInvalid(t *testing.T) {
+	tests := map[string]map[string]any{
+		"missing command":   {"asin": "B08N5WRWNW"},
+		"unknown command":   {"command": "DELETE_PRODUCT", "asin": "B08N5WRWNW"},
+		"scrape without id": {"command": "SCRAPE_ASIN"},
+		"refresh bad asin":  {"command": "REFRESH_PRODUCT", "asin": "shirt"},
+		"refresh url only":  {"command": "REFRESH_PRODUCT", "url": "https://www.amazon.de/dp/B08N5WRWNW"},
+		"cancel bad job id": {"command": "CANCEL_JOB", "job_id": "42"},
+		"metadata no json":  {"command": "SCRAPE_ASIN", "asin": "B08N5WRWNW", "metadata": "campaign=spring"},
+		"metadata number":   {"command": "SCRAPE_ASIN", "asin": "B08N5WRWNW", "metadata": `{"tenant":42}`},
+		"metadata reserved": {"command": "SCRAPE_ASIN", "asin": "B08N5WRWNW", "metadata": `{"source":"shop"}`},
+	}
+
+	for name, values := range tests {
+		t.Run(name, func(t *testing.T) {
+			cmd, err := ParseCommand("1-0", values)
+			if !errors.Is(err, ErrInvalidCommand) {
+				t.Fatalf("ParseCommand() error = %v, want ErrInvalidCommand", err)
+			}
+			// Rejected commands are still acknowledged under their ID
+			if cmd == nil || cmd.ID != "1-0" {
+				t.Errorf("ParseCommand() command = %+v, want ID of the stream entry", cmd)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package control
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/events"
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/jobs"
+	logging "github.com/maltedev/amazon-size-scraper/pkg/logger"
+	"github.com/redis/go-redis/v9"
+)
+
+// Config names the control stream and how its commands are consumed
+type Config struct {
+	Stream   string // e.g. "stream:scraper_commands"
+	Group    string // Shared by all instances, so every command runs on one of them
+	Consumer string // Name of this instance in the group
+	Workers  int    // Commands executed in parallel, 0 runs one at a time
+}
+
+// Consumer executes the commands of the control stream and acknowledges each one with a
+// SCRAPER_COMMAND_COMPLETED or SCRAPER_COMMAND_FAILED event
+type Consumer struct {
+	redis     redis.UniversalClient
+	jobs      *jobs.Manager
+	publisher *events.Publisher
+	cfg       Config
+	logger    *slog.Logger
+}
+
+// NewConsumer creates a control stream consumer
+func NewConsumer(client redis.UniversalClient, manager *jobs.Manager, publisher *events.Publisher, cfg Config, logger *slog.Logger) *Consumer {
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
+	return &Consumer{
+		redis:     client,
+		jobs:      manager,
+		publisher: publisher,
+		cfg:       cfg,
+		logger:    logger.With("component", "control_consumer", "stream", cfg.Stream),
+	}
+}
+
+// Run reads commands until ctx is cancelled. Commands left pending by an earlier run of this consumer,
+// e.g. interrupted by a restart, are executed first. A command is acknowledged in the stream only once
+// its result event is in the outbox, so none is lost, but one may run twice.
+func (c *Consumer) Run(ctx context.Context) error {
+	// New groups start at the end of the stream, commands sent before the first start are not replayed
+	err := c.redis.XGroupCreateMkStream(ctx, c.cfg.Stream, c.cfg.Group, "$").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to create control consumer group: %w", err)
+	}
+	c.logger.InfoContext(ctx, "control consumer started", "group", c.cfg.Group, "consumer", c.cfg.Consumer, "workers", c.cfg.Workers)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	slots := make(chan struct{}, c.cfg.Workers)
+
+	// Pending commands are read after a cursor, so one whose result still cannot be stored is not retried
+	// before the next start
+	replaying := true
+	pendingCursor := "0"
+	for ctx.Err() == nil {
+		readID := ">"
+		if replaying {
+			readID = pendingCursor
+		}
+
+		streams, err := c.redis.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    c.cfg.Group,
+			Consumer: c.cfg.Consumer,
+			Streams:  []string{c.cfg.Stream, readID},
+			Count:    int64(c.cfg.Workers),
+			Block:    5 * time.Second,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			c.logger.ErrorContext(ctx, "failed to read control stream", "error", err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		read := 0
+		for _, stream := range streams {
+			for _, msg := range stream.Messages {
+				read++
+				pendingCursor = msg.ID
+				select {
+				case slots <- struct{}{}:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+				wg.Add(1)
+				go func(msg redis.XMessage) {
+					defer wg.Done()
+					defer func() { <-slots }()
+					c.handle(ctx, msg)
+				}(msg)
+			}
+		}
+
+		if replaying && read == 0 {
+			replaying = false
+		}
+	}
+
+	c.logger.InfoContext(ctx, "control consumer stopping")
+	return ctx.Err()
+}
+
+// handle executes one command, publishes its result and acknowledges it. Commands interrupted by a
+// shutdown or whose result could not be stored stay pending.
+func (c *Consumer) handle(ctx context.Context, msg redis.XMessage) {
+	start := time.Now()
+	cmd, err := ParseCommand(msg.ID, msg.Values)
+
+	if cmd.TraceID != "" {
+		ctx = logging.WithTraceID(ctx, cmd.TraceID)
+	}
+	ctx = events.WithMetadata(logging.EnsureTraceID(ctx), cmd.Metadata)
+
+	result := &events.CommandResultPayload{
+		CommandID: cmd.ID,
+		Command:   cmd.Type,
+		MessageID: msg.ID,
+		ASIN:      cmd.ASIN,
+		JobID:     cmd.JobID,
+	}
+	if err == nil {
+		c.logger.InfoContext(ctx, "executing command", "command", cmd.Type, "command_id", cmd.ID, "asin", cmd.ASIN, "job_id", cmd.JobID)
+		err = c.execute(ctx, cmd, result)
+	}
+	if ctx.Err() != nil {
+		c.logger.WarnContext(ctx, "command interrupted", "command", cmd.Type, "command_id", cmd.ID)
+		return
+	}
+	result.DurationMs = time.Since(start).Milliseconds()
+
+	eventType := events.EventTypeCommandCompleted
+	if err != nil {
+		eventType = events.EventTypeCommandFailed
+		result.Error = err.Error()
+		c.logger.WarnContext(ctx, "command failed", "command", cmd.Type, "command_id", cmd.ID, "error", err)
+	}
+	if err := c.publisher.PublishCommandResult(ctx, eventType, result); err != nil {
+		c.logger.ErrorContext(ctx, "failed to publish command result", "command_id", cmd.ID, "error", err)
+		return
+	}
+	if err := c.redis.XAck(ctx, c.cfg.Stream, c.cfg.Group, msg.ID).Err(); err != nil {
+		c.logger.ErrorContext(ctx, "failed to acknowledge command", "command_id", cmd.ID, "error", err)
+	}
+}
+
+// execute runs a valid command and records its outcome in result
+func (c *Consumer) execute(ctx context.Context, cmd *Command, result *events.CommandResultPayload) error {
+	switch cmd.Type {
+	case CommandScrapeASIN, CommandRefreshProduct:
+		var scrape *jobs.ProductScrape
+		var err error
+		if cmd.Type == CommandScrapeASIN {
+			scrape, err = c.jobs.ScrapeProduct(ctx, cmd.ASIN, cmd.URL)
+		} else {
+			scrape, err = c.jobs.RefreshProduct(ctx, cmd.ASIN)
+		}
+		if err != nil {
+			return err
+		}
+		result.Result = scrape.Result
+		result.CanonicalASIN = scrape.CanonicalASIN
+	case CommandCancelJob:
+		job, err := c.jobs.CancelJob(ctx, cmd.JobID)
+		if err != nil {
+			return err
+		}
+		result.Result = job.Status
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package events
+
+import (
+	"context"
+	"maps"
+)
+
+type metadataKey struct{}
+
+// WithMetadata returns a context whose events carry the caller metadata, e.g. the campaign ID of the
+// job or request that emits them. Keys of metadata replace those already in ctx.
+func WithMetadata(ctx context.Context, metadata map[string]string) context.Context {
+	if len(metadata) == 0 {
+		return ctx
+	}
+	merged := maps.Clone(Metadata(ctx))
+	if merged == nil {
+		merged = make(map[string]string, len(metadata))
+	}
+	maps.Copy(merged, metadata)
+	return context.WithValue(ctx, metadataKey{}, merged)
+}
+
+// Metadata returns the caller metadata of ctx, nil if it carries none
+func Metadata(ctx context.Context) map[string]string {
+	metadata, _ := ctx.Value(metadataKey{}).(map[string]string)
+	return metadata
+}
@@ -10,6 +10,9 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/maltedev/amazon-size-scraper/internal/eventroute"
+	"github.com/maltedev/amazon-size-scraper/internal/schema"
+	logging "github.com/maltedev/amazon-size-scraper/pkg/logger"
 )
 
 // EventType represents the type of event
@@ -18,10 +21,29 @@ type EventType string
 const (
 	// EventTypeNewProductDetected is published when a new product is found
 	EventTypeNewProductDetected EventType = "NEW_PRODUCT_DETECTED"
+	// EventTypeReviewsEnriched is published when a fit summary was derived from the reviews of a product
+	EventTypeReviewsEnriched EventType = "PRODUCT_REVIEWS_ENRICHED"
+	// EventTypeProductRetired is published when a product's listing was found deleted on Amazon
+	EventTypeProductRetired EventType = "PRODUCT_RETIRED"
+	// EventTypeSizeTableUpdated is published when the size table of a product was replaced by hand
+	EventTypeSizeTableUpdated EventType = "SIZE_TABLE_UPDATED"
+	// EventTypeJobStarted is published when a crawl job starts running
+	EventTypeJobStarted EventType = "JOB_STARTED"
+	// EventTypeJobCompleted is published when a crawl job finished all its pages
+	EventTypeJobCompleted EventType = "JOB_COMPLETED"
+	// EventTypeJobFailed is published when a crawl job stopped with an error
+	EventTypeJobFailed EventType = "JOB_FAILED"
+	// EventTypeJobCancelled is published when a crawl job was cancelled before it finished
+	EventTypeJobCancelled EventType = "JOB_CANCELLED"
+	// EventTypeCommandCompleted acknowledges a control stream command that was carried out
+	EventTypeCommandCompleted EventType = "SCRAPER_COMMAND_COMPLETED"
+	// EventTypeCommandFailed acknowledges a control stream command that was rejected or failed
+	EventTypeCommandFailed EventType = "SCRAPER_COMMAND_FAILED"
 )
 
 // NewProductDetectedPayload represents the payload for NEW_PRODUCT_DETECTED event
 type NewProductDetectedPayload struct {
+	SchemaVersion  int                    `json:"schema_version"`
 	EventID        string                 `json:"event_id"`
 	EventType      string                 `json:"event_type"`
 	Timestamp      time.Time              `json:"timestamp"`
@@ -30,23 +52,101 @@ type NewProductDetectedPayload struct {
 	Brand          string                 `json:"brand,omitempty"`
 	DetailPageURL  string                 `json:"detail_page_url"`
 	Category       string                 `json:"category,omitempty"`
+	CategoryCode   string                 `json:"category_code,omitempty"` // Internal category, see package taxonomy
+	BrowseNode     string                 `json:"browse_node,omitempty"`   // Deepest browse node of the breadcrumbs
 	Price          *Price                 `json:"price,omitempty"`
 	Rating         *float64               `json:"rating,omitempty"`
 	ReviewCount    *int                   `json:"review_count,omitempty"`
 	Images         []string               `json:"images,omitempty"`
 	Features       []string               `json:"features,omitempty"`
+	Attributes     map[string]string      `json:"attributes,omitempty"` // Product overview attributes, e.g. material and fit
 	AvailableSizes []string               `json:"available_sizes,omitempty"`
 	SizeTable      *database.SizeTable    `json:"size_table,omitempty"`
+	FitFeedback    *database.FitFeedback  `json:"fit_feedback,omitempty"`
+	SizePrices     database.SizePrices    `json:"size_prices,omitempty"`
+	DataSources    map[string]string      `json:"data_sources,omitempty"` // Field -> "scraper" or "pa-api"
 	Source         string                 `json:"source"` // "scraper" instead of "pa-api"
 }
 
+// ReviewsEnrichedPayload represents the payload for PRODUCT_REVIEWS_ENRICHED event
+type ReviewsEnrichedPayload struct {
+	EventID    string               `json:"event_id"`
+	EventType  string               `json:"event_type"`
+	Timestamp  time.Time            `json:"timestamp"`
+	ASIN       string               `json:"asin"`
+	FitSummary *database.FitSummary `json:"fit_summary"`
+	Source     string               `json:"source"`
+}
+
+// ProductRetiredPayload represents the payload for PRODUCT_RETIRED event
+type ProductRetiredPayload struct {
+	EventID   string    `json:"event_id"`
+	EventType string    `json:"event_type"`
+	Timestamp time.Time `json:"timestamp"`
+	ASIN      string    `json:"asin"`
+	Reason    string    `json:"reason"` // e.g. "HTTP 404"
+	Source    string    `json:"source"`
+}
+
+// SizeTableUpdatedPayload represents the payload for SIZE_TABLE_UPDATED event
+type SizeTableUpdatedPayload struct {
+	EventID          string                     `json:"event_id"`
+	EventType        string                     `json:"event_type"`
+	Timestamp        time.Time                  `json:"timestamp"`
+	ASIN             string                     `json:"asin"`
+	SizeTable        *database.SizeTable        `json:"size_table"`
+	ValidationReport *database.ValidationReport `json:"validation_report,omitempty"`
+	Source           string                     `json:"source"` // "manual"
+}
+
+// JobPayload represents the payload for JOB_STARTED, JOB_COMPLETED, JOB_FAILED and JOB_CANCELLED events, the counts
+// are those of the job at the time of the event
+type JobPayload struct {
+	EventID         string         `json:"event_id"`
+	EventType       string         `json:"event_type"`
+	Timestamp       time.Time      `json:"timestamp"`
+	JobID           string         `json:"job_id"`
+	TemplateID      string         `json:"template_id,omitempty"`
+	SearchQuery     string         `json:"search_query"`
+	Category        string         `json:"category,omitempty"`
+	Marketplace     string         `json:"marketplace"`
+	PagesScraped    int            `json:"pages_scraped"`
+	ProductsFound   int            `json:"products_found"`
+	ProductsNew     int            `json:"products_new"`
+	ProductsSkipped int            `json:"products_skipped"`
+	SkipReasons     map[string]int `json:"skip_reasons,omitempty"`
+	DurationMs      int64          `json:"duration_ms"`
+	Error           string         `json:"error,omitempty"`
+	Source          string         `json:"source"`
+}
+
+// CommandResultPayload represents the payload for SCRAPER_COMMAND_COMPLETED and SCRAPER_COMMAND_FAILED
+// events, the acknowledgement of a command read from the control stream
+type CommandResultPayload struct {
+	EventID       string    `json:"event_id"`
+	EventType     string    `json:"event_type"`
+	Timestamp     time.Time `json:"timestamp"`
+	CommandID     string    `json:"command_id"` // Set by the sender, the stream entry ID otherwise
+	Command       string    `json:"command"`    // e.g. "SCRAPE_ASIN"
+	MessageID     string    `json:"message_id"` // Entry ID in the control stream
+	ASIN          string    `json:"asin,omitempty"`
+	JobID         string    `json:"job_id,omitempty"`
+	Result        string    `json:"result,omitempty"` // e.g. "saved", "unchanged", "duplicate" or "cancelled"
+	CanonicalASIN string    `json:"canonical_asin,omitempty"`
+	Error         string    `json:"error,omitempty"`
+	DurationMs    int64     `json:"duration_ms"`
+	Source        string    `json:"source"`
+}
+
 // EnhancedNewProductDetectedPayload is an alias for backward compatibility
 type EnhancedNewProductDetectedPayload = NewProductDetectedPayload
 
 // Price represents product pricing information
 type Price struct {
-	Amount   float64 `json:"amount"`
-	Currency string  `json:"currency"`
+	Amount            float64 `json:"amount"`
+	Currency          string  `json:"currency"`
+	ReportingAmount   float64 `json:"reporting_amount,omitempty"` // Amount converted for cross-marketplace comparison
+	ReportingCurrency string  `json:"reporting_currency,omitempty"`
 }
 
 // HasValidSizeTable checks if the payload has a valid size table with length and width
@@ -58,6 +158,7 @@ func (p *NewProductDetectedPayload) HasValidSizeTable() bool {
 type Publisher struct {
 	db     *database.DB
 	outbox *database.OutboxRepository
+	routes *eventroute.Table
 	logger *slog.Logger
 }
 
@@ -70,8 +171,88 @@ func NewPublisher(db *database.DB, logger *slog.Logger) *Publisher {
 	}
 }
 
+// SetRoutes sets the targets events are published to per event type, nil publishes everything to
+// eventroute.DefaultTarget
+func (p *Publisher) SetRoutes(routes *eventroute.Table) {
+	p.routes = routes
+}
+
 // PublishNewProductDetected publishes a NEW_PRODUCT_DETECTED event using transactional outbox
 func (p *Publisher) PublishNewProductDetected(ctx context.Context, payload *NewProductDetectedPayload) error {
+	outboxEvents, err := p.newProductOutboxEvents(ctx, payload)
+	if err != nil {
+		return err
+	}
+
+	// Use transaction to ensure atomicity
+	err = p.db.Transaction(ctx, func(tx pgx.Tx) error {
+		for _, outboxEvent := range outboxEvents {
+			if err := p.outbox.InsertWithTx(ctx, tx, outboxEvent); err != nil {
+				return fmt.Errorf("failed to insert outbox event: %w", err)
+			}
+		}
+
+		// Additional transactional operations can be added here
+		// For example, updating a products table, etc.
+
+		return nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+
+	p.logger.InfoContext(ctx, "event published to outbox",
+		"type", payload.EventType,
+		"event_id", payload.EventID,
+		"asin", payload.ASIN,
+		"outbox_id", outboxEvents[0].ID,
+		"targets", len(outboxEvents),
+	)
+
+	return nil
+}
+
+// PublishNewProductDetectedBatch inserts NEW_PRODUCT_DETECTED events for all payloads in one transaction
+func (p *Publisher) PublishNewProductDetectedBatch(ctx context.Context, payloads []*NewProductDetectedPayload) error {
+	outboxEvents := make([]*database.OutboxEvent, 0, len(payloads))
+	for _, payload := range payloads {
+		events, err := p.newProductOutboxEvents(ctx, payload)
+		if err != nil {
+			return fmt.Errorf("failed to build event for %s: %w", payload.ASIN, err)
+		}
+		outboxEvents = append(outboxEvents, events...)
+	}
+
+	err := p.db.Transaction(ctx, func(tx pgx.Tx) error {
+		for _, outboxEvent := range outboxEvents {
+			if err := p.outbox.InsertWithTx(ctx, tx, outboxEvent); err != nil {
+				return fmt.Errorf("failed to insert outbox event: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish events: %w", err)
+	}
+
+	p.logger.InfoContext(ctx, "event batch published to outbox",
+		"type", EventTypeNewProductDetected,
+		"count", len(outboxEvents),
+	)
+
+	return nil
+}
+
+// NewProductDetectedEvents builds the outbox events of a NEW_PRODUCT_DETECTED payload without inserting
+// them, for callers that insert them in the transaction of their own writes
+func (p *Publisher) NewProductDetectedEvents(ctx context.Context, payload *NewProductDetectedPayload) ([]*database.OutboxEvent, error) {
+	return p.newProductOutboxEvents(ctx, payload)
+}
+
+// newProductOutboxEvents fills missing event metadata of payload and wraps it in one outbox event per
+// routed target
+func (p *Publisher) newProductOutboxEvents(ctx context.Context, payload *NewProductDetectedPayload) ([]*database.OutboxEvent, error) {
 	// Set event metadata
 	if payload.EventID == "" {
 		payload.EventID = uuid.New().String()
@@ -85,43 +266,158 @@ func (p *Publisher) PublishNewProductDetected(ctx context.Context, payload *NewP
 	if payload.Source == "" {
 		payload.Source = "scraper"
 	}
+	if payload.SchemaVersion == 0 {
+		payload.SchemaVersion = schema.CurrentVersion
+	}
 
 	// Convert to JSON
 	data, err := json.Marshal(payload)
 	if err != nil {
-		return fmt.Errorf("failed to marshal event: %w", err)
+		return nil, fmt.Errorf("failed to marshal event: %w", err)
 	}
 
-	// Create outbox event
-	outboxEvent := &database.OutboxEvent{
-		AggregateType: "product",
-		AggregateID:   payload.ASIN,
-		EventType:     string(EventTypeNewProductDetected),
-		Payload:       data,
-		TargetStream:  "stream:product_lifecycle",
+	targets := p.routes.Resolve(string(EventTypeNewProductDetected))
+	outboxEvents := make([]*database.OutboxEvent, len(targets))
+	for i, target := range targets {
+		outboxEvents[i] = &database.OutboxEvent{
+			AggregateType: "product",
+			AggregateID:   payload.ASIN,
+			EventType:     string(EventTypeNewProductDetected),
+			Payload:       data,
+			TargetStream:  target.String(),
+			TraceID:       logging.TraceID(ctx),
+			Metadata:      Metadata(ctx),
+		}
 	}
+	return outboxEvents, nil
+}
 
-	// Use transaction to ensure atomicity
-	err = p.db.Transaction(ctx, func(tx pgx.Tx) error {
-		if err := p.outbox.InsertWithTx(ctx, tx, outboxEvent); err != nil {
-			return fmt.Errorf("failed to insert outbox event: %w", err)
-		}
+// PublishReviewsEnriched publishes a PRODUCT_REVIEWS_ENRICHED event using transactional outbox. Its
+// aggregate type is "review_summary" so the relay does not downgrade it like product events.
+func (p *Publisher) PublishReviewsEnriched(ctx context.Context, payload *ReviewsEnrichedPayload) error {
+	if payload.EventID == "" {
+		payload.EventID = uuid.New().String()
+	}
+	if payload.EventType == "" {
+		payload.EventType = string(EventTypeReviewsEnriched)
+	}
+	if payload.Timestamp.IsZero() {
+		payload.Timestamp = time.Now()
+	}
+	if payload.Source == "" {
+		payload.Source = "scraper"
+	}
 
-		// Additional transactional operations can be added here
-		// For example, updating a products table, etc.
+	return p.publish(ctx, "review_summary", EventTypeReviewsEnriched, payload.EventID, payload.ASIN, payload)
+}
+
+// PublishProductRetired publishes a PRODUCT_RETIRED event using transactional outbox. Its aggregate type
+// is "product_retirement" so the relay does not decode it as a product payload.
+func (p *Publisher) PublishProductRetired(ctx context.Context, payload *ProductRetiredPayload) error {
+	if payload.EventID == "" {
+		payload.EventID = uuid.New().String()
+	}
+	if payload.EventType == "" {
+		payload.EventType = string(EventTypeProductRetired)
+	}
+	if payload.Timestamp.IsZero() {
+		payload.Timestamp = time.Now()
+	}
+	if payload.Source == "" {
+		payload.Source = "scraper"
+	}
+
+	return p.publish(ctx, "product_retirement", EventTypeProductRetired, payload.EventID, payload.ASIN, payload)
+}
+
+// PublishSizeTableUpdated publishes a SIZE_TABLE_UPDATED event using transactional outbox, under the
+// aggregate type "size_table"
+func (p *Publisher) PublishSizeTableUpdated(ctx context.Context, payload *SizeTableUpdatedPayload) error {
+	if payload.EventID == "" {
+		payload.EventID = uuid.New().String()
+	}
+	if payload.EventType == "" {
+		payload.EventType = string(EventTypeSizeTableUpdated)
+	}
+	if payload.Timestamp.IsZero() {
+		payload.Timestamp = time.Now()
+	}
+	if payload.Source == "" {
+		payload.Source = database.SizeTableSourceManual
+	}
+
+	return p.publish(ctx, "size_table", EventTypeSizeTableUpdated, payload.EventID, payload.ASIN, payload)
+}
+
+// PublishJobEvent publishes one of the job lifecycle events using transactional outbox, keyed by the
+// job ID under the aggregate type "scraper_job"
+func (p *Publisher) PublishJobEvent(ctx context.Context, eventType EventType, payload *JobPayload) error {
+	if payload.EventID == "" {
+		payload.EventID = uuid.New().String()
+	}
+	payload.EventType = string(eventType)
+	if payload.Timestamp.IsZero() {
+		payload.Timestamp = time.Now()
+	}
+	if payload.Source == "" {
+		payload.Source = "scraper"
+	}
+
+	return p.publish(ctx, "scraper_job", eventType, payload.EventID, payload.JobID, payload)
+}
+
+// PublishCommandResult acknowledges a control stream command using transactional outbox, keyed by the
+// command ID under the aggregate type "scraper_command"
+func (p *Publisher) PublishCommandResult(ctx context.Context, eventType EventType, payload *CommandResultPayload) error {
+	if payload.EventID == "" {
+		payload.EventID = uuid.New().String()
+	}
+	payload.EventType = string(eventType)
+	if payload.Timestamp.IsZero() {
+		payload.Timestamp = time.Now()
+	}
+	if payload.Source == "" {
+		payload.Source = "scraper"
+	}
 
+	return p.publish(ctx, "scraper_command", eventType, payload.EventID, payload.CommandID, payload)
+}
+
+// publish inserts payload into the outbox once per routed target of eventType
+func (p *Publisher) publish(ctx context.Context, aggregateType string, eventType EventType, eventID, aggregateID string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	targets := p.routes.Resolve(string(eventType))
+	err = p.db.Transaction(ctx, func(tx pgx.Tx) error {
+		for _, target := range targets {
+			event := &database.OutboxEvent{
+				AggregateType: aggregateType,
+				AggregateID:   aggregateID,
+				EventType:     string(eventType),
+				Payload:       data,
+				TargetStream:  target.String(),
+				TraceID:       logging.TraceID(ctx),
+				Metadata:      Metadata(ctx),
+			}
+			if err := p.outbox.InsertWithTx(ctx, tx, event); err != nil {
+				return fmt.Errorf("failed to insert outbox event: %w", err)
+			}
+		}
 		return nil
 	})
-
 	if err != nil {
 		return fmt.Errorf("failed to publish event: %w", err)
 	}
 
-	p.logger.Info("event published to outbox",
-		"type", payload.EventType,
-		"event_id", payload.EventID,
-		"asin", payload.ASIN,
-		"outbox_id", outboxEvent.ID,
+	p.logger.InfoContext(ctx, "event published to outbox",
+		"type", eventType,
+		"event_id", eventID,
+		"aggregate_type", aggregateType,
+		"aggregate_id", aggregateID,
+		"targets", len(targets),
 	)
 
 	return nil
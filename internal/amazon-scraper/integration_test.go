@@ -53,7 +53,7 @@ func TestCompleteProductFlow(t *testing.T) {
 		Headless: true,
 		Timeout:  30 * time.Second,
 	}
-	b, err := browser.New(browserOpts)
+	b, err := browser.New(&browserOpts)
 	require.NoError(t, err)
 	defer b.Close()
 
@@ -71,7 +71,7 @@ func TestCompleteProductFlow(t *testing.T) {
 		}
 
 		// Create job
-		job, err := jobManager.CreateJob(ctx, testJob.SearchQuery, testJob.Category, testJob.MaxPages)
+		job, err := jobManager.CreateJob(ctx, testJob.SearchQuery, testJob.Category, testJob.MaxPages, testJob.ProductFilter, testJob.Metadata)
 		require.NoError(t, err)
 		assert.NotEmpty(t, job.ID)
 
@@ -128,7 +128,7 @@ func processTestJob(ctx context.Context, manager *jobs.Manager, jobID string) er
 }
 
 func TestSizeTableValidation(t *testing.T) {
-	t.Run("Only products with length and width are processed", func(t *testing.T) {
+	t.Run("Only products with length and chest are processed", func(t *testing.T) {
 		// Test various size table configurations
 		testCases := []struct {
 			name      string
@@ -136,7 +136,7 @@ func TestSizeTableValidation(t *testing.T) {
 			shouldPass bool
 		}{
 			{
-				name: "Valid - has length and width",
+				name: "Valid - has length and chest",
 				sizeTable: &database.SizeTable{
 					Sizes: []string{"M", "L"},
 					Measurements: map[string]map[string]float64{
@@ -159,11 +159,11 @@ func TestSizeTableValidation(t *testing.T) {
 				shouldPass: false,
 			},
 			{
-				name: "Invalid - missing width",
+				name: "Invalid - missing chest",
 				sizeTable: &database.SizeTable{
 					Sizes: []string{"M"},
 					Measurements: map[string]map[string]float64{
-						"M": {"chest": 100, "length": 72},
+						"M": {"length": 72, "width": 54},
 					},
 					Unit: "cm",
 				},
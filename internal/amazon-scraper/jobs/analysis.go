@@ -0,0 +1,280 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/events"
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/scraper"
+	"github.com/maltedev/amazon-size-scraper/internal/analytics"
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/maltedev/amazon-size-scraper/internal/quota"
+)
+
+// CategorySizeAnalysis marks size analysis jobs, which classify the size tables of search results
+// without storing the products. The Amazon search category is kept in the job's filters.
+const CategorySizeAnalysis = "size_analysis"
+
+// Limits of a size analysis
+const (
+	DefaultAnalysisSample = 50
+	MaxAnalysisSample     = 500
+)
+
+// analysisDelay paces the product pages of a size analysis
+const analysisDelay = 3 * time.Second
+
+// SizeAnalysisOptions selects the search results of a size analysis
+type SizeAnalysisOptions struct {
+	SearchQuery string
+	Category    string // Amazon search category, e.g. fashion
+	Marketplace string // Defaults to DefaultMarketplace
+	MaxPages    int    // Result pages to collect products from, defaults to 1
+	SampleSize  int    // Products to analyze, defaults to DefaultAnalysisSample
+}
+
+// validate applies the defaults and checks the limits
+func (o *SizeAnalysisOptions) validate() error {
+	o.SearchQuery = strings.TrimSpace(o.SearchQuery)
+	if o.SearchQuery == "" {
+		return errors.New("search query is required")
+	}
+	if o.Marketplace == "" {
+		o.Marketplace = DefaultMarketplace
+	}
+	if o.MaxPages == 0 {
+		o.MaxPages = 1
+	}
+	if o.SampleSize == 0 {
+		o.SampleSize = DefaultAnalysisSample
+	}
+	if o.MaxPages < 1 {
+		return errors.New("max pages must be positive")
+	}
+	if o.SampleSize < 1 || o.SampleSize > MaxAnalysisSample {
+		return fmt.Errorf("sample size must be between 1 and %d", MaxAnalysisSample)
+	}
+	return nil
+}
+
+// newSizeAnalysisJob creates a size analysis job of the given status together with its analysis
+func (m *Manager) newSizeAnalysisJob(ctx context.Context, opts SizeAnalysisOptions, status string) (*Job, *database.SizeAnalysis, error) {
+	if err := opts.validate(); err != nil {
+		return nil, nil, err
+	}
+
+	filters := map[string]string{}
+	if opts.Category != "" {
+		filters["i"] = opts.Category
+	}
+	analysis := &database.SizeAnalysis{
+		SearchURL:  buildSearchURL(opts.Marketplace, opts.SearchQuery, "", filters),
+		SampleSize: opts.SampleSize,
+	}
+	job := &Job{
+		SearchQuery: opts.SearchQuery,
+		Category:    CategorySizeAnalysis,
+		Marketplace: opts.Marketplace,
+		MaxPages:    opts.MaxPages,
+		Filters:     filters,
+		Status:      status,
+	}
+	// The worker must never see the job without its analysis
+	job.inTx = func(ctx context.Context, tx pgx.Tx) error {
+		analysis.JobID = &job.ID
+		return m.db.InsertSizeAnalysisWithTx(ctx, tx, analysis)
+	}
+
+	job, err := m.createJob(ctx, job)
+	if err != nil {
+		return nil, nil, err
+	}
+	return job, analysis, nil
+}
+
+// QueueSizeAnalysis creates a pending size analysis job for the worker
+func (m *Manager) QueueSizeAnalysis(ctx context.Context, opts SizeAnalysisOptions) (*Job, *database.SizeAnalysis, error) {
+	return m.newSizeAnalysisJob(ctx, opts, "pending")
+}
+
+// AnalyzeSizes runs a size analysis job in the caller, e.g. the analyze command, and returns the
+// completed analysis. The job sends heartbeats like one run by the worker, so no worker takes it over.
+func (m *Manager) AnalyzeSizes(ctx context.Context, opts SizeAnalysisOptions) (*Job, *database.SizeAnalysis, error) {
+	job, analysis, err := m.newSizeAnalysisJob(ctx, opts, "running")
+	if err != nil {
+		return nil, nil, err
+	}
+	m.publishJobEvent(ctx, events.EventTypeJobStarted, job.ID, nil)
+
+	jobCtx, cancelJob := context.WithCancelCause(ctx)
+	untrack := m.trackJob(job.ID, cancelJob)
+	stopHeartbeat := m.heartbeat(jobCtx, job.ID, cancelJob)
+	stats, err := m.runSizeAnalysis(jobCtx, job, analysis)
+	stopHeartbeat()
+	untrack()
+	cancelled := errors.Is(context.Cause(jobCtx), ErrJobCancelled)
+	cancelJob(nil)
+	if cancelled {
+		// CancelJob already stored the status and published JOB_CANCELLED
+		return job, analysis, ErrJobCancelled
+	}
+	if err != nil {
+		// Recorded even if the run was interrupted
+		ctx := context.WithoutCancel(ctx)
+		if statusErr := m.updateJobStatus(ctx, job.ID, "failed", err); statusErr != nil {
+			m.logger.ErrorContext(ctx, "failed to update job status", "id", job.ID, "error", statusErr)
+		}
+		m.publishJobEvent(ctx, events.EventTypeJobFailed, job.ID, err)
+		return job, analysis, err
+	}
+	if err := m.updateJobStatus(ctx, job.ID, "completed", nil); err != nil {
+		return nil, nil, fmt.Errorf("failed to complete size analysis job: %w", err)
+	}
+	m.publishJobEvent(ctx, events.EventTypeJobCompleted, job.ID, nil)
+
+	now := time.Now()
+	job.Status = "completed"
+	job.ProductsFound = stats.Total
+	job.CompletedAt = &now
+	analysis.Statistics = stats
+	analysis.CompletedAt = &now
+	return job, analysis, nil
+}
+
+// processSizeAnalysis runs a size analysis job picked up by the worker
+func (m *Manager) processSizeAnalysis(ctx context.Context, job *Job) error {
+	analysis, err := m.db.GetSizeAnalysisByJob(ctx, job.ID)
+	if err != nil {
+		return err
+	}
+	if analysis == nil {
+		return fmt.Errorf("job %s has no size analysis", job.ID)
+	}
+	_, err = m.runSizeAnalysis(ctx, job, analysis)
+	return err
+}
+
+// runSizeAnalysis collects the products of the analysis' search, classifies their size tables and
+// stores each result and the final statistics. Products analyzed before an interruption are kept.
+func (m *Manager) runSizeAnalysis(ctx context.Context, job *Job, analysis *database.SizeAnalysis) (analytics.Statistics, error) {
+	var stats analytics.Statistics
+
+	previous, err := m.db.ListSizeAnalysisResults(ctx, analysis.ID, "")
+	if err != nil {
+		return stats, err
+	}
+	done := make(map[string]bool, len(previous))
+	for _, r := range previous {
+		done[r.ASIN] = true
+		stats.Add(r.Status)
+	}
+
+	if err := m.scraper.GetBrowser().WaitMarketplace(ctx, analysis.SearchURL); err != nil {
+		return stats, err
+	}
+	crawler := scraper.NewCategoryCrawler(m.scraper, m.logger)
+	pages, err := crawler.CrawlFrom(ctx, analysis.SearchURL, 1, job.MaxPages)
+	if err != nil {
+		return stats, fmt.Errorf("failed to crawl search results: %w", err)
+	}
+	for _, page := range pages {
+		if errors.Is(page.Err, quota.ErrBudgetExceeded) {
+			return stats, page.Err
+		}
+		if page.Err != nil {
+			m.logger.WarnContext(ctx, "failed to crawl page", "page", page.Page, "error", page.Err)
+		}
+	}
+
+	products := analysisSample(pages, analysis.SampleSize, done)
+	m.logger.InfoContext(ctx, "analyzing size tables", "job_id", job.ID, "analysis_id", analysis.ID,
+		"products", len(products), "already_analyzed", len(done))
+
+	analyzer := analytics.NewAnalyzer(sizeChartExtractor{m.scraper}, analysisDelay)
+	_, err = analyzer.Run(ctx, products, func(r analytics.Result, err error) error {
+		if err != nil && (ctx.Err() != nil || errors.Is(err, quota.ErrBudgetExceeded)) {
+			return err
+		}
+		if err := m.db.SaveSizeAnalysisResult(ctx, analysis.ID, r); err != nil {
+			return err
+		}
+		stats.Add(r.Status)
+		if err := m.updateJobProgress(ctx, job.ID, len(pages), stats.Total, 0); err != nil {
+			m.logger.ErrorContext(ctx, "failed to update progress", "error", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return stats, err
+	}
+
+	if err := m.db.CompleteSizeAnalysis(ctx, analysis.ID, stats); err != nil {
+		return stats, err
+	}
+	m.logger.InfoContext(ctx, "size tables analyzed", "job_id", job.ID, "analysis_id", analysis.ID,
+		"total", stats.Total, "complete", stats.Complete, "no_table", stats.NoTable, "errors", stats.Errors)
+	return stats, nil
+}
+
+// analysisSample returns the products of the crawled pages in result order, without duplicates and
+// products already done, until done and returned products reach sampleSize
+func analysisSample(pages []*scraper.PageResult, sampleSize int, done map[string]bool) []analytics.Product {
+	seen := make(map[string]bool)
+	var products []analytics.Product
+	for _, page := range pages {
+		if page.Err != nil {
+			continue
+		}
+		for _, p := range page.Products {
+			if len(done)+len(products) >= sampleSize {
+				return products
+			}
+			if p.ASIN == "" || seen[p.ASIN] || done[p.ASIN] {
+				continue
+			}
+			seen[p.ASIN] = true
+			products = append(products, analytics.Product{ASIN: p.ASIN, Title: p.Title, URL: p.URL})
+		}
+	}
+	return products
+}
+
+// GetSizeAnalysis returns an analysis with its results of status, all if it is empty, nil if the
+// analysis does not exist
+func (m *Manager) GetSizeAnalysis(ctx context.Context, id, status string) (*database.SizeAnalysis, []analytics.Result, error) {
+	analysis, err := m.db.GetSizeAnalysis(ctx, id)
+	if err != nil || analysis == nil {
+		return nil, nil, err
+	}
+	results, err := m.db.ListSizeAnalysisResults(ctx, id, status)
+	if err != nil {
+		return nil, nil, err
+	}
+	return analysis, results, nil
+}
+
+// ListSizeAnalyses returns the latest size analyses, newest first
+func (m *Manager) ListSizeAnalyses(ctx context.Context, limit int) ([]*database.SizeAnalysis, error) {
+	return m.db.ListSizeAnalyses(ctx, limit)
+}
+
+// sizeChartExtractor extracts size tables for the analyzer with the scraper's size chart extraction
+type sizeChartExtractor struct {
+	scraper *scraper.Service
+}
+
+func (e sizeChartExtractor) ExtractTable(ctx context.Context, asin, url string) (*analytics.Table, error) {
+	dimensions, err := e.scraper.ExtractSizeChart(ctx, asin, url)
+	if err != nil {
+		return nil, err
+	}
+	if !dimensions.Found || dimensions.SizeTable == nil {
+		return nil, nil
+	}
+	st := dimensions.SizeTable
+	return &analytics.Table{Sizes: st.Sizes, Measurements: st.Measurements, Source: st.Source}, nil
+}
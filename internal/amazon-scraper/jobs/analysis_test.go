@@ -0,0 +1,42 @@
+package jobs
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/scraper"
+)
+
+func TestAnalysisSample(t *testing.T) {
+	pages := []*scraper.PageResult{
+		{Page: 1, Products: []*scraper.Product{{ASIN: "B000000001"}, {ASIN: "B000000002"}, {ASIN: "B000000001"}}},
+		{Page: 2, Err: errors.New("captcha"), Products: []*scraper.Product{{ASIN: "B000000009"}}},
+		{Page: 3, Products: []*scraper.Product{{ASIN: "B000000003", Title: "T-Shirt"}, {ASIN: "B000000004"}}},
+	}
+
+	// B000000002 was analyzed before an interruption and counts towards the sample
+	products := analysisSample(pages, 3, map[string]bool{"B000000002": true})
+	if len(products) != 2 || products[0].ASIN != "B000000001" || products[1].ASIN != "B000000003" || products[1].Title != "T-Shirt" {
+		t.Errorf("analysisSample() = %+v, want B000000001 and B000000003", products)
+	}
+
+	if products := analysisSample(pages, 10, nil); len(products) != 4 {
+		t.Errorf("analysisSample() returned %d products, want the 4 distinct ASINs of the crawled pages", len(products))
+	}
+}
+
+func TestSizeAnalysisOptionsValidate(t *testing.T) {
+	opts := SizeAnalysisOptions{SearchQuery: " t shirt größentabelle "}
+	if err := opts.validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.SearchQuery != "t shirt größentabelle" || opts.Marketplace != DefaultMarketplace || opts.MaxPages != 1 || opts.SampleSize != DefaultAnalysisSample {
+		t.Errorf("defaults not applied: %+v", opts)
+	}
+
+	for _, opts := range []SizeAnalysisOptions{{}, {SearchQuery: "jeans", SampleSize: MaxAnalysisSample + 1}, {SearchQuery: "jeans", MaxPages: -1}} {
+		if err := opts.validate(); err == nil {
+			t.Errorf("validate(%+v) succeeded, want an error", opts)
+		}
+	}
+}
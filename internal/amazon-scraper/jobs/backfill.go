@@ -0,0 +1,138 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/events"
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+)
+
+// DefaultBackfillBatchSize is the number of outbox events inserted per transaction
+const DefaultBackfillBatchSize = 100
+
+// BackfillOptions controls a NEW_PRODUCT_DETECTED backfill
+type BackfillOptions struct {
+	Filter    database.BackfillFilter
+	BatchSize int           // 0 uses DefaultBackfillBatchSize
+	Throttle  time.Duration // Pause between batches so the relay and consumers keep up
+	Limit     int           // Stop after this many products, 0 is unlimited
+	DryRun    bool          // Count matching products without inserting events
+	After     string        // Start after this ASIN, e.g. LastASIN of an interrupted run
+}
+
+// BackfillResult counts the outcome of a backfill
+type BackfillResult struct {
+	Scanned   int    `json:"scanned"`
+	Published int    `json:"published"`
+	Skipped   int    `json:"skipped"`   // Stored data could not be decoded
+	LastASIN  string `json:"last_asin"` // Resume point after an interrupted run
+}
+
+// Backfill re-emits NEW_PRODUCT_DETECTED for stored products matching the filter. Payloads are rebuilt
+// from the products table without scraping and inserted into the outbox in batches.
+func (m *Manager) Backfill(ctx context.Context, opts BackfillOptions) (*BackfillResult, error) {
+	if m.publisher == nil && !opts.DryRun {
+		return nil, fmt.Errorf("backfill requires an event publisher")
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBackfillBatchSize
+	}
+
+	result := &BackfillResult{LastASIN: opts.After}
+	for {
+		size := opts.BatchSize
+		if opts.Limit > 0 {
+			size = min(size, opts.Limit-result.Scanned)
+			if size <= 0 {
+				break
+			}
+		}
+
+		products, err := m.db.ListProductsForBackfill(ctx, opts.Filter, result.LastASIN, size)
+		if err != nil {
+			return result, err
+		}
+		if len(products) == 0 {
+			break
+		}
+
+		payloads := make([]*events.NewProductDetectedPayload, 0, len(products))
+		for _, p := range products {
+			payload, err := backfillPayload(p)
+			if err != nil {
+				m.logger.WarnContext(ctx, "skipping product with undecodable data", "asin", p.ASIN, "error", err)
+				result.Skipped++
+				continue
+			}
+			payloads = append(payloads, payload)
+		}
+
+		if !opts.DryRun && len(payloads) > 0 {
+			if err := m.publisher.PublishNewProductDetectedBatch(ctx, payloads); err != nil {
+				return result, err
+			}
+		}
+
+		result.Scanned += len(products)
+		result.Published += len(payloads)
+		result.LastASIN = products[len(products)-1].ASIN
+
+		m.logger.InfoContext(ctx, "backfill batch done",
+			"scanned", result.Scanned,
+			"published", result.Published,
+			"last_asin", result.LastASIN,
+			"dry_run", opts.DryRun)
+
+		if len(products) < size {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		case <-time.After(opts.Throttle):
+		}
+	}
+
+	return result, nil
+}
+
+// backfillPayload rebuilds the event payload of a stored product
+func backfillPayload(p *database.ProductLifecycle) (*events.NewProductDetectedPayload, error) {
+	payload := &events.NewProductDetectedPayload{
+		ASIN:          p.ASIN,
+		Title:         p.Title,
+		Brand:         p.Brand,
+		DetailPageURL: p.DetailPageURL,
+		Category:      p.Category,
+		CategoryCode:  p.CategoryCode,
+		Rating:        p.Rating,
+		ReviewCount:   p.ReviewCount,
+		Source:        "scraper",
+	}
+
+	if len(p.SizeTable) > 0 {
+		var st database.SizeTable
+		if err := json.Unmarshal(p.SizeTable, &st); err != nil {
+			return nil, fmt.Errorf("invalid size table: %w", err)
+		}
+		payload.SizeTable = &st
+		payload.AvailableSizes = st.Sizes
+	}
+	if len(p.FitFeedback) > 0 {
+		var fit database.FitFeedback
+		if err := json.Unmarshal(p.FitFeedback, &fit); err != nil {
+			return nil, fmt.Errorf("invalid fit feedback: %w", err)
+		}
+		payload.FitFeedback = &fit
+	}
+	if len(p.SizePrices) > 0 {
+		if err := json.Unmarshal(p.SizePrices, &payload.SizePrices); err != nil {
+			return nil, fmt.Errorf("invalid size prices: %w", err)
+		}
+	}
+
+	return payload, nil
+}
@@ -0,0 +1,59 @@
+package jobs
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+)
+
+func TestBackfillPayload(t *testing.T) {
+	rating := 4.5
+	p := &database.ProductLifecycle{
+		ASIN:          "B08N5WRWNW",
+		Title:         "Tall T-Shirt",
+		Brand:         "TallFit",
+		DetailPageURL: "https://www.amazon.de/dp/B08N5WRWNW",
+		Rating:        &rating,
+		SizeTable:     json.RawMessage(`{"sizes":["M","L"],"measurements":{"M":{"length":78,"chest":52}},"unit":"cm"}`),
+		FitFeedback:   json.RawMessage(`{"summary":"Fällt normal aus","fit":"true_to_size","too_small_percent":10,"true_to_size_percent":80,"too_large_percent":10}`),
+	}
+
+	payload, err := backfillPayload(p)
+	if err != nil {
+		t.Fatalf("backfillPayload() error = %v", err)
+	}
+
+	if payload.ASIN != p.ASIN || payload.Brand != "TallFit" || payload.DetailPageURL != p.DetailPageURL {
+		t.Errorf("Unexpected product fields: %+v", payload)
+	}
+	if payload.Rating == nil || *payload.Rating != 4.5 {
+		t.Errorf("Expected rating 4.5, got %v", payload.Rating)
+	}
+	if !payload.HasValidSizeTable() {
+		t.Error("Expected the stored size table to be valid")
+	}
+	if len(payload.AvailableSizes) != 2 {
+		t.Errorf("Expected available sizes from the size table, got %v", payload.AvailableSizes)
+	}
+	if payload.FitFeedback == nil || payload.FitFeedback.Fit != database.FitTrueToSize {
+		t.Errorf("Expected fit feedback, got %+v", payload.FitFeedback)
+	}
+}
+
+func TestBackfillPayloadWithoutSizeTable(t *testing.T) {
+	payload, err := backfillPayload(&database.ProductLifecycle{ASIN: "B000000001", Title: "Shirt"})
+	if err != nil {
+		t.Fatalf("backfillPayload() error = %v", err)
+	}
+	if payload.SizeTable != nil || payload.FitFeedback != nil {
+		t.Errorf("Expected no size table and fit feedback, got %+v", payload)
+	}
+}
+
+func TestBackfillPayloadRejectsInvalidSizeTable(t *testing.T) {
+	_, err := backfillPayload(&database.ProductLifecycle{ASIN: "B000000001", SizeTable: json.RawMessage(`"broken`)})
+	if err == nil {
+		t.Error("Expected an error for an undecodable size table")
+	}
+}
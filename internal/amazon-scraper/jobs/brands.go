@@ -0,0 +1,85 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/maltedev/amazon-size-scraper/internal/brand"
+)
+
+var (
+	// ErrAliasNotFound is returned when a brand alias does not exist
+	ErrAliasNotFound = errors.New("brand alias not found")
+	// ErrInvalidAlias is returned when a brand alias fails validation
+	ErrInvalidAlias = errors.New("invalid brand alias")
+)
+
+// LoadBrandAliases applies the stored brand aliases to the registry used by jobs
+func (m *Manager) LoadBrandAliases(ctx context.Context) error {
+	aliases, err := m.ListBrandAliases(ctx)
+	if err != nil {
+		return err
+	}
+	m.brands.SetAliases(aliases)
+	return nil
+}
+
+// ListBrandAliases returns the stored brand aliases ordered by brand
+func (m *Manager) ListBrandAliases(ctx context.Context) ([]brand.Alias, error) {
+	rows, err := m.db.Query(ctx, `SELECT alias, brand FROM brand_aliases ORDER BY brand, alias`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list brand aliases: %w", err)
+	}
+	defer rows.Close()
+
+	aliases := []brand.Alias{}
+	for rows.Next() {
+		var alias brand.Alias
+		if err := rows.Scan(&alias.Alias, &alias.Brand); err != nil {
+			return nil, fmt.Errorf("failed to scan brand alias: %w", err)
+		}
+		aliases = append(aliases, alias)
+	}
+	return aliases, rows.Err()
+}
+
+// SaveBrandAlias creates or replaces a brand alias and applies it to the following products. Products
+// stored before keep their brand.
+func (m *Manager) SaveBrandAlias(ctx context.Context, alias *brand.Alias) error {
+	if err := alias.Normalize(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidAlias, err)
+	}
+
+	query := `
+		INSERT INTO brand_aliases (alias_key, alias, brand)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (alias_key) DO UPDATE SET
+			alias = EXCLUDED.alias,
+			brand = EXCLUDED.brand,
+			updated_at = CURRENT_TIMESTAMP
+	`
+	if _, err := m.db.Exec(ctx, query, brand.Key(alias.Alias), alias.Alias, alias.Brand); err != nil {
+		return fmt.Errorf("failed to save brand alias: %w", err)
+	}
+
+	m.logger.InfoContext(ctx, "brand alias saved", "alias", alias.Alias, "brand", alias.Brand)
+	return m.LoadBrandAliases(ctx)
+}
+
+// DeleteBrandAlias removes a brand alias, matched by any spelling of it
+func (m *Manager) DeleteBrandAlias(ctx context.Context, alias string) error {
+	key := brand.Key(alias)
+	if key == "" {
+		return fmt.Errorf("%w: alias is required", ErrInvalidAlias)
+	}
+
+	tag, err := m.db.Exec(ctx, `DELETE FROM brand_aliases WHERE alias_key = $1`, key)
+	if err != nil {
+		return fmt.Errorf("failed to delete brand alias: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAliasNotFound
+	}
+	return m.LoadBrandAliases(ctx)
+}
@@ -0,0 +1,135 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// heartbeatInterval is how often a running job shows the worker is still alive
+	heartbeatInterval = 30 * time.Second
+	// orphanedJobAfter is how long a running job may miss heartbeats before it is recovered
+	orphanedJobAfter = 2 * time.Minute
+)
+
+// Checkpoint is how far a job got, stored while it runs so an interrupted job resumes where it stopped
+type Checkpoint struct {
+	Page             int             // Last search result page whose products were all processed
+	ASINs            map[string]bool // Processed products of the pages after Page
+	ProductsFound    int
+	ProductsFiltered int
+}
+
+// Done reports whether the product was processed before the job was interrupted
+func (c *Checkpoint) Done(asin string) bool {
+	return c != nil && c.ASINs[asin]
+}
+
+// retrySkip reports whether a product skipped for the reason is extracted again when the job resumes
+func retrySkip(reason string) bool {
+	switch reason {
+	case SkipTimeout, SkipCaptcha, SkipCooldown, SkipSaveFailed:
+		return true
+	}
+	return false
+}
+
+// loadCheckpoint returns the checkpoint of a job, a job that never ran has an empty one
+func (m *Manager) loadCheckpoint(ctx context.Context, jobID string) (*Checkpoint, error) {
+	query := `
+		SELECT checkpoint_page, checkpoint_asins, products_found, products_filtered
+		FROM scraper_jobs
+		WHERE id = $1
+	`
+
+	var asins []string
+	cp := &Checkpoint{}
+	if err := m.db.QueryRow(ctx, query, jobID).Scan(&cp.Page, &asins, &cp.ProductsFound, &cp.ProductsFiltered); err != nil {
+		return nil, fmt.Errorf("failed to load job checkpoint: %w", err)
+	}
+	cp.ASINs = make(map[string]bool, len(asins))
+	for _, asin := range asins {
+		cp.ASINs[asin] = true
+	}
+	return cp, nil
+}
+
+// checkpointProduct records a processed product together with the current counts
+func (m *Manager) checkpointProduct(ctx context.Context, jobID, asin string, productsFound, productsFiltered int) {
+	query := `
+		UPDATE scraper_jobs
+		SET checkpoint_asins = array_append(checkpoint_asins, $2),
+		    products_found = $3, products_filtered = $4, heartbeat_at = NOW()
+		WHERE id = $1
+	`
+	if _, err := m.db.Exec(ctx, query, jobID, asin, productsFound, productsFiltered); err != nil {
+		m.logger.WarnContext(ctx, "failed to checkpoint product", "asin", asin, "error", err)
+	}
+}
+
+// heartbeat keeps the job from being recovered as orphaned until the returned stop is called. A job
+// cancelled through another instance is noticed here and stopped with cancelJob.
+func (m *Manager) heartbeat(ctx context.Context, jobID string, cancelJob context.CancelCauseFunc) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				var status string
+				err := m.db.QueryRow(ctx, `UPDATE scraper_jobs SET heartbeat_at = NOW() WHERE id = $1 RETURNING status`, jobID).Scan(&status)
+				if err != nil && ctx.Err() == nil {
+					m.logger.WarnContext(ctx, "failed to update job heartbeat", "job_id", jobID, "error", err)
+				}
+				if status == "cancelled" {
+					cancelJob(ErrJobCancelled)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// RecoverJobs returns running jobs whose worker stopped sending heartbeats, e.g. after a restart, to
+// pending. They resume from their checkpoint. Import and analytics jobs run in the caller and are never
+// picked up by the worker, so they are not recovered. It returns the number of recovered jobs.
+func (m *Manager) RecoverJobs(ctx context.Context) (int, error) {
+	query := `
+		UPDATE scraper_jobs
+		SET status = 'pending', not_before = NULL
+		WHERE status = 'running'
+		  AND category NOT IN ($2, $3)
+		  AND (heartbeat_at IS NULL OR heartbeat_at < NOW() - make_interval(secs => $1))
+		RETURNING id, checkpoint_page
+	`
+
+	rows, err := m.db.Query(ctx, query, orphanedJobAfter.Seconds(), CategoryImport, CategoryAnalytics)
+	if err != nil {
+		return 0, fmt.Errorf("failed to recover orphaned jobs: %w", err)
+	}
+	defer rows.Close()
+
+	recovered := 0
+	for rows.Next() {
+		var jobID string
+		var page int
+		if err := rows.Scan(&jobID, &page); err != nil {
+			return recovered, fmt.Errorf("failed to scan recovered job: %w", err)
+		}
+		m.logger.WarnContext(ctx, "recovered orphaned job", "job_id", jobID, "checkpoint_page", page)
+		recovered++
+	}
+	return recovered, rows.Err()
+}
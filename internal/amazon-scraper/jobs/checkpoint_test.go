@@ -0,0 +1,35 @@
+package jobs
+
+import "testing"
+
+func TestCheckpointDone(t *testing.T) {
+	cp := &Checkpoint{Page: 2, ASINs: map[string]bool{"B0TEST0001": true}}
+	if !cp.Done("B0TEST0001") {
+		t.Error("checkpointed product should be done")
+	}
+	if cp.Done("B0TEST0002") {
+		t.Error("unknown product should not be done")
+	}
+
+	var empty *Checkpoint
+	if empty.Done("B0TEST0001") {
+		t.Error("nil checkpoint should have no products")
+	}
+}
+
+func TestRetrySkip(t *testing.T) {
+	tests := map[string]bool{
+		SkipTimeout:     true,
+		SkipCaptcha:     true,
+		SkipCooldown:    true,
+		SkipSaveFailed:  true,
+		SkipFiltered:    false,
+		SkipNoSizeTable: false,
+		SkipParseError:  false,
+	}
+	for reason, want := range tests {
+		if got := retrySkip(reason); got != want {
+			t.Errorf("retrySkip(%q) = %v, want %v", reason, got, want)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/events"
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/scraper"
+	"github.com/maltedev/amazon-size-scraper/internal/asin"
+)
+
+var (
+	// ErrJobCancelled is the cause of a job context stopped by CancelJob
+	ErrJobCancelled = errors.New("job cancelled")
+	// ErrJobNotFound is returned for an unknown job ID
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobFinished is returned when cancelling a job that already completed, failed or was cancelled
+	ErrJobFinished = errors.New("job already finished")
+	// ErrProductNotFound is returned when refreshing a product that was never stored
+	ErrProductNotFound = errors.New("product not found")
+)
+
+// Outcomes of scraping a single product
+const (
+	ScrapeSaved     = "saved"     // Stored and announced with NEW_PRODUCT_DETECTED
+	ScrapeUnchanged = "unchanged" // Same content hash as the stored product, only last_checked_at moved
+	ScrapeDuplicate = "duplicate" // Stored and linked to the canonical ASIN of a known duplicate
+)
+
+// ProductScrape is the outcome of scraping a single product outside a crawl job
+type ProductScrape struct {
+	ASIN          string `json:"asin"`
+	Result        string `json:"result"` // ScrapeSaved, ScrapeUnchanged or ScrapeDuplicate
+	CanonicalASIN string `json:"canonical_asin,omitempty"`
+}
+
+// ScrapeProduct extracts and stores one product like a crawl job would, without creating a job. An
+// empty productURL uses the product page on the default marketplace. Caller metadata in ctx, see
+// events.WithMetadata, is stored with the product and carried in its event.
+func (m *Manager) ScrapeProduct(ctx context.Context, productASIN, productURL string) (*ProductScrape, error) {
+	if productURL == "" {
+		productURL = asin.Product{ASIN: productASIN, Marketplace: asin.DefaultMarketplace}.URL()
+	}
+	listing := &scraper.Product{ASIN: productASIN, URL: productURL}
+
+	if err := m.scraper.GetBrowser().WaitMarketplace(ctx, productURL); err != nil {
+		return nil, err
+	}
+	product, err := m.extractCompleteProductData(ctx, listing)
+	if err != nil {
+		if scraper.Blocked(err) {
+			m.saveFallbackProduct(ctx, listing)
+		}
+		return nil, err
+	}
+	m.scraper.FillFromFallback(ctx, product)
+	m.applyReportingPrice(ctx, product)
+
+	result := &ProductScrape{ASIN: product.ASIN, Result: ScrapeSaved}
+	unchanged, err := m.db.MarkProductUnchanged(ctx, product.ASIN, product.ContentHash())
+	if err != nil {
+		m.logger.WarnContext(ctx, "failed to compare content hash", "asin", product.ASIN, "error", err)
+	}
+	if unchanged {
+		m.mergeProductMetadata(ctx, product.ASIN)
+		result.Result = ScrapeUnchanged
+		return result, nil
+	}
+
+	if err := m.storeCompleteProduct(ctx, product); err != nil {
+		return nil, err
+	}
+	if canonical := m.registerFingerprint(ctx, product); canonical != product.ASIN {
+		result.Result, result.CanonicalASIN = ScrapeDuplicate, canonical
+	} else if err := m.publishEnhancedProductEvent(ctx, product); err != nil {
+		m.logger.ErrorContext(ctx, "failed to publish event", "asin", product.ASIN, "error", err)
+	}
+	return result, nil
+}
+
+// RefreshProduct scrapes a stored product again from its stored product page
+func (m *Manager) RefreshProduct(ctx context.Context, productASIN string) (*ProductScrape, error) {
+	stored, err := m.db.GetProductLifecycleByASIN(ctx, productASIN)
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		return nil, fmt.Errorf("%w: %s", ErrProductNotFound, productASIN)
+	}
+	return m.ScrapeProduct(ctx, productASIN, stored.DetailPageURL)
+}
+
+// CancelJob cancels a pending or running job and publishes JOB_CANCELLED. A job running on this instance
+// stops right away, one running on another instance at its next heartbeat.
+func (m *Manager) CancelJob(ctx context.Context, jobID string) (*Job, error) {
+	query := `
+		UPDATE scraper_jobs
+		SET status = 'cancelled', completed_at = NOW(), error = $2
+		WHERE id = $1 AND status IN ('pending', 'running')
+		RETURNING id
+	`
+	var id string
+	err := m.db.QueryRow(ctx, query, jobID, ErrJobCancelled.Error()).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		var status string
+		err := m.db.QueryRow(ctx, `SELECT status FROM scraper_jobs WHERE id = $1`, jobID).Scan(&status)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s", ErrJobNotFound, jobID)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get job status: %w", err)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrJobFinished, status)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel job: %w", err)
+	}
+
+	m.runningMu.Lock()
+	if cancel, ok := m.running[jobID]; ok {
+		cancel(ErrJobCancelled)
+	}
+	m.runningMu.Unlock()
+
+	m.logger.InfoContext(ctx, "job cancelled", "id", jobID)
+	m.publishJobEvent(ctx, events.EventTypeJobCancelled, jobID, nil)
+	return m.GetJob(ctx, jobID)
+}
+
+// trackJob registers a job processed by this instance for CancelJob until the returned untrack is called
+func (m *Manager) trackJob(jobID string, cancel context.CancelCauseFunc) (untrack func()) {
+	m.runningMu.Lock()
+	m.running[jobID] = cancel
+	m.runningMu.Unlock()
+
+	return func() {
+		m.runningMu.Lock()
+		delete(m.running, jobID)
+		m.runningMu.Unlock()
+	}
+}
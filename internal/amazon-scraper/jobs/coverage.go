@@ -0,0 +1,98 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/events"
+	"github.com/maltedev/amazon-size-scraper/internal/analytics"
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+)
+
+// CategoryAnalytics marks analytics jobs, which measure stored products instead of scraping
+const CategoryAnalytics = "analytics"
+
+// MaxCoverageSample limits the products sampled by one coverage analysis
+const MaxCoverageSample = 5000
+
+// AnalyzeCoverage samples up to sampleSize scraped products of a category code, all categories if it is
+// empty, and stores how many have a size table, length and width measurements and which measurement
+// types. Like an import the analytics job is never picked up by the worker, it completes as soon as the
+// report is stored.
+func (m *Manager) AnalyzeCoverage(ctx context.Context, category string, sampleSize int) (*Job, *database.CoverageReport, error) {
+	if sampleSize < 1 || sampleSize > MaxCoverageSample {
+		return nil, nil, fmt.Errorf("sample size must be between 1 and %d", MaxCoverageSample)
+	}
+
+	job, err := m.createJob(ctx, &Job{
+		SearchQuery: "coverage:" + category,
+		Category:    CategoryAnalytics,
+		Marketplace: DefaultMarketplace,
+		Status:      "running",
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	m.publishJobEvent(ctx, events.EventTypeJobStarted, job.ID, nil)
+
+	report, err := m.measureCoverage(ctx, job.ID, category, sampleSize)
+	if err != nil {
+		if statusErr := m.updateJobStatus(ctx, job.ID, "failed", err); statusErr != nil {
+			m.logger.ErrorContext(ctx, "failed to update job status", "id", job.ID, "error", statusErr)
+		}
+		m.publishJobEvent(ctx, events.EventTypeJobFailed, job.ID, err)
+		return nil, nil, err
+	}
+
+	if err := m.updateJobProgress(ctx, job.ID, 0, report.Sampled, 0); err != nil {
+		return nil, nil, fmt.Errorf("failed to update job progress: %w", err)
+	}
+	if err := m.updateJobStatus(ctx, job.ID, "completed", nil); err != nil {
+		return nil, nil, fmt.Errorf("failed to complete analytics job: %w", err)
+	}
+	m.publishJobEvent(ctx, events.EventTypeJobCompleted, job.ID, nil)
+
+	m.logger.InfoContext(ctx, "coverage analyzed", "job_id", job.ID, "category", category,
+		"sampled", report.Sampled, "with_size_table", report.WithSizeTable, "complete", report.Complete)
+
+	now := time.Now()
+	job.Status = "completed"
+	job.ProductsFound = report.Sampled
+	job.StartedAt = &job.CreatedAt
+	job.CompletedAt = &now
+	return job, report, nil
+}
+
+// measureCoverage samples the products and stores their coverage report
+func (m *Manager) measureCoverage(ctx context.Context, jobID, category string, sampleSize int) (*database.CoverageReport, error) {
+	tables, err := m.db.SampleSizeTables(ctx, category, sampleSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var coverage analytics.Coverage
+	for _, st := range tables {
+		if st == nil {
+			coverage.Add(nil, nil)
+			continue
+		}
+		coverage.Add(st.Sizes, st.Measurements)
+	}
+
+	report := &database.CoverageReport{
+		JobID:      &jobID,
+		Category:   category,
+		SampleSize: sampleSize,
+		Coverage:   coverage,
+	}
+	if err := m.db.InsertCoverageReport(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// ListCoverageReports returns the coverage reports of a category code created after since, newest first
+func (m *Manager) ListCoverageReports(ctx context.Context, category string, since time.Time, limit int) ([]*database.CoverageReport, error) {
+	return m.db.ListCoverageReports(ctx, category, since, limit)
+}
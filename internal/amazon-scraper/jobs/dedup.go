@@ -0,0 +1,59 @@
+package jobs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// DefaultDedupWindow is how long an identical search job is returned instead of creating a new one
+const DefaultDedupWindow = 10 * time.Minute
+
+// SetDedupWindow sets how long an identical search job is returned instead of creating a new one, 0
+// disables deduplication
+func (m *Manager) SetDedupWindow(d time.Duration) {
+	m.dedupWindow = d
+}
+
+// dedupKey identifies identical search jobs, the same marketplace, query and category regardless of
+// case and whitespace
+func dedupKey(marketplace, searchQuery, category string) string {
+	if marketplace == "" {
+		marketplace = DefaultMarketplace
+	}
+	normalize := func(s string) string {
+		return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+	}
+	sum := sha256.Sum256([]byte(normalize(marketplace) + "\x00" + normalize(searchQuery) + "\x00" + normalize(category)))
+	return hex.EncodeToString(sum[:])
+}
+
+// findDuplicateJob returns the ID of the latest job with key created within window that did not fail,
+// empty if there is none. It holds a lock on the key until tx ends, so identical jobs created at the
+// same time on any instance see each other.
+func findDuplicateJob(ctx context.Context, tx pgx.Tx, key string, window time.Duration) (string, error) {
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, key); err != nil {
+		return "", fmt.Errorf("failed to lock job key: %w", err)
+	}
+
+	var id string
+	err := tx.QueryRow(ctx, `
+		SELECT id FROM scraper_jobs
+		WHERE dedup_key = $1 AND created_at > $2 AND status <> 'failed'
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, key, time.Now().Add(-window)).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to look up identical job: %w", err)
+	}
+	return id, nil
+}
@@ -0,0 +1,29 @@
+package jobs
+
+import "testing"
+
+func TestDedupKey(t *testing.T) {
+	key := dedupKey(DefaultMarketplace, "tall t-shirt", "fashion")
+
+	same := [][3]string{
+		{"", "tall t-shirt", "fashion"},
+		{"amazon.de", "  Tall   T-Shirt ", "Fashion"},
+	}
+	for _, s := range same {
+		if got := dedupKey(s[0], s[1], s[2]); got != key {
+			t.Errorf("dedupKey(%q, %q, %q) differs from the identical job", s[0], s[1], s[2])
+		}
+	}
+
+	different := [][3]string{
+		{"amazon.co.uk", "tall t-shirt", "fashion"},
+		{"amazon.de", "tall t-shirts", "fashion"},
+		{"amazon.de", "tall t-shirt", ""},
+		{"amazon.de", "tall", "t-shirt fashion"},
+	}
+	for _, d := range different {
+		if got := dedupKey(d[0], d[1], d[2]); got == key {
+			t.Errorf("dedupKey(%q, %q, %q) matches a different job", d[0], d[1], d[2])
+		}
+	}
+}
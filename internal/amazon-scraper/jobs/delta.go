@@ -0,0 +1,164 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/scraper"
+)
+
+const (
+	// DefaultDeltaThreshold is the share of known ASINs on a result page that ends a delta crawl
+	DefaultDeltaThreshold = 0.8
+	// deltaSort orders search results by "Neuerscheinungen", newest listings first
+	deltaSort = "date-desc-rank"
+	// maxFrontierASINs caps the ASINs a template's crawl frontier remembers
+	maxFrontierASINs = 2000
+)
+
+// CrawlFrontier is where the delta crawls of a template stand
+type CrawlFrontier struct {
+	ASINs        int       `json:"asins"` // ASINs listed by its delta crawls, they count as known
+	PagesCrawled int       `json:"pages_crawled"`
+	StopPage     int       `json:"stop_page,omitempty"` // Page of mostly known ASINs the last crawl stopped at, 0 if it ran out of pages
+	KnownShare   float64   `json:"known_share,omitempty"`
+	JobID        string    `json:"job_id"`
+	CrawledAt    time.Time `json:"crawled_at"`
+}
+
+// deltaCrawl decides when a delta crawl stops and collects the frontier it reached
+type deltaCrawl struct {
+	threshold float64
+	listed    []string // ASINs of all crawled pages in result order
+	pages     int
+	stopPage  int
+	share     float64
+}
+
+// page records a result page with known of its ASINs known and reports whether the crawl stops. A page
+// without products ends the crawl as well.
+func (d *deltaCrawl) page(result *scraper.PageResult, known int) bool {
+	d.pages++
+	for _, p := range result.Products {
+		d.listed = append(d.listed, p.ASIN)
+	}
+	if len(result.Products) == 0 {
+		return true
+	}
+	d.share = float64(known) / float64(len(result.Products))
+	if d.share >= d.threshold {
+		d.stopPage = result.Page
+		return true
+	}
+	return false
+}
+
+// mergeFrontier puts the listed ASINs in front of the previous frontier without duplicates and caps it
+func mergeFrontier(listed, previous []string, limit int) []string {
+	seen := make(map[string]bool, len(listed)+len(previous))
+	merged := make([]string, 0, min(len(listed)+len(previous), limit))
+	for _, list := range [][]string{listed, previous} {
+		for _, asin := range list {
+			if len(merged) == limit {
+				return merged
+			}
+			if !seen[asin] {
+				seen[asin] = true
+				merged = append(merged, asin)
+			}
+		}
+	}
+	return merged
+}
+
+// knownASINs counts the ASINs that are stored products or in the crawl frontier of the template
+func (m *Manager) knownASINs(ctx context.Context, templateID string, asins []string) (int, error) {
+	query := `
+		SELECT COUNT(DISTINCT a.asin)
+		FROM unnest($1::text[]) AS a(asin)
+		WHERE EXISTS (SELECT 1 FROM products p WHERE p.asin = a.asin)
+		   OR a.asin = ANY(COALESCE((SELECT asins FROM template_crawl_frontier WHERE template_id::text = $2), '{}'))
+	`
+	var known int
+	if err := m.db.QueryRow(ctx, query, asins, templateID).Scan(&known); err != nil {
+		return 0, fmt.Errorf("failed to count known ASINs: %w", err)
+	}
+	return known, nil
+}
+
+// crawlDelta crawls the result pages from fromPage until one lists mostly known ASINs. A failed lookup
+// of known ASINs keeps crawling, a delta crawl never ends early by mistake.
+func (m *Manager) crawlDelta(ctx context.Context, crawler *scraper.CategoryCrawler, job *Job, searchURL string, fromPage int) ([]*scraper.PageResult, *deltaCrawl, error) {
+	delta := &deltaCrawl{threshold: job.DeltaThreshold}
+	results, err := crawler.CrawlUntil(ctx, searchURL, fromPage, job.MaxPages, func(result *scraper.PageResult) bool {
+		asins := make([]string, len(result.Products))
+		for i, p := range result.Products {
+			asins[i] = p.ASIN
+		}
+		known, err := m.knownASINs(ctx, *job.TemplateID, asins)
+		if err != nil {
+			m.logger.WarnContext(ctx, "failed to look up known ASINs", "job", job.ID, "page", result.Page, "error", err)
+			known = 0
+		}
+		stop := delta.page(result, known)
+		m.logger.InfoContext(ctx, "delta crawl page", "job", job.ID, "page", result.Page,
+			"products", len(result.Products), "known", known, "stop", stop)
+		return stop
+	})
+	return results, delta, err
+}
+
+// saveFrontier records where the delta crawl of a template job stopped and adds the ASINs it listed
+func (m *Manager) saveFrontier(ctx context.Context, job *Job, delta *deltaCrawl) error {
+	return m.db.Transaction(ctx, func(tx pgx.Tx) error {
+		var previous []string
+		err := tx.QueryRow(ctx, `SELECT asins FROM template_crawl_frontier WHERE template_id::text = $1 FOR UPDATE`, *job.TemplateID).Scan(&previous)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("failed to read crawl frontier: %w", err)
+		}
+
+		var stopPage *int
+		if delta.stopPage > 0 {
+			stopPage = &delta.stopPage
+		}
+		_, err = tx.Exec(ctx, `
+			INSERT INTO template_crawl_frontier (template_id, asins, pages_crawled, stop_page, known_share, job_id, crawled_at)
+			VALUES ($1, $2, $3, $4, $5, $6, NOW())
+			ON CONFLICT (template_id) DO UPDATE SET
+				asins = EXCLUDED.asins, pages_crawled = EXCLUDED.pages_crawled, stop_page = EXCLUDED.stop_page,
+				known_share = EXCLUDED.known_share, job_id = EXCLUDED.job_id, crawled_at = EXCLUDED.crawled_at
+		`, *job.TemplateID, mergeFrontier(delta.listed, previous, maxFrontierASINs), delta.pages, stopPage, delta.share, job.ID)
+		if err != nil {
+			return fmt.Errorf("failed to save crawl frontier: %w", err)
+		}
+		return nil
+	})
+}
+
+// getFrontier returns the crawl frontier of a template, nil before its first delta crawl
+func (m *Manager) getFrontier(ctx context.Context, templateID string) (*CrawlFrontier, error) {
+	f := &CrawlFrontier{}
+	var stopPage *int
+	var share *float64
+	err := m.db.QueryRow(ctx, `
+		SELECT cardinality(asins), pages_crawled, stop_page, known_share, COALESCE(job_id::text, ''), crawled_at
+		FROM template_crawl_frontier
+		WHERE template_id::text = $1
+	`, templateID).Scan(&f.ASINs, &f.PagesCrawled, &stopPage, &share, &f.JobID, &f.CrawledAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get crawl frontier: %w", err)
+	}
+	if stopPage != nil {
+		f.StopPage = *stopPage
+	}
+	if share != nil {
+		f.KnownShare = *share
+	}
+	return f, nil
+}
@@ -0,0 +1,45 @@
+package jobs
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/scraper"
+)
+
+func pageOf(n int, asins ...string) *scraper.PageResult {
+	result := &scraper.PageResult{Page: n}
+	for _, asin := range asins {
+		result.Products = append(result.Products, &scraper.Product{ASIN: asin})
+	}
+	return result
+}
+
+func TestDeltaCrawlStops(t *testing.T) {
+	delta := &deltaCrawl{threshold: 0.8}
+
+	if delta.page(pageOf(1, "B1", "B2", "B3", "B4", "B5"), 1) {
+		t.Fatal("stopped at a page of mostly new ASINs")
+	}
+	if !delta.page(pageOf(2, "B6", "B7", "B8", "B9", "B10"), 4) {
+		t.Fatal("did not stop at a page of 80% known ASINs")
+	}
+	if delta.pages != 2 || delta.stopPage != 2 || delta.share != 0.8 {
+		t.Errorf("pages = %d, stop page = %d, share = %v", delta.pages, delta.stopPage, delta.share)
+	}
+	if len(delta.listed) != 10 || delta.listed[0] != "B1" {
+		t.Errorf("listed = %v", delta.listed)
+	}
+
+	empty := &deltaCrawl{threshold: 0.8}
+	if !empty.page(pageOf(1), 0) || empty.stopPage != 0 {
+		t.Errorf("empty page: stop page = %d, want a stop without stop page", empty.stopPage)
+	}
+}
+
+func TestMergeFrontier(t *testing.T) {
+	got := mergeFrontier([]string{"B3", "B1", "B3"}, []string{"B1", "B2", "B0"}, 3)
+	if want := []string{"B3", "B1", "B2"}; !slices.Equal(got, want) {
+		t.Errorf("mergeFrontier() = %v, want %v", got, want)
+	}
+}
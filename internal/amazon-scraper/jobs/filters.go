@@ -0,0 +1,90 @@
+package jobs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/scraper"
+)
+
+// Reasons a product was skipped by a ProductFilter
+const (
+	FilterReasonBrand   = "brand"
+	FilterReasonPrice   = "price"
+	FilterReasonKeyword = "keyword"
+)
+
+// ProductFilter skips irrelevant search results before the expensive product extraction
+type ProductFilter struct {
+	BrandAllow      []string `json:"brand_allow,omitempty"`      // Only keep these brands
+	BrandDeny       []string `json:"brand_deny,omitempty"`       // Skip these brands
+	PriceMin        *float64 `json:"price_min,omitempty"`        // Results without a price are kept
+	PriceMax        *float64 `json:"price_max,omitempty"`        // Results without a price are kept
+	ExcludeKeywords []string `json:"exclude_keywords,omitempty"` // Skip titles containing any of these, e.g. "Bundle"
+}
+
+// Validate checks that the price range is consistent
+func (f *ProductFilter) Validate() error {
+	if f == nil {
+		return nil
+	}
+	if f.PriceMin != nil && *f.PriceMin < 0 || f.PriceMax != nil && *f.PriceMax < 0 {
+		return fmt.Errorf("price limits must not be negative")
+	}
+	if f.PriceMin != nil && f.PriceMax != nil && *f.PriceMin > *f.PriceMax {
+		return fmt.Errorf("price_min must not exceed price_max")
+	}
+	return nil
+}
+
+// Match reports whether the product passes the filter, returning the reason if it does not
+func (f *ProductFilter) Match(p *scraper.Product) (bool, string) {
+	if f == nil {
+		return true, ""
+	}
+
+	if len(f.BrandAllow) > 0 && !matchesBrand(p, f.BrandAllow) {
+		return false, FilterReasonBrand
+	}
+	if len(f.BrandDeny) > 0 && matchesBrand(p, f.BrandDeny) {
+		return false, FilterReasonBrand
+	}
+
+	if p.Price > 0 {
+		if f.PriceMin != nil && p.Price < *f.PriceMin {
+			return false, FilterReasonPrice
+		}
+		if f.PriceMax != nil && p.Price > *f.PriceMax {
+			return false, FilterReasonPrice
+		}
+	}
+
+	title := strings.ToLower(p.Title)
+	for _, keyword := range f.ExcludeKeywords {
+		if keyword = strings.ToLower(strings.TrimSpace(keyword)); keyword != "" && strings.Contains(title, keyword) {
+			return false, FilterReasonKeyword
+		}
+	}
+
+	return true, ""
+}
+
+// matchesBrand compares the brand case-insensitively, falling back to the title prefix when search results omit the brand
+func matchesBrand(p *scraper.Product, brands []string) bool {
+	brand := strings.ToLower(strings.TrimSpace(p.Brand))
+	title := strings.ToLower(strings.TrimSpace(p.Title))
+
+	for _, b := range brands {
+		b = strings.ToLower(strings.TrimSpace(b))
+		if b == "" {
+			continue
+		}
+		if brand != "" && brand == b {
+			return true
+		}
+		if brand == "" && strings.HasPrefix(title, b) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package jobs
+
+import (
+	"testing"
+
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/scraper"
+)
+
+func TestProductFilterMatch(t *testing.T) {
+	minPrice, maxPrice := 15.0, 40.0
+	filter := &ProductFilter{
+		BrandDeny:       []string{"NoName"},
+		PriceMin:        &minPrice,
+		PriceMax:        &maxPrice,
+		ExcludeKeywords: []string{"Bundle", "3er Pack"},
+	}
+
+	tests := []struct {
+		name       string
+		product    scraper.Product
+		wantOK     bool
+		wantReason string
+	}{
+		{"passes", scraper.Product{Title: "Tall T-Shirt", Brand: "TallFit", Price: 24.99}, true, ""},
+		{"denied brand", scraper.Product{Title: "Shirt", Brand: "noname", Price: 20}, false, FilterReasonBrand},
+		{"too cheap", scraper.Product{Title: "Shirt", Brand: "TallFit", Price: 9.99}, false, FilterReasonPrice},
+		{"too expensive", scraper.Product{Title: "Shirt", Brand: "TallFit", Price: 49}, false, FilterReasonPrice},
+		{"unknown price kept", scraper.Product{Title: "Shirt", Brand: "TallFit"}, true, ""},
+		{"excluded keyword", scraper.Product{Title: "T-Shirt BUNDLE Herren", Brand: "TallFit", Price: 20}, false, FilterReasonKeyword},
+		{"excluded phrase", scraper.Product{Title: "T-Shirt 3er Pack", Brand: "TallFit", Price: 20}, false, FilterReasonKeyword},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, reason := filter.Match(&tt.product)
+			if ok != tt.wantOK || reason != tt.wantReason {
+				t.Errorf("Match() = %v, %q; want %v, %q", ok, reason, tt.wantOK, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestProductFilterBrandAllow(t *testing.T) {
+	filter := &ProductFilter{BrandAllow: []string{"TallFit", "LongLine"}}
+
+	if ok, _ := filter.Match(&scraper.Product{Brand: "tallfit"}); !ok {
+		t.Error("allowed brand filtered")
+	}
+	if ok, reason := filter.Match(&scraper.Product{Brand: "Basics"}); ok || reason != FilterReasonBrand {
+		t.Errorf("other brand = %v, %q; want filtered by brand", ok, reason)
+	}
+	// Search results without brand fall back to the title prefix
+	if ok, _ := filter.Match(&scraper.Product{Title: "LongLine Herren Shirt"}); !ok {
+		t.Error("brand in title prefix filtered")
+	}
+}
+
+func TestProductFilterNilAndValidate(t *testing.T) {
+	var filter *ProductFilter
+	if ok, _ := filter.Match(&scraper.Product{}); !ok {
+		t.Error("nil filter should keep every product")
+	}
+	if err := filter.Validate(); err != nil {
+		t.Errorf("nil filter Validate() = %v", err)
+	}
+
+	lo, hi := 50.0, 10.0
+	if err := (&ProductFilter{PriceMin: &lo, PriceMax: &hi}).Validate(); err == nil {
+		t.Error("expected error for inverted price range")
+	}
+	neg := -1.0
+	if err := (&ProductFilter{PriceMin: &neg}).Validate(); err == nil {
+		t.Error("expected error for negative price")
+	}
+}
@@ -0,0 +1,64 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/events"
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+)
+
+// CategoryImport marks jobs created for an imported ASIN list
+const CategoryImport = "import"
+
+// ImportProducts creates pending products for asins. With createJob the ASINs are linked to a new
+// import job whose products_new and products_updated track how many are still waiting to be scraped.
+// The job is never picked up by the worker, it completes as soon as the list is stored.
+func (m *Manager) ImportProducts(ctx context.Context, source, marketplace string, asins []string, createJob bool) (*Job, *database.ImportResult, error) {
+	if marketplace == "" {
+		marketplace = DefaultMarketplace
+	}
+	if !createJob {
+		result, err := m.db.ImportProducts(ctx, asins, marketplace, "")
+		return nil, result, err
+	}
+
+	job, err := m.createJob(ctx, &Job{
+		SearchQuery: "import:" + source,
+		Category:    CategoryImport,
+		Marketplace: marketplace,
+		Status:      "running",
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	m.publishJobEvent(ctx, events.EventTypeJobStarted, job.ID, nil)
+
+	result, err := m.db.ImportProducts(ctx, asins, marketplace, job.ID)
+	if err != nil {
+		if statusErr := m.updateJobStatus(ctx, job.ID, "failed", err); statusErr != nil {
+			m.logger.ErrorContext(ctx, "failed to update job status", "id", job.ID, "error", statusErr)
+		}
+		m.publishJobEvent(ctx, events.EventTypeJobFailed, job.ID, err)
+		return nil, nil, err
+	}
+
+	if err := m.updateJobProgress(ctx, job.ID, 0, len(asins), 0); err != nil {
+		return nil, nil, fmt.Errorf("failed to update job progress: %w", err)
+	}
+	if err := m.updateJobStatus(ctx, job.ID, "completed", nil); err != nil {
+		return nil, nil, fmt.Errorf("failed to complete import job: %w", err)
+	}
+	m.publishJobEvent(ctx, events.EventTypeJobCompleted, job.ID, nil)
+
+	m.logger.InfoContext(ctx, "products imported", "job_id", job.ID, "source", source,
+		"created", result.Created, "existing", result.Existing)
+
+	now := time.Now()
+	job.Status = "completed"
+	job.ProductsFound = len(asins)
+	job.StartedAt = &job.CreatedAt
+	job.CompletedAt = &now
+	return job, result, nil
+}
@@ -0,0 +1,283 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/scraper"
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/maltedev/amazon-size-scraper/internal/labels"
+	"github.com/maltedev/amazon-size-scraper/internal/models"
+)
+
+// DefaultLegacyBatchSize is the number of legacy products converted per transaction
+const DefaultLegacyBatchSize = 100
+
+// LegacyMigrationOptions controls the conversion of legacy product rows to the lifecycle schema
+type LegacyMigrationOptions struct {
+	BatchSize int           // 0 uses DefaultLegacyBatchSize
+	Throttle  time.Duration // Pause between batches so the database and event consumers keep up
+	Limit     int           // Stop after this many products, 0 is unlimited
+	Events    bool          // Emit NEW_PRODUCT_DETECTED for each converted product
+	DryRun    bool          // Convert and count without writing
+	Restart   bool          // Forget the watermark of earlier runs and start from the first legacy row
+
+	Labels    *labels.Dictionary           // Maps measurement keys of legacy size tables, nil uses German
+	Validator *database.SizeTableValidator // Validates converted size tables, nil uses the default rules
+}
+
+// LegacyMigrationResult counts the outcome of a legacy migration run
+type LegacyMigrationResult struct {
+	ResumedAfter string `json:"resumed_after,omitempty"` // Watermark of an earlier run this one continued from
+	Scanned      int    `json:"scanned"`
+	Migrated     int    `json:"migrated"`
+	Skipped      int    `json:"skipped"` // Stored data could not be decoded
+	Events       int    `json:"events"`
+	LastASIN     string `json:"last_asin"` // Watermark after this run
+	Completed    bool   `json:"completed"` // No legacy rows are left after the watermark
+}
+
+// MigrateLegacyProducts converts product rows of the deprecated layout, a bare size table and material
+// columns, to the lifecycle schema in batches ordered by ASIN. Each batch is written in one transaction
+// together with its events and the watermark, so an interrupted run continues after the last committed
+// ASIN. Converted rows get a content hash and are not converted again, re-running is safe.
+func (m *Manager) MigrateLegacyProducts(ctx context.Context, opts LegacyMigrationOptions) (*LegacyMigrationResult, error) {
+	if opts.Events && m.publisher == nil && !opts.DryRun {
+		return nil, fmt.Errorf("emitting events requires an event publisher")
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultLegacyBatchSize
+	}
+	if opts.Labels == nil {
+		opts.Labels = labels.New(labels.LocaleDE)
+	}
+	if opts.Validator == nil {
+		opts.Validator = database.DefaultSizeTableValidator()
+	}
+
+	result := &LegacyMigrationResult{}
+	if opts.Restart && !opts.DryRun {
+		if err := m.db.ResetMigrationState(ctx, database.LegacyProductsMigration); err != nil {
+			return nil, err
+		}
+	}
+	if !opts.Restart {
+		state, err := m.db.GetMigrationState(ctx, database.LegacyProductsMigration)
+		if err != nil {
+			return nil, err
+		}
+		if state != nil {
+			result.ResumedAfter = state.Watermark
+			result.LastASIN = state.Watermark
+		}
+	}
+
+	for {
+		size := opts.BatchSize
+		if opts.Limit > 0 {
+			size = min(size, opts.Limit-result.Scanned)
+			if size <= 0 {
+				break
+			}
+		}
+
+		products, err := m.db.ListLegacyProducts(ctx, result.LastASIN, size)
+		if err != nil {
+			return result, err
+		}
+		if len(products) == 0 {
+			result.Completed = true
+			break
+		}
+
+		conversions := make([]*database.LegacyConversion, 0, len(products))
+		skipped := 0
+		for _, p := range products {
+			c, err := convertLegacyProduct(p, opts.Labels, opts.Validator)
+			if err == nil && opts.Events && !opts.DryRun {
+				c.Events, err = m.legacyProductEvents(ctx, p, c)
+			}
+			if err != nil {
+				m.logger.WarnContext(ctx, "skipping legacy product with undecodable data", "asin", p.ASIN, "error", err)
+				skipped++
+				continue
+			}
+			conversions = append(conversions, c)
+		}
+		watermark := products[len(products)-1].ASIN
+
+		migrated, published := len(conversions), 0
+		if !opts.DryRun {
+			// Normalized measurements are replaced as a whole, writing them again on a re-run is harmless
+			for _, c := range conversions {
+				if c.SizeTable == nil {
+					continue
+				}
+				if err := m.db.SaveSizeMeasurements(ctx, c.ASIN, c.SizeTable); err != nil {
+					m.logger.WarnContext(ctx, "failed to save size measurements", "asin", c.ASIN, "error", err)
+				}
+			}
+			migrated, published, err = m.db.SaveLegacyConversions(ctx, database.LegacyProductsMigration, watermark, conversions, skipped)
+			if err != nil {
+				return result, err
+			}
+		}
+
+		result.Scanned += len(products)
+		result.Migrated += migrated
+		result.Skipped += skipped
+		result.Events += published
+		result.LastASIN = watermark
+
+		m.logger.InfoContext(ctx, "legacy migration batch done",
+			"scanned", result.Scanned,
+			"migrated", result.Migrated,
+			"skipped", result.Skipped,
+			"events", result.Events,
+			"last_asin", result.LastASIN,
+			"dry_run", opts.DryRun)
+
+		if len(products) < size {
+			result.Completed = true
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		case <-time.After(opts.Throttle):
+		}
+	}
+
+	if result.Completed && !opts.DryRun {
+		if err := m.db.CompleteMigration(ctx, database.LegacyProductsMigration); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// convertLegacyProduct brings a legacy row into lifecycle form: measurement keys of the size table are
+// mapped to canonical keys, the table is validated and hashed, and the material becomes an attribute
+func convertLegacyProduct(p *database.LegacyProduct, dict *labels.Dictionary, validator *database.SizeTableValidator) (*database.LegacyConversion, error) {
+	c := &database.LegacyConversion{ASIN: p.ASIN}
+
+	st, err := convertLegacySizeTable(p.SizeTable, dict)
+	if err != nil {
+		return nil, err
+	}
+	if st != nil {
+		c.SizeTable = st
+		c.Report = validator.Validate(st)
+	}
+
+	material, err := legacyMaterial(p.MaterialComposition, p.MaterialFullText)
+	if err != nil {
+		return nil, err
+	}
+	if material != "" {
+		c.Attributes = map[string]string{"material": material}
+	}
+
+	c.ContentHash = (&scraper.CompleteProduct{Title: p.Title, SizeTable: st}).ContentHash()
+	return c, nil
+}
+
+// convertLegacySizeTable decodes a legacy size table, nil if it has no sizes. Tables of the deprecated
+// size scraper carry the localized header as measurement key, e.g. "Länge (cm)", and no source.
+func convertLegacySizeTable(raw json.RawMessage, dict *labels.Dictionary) (*database.SizeTable, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var st database.SizeTable
+	if err := json.Unmarshal(raw, &st); err != nil {
+		return nil, fmt.Errorf("invalid size table: %w", err)
+	}
+	if len(st.Sizes) == 0 {
+		return nil, nil
+	}
+
+	for size, measurements := range st.Measurements {
+		canonical := make(map[string]float64, len(measurements))
+		for key, value := range measurements {
+			key = dict.Normalize(key)
+			if _, dup := canonical[key]; !dup {
+				canonical[key] = value
+			}
+		}
+		st.Measurements[size] = canonical
+	}
+	if st.Unit == "" {
+		st.Unit = "cm"
+	}
+	if st.Source == "" {
+		st.Source = database.SizeTableSourceHTML
+	}
+	if st.Confidence == 0 {
+		st.Confidence = 1
+	}
+	return &st, nil
+}
+
+// legacyMaterial returns the material attribute of the legacy material columns, e.g. "80% Baumwolle,
+// 20% Polyester", falling back to the full text
+func legacyMaterial(raw json.RawMessage, fullText string) (string, error) {
+	if len(raw) > 0 && string(raw) != "null" {
+		var composition models.MaterialComposition
+		if err := json.Unmarshal(raw, &composition); err != nil {
+			return "", fmt.Errorf("invalid material composition: %w", err)
+		}
+		parts := make([]string, 0, len(composition.Materials))
+		for _, m := range composition.Materials {
+			if m.Name == "" {
+				continue
+			}
+			if m.Percent > 0 {
+				parts = append(parts, strconv.FormatFloat(m.Percent, 'f', -1, 64)+"% "+m.Name)
+			} else {
+				parts = append(parts, m.Name)
+			}
+		}
+		if len(parts) > 0 {
+			return strings.Join(parts, ", "), nil
+		}
+	}
+	return strings.Join(strings.Fields(fullText), " "), nil
+}
+
+// legacyProductEvents builds the NEW_PRODUCT_DETECTED events of a converted legacy product
+func (m *Manager) legacyProductEvents(ctx context.Context, p *database.LegacyProduct, c *database.LegacyConversion) ([]*database.OutboxEvent, error) {
+	payload, err := backfillPayload(&database.ProductLifecycle{
+		ASIN:          p.ASIN,
+		Title:         p.Title,
+		Brand:         p.Brand,
+		DetailPageURL: p.URL,
+		Category:      p.Category,
+		Rating:        p.Rating,
+		ReviewCount:   p.ReviewCount,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if c.SizeTable != nil {
+		payload.SizeTable = c.SizeTable
+		payload.AvailableSizes = c.SizeTable.Sizes
+	}
+	if len(p.Attributes) > 0 && string(p.Attributes) != "null" {
+		if err := json.Unmarshal(p.Attributes, &payload.Attributes); err != nil {
+			return nil, fmt.Errorf("invalid attributes: %w", err)
+		}
+	}
+	for key, value := range c.Attributes {
+		if payload.Attributes == nil {
+			payload.Attributes = make(map[string]string)
+		}
+		if _, ok := payload.Attributes[key]; !ok {
+			payload.Attributes[key] = value
+		}
+	}
+	return m.publisher.NewProductDetectedEvents(ctx, payload)
+}
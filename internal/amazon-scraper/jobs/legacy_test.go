@@ -0,0 +1,72 @@
+package jobs
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/maltedev/amazon-size-scraper/internal/labels"
+)
+
+func TestConvertLegacyProduct(t *testing.T) {
+	p := &database.LegacyProduct{
+		ASIN:                "B08N5WRWNW",
+		Title:               "Tall T-Shirt",
+		SizeTable:           json.RawMessage(`{"sizes":["M","L"],"measurements":{"M":{"Länge (cm)":78,"Brustumfang":104},"L":{"Länge (cm)":80,"Brustumfang":110}}}`),
+		MaterialComposition: json.RawMessage(`{"materials":[{"name":"Baumwolle","percent":95},{"name":"Elasthan","percent":5}],"confidence":0.9,"source":"structured"}`),
+		MaterialFullText:    "95% Baumwolle, 5% Elasthan",
+	}
+
+	c, err := convertLegacyProduct(p, labels.New(labels.LocaleDE), database.DefaultSizeTableValidator())
+	if err != nil {
+		t.Fatalf("convertLegacyProduct() error = %v", err)
+	}
+
+	if got := c.SizeTable.Measurements["M"]; got[labels.Length] != 78 || got[labels.Chest] != 104 || len(got) != 2 {
+		t.Errorf("Expected canonical measurement keys, got %v", got)
+	}
+	if c.SizeTable.Unit != "cm" || c.SizeTable.Source != database.SizeTableSourceHTML || c.SizeTable.Confidence != 1 {
+		t.Errorf("Expected defaults of popover tables, got unit %q, source %q, confidence %v",
+			c.SizeTable.Unit, c.SizeTable.Source, c.SizeTable.Confidence)
+	}
+	if c.Report == nil {
+		t.Error("Expected a validation report")
+	}
+	if got := c.Attributes["material"]; got != "95% Baumwolle, 5% Elasthan" {
+		t.Errorf("material = %q", got)
+	}
+	if c.ContentHash == "" {
+		t.Error("Expected a content hash")
+	}
+
+	// Converting the same row again yields the same hash
+	again, _ := convertLegacyProduct(p, labels.New(labels.LocaleDE), database.DefaultSizeTableValidator())
+	if again.ContentHash != c.ContentHash {
+		t.Error("Expected a stable content hash")
+	}
+}
+
+func TestConvertLegacyProductMaterialOnly(t *testing.T) {
+	c, err := convertLegacyProduct(&database.LegacyProduct{ASIN: "B000000001", Title: "Shirt", MaterialFullText: " 100%  Leinen "},
+		labels.New(labels.LocaleDE), database.DefaultSizeTableValidator())
+	if err != nil {
+		t.Fatalf("convertLegacyProduct() error = %v", err)
+	}
+	if c.SizeTable != nil || c.Report != nil {
+		t.Errorf("Expected no size table, got %+v", c.SizeTable)
+	}
+	if got := c.Attributes["material"]; got != "100% Leinen" {
+		t.Errorf("material = %q, want the full text", got)
+	}
+}
+
+func TestConvertLegacyProductRejectsInvalidData(t *testing.T) {
+	for _, p := range []*database.LegacyProduct{
+		{ASIN: "B000000001", SizeTable: json.RawMessage(`"broken`)},
+		{ASIN: "B000000002", MaterialComposition: json.RawMessage(`[1, 2]`)},
+	} {
+		if _, err := convertLegacyProduct(p, labels.New(labels.LocaleDE), database.DefaultSizeTableValidator()); err == nil {
+			t.Errorf("%s: expected an error for undecodable data", p.ASIN)
+		}
+	}
+}
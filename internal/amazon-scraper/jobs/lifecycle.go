@@ -0,0 +1,62 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/events"
+)
+
+// publishJobEvent announces a job state change with the job's current summary, so downstream steps
+// can start once a crawl finished instead of polling its status. cause is set for JOB_FAILED. The event
+// carries the metadata of the job.
+func (m *Manager) publishJobEvent(ctx context.Context, eventType events.EventType, jobID string, cause error) {
+	if m.publisher == nil {
+		return
+	}
+
+	job, err := m.GetJob(ctx, jobID)
+	if err != nil {
+		m.logger.WarnContext(ctx, "failed to load job for event", "job_id", jobID, "type", eventType, "error", err)
+		return
+	}
+
+	payload := jobPayload(job, time.Now())
+	if cause != nil {
+		payload.Error = cause.Error()
+	}
+	if err := m.publisher.PublishJobEvent(events.WithMetadata(ctx, job.Metadata), eventType, payload); err != nil {
+		m.logger.ErrorContext(ctx, "failed to publish job event", "job_id", jobID, "type", eventType, "error", err)
+	}
+}
+
+// jobPayload summarizes job, skipped products are the filtered ones and those with a skip reason. Its duration runs from the first start to completion or now
+func jobPayload(job *Job, now time.Time) *events.JobPayload {
+	payload := &events.JobPayload{
+		JobID:         job.ID,
+		SearchQuery:   job.SearchQuery,
+		Category:      job.Category,
+		Marketplace:   job.Marketplace,
+		PagesScraped:  job.PagesScraped,
+		ProductsFound: job.ProductsFound,
+		ProductsNew:   job.ProductsNew,
+		SkipReasons:   job.SkipReasons,
+		Error:         job.Error,
+
+		ProductsSkipped: job.ProductsFiltered, // Filtered products have no stored skip reason
+	}
+	if job.TemplateID != nil {
+		payload.TemplateID = *job.TemplateID
+	}
+	for _, n := range job.SkipReasons {
+		payload.ProductsSkipped += n
+	}
+	if job.StartedAt != nil {
+		end := now
+		if job.CompletedAt != nil {
+			end = *job.CompletedAt
+		}
+		payload.DurationMs = end.Sub(*job.StartedAt).Milliseconds()
+	}
+	return payload
+}
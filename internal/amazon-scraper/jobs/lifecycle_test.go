@@ -0,0 +1,42 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJobPayload(t *testing.T) {
+	started := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	completed := started.Add(90 * time.Second)
+	template := "tpl-1"
+	job := &Job{
+		ID:               "job-1",
+		TemplateID:       &template,
+		SearchQuery:      "t-shirt",
+		Marketplace:      "amazon.de",
+		PagesScraped:     3,
+		ProductsFound:    40,
+		ProductsNew:      12,
+		ProductsFiltered: 4,
+		SkipReasons:      map[string]int{"duplicate": 5, "timeout": 3},
+		StartedAt:        &started,
+		CompletedAt:      &completed,
+	}
+
+	payload := jobPayload(job, completed.Add(time.Hour))
+	if payload.JobID != "job-1" || payload.TemplateID != "tpl-1" || payload.ProductsFound != 40 || payload.ProductsNew != 12 {
+		t.Errorf("jobPayload() = %+v", payload)
+	}
+	if payload.ProductsSkipped != 12 {
+		t.Errorf("ProductsSkipped = %d, want 12", payload.ProductsSkipped)
+	}
+	if payload.DurationMs != 90000 {
+		t.Errorf("DurationMs = %d, want 90000", payload.DurationMs)
+	}
+
+	// A running job counts until now
+	job.CompletedAt = nil
+	if payload := jobPayload(job, started.Add(time.Minute)); payload.DurationMs != 60000 {
+		t.Errorf("running DurationMs = %d, want 60000", payload.DurationMs)
+	}
+}
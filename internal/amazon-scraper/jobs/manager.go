@@ -5,39 +5,86 @@ import (
 	"database/sql"
 	"fmt"
 	"log/slog"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/events"
 	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/scraper"
+	"github.com/maltedev/amazon-size-scraper/internal/brand"
+	"github.com/maltedev/amazon-size-scraper/internal/currency"
 	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/maltedev/amazon-size-scraper/internal/quota"
+	"github.com/maltedev/amazon-size-scraper/internal/reviewsummary"
+	"github.com/maltedev/amazon-size-scraper/internal/schema"
+	"github.com/maltedev/amazon-size-scraper/internal/taxonomy"
 )
 
 type Manager struct {
-	db        *database.DB
-	scraper   *scraper.Service
-	logger    *slog.Logger
-	publisher *events.Publisher
+	db           *database.DB
+	scraper      *scraper.Service
+	logger       *slog.Logger
+	publisher    *events.Publisher
+	crawlWorkers int
+	fx           *currency.Converter
+	quotaAction  string
+	progress     *progressBroker
+	reportDir    string
+	taxonomy     *taxonomy.Mapper
+	brands       *brand.Registry
+	summarizer   *reviewsummary.Summarizer
+	dedupWindow  time.Duration
+
+	runningMu sync.Mutex
+	running   map[string]context.CancelCauseFunc // Jobs processed by this instance, cancelled by CancelJob
 }
 
 func NewManager(db *database.DB, scraper *scraper.Service, publisher *events.Publisher, logger *slog.Logger) *Manager {
 	return &Manager{
-		db:        db,
-		scraper:   scraper,
-		logger:    logger.With("component", "job_manager"),
-		publisher: publisher,
+		db:          db,
+		scraper:     scraper,
+		logger:      logger.With("component", "job_manager"),
+		publisher:   publisher,
+		quotaAction: quota.ActionQueue,
+		progress:    newProgressBroker(),
+		taxonomy:    taxonomy.NewMapper(nil),
+		brands:      brand.NewRegistry(nil),
+		dedupWindow: DefaultDedupWindow,
+		running:     make(map[string]context.CancelCauseFunc),
 	}
 }
 
+// SetCurrencyConverter enables reporting prices in a single currency, nil disables conversion
+func (m *Manager) SetCurrencyConverter(c *currency.Converter) {
+	m.fx = c
+}
+
+// SetQuotaAction sets what happens to a job that exceeds its fetch budget, quota.ActionQueue or quota.ActionReject
+func (m *Manager) SetQuotaAction(action string) {
+	m.quotaAction = action
+}
+
+// SetCrawlWorkers sets how many search result pages a job fetches in parallel, 0 keeps the crawler default
+func (m *Manager) SetCrawlWorkers(n int) {
+	m.crawlWorkers = n
+}
+
 // Job represents a scraping job
 type Job struct {
 	ID               string    `json:"id"`
+	TemplateID       *string   `json:"template_id,omitempty"`
 	SearchQuery      string    `json:"search_query"`
 	Category         string    `json:"category"`
+	Marketplace      string    `json:"marketplace"`
 	MaxPages         int       `json:"max_pages"`
+	Filters          map[string]string `json:"filters,omitempty"`
+	ProductFilter    *ProductFilter `json:"product_filter,omitempty"`
+	Priority         int       `json:"priority"`
 	Status           string    `json:"status"`
 	PagesScraped     int       `json:"pages_scraped"`
 	ProductsFound    int       `json:"products_found"`
+	ProductsFiltered int       `json:"products_filtered"`
 	ProductsComplete int       `json:"products_complete"`
 	ProductsNew      int       `json:"products_new"`
 	ProductsUpdated  int       `json:"products_updated"`
@@ -45,6 +92,14 @@ type Job struct {
 	StartedAt        *time.Time `json:"started_at,omitempty"`
 	CompletedAt      *time.Time `json:"completed_at,omitempty"`
 	Error            string    `json:"error,omitempty"`
+	SkipReasons      map[string]int `json:"skip_reasons,omitempty"` // Products not stored, by reason
+	Deduplicated     bool      `json:"deduplicated,omitempty"` // An identical job existed and was returned instead of creating one
+	Delta            bool      `json:"delta,omitempty"`           // Stops at the first result page of mostly known ASINs, see Template
+	DeltaThreshold   float64   `json:"delta_threshold,omitempty"`
+	Metadata         map[string]string `json:"metadata,omitempty"` // Caller metadata, stored with its products and carried in its events
+
+	dedupKey string                                      // Set for search jobs, empty jobs are never deduplicated
+	inTx     func(ctx context.Context, tx pgx.Tx) error // Stores rows belonging to the job in its insert transaction
 }
 
 // JobProduct represents a product found by a job
@@ -54,6 +109,9 @@ type JobProduct struct {
 	PageNumber int    `json:"page_number"`
 	Title      string `json:"title"`
 	HasSizes   bool   `json:"has_sizes"`
+	SkipReason string `json:"skip_reason,omitempty"`
+	Error      string `json:"error,omitempty"`
+	Screenshot string `json:"error_screenshot,omitempty"`
 }
 
 // Stats represents scraper statistics
@@ -66,50 +124,125 @@ type Stats struct {
 	TotalProducts     int     `json:"total_products"`
 	ProductsWithSizes int     `json:"products_with_sizes"`
 	SuccessRate       float64 `json:"success_rate"`
+
+	SkipReasons map[string]int `json:"skip_reasons,omitempty"` // Products not stored across all jobs, by reason
+
+	Quota []quota.Usage `json:"quota,omitempty"` // Today's page fetches per API key and job
 }
 
-// CreateJob creates a new scraping job
-func (m *Manager) CreateJob(ctx context.Context, searchQuery, category string, maxPages int) (*Job, error) {
+// CreateJob creates a new scraping job, filter may be nil to deep-scrape every result. While an
+// identical search job is within the dedup window that job is returned with Deduplicated set. Jobs
+// with metadata are never deduplicated, their events must carry their own metadata.
+func (m *Manager) CreateJob(ctx context.Context, searchQuery, category string, maxPages int, filter *ProductFilter, metadata map[string]string) (*Job, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	if err := schema.ValidateMetadata(metadata); err != nil {
+		return nil, err
+	}
+
 	job := &Job{
-		ID:          uuid.New().String(),
-		SearchQuery: searchQuery,
-		Category:    category,
-		MaxPages:    maxPages,
-		Status:      "pending",
-		CreatedAt:   time.Now(),
+		SearchQuery:   searchQuery,
+		Category:      category,
+		Marketplace:   DefaultMarketplace,
+		MaxPages:      maxPages,
+		ProductFilter: filter,
+		Metadata:      metadata,
+	}
+	if len(metadata) == 0 {
+		job.dedupKey = dedupKey(DefaultMarketplace, searchQuery, category)
+	}
+	return m.createJob(ctx, job)
+}
+
+// createJob inserts a job with the given settings, pending unless job.Status is set. A job with a
+// dedup key returns the identical job of the dedup window instead if there is one.
+func (m *Manager) createJob(ctx context.Context, job *Job) (*Job, error) {
+	job.ID = uuid.New().String()
+	if job.Status == "" {
+		job.Status = "pending"
+	}
+	job.CreatedAt = time.Now()
+	if job.Filters == nil {
+		job.Filters = map[string]string{}
+	}
+	if job.DeltaThreshold == 0 {
+		job.DeltaThreshold = DefaultDeltaThreshold
+	}
+	metadata := job.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+
+	var dedup *string
+	if job.dedupKey != "" && m.dedupWindow > 0 {
+		dedup = &job.dedupKey
+	}
+	// Jobs run by the caller start with a heartbeat, RecoverJobs must not take them for orphaned
+	var heartbeat *time.Time
+	if job.Status == "running" {
+		heartbeat = &job.CreatedAt
 	}
 
 	query := `
 		INSERT INTO scraper_jobs 
-		(id, search_query, category, max_pages, status, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		(id, template_id, search_query, category, marketplace, max_pages, filters, product_filter, priority, status, created_at, dedup_key,
+		 delta, delta_threshold, metadata, heartbeat_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 	`
 
-	_, err := m.db.Exec(ctx, query, 
-		job.ID, job.SearchQuery, job.Category, job.MaxPages, job.Status, job.CreatedAt)
+	var duplicateID string
+	err := m.db.Transaction(ctx, func(tx pgx.Tx) error {
+		if dedup != nil {
+			id, err := findDuplicateJob(ctx, tx, *dedup, m.dedupWindow)
+			if err != nil || id != "" {
+				duplicateID = id
+				return err
+			}
+		}
+		_, err := tx.Exec(ctx, query,
+			job.ID, job.TemplateID, job.SearchQuery, job.Category, job.Marketplace, job.MaxPages,
+			job.Filters, job.ProductFilter, job.Priority, job.Status, job.CreatedAt, dedup,
+			job.Delta, job.DeltaThreshold, metadata, heartbeat)
+		if err != nil || job.inTx == nil {
+			return err
+		}
+		return job.inTx(ctx, tx)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create job: %w", err)
 	}
 
-	m.logger.Info("job created", "id", job.ID, "query", searchQuery)
+	if duplicateID != "" {
+		existing, err := m.GetJob(ctx, duplicateID)
+		if err != nil {
+			return nil, err
+		}
+		existing.Deduplicated = true
+		m.logger.InfoContext(ctx, "identical job exists, returning it", "id", existing.ID, "query", job.SearchQuery, "status", existing.Status)
+		return existing, nil
+	}
+
+	m.logger.InfoContext(ctx, "job created", "id", job.ID, "query", job.SearchQuery, "template_id", job.TemplateID)
 	return job, nil
 }
 
 // GetJob retrieves a job by ID
 func (m *Manager) GetJob(ctx context.Context, jobID string) (*Job, error) {
 	query := `
-		SELECT id, search_query, category, max_pages, status,
-		       pages_scraped, products_found, products_complete,
-		       created_at, started_at, completed_at, error
+		SELECT id, template_id, search_query, category, marketplace, max_pages, filters, product_filter, priority, status,
+		       pages_scraped, products_found, products_complete, products_filtered,
+		       created_at, started_at, completed_at, COALESCE(error, ''), delta, delta_threshold, metadata
 		FROM scraper_jobs
 		WHERE id = $1
 	`
 
 	job := &Job{}
 	err := m.db.QueryRow(ctx, query, jobID).Scan(
-		&job.ID, &job.SearchQuery, &job.Category, &job.MaxPages, &job.Status,
-		&job.PagesScraped, &job.ProductsFound, &job.ProductsComplete,
-		&job.CreatedAt, &job.StartedAt, &job.CompletedAt, &job.Error,
+		&job.ID, &job.TemplateID, &job.SearchQuery, &job.Category, &job.Marketplace,
+		&job.MaxPages, &job.Filters, &job.ProductFilter, &job.Priority, &job.Status,
+		&job.PagesScraped, &job.ProductsFound, &job.ProductsComplete, &job.ProductsFiltered,
+		&job.CreatedAt, &job.StartedAt, &job.CompletedAt, &job.Error, &job.Delta, &job.DeltaThreshold, &job.Metadata,
 	)
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("job not found")
@@ -126,28 +259,34 @@ func (m *Manager) GetJob(ctx context.Context, jobID string) (*Job, error) {
 			COUNT(DISTINCT CASE WHEN p.status != 'pending' THEN jp.asin END) as updated
 		FROM job_products jp
 		LEFT JOIN products p ON jp.asin = p.asin
-		WHERE jp.job_id = $1
+		WHERE jp.job_id = $1 AND jp.skip_reason IS NULL
 	`
 
 	m.db.QueryRow(ctx, countQuery, jobID).Scan(
 		&job.ProductsFound, &job.ProductsNew, &job.ProductsUpdated,
 	)
 
+	job.SkipReasons, err = m.skipReasons(ctx, jobID)
+	if err != nil {
+		m.logger.WarnContext(ctx, "failed to get skip reasons", "job_id", jobID, "error", err)
+	}
+
 	return job, nil
 }
 
-// ListJobs lists all jobs
-func (m *Manager) ListJobs(ctx context.Context) ([]*Job, error) {
+// ListJobs lists all jobs, or only those created from templateID if it is set
+func (m *Manager) ListJobs(ctx context.Context, templateID string) ([]*Job, error) {
 	query := `
-		SELECT id, search_query, category, max_pages, status,
-		       pages_scraped, products_found, products_complete,
-		       created_at, started_at, completed_at
+		SELECT id, template_id, search_query, category, marketplace, max_pages, filters, product_filter, priority, status,
+		       pages_scraped, products_found, products_complete, products_filtered,
+		       created_at, started_at, completed_at, delta, delta_threshold, metadata
 		FROM scraper_jobs
+		WHERE $1 = '' OR template_id::text = $1
 		ORDER BY created_at DESC
 		LIMIT 100
 	`
 
-	rows, err := m.db.Query(ctx, query)
+	rows, err := m.db.Query(ctx, query, templateID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list jobs: %w", err)
 	}
@@ -157,9 +296,10 @@ func (m *Manager) ListJobs(ctx context.Context) ([]*Job, error) {
 	for rows.Next() {
 		job := &Job{}
 		err := rows.Scan(
-			&job.ID, &job.SearchQuery, &job.Category, &job.MaxPages, &job.Status,
-			&job.PagesScraped, &job.ProductsFound, &job.ProductsComplete,
-			&job.CreatedAt, &job.StartedAt, &job.CompletedAt,
+			&job.ID, &job.TemplateID, &job.SearchQuery, &job.Category, &job.Marketplace,
+			&job.MaxPages, &job.Filters, &job.ProductFilter, &job.Priority, &job.Status,
+			&job.PagesScraped, &job.ProductsFound, &job.ProductsComplete, &job.ProductsFiltered,
+			&job.CreatedAt, &job.StartedAt, &job.CompletedAt, &job.Delta, &job.DeltaThreshold, &job.Metadata,
 		)
 		if err != nil {
 			continue
@@ -173,10 +313,12 @@ func (m *Manager) ListJobs(ctx context.Context) ([]*Job, error) {
 // GetJobProducts retrieves products found by a job
 func (m *Manager) GetJobProducts(ctx context.Context, jobID string) ([]*JobProduct, error) {
 	query := `
-		SELECT jp.job_id, jp.asin, jp.page_number, p.title,
-		       CASE WHEN p.width_cm > 0 AND p.length_cm > 0 THEN true ELSE false END as has_sizes
+		SELECT jp.job_id, jp.asin, jp.page_number, COALESCE(p.title, ''),
+		       COALESCE(p.width_cm > 0 AND p.length_cm > 0, false) as has_sizes,
+		       COALESCE(jp.skip_reason, ''),
+		       COALESCE(p.error_message, ''), COALESCE(p.error_screenshot, '')
 		FROM job_products jp
-		JOIN products p ON jp.asin = p.asin
+		LEFT JOIN products p ON jp.asin = p.asin
 		WHERE jp.job_id = $1
 		ORDER BY jp.page_number, jp.asin
 	`
@@ -190,7 +332,7 @@ func (m *Manager) GetJobProducts(ctx context.Context, jobID string) ([]*JobProdu
 	var products []*JobProduct
 	for rows.Next() {
 		p := &JobProduct{}
-		err := rows.Scan(&p.JobID, &p.ASIN, &p.PageNumber, &p.Title, &p.HasSizes)
+		err := rows.Scan(&p.JobID, &p.ASIN, &p.PageNumber, &p.Title, &p.HasSizes, &p.SkipReason, &p.Error, &p.Screenshot)
 		if err != nil {
 			continue
 		}
@@ -237,9 +379,49 @@ func (m *Manager) GetStats(ctx context.Context) (*Stats, error) {
 
 	m.db.QueryRow(ctx, productQuery).Scan(&stats.TotalProducts, &stats.ProductsWithSizes)
 
+	stats.SkipReasons, err = m.skipReasons(ctx, "")
+	if err != nil {
+		m.logger.WarnContext(ctx, "failed to get skip reasons", "error", err)
+	}
+
+	if t := m.scraper.Quota(); t != nil {
+		usage, err := t.Usage(ctx)
+		if err != nil {
+			m.logger.WarnContext(ctx, "failed to get quota usage", "error", err)
+		}
+		stats.Quota = usage
+	}
+
 	return stats, nil
 }
 
+// skipReasons counts the skipped products of a job by reason, of all jobs if jobID is empty
+func (m *Manager) skipReasons(ctx context.Context, jobID string) (map[string]int, error) {
+	query := `
+		SELECT skip_reason, COUNT(*)
+		FROM job_products
+		WHERE skip_reason IS NOT NULL AND ($1 = '' OR job_id::text = $1)
+		GROUP BY skip_reason
+	`
+
+	rows, err := m.db.Query(ctx, query, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count skip reasons: %w", err)
+	}
+	defer rows.Close()
+
+	reasons := make(map[string]int)
+	for rows.Next() {
+		var reason string
+		var count int
+		if err := rows.Scan(&reason, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan skip reason: %w", err)
+		}
+		reasons[reason] = count
+	}
+	return reasons, rows.Err()
+}
+
 // updateJobStatus updates the status of a job
 func (m *Manager) updateJobStatus(ctx context.Context, jobID, status string, err error) error {
 	var query string
@@ -247,15 +429,16 @@ func (m *Manager) updateJobStatus(ctx context.Context, jobID, status string, err
 
 	if status == "running" {
 		now := time.Now()
-		query = `UPDATE scraper_jobs SET status = $1, started_at = $2 WHERE id = $3`
+		query = `UPDATE scraper_jobs SET status = $1, started_at = COALESCE(started_at, $2), heartbeat_at = NOW() WHERE id = $3`
 		args = []interface{}{status, now, jobID}
 	} else if status == "completed" {
+		// A job cancelled while it finished stays cancelled
 		now := time.Now()
-		query = `UPDATE scraper_jobs SET status = $1, completed_at = $2 WHERE id = $3`
+		query = `UPDATE scraper_jobs SET status = $1, completed_at = $2 WHERE id = $3 AND status <> 'cancelled'`
 		args = []interface{}{status, now, jobID}
 	} else if status == "failed" && err != nil {
 		now := time.Now()
-		query = `UPDATE scraper_jobs SET status = $1, completed_at = $2, error = $3 WHERE id = $4`
+		query = `UPDATE scraper_jobs SET status = $1, completed_at = $2, error = $3 WHERE id = $4 AND status <> 'cancelled'`
 		args = []interface{}{status, now, err.Error(), jobID}
 	} else {
 		query = `UPDATE scraper_jobs SET status = $1 WHERE id = $2`
@@ -266,13 +449,14 @@ func (m *Manager) updateJobStatus(ctx context.Context, jobID, status string, err
 	return execErr
 }
 
-// updateJobProgress updates job progress
-func (m *Manager) updateJobProgress(ctx context.Context, jobID string, pagesScraped, productsFound int) error {
+// updateJobProgress updates job progress and moves the checkpoint past the completed page
+func (m *Manager) updateJobProgress(ctx context.Context, jobID string, pagesScraped, productsFound, productsFiltered int) error {
 	query := `
 		UPDATE scraper_jobs 
-		SET pages_scraped = $1, products_found = $2 
-		WHERE id = $3
+		SET pages_scraped = $1, products_found = $2, products_filtered = $3,
+		    checkpoint_page = $1, checkpoint_asins = '{}', heartbeat_at = NOW()
+		WHERE id = $4
 	`
-	_, err := m.db.Exec(ctx, query, pagesScraped, productsFound, jobID)
+	_, err := m.db.Exec(ctx, query, pagesScraped, productsFound, productsFiltered, jobID)
 	return err
 }
\ No newline at end of file
@@ -0,0 +1,207 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// OverlapSide is one of the two compared jobs
+type OverlapSide struct {
+	JobID       string  `json:"job_id"`
+	TemplateID  *string `json:"template_id,omitempty"`
+	SearchQuery string  `json:"search_query"`
+	Category    string  `json:"category"`
+	Marketplace string  `json:"marketplace"`
+	Status      string  `json:"status"`
+	ASINs       int     `json:"asins"`  // ASINs the job found, stored or skipped
+	Unique      int     `json:"unique"` // ASINs the other job did not find
+}
+
+// SharedProduct is an ASIN both jobs found, ranked by the order they found them in
+type SharedProduct struct {
+	ASIN      string `json:"asin"`
+	LeftRank  int    `json:"left_rank"`
+	RightRank int    `json:"right_rank"`
+	RankDiff  int    `json:"rank_diff"` // RightRank - LeftRank, positive if the right search ranks it lower
+}
+
+// RankedProduct is an ASIN only one job found, with its rank in that job
+type RankedProduct struct {
+	ASIN string `json:"asin"`
+	Rank int    `json:"rank"`
+}
+
+// JobOverlap compares the ASIN sets of two jobs, e.g. to see how much two search queries cover the
+// same products. Ranks count from 1 in result page order.
+type JobOverlap struct {
+	Left            OverlapSide     `json:"left"`
+	Right           OverlapSide     `json:"right"`
+	Shared          int             `json:"shared"`
+	Jaccard         float64         `json:"jaccard"`            // Shared ASINs of all ASINs, between 0 and 1
+	MeanAbsRankDiff float64         `json:"mean_abs_rank_diff"` // Mean absolute rank difference of the shared ASINs
+	SharedProducts  []SharedProduct `json:"shared_products"`    // By left rank
+	OnlyLeft        []RankedProduct `json:"only_left"`
+	OnlyRight       []RankedProduct `json:"only_right"`
+	ComputedAt      time.Time       `json:"computed_at"`
+	Cached          bool            `json:"cached"`
+}
+
+// overlapRow is an ASIN of either job with its rank in each, nil where the job did not find it
+type overlapRow struct {
+	asin        string
+	left, right *int
+}
+
+// finishedJob reports whether a job's products no longer change
+func finishedJob(status string) bool {
+	return status == "completed" || status == "failed" || status == "cancelled"
+}
+
+// CompareJobs computes the shared and unique ASINs of two jobs and the rank differences of the shared
+// ones. The overlap of two finished jobs is computed once and then served from job_overlaps.
+func (m *Manager) CompareJobs(ctx context.Context, leftID, rightID string) (*JobOverlap, error) {
+	left, err := m.overlapSide(ctx, leftID)
+	if err != nil {
+		return nil, err
+	}
+	right, err := m.overlapSide(ctx, rightID)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheable := finishedJob(left.Status) && finishedJob(right.Status)
+	if cacheable {
+		overlap := &JobOverlap{}
+		err := m.db.QueryRow(ctx, `SELECT result FROM job_overlaps WHERE left_job_id = $1 AND right_job_id = $2`,
+			leftID, rightID).Scan(overlap)
+		if err == nil {
+			overlap.Cached = true
+			return overlap, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			m.logger.WarnContext(ctx, "failed to read cached job overlap", "left", leftID, "right", rightID, "error", err)
+		}
+	}
+
+	// Ranks follow the result pages and, within a page, the order the worker linked the products
+	query := `
+		WITH l AS (
+			SELECT asin, ROW_NUMBER() OVER (ORDER BY page_number, created_at, asin)::int AS rank
+			FROM job_products WHERE job_id = $1
+		), r AS (
+			SELECT asin, ROW_NUMBER() OVER (ORDER BY page_number, created_at, asin)::int AS rank
+			FROM job_products WHERE job_id = $2
+		)
+		SELECT COALESCE(l.asin, r.asin), l.rank, r.rank
+		FROM l FULL OUTER JOIN r ON l.asin = r.asin
+		ORDER BY l.rank NULLS LAST, r.rank`
+
+	rows, err := m.db.Query(ctx, query, leftID, rightID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare job products: %w", err)
+	}
+	var overlapRows []overlapRow
+	for rows.Next() {
+		var row overlapRow
+		if err := rows.Scan(&row.asin, &row.left, &row.right); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan job product: %w", err)
+		}
+		overlapRows = append(overlapRows, row)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to compare job products: %w", err)
+	}
+
+	overlap := buildOverlap(overlapRows)
+	overlap.Left, overlap.Right = *left, *right
+	overlap.Left.ASINs = overlap.Shared + len(overlap.OnlyLeft)
+	overlap.Left.Unique = len(overlap.OnlyLeft)
+	overlap.Right.ASINs = overlap.Shared + len(overlap.OnlyRight)
+	overlap.Right.Unique = len(overlap.OnlyRight)
+	overlap.ComputedAt = time.Now()
+
+	if cacheable {
+		_, err := m.db.Exec(ctx, `
+			INSERT INTO job_overlaps (left_job_id, right_job_id, result, computed_at)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (left_job_id, right_job_id) DO NOTHING`,
+			leftID, rightID, overlap, overlap.ComputedAt)
+		if err != nil {
+			m.logger.WarnContext(ctx, "failed to cache job overlap", "left", leftID, "right", rightID, "error", err)
+		}
+	}
+	return overlap, nil
+}
+
+// overlapSide returns the search of a compared job
+func (m *Manager) overlapSide(ctx context.Context, jobID string) (*OverlapSide, error) {
+	side := &OverlapSide{JobID: jobID}
+	err := m.db.QueryRow(ctx, `
+		SELECT template_id, search_query, category, marketplace, status
+		FROM scraper_jobs WHERE id = $1`, jobID,
+	).Scan(&side.TemplateID, &side.SearchQuery, &side.Category, &side.Marketplace, &side.Status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, jobID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	return side, nil
+}
+
+// LatestTemplateJob returns the ID of the most recently completed job of a template, to compare saved
+// searches by their latest results
+func (m *Manager) LatestTemplateJob(ctx context.Context, templateID string) (string, error) {
+	var jobID string
+	err := m.db.QueryRow(ctx, `
+		SELECT id FROM scraper_jobs
+		WHERE template_id = $1 AND status = 'completed'
+		ORDER BY completed_at DESC
+		LIMIT 1`, templateID,
+	).Scan(&jobID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", fmt.Errorf("%w: template %s has no completed job", ErrJobNotFound, templateID)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get latest template job: %w", err)
+	}
+	return jobID, nil
+}
+
+// buildOverlap splits the rows into shared and unique ASINs and computes the overlap measures
+func buildOverlap(rows []overlapRow) *JobOverlap {
+	overlap := &JobOverlap{
+		SharedProducts: []SharedProduct{},
+		OnlyLeft:       []RankedProduct{},
+		OnlyRight:      []RankedProduct{},
+	}
+	diffs := 0
+	for _, row := range rows {
+		switch {
+		case row.left != nil && row.right != nil:
+			shared := SharedProduct{ASIN: row.asin, LeftRank: *row.left, RightRank: *row.right, RankDiff: *row.right - *row.left}
+			overlap.SharedProducts = append(overlap.SharedProducts, shared)
+			diffs += max(shared.RankDiff, -shared.RankDiff)
+		case row.left != nil:
+			overlap.OnlyLeft = append(overlap.OnlyLeft, RankedProduct{ASIN: row.asin, Rank: *row.left})
+		case row.right != nil:
+			overlap.OnlyRight = append(overlap.OnlyRight, RankedProduct{ASIN: row.asin, Rank: *row.right})
+		}
+	}
+
+	overlap.Shared = len(overlap.SharedProducts)
+	if len(rows) > 0 {
+		overlap.Jaccard = math.Round(float64(overlap.Shared)/float64(len(rows))*1000) / 1000
+	}
+	if overlap.Shared > 0 {
+		overlap.MeanAbsRankDiff = math.Round(float64(diffs)/float64(overlap.Shared)*10) / 10
+	}
+	return overlap
+}
@@ -0,0 +1,40 @@
+package jobs
+
+import "testing"
+
+func TestBuildOverlap(t *testing.T) {
+	rank := func(n int) *int { return &n }
+	overlap := buildOverlap([]overlapRow{
+		{asin: "B000000001", left: rank(1), right: rank(3)},
+		{asin: "B000000002", left: rank(2)},
+		{asin: "B000000003", left: rank(3), right: rank(1)},
+		{asin: "B000000004", left: rank(4), right: rank(5)},
+		{asin: "B000000005", right: rank(2)},
+		{asin: "B000000006", right: rank(4)},
+	})
+
+	if overlap.Shared != 3 || len(overlap.OnlyLeft) != 1 || len(overlap.OnlyRight) != 2 {
+		t.Fatalf("overlap = %d shared, %d only left, %d only right, want 3, 1 and 2",
+			overlap.Shared, len(overlap.OnlyLeft), len(overlap.OnlyRight))
+	}
+	if got := overlap.SharedProducts[0]; got.ASIN != "B000000001" || got.RankDiff != 2 {
+		t.Errorf("first shared product = %+v, want B000000001 ranked 2 lower on the right", got)
+	}
+	if got := overlap.SharedProducts[1]; got.RankDiff != -2 {
+		t.Errorf("rank diff of B000000003 = %d, want -2", got.RankDiff)
+	}
+	if overlap.Jaccard != 0.5 {
+		t.Errorf("jaccard = %v, want 0.5", overlap.Jaccard)
+	}
+	// (2 + 2 + 1) / 3
+	if overlap.MeanAbsRankDiff != 1.7 {
+		t.Errorf("mean absolute rank diff = %v, want 1.7", overlap.MeanAbsRankDiff)
+	}
+	if got := overlap.OnlyRight[1]; got.ASIN != "B000000006" || got.Rank != 4 {
+		t.Errorf("last right-only product = %+v, want B000000006 at rank 4", got)
+	}
+
+	if empty := buildOverlap(nil); empty.Jaccard != 0 || empty.SharedProducts == nil {
+		t.Errorf("overlap of empty jobs = %+v, want zero measures and empty lists", empty)
+	}
+}
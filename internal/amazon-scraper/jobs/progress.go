@@ -0,0 +1,139 @@
+package jobs
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/scraper"
+	"github.com/maltedev/amazon-size-scraper/internal/browser"
+	"github.com/maltedev/amazon-size-scraper/internal/scrapeerr"
+)
+
+// Progress event types streamed by GET /jobs/{id}/events
+const (
+	EventSnapshot         = "snapshot"          // Current job state, sent when a client subscribes
+	EventPageCompleted    = "page_completed"    // A search result page was processed
+	EventProductSaved     = "product_saved"     // A product with size table was stored
+	EventProductUnchanged = "product_unchanged" // A re-scraped product matched its stored content hash
+	EventProductSkipped   = "product_skipped"   // A product was not stored, see Reason
+	EventJobRequeued      = "job_requeued"      // The job ran out of fetch budget and waits for the reset
+	EventJobFinished      = "job_finished"      // The job completed or failed, the stream ends
+)
+
+// Reasons of product_skipped events. All but filtered and save_failed are also stored in
+// job_products.skip_reason.
+const (
+	SkipFiltered      = "filtered"
+	SkipTimeout       = "timeout"
+	SkipNoSizeTable   = "no_size_table"
+	SkipMissingLength = "missing_length"
+	SkipCaptcha       = "captcha"
+	SkipCooldown      = "cooldown" // The marketplace circuit breaker opened during the extraction
+	SkipAgeGate       = "age_gate" // Amazon asked for age verification, see SCRAPER_AGE_GATE
+	SkipSignIn        = "sign_in_required"
+	SkipParseError    = "parse_error"
+	SkipSaveFailed    = "save_failed"
+)
+
+// SkipReason classifies why the extraction of a product failed
+func SkipReason(err error) string {
+	switch {
+	case errors.Is(err, scraper.ErrTaskTimeout):
+		return SkipTimeout
+	case errors.Is(err, browser.ErrCaptcha):
+		return SkipCaptcha
+	case errors.Is(err, browser.ErrMarketplaceCooldown):
+		return SkipCooldown
+	case errors.Is(err, browser.ErrAgeVerification):
+		return SkipAgeGate
+	case errors.Is(err, browser.ErrSignInRequired):
+		return SkipSignIn
+	case errors.Is(err, scraper.ErrMissingLength):
+		return SkipMissingLength
+	case errors.Is(err, scrapeerr.ErrNoSizeTable):
+		return SkipNoSizeTable
+	}
+	// Navigation and extraction errors as well as implausible values of a misread table
+	return SkipParseError
+}
+
+// ProgressEvent is a live progress update of a running job
+type ProgressEvent struct {
+	Type             string    `json:"type"`
+	JobID            string    `json:"job_id"`
+	Page             int       `json:"page,omitempty"`
+	ASIN             string    `json:"asin,omitempty"`
+	CanonicalASIN    string    `json:"canonical_asin,omitempty"` // product_saved of a duplicate
+	Reason           string    `json:"reason,omitempty"`
+	Detail           string    `json:"detail,omitempty"`
+	Status           string    `json:"status,omitempty"`
+	Error            string    `json:"error,omitempty"`
+	ProductsFound    int       `json:"products_found"`
+	ProductsFiltered int       `json:"products_filtered"`
+	Job              *Job      `json:"job,omitempty"` // Set on snapshot events
+	Time             time.Time `json:"time"`
+}
+
+// progressSubscriberBuffer is how many events a slow subscriber may lag behind before events are dropped
+const progressSubscriberBuffer = 64
+
+// progressBroker fans out progress events of the jobs run by this process to their subscribers
+type progressBroker struct {
+	mu   sync.Mutex
+	subs map[string]map[chan ProgressEvent]struct{}
+}
+
+func newProgressBroker() *progressBroker {
+	return &progressBroker{subs: make(map[string]map[chan ProgressEvent]struct{})}
+}
+
+func (b *progressBroker) subscribe(jobID string) (<-chan ProgressEvent, func()) {
+	ch := make(chan ProgressEvent, progressSubscriberBuffer)
+
+	b.mu.Lock()
+	if b.subs[jobID] == nil {
+		b.subs[jobID] = make(map[chan ProgressEvent]struct{})
+	}
+	b.subs[jobID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			delete(b.subs[jobID], ch)
+			if len(b.subs[jobID]) == 0 {
+				delete(b.subs, jobID)
+			}
+		})
+	}
+}
+
+// publish never blocks the worker, a subscriber whose buffer is full misses the event
+func (b *progressBroker) publish(ev ProgressEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs[ev.JobID] {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// SubscribeProgress returns the progress events of a job run by this process. The returned func
+// unsubscribes and must be called when the caller stops reading.
+func (m *Manager) SubscribeProgress(jobID string) (<-chan ProgressEvent, func()) {
+	return m.progress.subscribe(jobID)
+}
+
+// emit publishes a progress event of a job
+func (m *Manager) emit(ev ProgressEvent) {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	m.progress.publish(ev)
+}
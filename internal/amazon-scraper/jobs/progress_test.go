@@ -0,0 +1,76 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/scraper"
+	"github.com/maltedev/amazon-size-scraper/internal/browser"
+)
+
+func TestProgressBroker(t *testing.T) {
+	m := &Manager{progress: newProgressBroker()}
+
+	events, unsubscribe := m.SubscribeProgress("job-1")
+	other, unsubscribeOther := m.SubscribeProgress("job-2")
+	defer unsubscribeOther()
+
+	m.emit(ProgressEvent{Type: EventProductSaved, JobID: "job-1", ASIN: "B0TEST0001"})
+
+	select {
+	case ev := <-events:
+		if ev.Type != EventProductSaved || ev.ASIN != "B0TEST0001" || ev.Time.IsZero() {
+			t.Errorf("event = %+v", ev)
+		}
+	default:
+		t.Fatal("expected event for job-1")
+	}
+	if len(other) != 0 {
+		t.Error("job-2 subscriber received an event of job-1")
+	}
+
+	unsubscribe()
+	unsubscribe()
+	m.emit(ProgressEvent{Type: EventJobFinished, JobID: "job-1"})
+	if len(events) != 0 {
+		t.Error("received event after unsubscribe")
+	}
+}
+
+func TestProgressBroker_DropsForSlowSubscriber(t *testing.T) {
+	m := &Manager{progress: newProgressBroker()}
+	events, unsubscribe := m.SubscribeProgress("job-1")
+	defer unsubscribe()
+
+	// The worker must never block on a subscriber that stopped reading
+	for i := 0; i < progressSubscriberBuffer+10; i++ {
+		m.emit(ProgressEvent{Type: EventPageCompleted, JobID: "job-1", Page: i + 1})
+	}
+	if len(events) != progressSubscriberBuffer {
+		t.Errorf("buffered events = %d, want %d", len(events), progressSubscriberBuffer)
+	}
+}
+
+func TestSkipReason(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{fmt.Errorf("extract: %w", scraper.ErrTaskTimeout), SkipTimeout},
+		{fmt.Errorf("bot protection: %w", browser.ErrCaptcha), SkipCaptcha},
+		{fmt.Errorf("bot protection: %w", browser.ErrAgeVerification), SkipAgeGate},
+		{fmt.Errorf("bot protection: %w", browser.ErrSignInRequired), SkipSignIn},
+		{scraper.ErrMissingLength, SkipMissingLength},
+		{scraper.ErrNoSizeTable, SkipNoSizeTable},
+		{fmt.Errorf("wrap: %w", browser.ErrSizeChartNotFound), SkipNoSizeTable},
+		{fmt.Errorf("%w: chest out of range", scraper.ErrInvalidSizeTable), SkipParseError},
+		{errors.New("navigation failed"), SkipParseError},
+	}
+
+	for _, tt := range tests {
+		if got := SkipReason(tt.err); got != tt.want {
+			t.Errorf("SkipReason(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Report formats
+const (
+	ReportHTML = "html"
+	ReportPDF  = "pdf"
+)
+
+// ErrUnsupportedReportFormat is returned for report formats other than ReportHTML and ReportPDF
+var ErrUnsupportedReportFormat = errors.New("unsupported report format")
+
+const (
+	reportTopBrands = 10
+	reportExamples  = 10
+)
+
+// JobReport summarizes the results of a job for product and marketing teams
+type JobReport struct {
+	Job           *Job
+	GeneratedAt   time.Time
+	Products      int // Stored products
+	WithSizeTable int
+	WithLength    int
+	TopBrands     []BrandSummary
+	Examples      []ReportProduct
+}
+
+// BrandSummary counts the stored products of one brand
+type BrandSummary struct {
+	Brand         string
+	Products      int
+	WithSizeTable int
+}
+
+// ReportProduct is an example product shown in a report
+type ReportProduct struct {
+	ASIN     string
+	Title    string
+	Brand    string
+	URL      string
+	Sizes    int
+	LengthCM float64
+}
+
+// SizeTableCoverage returns the percentage of stored products with a size table
+func (r *JobReport) SizeTableCoverage() float64 {
+	return percent(r.WithSizeTable, r.Products)
+}
+
+// LengthCoverage returns the percentage of stored products with a length
+func (r *JobReport) LengthCoverage() float64 {
+	return percent(r.WithLength, r.Products)
+}
+
+// Skipped returns the number of products the job could not store
+func (r *JobReport) Skipped() int {
+	n := 0
+	for _, count := range r.Job.SkipReasons {
+		n += count
+	}
+	return n
+}
+
+// Coverage returns the percentage of the brand's products with a size table
+func (b BrandSummary) Coverage() float64 {
+	return percent(b.WithSizeTable, b.Products)
+}
+
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total) * 100
+}
+
+// SetReportDir stores rendered reports as <dir>/<job id>.<format> once a job finished, empty renders
+// every request anew
+func (m *Manager) SetReportDir(dir string) {
+	m.reportDir = dir
+}
+
+// BuildReport collects the statistics of a job
+func (m *Manager) BuildReport(ctx context.Context, job *Job) (*JobReport, error) {
+	report := &JobReport{Job: job, GeneratedAt: time.Now()}
+
+	err := m.db.QueryRow(ctx, `
+		SELECT COUNT(*),
+		       COUNT(CASE WHEN p.size_table IS NOT NULL THEN 1 END),
+		       COUNT(CASE WHEN p.length_cm > 0 THEN 1 END)
+		FROM job_products jp
+		JOIN products p ON jp.asin = p.asin
+		WHERE jp.job_id = $1 AND jp.skip_reason IS NULL
+	`, job.ID).Scan(&report.Products, &report.WithSizeTable, &report.WithLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count job products: %w", err)
+	}
+
+	rows, err := m.db.Query(ctx, `
+		SELECT COALESCE(NULLIF(p.brand, ''), 'Unknown') AS brand,
+		       COUNT(*),
+		       COUNT(CASE WHEN p.size_table IS NOT NULL THEN 1 END)
+		FROM job_products jp
+		JOIN products p ON jp.asin = p.asin
+		WHERE jp.job_id = $1 AND jp.skip_reason IS NULL
+		GROUP BY 1
+		ORDER BY 2 DESC, 1
+		LIMIT $2
+	`, job.ID, reportTopBrands)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count brands: %w", err)
+	}
+	for rows.Next() {
+		var b BrandSummary
+		if err := rows.Scan(&b.Brand, &b.Products, &b.WithSizeTable); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan brand: %w", err)
+		}
+		report.TopBrands = append(report.TopBrands, b)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to count brands: %w", err)
+	}
+
+	// Products with a size table first, in the order the job found them
+	rows, err = m.db.Query(ctx, `
+		SELECT p.asin, p.title, COALESCE(p.brand, ''), p.url,
+		       CASE WHEN jsonb_typeof(p.size_table->'sizes') = 'array'
+		            THEN jsonb_array_length(p.size_table->'sizes') ELSE 0 END,
+		       COALESCE(p.length_cm, 0)::float8
+		FROM job_products jp
+		JOIN products p ON jp.asin = p.asin
+		WHERE jp.job_id = $1 AND jp.skip_reason IS NULL
+		ORDER BY p.size_table IS NULL, jp.page_number, jp.asin
+		LIMIT $2
+	`, job.ID, reportExamples)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get example products: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p ReportProduct
+		if err := rows.Scan(&p.ASIN, &p.Title, &p.Brand, &p.URL, &p.Sizes, &p.LengthCM); err != nil {
+			return nil, fmt.Errorf("failed to scan example product: %w", err)
+		}
+		report.Examples = append(report.Examples, p)
+	}
+
+	return report, rows.Err()
+}
+
+// Report returns the report of a job in the given format. Reports of finished jobs are stored in the
+// report directory and served from there afterwards.
+func (m *Manager) Report(ctx context.Context, job *Job, format string) ([]byte, error) {
+	if format != ReportHTML && format != ReportPDF {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedReportFormat, format)
+	}
+
+	finished := job.Status == "completed" || job.Status == "failed"
+	path := ""
+	if m.reportDir != "" && finished {
+		path = filepath.Join(m.reportDir, job.ID+"."+format)
+		if data, err := os.ReadFile(path); err == nil {
+			return data, nil
+		}
+	}
+
+	report, err := m.BuildReport(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := WriteReportHTML(&buf, report); err != nil {
+		return nil, err
+	}
+	data := buf.Bytes()
+
+	if format == ReportPDF {
+		data, err = m.scraper.GetBrowser().RenderPDF(buf.String())
+		if err != nil {
+			return nil, fmt.Errorf("failed to render PDF report: %w", err)
+		}
+	}
+
+	if path != "" {
+		if err := writeReport(path, data); err != nil {
+			m.logger.WarnContext(ctx, "failed to store report", "job_id", job.ID, "path", path, "error", err)
+		}
+	}
+	return data, nil
+}
+
+// storeReport renders the HTML report of a job that just finished
+func (m *Manager) storeReport(ctx context.Context, jobID string) {
+	if m.reportDir == "" {
+		return
+	}
+
+	job, err := m.GetJob(ctx, jobID)
+	if err == nil {
+		_, err = m.Report(ctx, job, ReportHTML)
+	}
+	if err != nil {
+		m.logger.WarnContext(ctx, "failed to generate job report", "job_id", jobID, "error", err)
+	}
+}
+
+func writeReport(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// WriteReportHTML renders a report as a standalone HTML document
+func WriteReportHTML(w io.Writer, report *JobReport) error {
+	if err := reportTemplate.Execute(w, report); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+	return nil
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"pct": func(v float64) string { return fmt.Sprintf("%.1f %%", v) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Job report {{.Job.SearchQuery}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #222; margin: 2em; }
+h1 { font-size: 1.5em; margin-bottom: 0.2em; }
+h2 { font-size: 1.1em; margin-top: 1.8em; border-bottom: 1px solid #ddd; padding-bottom: 0.2em; }
+.meta { color: #666; font-size: 0.9em; }
+.cards { display: flex; gap: 1em; flex-wrap: wrap; margin-top: 1em; }
+.card { border: 1px solid #ddd; border-radius: 6px; padding: 0.6em 1em; min-width: 8em; }
+.card b { display: block; font-size: 1.4em; }
+table { border-collapse: collapse; width: 100%; font-size: 0.9em; }
+th, td { text-align: left; padding: 0.35em 0.5em; border-bottom: 1px solid #eee; }
+th { background: #f6f6f6; }
+td.num, th.num { text-align: right; }
+.bar { background: #eee; height: 0.6em; border-radius: 3px; }
+.bar span { display: block; height: 100%; background: #2f855a; border-radius: 3px; }
+</style>
+</head>
+<body>
+<h1>{{.Job.SearchQuery}}</h1>
+<p class="meta">
+Job {{.Job.ID}} &middot; {{.Job.Marketplace}}{{if .Job.Category}} &middot; {{.Job.Category}}{{end}} &middot; status {{.Job.Status}}<br>
+Created {{.Job.CreatedAt.Format "2006-01-02 15:04"}}{{with .Job.CompletedAt}}, finished {{.Format "2006-01-02 15:04"}}{{end}} &middot; report generated {{.GeneratedAt.Format "2006-01-02 15:04"}}
+</p>
+{{with .Job.Error}}<p><b>Error:</b> {{.}}</p>{{end}}
+
+<div class="cards">
+<div class="card"><b>{{.Job.PagesScraped}}</b>pages scraped</div>
+<div class="card"><b>{{.Products}}</b>products stored</div>
+<div class="card"><b>{{.Skipped}}</b>products skipped</div>
+<div class="card"><b>{{pct .SizeTableCoverage}}</b>with size table</div>
+<div class="card"><b>{{pct .LengthCoverage}}</b>with length</div>
+</div>
+
+{{if .Job.SkipReasons}}
+<h2>Skipped products</h2>
+<table>
+<tr><th>Reason</th><th class="num">Products</th></tr>
+{{range $reason, $count := .Job.SkipReasons}}<tr><td>{{$reason}}</td><td class="num">{{$count}}</td></tr>
+{{end}}</table>
+{{end}}
+
+{{if .TopBrands}}
+<h2>Top brands</h2>
+<table>
+<tr><th>Brand</th><th class="num">Products</th><th class="num">With size table</th><th>Coverage</th></tr>
+{{range .TopBrands}}<tr><td>{{.Brand}}</td><td class="num">{{.Products}}</td><td class="num">{{.WithSizeTable}}</td>
+<td><div class="bar"><span style="width: {{printf "%.0f" .Coverage}}%"></span></div></td></tr>
+{{end}}</table>
+{{end}}
+
+{{if .Examples}}
+<h2>Example products</h2>
+<table>
+<tr><th>ASIN</th><th>Title</th><th>Brand</th><th class="num">Sizes</th><th class="num">Length</th></tr>
+{{range .Examples}}<tr><td><a href="{{.URL}}">{{.ASIN}}</a></td><td>{{.Title}}</td><td>{{.Brand}}</td>
+<td class="num">{{.Sizes}}</td><td class="num">{{if .LengthCM}}{{printf "%.1f" .LengthCM}} cm{{else}}-{{end}}</td></tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
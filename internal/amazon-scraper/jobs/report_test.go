@@ -0,0 +1,56 @@
+package jobs
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteReportHTML(t *testing.T) {
+	report := &JobReport{
+		Job: &Job{
+			ID:           "550e8400-e29b-41d4-a716-446655440000",
+			SearchQuery:  "Herren Jeans <lang>",
+			Marketplace:  "amazon.de",
+			Status:       "completed",
+			PagesScraped: 5,
+			CreatedAt:    time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
+			SkipReasons:  map[string]int{"no_size_table": 3, "captcha": 1},
+		},
+		GeneratedAt:   time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC),
+		Products:      8,
+		WithSizeTable: 6,
+		WithLength:    4,
+		TopBrands:     []BrandSummary{{Brand: "Levi's", Products: 4, WithSizeTable: 3}},
+		Examples:      []ReportProduct{{ASIN: "B08N5WRWNW", Title: "Levi's 501", URL: "https://www.amazon.de/dp/B08N5WRWNW", Sizes: 12, LengthCM: 112.5}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteReportHTML(&buf, report); err != nil {
+		t.Fatal(err)
+	}
+	html := buf.String()
+
+	for _, want := range []string{
+		"Herren Jeans &lt;lang&gt;",
+		"75.0 %", // Size table coverage
+		"50.0 %", // Length coverage
+		"<b>4</b>products skipped",
+		"Levi&#39;s",
+		"no_size_table",
+		`href="https://www.amazon.de/dp/B08N5WRWNW"`,
+		"112.5 cm",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("Expected report to contain %q", want)
+		}
+	}
+}
+
+func TestReportCoverageWithoutProducts(t *testing.T) {
+	report := &JobReport{Job: &Job{}}
+	if report.SizeTableCoverage() != 0 || report.LengthCoverage() != 0 || report.Skipped() != 0 {
+		t.Error("Expected zero coverage for a job without products")
+	}
+}
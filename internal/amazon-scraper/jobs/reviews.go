@@ -0,0 +1,64 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/events"
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/maltedev/amazon-size-scraper/internal/reviewsummary"
+)
+
+// ErrSummariesDisabled is returned when no review summarizer is configured
+var ErrSummariesDisabled = errors.New("review summaries are disabled")
+
+// SetReviewSummarizer enables the review summary stage, nil disables it
+func (m *Manager) SetReviewSummarizer(s *reviewsummary.Summarizer) {
+	m.summarizer = s
+}
+
+// ReviewSummariesEnabled reports whether a review summarizer is configured
+func (m *Manager) ReviewSummariesEnabled() bool {
+	return m.summarizer != nil
+}
+
+// SummarizeReviews derives a fit summary from the stored reviews of a product, stores it and publishes
+// PRODUCT_REVIEWS_ENRICHED. Reviews are stored when they are extracted.
+func (m *Manager) SummarizeReviews(ctx context.Context, asin string) (*database.FitSummary, error) {
+	if m.summarizer == nil {
+		return nil, ErrSummariesDisabled
+	}
+
+	reviews, err := m.db.ListReviews(ctx, asin)
+	if err != nil {
+		return nil, err
+	}
+	summary, err := m.summarizer.Summarize(ctx, reviews)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.db.SaveFitSummary(ctx, asin, summary); err != nil {
+		return nil, err
+	}
+
+	if m.publisher != nil {
+		payload := &events.ReviewsEnrichedPayload{ASIN: asin, FitSummary: summary}
+		if err := m.publisher.PublishReviewsEnriched(ctx, payload); err != nil {
+			return summary, fmt.Errorf("failed to publish reviews enriched event: %w", err)
+		}
+	}
+
+	m.logger.InfoContext(ctx, "summarized reviews",
+		"asin", asin,
+		"reviews", summary.ReviewCount,
+		"runs_small_score", summary.RunsSmallScore,
+		"fabric_quality", summary.FabricQuality,
+	)
+	return summary, nil
+}
+
+// FitSummary returns the stored fit summary of a product, nil if none was made
+func (m *Manager) FitSummary(ctx context.Context, asin string) (*database.FitSummary, error) {
+	return m.db.GetFitSummary(ctx, asin)
+}
@@ -0,0 +1,38 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/events"
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/maltedev/amazon-size-scraper/internal/importer"
+)
+
+// ErrInvalidSizeTable is returned for an uploaded size table that fails validation
+var ErrInvalidSizeTable = errors.New("invalid size table")
+
+// ImportSizeTable replaces the size table of a product with a corrected one uploaded by hand. The table
+// is parsed and validated like a scraped chart; tables with validation errors are rejected with
+// ErrInvalidSizeTable and their report. Stored tables are published as SIZE_TABLE_UPDATED.
+func (m *Manager) ImportSizeTable(ctx context.Context, asin string, table *importer.Table) (*database.SizeTable, *database.ValidationReport, error) {
+	st := m.scraper.ParseSizeTable(table.Data())
+	report := m.scraper.ValidateSizeTable(st)
+	if st == nil || !report.Valid {
+		return nil, report, ErrInvalidSizeTable
+	}
+
+	if err := m.db.SaveManualSizeTable(ctx, asin, st, report); err != nil {
+		return nil, nil, err
+	}
+	m.logger.InfoContext(ctx, "imported size table", "asin", asin, "sizes", len(st.Sizes), "score", report.Score)
+
+	if m.publisher != nil {
+		payload := &events.SizeTableUpdatedPayload{ASIN: asin, SizeTable: st, ValidationReport: report}
+		if err := m.publisher.PublishSizeTableUpdated(ctx, payload); err != nil {
+			return st, report, fmt.Errorf("failed to publish size table updated event: %w", err)
+		}
+	}
+	return st, report, nil
+}
@@ -0,0 +1,158 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/maltedev/amazon-size-scraper/internal/taxonomy"
+)
+
+var (
+	// ErrMappingNotFound is returned when a category mapping does not exist
+	ErrMappingNotFound = errors.New("category mapping not found")
+	// ErrInvalidMapping is returned when a category mapping fails validation
+	ErrInvalidMapping = errors.New("invalid category mapping")
+)
+
+// remapBatchSize is the number of products RemapCategories reads per query
+const remapBatchSize = 500
+
+// LoadCategoryMappings applies the stored category mappings to the mapper used by jobs
+func (m *Manager) LoadCategoryMappings(ctx context.Context) error {
+	custom, err := m.storedCategoryMappings(ctx)
+	if err != nil {
+		return err
+	}
+	m.taxonomy.SetMappings(custom)
+	return nil
+}
+
+// ListCategoryMappings returns the stored mappings followed by the built-in ones they do not override
+func (m *Manager) ListCategoryMappings(ctx context.Context) ([]taxonomy.Mapping, error) {
+	mappings, err := m.storedCategoryMappings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stored := make(map[taxonomy.Mapping]bool, len(mappings))
+	for _, mapping := range mappings {
+		stored[taxonomy.Mapping{Kind: mapping.Kind, Value: mapping.Value}] = true
+	}
+	for _, mapping := range taxonomy.DefaultMappings() {
+		if !stored[taxonomy.Mapping{Kind: mapping.Kind, Value: mapping.Value}] {
+			mappings = append(mappings, mapping)
+		}
+	}
+	return mappings, nil
+}
+
+// SaveCategoryMapping creates or replaces a category mapping and applies it to the following products
+func (m *Manager) SaveCategoryMapping(ctx context.Context, mapping *taxonomy.Mapping) error {
+	if err := mapping.Normalize(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidMapping, err)
+	}
+	mapping.Builtin = false
+
+	query := `
+		INSERT INTO category_mappings (kind, value, category)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (kind, value) DO UPDATE SET
+			category = EXCLUDED.category,
+			updated_at = CURRENT_TIMESTAMP
+	`
+	if _, err := m.db.Exec(ctx, query, mapping.Kind, mapping.Value, mapping.Category); err != nil {
+		return fmt.Errorf("failed to save category mapping: %w", err)
+	}
+
+	m.logger.InfoContext(ctx, "category mapping saved", "kind", mapping.Kind, "value", mapping.Value, "category", mapping.Category)
+	return m.LoadCategoryMappings(ctx)
+}
+
+// DeleteCategoryMapping removes a stored category mapping, built-in mappings cannot be deleted
+func (m *Manager) DeleteCategoryMapping(ctx context.Context, kind, value string) error {
+	mapping := taxonomy.Mapping{Kind: kind, Value: value, Category: taxonomy.TShirt}
+	if err := mapping.Normalize(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidMapping, err)
+	}
+
+	tag, err := m.db.Exec(ctx, `DELETE FROM category_mappings WHERE kind = $1 AND value = $2`, mapping.Kind, mapping.Value)
+	if err != nil {
+		return fmt.Errorf("failed to delete category mapping: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrMappingNotFound
+	}
+	return m.LoadCategoryMappings(ctx)
+}
+
+// RemapCategories applies the current mappings to the category path of every stored product and
+// returns the number of products whose category code changed. Browse nodes are not stored, so node
+// mappings only apply to products scraped afterwards.
+func (m *Manager) RemapCategories(ctx context.Context) (int, error) {
+	if err := m.LoadCategoryMappings(ctx); err != nil {
+		return 0, err
+	}
+
+	changed, after := 0, ""
+	for {
+		rows, err := m.db.Query(ctx, `
+			SELECT asin, category_path, COALESCE(category_code, '')
+			FROM products
+			WHERE category_path IS NOT NULL AND asin > $1
+			ORDER BY asin
+			LIMIT $2
+		`, after, remapBatchSize)
+		if err != nil {
+			return changed, fmt.Errorf("failed to list product categories: %w", err)
+		}
+
+		updates := make(map[string]string)
+		n := 0
+		for rows.Next() {
+			var asin, path, code string
+			if err := rows.Scan(&asin, &path, &code); err != nil {
+				rows.Close()
+				return changed, fmt.Errorf("failed to scan product category: %w", err)
+			}
+			n++
+			after = asin
+			if mapped := m.taxonomy.Map(taxonomy.SplitBreadcrumbs(path), nil); mapped != code {
+				updates[asin] = mapped
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return changed, fmt.Errorf("failed to list product categories: %w", err)
+		}
+
+		for asin, code := range updates {
+			if _, err := m.db.Exec(ctx, `UPDATE products SET category_code = NULLIF($2, '') WHERE asin = $1`, asin, code); err != nil {
+				return changed, fmt.Errorf("failed to update product category: %w", err)
+			}
+			changed++
+		}
+
+		if n < remapBatchSize {
+			return changed, nil
+		}
+	}
+}
+
+func (m *Manager) storedCategoryMappings(ctx context.Context) ([]taxonomy.Mapping, error) {
+	rows, err := m.db.Query(ctx, `SELECT kind, value, category FROM category_mappings ORDER BY kind, value`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list category mappings: %w", err)
+	}
+	defer rows.Close()
+
+	var mappings []taxonomy.Mapping
+	for rows.Next() {
+		var mapping taxonomy.Mapping
+		if err := rows.Scan(&mapping.Kind, &mapping.Value, &mapping.Category); err != nil {
+			return nil, fmt.Errorf("failed to scan category mapping: %w", err)
+		}
+		mappings = append(mappings, mapping)
+	}
+	return mappings, rows.Err()
+}
@@ -0,0 +1,282 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// DefaultMarketplace is used when a job or template does not name one
+const DefaultMarketplace = "amazon.de"
+
+var (
+	// ErrTemplateNotFound is returned when a job template does not exist
+	ErrTemplateNotFound = errors.New("job template not found")
+	// ErrTemplateExists is returned when another template already uses the name
+	ErrTemplateExists = errors.New("job template name already exists")
+	// ErrInvalidTemplate is returned when a template fails validation
+	ErrInvalidTemplate = errors.New("invalid job template")
+)
+
+// marketplacePattern matches marketplace domains such as amazon.de or amazon.co.uk
+var marketplacePattern = regexp.MustCompile(`^amazon(\.[a-z]{2,3}){1,2}$`)
+
+// Template is a saved search definition that can be run as a job
+type Template struct {
+	ID            string            `json:"id"`
+	Name          string            `json:"name"`
+	SearchQuery   string            `json:"search_query"`
+	Category      string            `json:"category"`
+	Marketplace   string            `json:"marketplace"`
+	MaxPages      int               `json:"max_pages"`
+	Filters       map[string]string `json:"filters"`
+	Priority      int               `json:"priority"`
+	ProductFilter *ProductFilter    `json:"product_filter,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+
+	Delta          bool           `json:"delta"`              // Crawl newest listings first and stop at a page of mostly known ASINs
+	DeltaThreshold float64        `json:"delta_threshold"`    // Share of known ASINs that stops a delta crawl, 0 uses DefaultDeltaThreshold
+	Frontier       *CrawlFrontier `json:"frontier,omitempty"` // Where the delta crawls stand, only in GetTemplate
+}
+
+// Normalize applies defaults and validates the template
+func (t *Template) Normalize() error {
+	t.Name = strings.TrimSpace(t.Name)
+	t.SearchQuery = strings.TrimSpace(t.SearchQuery)
+	t.Frontier = nil // Read only, reported by GetTemplate
+
+	if t.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidTemplate)
+	}
+	if t.SearchQuery == "" {
+		return fmt.Errorf("%w: search_query is required", ErrInvalidTemplate)
+	}
+	if t.Marketplace == "" {
+		t.Marketplace = DefaultMarketplace
+	}
+	if !marketplacePattern.MatchString(t.Marketplace) {
+		return fmt.Errorf("%w: unsupported marketplace: %s", ErrInvalidTemplate, t.Marketplace)
+	}
+	if t.MaxPages <= 0 {
+		t.MaxPages = 10
+	}
+	if t.MaxPages > 100 {
+		return fmt.Errorf("%w: max_pages must not exceed 100", ErrInvalidTemplate)
+	}
+	if t.Filters == nil {
+		t.Filters = map[string]string{}
+	}
+	for key := range t.Filters {
+		if key == "k" || key == "i" || key == "page" {
+			return fmt.Errorf("%w: filter %q is set from the template fields", ErrInvalidTemplate, key)
+		}
+	}
+	if err := t.ProductFilter.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	if t.DeltaThreshold == 0 {
+		t.DeltaThreshold = DefaultDeltaThreshold
+	}
+	if t.DeltaThreshold < 0 || t.DeltaThreshold > 1 {
+		return fmt.Errorf("%w: delta_threshold must be between 0 and 1", ErrInvalidTemplate)
+	}
+	if _, ok := t.Filters["s"]; ok && t.Delta {
+		return fmt.Errorf("%w: filter \"s\" is set by delta crawls", ErrInvalidTemplate)
+	}
+	return nil
+}
+
+// CreateTemplate stores a new job template
+func (m *Manager) CreateTemplate(ctx context.Context, t *Template) (*Template, error) {
+	if err := t.Normalize(); err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO job_templates (name, search_query, category, marketplace, max_pages, filters, priority, product_filter, delta, delta_threshold)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := m.db.QueryRow(ctx, query,
+		t.Name, t.SearchQuery, t.Category, t.Marketplace, t.MaxPages, t.Filters, t.Priority, t.ProductFilter,
+		t.Delta, t.DeltaThreshold,
+	).Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt)
+	if isUniqueViolation(err) {
+		return nil, ErrTemplateExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create job template: %w", err)
+	}
+
+	m.logger.InfoContext(ctx, "job template created", "id", t.ID, "name", t.Name)
+	return t, nil
+}
+
+// GetTemplate retrieves a job template by ID
+func (m *Manager) GetTemplate(ctx context.Context, id string) (*Template, error) {
+	query := `
+		SELECT id, name, search_query, COALESCE(category, ''), marketplace, max_pages, filters, priority,
+		       product_filter, created_at, updated_at, delta, delta_threshold
+		FROM job_templates
+		WHERE id::text = $1
+	`
+
+	t := &Template{}
+	err := m.db.QueryRow(ctx, query, id).Scan(
+		&t.ID, &t.Name, &t.SearchQuery, &t.Category, &t.Marketplace, &t.MaxPages, &t.Filters, &t.Priority,
+		&t.ProductFilter, &t.CreatedAt, &t.UpdatedAt, &t.Delta, &t.DeltaThreshold,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, ErrTemplateNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job template: %w", err)
+	}
+
+	if t.Delta {
+		if t.Frontier, err = m.getFrontier(ctx, t.ID); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// ListTemplates lists all job templates by name
+func (m *Manager) ListTemplates(ctx context.Context) ([]*Template, error) {
+	query := `
+		SELECT id, name, search_query, COALESCE(category, ''), marketplace, max_pages, filters, priority,
+		       product_filter, created_at, updated_at, delta, delta_threshold
+		FROM job_templates
+		ORDER BY name
+	`
+
+	rows, err := m.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list job templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates := []*Template{}
+	for rows.Next() {
+		t := &Template{}
+		if err := rows.Scan(
+			&t.ID, &t.Name, &t.SearchQuery, &t.Category, &t.Marketplace, &t.MaxPages, &t.Filters, &t.Priority,
+			&t.ProductFilter, &t.CreatedAt, &t.UpdatedAt, &t.Delta, &t.DeltaThreshold,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan job template: %w", err)
+		}
+		templates = append(templates, t)
+	}
+
+	return templates, rows.Err()
+}
+
+// UpdateTemplate replaces the settings of an existing job template
+func (m *Manager) UpdateTemplate(ctx context.Context, t *Template) (*Template, error) {
+	if err := t.Normalize(); err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE job_templates
+		SET name = $2, search_query = $3, category = $4, marketplace = $5,
+		    max_pages = $6, filters = $7, priority = $8, product_filter = $9, delta = $10, delta_threshold = $11,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id::text = $1
+		RETURNING created_at, updated_at
+	`
+
+	err := m.db.QueryRow(ctx, query,
+		t.ID, t.Name, t.SearchQuery, t.Category, t.Marketplace, t.MaxPages, t.Filters, t.Priority, t.ProductFilter,
+		t.Delta, t.DeltaThreshold,
+	).Scan(&t.CreatedAt, &t.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, ErrTemplateNotFound
+	}
+	if isUniqueViolation(err) {
+		return nil, ErrTemplateExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update job template: %w", err)
+	}
+
+	return t, nil
+}
+
+// DeleteTemplate removes a job template, jobs created from it keep running without the reference
+func (m *Manager) DeleteTemplate(ctx context.Context, id string) error {
+	tag, err := m.db.Exec(ctx, `DELETE FROM job_templates WHERE id::text = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete job template: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrTemplateNotFound
+	}
+	return nil
+}
+
+// RunTemplate creates a pending job from a template, or returns the identical job of the dedup window.
+// Delta templates sort the search by newest listings.
+func (m *Manager) RunTemplate(ctx context.Context, id string) (*Job, error) {
+	t, err := m.GetTemplate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	filters := t.Filters
+	if t.Delta {
+		filters = make(map[string]string, len(t.Filters)+1)
+		for key, value := range t.Filters {
+			filters[key] = value
+		}
+		filters["s"] = deltaSort
+	}
+
+	return m.createJob(ctx, &Job{
+		TemplateID:     &t.ID,
+		SearchQuery:    t.SearchQuery,
+		Category:       t.Category,
+		Marketplace:    t.Marketplace,
+		MaxPages:       t.MaxPages,
+		Filters:        filters,
+		Priority:       t.Priority,
+		ProductFilter:  t.ProductFilter,
+		Delta:          t.Delta,
+		DeltaThreshold: t.DeltaThreshold,
+		dedupKey:       dedupKey(t.Marketplace, t.SearchQuery, t.Category),
+	})
+}
+
+// isUniqueViolation reports whether err is a Postgres unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// buildSearchURL builds the marketplace search URL for a job
+func buildSearchURL(marketplace, searchQuery, category string, filters map[string]string) string {
+	if marketplace == "" {
+		marketplace = DefaultMarketplace
+	}
+
+	params := url.Values{}
+	params.Set("k", searchQuery)
+	if category != "" {
+		params.Set("i", category)
+	}
+
+	for key, value := range filters {
+		params.Set(key, value)
+	}
+
+	return fmt.Sprintf("https://www.%s/s?%s", marketplace, params.Encode())
+}
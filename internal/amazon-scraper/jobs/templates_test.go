@@ -0,0 +1,77 @@
+package jobs
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTemplateNormalize(t *testing.T) {
+	tmpl := &Template{Name: " tall-shirts ", SearchQuery: "tall t-shirt"}
+	if err := tmpl.Normalize(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tmpl.Name != "tall-shirts" || tmpl.Marketplace != DefaultMarketplace || tmpl.MaxPages != 10 || tmpl.Filters == nil {
+		t.Errorf("defaults not applied: %+v", tmpl)
+	}
+
+	invalid := []*Template{
+		{SearchQuery: "shirt"},
+		{Name: "no-query"},
+		{Name: "bad-marketplace", SearchQuery: "shirt", Marketplace: "amazon.de/evil"},
+		{Name: "too-many-pages", SearchQuery: "shirt", MaxPages: 101},
+		{Name: "reserved-filter", SearchQuery: "shirt", Filters: map[string]string{"page": "3"}},
+	}
+	for _, tmpl := range invalid {
+		if err := tmpl.Normalize(); !errors.Is(err, ErrInvalidTemplate) {
+			t.Errorf("Normalize(%q) error = %v, want ErrInvalidTemplate", tmpl.Name, err)
+		}
+	}
+
+	uk := &Template{Name: "uk", SearchQuery: "shirt", Marketplace: "amazon.co.uk"}
+	if err := uk.Normalize(); err != nil {
+		t.Errorf("amazon.co.uk rejected: %v", err)
+	}
+}
+
+func TestBuildSearchURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		marketplace string
+		query       string
+		category    string
+		filters     map[string]string
+		want        string
+	}{
+		{"defaults", "", "t-shirt", "", nil, "https://www.amazon.de/s?k=t-shirt"},
+		{"escapes query", "amazon.de", "tall t-shirt größe", "fashion", nil, "https://www.amazon.de/s?i=fashion&k=tall+t-shirt+gr%C3%B6%C3%9Fe"},
+		{"filters", "amazon.fr", "shirt", "", map[string]string{"s": "review-rank", "rh": "p_72:419117031"}, "https://www.amazon.fr/s?k=shirt&rh=p_72%3A419117031&s=review-rank"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildSearchURL(tt.marketplace, tt.query, tt.category, tt.filters); got != tt.want {
+				t.Errorf("buildSearchURL() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateNormalizeDelta(t *testing.T) {
+	tmpl := &Template{Name: "new-shirts", SearchQuery: "tall t-shirt", Delta: true}
+	if err := tmpl.Normalize(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tmpl.DeltaThreshold != DefaultDeltaThreshold {
+		t.Errorf("DeltaThreshold = %v, want %v", tmpl.DeltaThreshold, DefaultDeltaThreshold)
+	}
+
+	invalid := []*Template{
+		{Name: "threshold", SearchQuery: "shirt", Delta: true, DeltaThreshold: 1.5},
+		{Name: "sorted", SearchQuery: "shirt", Delta: true, Filters: map[string]string{"s": "price-asc-rank"}},
+	}
+	for _, tmpl := range invalid {
+		if err := tmpl.Normalize(); !errors.Is(err, ErrInvalidTemplate) {
+			t.Errorf("Normalize(%q) error = %v, want ErrInvalidTemplate", tmpl.Name, err)
+		}
+	}
+}
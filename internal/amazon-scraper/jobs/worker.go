@@ -2,25 +2,35 @@ package jobs
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/events"
 	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/scraper"
 	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/maltedev/amazon-size-scraper/internal/dedup"
+	"github.com/maltedev/amazon-size-scraper/internal/quota"
+	logging "github.com/maltedev/amazon-size-scraper/pkg/logger"
 )
 
 // StartWorker starts the background job worker
 func (m *Manager) StartWorker(ctx context.Context) {
-	m.logger.Info("job worker started")
+	m.logger.InfoContext(ctx, "job worker started")
 	
 	ticker := time.NewTicker(10 * time.Second)
 	defer ticker.Stop()
 
 	for {
+		// Jobs left running by a restart are picked up again once their heartbeat is stale
+		if _, err := m.RecoverJobs(ctx); err != nil && ctx.Err() == nil {
+			m.logger.ErrorContext(ctx, "failed to recover jobs", "error", err)
+		}
+
 		select {
 		case <-ctx.Done():
-			m.logger.Info("job worker stopping")
+			m.logger.InfoContext(ctx, "job worker stopping")
 			return
 		case <-ticker.C:
 			m.processNextJob(ctx)
@@ -30,122 +40,325 @@ func (m *Manager) StartWorker(ctx context.Context) {
 
 // processNextJob processes the next pending job
 func (m *Manager) processNextJob(ctx context.Context) {
-	// Get next pending job
+	// Claim the next pending job in one statement, the row lock is held until it is running so no
+	// other instance picks it up as well
 	query := `
-		SELECT id, search_query, category, max_pages
-		FROM scraper_jobs
-		WHERE status = 'pending'
-		ORDER BY created_at
-		LIMIT 1
-		FOR UPDATE SKIP LOCKED
+		UPDATE scraper_jobs
+		SET status = 'running', started_at = COALESCE(started_at, NOW()), heartbeat_at = NOW()
+		WHERE id = (
+			SELECT id FROM scraper_jobs
+			WHERE status = 'pending' AND (not_before IS NULL OR not_before <= NOW())
+			ORDER BY priority DESC, created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, template_id, search_query, category, marketplace, max_pages, filters, product_filter, delta, delta_threshold, metadata
 	`
 
-	var jobID, searchQuery, category string
-	var maxPages int
-	
-	err := m.db.QueryRow(ctx, query).Scan(&jobID, &searchQuery, &category, &maxPages)
+	job := &Job{}
+	err := m.db.QueryRow(ctx, query).Scan(
+		&job.ID, &job.TemplateID, &job.SearchQuery, &job.Category, &job.Marketplace, &job.MaxPages, &job.Filters, &job.ProductFilter,
+		&job.Delta, &job.DeltaThreshold, &job.Metadata,
+	)
 	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) && ctx.Err() == nil {
+			m.logger.ErrorContext(ctx, "failed to claim next job", "error", err)
+		}
 		// No pending jobs
 		return
 	}
+	jobID := job.ID
+
+	// Every record of this job run, down to the relayed events, carries the job and trace ID, its events
+	// and products the caller metadata
+	ctx = logging.WithJobID(logging.EnsureTraceID(ctx), jobID)
+	ctx = events.WithMetadata(ctx, job.Metadata)
 
-	m.logger.Info("processing job", "id", jobID, "query", searchQuery)
+	m.logger.InfoContext(ctx, "processing job", "id", jobID, "query", job.SearchQuery, "marketplace", job.Marketplace)
+	m.publishJobEvent(ctx, events.EventTypeJobStarted, jobID, nil)
 
-	// Update status to running
-	if err := m.updateJobStatus(ctx, jobID, "running", nil); err != nil {
-		m.logger.Error("failed to update job status", "error", err)
+	// Process the job, its page fetches are charged to the job's daily budget. CancelJob stops it
+	// through its own context.
+	jobCtx, cancelJob := context.WithCancelCause(ctx)
+	untrack := m.trackJob(jobID, cancelJob)
+	stopHeartbeat := m.heartbeat(jobCtx, jobID, cancelJob)
+	err = m.processJob(quota.WithSubject(jobCtx, quota.JobSubject(jobID)), job)
+	stopHeartbeat()
+	untrack()
+	cancelled := errors.Is(context.Cause(jobCtx), ErrJobCancelled)
+	cancelJob(nil)
+	if ctx.Err() != nil {
+		// Shutdown, the job stays running and is recovered from its checkpoint
+		m.logger.WarnContext(ctx, "job interrupted", "id", jobID)
 		return
 	}
-
-	// Process the job
-	if err := m.processJob(ctx, jobID, searchQuery, category, maxPages); err != nil {
-		m.logger.Error("job failed", "id", jobID, "error", err)
+	if cancelled {
+		// CancelJob already stored the status and published JOB_CANCELLED
+		m.logger.WarnContext(ctx, "job cancelled", "id", jobID)
+		m.emit(ProgressEvent{Type: EventJobFinished, JobID: jobID, Status: "cancelled"})
+		m.storeReport(ctx, jobID)
+		return
+	}
+	if err != nil {
+		if errors.Is(err, quota.ErrBudgetExceeded) && m.quotaAction == quota.ActionQueue {
+			m.requeueJob(ctx, jobID, err)
+			m.emit(ProgressEvent{Type: EventJobRequeued, JobID: jobID, Status: "pending", Error: err.Error()})
+			return
+		}
+		m.logger.ErrorContext(ctx, "job failed", "id", jobID, "error", err)
 		m.updateJobStatus(ctx, jobID, "failed", err)
+		m.emit(ProgressEvent{Type: EventJobFinished, JobID: jobID, Status: "failed", Error: err.Error()})
+		m.publishJobEvent(ctx, events.EventTypeJobFailed, jobID, err)
+		m.storeReport(ctx, jobID)
 		return
 	}
 
 	// Mark as completed
 	if err := m.updateJobStatus(ctx, jobID, "completed", nil); err != nil {
-		m.logger.Error("failed to mark job as completed", "error", err)
+		m.logger.ErrorContext(ctx, "failed to mark job as completed", "error", err)
 	}
+	m.emit(ProgressEvent{Type: EventJobFinished, JobID: jobID, Status: "completed"})
+	m.publishJobEvent(ctx, events.EventTypeJobCompleted, jobID, nil)
+	m.storeReport(ctx, jobID)
 
-	m.logger.Info("job completed", "id", jobID)
+	m.logger.InfoContext(ctx, "job completed", "id", jobID)
 }
 
 // processJob processes a single job
-func (m *Manager) processJob(ctx context.Context, jobID, searchQuery, category string, maxPages int) error {
+func (m *Manager) processJob(ctx context.Context, job *Job) error {
+	if job.Category == CategorySizeAnalysis {
+		return m.processSizeAnalysis(ctx, job)
+	}
+	jobID, maxPages := job.ID, job.MaxPages
+
+	cp, err := m.loadCheckpoint(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	if cp.Page > 0 || len(cp.ASINs) > 0 {
+		m.logger.InfoContext(ctx, "resuming job from checkpoint", "id", jobID, "page", cp.Page, "products_done", len(cp.ASINs))
+	}
+
 	// Create category crawler
 	crawler := scraper.NewCategoryCrawler(m.scraper, m.logger)
-	
+	if m.crawlWorkers > 0 {
+		crawler.SetWorkers(m.crawlWorkers)
+	}
+
+	// The profile active at the start sets the workers for the whole job, rate limits follow the clock
+	if sched := m.scraper.Schedule(); sched != nil {
+		marketplace := job.Marketplace
+		if marketplace == "" {
+			marketplace = DefaultMarketplace
+		}
+		profile, settings := sched.Profile(marketplace)
+		crawler.SetWorkers(settings.Workers)
+		m.logger.InfoContext(ctx, "crawl profile", "marketplace", marketplace, "profile", profile,
+			"workers", settings.Workers, "rate_limit", settings.RateLimit())
+	}
+
+	// Mappings edited through another instance apply from the next job on
+	if err := m.LoadCategoryMappings(ctx); err != nil {
+		m.logger.WarnContext(ctx, "failed to load category mappings", "error", err)
+	}
+	if err := m.LoadBrandAliases(ctx); err != nil {
+		m.logger.WarnContext(ctx, "failed to load brand aliases", "error", err)
+	}
+
 	// Construct search URL
-	searchURL := fmt.Sprintf("https://www.amazon.de/s?k=%s", searchQuery)
-	if category != "" {
-		searchURL += fmt.Sprintf("&i=%s", category)
+	searchURL := buildSearchURL(job.Marketplace, job.SearchQuery, job.Category, job.Filters)
+
+	// A marketplace serving error pages en masse is paused for all workers
+	if err := m.scraper.GetBrowser().WaitMarketplace(ctx, searchURL); err != nil {
+		return err
 	}
 
-	// Crawl pages
-	totalProducts := 0
-	for page := 1; page <= maxPages; page++ {
+	// Crawl the result pages after the checkpoint, ordered by page index. Delta crawls of a template
+	// fetch them one by one and stop at the first page of mostly known ASINs.
+	var results []*scraper.PageResult
+	var delta *deltaCrawl
+	if job.Delta && job.TemplateID != nil {
+		results, delta, err = m.crawlDelta(ctx, crawler, job, searchURL, cp.Page+1)
+	} else {
+		results, err = crawler.CrawlFrom(ctx, searchURL, cp.Page+1, maxPages)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to crawl search results: %w", err)
+	}
+
+	totalProducts := cp.ProductsFound
+	filteredProducts := cp.ProductsFiltered
+	duplicateProducts := 0
+	for _, result := range results {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 		default:
 		}
 
-		m.logger.Info("crawling page", "job", jobID, "page", page)
-
-		// Crawl page and get ASINs
-		products, hasNext, err := crawler.CrawlPage(ctx, searchURL, page)
-		if err != nil {
-			m.logger.Error("failed to crawl page", "page", page, "error", err)
+		page := result.Page
+		if errors.Is(result.Err, quota.ErrBudgetExceeded) {
+			return result.Err
+		}
+		if result.Err != nil {
+			m.logger.ErrorContext(ctx, "failed to crawl page", "page", page, "error", result.Err)
+			m.emit(ProgressEvent{Type: EventPageCompleted, JobID: jobID, Page: page, Error: result.Err.Error(),
+				ProductsFound: totalProducts, ProductsFiltered: filteredProducts})
 			// Continue with next page even if one fails
 			continue
 		}
 
+		// done checkpoints a processed product, so a resumed job does not process it again
+		done := func(asin string) {
+			m.checkpointProduct(ctx, jobID, asin, totalProducts, filteredProducts)
+		}
+
+		// skip reports a product that is not stored
+		skip := func(asin, reason, detail string) {
+			m.emit(ProgressEvent{Type: EventProductSkipped, JobID: jobID, Page: page, ASIN: asin, Reason: reason, Detail: detail,
+				ProductsFound: totalProducts, ProductsFiltered: filteredProducts})
+			if !retrySkip(reason) {
+				done(asin)
+			}
+		}
+
+		// A requeued job resumes without fetching products it already saved, looked up once per page
+		asins := make([]string, 0, len(result.Products))
+		for _, product := range result.Products {
+			asins = append(asins, product.ASIN)
+		}
+		known := m.jobProducts(ctx, jobID, asins)
+
+		// Extracted products are saved in one batch per page, unchanged ones are only linked to the job
+		var extracted []pageProduct
+		save := func(ctx context.Context) {
+			if len(extracted) == 0 {
+				return
+			}
+			batch := extracted
+			extracted = nil
+
+			if err := m.savePageProducts(ctx, jobID, page, batch); err != nil {
+				m.logger.ErrorContext(ctx, "failed to save products", "page", page, "products", len(batch), "error", err)
+				for _, p := range batch {
+					skip(p.ASIN, SkipSaveFailed, err.Error())
+				}
+				return
+			}
+
+			for _, p := range batch {
+				totalProducts++
+				done(p.ASIN)
+				if p.unchanged {
+					m.logger.DebugContext(ctx, "product unchanged", "asin", p.ASIN)
+					m.emit(ProgressEvent{Type: EventProductUnchanged, JobID: jobID, Page: page, ASIN: p.ASIN,
+						ProductsFound: totalProducts, ProductsFiltered: filteredProducts})
+					continue
+				}
+
+				// Publish enhanced NEW_PRODUCT_DETECTED event, duplicates of a known product are only linked
+				saved := ProgressEvent{Type: EventProductSaved, JobID: jobID, Page: page, ASIN: p.ASIN,
+					ProductsFound: totalProducts, ProductsFiltered: filteredProducts}
+				if canonical := m.registerFingerprint(ctx, p.CompleteProduct); canonical != p.ASIN {
+					m.logger.InfoContext(ctx, "duplicate product linked", "asin", p.ASIN, "canonical_asin", canonical)
+					duplicateProducts++
+					saved.CanonicalASIN = canonical
+				} else if err := m.publishEnhancedProductEvent(ctx, p.CompleteProduct); err != nil {
+					m.logger.ErrorContext(ctx, "failed to publish event", "asin", p.ASIN, "error", err)
+				}
+				m.emit(saved)
+			}
+		}
+
 		// Process found products
-		for _, product := range products {
+		for _, product := range result.Products {
+			if cp.Done(product.ASIN) {
+				continue
+			}
+
+			// Brand filters compare canonical names
+			product.Brand = m.brands.Canonical(product.Brand)
+
+			// Skip irrelevant results before the expensive product extraction
+			if ok, reason := job.ProductFilter.Match(product); !ok {
+				m.logger.DebugContext(ctx, "product filtered", "job", jobID, "asin", product.ASIN, "reason", reason)
+				filteredProducts++
+				skip(product.ASIN, SkipFiltered, reason)
+				continue
+			}
+
+			if known[product.ASIN] {
+				continue
+			}
+
+			if err := m.scraper.GetBrowser().WaitMarketplace(ctx, product.URL); err != nil {
+				// The products extracted so far are kept even when the job was cancelled
+				saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+				save(saveCtx)
+				cancel()
+				return err
+			}
+
 			// Extract complete product data including size table
 			completeProduct, err := m.extractCompleteProductData(ctx, product)
-			if err != nil {
-				m.logger.Warn("skipping product - no valid size table", 
-					"asin", product.ASIN, 
+			if errors.Is(err, quota.ErrBudgetExceeded) {
+				save(ctx)
+				return err
+			}
+			if errors.Is(err, scraper.ErrTaskTimeout) {
+				m.logger.WarnContext(ctx, "skipping product - extraction timed out",
+					"asin", product.ASIN,
+					"category", scraper.FailureTimeout,
 					"error", err)
+				m.recordSkip(ctx, jobID, product.ASIN, page, SkipTimeout)
+				skip(product.ASIN, SkipTimeout, err.Error())
 				continue
 			}
-			
-			// Save complete product to database
-			if err := m.saveCompleteProduct(ctx, jobID, completeProduct, page); err != nil {
-				m.logger.Error("failed to save product", "asin", product.ASIN, "error", err)
+			if err != nil {
+				reason := SkipReason(err)
+				m.logger.WarnContext(ctx, "skipping product - no valid size table", 
+					"asin", product.ASIN, 
+					"reason", reason,
+					"error", err)
+				if scraper.Blocked(err) {
+					m.saveFallbackProduct(ctx, product)
+				}
+				m.recordSkip(ctx, jobID, product.ASIN, page, reason)
+				skip(product.ASIN, reason, err.Error())
 				continue
 			}
 			
-			// Publish enhanced NEW_PRODUCT_DETECTED event
-			if err := m.publishEnhancedProductEvent(ctx, completeProduct); err != nil {
-				m.logger.Error("failed to publish event", "asin", product.ASIN, "error", err)
+			m.scraper.FillFromFallback(ctx, completeProduct)
+			m.applyReportingPrice(ctx, completeProduct)
+
+			// A re-scrape that finds the stored content only moves last_checked_at
+			unchanged, err := m.db.MarkProductUnchanged(ctx, completeProduct.ASIN, completeProduct.ContentHash())
+			if err != nil {
+				m.logger.WarnContext(ctx, "failed to compare content hash", "asin", product.ASIN, "error", err)
 			}
-			
-			totalProducts++
+			extracted = append(extracted, pageProduct{CompleteProduct: completeProduct, unchanged: unchanged})
 			
 			// Rate limiting between product extractions
 			time.Sleep(2 * time.Second)
 		}
+		save(ctx)
 
 		// Update progress
-		if err := m.updateJobProgress(ctx, jobID, page, totalProducts); err != nil {
-			m.logger.Error("failed to update progress", "error", err)
+		if err := m.updateJobProgress(ctx, jobID, page, totalProducts, filteredProducts); err != nil {
+			m.logger.ErrorContext(ctx, "failed to update progress", "error", err)
 		}
+		m.emit(ProgressEvent{Type: EventPageCompleted, JobID: jobID, Page: page,
+			ProductsFound: totalProducts, ProductsFiltered: filteredProducts})
+	}
 
-		// Check if there are more pages
-		if !hasNext {
-			m.logger.Info("no more pages", "job", jobID, "lastPage", page)
-			break
+	if delta != nil {
+		if err := m.saveFrontier(ctx, job, delta); err != nil {
+			m.logger.ErrorContext(ctx, "failed to save crawl frontier", "job", jobID, "error", err)
 		}
-
-		// Rate limiting
-		time.Sleep(3 * time.Second)
+		m.logger.InfoContext(ctx, "delta crawl stopped", "job", jobID, "pages", delta.pages, "stop_page", delta.stopPage, "known_share", delta.share)
 	}
 
-	m.logger.Info("job processing complete", "job", jobID, "products", totalProducts)
+	m.logger.InfoContext(ctx, "job processing complete", "job", jobID, "products", totalProducts, "filtered", filteredProducts, "duplicates", duplicateProducts)
 	return nil
 }
 
@@ -158,7 +371,7 @@ func (m *Manager) saveProduct(ctx context.Context, jobID string, product *scrape
 			status, created_at, updated_at
 		) VALUES (
 			gen_random_uuid(), $1, $2, $3, $4,
-			'PENDING', NOW(), NOW()
+			$5, NOW(), NOW()
 		)
 		ON CONFLICT (asin) DO UPDATE SET
 			title = EXCLUDED.title,
@@ -168,7 +381,7 @@ func (m *Manager) saveProduct(ctx context.Context, jobID string, product *scrape
 	`
 
 	_, err := m.db.Exec(ctx, productQuery, 
-		product.ASIN, product.Title, product.URL, product.Brand)
+		product.ASIN, product.Title, product.URL, product.Brand, database.StatusDiscovered)
 	if err != nil {
 		return fmt.Errorf("failed to insert product: %w", err)
 	}
@@ -188,52 +401,227 @@ func (m *Manager) saveProduct(ctx context.Context, jobID string, product *scrape
 	return nil
 }
 
+// requeueJob returns a job that exhausted its fetch budget to pending until the budget resets
+func (m *Manager) requeueJob(ctx context.Context, jobID string, cause error) {
+	notBefore := time.Now().Add(time.Hour)
+	if t := m.scraper.Quota(); t != nil {
+		notBefore = time.Now().Add(t.ResetIn())
+	}
+
+	query := `UPDATE scraper_jobs SET status = 'pending', not_before = $1 WHERE id = $2`
+	if _, err := m.db.Exec(ctx, query, notBefore, jobID); err != nil {
+		m.logger.ErrorContext(ctx, "failed to requeue job", "id", jobID, "error", err)
+		m.updateJobStatus(ctx, jobID, "failed", cause)
+		m.publishJobEvent(ctx, events.EventTypeJobFailed, jobID, cause)
+		return
+	}
+	m.logger.WarnContext(ctx, "job requeued, fetch budget exceeded", "id", jobID, "not_before", notBefore, "error", cause)
+}
+
+// jobProducts returns which of asins the job already saved or skipped for a reason a retry would
+// not fix, timeouts, captchas and cooldowns are retried. A failed lookup processes all of them.
+func (m *Manager) jobProducts(ctx context.Context, jobID string, asins []string) map[string]bool {
+	found := make(map[string]bool)
+	if len(asins) == 0 {
+		return found
+	}
+	query := `
+		SELECT asin FROM job_products
+		WHERE job_id = $1 AND asin = ANY($2)
+		  AND (skip_reason IS NULL OR skip_reason NOT IN ('timeout', 'captcha', 'cooldown'))`
+	rows, err := m.db.Query(ctx, query, jobID, asins)
+	if err != nil {
+		m.logger.WarnContext(ctx, "failed to look up job products", "job", jobID, "error", err)
+		return found
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var asin string
+		if err := rows.Scan(&asin); err != nil {
+			m.logger.WarnContext(ctx, "failed to scan job product", "job", jobID, "error", err)
+			return make(map[string]bool)
+		}
+		found[asin] = true
+	}
+	return found
+}
+
 // extractCompleteProductData extracts full product data including size table
 func (m *Manager) extractCompleteProductData(ctx context.Context, product *scraper.Product) (*scraper.CompleteProduct, error) {
+	ctx = logging.WithASIN(ctx, product.ASIN)
 	extractor := scraper.NewProductExtractor(m.scraper.GetBrowser(), m.logger)
+	extractor.SetStages(m.scraper.Stages())
+	extractor.SetMeasurementPolicy(m.scraper.MeasurementPolicy())
 	
-	completeProduct, err := extractor.ExtractCompleteProduct(ctx, product.ASIN, product.URL)
+	// Run under the browser supervisor so a Chromium crash relaunches the browser and replays this product,
+	// the task deadline keeps a stuck page from blocking the worker
+	var completeProduct *scraper.CompleteProduct
+	err := m.scraper.RunTask(ctx, "complete_product:"+product.ASIN, func(ctx context.Context) error {
+		if err := m.scraper.ConsumeQuota(ctx, 1); err != nil {
+			return err
+		}
+		var err error
+		completeProduct, err = extractor.ExtractCompleteProduct(ctx, product.ASIN, product.URL)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 	
-	// Ensure we have a valid size table with length and width
-	if completeProduct.SizeTable == nil || !database.ValidateSizeTable(completeProduct.SizeTable) {
-		return nil, fmt.Errorf("product does not have valid size table with length and width")
+	completeProduct.CategoryCode = m.taxonomy.Map(completeProduct.Breadcrumbs, completeProduct.BrowseNodes)
+	completeProduct.Brand = m.brands.Canonical(completeProduct.Brand)
+
+	if err := m.validateProduct(completeProduct); err != nil {
+		return nil, err
 	}
 	
 	return completeProduct, nil
 }
 
-// saveCompleteProduct saves a complete product with all data to the database
-func (m *Manager) saveCompleteProduct(ctx context.Context, jobID string, product *scraper.CompleteProduct, pageNumber int) error {
+// validateProduct runs the size table rules with the measurements the policy requires, the report is
+// stored with the product for quality scoring. Only an empty table or missing required measurements
+// reject the product, other issues lower its score.
+func (m *Manager) validateProduct(product *scraper.CompleteProduct) error {
+	report := m.scraper.ValidateSizeTable(product.SizeTable)
+	product.Validation = report
+	if issue := report.Rejection(); issue != nil {
+		return fmt.Errorf("%w: %s", scraper.ErrInvalidSizeTable, issue.Message)
+	}
+	return nil
+}
+
+// pageProduct is a product extracted from a result page, waiting for the page's batched save
+type pageProduct struct {
+	*scraper.CompleteProduct
+	unchanged bool // Matched its stored content hash, only linked to the job
+}
+
+// savePageProducts stores the changed products of a page and links all of them to the job, one
+// statement each for the products, their metadata and the links instead of one per product
+func (m *Manager) savePageProducts(ctx context.Context, jobID string, pageNumber int, products []pageProduct) error {
+	var changed []*scraper.CompleteProduct
+	asins := make([]string, 0, len(products))
+	for _, p := range products {
+		if !p.unchanged {
+			changed = append(changed, p.CompleteProduct)
+		}
+		asins = append(asins, p.ASIN)
+	}
+
+	if err := m.storeCompleteProducts(ctx, changed); err != nil {
+		return err
+	}
+	m.mergeProductMetadata(ctx, asins...)
+	return m.linkJobProducts(ctx, jobID, asins, pageNumber)
+}
+
+// storeCompleteProduct stores a complete product and its normalized size measurements
+func (m *Manager) storeCompleteProduct(ctx context.Context, product *scraper.CompleteProduct) error {
+	if err := m.storeCompleteProducts(ctx, []*scraper.CompleteProduct{product}); err != nil {
+		return err
+	}
+	m.mergeProductMetadata(ctx, product.ASIN)
+	return nil
+}
+
+// storeCompleteProducts stores complete products in one batch along with their normalized size measurements
+func (m *Manager) storeCompleteProducts(ctx context.Context, products []*scraper.CompleteProduct) error {
 	// Convert to database ProductLifecycle
 	extractor := scraper.NewProductExtractor(m.scraper.GetBrowser(), m.logger)
-	dbProduct, err := extractor.ConvertToLifecycleProduct(product)
-	if err != nil {
-		return fmt.Errorf("failed to convert product: %w", err)
+	dbProducts := make([]*database.ProductLifecycle, 0, len(products))
+	for _, product := range products {
+		dbProduct, err := extractor.ConvertToLifecycleProduct(product)
+		if err != nil {
+			return fmt.Errorf("failed to convert product %s: %w", product.ASIN, err)
+		}
+		dbProducts = append(dbProducts, dbProduct)
 	}
 	
 	// Insert into product table
-	if err := m.db.InsertProductLifecycle(ctx, dbProduct); err != nil {
-		return fmt.Errorf("failed to insert product: %w", err)
+	if err := m.db.InsertProductLifecycles(ctx, dbProducts); err != nil {
+		return fmt.Errorf("failed to insert products: %w", err)
 	}
 	
-	// Link to job
-	jobProductQuery := `
-		INSERT INTO job_products (job_id, asin, page_number)
-		VALUES ($1, $2, $3)
-		ON CONFLICT (job_id, asin) DO NOTHING
-	`
+	// Flatten the size tables into per-size rows for downstream matching
+	for _, product := range products {
+		if err := m.db.SaveSizeMeasurements(ctx, product.ASIN, product.SizeTable); err != nil {
+			m.logger.WarnContext(ctx, "failed to save size measurements", "asin", product.ASIN, "error", err)
+		}
+	}
 	
-	_, err = m.db.Exec(ctx, jobProductQuery, jobID, product.ASIN, pageNumber)
+	return nil
+}
+
+// mergeProductMetadata stores the caller metadata of the job or request in ctx with products
+func (m *Manager) mergeProductMetadata(ctx context.Context, asins ...string) {
+	if err := m.db.MergeProductsMetadata(ctx, asins, events.Metadata(ctx)); err != nil {
+		m.logger.WarnContext(ctx, "failed to save product metadata", "asins", asins, "error", err)
+	}
+}
+
+// saveFallbackProduct stores the PA-API data of a product whose page was blocked as pending, so its size
+// table is scraped later. Nothing is stored without a PA-API fallback.
+func (m *Manager) saveFallbackProduct(ctx context.Context, listing *scraper.Product) {
+	if m.scraper.Fallback() == nil {
+		return
+	}
+
+	product, err := m.scraper.FallbackProduct(ctx, listing)
 	if err != nil {
-		return fmt.Errorf("failed to link product to job: %w", err)
+		m.logger.WarnContext(ctx, "PA-API fallback failed", "asin", listing.ASIN, "error", err)
+		return
+	}
+
+	extractor := scraper.NewProductExtractor(m.scraper.GetBrowser(), m.logger)
+	dbProduct, err := extractor.ConvertToLifecycleProduct(product)
+	if err != nil {
+		m.logger.WarnContext(ctx, "failed to convert fallback product", "asin", listing.ASIN, "error", err)
+		return
+	}
+	inserted, err := m.db.InsertFallbackProduct(ctx, dbProduct)
+	if err != nil {
+		m.logger.WarnContext(ctx, "failed to save fallback product", "asin", listing.ASIN, "error", err)
+		return
+	}
+	if inserted {
+		m.logger.InfoContext(ctx, "stored blocked product from PA-API", "asin", listing.ASIN, "data_sources", product.DataSources)
+	}
+}
+
+// linkJobProducts links stored products of a page to the job with the job's metadata, replacing the
+// skips of an earlier attempt
+func (m *Manager) linkJobProducts(ctx context.Context, jobID string, asins []string, pageNumber int) error {
+	query := `
+		INSERT INTO job_products (job_id, asin, page_number, metadata)
+		SELECT $1, asin, $3, $4 FROM unnest($2::text[]) AS asin
+		ON CONFLICT (job_id, asin) DO UPDATE SET
+			page_number = EXCLUDED.page_number,
+			metadata = EXCLUDED.metadata,
+			skip_reason = NULL
+	`
+
+	if _, err := m.db.Exec(ctx, query, jobID, asins, pageNumber, database.Metadata(events.Metadata(ctx)).JSON()); err != nil {
+		return fmt.Errorf("failed to link products to job: %w", err)
 	}
-	
 	return nil
 }
 
+// recordSkip stores why the job did not save a product
+func (m *Manager) recordSkip(ctx context.Context, jobID, asin string, pageNumber int, reason string) {
+	query := `
+		INSERT INTO job_products (job_id, asin, page_number, skip_reason)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (job_id, asin) DO UPDATE SET
+			page_number = EXCLUDED.page_number,
+			skip_reason = EXCLUDED.skip_reason
+		WHERE job_products.skip_reason IS NOT NULL
+	`
+	if _, err := m.db.Exec(ctx, query, jobID, asin, pageNumber, reason); err != nil {
+		m.logger.ErrorContext(ctx, "failed to record skipped product", "asin", asin, "reason", reason, "error", err)
+	}
+}
+
 // publishEnhancedProductEvent publishes a NEW_PRODUCT_DETECTED event with complete data
 func (m *Manager) publishEnhancedProductEvent(ctx context.Context, product *scraper.CompleteProduct) error {
 	// Create enhanced event payload with all product data
@@ -243,15 +631,23 @@ func (m *Manager) publishEnhancedProductEvent(ctx context.Context, product *scra
 		Brand:          product.Brand,
 		DetailPageURL:  product.DetailPageURL,
 		Category:       product.Category,
-		Price:          convertPrice(product.CurrentPrice, product.Currency),
+		CategoryCode:   product.CategoryCode,
+		Price:          convertPrice(product),
 		Rating:         product.Rating,
 		ReviewCount:    product.ReviewCount,
 		Images:         product.ImageURLs,
 		Features:       product.Features,
+		Attributes:     product.Attributes,
 		AvailableSizes: product.AvailableSizes,
 		SizeTable:      product.SizeTable,
+		FitFeedback:    product.FitFeedback,
+		SizePrices:     product.SizePrices,
+		DataSources:    product.DataSources,
 		Source:         "scraper",
 	}
+	if n := len(product.BrowseNodes); n > 0 {
+		payload.BrowseNode = product.BrowseNodes[n-1]
+	}
 	
 	// Publish event
 	if err := m.publisher.PublishNewProductDetected(ctx, payload); err != nil {
@@ -262,14 +658,59 @@ func (m *Manager) publishEnhancedProductEvent(ctx context.Context, product *scra
 }
 
 // convertPrice converts price data to event format
-func convertPrice(amount *float64, currency string) *events.Price {
-	if amount == nil {
+func convertPrice(product *scraper.CompleteProduct) *events.Price {
+	if product.CurrentPrice == nil {
 		return nil
 	}
-	return &events.Price{
-		Amount:   *amount,
-		Currency: currency,
+	price := &events.Price{
+		Amount:   *product.CurrentPrice,
+		Currency: product.Currency,
+	}
+	if product.ReportingPrice != nil {
+		price.ReportingAmount = *product.ReportingPrice
+		price.ReportingCurrency = product.ReportingCurrency
+	}
+	return price
+}
+
+// applyReportingPrice converts the product price into the reporting currency if a converter is configured
+func (m *Manager) applyReportingPrice(ctx context.Context, product *scraper.CompleteProduct) {
+	if m.fx == nil || product.CurrentPrice == nil || product.Currency == "" {
+		return
+	}
+
+	converted, err := m.fx.Convert(ctx, *product.CurrentPrice, product.Currency)
+	if err != nil {
+		m.logger.DebugContext(ctx, "failed to convert price", "asin", product.ASIN, "currency", product.Currency, "error", err)
+		return
+	}
+	product.ReportingPrice = &converted
+	product.ReportingCurrency = m.fx.Reporting()
+}
+
+// registerFingerprint links the product to an existing duplicate and returns its canonical ASIN.
+// On errors the product is treated as canonical so it is never dropped.
+func (m *Manager) registerFingerprint(ctx context.Context, product *scraper.CompleteProduct) string {
+	fp := dedup.Compute(dedup.Product{
+		Brand:     product.Brand,
+		Title:     product.Title,
+		ImageURLs: product.ImageURLs,
+		SizeTable: product.SizeTable,
+	})
+
+	canonical, err := m.db.RegisterFingerprint(ctx, &database.ProductFingerprint{
+		ASIN:          product.ASIN,
+		Brand:         fp.Brand,
+		TitleKey:      fp.TitleKey,
+		ImageHash:     fp.ImageHash,
+		SizeSignature: fp.SizeSignature,
+		MatchKeys:     fp.MatchKeys(),
+	})
+	if err != nil {
+		m.logger.WarnContext(ctx, "failed to register fingerprint", "asin", product.ASIN, "error", err)
+		return product.ASIN
 	}
+	return canonical
 }
 
 // publishProductEvent publishes a NEW_PRODUCT_DETECTED event
@@ -0,0 +1,96 @@
+package jobs
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/scraper"
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/maltedev/amazon-size-scraper/internal/importer"
+)
+
+func TestValidateProductFollowsPolicy(t *testing.T) {
+	chestOnly := func() *scraper.CompleteProduct {
+		return &scraper.CompleteProduct{ASIN: "B0TEST0001", SizeTable: &database.SizeTable{
+			Sizes: []string{"M", "L"},
+			Measurements: map[string]map[string]float64{
+				"M": {"chest": 100},
+				"L": {"chest": 104},
+			},
+			Unit: "cm",
+		}}
+	}
+	svc := scraper.NewService(nil, nil, slog.Default())
+	m := &Manager{scraper: svc}
+
+	// Length and chest are required by default
+	if err := m.validateProduct(chestOnly()); !errors.Is(err, scraper.ErrInvalidSizeTable) {
+		t.Errorf("default policy: error = %v, want ErrInvalidSizeTable", err)
+	}
+
+	svc.SetMeasurementPolicy(database.MeasurementPolicy{RequireChest: true})
+	product := chestOnly()
+	if err := m.validateProduct(product); err != nil {
+		t.Fatalf("chest policy: unexpected error %v", err)
+	}
+	if !product.Validation.Valid || len(product.Validation.Issues) != 0 {
+		t.Errorf("chest policy: report = %+v, want valid without issues", product.Validation)
+	}
+
+	// A partial table the policy keeps is a warning on the report
+	svc.SetMeasurementPolicy(database.MeasurementPolicy{RequireLength: true, RequireChest: true, AcceptPartial: true})
+	product = chestOnly()
+	if err := m.validateProduct(product); err != nil {
+		t.Fatalf("partial policy: unexpected error %v", err)
+	}
+	if product.Validation.Score >= 1 {
+		t.Errorf("partial policy: score = %v, want lowered by the warning", product.Validation.Score)
+	}
+}
+
+func TestValidateProductChildrensChart(t *testing.T) {
+	svc := scraper.NewService(nil, nil, slog.Default())
+	m := &Manager{scraper: svc}
+
+	table := &importer.Table{
+		Headers: []string{"Körpergröße", "Länge (cm)", "Brustweite (cm)"},
+		Rows: [][]string{
+			{"92/98", "36", "29"},
+			{"104/110", "40", "31"},
+			{"116/122", "44", "33"},
+		},
+	}
+	st := svc.ParseSizeTable(table.Data())
+	if st == nil || st.System != "HEIGHT" {
+		t.Fatalf("parsed table = %+v, want a HEIGHT chart", st)
+	}
+
+	product := &scraper.CompleteProduct{ASIN: "B0TEST0002", SizeTable: st}
+	if err := m.validateProduct(product); err != nil {
+		t.Fatalf("unexpected error %v, report %+v", err, product.Validation)
+	}
+	if len(product.Validation.Issues) != 0 {
+		t.Errorf("issues = %+v, want none", product.Validation.Issues)
+	}
+}
+
+func TestValidateProductKeepsImplausibleCells(t *testing.T) {
+	m := &Manager{scraper: scraper.NewService(nil, nil, slog.Default())}
+
+	// A length typo and a repeated size lower the score, the product is kept
+	product := &scraper.CompleteProduct{ASIN: "B0TEST0003", SizeTable: &database.SizeTable{
+		Sizes: []string{"M", "L", "L"},
+		Measurements: map[string]map[string]float64{
+			"M": {"length": 7.2, "chest": 100},
+			"L": {"length": 74, "chest": 104},
+		},
+		Unit: "cm",
+	}}
+	if err := m.validateProduct(product); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if product.Validation.Valid || product.Validation.Score >= 1 {
+		t.Errorf("report = %+v, want errors lowering the score", product.Validation)
+	}
+}
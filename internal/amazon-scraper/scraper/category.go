@@ -5,7 +5,11 @@ import (
 	"fmt"
 	"log/slog"
 	"net/url"
-	"time"
+	"sync"
+
+	"github.com/maltedev/amazon-size-scraper/internal/browser"
+	"github.com/maltedev/amazon-size-scraper/internal/currency"
+	"github.com/playwright-community/playwright-go"
 )
 
 // Product represents a product found on a category page
@@ -15,80 +19,319 @@ type Product struct {
 	URL      string
 	Brand    string
 	Category string
+	Price    float64 // 0 if the search result shows no price
+	Currency string  // Detected from the price text, marketplace currency otherwise
+}
+
+// DefaultCrawlWorkers is how many search result pages are fetched in parallel
+const DefaultCrawlWorkers = 3
+
+// PageResult holds the products found on one search results page
+type PageResult struct {
+	Page     int
+	URL      string
+	Products []*Product
+	Err      error
 }
 
 // CategoryCrawler handles crawling of Amazon category/search pages
 type CategoryCrawler struct {
 	service *Service
+	workers int
 	logger  *slog.Logger
 }
 
 func NewCategoryCrawler(service *Service, logger *slog.Logger) *CategoryCrawler {
 	return &CategoryCrawler{
 		service: service,
+		workers: DefaultCrawlWorkers,
 		logger:  logger.With("component", "category_crawler"),
 	}
 }
 
+// SetWorkers sets how many result pages are fetched in parallel
+func (c *CategoryCrawler) SetWorkers(n int) {
+	if n < 1 {
+		n = 1
+	}
+	c.workers = n
+}
+
+// Crawl fetches the first results page, discovers the remaining pages from the pagination strip and
+// fetches them in parallel. Results are ordered by page index and products already found on an
+// earlier page are dropped from later ones, so the outcome does not depend on fetch order.
+func (c *CategoryCrawler) Crawl(ctx context.Context, searchURL string, maxPages int) ([]*PageResult, error) {
+	return c.CrawlFrom(ctx, searchURL, 1, maxPages)
+}
+
+// CrawlFrom is Crawl for a job resuming after its first fromPage-1 pages were processed. The first page is
+// still fetched to discover the pagination, only pages from fromPage on are returned.
+func (c *CategoryCrawler) CrawlFrom(ctx context.Context, searchURL string, fromPage, maxPages int) ([]*PageResult, error) {
+	var lastPage int
+	var hasNext bool
+	first := c.fetchPage(ctx, nil, searchURL, 1, true, func(page playwright.Page) {
+		var err error
+		if lastPage, err = c.lastPage(page); err != nil {
+			c.logger.WarnContext(ctx, "failed to read pagination", "error", err)
+		}
+		if hasNext, err = c.hasNextPage(page); err != nil {
+			c.logger.WarnContext(ctx, "failed to check for next page", "error", err)
+		}
+	})
+	if first.Err != nil {
+		return nil, first.Err
+	}
+
+	results := []*PageResult{first}
+	switch {
+	case maxPages <= 1:
+	case lastPage > 1:
+		// Pages are known up front, fetch them from a shared frontier
+		results = append(results, make([]*PageResult, min(lastPage, maxPages)-1)...)
+		c.crawlFrontier(ctx, searchURL, results, fromPage)
+	case hasNext:
+		// No page numbers in the strip, follow the next links one by one
+		for n := 2; n <= maxPages && hasNext && ctx.Err() == nil; n++ {
+			hasNext = false
+			result := c.fetchPage(ctx, nil, searchURL, n, false, func(page playwright.Page) {
+				hasNext, _ = c.hasNextPage(page)
+			})
+			results = append(results, result)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return resultsFrom(mergeResults(results), fromPage), nil
+}
+
+// CrawlUntil fetches the result pages from fromPage one after another and stops after the page stop
+// returns true for, e.g. a delta crawl once a page lists mostly known products. Products already found
+// on an earlier page are dropped like in Crawl, stop sees the page before that.
+func (c *CategoryCrawler) CrawlUntil(ctx context.Context, searchURL string, fromPage, maxPages int, stop func(*PageResult) bool) ([]*PageResult, error) {
+	var results []*PageResult
+	for n := max(fromPage, 1); n <= maxPages; n++ {
+		hasNext := true
+		result := c.fetchPage(ctx, nil, searchURL, n, n == max(fromPage, 1), func(page playwright.Page) {
+			var err error
+			if hasNext, err = c.hasNextPage(page); err != nil {
+				c.logger.WarnContext(ctx, "failed to check for next page", "error", err)
+			}
+		})
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if result.Err != nil && len(results) == 0 {
+			return nil, result.Err
+		}
+		results = append(results, result)
+		if result.Err == nil && (!hasNext || stop(result)) {
+			break
+		}
+	}
+	return mergeResults(results), nil
+}
+
 // CrawlPage crawls a single page of search results
 func (c *CategoryCrawler) CrawlPage(ctx context.Context, searchURL string, pageNumber int) ([]*Product, bool, error) {
-	// Add page parameter if not first page
-	if pageNumber > 1 {
-		parsedURL, err := url.Parse(searchURL)
-		if err != nil {
-			return nil, false, fmt.Errorf("failed to parse URL: %w", err)
+	var hasNext bool
+	result := c.fetchPage(ctx, nil, searchURL, pageNumber, pageNumber == 1, func(page playwright.Page) {
+		var err error
+		if hasNext, err = c.hasNextPage(page); err != nil {
+			c.logger.WarnContext(ctx, "failed to check for next page", "error", err)
 		}
-		
-		q := parsedURL.Query()
-		q.Set("page", fmt.Sprintf("%d", pageNumber))
-		parsedURL.RawQuery = q.Encode()
-		searchURL = parsedURL.String()
+	})
+	if result.Err != nil {
+		return nil, false, result.Err
+	}
+
+	c.logger.InfoContext(ctx, "extracted products", "count", len(result.Products), "hasNext", hasNext)
+	return result.Products, hasNext, nil
+}
+
+// crawlFrontier fills results[fromPage-1:], but never the first page, using a bounded set of workers,
+// each in its own browser context
+func (c *CategoryCrawler) crawlFrontier(ctx context.Context, searchURL string, results []*PageResult, fromPage int) {
+	frontier := make(chan int, len(results)-1)
+	for n := max(fromPage, 2); n <= len(results); n++ {
+		frontier <- n
+	}
+	close(frontier)
+
+	var wg sync.WaitGroup
+	for w := 0; w < min(c.workers, len(frontier)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.runWorker(ctx, searchURL, frontier, results)
+		}()
+	}
+	wg.Wait()
+}
+
+// runWorker fetches pages from the frontier until it is empty, every page index is written by exactly one worker
+func (c *CategoryCrawler) runWorker(ctx context.Context, searchURL string, frontier <-chan int, results []*PageResult) {
+	bctx, err := c.service.browser.NewContext()
+	if err != nil {
+		c.logger.WarnContext(ctx, "failed to create worker context, using shared context", "error", err)
+		bctx = nil
+	} else {
+		defer bctx.Close()
+	}
+
+	// A fresh context has no cookies yet, so its first page passes the homepage bot check
+	warm := bctx != nil
+	for n := range frontier {
+		if err := ctx.Err(); err != nil {
+			results[n-1] = &PageResult{Page: n, Err: err}
+			continue
+		}
+		results[n-1] = c.fetchPage(ctx, bctx, searchURL, n, warm, nil)
+		warm = false
+	}
+}
+
+// fetchPage loads one results page in the browser context (nil for the shared one) and extracts its products,
+// inspect runs on the loaded page before it is closed
+func (c *CategoryCrawler) fetchPage(ctx context.Context, bctx playwright.BrowserContext, searchURL string, pageNumber int, warm bool, inspect func(playwright.Page)) *PageResult {
+	result := &PageResult{Page: pageNumber}
+
+	target, err := pageURL(searchURL, pageNumber)
+	if err != nil {
+		result.Err = err
+		return result
 	}
+	result.URL = target
 
-	c.logger.Info("crawling page", "url", searchURL, "page", pageNumber)
+	// The homepage warm-up is a separate fetch
+	fetches := int64(1)
+	if warm {
+		fetches++
+	}
+	if err := c.service.ConsumeQuota(ctx, fetches); err != nil {
+		result.Err = err
+		return result
+	}
+
+	if err := c.service.WaitRateLimit(ctx, target); err != nil {
+		result.Err = err
+		return result
+	}
+
+	c.logger.InfoContext(ctx, "crawling page", "url", target, "page", pageNumber)
 
-	page, err := c.service.browser.NewPage()
+	page, err := c.service.browser.NewTaskPageIn(bctx, browser.TaskSearch)
 	if err != nil {
-		return nil, false, fmt.Errorf("failed to create page: %w", err)
+		result.Err = fmt.Errorf("failed to create page: %w", err)
+		return result
 	}
 	defer page.Close()
 
-	// First navigate to Amazon.de to handle bot check
-	if pageNumber == 1 {
-		if err := c.service.browser.NavigateWithRetry(page, "https://www.amazon.de", 1); err != nil {
-			c.logger.Warn("failed to navigate to homepage", "error", err)
+	// Visit the homepage first to handle the bot check
+	if warm {
+		if err := c.service.browser.NavigateWithRetry(page, browser.HomepageOf(target), 1); err != nil {
+			c.logger.WarnContext(ctx, "failed to navigate to homepage", "error", err)
 		}
-		time.Sleep(2 * time.Second)
 	}
 
-	// Navigate to search page
-	if err := c.service.browser.NavigateWithRetry(page, searchURL, 3); err != nil {
-		return nil, false, fmt.Errorf("failed to navigate to search page: %w", err)
+	if err := c.service.browser.NavigateWithRetry(page, target, 3); err != nil {
+		result.Err = fmt.Errorf("failed to navigate to search page: %w", err)
+		return result
 	}
 
-	// Wait for products to load
-	time.Sleep(2 * time.Second)
+	// Wait for products to load, an empty results page simply times out
+	page.WaitForSelector(`[data-component-type="s-search-result"]`, playwright.PageWaitForSelectorOptions{
+		Timeout: playwright.Float(5000),
+	})
 
-	// Extract products
-	products, err := c.extractProducts(page)
+	products, err := c.extractProducts(page, browser.HomepageOf(target))
 	if err != nil {
-		return nil, false, fmt.Errorf("failed to extract products: %w", err)
+		result.Err = fmt.Errorf("failed to extract products: %w", err)
+		return result
 	}
+	result.Products = products
 
-	// Check if there's a next page
-	hasNext, err := c.hasNextPage(page)
+	if inspect != nil {
+		inspect(page)
+	}
+	return result
+}
+
+// lastPage returns the highest page number shown in the pagination strip, 0 if none is shown
+func (c *CategoryCrawler) lastPage(page playwright.Page) (int, error) {
+	result, err := page.Evaluate(`() => {
+		let last = 0;
+		document.querySelectorAll('.s-pagination-item').forEach(el => {
+			const n = parseInt(el.textContent.trim(), 10);
+			if (!isNaN(n) && n > last) last = n;
+		});
+		return last;
+	}`)
+	if err != nil {
+		return 0, err
+	}
+
+	switch n := result.(type) {
+	case int:
+		return n, nil
+	case float64:
+		return int(n), nil
+	}
+	return 0, fmt.Errorf("unexpected result type from evaluate")
+}
+
+// pageURL sets the page parameter for all but the first page
+func pageURL(searchURL string, pageNumber int) (string, error) {
+	if pageNumber <= 1 {
+		return searchURL, nil
+	}
+
+	parsedURL, err := url.Parse(searchURL)
 	if err != nil {
-		c.logger.Warn("failed to check for next page", "error", err)
-		hasNext = false
+		return "", fmt.Errorf("failed to parse URL: %w", err)
 	}
 
-	c.logger.Info("extracted products", "count", len(products), "hasNext", hasNext)
+	q := parsedURL.Query()
+	q.Set("page", fmt.Sprintf("%d", pageNumber))
+	parsedURL.RawQuery = q.Encode()
+	return parsedURL.String(), nil
+}
 
-	return products, hasNext, nil
+// mergeResults drops products already found on an earlier page, results must be ordered by page
+func mergeResults(results []*PageResult) []*PageResult {
+	seen := make(map[string]bool)
+	for _, result := range results {
+		if result == nil || result.Err != nil {
+			continue
+		}
+
+		products := result.Products[:0]
+		for _, p := range result.Products {
+			if seen[p.ASIN] {
+				continue
+			}
+			seen[p.ASIN] = true
+			products = append(products, p)
+		}
+		result.Products = products
+	}
+	return results
+}
+
+// resultsFrom drops the pages before fromPage and those that were not fetched
+func resultsFrom(results []*PageResult, fromPage int) []*PageResult {
+	kept := results[:0]
+	for _, result := range results {
+		if result != nil && result.Page >= fromPage {
+			kept = append(kept, result)
+		}
+	}
+	return kept
 }
 
 // extractProducts extracts product information from the page
-func (c *CategoryCrawler) extractProducts(page interface{}) ([]*Product, error) {
+func (c *CategoryCrawler) extractProducts(page interface{}, baseURL string) ([]*Product, error) {
 	// Import playwright
 	pwPage, ok := page.(interface {
 		Evaluate(expression string, options ...interface{}) (interface{}, error)
@@ -108,11 +351,13 @@ func (c *CategoryCrawler) extractProducts(page interface{}) ([]*Product, error)
 			
 			const titleEl = el.querySelector('h2 a span');
 			const brandEl = el.querySelector('span.s-size-override-12');
+			const priceEl = el.querySelector('.a-price .a-offscreen');
 			
 			products.push({
 				asin: asin,
 				title: titleEl ? titleEl.textContent.trim() : '',
-				brand: brandEl ? brandEl.textContent.trim() : ''
+				brand: brandEl ? brandEl.textContent.trim() : '',
+				price: priceEl ? priceEl.textContent.trim() : ''
 			});
 		});
 		
@@ -143,12 +388,16 @@ func (c *CategoryCrawler) extractProducts(page interface{}) ([]*Product, error)
 
 		title, _ := productMap["title"].(string)
 		brand, _ := productMap["brand"].(string)
+		priceText, _ := productMap["price"].(string)
+		price, cur := currency.Parse(priceText, currency.ForMarketplace(baseURL))
 
 		products = append(products, &Product{
-			ASIN:  asin,
-			Title: title,
-			Brand: brand,
-			URL:   fmt.Sprintf("https://www.amazon.de/dp/%s", asin),
+			ASIN:     asin,
+			Title:    title,
+			Brand:    brand,
+			URL:      fmt.Sprintf("%s/dp/%s", baseURL, asin),
+			Price:    price,
+			Currency: cur,
 		})
 	}
 
@@ -171,8 +420,7 @@ func (c *CategoryCrawler) hasNextPage(page interface{}) (bool, error) {
 		// Check for pagination next button
 		const nextButton = document.querySelector('.s-pagination-next:not(.s-pagination-disabled)');
 		// Also check for "Weiter" text link
-		const weiterLink = document.querySelector('a:contains("Weiter")') || 
-			Array.from(document.querySelectorAll('a')).find(a => a.textContent.includes('Weiter'));
+		const weiterLink = Array.from(document.querySelectorAll('a')).find(a => a.textContent.includes('Weiter'));
 		return (nextButton !== null) || (weiterLink !== null);
 	}`)
 	
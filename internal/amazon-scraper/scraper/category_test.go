@@ -0,0 +1,74 @@
+package scraper
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/maltedev/amazon-size-scraper/internal/amazontest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCategoryCrawler_Crawl(t *testing.T) {
+	server := amazontest.NewServer()
+	defer server.Close()
+	server.SetPageSize(1)
+	b := amazontest.NewBrowser(t)
+
+	crawler := NewCategoryCrawler(NewService(b, nil, slog.Default()), slog.Default())
+	crawler.SetWorkers(2)
+
+	results, err := crawler.Crawl(context.Background(), server.SearchURL("herren"), 10)
+	require.NoError(t, err)
+	require.Len(t, results, len(amazontest.DefaultProducts()))
+
+	for i, result := range results {
+		assert.Equal(t, i+1, result.Page)
+		require.NoError(t, result.Err)
+		require.Len(t, result.Products, 1)
+		assert.Equal(t, amazontest.DefaultProducts()[i].ASIN, result.Products[0].ASIN)
+	}
+
+	// maxPages caps the discovered pages
+	results, err = crawler.Crawl(context.Background(), server.SearchURL("herren"), 2)
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	// A resumed crawl only returns the pages after the checkpoint
+	results, err = crawler.CrawlFrom(context.Background(), server.SearchURL("herren"), 3, 10)
+	require.NoError(t, err)
+	require.Len(t, results, len(amazontest.DefaultProducts())-2)
+	assert.Equal(t, 3, results[0].Page)
+}
+
+func TestPageURL(t *testing.T) {
+	got, err := pageURL("https://www.amazon.de/s?k=herren", 1)
+	require.NoError(t, err)
+	assert.Equal(t, "https://www.amazon.de/s?k=herren", got)
+
+	got, err = pageURL("https://www.amazon.de/s?k=herren&page=1", 3)
+	require.NoError(t, err)
+	assert.Equal(t, "https://www.amazon.de/s?k=herren&page=3", got)
+}
+
+func TestMergeResults(t *testing.T) {
+	results := mergeResults([]*PageResult{
+		{Page: 1, Products: []*Product{{ASIN: "A"}, {ASIN: "B"}}},
+		{Page: 2, Err: context.Canceled},
+		{Page: 3, Products: []*Product{{ASIN: "B"}, {ASIN: "C"}}},
+	})
+
+	assert.Len(t, results, 3)
+	assert.Len(t, results[0].Products, 2)
+	require.Len(t, results[2].Products, 1)
+	assert.Equal(t, "C", results[2].Products[0].ASIN)
+}
+
+func TestResultsFrom(t *testing.T) {
+	results := resultsFrom([]*PageResult{{Page: 1}, nil, {Page: 3}, {Page: 4}}, 3)
+
+	require.Len(t, results, 2)
+	assert.Equal(t, 3, results[0].Page)
+	assert.Equal(t, 4, results[1].Page)
+}
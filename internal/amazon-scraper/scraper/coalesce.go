@@ -0,0 +1,46 @@
+package scraper
+
+import (
+	"context"
+
+	"github.com/maltedev/amazon-size-scraper/internal/asin"
+)
+
+// sizeChartKey identifies the product of a size chart extraction by marketplace and ASIN, extractions
+// of a URL without ASIN by the URL
+func sizeChartKey(id, url string) string {
+	if id == "" {
+		return url
+	}
+	marketplace := asin.DefaultMarketplace
+	if p, ok := asin.Parse(url, asin.DefaultMarketplace); ok {
+		marketplace = p.Marketplace
+	}
+	return asin.Product{ASIN: id, Marketplace: marketplace}.String()
+}
+
+// coalesceSizeChart runs extract once for concurrent calls with the same key and hands every caller its
+// result. The extraction keeps the deadline but not the cancellation of the call that started it, so one
+// caller giving up does not fail the others; each caller still returns as soon as its own context is done.
+func (s *Service) coalesceSizeChart(ctx context.Context, key string, extract func(context.Context) (*Dimensions, error)) (*Dimensions, error) {
+	ch := s.sizeCharts.DoChan(key, func() (any, error) {
+		detached := context.WithoutCancel(ctx)
+		if deadline, ok := ctx.Deadline(); ok {
+			var cancel context.CancelFunc
+			detached, cancel = context.WithDeadline(detached, deadline)
+			defer cancel()
+		}
+		return extract(detached)
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Shared {
+			s.logger.DebugContext(ctx, "size chart extraction shared with concurrent requests", "key", key)
+		}
+		dimensions, _ := res.Val.(*Dimensions)
+		return dimensions, res.Err
+	}
+}
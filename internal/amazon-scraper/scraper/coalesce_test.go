@@ -0,0 +1,91 @@
+package scraper
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSizeChartKey(t *testing.T) {
+	tests := []struct {
+		id, url, want string
+	}{
+		{"B08N5WRWNW", "", "amazon.de/B08N5WRWNW"},
+		{"B08N5WRWNW", "https://www.amazon.co.uk/dp/B08N5WRWNW", "amazon.co.uk/B08N5WRWNW"},
+		{"", "https://www.amazon.de/some-link", "https://www.amazon.de/some-link"},
+	}
+	for _, tt := range tests {
+		if got := sizeChartKey(tt.id, tt.url); got != tt.want {
+			t.Errorf("sizeChartKey(%q, %q) = %q, want %q", tt.id, tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestService_CoalesceSizeChart(t *testing.T) {
+	s := &Service{logger: slog.Default()}
+	release := make(chan struct{})
+	var runs atomic.Int32
+	extract := func(ctx context.Context) (*Dimensions, error) {
+		runs.Add(1)
+		<-release
+		return &Dimensions{Found: true}, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]*Dimensions, 3)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = s.coalesceSizeChart(context.Background(), "amazon.de/B08N5WRWNW", extract)
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := runs.Load(); n != 1 {
+		t.Errorf("extract ran %d times, want 1", n)
+	}
+	for i, d := range results {
+		if d == nil || !d.Found {
+			t.Errorf("caller %d got %+v", i, d)
+		}
+	}
+}
+
+func TestService_CoalesceSizeChart_CallerCanceled(t *testing.T) {
+	s := &Service{logger: slog.Default()}
+	release := make(chan struct{})
+	extract := func(ctx context.Context) (*Dimensions, error) {
+		<-release
+		return &Dimensions{Found: true}, ctx.Err()
+	}
+
+	// The first caller gives up, the one that joined still gets the result
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := s.coalesceSizeChart(ctx, "amazon.de/B08N5WRWNW", extract)
+		first <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	second := make(chan *Dimensions, 1)
+	go func() {
+		d, _ := s.coalesceSizeChart(context.Background(), "amazon.de/B08N5WRWNW", extract)
+		second <- d
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	cancel()
+	if err := <-first; err != context.Canceled {
+		t.Errorf("first caller error = %v, want context.Canceled", err)
+	}
+	close(release)
+	if d := <-second; d == nil || !d.Found {
+		t.Errorf("second caller got %+v", d)
+	}
+}
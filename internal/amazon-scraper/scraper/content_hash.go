@@ -0,0 +1,43 @@
+package scraper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ContentHash hashes the parts of a product page a re-scrape compares: title, price and size table.
+// Formatting differences like whitespace, letter case or measurement order do not change the hash.
+func (cp *CompleteProduct) ContentHash() string {
+	var b strings.Builder
+
+	b.WriteString(strings.ToLower(strings.Join(strings.Fields(cp.Title), " ")))
+	b.WriteByte('\n')
+
+	if cp.CurrentPrice != nil {
+		fmt.Fprintf(&b, "%.2f %s", *cp.CurrentPrice, strings.ToUpper(cp.Currency))
+	}
+	b.WriteByte('\n')
+
+	if t := cp.SizeTable; t != nil {
+		b.WriteString(strings.ToLower(t.Unit))
+		for _, size := range t.Sizes {
+			fmt.Fprintf(&b, "\n%s:", strings.ToUpper(strings.TrimSpace(size)))
+
+			measurements := t.Measurements[size]
+			names := make([]string, 0, len(measurements))
+			for name := range measurements {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				fmt.Fprintf(&b, " %s=%.1f", strings.ToLower(name), measurements[name])
+			}
+		}
+	}
+
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
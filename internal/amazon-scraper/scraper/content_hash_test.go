@@ -0,0 +1,49 @@
+package scraper
+
+import (
+	"testing"
+
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContentHash(t *testing.T) {
+	price := 24.99
+	newProduct := func() *CompleteProduct {
+		return &CompleteProduct{
+			ASIN:         "B0TEST0001",
+			Title:        "Tall T-Shirt Herren extra lang",
+			CurrentPrice: &price,
+			Currency:     "EUR",
+			SizeTable: &database.SizeTable{
+				Sizes: []string{"M", "L"},
+				Measurements: map[string]map[string]float64{
+					"M": {"length": 78, "chest": 102},
+					"L": {"length": 80, "chest": 108},
+				},
+				Unit: "cm",
+			},
+		}
+	}
+
+	base := newProduct().ContentHash()
+	assert.Len(t, base, 64)
+
+	same := newProduct()
+	same.Title = "  Tall T-Shirt   Herren extra lang "
+	same.Features = []string{"Not part of the hash"}
+	assert.Equal(t, base, same.ContentHash(), "formatting and other fields must not change the hash")
+
+	cheaper := newProduct()
+	lower := 19.99
+	cheaper.CurrentPrice = &lower
+	assert.NotEqual(t, base, cheaper.ContentHash())
+
+	longer := newProduct()
+	longer.SizeTable.Measurements["L"]["length"] = 82
+	assert.NotEqual(t, base, longer.ContentHash())
+
+	noPrice := newProduct()
+	noPrice.CurrentPrice = nil
+	assert.NotEqual(t, base, noPrice.ContentHash())
+}
@@ -0,0 +1,138 @@
+package scraper
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/maltedev/amazon-size-scraper/internal/browser"
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/playwright-community/playwright-go"
+)
+
+// sizeOrder is the order of the letter sizes recognized in description text
+var sizeOrder = []string{"XS", "S", "M", "L", "XL", "XXL", "XXXL", "3XL", "4XL", "5XL", "6XL"}
+
+var (
+	// textSize matches a letter size after a size keyword ("Größe M", "Gr. L", "size XL") or standing alone
+	// in upper case, so lower case "m" or "s" in prose is not taken for a size. \b only knows ASCII letters,
+	// see findSizes.
+	textSize = regexp.MustCompile(`(?i:(?:größe|groesse|gr\.|size|taglia|talla)\s*(xs|s|m|l|xl|xxl|xxxl|[3-6]xl)\b)|\b(XS|S|M|L|XL|XXL|XXXL|[3-6]XL)\b`)
+	// textMeasurement matches a value or range in centimeters or millimeters, e.g. "74 cm", "52,5cm", "70-72 cm"
+	textMeasurement = regexp.MustCompile(`(\d+(?:[.,]\d+)?)(?:\s*[-–]\s*(\d+(?:[.,]\d+)?))?\s*(cm|mm)\b`)
+)
+
+// extractDescriptionSizeTable assembles a partial size table from measurements mentioned in the bullet
+// points and description, the last resort when the page has no size table
+func (s *Service) extractDescriptionSizeTable(page playwright.Page, asin string) *database.SizeTable {
+	text, err := browser.ProductDescriptionText(page)
+	if err != nil {
+		s.logger.Warn("failed to read description text", "asin", asin, "error", err)
+		return nil
+	}
+
+	sizeTable := s.parseDescriptionSizeTable(text)
+	if sizeTable == nil {
+		return nil
+	}
+	s.logger.Info("derived size table from description text", "asin", asin, "sizeCount", len(sizeTable.Sizes))
+	return sizeTable
+}
+
+// parseDescriptionSizeTable reads per-size measurements from lines like "Länge bei Größe M: 74 cm",
+// "Größe L: Länge 76 cm, Brustumfang 56 cm" or "Länge: S 70 cm, M 72 cm". Each value is assigned to the
+// last measurement label and size mentioned before it on its line; a line naming a single size after its
+// values applies it to all of them. Returns nil when no value has both.
+func (s *Service) parseDescriptionSizeTable(text string) *database.SizeTable {
+	sizeTable := &database.SizeTable{
+		Measurements: make(map[string]map[string]float64),
+		Unit:         "cm",
+		Source:       database.SizeTableSourceDescriptionText,
+		Confidence:   database.DescriptionTextConfidence,
+	}
+
+	for _, line := range strings.FieldsFunc(text, func(r rune) bool { return r == '\n' || r == '•' || r == ';' }) {
+		var label, size string
+		lineSize := onlySize(line)
+		prev := 0
+		for _, m := range textMeasurement.FindAllStringSubmatchIndex(line, -1) {
+			before := line[prev:m[0]]
+			prev = m[1]
+
+			if key, ok := s.labelDictionary().Lookup(before); ok {
+				label = key
+			}
+			if sizes := findSizes(before); len(sizes) > 0 {
+				size = sizes[len(sizes)-1]
+			}
+			if size == "" {
+				size = lineSize
+			}
+			if label == "" || size == "" {
+				continue
+			}
+
+			value := parseValue(line[m[2]:m[3]])
+			// Ranges count with their upper bound, like in size tables
+			if m[4] >= 0 {
+				value = parseValue(line[m[4]:m[5]])
+			}
+			if line[m[6]:m[7]] == "mm" {
+				value /= 10
+			}
+			if value <= 0 {
+				continue
+			}
+
+			if sizeTable.Measurements[size] == nil {
+				sizeTable.Measurements[size] = make(map[string]float64)
+			}
+			sizeTable.Measurements[size][label] = value
+		}
+	}
+
+	for _, size := range sizeOrder {
+		if _, ok := sizeTable.Measurements[size]; ok {
+			sizeTable.Sizes = append(sizeTable.Sizes, size)
+		}
+	}
+	if len(sizeTable.Sizes) == 0 {
+		return nil
+	}
+	return sizeTable
+}
+
+// onlySize returns the size a line names, empty if it names none or several
+func onlySize(line string) string {
+	var sizes []string
+	for _, size := range findSizes(line) {
+		if !slices.Contains(sizes, size) {
+			sizes = append(sizes, size)
+		}
+	}
+	if len(sizes) != 1 {
+		return ""
+	}
+	return sizes[0]
+}
+
+// findSizes returns the sizes mentioned in text in order. Matches next to a non-ASCII letter are part of a
+// word, e.g. the L of "Länge".
+func findSizes(text string) []string {
+	var sizes []string
+	for _, m := range textSize.FindAllStringSubmatchIndex(text, -1) {
+		before, _ := utf8.DecodeLastRuneInString(text[:m[0]])
+		after, _ := utf8.DecodeRuneInString(text[m[1]:])
+		if unicode.IsLetter(before) || unicode.IsLetter(after) {
+			continue
+		}
+		if m[2] >= 0 {
+			sizes = append(sizes, strings.ToUpper(text[m[2]:m[3]]))
+		} else {
+			sizes = append(sizes, text[m[4]:m[5]])
+		}
+	}
+	return sizes
+}
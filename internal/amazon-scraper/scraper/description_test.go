@@ -0,0 +1,80 @@
+package scraper
+
+import (
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/maltedev/amazon-size-scraper/internal/labels"
+)
+
+func TestParseDescriptionSizeTable(t *testing.T) {
+	s := &Service{logger: slog.Default()}
+
+	tests := []struct {
+		name  string
+		text  string
+		sizes []string
+		want  map[string]map[string]float64
+	}{
+		{
+			name:  "size per line",
+			text:  "Länge bei Größe M: 74 cm\nBrustumfang bei Gr. L: 56,5 cm",
+			sizes: []string{"M", "L"},
+			want: map[string]map[string]float64{
+				"M": {labels.Length: 74},
+				"L": {labels.Chest: 56.5},
+			},
+		},
+		{
+			name:  "several measurements of one size",
+			text:  "• Größe M: Länge 74 cm, Brustumfang 52 cm • Das Model ist 185 cm groß",
+			sizes: []string{"M"},
+			want:  map[string]map[string]float64{"M": {labels.Length: 74, labels.Chest: 52}},
+		},
+		{
+			name:  "several sizes of one measurement",
+			text:  "Länge: S 70 cm, M 72 cm, L 740 mm",
+			sizes: []string{"S", "M", "L"},
+			want: map[string]map[string]float64{
+				"S": {labels.Length: 70},
+				"M": {labels.Length: 72},
+				"L": {labels.Length: 74},
+			},
+		},
+		{
+			name:  "size after the value",
+			text:  "Rückenlänge 70-72 cm in Größe XL",
+			sizes: []string{"XL"},
+			want:  map[string]map[string]float64{"XL": {labels.Length: 72}},
+		},
+		{
+			name: "no size",
+			text: "Länge ca. 74 cm, Baumwolle, Maschinenwäsche bei 40 Grad",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := s.parseDescriptionSizeTable(tt.text)
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("expected no size table, got %+v", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("expected a size table")
+			}
+			if !reflect.DeepEqual(got.Sizes, tt.sizes) {
+				t.Errorf("Sizes = %v, want %v", got.Sizes, tt.sizes)
+			}
+			if !reflect.DeepEqual(got.Measurements, tt.want) {
+				t.Errorf("Measurements = %v, want %v", got.Measurements, tt.want)
+			}
+			if got.Source != database.SizeTableSourceDescriptionText || got.Confidence != database.DescriptionTextConfidence {
+				t.Errorf("Source = %q, Confidence = %v", got.Source, got.Confidence)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/asin"
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/maltedev/amazon-size-scraper/internal/paapi"
+	"github.com/maltedev/amazon-size-scraper/internal/scrapeerr"
+)
+
+// Data sources of CompleteProduct.DataSources
+const (
+	SourceScraper = "scraper"
+	SourcePAAPI   = "pa-api"
+)
+
+// Fields of CompleteProduct.DataSources, the basic data PA-API can provide
+const (
+	FieldTitle  = "title"
+	FieldBrand  = "brand"
+	FieldPrice  = "price"
+	FieldImages = "images"
+)
+
+// SetFallback sets the PA-API client that provides basic product data the scraper could not get, nil
+// disables the fallback
+func (s *Service) SetFallback(c *paapi.Client) {
+	s.fallback = c
+}
+
+// Fallback returns the PA-API client, nil if the fallback is disabled
+func (s *Service) Fallback() *paapi.Client {
+	return s.fallback
+}
+
+// LookupFallback returns the basic data of a product from PA-API
+func (s *Service) LookupFallback(ctx context.Context, id, marketplace string) (*paapi.Item, error) {
+	if s.fallback == nil {
+		return nil, fmt.Errorf("PA-API fallback is disabled")
+	}
+	if marketplace == "" {
+		marketplace = asin.DefaultMarketplace
+	}
+	return s.fallback.GetItem(ctx, marketplace, id)
+}
+
+// FillFromFallback completes the title, brand, price and images of a scraped product from PA-API and
+// records the source of each field. Products with all of them scraped are not looked up.
+func (s *Service) FillFromFallback(ctx context.Context, product *CompleteProduct) {
+	product.markScraped()
+	if s.fallback == nil || len(product.missingFields()) == 0 {
+		return
+	}
+
+	marketplace := asin.DefaultMarketplace
+	if p, ok := asin.Parse(product.DetailPageURL, asin.DefaultMarketplace); ok {
+		marketplace = p.Marketplace
+	}
+	item, err := s.LookupFallback(ctx, product.ASIN, marketplace)
+	if err != nil {
+		s.logger.DebugContext(ctx, "PA-API fallback failed", "asin", product.ASIN, "error", err)
+		return
+	}
+	if filled := product.MergeItem(item); len(filled) > 0 {
+		s.logger.InfoContext(ctx, "filled product data from PA-API", "asin", product.ASIN, "fields", filled)
+	}
+}
+
+// Blocked reports whether an extraction failed because Amazon kept the scraper from the product page
+func Blocked(err error) bool {
+	return errors.Is(err, scrapeerr.ErrBlocked)
+}
+
+// FallbackProduct builds a product without size table from a search result whose page was blocked,
+// completed from PA-API
+func (s *Service) FallbackProduct(ctx context.Context, listing *Product) (*CompleteProduct, error) {
+	product := &CompleteProduct{
+		ASIN:          listing.ASIN,
+		Title:         listing.Title,
+		Brand:         listing.Brand,
+		DetailPageURL: listing.URL,
+		Category:      listing.Category,
+	}
+	if listing.Price > 0 {
+		price := listing.Price
+		product.CurrentPrice, product.Currency = &price, listing.Currency
+		product.Provenance = database.Provenance{}
+		product.Provenance.Record(database.FieldPrice, database.ProvenancePage, "search_result", time.Now())
+	}
+	product.markScraped()
+
+	marketplace := asin.DefaultMarketplace
+	if p, ok := asin.Parse(listing.URL, asin.DefaultMarketplace); ok {
+		marketplace = p.Marketplace
+	}
+	item, err := s.LookupFallback(ctx, listing.ASIN, marketplace)
+	if err != nil {
+		return nil, err
+	}
+	product.MergeItem(item)
+	return product, nil
+}
+
+// MergeItem fills the fields the scraper left empty from a PA-API item and returns the filled fields.
+// Scraped values always win, their source is recorded as SourceScraper.
+func (p *CompleteProduct) MergeItem(item *paapi.Item) []string {
+	p.markScraped()
+	if item == nil {
+		return nil
+	}
+
+	var filled []string
+	for _, field := range p.missingFields() {
+		switch field {
+		case FieldTitle:
+			if item.Title == "" {
+				continue
+			}
+			p.Title = item.Title
+		case FieldBrand:
+			if item.Brand == "" {
+				continue
+			}
+			p.Brand = item.Brand
+		case FieldPrice:
+			if item.Price == nil {
+				continue
+			}
+			price := *item.Price
+			p.CurrentPrice, p.Currency = &price, item.Currency
+			if p.Provenance == nil {
+				p.Provenance = make(database.Provenance)
+			}
+			p.Provenance.Record(database.FieldPrice, database.ProvenancePAAPI, "", time.Now())
+		case FieldImages:
+			if len(item.Images) == 0 {
+				continue
+			}
+			p.ImageURLs = item.Images
+		}
+		p.DataSources[field] = SourcePAAPI
+		filled = append(filled, field)
+	}
+	if p.DetailPageURL == "" {
+		p.DetailPageURL = item.DetailPageURL
+	}
+	return filled
+}
+
+// missingFields returns the basic fields without a value
+func (p *CompleteProduct) missingFields() []string {
+	var missing []string
+	if p.Title == "" {
+		missing = append(missing, FieldTitle)
+	}
+	if p.Brand == "" {
+		missing = append(missing, FieldBrand)
+	}
+	if p.CurrentPrice == nil {
+		missing = append(missing, FieldPrice)
+	}
+	if len(p.ImageURLs) == 0 {
+		missing = append(missing, FieldImages)
+	}
+	return missing
+}
+
+// markScraped records SourceScraper for basic fields that have a value and no source yet
+func (p *CompleteProduct) markScraped() {
+	if p.DataSources == nil {
+		p.DataSources = make(map[string]string)
+	}
+	set := map[string]bool{
+		FieldTitle:  p.Title != "",
+		FieldBrand:  p.Brand != "",
+		FieldPrice:  p.CurrentPrice != nil,
+		FieldImages: len(p.ImageURLs) > 0,
+	}
+	for field, ok := range set {
+		if _, known := p.DataSources[field]; ok && !known {
+			p.DataSources[field] = SourceScraper
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/browser"
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/maltedev/amazon-size-scraper/internal/paapi"
+)
+
+func TestMergeItem(t *testing.T) {
+	scrapedPrice, apiPrice := 19.99, 24.99
+	product := &CompleteProduct{ASIN: "B08N5WRWNW", Title: "Scraped title", CurrentPrice: &scrapedPrice, Currency: "EUR"}
+	item := &paapi.Item{
+		ASIN:     "B08N5WRWNW",
+		Title:    "API title",
+		Brand:    "Tall Co",
+		Price:    &apiPrice,
+		Currency: "EUR",
+	}
+
+	filled := product.MergeItem(item)
+	if !reflect.DeepEqual(filled, []string{FieldBrand}) {
+		t.Errorf("filled = %v, want only brand", filled)
+	}
+	if product.Title != "Scraped title" || *product.CurrentPrice != scrapedPrice {
+		t.Errorf("scraped values should win: %+v", product)
+	}
+	if product.Brand != "Tall Co" {
+		t.Errorf("Brand = %q", product.Brand)
+	}
+
+	want := map[string]string{FieldTitle: SourceScraper, FieldBrand: SourcePAAPI, FieldPrice: SourceScraper}
+	if !reflect.DeepEqual(product.DataSources, want) {
+		t.Errorf("DataSources = %v, want %v", product.DataSources, want)
+	}
+
+	// A later merge with images keeps the recorded sources
+	product.MergeItem(&paapi.Item{Images: []string{"https://m.media-amazon.com/images/I/main.jpg"}})
+	if product.DataSources[FieldImages] != SourcePAAPI || product.DataSources[FieldBrand] != SourcePAAPI {
+		t.Errorf("DataSources = %v", product.DataSources)
+	}
+}
+
+func TestBlocked(t *testing.T) {
+	for _, err := range []error{
+		fmt.Errorf("failed to navigate: %w", browser.ErrCaptcha),
+		browser.ErrMarketplaceCooldown,
+		browser.ErrSignInRequired,
+	} {
+		if !Blocked(err) {
+			t.Errorf("Blocked(%v) = false", err)
+		}
+	}
+	for _, err := range []error{ErrNoSizeTable, ErrTaskTimeout, errors.New("net::ERR_CONNECTION_RESET")} {
+		if Blocked(err) {
+			t.Errorf("Blocked(%v) = true", err)
+		}
+	}
+}
+
+func TestProvenance(t *testing.T) {
+	at := time.Date(2024, 3, 1, 10, 15, 0, 0, time.UTC)
+	apiPrice := 24.99
+	product := &CompleteProduct{
+		ASIN:      "B08N5WRWNW",
+		SizeTable: &database.SizeTable{Sizes: []string{"M"}, Source: database.SizeTableSourceAPlus},
+	}
+
+	// PA-API fills the price, the scraped size table keeps its own origin
+	product.MergeItem(&paapi.Item{Price: &apiPrice, Currency: "EUR"})
+	product.recordProvenance(at)
+
+	if got := product.Provenance[database.FieldPrice].Source; got != database.ProvenancePAAPI {
+		t.Errorf("price source = %q, want %q", got, database.ProvenancePAAPI)
+	}
+	want := database.FieldProvenance{Source: database.ProvenanceTable, Detail: database.SizeTableSourceAPlus, ExtractedAt: at}
+	if got := product.Provenance[database.FieldSizeTable]; got != want {
+		t.Errorf("size table provenance = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,117 @@
+package scraper
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/maltedev/amazon-size-scraper/internal/numparse"
+	"github.com/playwright-community/playwright-go"
+)
+
+var (
+	fitPercentPattern = regexp.MustCompile(`(\d+(?:[,.]\d+)?)\s*%`)
+	fitCountPattern   = regexp.MustCompile(`(\d+(?:[.,]\d{3})*)`)
+)
+
+// fitBar is a row of the fit histogram, e.g. "Zu klein" / "12 %"
+type fitBar struct {
+	Label   string
+	Percent string
+}
+
+// extractFitFeedback reads the "Passform" widget with the customer fit rating and its histogram
+func (pe *ProductExtractor) extractFitFeedback(page playwright.Page, product *CompleteProduct) error {
+	section, err := page.QuerySelector("#fitRecommendationsSection")
+	if err != nil || section == nil {
+		return nil
+	}
+
+	var summary, count string
+	if el, err := section.QuerySelector(".a-text-bold"); err == nil && el != nil {
+		summary, _ = el.TextContent()
+	}
+	if el, err := section.QuerySelector(".a-color-secondary"); err == nil && el != nil {
+		count, _ = el.TextContent()
+	}
+
+	var bars []fitBar
+	rows, err := page.QuerySelectorAll("#fit-histogram-table tr")
+	if err == nil {
+		for _, row := range rows {
+			cells, err := row.QuerySelectorAll("td")
+			if err != nil || len(cells) < 2 {
+				continue
+			}
+			label, _ := cells[0].TextContent()
+			percent, _ := cells[len(cells)-1].TextContent()
+			bars = append(bars, fitBar{Label: label, Percent: percent})
+		}
+	}
+
+	product.FitFeedback = parseFitFeedback(summary, count, bars)
+	return nil
+}
+
+// parseFitFeedback builds the fit rating from the widget texts, nil if it holds none. The overall fit
+// is taken from the summary and falls back to the largest histogram bar.
+func parseFitFeedback(summary, count string, bars []fitBar) *database.FitFeedback {
+	fit := &database.FitFeedback{
+		Summary: strings.Join(strings.Fields(summary), " "),
+		Fit:     classifyFit(summary),
+	}
+
+	for _, bar := range bars {
+		m := fitPercentPattern.FindStringSubmatch(bar.Percent)
+		if m == nil {
+			continue
+		}
+		percent, _ := numparse.Float(m[1], numparse.Auto)
+
+		// "Etwas zu klein" and "Zu klein" both count as small
+		switch classifyFit(bar.Label) {
+		case database.FitSmall:
+			fit.TooSmall += percent
+		case database.FitTrueToSize:
+			fit.TrueToSize += percent
+		case database.FitLarge:
+			fit.TooLarge += percent
+		}
+	}
+
+	if m := fitCountPattern.FindString(count); m != "" {
+		fit.Ratings, _ = strconv.Atoi(strings.NewReplacer(".", "", ",", "").Replace(m))
+	}
+
+	if fit.Fit == "" {
+		switch {
+		case fit.TrueToSize > 0 && fit.TrueToSize >= fit.TooSmall && fit.TrueToSize >= fit.TooLarge:
+			fit.Fit = database.FitTrueToSize
+		case fit.TooSmall > 0 && fit.TooSmall >= fit.TooLarge:
+			fit.Fit = database.FitSmall
+		case fit.TooLarge > 0:
+			fit.Fit = database.FitLarge
+		}
+	}
+
+	if fit.Summary == "" && fit.Fit == "" {
+		return nil
+	}
+	return fit
+}
+
+// classifyFit maps a fit text like "Fällt klein aus" or "Runs large" to a Fit constant
+func classifyFit(text string) string {
+	text = strings.ToLower(text)
+	switch {
+	case strings.Contains(text, "klein"), strings.Contains(text, "small"):
+		return database.FitSmall
+	case strings.Contains(text, "groß"), strings.Contains(text, "gross"), strings.Contains(text, "large"):
+		return database.FitLarge
+	case strings.Contains(text, "normal"), strings.Contains(text, "erwartet"),
+		strings.Contains(text, "true to size"), strings.Contains(text, "as expected"):
+		return database.FitTrueToSize
+	}
+	return ""
+}
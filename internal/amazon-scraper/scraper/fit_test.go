@@ -0,0 +1,44 @@
+package scraper
+
+import (
+	"testing"
+
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFitFeedback(t *testing.T) {
+	bars := []fitBar{
+		{Label: "Zu klein", Percent: "8 %"},
+		{Label: "Etwas zu klein", Percent: "10 %"},
+		{Label: "Wie erwartet", Percent: "71 %"},
+		{Label: "Etwas zu groß", Percent: "7 %"},
+		{Label: "Zu groß", Percent: "4%"},
+	}
+
+	fit := parseFitFeedback("  Fällt normal\n aus ", "Basierend auf 1.312 Bewertungen", bars)
+	if assert.NotNil(t, fit) {
+		assert.Equal(t, "Fällt normal aus", fit.Summary)
+		assert.Equal(t, database.FitTrueToSize, fit.Fit)
+		assert.Equal(t, 18.0, fit.TooSmall)
+		assert.Equal(t, 71.0, fit.TrueToSize)
+		assert.Equal(t, 11.0, fit.TooLarge)
+		assert.Equal(t, 1312, fit.Ratings)
+	}
+}
+
+func TestParseFitFeedback_FitFromHistogram(t *testing.T) {
+	fit := parseFitFeedback("", "", []fitBar{
+		{Label: "Runs small", Percent: "55%"},
+		{Label: "True to size", Percent: "40%"},
+		{Label: "Runs large", Percent: "5%"},
+	})
+	if assert.NotNil(t, fit) {
+		assert.Equal(t, database.FitSmall, fit.Fit)
+	}
+}
+
+func TestParseFitFeedback_Empty(t *testing.T) {
+	assert.Nil(t, parseFitFeedback("", "", nil))
+	assert.Equal(t, database.FitLarge, classifyFit("Fällt groß aus"))
+}
@@ -0,0 +1,45 @@
+package scraper
+
+import (
+	"github.com/maltedev/amazon-size-scraper/internal/browser"
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/playwright-community/playwright-go"
+)
+
+// extractInlineSizeTable parses size tables rendered directly in the product description or A+ content
+func (s *Service) extractInlineSizeTable(page playwright.Page, asin string) *database.SizeTable {
+	tables, err := browser.FindInlineSizeTables(page)
+	if err != nil {
+		s.logger.Warn("failed to find inline size tables", "asin", asin, "error", err)
+		return nil
+	}
+
+	for _, table := range tables {
+		sizeTable := s.parseInlineSizeTable(table)
+		if sizeTable == nil {
+			continue
+		}
+
+		s.logger.Info("extracted inline size table", "asin", asin, "source", sizeTable.Source, "sizeCount", len(sizeTable.Sizes))
+		return sizeTable
+	}
+	return nil
+}
+
+// parseInlineSizeTable runs a candidate table through the modal table parser and tags its section.
+// Description and A+ content also hold spec and care tables, so only tables with measurements count.
+func (s *Service) parseInlineSizeTable(table browser.InlineSizeTable) *database.SizeTable {
+	sizeTable := s.parseFullSizeTable(table.Data)
+	if sizeTable == nil || !sizeTable.HasMeasurements() {
+		return nil
+	}
+
+	switch table.Section {
+	case browser.SectionAPlus:
+		sizeTable.Source = database.SizeTableSourceAPlus
+	default:
+		sizeTable.Source = database.SizeTableSourceInline
+	}
+	sizeTable.Confidence = 1.0
+	return sizeTable
+}
@@ -0,0 +1,95 @@
+package scraper
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/maltedev/amazon-size-scraper/internal/amazontest"
+	"github.com/maltedev/amazon-size-scraper/internal/browser"
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// table builds the {headers, rows} shape returned by browser.FindInlineSizeTables
+func table(section string, rows ...[]string) browser.InlineSizeTable {
+	data := []interface{}{}
+	for _, row := range rows[1:] {
+		data = append(data, toInterfaces(row))
+	}
+	return browser.InlineSizeTable{
+		Section: section,
+		Data:    map[string]interface{}{"section": section, "headers": toInterfaces(rows[0]), "rows": data},
+	}
+}
+
+func TestParseInlineSizeTable(t *testing.T) {
+	s := NewService(nil, nil, slog.Default())
+
+	t.Run("description table", func(t *testing.T) {
+		st := s.parseInlineSizeTable(table(browser.SectionDescription,
+			[]string{"Größe", "L", "XL"},
+			[]string{"Länge (cm)", "84", "86"},
+			[]string{"Brustumfang (cm)", "108", "114"},
+		))
+		require.NotNil(t, st)
+		assert.Equal(t, []string{"L", "XL"}, st.Sizes)
+		assert.Equal(t, 86.0, st.Measurements["XL"]["length"])
+		assert.Equal(t, database.SizeTableSourceInline, st.Source)
+		assert.Equal(t, 1.0, st.Confidence)
+	})
+
+	t.Run("A+ table", func(t *testing.T) {
+		st := s.parseInlineSizeTable(table(browser.SectionAPlus,
+			[]string{"Größe", "Brustumfang (cm)", "Länge (cm)"},
+			[]string{"M", "104", "77"},
+		))
+		require.NotNil(t, st)
+		assert.Equal(t, 104.0, st.Measurements["M"]["chest"])
+		assert.Equal(t, database.SizeTableSourceAPlus, st.Source)
+	})
+
+	t.Run("table without measurements", func(t *testing.T) {
+		assert.Nil(t, s.parseInlineSizeTable(table(browser.SectionAPlus,
+			[]string{"Größe", "Farbe", "Material"},
+			[]string{"M", "Schwarz", "Baumwolle"},
+		)))
+	})
+
+	t.Run("layout table", func(t *testing.T) {
+		assert.Nil(t, s.parseInlineSizeTable(table(browser.SectionAPlus, []string{"", "Perfekte Passform"})))
+	})
+}
+
+func TestExtractSizeChart_Inline(t *testing.T) {
+	server := amazontest.NewServer()
+	defer server.Close()
+	for _, p := range amazontest.InlineChartProducts() {
+		server.AddProduct(p)
+	}
+	b := amazontest.NewBrowser(t)
+
+	s := NewService(b, nil, slog.Default())
+
+	tests := []struct {
+		asin   string
+		source string
+		size   string
+		length float64
+	}{
+		{amazontest.ASINDescriptionChart, database.SizeTableSourceInline, "XL", 86},
+		{amazontest.ASINAPlusChart, database.SizeTableSourceAPlus, "L", 79},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			dimensions, err := s.ExtractSizeChart(context.Background(), tt.asin, server.ProductURL(tt.asin))
+			require.NoError(t, err)
+			require.True(t, dimensions.Found)
+			require.NotNil(t, dimensions.SizeTable)
+			assert.Equal(t, tt.source, dimensions.SizeTable.Source)
+			assert.Equal(t, tt.length, dimensions.SizeTable.Measurements[tt.size]["length"])
+		})
+	}
+}
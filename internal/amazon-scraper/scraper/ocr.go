@@ -0,0 +1,124 @@
+package scraper
+
+import (
+	"context"
+
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/maltedev/amazon-size-scraper/internal/ocr"
+	"github.com/playwright-community/playwright-go"
+)
+
+// maxOCRImages limits how many candidate images are sent to the OCR engine per product
+const maxOCRImages = 3
+
+// SetOCR enables the image OCR fallback for size charts, nil disables it
+func (s *Service) SetOCR(engine ocr.Engine) {
+	s.ocr = engine
+}
+
+// extractSizeChartFromImages recognizes size chart images on the page and parses them like HTML tables
+func (s *Service) extractSizeChartFromImages(ctx context.Context, page playwright.Page, asin string) *database.SizeTable {
+	if s.ocr == nil {
+		return nil
+	}
+
+	// Tag images whose alt text, source or surrounding heading mentions a size chart
+	count, err := page.Evaluate(`(max) => {
+		const keywords = ['größentabelle', 'größenratgeber', 'größentab', 'maßtabelle', 'size chart', 'size guide', 'sizechart', 'size_chart', 'size-chart'];
+		const matches = (text) => {
+			text = (text || '').toLowerCase();
+			return keywords.some(k => text.includes(k));
+		};
+
+		const candidates = document.querySelectorAll(
+			'.a-popover-content img, .a-modal-content img, #aplus img, #aplus_feature_div img, .aplus-v2 img, #productDescription img'
+		);
+
+		let tagged = 0;
+		for (const img of candidates) {
+			if (tagged >= max) break;
+
+			const module = img.closest('.aplus-module, .a-popover-content, .a-modal-content');
+			const heading = module ? module.querySelector('h1, h2, h3, h4, h5') : null;
+			const inPopover = !!img.closest('.a-popover-content, .a-modal-content');
+
+			if (inPopover || matches(img.alt) || matches(img.src) || matches(img.title) || (heading && matches(heading.textContent))) {
+				img.setAttribute('data-size-chart-ocr', String(tagged));
+				tagged++;
+			}
+		}
+		return tagged;
+	}`, maxOCRImages)
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to detect size chart images", "asin", asin, "error", err)
+		return nil
+	}
+
+	var n int
+	switch v := count.(type) {
+	case int:
+		n = v
+	case float64:
+		n = int(v)
+	}
+
+	images := page.Locator("img[data-size-chart-ocr]")
+	for i := 0; i < n; i++ {
+		image, err := images.Nth(i).Screenshot()
+		if err != nil {
+			s.logger.WarnContext(ctx, "failed to capture size chart image", "asin", asin, "error", err)
+			continue
+		}
+
+		text, err := s.ocr.Recognize(ctx, image)
+		if err != nil {
+			s.logger.WarnContext(ctx, "failed to recognize size chart image", "asin", asin, "error", err)
+			continue
+		}
+
+		sizeTable := s.parseOCRText(text)
+		if sizeTable == nil {
+			s.logger.DebugContext(ctx, "no size table in image text", "asin", asin, "image", i)
+			continue
+		}
+
+		s.logger.InfoContext(ctx, "extracted size table from image", "asin", asin, "sizeCount", len(sizeTable.Sizes))
+		return sizeTable
+	}
+
+	return nil
+}
+
+// parseOCRText feeds recognized text through the HTML table parser and marks the result as OCR
+func (s *Service) parseOCRText(text string) *database.SizeTable {
+	headers, rows := ocr.ParseTable(text)
+	if len(headers) == 0 {
+		return nil
+	}
+
+	rowData := make([]interface{}, len(rows))
+	for i, row := range rows {
+		rowData[i] = toInterfaces(row)
+	}
+
+	sizeTable := s.parseFullSizeTable(map[string]interface{}{
+		"headers": toInterfaces(headers),
+		"rows":    rowData,
+	})
+	if sizeTable == nil {
+		return nil
+	}
+
+	sizeTable.Source = database.SizeTableSourceOCR
+	sizeTable.Confidence = database.OCRConfidence
+	return sizeTable
+}
+
+// toInterfaces converts cells to the shape returned by page.Evaluate
+func toInterfaces(cells []string) []interface{} {
+	out := make([]interface{}, len(cells))
+	for i, cell := range cells {
+		out[i] = cell
+	}
+	return out
+}
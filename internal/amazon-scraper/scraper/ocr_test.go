@@ -0,0 +1,36 @@
+package scraper
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOCRText(t *testing.T) {
+	s := NewService(nil, nil, slog.Default())
+
+	t.Run("sizes in header row", func(t *testing.T) {
+		st := s.parseOCRText("Größe S M L\nLänge (cm) 76 78 80\nBrustumfang (cm) 96 102 108\n")
+		require.NotNil(t, st)
+		assert.Equal(t, []string{"S", "M", "L"}, st.Sizes)
+		assert.Equal(t, 78.0, st.Measurements["M"]["length"])
+		assert.Equal(t, 108.0, st.Measurements["L"]["chest"])
+		assert.Equal(t, database.SizeTableSourceOCR, st.Source)
+		assert.Equal(t, database.OCRConfidence, st.Confidence)
+	})
+
+	t.Run("sizes in first column", func(t *testing.T) {
+		st := s.parseOCRText("Größe    Brustumfang (cm)    Länge (cm)\nM    100    79\nL    106    81\n")
+		require.NotNil(t, st)
+		assert.Equal(t, []string{"M", "L"}, st.Sizes)
+		assert.Equal(t, 81.0, st.Measurements["L"]["length"])
+		assert.True(t, database.ValidateSizeTable(st))
+	})
+
+	t.Run("no table", func(t *testing.T) {
+		assert.Nil(t, s.parseOCRText("Pflegehinweise: 30 Grad waschen"))
+	})
+}
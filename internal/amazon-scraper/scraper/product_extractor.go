@@ -3,37 +3,61 @@ package scraper
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/maltedev/amazon-size-scraper/internal/brand"
 	"github.com/maltedev/amazon-size-scraper/internal/browser"
+	"github.com/maltedev/amazon-size-scraper/internal/currency"
 	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/maltedev/amazon-size-scraper/internal/numparse"
+	"github.com/maltedev/amazon-size-scraper/internal/parser"
+	"github.com/maltedev/amazon-size-scraper/internal/scrapeerr"
+	"github.com/maltedev/amazon-size-scraper/internal/stages"
+	"github.com/maltedev/amazon-size-scraper/internal/taxonomy"
 	"github.com/playwright-community/playwright-go"
 )
 
 // CompleteProduct represents a product with all extracted data
 type CompleteProduct struct {
-	ASIN           string                 `json:"asin"`
-	Title          string                 `json:"title"`
-	Brand          string                 `json:"brand"`
-	DetailPageURL  string                 `json:"detail_page_url"`
-	Category       string                 `json:"category"`
-	ImageURLs      []string               `json:"image_urls"`
-	Features       []string               `json:"features"`
-	CurrentPrice   *float64               `json:"current_price"`
-	Currency       string                 `json:"currency"`
-	Rating         *float64               `json:"rating"`
-	ReviewCount    *int                   `json:"review_count"`
-	AvailableSizes []string               `json:"available_sizes"`
-	SizeTable      *database.SizeTable    `json:"size_table"`
+	ASIN              string                             `json:"asin"`
+	Title             string                             `json:"title"`
+	Brand             string                             `json:"brand"`
+	DetailPageURL     string                             `json:"detail_page_url"`
+	Category          string                             `json:"category"`
+	Breadcrumbs       []string                           `json:"breadcrumbs,omitempty"`   // Category path, top level first
+	BrowseNodes       []string                           `json:"browse_nodes,omitempty"`  // Browse nodes of the breadcrumb links
+	CategoryCode      string                             `json:"category_code,omitempty"` // Internal category, see package taxonomy
+	ImageURLs         []string                           `json:"image_urls"`
+	Features          []string                           `json:"features"`
+	Attributes        map[string]string                  `json:"attributes,omitempty"` // Product overview grid and "Label: value" bullets by canonical key
+	CurrentPrice      *float64                           `json:"current_price"`
+	Currency          string                             `json:"currency"`
+	ReportingPrice    *float64                           `json:"reporting_price,omitempty"` // CurrentPrice in the reporting currency
+	ReportingCurrency string                             `json:"reporting_currency,omitempty"`
+	Rating            *float64                           `json:"rating"`
+	ReviewCount       *int                               `json:"review_count"`
+	AvailableSizes    []string                           `json:"available_sizes"`
+	SizePrices        database.SizePrices                `json:"size_prices,omitempty"`
+	FitFeedback       *database.FitFeedback              `json:"fit_feedback,omitempty"`
+	SizeTable         *database.SizeTable                `json:"size_table"`
+	Validation        *database.ValidationReport         `json:"validation,omitempty"`
+	StageTimings      stages.Timings                     `json:"stage_timings_ms,omitempty"`   // Milliseconds per extraction stage
+	DataSources       map[string]string                  `json:"data_sources,omitempty"`       // Field -> SourceScraper or SourcePAAPI
+	Provenance        database.Provenance                `json:"provenance,omitempty"`         // Origin and time of price and size table
+	MeasurementPolicy *database.AppliedMeasurementPolicy `json:"measurement_policy,omitempty"` // Policy the size table was accepted under
 }
 
 // ProductExtractor handles comprehensive product data extraction
 type ProductExtractor struct {
 	browser *browser.Browser
+	stages  stages.Flags
+	policy  database.MeasurementPolicy
 	logger  *slog.Logger
 }
 
@@ -41,31 +65,52 @@ type ProductExtractor struct {
 func NewProductExtractor(browser *browser.Browser, logger *slog.Logger) *ProductExtractor {
 	return &ProductExtractor{
 		browser: browser,
+		policy:  database.DefaultMeasurementPolicy(),
 		logger:  logger.With("component", "product_extractor"),
 	}
 }
 
+// SetStages sets which optional extraction stages run, all of them by default
+func (pe *ProductExtractor) SetStages(f stages.Flags) {
+	pe.stages = f
+}
+
+// SetMeasurementPolicy sets which size tables are accepted, length and chest are required by default
+func (pe *ProductExtractor) SetMeasurementPolicy(p database.MeasurementPolicy) {
+	pe.policy = p
+}
+
 // ExtractCompleteProduct extracts all product data including size table
 func (pe *ProductExtractor) ExtractCompleteProduct(ctx context.Context, asin, url string) (*CompleteProduct, error) {
 	if url == "" && asin != "" {
 		url = fmt.Sprintf("https://www.amazon.de/dp/%s", asin)
 	}
 
-	pe.logger.Info("extracting complete product data", "asin", asin, "url", url)
+	pe.logger.InfoContext(ctx, "extracting complete product data", "asin", asin, "url", url)
 
-	page, err := pe.browser.NewPage()
+	page, err := pe.browser.NewTaskPage(browser.TaskProduct)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create page: %w", err)
 	}
 	defer page.Close()
+	defer browser.ClosePageOnDone(ctx, page)()
+
+	// Every stage is timed, optional stages disabled by the deployment are skipped
+	timer := pe.stages.NewTimer()
 
 	// Navigate to product page
-	if err := pe.browser.NavigateWithRetry(page, url, 3); err != nil {
+	if err := timer.Run(stages.Navigate, func() error {
+		return pe.browser.NavigateWithRetryContext(ctx, page, url, 3)
+	}); err != nil {
 		return nil, fmt.Errorf("failed to navigate: %w", err)
 	}
 
 	// Add human-like behavior
-	pe.browser.HumanizeInteraction(page)
+	if err := timer.Run(stages.Humanize, func() error {
+		return pe.browser.HumanizeTask(ctx, page, browser.TaskProduct)
+	}); err != nil {
+		return nil, err
+	}
 
 	// Extract all product data
 	product := &CompleteProduct{
@@ -74,61 +119,102 @@ func (pe *ProductExtractor) ExtractCompleteProduct(ctx context.Context, asin, ur
 	}
 
 	// Extract basic info
-	if err := pe.extractBasicInfo(page, product); err != nil {
-		pe.logger.Warn("failed to extract basic info", "error", err)
+	if err := timer.Run(stages.Basic, func() error { return pe.extractBasicInfo(page, product) }); err != nil {
+		pe.logger.WarnContext(ctx, "failed to extract basic info", "error", err)
 	}
 
 	// Extract images
-	if err := pe.extractImages(page, product); err != nil {
-		pe.logger.Warn("failed to extract images", "error", err)
+	if err := timer.Run(stages.Images, func() error { return pe.extractImages(page, product) }); err != nil {
+		pe.logger.WarnContext(ctx, "failed to extract images", "error", err)
 	}
 
 	// Extract features
-	if err := pe.extractFeatures(page, product); err != nil {
-		pe.logger.Warn("failed to extract features", "error", err)
+	if err := timer.Run(stages.Features, func() error { return pe.extractFeatures(page, product) }); err != nil {
+		pe.logger.WarnContext(ctx, "failed to extract features", "error", err)
 	}
 
 	// Extract price
-	if err := pe.extractPrice(page, product); err != nil {
-		pe.logger.Warn("failed to extract price", "error", err)
+	if err := timer.Run(stages.Price, func() error { return pe.extractPrice(page, product) }); err != nil {
+		pe.logger.WarnContext(ctx, "failed to extract price", "error", err)
 	}
 
-	// Extract ratings
-	if err := pe.extractRatings(page, product); err != nil {
-		pe.logger.Warn("failed to extract ratings", "error", err)
+	// Extract ratings and customer fit feedback
+	if err := timer.Run(stages.Reviews, func() error {
+		if err := pe.extractRatings(page, product); err != nil {
+			pe.logger.WarnContext(ctx, "failed to extract ratings", "error", err)
+		}
+		return pe.extractFitFeedback(page, product)
+	}); err != nil {
+		pe.logger.WarnContext(ctx, "failed to extract fit feedback", "error", err)
 	}
 
-	// Extract available sizes
-	if err := pe.extractAvailableSizes(page, product); err != nil {
-		pe.logger.Warn("failed to extract sizes", "error", err)
+	// Extract available sizes with price and availability per size
+	if err := timer.Run(stages.Sizes, func() error {
+		if err := pe.extractAvailableSizes(page, product); err != nil {
+			pe.logger.WarnContext(ctx, "failed to extract sizes", "error", err)
+		}
+		return pe.extractSizePrices(page, product)
+	}); err != nil {
+		pe.logger.WarnContext(ctx, "failed to extract size prices", "error", err)
 	}
 
 	// Extract size table - this is critical
-	sizeTable, err := pe.extractSizeTable(page, asin)
+	var sizeTable *database.SizeTable
+	err = timer.Run(stages.SizeTable, func() error {
+		var err error
+		sizeTable, err = pe.extractSizeTable(ctx, page, asin, url)
+		return err
+	})
+	product.StageTimings = timer.Timings()
+	pe.logger.DebugContext(ctx, "extraction stage timings", "asin", asin, "timings_ms", product.StageTimings)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
 	if err != nil {
-		pe.logger.Warn("failed to extract size table", "error", err)
-		return nil, fmt.Errorf("no size table found")
+		pe.logger.WarnContext(ctx, "failed to extract size table", "error", err)
+		if errors.Is(err, scrapeerr.ErrBlocked) || errors.Is(err, scrapeerr.ErrNoSizeTable) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", ErrNoSizeTable, err)
 	}
 
-	// Validate size table has length and chest
-	if !database.ValidateSizeTable(sizeTable) {
-		pe.logger.Warn("size table missing length/chest", "asin", asin)
-		return nil, fmt.Errorf("size table missing length or chest measurements")
+	// Validate size table has the measurements the policy requires
+	if !pe.policy.Accepts(sizeTable) {
+		pe.logger.WarnContext(ctx, "size table missing required measurements", "asin", asin, "policy", pe.policy)
+		return nil, ErrMissingLength
 	}
 
 	product.SizeTable = sizeTable
+	applied := pe.policy.Apply(sizeTable)
+	product.MeasurementPolicy = &applied
+	product.recordProvenance(time.Now())
 
-	pe.logger.Info("extracted complete product data",
+	pe.logger.InfoContext(ctx, "extracted complete product data",
 		"asin", asin,
 		"hasImages", len(product.ImageURLs) > 0,
 		"hasFeatures", len(product.Features) > 0,
 		"hasSizeTable", product.SizeTable != nil,
 		"sizeCount", len(product.SizeTable.Sizes),
+		"duration_ms", product.StageTimings.Total(),
 	)
 
 	return product, nil
 }
 
+// recordProvenance records the origin of the scraped price and size table, fields that already have one
+// (e.g. from PA-API) keep it
+func (cp *CompleteProduct) recordProvenance(at time.Time) {
+	if cp.Provenance == nil {
+		cp.Provenance = make(database.Provenance)
+	}
+	if _, ok := cp.Provenance[database.FieldPrice]; !ok && cp.CurrentPrice != nil {
+		cp.Provenance.Record(database.FieldPrice, database.ProvenancePage, "", at)
+	}
+	if _, ok := cp.Provenance[database.FieldSizeTable]; !ok {
+		cp.Provenance.RecordSizeTable(cp.SizeTable, at)
+	}
+}
+
 func (pe *ProductExtractor) extractBasicInfo(page playwright.Page, product *CompleteProduct) error {
 	// Extract title
 	titleEl, err := page.QuerySelector("#productTitle")
@@ -146,25 +232,27 @@ func (pe *ProductExtractor) extractBasicInfo(page playwright.Page, product *Comp
 	for _, selector := range brandSelectors {
 		brandEl, err := page.QuerySelector(selector)
 		if err == nil && brandEl != nil {
-			brand, _ := brandEl.TextContent()
-			brand = strings.TrimSpace(brand)
-			brand = strings.TrimPrefix(brand, "Marke: ")
-			brand = strings.TrimPrefix(brand, "Brand: ")
-			product.Brand = brand
+			text, _ := brandEl.TextContent()
+			product.Brand = brand.Normalize(text)
 			break
 		}
 	}
 
-	// Extract category from breadcrumbs
+	// Extract category from breadcrumbs, the last non-product breadcrumb is the category
 	breadcrumbs, err := page.QuerySelectorAll("div#wayfinding-breadcrumbs_feature_div a")
-	if err == nil && len(breadcrumbs) > 0 {
-		// Get the last non-product breadcrumb
-		for i := len(breadcrumbs) - 1; i >= 0; i-- {
-			text, _ := breadcrumbs[i].TextContent()
+	if err == nil {
+		for _, crumb := range breadcrumbs {
+			text, _ := crumb.TextContent()
 			text = strings.TrimSpace(text)
-			if text != "" && text != product.Title {
-				product.Category = text
-				break
+			if text == "" || text == product.Title {
+				continue
+			}
+			product.Breadcrumbs = append(product.Breadcrumbs, text)
+			product.Category = text
+
+			href, _ := crumb.GetAttribute("href")
+			if node := taxonomy.BrowseNode(href); node != "" {
+				product.BrowseNodes = append(product.BrowseNodes, node)
 			}
 		}
 	}
@@ -221,6 +309,17 @@ func (pe *ProductExtractor) extractFeatures(page playwright.Page, product *Compl
 	}
 
 	product.Features = features
+
+	// The product overview grid lists attributes like material and fit, bullets fill its gaps
+	grid, err := page.QuerySelector("#productOverview_feature_div")
+	if err == nil && grid != nil {
+		if html, err := grid.Evaluate("el => el.outerHTML"); err == nil {
+			if s, ok := html.(string); ok {
+				product.Attributes, _ = parser.ProductOverviewHTML(s)
+			}
+		}
+	}
+	product.Attributes = parser.MergeFeatureAttributes(product.Attributes, features)
 	return nil
 }
 
@@ -237,10 +336,10 @@ func (pe *ProductExtractor) extractPrice(page playwright.Page, product *Complete
 		priceEl, err := page.QuerySelector(selector)
 		if err == nil && priceEl != nil {
 			priceText, _ := priceEl.TextContent()
-			price := pe.parsePrice(priceText)
+			price, cur := currency.Parse(priceText, currency.ForMarketplace(page.URL()))
 			if price > 0 {
 				product.CurrentPrice = &price
-				product.Currency = "EUR"
+				product.Currency = cur
 				break
 			}
 		}
@@ -304,55 +403,31 @@ func (pe *ProductExtractor) extractAvailableSizes(page playwright.Page, product
 	return nil
 }
 
-func (pe *ProductExtractor) extractSizeTable(page playwright.Page, asin string) (*database.SizeTable, error) {
+func (pe *ProductExtractor) extractSizeTable(ctx context.Context, page playwright.Page, asin, url string) (*database.SizeTable, error) {
 	// Use the existing ExtractSizeChart method from Service
 	service := &Service{
 		browser: pe.browser,
 		logger:  pe.logger,
 	}
 
-	dimensions, err := service.ExtractSizeChart(context.Background(), asin, "")
+	dimensions, err := service.ExtractSizeChart(ctx, asin, url)
 	if err != nil {
 		return nil, err
 	}
 
 	if !dimensions.Found || dimensions.SizeTable == nil {
-		return nil, fmt.Errorf("no size table found")
+		return nil, ErrNoSizeTable
 	}
 
 	return dimensions.SizeTable, nil
 }
 
-func (pe *ProductExtractor) parsePrice(text string) float64 {
-	// Remove currency symbols and spaces
-	text = strings.Replace(text, "€", "", -1)
-	text = strings.Replace(text, "EUR", "", -1)
-	text = strings.TrimSpace(text)
-
-	// Handle German decimal format (1.234,56)
-	text = strings.Replace(text, ".", "", -1)  // Remove thousand separators
-	text = strings.Replace(text, ",", ".", -1) // Convert comma to dot
-
-	// Extract numeric value
-	re := regexp.MustCompile(`\d+\.?\d*`)
-	match := re.FindString(text)
-	if match != "" {
-		price, err := strconv.ParseFloat(match, 64)
-		if err == nil {
-			return price
-		}
-	}
-
-	return 0
-}
-
 func (pe *ProductExtractor) parseRating(text string) float64 {
 	// Extract rating from text like "4,5 von 5 Sternen"
 	re := regexp.MustCompile(`(\d+[,.]?\d*)\s*von\s*5`)
 	match := re.FindStringSubmatch(text)
 	if len(match) > 1 {
-		rating := strings.Replace(match[1], ",", ".", 1)
-		if val, err := strconv.ParseFloat(rating, 64); err == nil {
+		if val, ok := numparse.Float(match[1], numparse.Auto); ok {
 			return val
 		}
 	}
@@ -381,11 +456,14 @@ func (pe *ProductExtractor) ConvertToLifecycleProduct(cp *CompleteProduct) (*dat
 		Brand:         cp.Brand,
 		DetailPageURL: cp.DetailPageURL,
 		Category:      cp.Category,
+		CategoryPath:  strings.Join(cp.Breadcrumbs, " > "),
+		CategoryCode:  cp.CategoryCode,
 		CurrentPrice:  cp.CurrentPrice,
 		Currency:      cp.Currency,
 		Rating:        cp.Rating,
 		ReviewCount:   cp.ReviewCount,
-		Status:        "SCRAPED",
+		Status:        string(database.StatusScraped),
+		ContentHash:   cp.ContentHash(),
 	}
 
 	// Convert arrays to JSON
@@ -399,6 +477,11 @@ func (pe *ProductExtractor) ConvertToLifecycleProduct(cp *CompleteProduct) (*dat
 		p.Features = json.RawMessage(data)
 	}
 
+	if len(cp.Attributes) > 0 {
+		data, _ := json.Marshal(cp.Attributes)
+		p.Attributes = json.RawMessage(data)
+	}
+
 	if len(cp.AvailableSizes) > 0 {
 		data, _ := json.Marshal(cp.AvailableSizes)
 		p.AvailableSizes = json.RawMessage(data)
@@ -409,5 +492,38 @@ func (pe *ProductExtractor) ConvertToLifecycleProduct(cp *CompleteProduct) (*dat
 		p.SizeTable = json.RawMessage(data)
 	}
 
+	if cp.FitFeedback != nil {
+		data, _ := json.Marshal(cp.FitFeedback)
+		p.FitFeedback = json.RawMessage(data)
+	}
+
+	if len(cp.SizePrices) > 0 {
+		data, _ := json.Marshal(cp.SizePrices)
+		p.SizePrices = json.RawMessage(data)
+	}
+
+	if len(cp.StageTimings) > 0 {
+		data, _ := json.Marshal(cp.StageTimings)
+		p.StageTimings = json.RawMessage(data)
+	}
+
+	if len(cp.DataSources) > 0 {
+		data, _ := json.Marshal(cp.DataSources)
+		p.DataSources = json.RawMessage(data)
+	}
+
+	p.Provenance = cp.Provenance.JSON()
+
+	if cp.MeasurementPolicy != nil {
+		p.MeasurementPolicy = cp.MeasurementPolicy.JSON()
+	}
+
+	if cp.Validation != nil {
+		data, _ := json.Marshal(cp.Validation)
+		p.ValidationReport = json.RawMessage(data)
+		score := cp.Validation.Score
+		p.QualityScore = &score
+	}
+
 	return p, nil
-}
\ No newline at end of file
+}
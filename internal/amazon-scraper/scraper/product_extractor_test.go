@@ -1,13 +1,23 @@
 package scraper
 
 import (
+	"context"
+	"log/slog"
 	"testing"
 
+	"github.com/maltedev/amazon-size-scraper/internal/amazontest"
+	"github.com/maltedev/amazon-size-scraper/internal/currency"
 	"github.com/maltedev/amazon-size-scraper/internal/database"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestExtractCompleteProductData(t *testing.T) {
+	server := amazontest.NewServer()
+	defer server.Close()
+	b := amazontest.NewBrowser(t)
+
+	pe := NewProductExtractor(b, slog.Default())
+
 	tests := []struct {
 		name        string
 		asin        string
@@ -15,39 +25,32 @@ func TestExtractCompleteProductData(t *testing.T) {
 		checkFunc   func(t *testing.T, product *CompleteProduct)
 	}{
 		{
-			name:        "Valid product with size table containing length and width",
-			asin:        "B08N5WRWNW",
+			name:        "Valid product with size table containing length and chest",
+			asin:        amazontest.ASINVerticalChart,
 			expectValid: true,
 			checkFunc: func(t *testing.T, product *CompleteProduct) {
 				assert.NotEmpty(t, product.Title)
 				assert.NotEmpty(t, product.DetailPageURL)
 				assert.NotNil(t, product.SizeTable)
 				assert.True(t, len(product.SizeTable.Sizes) > 0)
-				
-				// Check that at least one size has length and width
-				hasLengthAndWidth := false
-				for _, measurements := range product.SizeTable.Measurements {
-					if _, hasLength := measurements["length"]; hasLength {
-						if _, hasWidth := measurements["width"]; hasWidth {
-							hasLengthAndWidth = true
-							break
-						}
-					}
+				assert.True(t, database.ValidateSizeTable(product.SizeTable), "Size table must have length and chest measurements")
+				if assert.NotNil(t, product.FitFeedback) {
+					assert.Equal(t, database.FitTrueToSize, product.FitFeedback.Fit)
+					assert.Equal(t, 312, product.FitFeedback.Ratings)
 				}
-				assert.True(t, hasLengthAndWidth, "Size table must have length and width measurements")
 			},
 		},
 		{
 			name:        "Product without size table",
-			asin:        "B0INVALIDNO",
+			asin:        amazontest.ASINNoChart,
 			expectValid: false,
 			checkFunc: func(t *testing.T, product *CompleteProduct) {
 				assert.Nil(t, product)
 			},
 		},
 		{
-			name:        "Product with size table but missing length/width",
-			asin:        "B0SIZENOLEN",
+			name:        "Product with size table but missing length",
+			asin:        amazontest.ASINNoLength,
 			expectValid: false,
 			checkFunc: func(t *testing.T, product *CompleteProduct) {
 				assert.Nil(t, product)
@@ -57,8 +60,13 @@ func TestExtractCompleteProductData(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// This would need a mock browser or test mode
-			t.Skip("Requires browser implementation")
+			product, err := pe.ExtractCompleteProduct(context.Background(), tt.asin, server.ProductURL(tt.asin))
+			if tt.expectValid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+			tt.checkFunc(t, product)
 		})
 	}
 }
@@ -70,7 +78,7 @@ func TestValidateSizeTableForProduct(t *testing.T) {
 		expected  bool
 	}{
 		{
-			name: "Valid size table with length and width",
+			name: "Valid size table with length and chest",
 			sizeTable: &database.SizeTable{
 				Sizes: []string{"S", "M", "L"},
 				Measurements: map[string]map[string]float64{
@@ -95,12 +103,12 @@ func TestValidateSizeTableForProduct(t *testing.T) {
 			expected: false,
 		},
 		{
-			name: "Invalid - no width",
+			name: "Invalid - no chest",
 			sizeTable: &database.SizeTable{
 				Sizes: []string{"S", "M"},
 				Measurements: map[string]map[string]float64{
-					"S": {"chest": 96, "length": 70},
-					"M": {"chest": 100, "length": 72},
+					"S": {"length": 70, "width": 52},
+					"M": {"length": 72, "width": 54},
 				},
 				Unit: "cm",
 			},
@@ -121,13 +129,13 @@ func TestValidateSizeTableForProduct(t *testing.T) {
 			expected:  false,
 		},
 		{
-			name: "Valid - at least one size has length and width",
+			name: "Valid - at least one size has length and chest",
 			sizeTable: &database.SizeTable{
 				Sizes: []string{"S", "M", "L"},
 				Measurements: map[string]map[string]float64{
-					"S": {"chest": 96}, // Missing length and width
+					"S": {"chest": 96},                             // Missing length
 					"M": {"chest": 100, "length": 72, "width": 54}, // Has both
-					"L": {"chest": 104, "length": 74}, // Missing width
+					"L": {"width": 56, "length": 74},               // Missing chest
 				},
 				Unit: "cm",
 			},
@@ -137,13 +145,15 @@ func TestValidateSizeTableForProduct(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := ValidateSizeTableForProduct(tt.sizeTable)
+			result := database.ValidateSizeTable(tt.sizeTable)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
 }
 
 func TestParseProductDetails(t *testing.T) {
+	pe := NewProductExtractor(nil, slog.Default())
+
 	t.Run("Parse price from German format", func(t *testing.T) {
 		testCases := []struct {
 			input    string
@@ -156,8 +166,9 @@ func TestParseProductDetails(t *testing.T) {
 		}
 
 		for _, tc := range testCases {
-			result := parsePrice(tc.input)
-			assert.Equal(t, tc.expected, result)
+			result, cur := currency.Parse(tc.input, "EUR")
+			assert.Equal(t, tc.expected, result, tc.input)
+			assert.Equal(t, "EUR", cur, tc.input)
 		}
 	})
 
@@ -168,12 +179,13 @@ func TestParseProductDetails(t *testing.T) {
 		}{
 			{"4,5 von 5 Sternen", 4.5},
 			{"3,0 von 5", 3.0},
-			{"4.5 out of 5 stars", 4.5},
+			{"4.5 von 5 Sternen", 4.5},
+			{"Keine Bewertungen", 0},
 		}
 
 		for _, tc := range testCases {
-			result := parseRating(tc.input)
-			assert.Equal(t, tc.expected, result)
+			result := pe.parseRating(tc.input)
+			assert.Equal(t, tc.expected, result, tc.input)
 		}
 	})
 
@@ -188,28 +200,8 @@ func TestParseProductDetails(t *testing.T) {
 		}
 
 		for _, tc := range testCases {
-			result := parseReviewCount(tc.input)
-			assert.Equal(t, tc.expected, result)
+			result := pe.parseReviewCount(tc.input)
+			assert.Equal(t, tc.expected, result, tc.input)
 		}
 	})
 }
-
-// Mock helper functions that would be implemented in the actual code
-func parsePrice(input string) float64 {
-	// This is a placeholder - actual implementation would parse German price format
-	return 0.0
-}
-
-func parseRating(input string) float64 {
-	// This is a placeholder - actual implementation would parse rating
-	return 0.0
-}
-
-func parseReviewCount(input string) int {
-	// This is a placeholder - actual implementation would parse review count
-	return 0
-}
-
-func ValidateSizeTableForProduct(st *database.SizeTable) bool {
-	return database.ValidateSizeTable(st)
-}
\ No newline at end of file
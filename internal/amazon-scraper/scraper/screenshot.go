@@ -0,0 +1,112 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/browser"
+	logging "github.com/maltedev/amazon-size-scraper/pkg/logger"
+)
+
+// DefaultScreenshotMaxAge is how long an archived product screenshot is served before it is captured again
+const DefaultScreenshotMaxAge = 24 * time.Hour
+
+// Screenshot is a captured or archived image of a product page
+type Screenshot struct {
+	Image      []byte
+	Format     string
+	CapturedAt time.Time
+	Archived   bool // Served from the archive instead of navigating to the product
+}
+
+// SetScreenshotArchive stores product screenshots as <dir>/<asin>-<region>.<format> and serves them
+// until they are older than maxAge
+func (s *Service) SetScreenshotArchive(dir string, maxAge time.Duration) {
+	s.screenshotDir = dir
+	s.screenshotMaxAge = maxAge
+}
+
+// ProductScreenshot returns a screenshot of the product page, from the archive while it is fresh
+func (s *Service) ProductScreenshot(ctx context.Context, asin string, opts browser.ScreenshotOptions) (*Screenshot, error) {
+	if opts.Format == "" {
+		opts.Format = browser.ScreenshotPNG
+	}
+	ctx = logging.WithASIN(ctx, asin)
+
+	path := s.screenshotPath(asin, opts)
+	if path != "" {
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) < s.screenshotMaxAge {
+			if image, err := os.ReadFile(path); err == nil {
+				return &Screenshot{Image: image, Format: opts.Format, CapturedAt: info.ModTime(), Archived: true}, nil
+			}
+		}
+	}
+
+	var shot *Screenshot
+	err := s.RunTask(ctx, "screenshot:"+asin, func(ctx context.Context) error {
+		var err error
+		shot, err = s.captureScreenshot(ctx, asin, opts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if path != "" {
+		if err := writeScreenshot(path, shot.Image); err != nil {
+			s.logger.WarnContext(ctx, "failed to archive screenshot", "path", path, "error", err)
+		}
+	}
+	return shot, nil
+}
+
+func (s *Service) captureScreenshot(ctx context.Context, asin string, opts browser.ScreenshotOptions) (*Screenshot, error) {
+	url := fmt.Sprintf("https://www.amazon.de/dp/%s", asin)
+	s.logger.InfoContext(ctx, "capturing product screenshot", "url", url, "full_page", opts.FullPage, "format", opts.Format)
+
+	if err := s.ConsumeQuota(ctx, 1); err != nil {
+		return nil, err
+	}
+
+	page, err := s.browser.NewTaskPage(browser.TaskDefault)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create page: %w", err)
+	}
+	defer page.Close()
+	defer browser.ClosePageOnDone(ctx, page)()
+
+	if err := s.browser.NavigateWithRetryContext(ctx, page, url, 3); err != nil {
+		return nil, fmt.Errorf("failed to navigate: %w", err)
+	}
+
+	image, err := s.browser.Screenshot(page, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Screenshot{Image: image, Format: opts.Format, CapturedAt: time.Now()}, nil
+}
+
+func (s *Service) screenshotPath(asin string, opts browser.ScreenshotOptions) string {
+	if s.screenshotDir == "" || s.screenshotMaxAge <= 0 {
+		return ""
+	}
+	region := "viewport"
+	if opts.FullPage {
+		region = "full"
+	}
+	return filepath.Join(s.screenshotDir, fmt.Sprintf("%s-%s.%s", asin, region, opts.Format))
+}
+
+func writeScreenshot(path string, image []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, image, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
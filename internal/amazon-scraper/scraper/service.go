@@ -2,28 +2,199 @@ package scraper
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/url"
 	"strings"
 	"time"
 
 	"github.com/playwright-community/playwright-go"
+	"golang.org/x/sync/singleflight"
+	"github.com/maltedev/amazon-size-scraper/internal/asin"
 	"github.com/maltedev/amazon-size-scraper/internal/browser"
 	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/maltedev/amazon-size-scraper/internal/labels"
+	"github.com/maltedev/amazon-size-scraper/internal/numparse"
+	"github.com/maltedev/amazon-size-scraper/internal/ocr"
+	"github.com/maltedev/amazon-size-scraper/internal/paapi"
+	"github.com/maltedev/amazon-size-scraper/internal/quota"
+	"github.com/maltedev/amazon-size-scraper/internal/ratelimit"
+	"github.com/maltedev/amazon-size-scraper/internal/schedule"
+	"github.com/maltedev/amazon-size-scraper/internal/scrapeerr"
+	"github.com/maltedev/amazon-size-scraper/internal/stages"
+	logging "github.com/maltedev/amazon-size-scraper/pkg/logger"
 )
 
 type Service struct {
-	browser *browser.Browser
-	db      *database.DB
-	logger  *slog.Logger
+	browser    *browser.Browser
+	supervisor *browser.Supervisor
+	db         *database.DB
+	labels     *labels.Dictionary
+	validator  *database.SizeTableValidator
+	ocr        ocr.Engine
+	limiter    ratelimit.RateLimiter
+	schedule   *schedule.Schedule
+	stages     stages.Flags
+	policy     database.MeasurementPolicy
+	quota      *quota.Tracker
+	fallback   *paapi.Client // Basic product data when scraping is blocked, nil disables
+	timeout    time.Duration // Hard deadline per extraction task, 0 disables
+	logger     *slog.Logger
+
+	sizeCharts singleflight.Group // Concurrent extractions of the same product share one browser session
+
+	screenshotDir    string        // Archive of product screenshots, empty captures every request anew
+	screenshotMaxAge time.Duration // Archived screenshots older than this are captured again
 }
 
-func NewService(browser *browser.Browser, db *database.DB, logger *slog.Logger) *Service {
+// DefaultTaskTimeout bounds a single extraction task including retries and modal waits
+const DefaultTaskTimeout = 90 * time.Second
+
+// ErrTaskTimeout marks extractions aborted by the per-task deadline
+var ErrTaskTimeout = errors.New("task deadline exceeded")
+
+// Size table extraction failures
+var (
+	ErrNoSizeTable      = scrapeerr.ErrNoSizeTable
+	ErrMissingLength    = errors.New("size table missing required measurements")
+	ErrInvalidSizeTable = errors.New("size table failed validation")
+)
+
+// Failure categories reported for failed extractions
+const (
+	FailureTimeout = "timeout"
+	FailureAgeGate = "age_gate"
+	FailureSignIn  = "sign_in_required"
+	FailureError   = "error"
+)
+
+// FailureCategory classifies an extraction error, timeouts and interstitials that retries cannot get
+// past are kept apart from page failures
+func FailureCategory(err error) string {
+	switch {
+	case errors.Is(err, ErrTaskTimeout):
+		return FailureTimeout
+	case errors.Is(err, browser.ErrAgeVerification):
+		return FailureAgeGate
+	case errors.Is(err, browser.ErrSignInRequired):
+		return FailureSignIn
+	}
+	return FailureError
+}
+
+func NewService(b *browser.Browser, db *database.DB, logger *slog.Logger) *Service {
 	return &Service{
-		browser: browser,
-		db:      db,
-		logger:  logger.With("component", "scraper"),
+		browser:    b,
+		supervisor: browser.NewSupervisor(b, 1),
+		db:         db,
+		labels:     labels.New(labels.LocaleDE),
+		validator:  database.DefaultSizeTableValidator(),
+		policy:     database.DefaultMeasurementPolicy(),
+		timeout:    DefaultTaskTimeout,
+		logger:     logger.With("component", "scraper"),
+	}
+}
+
+// SetLabels sets the measurement label dictionary used to parse size tables
+func (s *Service) SetLabels(d *labels.Dictionary) {
+	s.labels = d
+}
+
+// SetValidator sets the rules used to validate extracted size tables
+func (s *Service) SetValidator(v *database.SizeTableValidator) {
+	s.validator = v
+}
+
+// SetRateLimiter sets the limiter shared by all page fetches against the marketplace
+func (s *Service) SetRateLimiter(l ratelimit.RateLimiter) {
+	s.limiter = l
+}
+
+// SetSchedule sets the time-of-day schedule, its per-marketplace rate limits replace the shared limiter
+func (s *Service) SetSchedule(sched *schedule.Schedule) {
+	s.schedule = sched
+}
+
+// SetStages sets which optional extraction stages product extractions run
+func (s *Service) SetStages(f stages.Flags) {
+	s.stages = f
+}
+
+// Stages returns the extraction stage flags, all stages are enabled unless set
+func (s *Service) Stages() stages.Flags {
+	return s.stages
+}
+
+// SetMeasurementPolicy sets which size tables product extractions accept
+func (s *Service) SetMeasurementPolicy(p database.MeasurementPolicy) {
+	s.policy = p
+}
+
+// MeasurementPolicy returns the measurement policy, length and chest are required unless set
+func (s *Service) MeasurementPolicy() database.MeasurementPolicy {
+	return s.policy
+}
+
+// Schedule returns the time-of-day schedule, nil when none is set
+func (s *Service) Schedule() *schedule.Schedule {
+	return s.schedule
+}
+
+// WaitRateLimit blocks until the rate limit allows the next request to target, the schedule's limit of
+// its marketplace when set, the shared limiter otherwise, no limiter means no wait
+func (s *Service) WaitRateLimit(ctx context.Context, target string) error {
+	if s.schedule != nil {
+		u, err := url.Parse(target)
+		if err != nil {
+			return fmt.Errorf("invalid url: %w", err)
+		}
+		marketplace := asin.Marketplace(u.Hostname())
+		if marketplace == "" {
+			marketplace = asin.DefaultMarketplace
+		}
+		return s.schedule.Wait(ctx, marketplace)
+	}
+	if s.limiter == nil {
+		return nil
+	}
+	return s.limiter.Wait(ctx)
+}
+
+// SetQuota sets the tracker charging page fetches against daily budgets, nil disables accounting
+func (s *Service) SetQuota(t *quota.Tracker) {
+	s.quota = t
+}
+
+// Quota returns the fetch budget tracker, nil when accounting is disabled
+func (s *Service) Quota() *quota.Tracker {
+	return s.quota
+}
+
+// ConsumeQuota charges n page fetches to the subject of the context, returning quota.ErrBudgetExceeded
+// when its daily budget is used up
+func (s *Service) ConsumeQuota(ctx context.Context, n int64) error {
+	if s.quota == nil {
+		return nil
+	}
+	return s.quota.Consume(ctx, n)
+}
+
+// ValidateSizeTable runs the configured validation rules against a size table, the measurement policy
+// decides which measurements are required
+func (s *Service) ValidateSizeTable(st *database.SizeTable) *database.ValidationReport {
+	if s.validator == nil {
+		s.validator = database.DefaultSizeTableValidator()
 	}
+	return s.validator.WithPolicy(s.MeasurementPolicy()).Validate(st)
+}
+
+// labelDictionary returns the configured label dictionary, defaulting to German
+func (s *Service) labelDictionary() *labels.Dictionary {
+	if s.labels == nil {
+		s.labels = labels.New(labels.LocaleDE)
+	}
+	return s.labels
 }
 
 // GetBrowser returns the browser instance
@@ -31,34 +202,102 @@ func (s *Service) GetBrowser() *browser.Browser {
 	return s.browser
 }
 
+// BrowserStats returns crash recovery counters of the supervised browser
+func (s *Service) BrowserStats() browser.SupervisorStats {
+	if s.supervisor == nil {
+		return browser.SupervisorStats{Connected: s.browser.IsConnected(), Restarts: s.browser.Restarts()}
+	}
+	return s.supervisor.Stats()
+}
+
+// SetTaskTimeout sets the hard deadline of a single extraction task, 0 disables it
+func (s *Service) SetTaskTimeout(d time.Duration) {
+	s.timeout = d
+}
+
+// RunTask runs task under the browser supervisor with the per-task deadline applied to its context.
+// A task cut off by the deadline returns an error wrapping ErrTaskTimeout.
+func (s *Service) RunTask(ctx context.Context, name string, task func(ctx context.Context) error) error {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	err := s.Supervise(ctx, name, func() error { return task(ctx) })
+	if errors.Is(err, context.DeadlineExceeded) {
+		s.logger.WarnContext(ctx, "task deadline exceeded", "task", name, "timeout", s.timeout)
+		return fmt.Errorf("%w after %s: %w", ErrTaskTimeout, s.timeout, err)
+	}
+	return err
+}
+
+// Supervise runs task under the browser supervisor so a crashed browser is relaunched and the task replayed
+func (s *Service) Supervise(ctx context.Context, name string, task func() error) error {
+	if s.supervisor == nil {
+		return task()
+	}
+	return s.supervisor.Run(ctx, name, task)
+}
+
 // Dimensions represents extracted product dimensions
 type Dimensions struct {
-	Found     bool
-	SizeTable *database.SizeTable
+	Found       bool
+	SizeTable   *database.SizeTable
+	Diagnostics *browser.Diagnostics
 }
 
 // ExtractSizeChart extracts size chart dimensions from a product page
 func (s *Service) ExtractSizeChart(ctx context.Context, asin, url string) (*Dimensions, error) {
+	if asin != "" {
+		ctx = logging.WithASIN(ctx, asin)
+	}
+	return s.coalesceSizeChart(ctx, sizeChartKey(asin, url), func(ctx context.Context) (*Dimensions, error) {
+		var dimensions *Dimensions
+		err := s.RunTask(ctx, "size_chart:"+asin, func(ctx context.Context) error {
+			var err error
+			dimensions, err = s.extractSizeChart(ctx, asin, url)
+			return err
+		})
+		return dimensions, err
+	})
+}
+
+// extractSizeChart performs a single size chart extraction attempt
+func (s *Service) extractSizeChart(ctx context.Context, asin, url string) (*Dimensions, error) {
 	// Construct URL if only ASIN is provided
 	if url == "" && asin != "" {
 		url = fmt.Sprintf("https://www.amazon.de/dp/%s", asin)
 	}
 
-	s.logger.Info("extracting size chart", "asin", asin, "url", url)
+	s.logger.InfoContext(ctx, "extracting size chart", "asin", asin, "url", url)
+
+	// The OCR fallback screenshots size chart images, so they must be loaded
+	task := browser.TaskSizeChart
+	if s.ocr != nil {
+		task = browser.TaskSizeChartOCR
+	}
+
+	if err := s.ConsumeQuota(ctx, 1); err != nil {
+		return nil, err
+	}
 
-	page, err := s.browser.NewPage()
+	page, err := s.browser.NewTaskPage(task)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create page: %w", err)
 	}
 	defer page.Close()
+	defer browser.ClosePageOnDone(ctx, page)()
 
 	// Navigate to product page
-	if err := s.browser.NavigateWithRetry(page, url, 3); err != nil {
+	if err := s.browser.NavigateWithRetryContext(ctx, page, url, 3); err != nil {
 		return nil, fmt.Errorf("failed to navigate: %w", err)
 	}
 
 	// Add human-like behavior
-	s.browser.HumanizeInteraction(page)
+	if err := s.browser.HumanizeTask(ctx, page, task); err != nil {
+		return nil, err
+	}
 
 	// Look for and click size table button
 	clicked, err := page.Evaluate(`() => {
@@ -102,56 +341,39 @@ func (s *Service) ExtractSizeChart(ctx context.Context, asin, url string) (*Dime
 	}`)
 
 	if err != nil || !clicked.(bool) {
-		s.logger.Warn("size table button not found", "asin", asin)
-		return &Dimensions{Found: false}, nil
+		s.logger.WarnContext(ctx, "size table button not found", "asin", asin)
+		return s.sizeChartFallback(ctx, page, asin), nil
 	}
 
-	// Wait for modal to appear
-	time.Sleep(3 * time.Second)
-
-	// Extract table data
-	tableData, err := page.Evaluate(`() => {
-		const tables = document.querySelectorAll('.a-popover-content table, .a-modal-content table, [id*="popover"] table');
-		if (tables.length === 0) return null;
-		
-		const table = tables[0];
-		const data = {
-			headers: [],
-			rows: []
-		};
-		
-		// Get all rows
-		for (let i = 0; i < table.rows.length; i++) {
-			const row = table.rows[i];
-			const rowData = [];
-			for (let j = 0; j < row.cells.length; j++) {
-				rowData.push(row.cells[j].textContent.trim());
-			}
-			
-			if (i === 0) {
-				data.headers = rowData;
-			} else {
-				data.rows.push(rowData);
-			}
+	// Wait for the chart in any registered layout instead of sleeping a fixed time
+	chart, err := s.browser.WaitForSizeChart(ctx, page)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
 		}
-		
-		return data;
-	}`)
+		s.logger.WarnContext(ctx, "size chart did not open", "asin", asin, "error", err)
+		return s.sizeChartFallback(ctx, page, asin), nil
+	}
 
+	tableData, err := chart.ExtractTable()
 	if err != nil || tableData == nil {
-		s.logger.Warn("failed to extract table data", "asin", asin, "error", err)
-		return &Dimensions{Found: false}, nil
+		s.logger.WarnContext(ctx, "failed to extract table data", "asin", asin, "error", err)
+		return s.sizeChartFallback(ctx, page, asin), nil
 	}
 
 	// Parse the complete size table
 	sizeTable := s.parseFullSizeTable(tableData)
+	if sizeTable != nil {
+		sizeTable.Source = database.SizeTableSourceHTML
+		sizeTable.Confidence = 1.0
+	}
 
 	dimensions := &Dimensions{
 		Found:     true,
 		SizeTable: sizeTable,
 	}
 
-	s.logger.Info("extracted dimensions", 
+	s.logger.InfoContext(ctx, "extracted dimensions", 
 		"asin", asin,
 		"hasSizeTable", sizeTable != nil,
 		"sizeCount", func() int {
@@ -165,6 +387,69 @@ func (s *Service) ExtractSizeChart(ctx context.Context, asin, url string) (*Dime
 	return dimensions, nil
 }
 
+// sizeChartFallback tries inline description and A+ tables, then OCR on size chart images, then
+// measurements mentioned in the description text, before reporting the extraction as failed
+func (s *Service) sizeChartFallback(ctx context.Context, page playwright.Page, asin string) *Dimensions {
+	if sizeTable := s.extractInlineSizeTable(page, asin); sizeTable != nil {
+		return &Dimensions{Found: true, SizeTable: sizeTable}
+	}
+	if sizeTable := s.extractSizeChartFromImages(ctx, page, asin); sizeTable != nil {
+		return &Dimensions{Found: true, SizeTable: sizeTable}
+	}
+	if sizeTable := s.extractDescriptionSizeTable(page, asin); sizeTable != nil {
+		return &Dimensions{Found: true, SizeTable: sizeTable}
+	}
+	return &Dimensions{Found: false, Diagnostics: s.captureFailure(page, asin)}
+}
+
+// captureFailure stores a screenshot and DOM snippet of the failed page, returning nil if capture is disabled
+func (s *Service) captureFailure(page playwright.Page, asin string) *browser.Diagnostics {
+	diag, err := s.browser.CaptureFailure(page, asin)
+	if err != nil {
+		s.logger.Warn("failed to capture diagnostics", "asin", asin, "error", err)
+	}
+	return diag
+}
+
+// GetProduct returns a product including its failure diagnostics
+func (s *Service) GetProduct(ctx context.Context, asin string) (*database.Product, error) {
+	return s.db.GetProduct(ctx, asin)
+}
+
+// GetProductGroup returns the canonical ASIN and linked duplicates of a product
+func (s *Service) GetProductGroup(ctx context.Context, asin string) (*database.ProductGroup, error) {
+	return s.db.GetProductGroup(ctx, asin)
+}
+
+// GetProductHistory returns the current version of a product, nil if it does not exist, and its
+// previous versions, newest first
+func (s *Service) GetProductHistory(ctx context.Context, asin string, limit, offset int) (*database.ProductVersion, []database.ProductVersion, error) {
+	current, err := s.db.GetCurrentProductVersion(ctx, asin)
+	if err != nil || current == nil {
+		return nil, nil, err
+	}
+	history, err := s.db.ListProductHistory(ctx, asin, limit, offset)
+	if err != nil {
+		return nil, nil, err
+	}
+	return current, history, nil
+}
+
+// GetProductVersionAt returns the version of a product that was valid at the given time
+func (s *Service) GetProductVersionAt(ctx context.Context, asin string, at time.Time) (*database.ProductVersion, error) {
+	return s.db.GetProductVersionAt(ctx, asin, at)
+}
+
+// ListSizeMeasurements returns normalized per-size rows for export
+func (s *Service) ListSizeMeasurements(ctx context.Context, asin string, limit, offset int) ([]database.SizeMeasurement, error) {
+	return s.db.ListSizeMeasurements(ctx, asin, limit, offset)
+}
+
+// ListSizeConversions returns stored international size mappings
+func (s *Service) ListSizeConversions(ctx context.Context, asin, system string, limit, offset int) ([]database.SizeConversion, error) {
+	return s.db.ListSizeConversions(ctx, asin, system, limit, offset)
+}
+
 // UNUSED - extractSizeTableWithXPath extracts size table data using XPath selectors
 func (s *Service) extractSizeTableWithXPath(page playwright.Page) (*database.SizeTable, error) {
 	// Find size table in popover/modal
@@ -185,16 +470,17 @@ func (s *Service) extractSizeTableWithXPath(page playwright.Page) (*database.Siz
 	var chestIndex, lengthIndex int = -1, -1
 	var sizeIndex int = 0 // Usually first column
 	
-	// Find column indices for "Brustumfang" and "Länge"
+	// Find column indices for chest and length
 	for i := 0; i < headerCount; i++ {
 		headerText, _ := headers.Nth(i).TextContent()
 		headerLower := strings.ToLower(headerText)
-		
-		if strings.Contains(headerLower, "brustumfang") {
+
+		switch key, _ := s.labelDictionary().Lookup(headerText); {
+		case key == labels.Chest:
 			chestIndex = i
-		} else if strings.Contains(headerLower, "länge") && !strings.Contains(headerLower, "armlänge") {
+		case key == labels.Length:
 			lengthIndex = i
-		} else if strings.Contains(headerLower, "größe") || strings.Contains(headerLower, "size") {
+		case strings.Contains(headerLower, "größe") || strings.Contains(headerLower, "size"):
 			sizeIndex = i
 		}
 	}
@@ -259,6 +545,12 @@ func max(a, b int) int {
 	return b
 }
 
+// ParseSizeTable parses the headers and rows returned by the size chart script, nil if they hold no
+// size table
+func (s *Service) ParseSizeTable(data interface{}) *database.SizeTable {
+	return s.parseFullSizeTable(data)
+}
+
 // parseFullSizeTable parses the JavaScript table data into a complete size table
 func (s *Service) parseFullSizeTable(data interface{}) *database.SizeTable {
 	sizeTable := &database.SizeTable{
@@ -286,12 +578,15 @@ func (s *Service) parseFullSizeTable(data interface{}) *database.SizeTable {
 	// Option 1: Sizes in first column, measurements in rows
 	// Option 2: Sizes in header row, measurements in columns
 	
+	// Children's charts name their sizes in the first header cell, e.g. "Alter" or "Körpergröße"
+	kidsHint, _ := labels.KidsSizeColumn(fmt.Sprintf("%v", headers[0]))
+
 	// Check if first row contains size labels
 	firstRowHasSizes := false
 	if len(headers) > 1 {
 		for i := 1; i < len(headers); i++ {
 			headerStr := fmt.Sprintf("%v", headers[i])
-			if isSizeLabel(headerStr) {
+			if _, _, ok := sizeLabel(headerStr, kidsHint); ok {
 				firstRowHasSizes = true
 				break
 			}
@@ -302,10 +597,20 @@ func (s *Service) parseFullSizeTable(data interface{}) *database.SizeTable {
 		// Sizes are in the header row
 		// Extract sizes from headers (skip first column which is usually the measurement type)
 		for i := 1; i < len(headers); i++ {
-			sizeStr := strings.TrimSpace(fmt.Sprintf("%v", headers[i]))
-			if isSizeLabel(sizeStr) {
+			if sizeStr, kidsSystem, ok := sizeLabel(fmt.Sprintf("%v", headers[i]), kidsHint); ok {
 				sizeTable.Sizes = append(sizeTable.Sizes, sizeStr)
 				sizeTable.Measurements[sizeStr] = make(map[string]float64)
+				if kidsSystem != "" {
+					sizeTable.AddConversion(sizeStr, kidsSystem, sizeStr)
+				}
+			}
+		}
+
+		// A size system in the first header cell names the system of the sizes themselves
+		if system, ok := labels.SizeSystem(fmt.Sprintf("%v", headers[0])); ok {
+			sizeTable.System = system
+			for _, size := range sizeTable.Sizes {
+				sizeTable.AddConversion(size, system, size)
 			}
 		}
 
@@ -316,18 +621,25 @@ func (s *Service) parseFullSizeTable(data interface{}) *database.SizeTable {
 				continue
 			}
 
-			measurementType := strings.ToLower(fmt.Sprintf("%v", rowData[0]))
-			measurementKey := ""
+			label := fmt.Sprintf("%v", rowData[0])
+
+			// Age and body height rows of children's charts, e.g. "Alter | 6-7 J. | 8-9 J."
+			if system, ok := labels.KidsSizeColumn(label); ok {
+				for i := 1; i < len(rowData) && i-1 < len(sizeTable.Sizes); i++ {
+					sizeTable.AddConversion(sizeTable.Sizes[i-1], system, kidsSizeValue(fmt.Sprintf("%v", rowData[i]), system))
+				}
+				continue
+			}
+
+			// Map localized measurement names to canonical keys
+			measurementKey, _ := s.labelDictionary().Lookup(label)
 
-			// Map German/English measurement names
-			if strings.Contains(measurementType, "brust") || strings.Contains(measurementType, "chest") {
-				measurementKey = "chest"
-			} else if strings.Contains(measurementType, "länge") || strings.Contains(measurementType, "length") {
-				measurementKey = "length"
-			} else if strings.Contains(measurementType, "schulter") || strings.Contains(measurementType, "shoulder") {
-				measurementKey = "shoulder"
-			} else if strings.Contains(measurementType, "ärmel") || strings.Contains(measurementType, "sleeve") {
-				measurementKey = "sleeve"
+			// Rows of other size systems, e.g. "EU | 48 | 50 | 52"
+			if system, ok := labels.SizeSystem(label); ok && measurementKey == "" {
+				for i := 1; i < len(rowData) && i-1 < len(sizeTable.Sizes); i++ {
+					sizeTable.AddConversion(sizeTable.Sizes[i-1], system, fmt.Sprintf("%v", rowData[i]))
+				}
+				continue
 			}
 
 			if measurementKey != "" {
@@ -345,22 +657,27 @@ func (s *Service) parseFullSizeTable(data interface{}) *database.SizeTable {
 		// Sizes are in the first column of each row
 		// Extract measurements from headers (skip first column)
 		measurementTypes := []string{}
+		sizeSystems := []string{}
 		for i := 1; i < len(headers); i++ {
-			headerStr := strings.ToLower(fmt.Sprintf("%v", headers[i]))
+			label := fmt.Sprintf("%v", headers[i])
+
+			// Age and body height columns of children's charts are size systems as well
+			system, kids := labels.KidsSizeColumn(label)
 			measurementKey := ""
+			if !kids {
+				measurementKey, _ = s.labelDictionary().Lookup(label)
+			}
 
-			if strings.Contains(headerStr, "brust") || strings.Contains(headerStr, "chest") {
-				measurementKey = "chest"
-			} else if strings.Contains(headerStr, "länge") || strings.Contains(headerStr, "length") {
-				measurementKey = "length"
-			} else if strings.Contains(headerStr, "schulter") || strings.Contains(headerStr, "shoulder") {
-				measurementKey = "shoulder"
-			} else if strings.Contains(headerStr, "ärmel") || strings.Contains(headerStr, "sleeve") {
-				measurementKey = "sleeve"
+			// Columns of other size systems, e.g. "US | M"
+			if measurementKey == "" && !kids {
+				system, _ = labels.SizeSystem(label)
 			}
 
 			measurementTypes = append(measurementTypes, measurementKey)
+			sizeSystems = append(sizeSystems, system)
 		}
+		ownSystem, _ := labels.SizeSystem(fmt.Sprintf("%v", headers[0]))
+		sizeTable.System = ownSystem
 
 		// Extract sizes and values from rows
 		for _, row := range rows {
@@ -369,10 +686,20 @@ func (s *Service) parseFullSizeTable(data interface{}) *database.SizeTable {
 				continue
 			}
 
-			sizeStr := strings.TrimSpace(fmt.Sprintf("%v", rowData[0]))
-			if isSizeLabel(sizeStr) {
+			if sizeStr, kidsSystem, ok := sizeLabel(fmt.Sprintf("%v", rowData[0]), kidsHint); ok {
 				sizeTable.Sizes = append(sizeTable.Sizes, sizeStr)
 				sizeTable.Measurements[sizeStr] = make(map[string]float64)
+				if ownSystem != "" {
+					sizeTable.AddConversion(sizeStr, ownSystem, sizeStr)
+				}
+				if kidsSystem != "" {
+					sizeTable.AddConversion(sizeStr, kidsSystem, sizeStr)
+				}
+				for i := 1; i < len(rowData) && i-1 < len(sizeSystems); i++ {
+					if sizeSystems[i-1] != "" {
+						sizeTable.AddConversion(sizeStr, sizeSystems[i-1], kidsSizeValue(fmt.Sprintf("%v", rowData[i]), sizeSystems[i-1]))
+					}
+				}
 
 				// Extract measurements for this size
 				for i := 1; i < len(rowData) && i-1 < len(measurementTypes); i++ {
@@ -391,10 +718,50 @@ func (s *Service) parseFullSizeTable(data interface{}) *database.SizeTable {
 	if len(sizeTable.Sizes) == 0 {
 		return nil
 	}
+	if sizeTable.System == "" {
+		sizeTable.System = sizeTableSystem(sizeTable.Sizes)
+	}
 
 	return sizeTable
 }
 
+// sizeLabel returns the label a size table cell is stored under and the children's size system it
+// belongs to, empty for letter sizes. hint is the children's size system named by the table, it lets
+// ages and heights without unit count.
+func sizeLabel(cell, hint string) (size, kidsSystem string, ok bool) {
+	cell = strings.TrimSpace(cell)
+	if isSizeLabel(cell) {
+		return cell, "", true
+	}
+	if system, canonical, ok := labels.KidsSize(cell, hint); ok {
+		return canonical, system, true
+	}
+	return "", "", false
+}
+
+// kidsSizeValue returns the canonical label of an age or height cell, other cells unchanged
+func kidsSizeValue(cell, system string) string {
+	if system != labels.SystemAge && system != labels.SystemHeight {
+		return cell
+	}
+	if _, canonical, ok := labels.KidsSize(cell, system); ok {
+		return canonical
+	}
+	return cell
+}
+
+// sizeTableSystem returns the size system of a table whose header names none: the children's size
+// system of its first size, or international letter sizes
+func sizeTableSystem(sizes []string) string {
+	if system, _, ok := labels.KidsSize(sizes[0], ""); ok {
+		return system
+	}
+	if isSizeLabel(sizes[0]) {
+		return labels.SystemINT
+	}
+	return ""
+}
+
 // ReviewData represents extracted review information
 type ReviewData struct {
 	Reviews       []ReviewInfo
@@ -402,6 +769,21 @@ type ReviewData struct {
 	TotalReviews  int
 }
 
+// Stored returns the reviews as they are stored in the database
+func (d *ReviewData) Stored() []database.Review {
+	reviews := make([]database.Review, len(d.Reviews))
+	for i, r := range d.Reviews {
+		reviews[i] = database.Review{
+			Rating:        r.Rating,
+			Title:         r.Title,
+			Text:          r.Text,
+			VerifiedBuyer: r.VerifiedBuyer,
+			Date:          r.Date,
+		}
+	}
+	return reviews
+}
+
 type ReviewInfo struct {
 	Rating         int
 	Title          string
@@ -414,21 +796,46 @@ type ReviewInfo struct {
 
 // ExtractReviews extracts product reviews from Amazon
 func (s *Service) ExtractReviews(ctx context.Context, asin, url string) (*ReviewData, error) {
+	if asin != "" {
+		ctx = logging.WithASIN(ctx, asin)
+	}
+	var reviews *ReviewData
+	err := s.RunTask(ctx, "reviews:"+asin, func(ctx context.Context) error {
+		var err error
+		reviews, err = s.extractReviews(ctx, asin, url)
+		return err
+	})
+	if err == nil && asin != "" && s.db != nil {
+		// Stored reviews are the input of the review summary
+		if err := s.db.ReplaceReviews(ctx, asin, reviews.Stored()); err != nil {
+			s.logger.WarnContext(ctx, "failed to store reviews", "asin", asin, "error", err)
+		}
+	}
+	return reviews, err
+}
+
+// extractReviews performs a single review extraction attempt
+func (s *Service) extractReviews(ctx context.Context, asin, url string) (*ReviewData, error) {
 	// Construct URL if only ASIN is provided
 	if url == "" && asin != "" {
 		url = fmt.Sprintf("https://www.amazon.de/dp/%s", asin)
 	}
 
-	s.logger.Info("extracting reviews", "asin", asin, "url", url)
+	s.logger.InfoContext(ctx, "extracting reviews", "asin", asin, "url", url)
+
+	if err := s.ConsumeQuota(ctx, 1); err != nil {
+		return nil, err
+	}
 
-	page, err := s.browser.NewPage()
+	page, err := s.browser.NewTaskPage(browser.TaskReviews)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create page: %w", err)
 	}
 	defer page.Close()
+	defer browser.ClosePageOnDone(ctx, page)()
 
 	// Navigate to product page
-	if err := s.browser.NavigateWithRetry(page, url, 3); err != nil {
+	if err := s.browser.NavigateWithRetryContext(ctx, page, url, 3); err != nil {
 		return nil, fmt.Errorf("failed to navigate: %w", err)
 	}
 
@@ -436,7 +843,9 @@ func (s *Service) ExtractReviews(ctx context.Context, asin, url string) (*Review
 	reviewsLink := page.Locator(`a[data-hook="see-all-reviews-link-foot"]`).First()
 	if count, _ := reviewsLink.Count(); count > 0 {
 		reviewsLink.Click()
-		time.Sleep(2 * time.Second)
+		if err := browser.Sleep(ctx, 2*time.Second); err != nil {
+			return nil, err
+		}
 	}
 
 	// Extract review data
@@ -506,7 +915,7 @@ func (s *Service) ExtractReviews(ctx context.Context, asin, url string) (*Review
 		result.TotalReviews = int(reviewMap["total_reviews"].(float64))
 	}
 
-	s.logger.Info("extracted reviews", 
+	s.logger.InfoContext(ctx, "extracted reviews", 
 		"asin", asin,
 		"count", len(result.Reviews),
 		"avg_rating", result.AverageRating,
@@ -528,33 +937,10 @@ func isSizeLabel(s string) bool {
 	return false
 }
 
-func parseValue(text string) float64 {
-	// Handle ranges (e.g., "84 - 94") by taking the maximum
-	if strings.Contains(text, "-") {
-		parts := strings.Split(text, "-")
-		if len(parts) == 2 {
-			val1 := parseValue(parts[0])
-			val2 := parseValue(parts[1])
-			if val2 > 0 {
-				return val2 // Take the maximum
-			}
-			return val1
-		}
-	}
-
-	// Extract numeric value
-	var numStr string
-	for _, r := range text {
-		if (r >= '0' && r <= '9') || r == '.' || r == ',' {
-			if r == ',' {
-				numStr += "."
-			} else {
-				numStr += string(r)
-			}
-		}
-	}
+// measurementValues reads size table cells of any marketplace, ranges (e.g. "84 - 94") count with
+// their upper bound
+var measurementValues = numparse.Parser{Locale: numparse.Auto, Range: numparse.RangeMax}
 
-	var result float64
-	fmt.Sscanf(numStr, "%f", &result)
-	return result
+func parseValue(text string) float64 {
+	return measurementValues.Value(text)
 }
\ No newline at end of file
@@ -1,6 +1,7 @@
 package scraper
 
 import (
+	"log/slog"
 	"testing"
 )
 
@@ -60,9 +61,9 @@ func TestParseValue(t *testing.T) {
 }
 
 func TestParseTableData(t *testing.T) {
-	s := &Service{}
+	s := NewService(nil, nil, slog.Default())
 
-	// Test horizontal layout (sizes in first row)
+	// Test horizontal layout (sizes in first column)
 	tableData := map[string]interface{}{
 		"headers": []interface{}{"Größe", "Brustumfang", "Länge"},
 		"rows": []interface{}{
@@ -72,13 +73,16 @@ func TestParseTableData(t *testing.T) {
 		},
 	}
 
-	dimensions := s.parseTableData(tableData)
+	sizeTable := s.parseFullSizeTable(tableData)
+	if sizeTable == nil {
+		t.Fatal("Expected a size table")
+	}
 
-	if dimensions.WidthCM == 0 {
-		t.Error("Expected width to be calculated from chest measurement")
+	if sizeTable.Measurements["M"]["chest"] == 0 {
+		t.Error("Expected chest to be extracted")
 	}
 
-	if dimensions.LengthCM == 0 {
+	if sizeTable.Measurements["M"]["length"] == 0 {
 		t.Error("Expected length to be extracted")
 	}
 }
@@ -95,18 +99,21 @@ func TestDimensionExtraction(t *testing.T) {
 		},
 	}
 
-	s := &Service{}
-	dimensions := s.parseTableData(tableData)
+	s := NewService(nil, nil, slog.Default())
+	sizeTable := s.parseFullSizeTable(tableData)
+	if sizeTable == nil {
+		t.Fatal("Expected a size table")
+	}
 
-	// Should use XL measurements (largest common size)
-	expectedWidth := 106.0 / 2 // Chest / 2
+	// Ranges keep their upper bound
+	expectedChest := 106.0
 	expectedLength := 76.0
 
-	if dimensions.WidthCM != expectedWidth {
-		t.Errorf("Expected width %v, got %v", expectedWidth, dimensions.WidthCM)
+	if got := sizeTable.Measurements["XL"]["chest"]; got != expectedChest {
+		t.Errorf("Expected chest %v, got %v", expectedChest, got)
 	}
 
-	if dimensions.LengthCM != expectedLength {
-		t.Errorf("Expected length %v, got %v", expectedLength, dimensions.LengthCM)
+	if got := sizeTable.Measurements["XL"]["length"]; got != expectedLength {
+		t.Errorf("Expected length %v, got %v", expectedLength, got)
 	}
-}
\ No newline at end of file
+}
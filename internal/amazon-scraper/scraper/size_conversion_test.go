@@ -0,0 +1,98 @@
+package scraper
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sizeChart(headers []string, rows ...[]string) map[string]interface{} {
+	data := map[string]interface{}{"headers": toInterfaces(headers)}
+	var r []interface{}
+	for _, row := range rows {
+		r = append(r, toInterfaces(row))
+	}
+	data["rows"] = r
+	return data
+}
+
+func TestParseFullSizeTable_Conversions(t *testing.T) {
+	s := NewService(nil, nil, slog.Default())
+
+	t.Run("size system rows", func(t *testing.T) {
+		st := s.parseFullSizeTable(sizeChart(
+			[]string{"Größe", "M", "L"},
+			[]string{"DE", "50", "52"},
+			[]string{"UK-Größe", "40", "42"},
+			[]string{"Länge (cm)", "78", "80"},
+			[]string{"Brustumfang (cm)", "102", "108"},
+		))
+		require.NotNil(t, st)
+		assert.Equal(t, map[string]string{"DE": "50", "UK": "40"}, st.Conversions["M"])
+		assert.Equal(t, "52", st.Conversions["L"]["DE"])
+		assert.Equal(t, 78.0, st.Measurements["M"]["length"])
+		assert.Len(t, st.Measurements["M"], 2, "size system rows are no measurements")
+	})
+
+	t.Run("size system columns", func(t *testing.T) {
+		st := s.parseFullSizeTable(sizeChart(
+			[]string{"US", "EU", "Size (UK)", "Länge (cm)", "Brustumfang (cm)"},
+			[]string{"M", "50", "40", "78", "102"},
+			[]string{"L", "52", "42", "80", "108"},
+		))
+		require.NotNil(t, st)
+		assert.Equal(t, []string{"M", "L"}, st.Sizes)
+		assert.Equal(t, map[string]string{"US": "M", "EU": "50", "UK": "40"}, st.Conversions["M"])
+		assert.Equal(t, 108.0, st.Measurements["L"]["chest"])
+	})
+
+	t.Run("no size systems", func(t *testing.T) {
+		st := s.parseFullSizeTable(sizeChart(
+			[]string{"Größe", "Länge (cm)"},
+			[]string{"M", "78"},
+		))
+		require.NotNil(t, st)
+		assert.Nil(t, st.Conversions)
+	})
+}
+
+func TestParseFullSizeTable_KidsSizes(t *testing.T) {
+	s := NewService(nil, nil, slog.Default())
+
+	t.Run("age columns in header row", func(t *testing.T) {
+		st := s.parseFullSizeTable(sizeChart(
+			[]string{"Alter", "6-7 Jahre", "8-9 Jahre"},
+			[]string{"Körpergröße (cm)", "116-122", "128-134"},
+			[]string{"Brustumfang (cm)", "64", "68"},
+		))
+		require.NotNil(t, st)
+		assert.Equal(t, []string{"6-7Y", "8-9Y"}, st.Sizes)
+		assert.Equal(t, "AGE", st.System)
+		assert.Equal(t, map[string]string{"AGE": "6-7Y", "HEIGHT": "116-122cm"}, st.Conversions["6-7Y"])
+		assert.Equal(t, 68.0, st.Measurements["8-9Y"]["chest"])
+	})
+
+	t.Run("height sizes in first column", func(t *testing.T) {
+		st := s.parseFullSizeTable(sizeChart(
+			[]string{"Körpergröße", "Alter", "Länge (cm)"},
+			[]string{"116", "6 J.", "52"},
+			[]string{"128", "8 J.", "56"},
+		))
+		require.NotNil(t, st)
+		assert.Equal(t, []string{"116cm", "128cm"}, st.Sizes)
+		assert.Equal(t, "HEIGHT", st.System)
+		assert.Equal(t, "8Y", st.Conversions["128cm"]["AGE"])
+		assert.Equal(t, 56.0, st.Measurements["128cm"]["length"])
+	})
+
+	t.Run("letter sizes", func(t *testing.T) {
+		st := s.parseFullSizeTable(sizeChart(
+			[]string{"Größe", "Länge (cm)"},
+			[]string{"M", "78"},
+		))
+		require.NotNil(t, st)
+		assert.Equal(t, "INT", st.System)
+	})
+}
@@ -0,0 +1,100 @@
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/maltedev/amazon-size-scraper/internal/currency"
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/playwright-community/playwright-go"
+)
+
+// sizeOption is a size of the variation selector as rendered, e.g. "M" / "19,99 €"
+type sizeOption struct {
+	Size        string `json:"size"`
+	Price       string `json:"price"`
+	ASIN        string `json:"asin"`
+	Unavailable bool   `json:"unavailable"`
+}
+
+// sizeOptionsScript reads the size swatches of the classic and the inline twister and the size dropdown.
+// Dropdown options carry no price, their child ASIN is the second part of the value.
+const sizeOptionsScript = `() => {
+	const text = el => (el && el.textContent || '').trim();
+	const options = [];
+	const swatches = document.querySelectorAll(
+		'#variation_size_name li, #inline-twister-expander-content-size_name li, #tp-inline-twister-dim-values-container li');
+	for (const li of swatches) {
+		const size = text(li.querySelector('.swatch-title-text, .twisterTextDiv, .a-button-text, p'));
+		const price = text(li.querySelector('.twisterSwatchPrice, .twister_swatch_price, .a-price .a-offscreen, .a-size-mini'));
+		const asin = li.getAttribute('data-asin') || li.getAttribute('data-defaultasin') || '';
+		const unavailable = /swatchUnavailable|unavailable/i.test(li.className);
+		options.push({size, price, asin, unavailable});
+	}
+	if (options.length === 0) {
+		for (const option of document.querySelectorAll('select#native_dropdown_selected_size_name option')) {
+			const value = option.getAttribute('value') || '';
+			if (value === '-1') continue;
+			options.push({
+				size: text(option),
+				price: '',
+				asin: value.split(',')[1] || '',
+				unavailable: option.classList.contains('dropdownUnavailable'),
+			});
+		}
+	}
+	return JSON.stringify(options);
+}`
+
+// extractSizePrices reads price and availability of each size variant
+func (pe *ProductExtractor) extractSizePrices(page playwright.Page, product *CompleteProduct) error {
+	result, err := page.Evaluate(sizeOptionsScript)
+	if err != nil {
+		return fmt.Errorf("failed to read size options: %w", err)
+	}
+	data, ok := result.(string)
+	if !ok {
+		return nil
+	}
+
+	var options []sizeOption
+	if err := json.Unmarshal([]byte(data), &options); err != nil {
+		return fmt.Errorf("failed to decode size options: %w", err)
+	}
+
+	product.SizePrices = parseSizePrices(options, product.CurrentPrice, product.Currency, currency.ForMarketplace(page.URL()))
+	return nil
+}
+
+// parseSizePrices builds the size offers, nil if the product has no size variants. Sizes without a price
+// of their own are offered at the product price.
+func parseSizePrices(options []sizeOption, price *float64, cur, fallback string) database.SizePrices {
+	if cur == "" {
+		cur = fallback
+	}
+
+	prices := make(database.SizePrices)
+	for _, option := range options {
+		size := strings.Join(strings.Fields(option.Size), " ")
+		if size == "" || size == "Größe auswählen" {
+			continue
+		}
+
+		offer := database.SizeOffer{
+			Available: !option.Unavailable,
+			ASIN:      strings.TrimSpace(option.ASIN),
+		}
+		if amount, c := currency.Parse(option.Price, cur); amount > 0 {
+			offer.Price, offer.Currency = amount, c
+		} else if price != nil && offer.Available {
+			offer.Price, offer.Currency = *price, cur
+		}
+		prices[size] = offer
+	}
+
+	if len(prices) == 0 {
+		return nil
+	}
+	return prices
+}
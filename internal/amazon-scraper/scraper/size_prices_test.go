@@ -0,0 +1,37 @@
+package scraper
+
+import (
+	"testing"
+
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSizePrices(t *testing.T) {
+	price := 24.99
+	options := []sizeOption{
+		{Size: " S ", Price: "19,99 €", ASIN: "B0SIZES001"},
+		{Size: "M", Price: "", ASIN: "B0SIZES002"},
+		{Size: "XL", Price: "", ASIN: "B0SIZES003", Unavailable: true},
+		{Size: "Größe auswählen"},
+		{Size: ""},
+	}
+
+	prices := parseSizePrices(options, &price, "EUR", "EUR")
+	assert.Equal(t, database.SizePrices{
+		"S":  {Price: 19.99, Currency: "EUR", Available: true, ASIN: "B0SIZES001"},
+		"M":  {Price: 24.99, Currency: "EUR", Available: true, ASIN: "B0SIZES002"},
+		"XL": {Available: false, ASIN: "B0SIZES003"},
+	}, prices)
+}
+
+func TestParseSizePrices_MarketplaceCurrency(t *testing.T) {
+	prices := parseSizePrices([]sizeOption{{Size: "L", Price: "£15.50"}, {Size: "XL", Price: "16.00"}}, nil, "", "GBP")
+	assert.Equal(t, 15.5, prices["L"].Price)
+	assert.Equal(t, "GBP", prices["L"].Currency)
+	assert.Equal(t, "GBP", prices["XL"].Currency)
+}
+
+func TestParseSizePrices_NoVariants(t *testing.T) {
+	assert.Nil(t, parseSizePrices(nil, nil, "EUR", "EUR"))
+}
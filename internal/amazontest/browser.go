@@ -0,0 +1,37 @@
+package amazontest
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/browser"
+)
+
+// RequireBrowserEnv makes NewBrowser fail instead of skip when no browser can be launched, set it in CI
+const RequireBrowserEnv = "AMAZONTEST_REQUIRE_BROWSER"
+
+// NewBrowser launches a headless browser for tests against the server.
+// The test is skipped when Playwright or its browsers are not installed.
+func NewBrowser(t testing.TB) *browser.Browser {
+	t.Helper()
+
+	if testing.Short() {
+		t.Skip("skipping browser test in short mode")
+	}
+
+	opts := browser.DefaultOptions()
+	opts.Timeout = 15 * time.Second
+	opts.DiagnosticsDir = ""
+
+	b, err := browser.New(opts)
+	if err != nil {
+		if os.Getenv(RequireBrowserEnv) != "" {
+			t.Fatalf("failed to launch browser: %v", err)
+		}
+		t.Skipf("browser not available (run go run github.com/playwright-community/playwright-go/cmd/playwright install chromium): %v", err)
+	}
+	t.Cleanup(func() { b.Close() })
+
+	return b
+}
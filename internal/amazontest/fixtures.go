@@ -0,0 +1,118 @@
+package amazontest
+
+// Fixture ASINs served by DefaultProducts
+const (
+	ASINVerticalChart   = "B0TEST0001" // Sizes in the header row
+	ASINHorizontalChart = "B0TEST0002" // Sizes in the first column
+	ASINNoChart         = "B0TEST0003" // No Größentabelle link
+	ASINNoLength        = "B0TEST0004" // Size chart without length
+)
+
+// SellerTallFit sells the TallFit fixtures of DefaultProducts
+const SellerTallFit = "A1TALLFIT0SELLER"
+
+// Fixture ASINs served by InlineChartProducts
+const (
+	ASINDescriptionChart = "B0TEST0005" // Size table inline in the product description
+	ASINAPlusChart       = "B0TEST0006" // Size table in A+ content
+)
+
+// DefaultProducts returns the canned men's shirts the server starts with
+func DefaultProducts() []Product {
+	return []Product{
+		{
+			ASIN:        ASINVerticalChart,
+			Title:       "Tall T-Shirt Herren extra lang aus Baumwolle",
+			Brand:       "TallFit",
+			Seller:      SellerTallFit,
+			Price:       "24,99 €",
+			Rating:      "4,5 von 5 Sternen",
+			ReviewCount: "1.234 Sternebewertungen",
+			Images:      []string{"/images/I/" + ASINVerticalChart + "._AC_US40_.jpg"},
+			Features:    []string{"Extra lange Passform für große Männer", "100% Baumwolle"},
+			Sizes:       []string{"S", "M", "L", "XL"},
+			Material:    "100% Baumwolle",
+			FitSummary:  "Fällt normal aus",
+			FitRatings:  "Basierend auf 312 Bewertungen",
+			FitHistogram: [][2]string{
+				{"Zu klein", "8 %"}, {"Etwas zu klein", "10 %"}, {"Wie erwartet", "71 %"},
+				{"Etwas zu groß", "7 %"}, {"Zu groß", "4 %"},
+			},
+			SizeChart: SizeChart{
+				{"Größe", "S", "M", "L", "XL"},
+				{"Länge (cm)", "76", "78", "80", "82"},
+				{"Brustumfang (cm)", "96", "102", "108", "114"},
+				{"Ärmellänge (cm)", "21", "22", "23", "24"},
+			},
+		},
+		{
+			ASIN:        ASINHorizontalChart,
+			Title:       "Langes Shirt Herren Rundhals",
+			Brand:       "LongLine",
+			Price:       "19,95 €",
+			Rating:      "4,1 von 5 Sternen",
+			ReviewCount: "87 Sternebewertungen",
+			Sizes:       []string{"M", "L", "XL"},
+			SizeChart: SizeChart{
+				{"Größe", "Brustumfang (cm)", "Länge (cm)"},
+				{"M", "100", "79"},
+				{"L", "106", "81"},
+				{"XL", "112", "83"},
+			},
+		},
+		{
+			ASIN:        ASINNoChart,
+			Title:       "Basic T-Shirt Herren",
+			Brand:       "Basics",
+			Price:       "9,99 €",
+			Rating:      "3,9 von 5 Sternen",
+			ReviewCount: "15 Sternebewertungen",
+			Sizes:       []string{"S", "M", "L"},
+		},
+		{
+			ASIN:   ASINNoLength,
+			Title:  "Poloshirt Herren Tall",
+			Brand:  "TallFit",
+			Seller: SellerTallFit,
+			Price:  "29,99 €",
+			Sizes:  []string{"M", "L"},
+			Rating: "4,0 von 5 Sternen",
+			SizeChart: SizeChart{
+				{"Größe", "M", "L"},
+				{"Brustumfang (cm)", "100", "104"},
+			},
+		},
+	}
+}
+
+// InlineChartProducts returns products whose size table is not behind the Größentabelle link,
+// they are kept out of DefaultProducts so search and pagination fixtures stay stable
+func InlineChartProducts() []Product {
+	return []Product{
+		{
+			ASIN:  ASINDescriptionChart,
+			Title: "Extra langes Longsleeve Herren",
+			Brand: "TallFit",
+			Price: "34,99 €",
+			Sizes: []string{"L", "XL", "XXL"},
+			DescriptionSizeChart: SizeChart{
+				{"Größe", "L", "XL", "XXL"},
+				{"Länge (cm)", "84", "86", "88"},
+				{"Brustumfang (cm)", "108", "114", "120"},
+			},
+		},
+		{
+			ASIN:   ASINAPlusChart,
+			Title:  "Tall Hoodie Herren",
+			Brand:  "LongLine",
+			Price:  "49,95 €",
+			Sizes:  []string{"M", "L"},
+			Images: []string{"/images/I/" + ASINAPlusChart + "._AC_US40_.jpg"},
+			APlusSizeChart: SizeChart{
+				{"Größe", "Brustumfang (cm)", "Länge (cm)"},
+				{"M", "104", "77"},
+				{"L", "110", "79"},
+			},
+		},
+	}
+}
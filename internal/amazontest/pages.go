@@ -0,0 +1,187 @@
+package amazontest
+
+import "html/template"
+
+// The markup mirrors the selectors the crawlers and extractors rely on, not the full amazon.de pages
+
+var homePage = template.Must(template.New("home").Parse(`<!DOCTYPE html>
+<html lang="de-de">
+<head><meta charset="utf-8"><title>Amazon.de: Günstige Preise für Elektronik &amp; Foto, Filme, Musik, Bücher, Games, Spielzeug &amp; mehr</title></head>
+<body>
+<div id="nav-main"><form action="/s" method="get"><input id="twotabsearchtextbox" name="k" type="text"><input type="submit" value="Los"></form></div>
+</body>
+</html>`))
+
+var searchPage = template.Must(template.New("search").Parse(`<!DOCTYPE html>
+<html lang="de-de">
+<head><meta charset="utf-8"><title>Amazon.de : {{.Query}}</title></head>
+<body>
+<div class="s-main-slot s-result-list">
+{{range .Results}}
+	<div data-component-type="s-search-result" data-asin="{{.ASIN}}" class="s-result-item">
+		<div class="s-line-clamp-1"><span class="a-size-base-plus s-size-override-12">{{.Brand}}</span></div>
+		<h2><a class="a-link-normal s-link-style" href="/dp/{{.ASIN}}"><span>{{.Title}}</span></a></h2>
+		{{if .Rating}}<i class="a-icon a-icon-star-small"><span class="a-icon-alt">{{.Rating}}</span></i>{{end}}
+		{{if .ReviewCount}}<a href="/dp/{{.ASIN}}#customerReviews"><span aria-label="{{.ReviewCount}} Bewertungen" class="s-underline-text">{{.ReviewCount}}</span></a>{{end}}
+		{{if .Price}}<span class="a-price"><span class="a-offscreen">{{.Price}}</span></span>{{end}}
+	</div>
+{{end}}
+</div>
+<div class="s-pagination-container s-pagination-strip">
+{{range .Pages}}
+	{{if .Current}}<span class="s-pagination-item s-pagination-selected">{{.Number}}</span>{{else}}<a class="s-pagination-item s-pagination-button" href="{{.URL}}">{{.Number}}</a>{{end}}
+{{end}}
+{{if .NextURL}}
+	<a class="s-pagination-item s-pagination-next s-pagination-button" href="{{.NextURL}}">Weiter</a>
+{{else}}
+	<span class="s-pagination-item s-pagination-next s-pagination-disabled" aria-disabled="true">Weiter</span>
+{{end}}
+</div>
+</body>
+</html>`))
+
+var productPage = template.Must(template.New("product").Parse(`<!DOCTYPE html>
+<html lang="de-de">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} : Amazon.de: Fashion</title>
+<style>
+	.a-popover { display: none; position: fixed; top: 10%; left: 10%; background: #fff; border: 1px solid #888; }
+	.a-popover.a-popover-visible { display: block; }
+</style>
+</head>
+<body>
+<div id="dp-container">
+	<div id="wayfinding-breadcrumbs_feature_div"><a href="#">Fashion</a> › <a href="#">Herren</a> › <a href="#">T-Shirts</a></div>
+	<div id="imageBlock">
+		{{if .Images}}<img id="landingImage" src="{{index .Images 0}}" alt="">{{end}}
+		<div id="altImages">{{range .Images}}<img src="{{.}}" alt="">{{end}}</div>
+	</div>
+	<h1><span id="productTitle" class="a-size-large">{{.Title}}</span></h1>
+	<a id="bylineInfo" href="#">Marke: {{.Brand}}</a>
+	{{if .Rating}}<span id="acrPopover"><i class="a-icon a-icon-star"><span class="a-icon-alt">{{.Rating}}</span></i></span>{{end}}
+	{{if .ReviewCount}}<span id="acrCustomerReviewText">{{.ReviewCount}}</span>{{end}}
+	{{if .Price}}<span class="a-price"><span class="a-price-whole">{{.Price}}</span></span>{{end}}
+	{{if .FitSummary}}
+	<div id="fitRecommendationsSection">
+		<span class="a-size-base">Passform:</span> <span class="a-text-bold">{{.FitSummary}}</span>
+		<span class="a-color-secondary">{{.FitRatings}}</span>
+		<table id="fit-histogram-table">
+		{{range .FitHistogram}}<tr><td>{{index . 0}}</td><td><div class="a-meter"></div></td><td>{{index . 1}}</td></tr>{{end}}
+		</table>
+	</div>
+	{{end}}
+	{{if .Sizes}}
+	<select id="native_dropdown_selected_size_name">
+		<option>Größe auswählen</option>
+		{{range .Sizes}}<option>{{.}}</option>{{end}}
+	</select>
+	{{end}}
+	{{if .SizeChart}}
+	<a id="size-chart-link" href="#" onclick="document.getElementById('a-popover-sizeChart').classList.add('a-popover-visible'); return false;">Größentabelle</a>
+	<div id="a-popover-sizeChart" class="a-popover">
+		<div class="a-popover-content">
+			<table class="a-bordered">
+			{{range .SizeChart}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>{{end}}
+			</table>
+		</div>
+	</div>
+	{{end}}
+	{{if .DescriptionSizeChart}}
+	<div id="productDescription_feature_div"><div id="productDescription">
+		<p>Unsere Shirts sind extra lang geschnitten.</p>
+		<table>
+		{{range .DescriptionSizeChart}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>{{end}}
+		</table>
+	</div></div>
+	{{end}}
+	{{if .APlusSizeChart}}
+	<div id="aplus_feature_div"><div id="aplus" class="aplus-v2">
+		<div class="aplus-module aplus-standard"><table><tr><td><img src="/images/I/aplus-banner.jpg" alt=""></td><td><h3>Perfekte Passform</h3></td></tr></table></div>
+		<div class="aplus-module aplus-standard">
+			<h3>Größenratgeber</h3>
+			<table class="aplus-tech-spec-table">
+			{{range .APlusSizeChart}}<tr>{{range .}}<th>{{.}}</th>{{end}}</tr>{{end}}
+			</table>
+		</div>
+	</div></div>
+	{{end}}
+	<div id="feature-bullets"><ul>{{range .Features}}<li><span class="a-list-item">{{.}}</span></li>{{end}}</ul></div>
+	{{if .Material}}
+	<div class="a-fixed-left-grid"><div class="a-fixed-left-grid-inner">
+		<div class="a-col-left"><span class="a-color-base">Materialzusammensetzung</span></div>
+		<div class="a-col-right"><span class="a-color-base">{{.Material}}</span></div>
+	</div></div>
+	{{end}}
+</div>
+</body>
+</html>`))
+
+// botCheckPage is the soft interstitial the browser package bypasses with "Weiter shoppen"
+var botCheckPage = template.Must(template.New("botcheck").Parse(`<!DOCTYPE html>
+<html lang="de-de">
+<head><meta charset="utf-8"><title>Amazon.de</title></head>
+<body>
+<div class="a-box-inner">
+	<h4>Klicke auf die Schaltfläche unten, um mit dem Einkaufen fortzufahren</h4>
+	<form method="post" action="/botcheck">
+		<input type="hidden" name="return" value="{{.Return}}">
+		<span class="a-button a-button-primary"><button type="submit" class="a-button-text">Weiter shoppen</button></span>
+	</form>
+</div>
+</body>
+</html>`))
+
+// captchaPage is the hard robot check that cannot be bypassed
+var captchaPage = template.Must(template.New("captcha").Parse(`<!DOCTYPE html>
+<html lang="de-de">
+<head><meta charset="utf-8"><title>Robot Check</title></head>
+<body>
+<div class="a-box-inner">
+	<h4>Geben Sie die angezeigten Zeichen im Bild ein</h4>
+	<form method="get" action="/errors/validateCaptcha">
+		<img src="data:image/gif;base64,R0lGODlhAQABAAAAACw=" alt="captcha">
+		<input id="captchacharacters" name="field-keywords" type="text">
+		<button type="submit">Weiter</button>
+	</form>
+</div>
+</body>
+</html>`))
+
+var notFoundPage = template.Must(template.New("notfound").Parse(`<!DOCTYPE html>
+<html lang="de-de">
+<head><meta charset="utf-8"><title>Seite wurde nicht gefunden</title></head>
+<body><p>Suchen Sie bestimmte Informationen? Die Webadresse, die Sie eingegeben haben, ist keine funktionierende Seite auf unserer Website.</p></body>
+</html>`))
+
+// storePage is a brand store page, the last tile is only added to the grid once the page is scrolled
+var storePage = template.Must(template.New("store").Parse(`<!DOCTYPE html>
+<html lang="de-de">
+<head><meta charset="utf-8"><title>Amazon.de: {{.Brand}}</title></head>
+<body>
+<nav data-testid="navigation" aria-label="Store-Navigation">
+	<ul>{{range .Nav}}<li><a href="{{.URL}}">{{.Name}}</a></li>{{end}}</ul>
+</nav>
+<ul data-testid="product-grid" id="product-grid">
+{{range .Tiles}}
+	<li data-testid="product-grid-item">
+		<a href="/{{.Brand}}-Shirt/dp/{{.ASIN}}?ref_=ast_sto_dp"><img src="/images/I/{{.ASIN}}._AC_SR230_.jpg" alt=""></a>
+		<a href="/{{.Brand}}-Shirt/dp/{{.ASIN}}?ref_=ast_sto_dp"><span data-testid="product-grid-title">{{.Title}}</span></a>
+		{{if .Price}}<span class="a-price"><span class="a-offscreen">{{.Price}}</span></span>{{end}}
+	</li>
+{{end}}
+</ul>
+<div style="height: 3000px"></div>
+<template id="lazy-tile">
+	<li data-testid="product-grid-item">
+		<a href="/{{.Lazy.Brand}}-Shirt/dp/{{.Lazy.ASIN}}?ref_=ast_sto_dp"><span data-testid="product-grid-title">{{.Lazy.Title}}</span></a>
+	</li>
+</template>
+<script>
+	window.addEventListener('scroll', function load() {
+		window.removeEventListener('scroll', load);
+		document.getElementById('product-grid').appendChild(document.getElementById('lazy-tile').content.cloneNode(true));
+	});
+</script>
+</body>
+</html>`))
@@ -0,0 +1,28 @@
+package amazontest
+
+import "io"
+
+// RenderProductPage writes the detail page the server serves for p, e.g. as parser benchmark input
+func RenderProductPage(w io.Writer, p Product) error {
+	return productPage.Execute(w, p)
+}
+
+// TableData converts the chart to the headers and rows the size chart script of the scraper returns
+func (c SizeChart) TableData() map[string]interface{} {
+	if len(c) == 0 {
+		return nil
+	}
+	rows := make([]interface{}, 0, len(c)-1)
+	for _, row := range c[1:] {
+		rows = append(rows, cells(row))
+	}
+	return map[string]interface{}{"headers": cells(c[0]), "rows": rows}
+}
+
+func cells(row []string) []interface{} {
+	out := make([]interface{}, len(row))
+	for i, cell := range row {
+		out[i] = cell
+	}
+	return out
+}
@@ -0,0 +1,316 @@
+// Package amazontest serves canned Amazon.de-like pages so crawlers and
+// extractors can run against a real headless browser without touching amazon.de.
+package amazontest
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// SizeChart is the table shown in the Größentabelle popover, the first row is the header
+type SizeChart [][]string
+
+// Product is a canned product detail page
+type Product struct {
+	ASIN        string
+	Title       string
+	Brand       string
+	Seller      string // Seller ID of the /s?me= storefront
+	Price       string // e.g. "24,99 €"
+	Rating      string // e.g. "4,5 von 5 Sternen"
+	ReviewCount string // e.g. "1.234 Sternebewertungen"
+	Images      []string
+	Features    []string
+	Sizes       []string
+	Material    string
+	SizeChart   SizeChart // nil renders a page without Größentabelle link
+
+	FitSummary   string      // "Passform" widget, e.g. "Fällt normal aus"; empty renders no widget
+	FitRatings   string      // e.g. "Basierend auf 312 Bewertungen"
+	FitHistogram [][2]string // Histogram rows of label and percentage
+
+	DescriptionSizeChart SizeChart // Rendered inline in #productDescription
+	APlusSizeChart       SizeChart // Rendered in an A+ content module
+}
+
+// Server is an httptest server serving search results, product pages and bot checks
+type Server struct {
+	*httptest.Server
+
+	mu       sync.RWMutex
+	products []Product
+	pageSize int
+
+	botChecks atomic.Int32 // Remaining requests answered with the "Weiter shoppen" interstitial
+	blocked   atomic.Bool  // Answer every request with the hard captcha page
+	requests  atomic.Int64
+}
+
+// NewServer starts a server seeded with DefaultProducts
+func NewServer() *Server {
+	s := &Server{
+		products: DefaultProducts(),
+		pageSize: 2,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleHome)
+	mux.HandleFunc("/s", s.handleSearch)
+	mux.HandleFunc("/dp/", s.handleProduct)
+	mux.HandleFunc("/stores/", s.handleStore)
+	mux.HandleFunc("/botcheck", s.handleBotCheckSubmit)
+	s.Server = httptest.NewServer(s.guard(mux))
+
+	return s
+}
+
+// AddProduct adds or replaces a product page
+func (s *Server) AddProduct(p Product) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.products {
+		if s.products[i].ASIN == p.ASIN {
+			s.products[i] = p
+			return
+		}
+	}
+	s.products = append(s.products, p)
+}
+
+// SetPageSize sets the number of results per search page
+func (s *Server) SetPageSize(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pageSize = n
+}
+
+// ServeBotChecks answers the next n page requests with the "Weiter shoppen" interstitial
+func (s *Server) ServeBotChecks(n int) {
+	s.botChecks.Store(int32(n))
+}
+
+// SetBlocked answers every request with the captcha page until reset
+func (s *Server) SetBlocked(blocked bool) {
+	s.blocked.Store(blocked)
+}
+
+// Requests returns the number of requests served
+func (s *Server) Requests() int64 {
+	return s.requests.Load()
+}
+
+// SearchURL returns the search results URL for a query
+func (s *Server) SearchURL(query string) string {
+	return fmt.Sprintf("%s/s?k=%s", s.URL, url.QueryEscape(query))
+}
+
+// ProductURL returns the product detail URL for an ASIN
+func (s *Server) ProductURL(asin string) string {
+	return fmt.Sprintf("%s/dp/%s", s.URL, asin)
+}
+
+// StoreURL returns the home page URL of a brand store
+func (s *Server) StoreURL(brand string) string {
+	return fmt.Sprintf("%s/stores/%s/page/%s", s.URL, url.PathEscape(brand), storePageID(1))
+}
+
+// SellerURL returns the seller profile URL, its products are listed at /s?me=<seller>
+func (s *Server) SellerURL(seller string) string {
+	return fmt.Sprintf("%s/sp?seller=%s", s.URL, url.QueryEscape(seller))
+}
+
+// guard serves captcha and bot check pages before the real handlers
+func (s *Server) guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+
+		if s.blocked.Load() {
+			render(w, http.StatusOK, captchaPage, nil)
+			return
+		}
+		if isPage(r.URL.Path) && s.botChecks.Load() > 0 && s.botChecks.Add(-1) >= 0 {
+			render(w, http.StatusOK, botCheckPage, map[string]string{"Return": r.URL.RequestURI()})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleHome(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	render(w, http.StatusOK, homePage, nil)
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	query := params.Get("k")
+	seller := params.Get("me")
+	page, _ := strconv.Atoi(params.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	s.mu.RLock()
+	var matches []Product
+	for _, p := range s.products {
+		if (query == "" || matchesQuery(p, query)) && (seller == "" || p.Seller == seller) {
+			matches = append(matches, p)
+		}
+	}
+	pageSize := s.pageSize
+	s.mu.RUnlock()
+
+	start := (page - 1) * pageSize
+	end := start + pageSize
+	if start > len(matches) {
+		start = len(matches)
+	}
+	if end > len(matches) {
+		end = len(matches)
+	}
+
+	pageURL := func(n int) string {
+		params.Set("page", strconv.Itoa(n))
+		return "/s?" + params.Encode()
+	}
+
+	// Numbered links like the amazon.de pagination strip
+	var pages []map[string]interface{}
+	for n := 1; pageSize > 0 && (n-1)*pageSize < len(matches); n++ {
+		pages = append(pages, map[string]interface{}{
+			"Number":  n,
+			"URL":     pageURL(n),
+			"Current": n == page,
+		})
+	}
+
+	data := map[string]interface{}{
+		"Query":   query,
+		"Results": matches[start:end],
+		"Pages":   pages,
+	}
+	if end < len(matches) {
+		data["NextURL"] = pageURL(page + 1)
+	}
+
+	render(w, http.StatusOK, searchPage, data)
+}
+
+func (s *Server) handleProduct(w http.ResponseWriter, r *http.Request) {
+	asin := strings.Trim(strings.TrimPrefix(r.URL.Path, "/dp/"), "/")
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, p := range s.products {
+		if p.ASIN == asin {
+			render(w, http.StatusOK, productPage, p)
+			return
+		}
+	}
+
+	render(w, http.StatusNotFound, notFoundPage, nil)
+}
+
+// handleStore serves /stores/<Brand>/page/<id>, one page per pageSize products of the brand with a
+// navigation linking all pages. The last tile of each page only renders after scrolling, like the
+// lazily loaded product grids of real stores.
+func (s *Server) handleStore(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/stores/"), "/"), "/")
+	if len(parts) != 3 || parts[1] != "page" {
+		render(w, http.StatusNotFound, notFoundPage, nil)
+		return
+	}
+	brand := parts[0]
+	page, err := strconv.Atoi(strings.TrimPrefix(parts[2], storePagePrefix))
+	if err != nil || page < 1 {
+		render(w, http.StatusNotFound, notFoundPage, nil)
+		return
+	}
+
+	s.mu.RLock()
+	var matches []Product
+	for _, p := range s.products {
+		if p.Brand == brand {
+			matches = append(matches, p)
+		}
+	}
+	pageSize := s.pageSize
+	s.mu.RUnlock()
+
+	start := (page - 1) * pageSize
+	if len(matches) == 0 || pageSize <= 0 || start >= len(matches) {
+		render(w, http.StatusNotFound, notFoundPage, nil)
+		return
+	}
+	end := start + pageSize
+	if end > len(matches) {
+		end = len(matches)
+	}
+
+	var nav []map[string]interface{}
+	for n := 1; (n-1)*pageSize < len(matches); n++ {
+		nav = append(nav, map[string]interface{}{
+			"Name": fmt.Sprintf("Kollektion %d", n),
+			"URL":  fmt.Sprintf("/stores/%s/page/%s?ref_=ast_bln", url.PathEscape(brand), storePageID(n)),
+		})
+	}
+
+	tiles := matches[start:end]
+	render(w, http.StatusOK, storePage, map[string]interface{}{
+		"Brand": brand,
+		"Nav":   nav,
+		"Tiles": tiles[:len(tiles)-1],
+		"Lazy":  tiles[len(tiles)-1],
+	})
+}
+
+// handleBotCheckSubmit returns to the page that triggered the interstitial
+func (s *Server) handleBotCheckSubmit(w http.ResponseWriter, r *http.Request) {
+	target := r.FormValue("return")
+	if !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") {
+		target = "/"
+	}
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
+
+// isPage reports whether the path is a document rather than an asset such as an image or favicon
+func isPage(path string) bool {
+	return path == "/" || path == "/s" || strings.HasPrefix(path, "/dp/") || strings.HasPrefix(path, "/stores/")
+}
+
+// storePagePrefix starts the store page IDs, real stores use opaque UUIDs
+const storePagePrefix = "STOREPAGE"
+
+func storePageID(n int) string {
+	return fmt.Sprintf("%s%d", storePagePrefix, n)
+}
+
+// matchesQuery reports whether all query words occur in the title or brand
+func matchesQuery(p Product, query string) bool {
+	text := strings.ToLower(p.Title + " " + p.Brand)
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
+
+func render(w http.ResponseWriter, status int, tmpl *template.Template, data interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	tmpl.Execute(w, data)
+}
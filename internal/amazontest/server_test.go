@@ -0,0 +1,189 @@
+package amazontest
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func get(t *testing.T, url string) (int, string) {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	return resp.StatusCode, string(body)
+}
+
+func TestSearchPagination(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	_, body := get(t, s.SearchURL("herren"))
+	if got := strings.Count(body, `data-component-type="s-search-result"`); got != 2 {
+		t.Errorf("page 1 results = %d, want 2", got)
+	}
+	if !strings.Contains(body, `data-asin="`+ASINVerticalChart+`"`) {
+		t.Error("expected first fixture on page 1")
+	}
+	if !strings.Contains(body, `href="/s?k=herren&amp;page=2"`) {
+		t.Error("expected next page link on page 1")
+	}
+	if !strings.Contains(body, `s-pagination-selected">1<`) || !strings.Contains(body, `s-pagination-button" href="/s?k=herren&amp;page=2">2<`) {
+		t.Error("expected numbered pagination strip on page 1")
+	}
+
+	_, body = get(t, s.SearchURL("herren")+"&page=2")
+	if !strings.Contains(body, `aria-disabled="true"`) {
+		t.Error("expected disabled next button on last page")
+	}
+}
+
+func TestSearchFiltersByQuery(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	_, body := get(t, s.SearchURL("tallfit"))
+	if got := strings.Count(body, `data-component-type="s-search-result"`); got != 2 {
+		t.Errorf("results = %d, want 2", got)
+	}
+	if strings.Contains(body, ASINHorizontalChart) {
+		t.Error("unexpected non-matching product")
+	}
+}
+
+func TestProductPage(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	status, body := get(t, s.ProductURL(ASINVerticalChart))
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, want 200", status)
+	}
+	for _, want := range []string{`id="productTitle"`, "Größentabelle", `class="a-popover-content"`, "<td>Länge (cm)</td>", "Materialzusammensetzung", `id="fitRecommendationsSection"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("product page missing %q", want)
+		}
+	}
+
+	_, body = get(t, s.ProductURL(ASINNoChart))
+	if strings.Contains(body, "Größentabelle") {
+		t.Error("product without chart should not link a Größentabelle")
+	}
+
+	if status, _ := get(t, s.ProductURL("B0MISSING0")); status != http.StatusNotFound {
+		t.Errorf("unknown ASIN status = %d, want 404", status)
+	}
+}
+
+func TestAddProductReplacesFixture(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	s.AddProduct(Product{ASIN: ASINNoChart, Title: "Ersetzt"})
+	s.AddProduct(Product{ASIN: "B0TEST0099", Title: "Neu"})
+
+	_, body := get(t, s.ProductURL(ASINNoChart))
+	if !strings.Contains(body, "Ersetzt") {
+		t.Error("expected replaced product")
+	}
+	if status, _ := get(t, s.ProductURL("B0TEST0099")); status != http.StatusOK {
+		t.Errorf("added product status = %d, want 200", status)
+	}
+}
+
+func TestBotCheckInterstitial(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	s.ServeBotChecks(1)
+
+	// Assets do not consume the bot check
+	get(t, s.URL+"/favicon.ico")
+
+	_, body := get(t, s.ProductURL(ASINVerticalChart))
+	if !strings.Contains(body, "Weiter shoppen") {
+		t.Fatal("expected bot check interstitial")
+	}
+	if !strings.Contains(body, `value="/dp/`+ASINVerticalChart+`"`) {
+		t.Error("expected return path in bot check form")
+	}
+
+	// Submitting the form returns to the product page
+	resp, err := http.PostForm(s.URL+"/botcheck", map[string][]string{"return": {"/dp/" + ASINVerticalChart}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body2, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body2), `id="productTitle"`) {
+		t.Error("expected product page after bot check")
+	}
+}
+
+func TestBlockedServesCaptcha(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	s.SetBlocked(true)
+	_, body := get(t, s.SearchURL("herren"))
+	if !strings.Contains(body, `id="captchacharacters"`) {
+		t.Error("expected captcha page while blocked")
+	}
+
+	s.SetBlocked(false)
+	_, body = get(t, s.SearchURL("herren"))
+	if strings.Contains(body, "captchacharacters") {
+		t.Error("expected search results after unblocking")
+	}
+}
+
+func TestStorePages(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.SetPageSize(1)
+
+	status, body := get(t, s.StoreURL("TallFit"))
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, want 200", status)
+	}
+	if !strings.Contains(body, `data-testid="product-grid-item"`) || !strings.Contains(body, `id="lazy-tile"`) {
+		t.Error("expected product grid with lazily loaded tile")
+	}
+	if !strings.Contains(body, "/dp/"+ASINVerticalChart) {
+		t.Error("expected first TallFit product on page 1")
+	}
+	if !strings.Contains(body, `href="/stores/TallFit/page/STOREPAGE2?ref_=ast_bln"`) {
+		t.Error("expected navigation link to page 2")
+	}
+
+	_, body = get(t, s.URL+"/stores/TallFit/page/STOREPAGE2")
+	if !strings.Contains(body, "/dp/"+ASINNoLength) || strings.Contains(body, ASINHorizontalChart) {
+		t.Error("expected only TallFit products on page 2")
+	}
+
+	if status, _ := get(t, s.URL+"/stores/TallFit/page/STOREPAGE3"); status != http.StatusNotFound {
+		t.Errorf("page past the end status = %d, want 404", status)
+	}
+}
+
+func TestSellerStorefront(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	_, body := get(t, s.URL+"/s?me="+SellerTallFit)
+	if got := strings.Count(body, `data-component-type="s-search-result"`); got != 2 {
+		t.Errorf("results = %d, want 2", got)
+	}
+	if strings.Contains(body, ASINNoChart) {
+		t.Error("unexpected product of another seller")
+	}
+}
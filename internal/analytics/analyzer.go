@@ -0,0 +1,195 @@
+package analytics
+
+import (
+	"context"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/labels"
+)
+
+// Statuses of an analyzed product
+const (
+	StatusComplete      = "complete"       // Length and width measurements
+	StatusMissingLength = "missing_length" // Width but no length
+	StatusMissingWidth  = "missing_width"  // Length but no width
+	StatusMissingBoth   = "missing_both"   // A size table without length and width
+	StatusNoTable       = "no_table"
+	StatusError         = "error" // The product page could not be analyzed
+)
+
+// Table is the size table an extractor found on a product page
+type Table struct {
+	Sizes        []string
+	Measurements map[string]map[string]float64 // Size -> measurement key -> value
+	Source       string                        // e.g. html, ocr
+}
+
+// Extractor extracts the size table of a product page, nil without an error if the page has none
+type Extractor interface {
+	ExtractTable(ctx context.Context, asin, url string) (*Table, error)
+}
+
+// Product is a product to analyze, usually a search result
+type Product struct {
+	ASIN  string
+	Title string
+	URL   string // Product page, the extractor builds it from the ASIN if empty
+}
+
+// Result is the analysis of one product's size table
+type Result struct {
+	ASIN         string    `json:"asin"`
+	Title        string    `json:"title,omitempty"`
+	Status       string    `json:"status"`
+	HasTable     bool      `json:"has_table"`
+	HasLength    bool      `json:"has_length"`
+	HasWidth     bool      `json:"has_width"` // Chest or width measurement
+	Measurements []string  `json:"measurements"`
+	Source       string    `json:"source,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	AnalyzedAt   time.Time `json:"analyzed_at"`
+}
+
+// Statistics counts the analyzed products per status
+type Statistics struct {
+	Total         int `json:"total"`
+	Complete      int `json:"complete"`
+	MissingLength int `json:"missing_length"`
+	MissingWidth  int `json:"missing_width"`
+	MissingBoth   int `json:"missing_both"`
+	NoTable       int `json:"no_table"`
+	Errors        int `json:"errors"`
+}
+
+// Add counts a product of the given status
+func (s *Statistics) Add(status string) {
+	s.Total++
+	switch status {
+	case StatusComplete:
+		s.Complete++
+	case StatusMissingLength:
+		s.MissingLength++
+	case StatusMissingWidth:
+		s.MissingWidth++
+	case StatusMissingBoth:
+		s.MissingBoth++
+	case StatusNoTable:
+		s.NoTable++
+	default:
+		s.Errors++
+	}
+}
+
+// Share returns n as share of the analyzed products, between 0 and 1 and rounded to three digits
+func (s Statistics) Share(n int) float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return math.Round(float64(n)/float64(s.Total)*1000) / 1000
+}
+
+// Classify returns the status of a size table and its measurement keys, sorted. Length and width count
+// when any size has them.
+func Classify(table *Table) (string, []string) {
+	if table == nil {
+		return StatusNoTable, nil
+	}
+	keys, _ := measurementKeys(table.Sizes, table.Measurements)
+	if len(keys) == 0 {
+		return StatusNoTable, nil
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	slices.Sort(sorted)
+
+	hasLength, hasWidth := keys[labels.Length], keys[labels.Chest] || keys[labels.Width]
+	switch {
+	case hasLength && hasWidth:
+		return StatusComplete, sorted
+	case hasWidth:
+		return StatusMissingLength, sorted
+	case hasLength:
+		return StatusMissingWidth, sorted
+	}
+	return StatusMissingBoth, sorted
+}
+
+// measurementKeys returns the measurement keys with a value in any size, and whether one size has
+// both length and width
+func measurementKeys(sizes []string, measurements map[string]map[string]float64) (map[string]bool, bool) {
+	keys := make(map[string]bool)
+	complete := false
+	for _, size := range sizes {
+		values := measurements[size]
+		for key, value := range values {
+			if value > 0 {
+				keys[key] = true
+			}
+		}
+		if values[labels.Length] > 0 && (values[labels.Chest] > 0 || values[labels.Width] > 0) {
+			complete = true
+		}
+	}
+	return keys, complete
+}
+
+// Analyzer classifies the size tables of products one by one, pausing between product pages
+type Analyzer struct {
+	extractor Extractor
+	delay     time.Duration
+}
+
+// NewAnalyzer creates an analyzer extracting size tables with e and waiting delay between products
+func NewAnalyzer(e Extractor, delay time.Duration) *Analyzer {
+	return &Analyzer{extractor: e, delay: delay}
+}
+
+// Analyze extracts and classifies the size table of one product. A failed extraction is a result of
+// StatusError, the error is returned as well so callers can tell budget or shutdown errors apart.
+func (a *Analyzer) Analyze(ctx context.Context, p Product) (Result, error) {
+	result := Result{ASIN: p.ASIN, Title: p.Title, AnalyzedAt: time.Now()}
+	table, err := a.extractor.ExtractTable(ctx, p.ASIN, p.URL)
+	if err != nil {
+		result.Status, result.Error = StatusError, err.Error()
+		return result, err
+	}
+
+	result.Status, result.Measurements = Classify(table)
+	if result.Status != StatusNoTable {
+		result.HasTable = true
+		result.Source = table.Source
+		result.HasLength = slices.Contains(result.Measurements, labels.Length)
+		result.HasWidth = slices.Contains(result.Measurements, labels.Chest) || slices.Contains(result.Measurements, labels.Width)
+	}
+	return result, nil
+}
+
+// Run analyzes the products in order and passes every result to record with the extraction error, if
+// any. It stops when ctx is done or record returns an error, returning the statistics so far.
+func (a *Analyzer) Run(ctx context.Context, products []Product, record func(Result, error) error) (Statistics, error) {
+	var stats Statistics
+	for i, p := range products {
+		if i > 0 && a.delay > 0 {
+			select {
+			case <-ctx.Done():
+				return stats, ctx.Err()
+			case <-time.After(a.delay):
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+
+		result, err := a.Analyze(ctx, p)
+		if err := record(result, err); err != nil {
+			return stats, err
+		}
+		stats.Add(result.Status)
+	}
+	return stats, nil
+}
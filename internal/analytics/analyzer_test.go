@@ -0,0 +1,84 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name  string
+		table *Table
+		want  string
+		keys  []string
+	}{
+		{"no table", nil, StatusNoTable, nil},
+		{"zero values", &Table{Sizes: []string{"M"}, Measurements: map[string]map[string]float64{"M": {"length": 0}}}, StatusNoTable, nil},
+		{"complete", &Table{Sizes: []string{"S", "M"}, Measurements: map[string]map[string]float64{
+			"S": {"length": 70}, "M": {"chest": 50, "sleeve": 20},
+		}}, StatusComplete, []string{"chest", "length", "sleeve"}},
+		{"width only", &Table{Sizes: []string{"M"}, Measurements: map[string]map[string]float64{"M": {"width": 50}}}, StatusMissingLength, []string{"width"}},
+		{"length only", &Table{Sizes: []string{"M"}, Measurements: map[string]map[string]float64{"M": {"length": 72}}}, StatusMissingWidth, []string{"length"}},
+		{"neither", &Table{Sizes: []string{"M"}, Measurements: map[string]map[string]float64{"M": {"waist": 80}}}, StatusMissingBoth, []string{"waist"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, keys := Classify(tt.table)
+			if status != tt.want || !reflect.DeepEqual(keys, tt.keys) {
+				t.Errorf("Classify() = %s %v, want %s %v", status, keys, tt.want, tt.keys)
+			}
+		})
+	}
+}
+
+// fakeExtractor returns the table of an ASIN, an error for unknown ones
+type fakeExtractor map[string]*Table
+
+func (f fakeExtractor) ExtractTable(ctx context.Context, asin, url string) (*Table, error) {
+	table, ok := f[asin]
+	if !ok {
+		return nil, errors.New("navigation failed")
+	}
+	return table, nil
+}
+
+func TestAnalyzerRun(t *testing.T) {
+	a := NewAnalyzer(fakeExtractor{
+		"B000000001": {Sizes: []string{"M"}, Measurements: map[string]map[string]float64{"M": {"length": 72, "chest": 50}}, Source: "html"},
+		"B000000002": nil,
+	}, 0)
+	products := []Product{{ASIN: "B000000001", Title: "T-Shirt"}, {ASIN: "B000000002"}, {ASIN: "B000000003"}}
+
+	var results []Result
+	stats, err := a.Run(context.Background(), products, func(r Result, err error) error {
+		results = append(results, r)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := (Statistics{Total: 3, Complete: 1, NoTable: 1, Errors: 1}); stats != want {
+		t.Errorf("statistics = %+v, want %+v", stats, want)
+	}
+	if r := results[0]; !r.HasTable || !r.HasLength || !r.HasWidth || r.Source != "html" || r.Title != "T-Shirt" {
+		t.Errorf("complete result = %+v", r)
+	}
+	if r := results[2]; r.Status != StatusError || r.Error != "navigation failed" {
+		t.Errorf("failed result = %+v", r)
+	}
+	if share := stats.Share(stats.Complete); share != 0.333 {
+		t.Errorf("share of complete = %v, want 0.333", share)
+	}
+
+	// record aborts the run at the failed product
+	stats, err = a.Run(context.Background(), products, func(r Result, err error) error {
+		return err
+	})
+	if err == nil || stats.Total != 2 {
+		t.Errorf("aborted run = %+v, %v, want 2 products and the extraction error", stats, err)
+	}
+}
@@ -0,0 +1,74 @@
+// Package analytics measures the quality of the scraped data, over samples of stored products and by
+// analyzing the size tables of live product pages
+package analytics
+
+import (
+	"math"
+
+	"github.com/maltedev/amazon-size-scraper/internal/labels"
+)
+
+// Coverage counts how many products of a sample have a size table and which measurements it holds
+type Coverage struct {
+	Sampled          int            `json:"sampled"`
+	WithSizeTable    int            `json:"with_size_table"`
+	WithLength       int            `json:"with_length"`
+	WithWidth        int            `json:"with_width"`        // Chest or width measurement
+	Complete         int            `json:"complete"`          // Length and width in the same size
+	MeasurementTypes map[string]int `json:"measurement_types"` // Products per measurement key, e.g. "sleeve"
+}
+
+// Rates are the shares of the sample, between 0 and 1
+type Rates struct {
+	SizeTable float64 `json:"size_table"`
+	Length    float64 `json:"length"`
+	Width     float64 `json:"width"`
+	Complete  float64 `json:"complete"`
+}
+
+// Add counts one sampled product with the sizes and measurements of its size table, both empty for a
+// product without one
+func (c *Coverage) Add(sizes []string, measurements map[string]map[string]float64) {
+	c.Sampled++
+	if len(sizes) == 0 || len(measurements) == 0 {
+		return
+	}
+	if c.MeasurementTypes == nil {
+		c.MeasurementTypes = make(map[string]int)
+	}
+
+	keys, complete := measurementKeys(sizes, measurements)
+	if len(keys) == 0 {
+		return
+	}
+
+	c.WithSizeTable++
+	for key := range keys {
+		c.MeasurementTypes[key]++
+	}
+	if keys[labels.Length] {
+		c.WithLength++
+	}
+	if keys[labels.Chest] || keys[labels.Width] {
+		c.WithWidth++
+	}
+	if complete {
+		c.Complete++
+	}
+}
+
+// Rates returns the counts as shares of the sample, rounded to three digits
+func (c Coverage) Rates() Rates {
+	share := func(n int) float64 {
+		if c.Sampled == 0 {
+			return 0
+		}
+		return math.Round(float64(n)/float64(c.Sampled)*1000) / 1000
+	}
+	return Rates{
+		SizeTable: share(c.WithSizeTable),
+		Length:    share(c.WithLength),
+		Width:     share(c.WithWidth),
+		Complete:  share(c.Complete),
+	}
+}
@@ -0,0 +1,43 @@
+package analytics
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCoverage(t *testing.T) {
+	var c Coverage
+	// Length and chest in the same size
+	c.Add([]string{"S", "M"}, map[string]map[string]float64{
+		"S": {"length": 70, "chest": 48},
+		"M": {"length": 72, "chest": 50, "sleeve": 20},
+	})
+	// Length and width, but never in the same size
+	c.Add([]string{"S", "M"}, map[string]map[string]float64{
+		"S": {"length": 70},
+		"M": {"width": 50},
+	})
+	// Only zero values count as no size table
+	c.Add([]string{"M"}, map[string]map[string]float64{"M": {"length": 0}})
+	c.Add(nil, nil)
+
+	want := Coverage{
+		Sampled:          4,
+		WithSizeTable:    2,
+		WithLength:       2,
+		WithWidth:        2,
+		Complete:         1,
+		MeasurementTypes: map[string]int{"length": 2, "chest": 1, "width": 1, "sleeve": 1},
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("Coverage = %+v, want %+v", c, want)
+	}
+
+	rates := c.Rates()
+	if rates != (Rates{SizeTable: 0.5, Length: 0.5, Width: 0.5, Complete: 0.25}) {
+		t.Errorf("Rates() = %+v", rates)
+	}
+	if (Coverage{}).Rates() != (Rates{}) {
+		t.Error("Rates() of an empty sample should be zero")
+	}
+}
@@ -0,0 +1,220 @@
+// Package asin validates Amazon Standard Identification Numbers and extracts them from the URL forms
+// Amazon links products with.
+package asin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrInvalid is returned for values that neither are nor link to an ASIN
+var ErrInvalid = errors.New("invalid ASIN")
+
+var (
+	// ASINs of non-book products start with B, books use their ISBN-10
+	productPattern = regexp.MustCompile(`^B[0-9A-Z]{9}$`)
+	isbnPattern    = regexp.MustCompile(`^[0-9]{9}[0-9X]$`)
+	// marketplacePattern matches marketplace domains such as amazon.de or amazon.co.uk
+	marketplacePattern = regexp.MustCompile(`^amazon(\.[a-z]{2,3}){1,2}$`)
+	urlPattern         = regexp.MustCompile(`(?i)/(?:dp(?:/product)?|gp/product|gp/aw/d|gp/offer-listing|product-reviews|o/ASIN|exec/obidos/ASIN)/([0-9A-Z]{10})(?:[/?#;]|$)`)
+)
+
+// shortHosts redirect to a product page, the ASIN is only known after following the redirect
+var shortHosts = map[string]bool{"amzn.to": true, "amzn.eu": true, "amzn.asia": true, "a.co": true}
+
+// Valid reports whether s is a well-formed ASIN. Product ASINs need a digit besides the leading B, so
+// words like BESTSELLER are rejected, ISBN-10s need a valid check digit.
+func Valid(s string) bool {
+	switch {
+	case productPattern.MatchString(s):
+		return strings.ContainsAny(s[1:], "0123456789")
+	case isbnPattern.MatchString(s):
+		return isbnChecksum(s)
+	}
+	return false
+}
+
+// isbnChecksum verifies the weighted ISBN-10 check digit, X stands for 10
+func isbnChecksum(s string) bool {
+	sum := 0
+	for i, c := range s {
+		d := int(c - '0')
+		if c == 'X' {
+			d = 10
+		}
+		sum += (10 - i) * d
+	}
+	return sum%11 == 0
+}
+
+// Normalize trims whitespace and quotes and upper-cases a bare ASIN
+func Normalize(s string) (string, bool) {
+	s = strings.ToUpper(strings.Trim(strings.TrimSpace(s), `"'`))
+	return s, Valid(s)
+}
+
+// Extract returns the ASIN of a bare ASIN or a product URL: /dp/, /gp/product/, /gp/aw/d/, review and
+// offer pages and sponsored /sspa/click links, which carry the product path in their url parameter.
+// Short links need Resolver.Resolve.
+func Extract(value string) (string, bool) {
+	p, ok := Parse(value, "")
+	return p.ASIN, ok
+}
+
+// DefaultMarketplace is assumed for bare ASINs and links that do not name a marketplace
+const DefaultMarketplace = "amazon.de"
+
+// Product is an ASIN on a marketplace, the same ASIN may be listed on several
+type Product struct {
+	ASIN        string `json:"asin"`
+	Marketplace string `json:"marketplace"` // Domain without www, e.g. amazon.co.uk
+}
+
+// URL returns the canonical product page, without ref tags and tracking parameters
+func (p Product) URL() string {
+	return fmt.Sprintf("https://www.%s/dp/%s", p.Marketplace, p.ASIN)
+}
+
+// String returns marketplace/ASIN, e.g. amazon.de/B08N5WRWNW
+func (p Product) String() string {
+	return p.Marketplace + "/" + p.ASIN
+}
+
+// Parse returns the ASIN and marketplace of a bare ASIN or product URL, see Extract. Bare ASINs and
+// links that do not name a marketplace get defaultMarketplace.
+func Parse(value, defaultMarketplace string) (Product, bool) {
+	value = strings.Trim(strings.TrimSpace(value), `"'`)
+	if asin, ok := Normalize(value); ok {
+		return Product{ASIN: asin, Marketplace: defaultMarketplace}, true
+	}
+	asin, marketplace, ok := fromURL(value, 2)
+	if !ok {
+		return Product{}, false
+	}
+	if marketplace == "" {
+		marketplace = defaultMarketplace
+	}
+	return Product{ASIN: asin, Marketplace: marketplace}, true
+}
+
+func fromURL(value string, depth int) (string, string, bool) {
+	u, err := url.Parse(value)
+	if err != nil {
+		return "", "", false
+	}
+	marketplace := Marketplace(u.Hostname())
+	if m := urlPattern.FindStringSubmatch(u.EscapedPath()); m != nil {
+		if asin, ok := Normalize(m[1]); ok {
+			return asin, marketplace, true
+		}
+	}
+
+	query := u.Query()
+	for _, key := range []string{"asin", "ASIN", "pd_rd_i"} {
+		if asin, ok := Normalize(query.Get(key)); ok {
+			return asin, marketplace, true
+		}
+	}
+	// Sponsored links redirect to the encoded product path in url, usually relative to their own host
+	if target := query.Get("url"); target != "" && depth > 0 {
+		asin, inner, ok := fromURL(target, depth-1)
+		if inner == "" {
+			inner = marketplace
+		}
+		return asin, inner, ok
+	}
+	return "", "", false
+}
+
+// Marketplace returns the marketplace of an Amazon host, e.g. amazon.co.uk for www.amazon.co.uk, or
+// empty for other hosts
+func Marketplace(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	for _, prefix := range []string{"www.", "smile.", "m."} {
+		host = strings.TrimPrefix(host, prefix)
+	}
+	if !marketplacePattern.MatchString(host) {
+		return ""
+	}
+	return host
+}
+
+// IsShortLink reports whether value is an amzn.to, amzn.eu or a.co link
+func IsShortLink(value string) bool {
+	value = strings.TrimSpace(value)
+	if !strings.Contains(value, "://") {
+		value = "https://" + value
+	}
+	u, err := url.Parse(value)
+	return err == nil && shortHosts[strings.ToLower(u.Hostname())]
+}
+
+// Resolver extracts ASINs from short links by following their redirects
+type Resolver struct {
+	client *http.Client
+}
+
+// NewResolver creates a resolver with a timeout per short link
+func NewResolver(timeout time.Duration) *Resolver {
+	return &Resolver{client: &http.Client{Timeout: timeout}}
+}
+
+// Resolve returns the ASIN of value like Extract, following the redirects of short links until a
+// location carries an ASIN. The product page itself is not requested.
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	p, err := r.ResolveProduct(ctx, value, "")
+	return p.ASIN, err
+}
+
+// ResolveProduct is Resolve returning the marketplace as well, defaultMarketplace when the value and
+// its redirects do not name one
+func (r *Resolver) ResolveProduct(ctx context.Context, value, defaultMarketplace string) (Product, error) {
+	if p, ok := Parse(value, defaultMarketplace); ok {
+		return p, nil
+	}
+	if !IsShortLink(value) {
+		return Product{}, fmt.Errorf("%w: %q", ErrInvalid, value)
+	}
+
+	link := strings.TrimSpace(value)
+	if !strings.Contains(link, "://") {
+		link = "https://" + link
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, link, nil)
+	if err != nil {
+		return Product{}, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	var found Product
+	var ok bool
+	client := *r.client
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if found, ok = Parse(req.URL.String(), defaultMarketplace); ok {
+			return http.ErrUseLastResponse
+		}
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Product{}, fmt.Errorf("failed to resolve short link: %w", err)
+	}
+	resp.Body.Close()
+
+	if !ok {
+		found, ok = Parse(resp.Request.URL.String(), defaultMarketplace)
+	}
+	if !ok {
+		return Product{}, fmt.Errorf("%w: short link %s leads to no product", ErrInvalid, link)
+	}
+	return found, nil
+}
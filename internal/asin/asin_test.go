@@ -0,0 +1,156 @@
+package asin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"B08N5WRWNW", true},
+		{"B0TEST0001", true},
+		{"3453435990", true},
+		{"080442957X", true},
+		{"3453435991", false}, // Wrong ISBN check digit
+		{"BESTSELLER", false},
+		{"A08N5WRWNW", false},
+		{"B08N5WRWN", false},
+		{"b08n5wrwnw", false},
+	}
+
+	for _, tt := range tests {
+		if got := Valid(tt.in); got != tt.want {
+			t.Errorf("Valid(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"B08N5WRWNW", "B08N5WRWNW", true},
+		{" b08n5wrwnw ", "B08N5WRWNW", true},
+		{`"B08N5WRWNW"`, "B08N5WRWNW", true},
+		{"3453435990", "3453435990", true},
+		{"https://www.amazon.de/Tall-Shirt/dp/B08N5WRWNW/ref=sr_1_1?th=1", "B08N5WRWNW", true},
+		{"https://www.amazon.de/gp/product/B08N5WRWNW", "B08N5WRWNW", true},
+		{"https://www.amazon.co.uk/dp/product/B08N5WRWNW?psc=1", "B08N5WRWNW", true},
+		{"https://www.amazon.com/gp/aw/d/B08N5WRWNW", "B08N5WRWNW", true},
+		{"https://www.amazon.fr/product-reviews/B08N5WRWNW/ref=cm_cr_dp", "B08N5WRWNW", true},
+		{"amazon.de/dp/b08n5wrwnw", "B08N5WRWNW", true},
+		{"https://www.amazon.de/sspa/click?ie=UTF8&spc=MToxMjM&url=%2FTall-Shirt%2Fdp%2FB08N5WRWNW%2Fref%3Dsr_1_1_sspa%3Fpsc%3D1", "B08N5WRWNW", true},
+		{"https://www.amazon.de/s?k=shirt&pd_rd_i=B08N5WRWNW", "B08N5WRWNW", true},
+		{"B08N5WRWN", "", false},
+		{"A08N5WRWNW", "", false},
+		{"https://www.amazon.de/dp/B08N5WRWNWX", "", false},
+		{"https://amzn.to/3xYzAbC", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := Extract(tt.in)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("Extract(%q) = %q, %v; want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Product
+	}{
+		{"B08N5WRWNW", Product{"B08N5WRWNW", "amazon.de"}},
+		{"https://www.amazon.co.uk/Tall-Shirt/dp/B08N5WRWNW/ref=sr_1_3?tag=x-21", Product{"B08N5WRWNW", "amazon.co.uk"}},
+		{"https://smile.amazon.com/gp/product/B08N5WRWNW?psc=1", Product{"B08N5WRWNW", "amazon.com"}},
+		{"https://m.amazon.fr/gp/aw/d/B08N5WRWNW", Product{"B08N5WRWNW", "amazon.fr"}},
+		{"https://www.amazon.it/sspa/click?ie=UTF8&url=%2FShirt%2Fdp%2FB08N5WRWNW%2Fref%3Dsr_1_1_sspa", Product{"B08N5WRWNW", "amazon.it"}},
+		{"https://aax-eu.amazon-adsystem.com/x/c/abc?url=https%3A%2F%2Fwww.amazon.es%2Fdp%2FB08N5WRWNW", Product{"B08N5WRWNW", "amazon.es"}},
+		{"https://example.com/dp/B08N5WRWNW", Product{"B08N5WRWNW", "amazon.de"}},
+	}
+	for _, tt := range tests {
+		got, ok := Parse(tt.value, DefaultMarketplace)
+		if !ok || got != tt.want {
+			t.Errorf("Parse(%q) = %v, %v; want %v", tt.value, got, ok, tt.want)
+		}
+	}
+
+	if got := (Product{"B08N5WRWNW", "amazon.co.uk"}).URL(); got != "https://www.amazon.co.uk/dp/B08N5WRWNW" {
+		t.Errorf("URL() = %q, want https://www.amazon.co.uk/dp/B08N5WRWNW", got)
+	}
+	if _, ok := Parse("https://www.amazon.de/s?k=shirt", DefaultMarketplace); ok {
+		t.Error("Expected search pages not to parse")
+	}
+}
+
+func TestIsShortLink(t *testing.T) {
+	for in, want := range map[string]bool{
+		"https://amzn.to/3xYzAbC":     true,
+		"amzn.eu/d/abc":               true,
+		"https://a.co/d/abc":          true,
+		"https://www.amazon.de/dp/B0": false,
+		"not a link":                  false,
+	} {
+		if got := IsShortLink(in); got != want {
+			t.Errorf("IsShortLink(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+// hostTransport sends all requests to srv, keeping the path
+type hostTransport struct{ srv *httptest.Server }
+
+func (h hostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	target, _ := url.Parse(h.srv.URL)
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = target.Scheme, target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestResolve(t *testing.T) {
+	productRequested := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/3xYzAbC":
+			http.Redirect(w, r, "https://www.amazon.de/Tall-Shirt/dp/B08N5WRWNW?tag=x", http.StatusMovedPermanently)
+		case "/d/uk1":
+			http.Redirect(w, r, "https://www.amazon.co.uk/dp/B08N5WRWNW?ref_=xyz", http.StatusFound)
+		case "/dead":
+			http.Redirect(w, r, "https://www.amazon.de/", http.StatusMovedPermanently)
+		default:
+			productRequested = true
+		}
+	}))
+	defer srv.Close()
+
+	r := &Resolver{client: &http.Client{Transport: hostTransport{srv}}}
+
+	got, err := r.Resolve(context.Background(), "https://amzn.to/3xYzAbC")
+	if err != nil || got != "B08N5WRWNW" {
+		t.Errorf("Resolve() = %q, %v; want B08N5WRWNW", got, err)
+	}
+	if productRequested {
+		t.Error("Expected the product page not to be requested")
+	}
+
+	product, err := r.ResolveProduct(context.Background(), "https://amzn.eu/d/uk1", DefaultMarketplace)
+	if err != nil || product != (Product{"B08N5WRWNW", "amazon.co.uk"}) {
+		t.Errorf("ResolveProduct() = %v, %v; want amazon.co.uk/B08N5WRWNW", product, err)
+	}
+
+	if _, err := r.Resolve(context.Background(), "https://amzn.to/dead"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Expected ErrInvalid for a link without product, got %v", err)
+	}
+	if _, err := r.Resolve(context.Background(), "https://example.com/x"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Expected ErrInvalid for other links, got %v", err)
+	}
+}
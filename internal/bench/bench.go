@@ -0,0 +1,236 @@
+// Package bench measures the throughput of the product page parser and the size table pipeline on a
+// corpus of product pages, for go test -bench and the scraper bench command.
+package bench
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/scraper"
+	"github.com/maltedev/amazon-size-scraper/internal/amazontest"
+	"github.com/maltedev/amazon-size-scraper/internal/asin"
+	"github.com/maltedev/amazon-size-scraper/internal/parser"
+)
+
+// Fixture is one product page of the corpus
+type Fixture struct {
+	Name       string
+	ASIN       string
+	HTML       string
+	SizeTables []interface{} // Size chart script results, headers and rows
+}
+
+// DefaultCorpus renders the product pages of the amazontest fixtures
+func DefaultCorpus() ([]Fixture, error) {
+	products := append(amazontest.DefaultProducts(), amazontest.InlineChartProducts()...)
+
+	corpus := make([]Fixture, 0, len(products))
+	for _, p := range products {
+		var buf bytes.Buffer
+		if err := amazontest.RenderProductPage(&buf, p); err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", p.ASIN, err)
+		}
+		f := Fixture{Name: p.ASIN, ASIN: p.ASIN, HTML: buf.String()}
+		for _, chart := range []amazontest.SizeChart{p.SizeChart, p.DescriptionSizeChart, p.APlusSizeChart} {
+			if data := chart.TableData(); data != nil {
+				f.SizeTables = append(f.SizeTables, data)
+			}
+		}
+		corpus = append(corpus, f)
+	}
+	return corpus, nil
+}
+
+// LoadDir reads saved product pages (*.html), e.g. from scraper debug --html. The ASIN is taken from
+// the file name and the page's tables stand in for size chart script results.
+func LoadDir(dir string) ([]Fixture, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.html"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	corpus := make([]Fixture, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimSuffix(filepath.Base(path), ".html")
+		id, _ := asin.Extract(name)
+		tables, err := htmlTables(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		corpus = append(corpus, Fixture{Name: name, ASIN: id, HTML: string(data), SizeTables: tables})
+	}
+	return corpus, nil
+}
+
+// htmlTables converts tables with at least two rows and columns into size chart script results
+func htmlTables(html string) ([]interface{}, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return nil, err
+	}
+
+	var tables []interface{}
+	doc.Find("table").Each(func(_ int, table *goquery.Selection) {
+		var rows []interface{}
+		table.Find("tr").Each(func(_ int, tr *goquery.Selection) {
+			var cells []interface{}
+			tr.Find("th, td").Each(func(_ int, cell *goquery.Selection) {
+				cells = append(cells, strings.TrimSpace(cell.Text()))
+			})
+			if len(cells) >= 2 {
+				rows = append(rows, cells)
+			}
+		})
+		if len(rows) >= 2 {
+			tables = append(tables, map[string]interface{}{"headers": rows[0], "rows": rows[1:]})
+		}
+	})
+	return tables, nil
+}
+
+// Benchmark is a named benchmark over the whole corpus, one op parses every fixture once
+type Benchmark struct {
+	Name string
+	F    func(b *testing.B)
+}
+
+// Benchmarks returns the parser and size table benchmarks for corpus
+func Benchmarks(corpus []Fixture) []Benchmark {
+	p := parser.NewAmazonParser()
+	service := scraper.NewService(nil, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	return []Benchmark{
+		{"ParseProductPage", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				for _, f := range corpus {
+					p.ParseProductPage(f.HTML, f.ASIN)
+				}
+			}
+		}},
+		{"ExtractMaterialComposition", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				for _, f := range corpus {
+					p.ExtractMaterialComposition(f.HTML)
+				}
+			}
+		}},
+		{"ParseSizeTable", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				for _, f := range corpus {
+					for _, table := range f.SizeTables {
+						service.ParseSizeTable(table)
+					}
+				}
+			}
+		}},
+	}
+}
+
+// Result is the outcome of a benchmark
+type Result struct {
+	Name        string `json:"name"`
+	Iterations  int    `json:"iterations"`
+	NsPerOp     int64  `json:"ns_per_op"`
+	AllocsPerOp int64  `json:"allocs_per_op"`
+	BytesPerOp  int64  `json:"bytes_per_op"`
+}
+
+// Run runs the benchmarks whose name contains filter, all for an empty filter
+func Run(benchmarks []Benchmark, filter string) []Result {
+	var results []Result
+	for _, bm := range benchmarks {
+		if filter != "" && !strings.Contains(bm.Name, filter) {
+			continue
+		}
+		r := testing.Benchmark(bm.F)
+		results = append(results, Result{
+			Name:        bm.Name,
+			Iterations:  r.N,
+			NsPerOp:     r.NsPerOp(),
+			AllocsPerOp: r.AllocsPerOp(),
+			BytesPerOp:  r.AllocedBytesPerOp(),
+		})
+	}
+	return results
+}
+
+// Regression is a benchmark that got slower or allocates more than a baseline allows
+type Regression struct {
+	Name     string  `json:"name"`
+	Metric   string  `json:"metric"` // ns_per_op or allocs_per_op
+	Baseline int64   `json:"baseline"`
+	Current  int64   `json:"current"`
+	Change   float64 `json:"change"` // Relative change, 0.25 is 25% worse
+}
+
+// Compare returns the results whose time or allocations per op exceed the baseline by more than
+// tolerance, benchmarks missing from the baseline are ignored
+func Compare(baseline, current []Result, tolerance float64) []Regression {
+	base := make(map[string]Result, len(baseline))
+	for _, r := range baseline {
+		base[r.Name] = r
+	}
+
+	var regressions []Regression
+	for _, r := range current {
+		b, ok := base[r.Name]
+		if !ok {
+			continue
+		}
+		for _, m := range []struct {
+			metric            string
+			baseline, current int64
+		}{
+			{"ns_per_op", b.NsPerOp, r.NsPerOp},
+			{"allocs_per_op", b.AllocsPerOp, r.AllocsPerOp},
+		} {
+			if m.baseline <= 0 {
+				continue
+			}
+			change := float64(m.current-m.baseline) / float64(m.baseline)
+			if change > tolerance {
+				regressions = append(regressions, Regression{Name: r.Name, Metric: m.metric, Baseline: m.baseline, Current: m.current, Change: change})
+			}
+		}
+	}
+	return regressions
+}
+
+// ReadResults reads results written by WriteResults
+func ReadResults(path string) ([]Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+	var results []Result
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline: %w", err)
+	}
+	return results, nil
+}
+
+// WriteResults stores results as JSON, e.g. as baseline of later runs
+func WriteResults(path string, results []Result) error {
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,79 @@
+package bench
+
+import (
+	"testing"
+)
+
+func corpus(tb testing.TB) []Fixture {
+	tb.Helper()
+	c, err := DefaultCorpus()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return c
+}
+
+func benchmark(b *testing.B, name string) {
+	for _, bm := range Benchmarks(corpus(b)) {
+		if bm.Name == name {
+			bm.F(b)
+			return
+		}
+	}
+	b.Fatalf("unknown benchmark %s", name)
+}
+
+func BenchmarkParseProductPage(b *testing.B)           { benchmark(b, "ParseProductPage") }
+func BenchmarkExtractMaterialComposition(b *testing.B) { benchmark(b, "ExtractMaterialComposition") }
+func BenchmarkParseSizeTable(b *testing.B)             { benchmark(b, "ParseSizeTable") }
+
+func TestDefaultCorpus(t *testing.T) {
+	c := corpus(t)
+	if len(c) < 6 {
+		t.Fatalf("Expected the amazontest products, got %d fixtures", len(c))
+	}
+	tables := 0
+	for _, f := range c {
+		tables += len(f.SizeTables)
+	}
+	if tables == 0 {
+		t.Error("Expected size tables in the corpus")
+	}
+}
+
+func TestHTMLTables(t *testing.T) {
+	tables, err := htmlTables(`<table><tr><th>Größe</th><th>M</th></tr><tr><td>Länge</td><td>78</td></tr></table><table><tr><td>x</td></tr></table>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 1 {
+		t.Fatalf("Expected one size table, got %d", len(tables))
+	}
+	data := tables[0].(map[string]interface{})
+	if headers := data["headers"].([]interface{}); len(headers) != 2 || headers[1] != "M" {
+		t.Errorf("Unexpected headers %v", headers)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	baseline := []Result{
+		{Name: "ParseProductPage", NsPerOp: 1000, AllocsPerOp: 100},
+		{Name: "ParseSizeTable", NsPerOp: 1000, AllocsPerOp: 100},
+	}
+	current := []Result{
+		{Name: "ParseProductPage", NsPerOp: 1100, AllocsPerOp: 300},
+		{Name: "ParseSizeTable", NsPerOp: 1500, AllocsPerOp: 100},
+		{Name: "New", NsPerOp: 1, AllocsPerOp: 1},
+	}
+
+	regressions := Compare(baseline, current, 0.2)
+	if len(regressions) != 2 {
+		t.Fatalf("Expected 2 regressions, got %+v", regressions)
+	}
+	if regressions[0].Name != "ParseProductPage" || regressions[0].Metric != "allocs_per_op" {
+		t.Errorf("Unexpected regression %+v", regressions[0])
+	}
+	if regressions[1].Name != "ParseSizeTable" || regressions[1].Metric != "ns_per_op" || regressions[1].Change != 0.5 {
+		t.Errorf("Unexpected regression %+v", regressions[1])
+	}
+}
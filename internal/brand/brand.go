@@ -0,0 +1,154 @@
+// Package brand normalizes the brand names Amazon shows in different forms on byline links, search
+// results and store pages, e.g. "Besuchen Sie den XYZ-Store", "XYZ GmbH" and "xyz", and maps them to one
+// canonical name through an alias registry.
+package brand
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// prefixes are byline texts in front of the brand, matched case-insensitively
+var prefixes = []string{
+	"besuchen sie den ",
+	"besuche den ",
+	"visit the ",
+	"visitez la boutique ",
+	"visita lo store di ",
+	"visita la tienda de ",
+	"marke: ",
+	"brand: ",
+	"marca: ",
+	"marque : ",
+	"marque: ",
+}
+
+// suffixes are store and legal form suffixes behind the brand, matched case-insensitively
+var suffixes = []string{
+	"-store",
+	" store",
+	"-shop",
+	" shop",
+	" gmbh & co. kg",
+	" gmbh & co kg",
+	" gmbh",
+	" ag",
+	" kg",
+	" e.k.",
+	" inc.",
+	" inc",
+	" ltd.",
+	" ltd",
+	" llc",
+	" s.a.",
+	" s.r.l.",
+	" b.v.",
+	" se",
+}
+
+// Normalize strips store and byline phrasing and legal forms from a brand name and collapses its
+// whitespace, e.g. "Besuchen Sie den XYZ-Store" and "XYZ GmbH" both become "XYZ". The case is kept.
+func Normalize(raw string) string {
+	name := strings.Join(strings.Fields(raw), " ")
+	for _, prefix := range prefixes {
+		if len(name) > len(prefix) && strings.EqualFold(name[:len(prefix)], prefix) {
+			name = name[len(prefix):]
+			break
+		}
+	}
+	// "XYZ Store GmbH" needs more than one pass
+	for stripped := true; stripped; {
+		stripped = false
+		for _, suffix := range suffixes {
+			if len(name) > len(suffix) && strings.EqualFold(name[len(name)-len(suffix):], suffix) {
+				name = strings.TrimRight(name[:len(name)-len(suffix)], " ,")
+				stripped = true
+			}
+		}
+	}
+	return strings.TrimSpace(name)
+}
+
+// Key folds a brand name for comparison, ignoring case, spaces and punctuation, so "Jack & Jones" and
+// "JACK&JONES" share the key "jackjones"
+func Key(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(Normalize(name)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Alias maps a spelling of a brand to its canonical name
+type Alias struct {
+	Alias string `json:"alias"`
+	Brand string `json:"brand"`
+}
+
+// Normalize validates the alias and normalizes both names
+func (a *Alias) Normalize() error {
+	a.Alias = Normalize(a.Alias)
+	a.Brand = Normalize(a.Brand)
+	switch {
+	case Key(a.Alias) == "":
+		return fmt.Errorf("alias is required")
+	case Key(a.Brand) == "":
+		return fmt.Errorf("brand is required")
+	}
+	return nil
+}
+
+// Registry maps brand names to their canonical spelling, safe for concurrent use
+type Registry struct {
+	mu      sync.RWMutex
+	aliases map[string]string // Key -> canonical name
+}
+
+// NewRegistry creates a registry with the given aliases
+func NewRegistry(aliases []Alias) *Registry {
+	r := &Registry{}
+	r.SetAliases(aliases)
+	return r
+}
+
+// SetAliases replaces the aliases. Every canonical name is an alias of itself, so other spellings of
+// it such as "xyz" for "XYZ" map to it without an alias of their own.
+func (r *Registry) SetAliases(aliases []Alias) {
+	m := make(map[string]string, 2*len(aliases))
+	for _, a := range aliases {
+		if key := Key(a.Brand); key != "" {
+			if _, ok := m[key]; !ok {
+				m[key] = Normalize(a.Brand)
+			}
+		}
+	}
+	// Explicit aliases win over the canonical names
+	for _, a := range aliases {
+		if key := Key(a.Alias); key != "" {
+			m[key] = Normalize(a.Brand)
+		}
+	}
+
+	r.mu.Lock()
+	r.aliases = m
+	r.mu.Unlock()
+}
+
+// Canonical returns the canonical name of a brand, the normalized name if no alias matches
+func (r *Registry) Canonical(raw string) string {
+	name := Normalize(raw)
+	if r == nil || name == "" {
+		return name
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if canonical, ok := r.aliases[Key(name)]; ok {
+		return canonical
+	}
+	return name
+}
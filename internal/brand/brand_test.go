@@ -0,0 +1,68 @@
+package brand
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"Besuchen Sie den XYZ-Store":       "XYZ",
+		"Visit the Jack & Jones Store":     "Jack & Jones",
+		"Marke: s.Oliver":                  "s.Oliver",
+		"XYZ GmbH":                         "XYZ",
+		"Tom Tailor GmbH & Co. KG":         "Tom Tailor",
+		"  Levi's   Store GmbH ":           "Levi's",
+		"Hugo Boss AG":                     "Hugo Boss",
+		"xyz":                              "xyz",
+		"Store":                            "Store",
+		"Besuchen Sie den ":                "Besuchen Sie den",
+		"Mustang, Inc.":                    "Mustang",
+		"Visitez la boutique Petit Bateau": "Petit Bateau",
+	}
+	for raw, want := range tests {
+		if got := Normalize(raw); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	if Key("Jack & Jones") != "jackjones" || Key("JACK&JONES GmbH") != "jackjones" {
+		t.Errorf("Key() = %q, %q", Key("Jack & Jones"), Key("JACK&JONES GmbH"))
+	}
+}
+
+func TestRegistry_Canonical(t *testing.T) {
+	r := NewRegistry([]Alias{
+		{Alias: "JJ", Brand: "Jack & Jones"},
+		{Alias: "Jack and Jones", Brand: "Jack & Jones"},
+	})
+
+	tests := map[string]string{
+		"Besuchen Sie den JJ-Store": "Jack & Jones",
+		"jack and jones":            "Jack & Jones",
+		"JACK & JONES":              "Jack & Jones", // Spelling of the canonical name
+		"XYZ GmbH":                  "XYZ",          // No alias
+		"":                          "",
+	}
+	for raw, want := range tests {
+		if got := r.Canonical(raw); got != want {
+			t.Errorf("Canonical(%q) = %q, want %q", raw, got, want)
+		}
+	}
+
+	var empty *Registry
+	if got := empty.Canonical("XYZ-Store"); got != "XYZ" {
+		t.Errorf("nil registry Canonical() = %q, want XYZ", got)
+	}
+}
+
+func TestAlias_Normalize(t *testing.T) {
+	a := Alias{Alias: " Besuchen Sie den JJ-Store ", Brand: "Jack & Jones GmbH"}
+	if err := a.Normalize(); err != nil || a.Alias != "JJ" || a.Brand != "Jack & Jones" {
+		t.Errorf("Normalize() = %+v, %v", a, err)
+	}
+	for _, invalid := range []Alias{{Alias: "", Brand: "XYZ"}, {Alias: "xyz", Brand: " - "}} {
+		if err := invalid.Normalize(); err == nil {
+			t.Errorf("Normalize(%+v) expected an error", invalid)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/scrapeerr"
+)
+
+// ErrMarketplaceCooldown is returned for navigations to a marketplace whose circuit breaker is open
+var ErrMarketplaceCooldown = fmt.Errorf("marketplace cooling down after high error rate: %w", scrapeerr.ErrBlocked)
+
+// Marketplace breaker states
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+// BreakerConfig controls when the breaker of a marketplace opens, a zero ErrorRate disables it
+type BreakerConfig struct {
+	ErrorRate   float64       // Share of failed navigations in the window that opens the breaker
+	Window      int           // Number of most recent navigations the error rate is computed over
+	MinRequests int           // Navigations needed in the window before the breaker may open
+	Cooldown    time.Duration // Pause of all navigations to the marketplace once open
+}
+
+// DefaultBreakerConfig opens after half of the last 20 navigations failed and pauses for 5 minutes
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{ErrorRate: 0.5, Window: 20, MinRequests: 10, Cooldown: 5 * time.Minute}
+}
+
+// MarketplaceState is the breaker state of one marketplace for monitoring
+type MarketplaceState struct {
+	Marketplace string    `json:"marketplace"`
+	State       string    `json:"state"`
+	ErrorRate   float64   `json:"error_rate"`
+	Trips       int64     `json:"trips"`
+	OpenedAt    time.Time `json:"opened_at,omitempty"`
+}
+
+type marketplaceCircuit struct {
+	outcomes []bool // Ring of recent navigations, true is a failure
+	next     int
+	state    string
+	openedAt time.Time
+	probing  bool
+	trips    int64
+}
+
+// MarketplaceBreaker pauses navigations to a marketplace after Amazon served too many error pages,
+// captchas or failed loads, shared by all pages and workers of a browser
+type MarketplaceBreaker struct {
+	mu       sync.Mutex
+	cfg      BreakerConfig
+	circuits map[string]*marketplaceCircuit
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+// NewMarketplaceBreaker creates a breaker with all marketplaces closed
+func NewMarketplaceBreaker(cfg BreakerConfig) *MarketplaceBreaker {
+	if cfg.Window <= 0 {
+		cfg.Window = DefaultBreakerConfig().Window
+	}
+	if cfg.MinRequests <= 0 || cfg.MinRequests > cfg.Window {
+		cfg.MinRequests = cfg.Window
+	}
+	return &MarketplaceBreaker{
+		cfg:      cfg,
+		circuits: make(map[string]*marketplaceCircuit),
+		logger:   slog.Default().With("component", "marketplace_breaker"),
+		now:      time.Now,
+	}
+}
+
+// Allow reports whether a navigation to the marketplace may start. Once the cooldown elapsed a single
+// probe is let through, its outcome closes or reopens the breaker.
+func (m *MarketplaceBreaker) Allow(marketplace string) bool {
+	if m.disabled() {
+		return true
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c := m.circuit(marketplace)
+	switch c.state {
+	case BreakerOpen:
+		if m.now().Sub(c.openedAt) < m.cfg.Cooldown {
+			return false
+		}
+		c.state = BreakerHalfOpen
+		c.probing = true
+		return true
+	case BreakerHalfOpen:
+		if c.probing {
+			return false
+		}
+		c.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// Record adds the outcome of a navigation, opening the breaker when the error rate reaches the threshold
+func (m *MarketplaceBreaker) Record(marketplace string, failed bool) {
+	if m.disabled() {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c := m.circuit(marketplace)
+	if c.state == BreakerHalfOpen {
+		c.probing = false
+		if failed {
+			m.open(marketplace, c)
+			return
+		}
+		m.logger.Info("marketplace circuit closed", "marketplace", marketplace)
+		c.state = BreakerClosed
+		c.outcomes, c.next = c.outcomes[:0], 0
+	}
+
+	if len(c.outcomes) < m.cfg.Window {
+		c.outcomes = append(c.outcomes, failed)
+	} else {
+		c.outcomes[c.next] = failed
+		c.next = (c.next + 1) % m.cfg.Window
+	}
+
+	if c.state == BreakerClosed && len(c.outcomes) >= m.cfg.MinRequests && errorRate(c.outcomes) >= m.cfg.ErrorRate {
+		m.open(marketplace, c)
+	}
+}
+
+// release ends a probe that was aborted before it had an outcome, e.g. by a cancelled context
+func (m *MarketplaceBreaker) release(marketplace string) {
+	if m.disabled() {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.circuit(marketplace).probing = false
+}
+
+// RetryAfter returns how long navigations to the marketplace stay paused
+func (m *MarketplaceBreaker) RetryAfter(marketplace string) time.Duration {
+	if m.disabled() {
+		return 0
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.circuits[marketplace]
+	if !ok {
+		return 0
+	}
+	switch {
+	case c.state == BreakerOpen:
+		return max(m.cfg.Cooldown-m.now().Sub(c.openedAt), 0)
+	case c.state == BreakerHalfOpen && c.probing:
+		// Wait for the outcome of the probe
+		return time.Second
+	}
+	return 0
+}
+
+// States returns the state of every marketplace seen so far, sorted by marketplace
+func (m *MarketplaceBreaker) States() []MarketplaceState {
+	if m.disabled() {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	states := make([]MarketplaceState, 0, len(m.circuits))
+	for marketplace, c := range m.circuits {
+		state := MarketplaceState{
+			Marketplace: marketplace,
+			State:       c.state,
+			ErrorRate:   errorRate(c.outcomes),
+			Trips:       c.trips,
+		}
+		if c.state != BreakerClosed {
+			state.OpenedAt = c.openedAt
+		}
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Marketplace < states[j].Marketplace })
+	return states
+}
+
+// WriteMetrics writes the breaker states in the Prometheus text format
+func (m *MarketplaceBreaker) WriteMetrics(w io.Writer) {
+	states := m.States()
+
+	fmt.Fprintln(w, "# HELP scraper_marketplace_breaker_open Whether scraping of the marketplace is paused, 1 while open or probing.")
+	fmt.Fprintln(w, "# TYPE scraper_marketplace_breaker_open gauge")
+	for _, s := range states {
+		open := 0
+		if s.State != BreakerClosed {
+			open = 1
+		}
+		fmt.Fprintf(w, "scraper_marketplace_breaker_open{marketplace=%q} %d\n", s.Marketplace, open)
+	}
+	fmt.Fprintln(w, "# HELP scraper_marketplace_error_rate Share of failed navigations in the breaker window.")
+	fmt.Fprintln(w, "# TYPE scraper_marketplace_error_rate gauge")
+	for _, s := range states {
+		fmt.Fprintf(w, "scraper_marketplace_error_rate{marketplace=%q} %g\n", s.Marketplace, s.ErrorRate)
+	}
+	fmt.Fprintln(w, "# HELP scraper_marketplace_breaker_trips_total Times the breaker of the marketplace opened.")
+	fmt.Fprintln(w, "# TYPE scraper_marketplace_breaker_trips_total counter")
+	for _, s := range states {
+		fmt.Fprintf(w, "scraper_marketplace_breaker_trips_total{marketplace=%q} %d\n", s.Marketplace, s.Trips)
+	}
+}
+
+// open trips the breaker, the error log is the alert while scraping of the marketplace is paused
+func (m *MarketplaceBreaker) open(marketplace string, c *marketplaceCircuit) {
+	c.state = BreakerOpen
+	c.openedAt = m.now()
+	c.trips++
+	m.logger.Error("marketplace circuit opened, pausing scraping",
+		"alert", "marketplace_breaker_open",
+		"marketplace", marketplace,
+		"error_rate", errorRate(c.outcomes),
+		"cooldown", m.cfg.Cooldown,
+		"trips", c.trips)
+}
+
+func (m *MarketplaceBreaker) circuit(marketplace string) *marketplaceCircuit {
+	c, ok := m.circuits[marketplace]
+	if !ok {
+		c = &marketplaceCircuit{state: BreakerClosed}
+		m.circuits[marketplace] = c
+	}
+	return c
+}
+
+func (m *MarketplaceBreaker) disabled() bool {
+	return m == nil || m.cfg.ErrorRate <= 0
+}
+
+func errorRate(outcomes []bool) float64 {
+	if len(outcomes) == 0 {
+		return 0
+	}
+	failed := 0
+	for _, f := range outcomes {
+		if f {
+			failed++
+		}
+	}
+	return float64(failed) / float64(len(outcomes))
+}
+
+// marketplaceOf returns the marketplace of a URL, e.g. amazon.de for https://www.amazon.de/dp/B08N5WRWNW
+func marketplaceOf(target string) string {
+	return strings.TrimPrefix(hostOf(target), "www.")
+}
+
+// MarketplaceBreaker returns the circuit breaker shared by all navigations of the browser
+func (b *Browser) MarketplaceBreaker() *MarketplaceBreaker {
+	return b.breaker
+}
+
+// WaitMarketplace blocks while the circuit breaker of the URL's marketplace is open, so workers
+// pause instead of failing every product during the cooldown
+func (b *Browser) WaitMarketplace(ctx context.Context, target string) error {
+	marketplace := marketplaceOf(target)
+	for {
+		wait := b.breaker.RetryAfter(marketplace)
+		if wait <= 0 {
+			return nil
+		}
+		b.logger.InfoContext(ctx, "waiting for marketplace cooldown", "marketplace", marketplace, "wait", wait)
+		if err := Sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
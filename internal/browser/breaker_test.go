@@ -0,0 +1,92 @@
+package browser
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestBreaker(now *time.Time) *MarketplaceBreaker {
+	m := NewMarketplaceBreaker(BreakerConfig{ErrorRate: 0.5, Window: 4, MinRequests: 4, Cooldown: time.Minute})
+	m.now = func() time.Time { return *now }
+	return m
+}
+
+func TestMarketplaceBreakerOpensAtErrorRate(t *testing.T) {
+	now := time.Now()
+	m := newTestBreaker(&now)
+
+	m.Record("amazon.de", true)
+	m.Record("amazon.de", false)
+	m.Record("amazon.de", true)
+	if !m.Allow("amazon.de") {
+		t.Fatal("Expected breaker to stay closed below MinRequests")
+	}
+
+	m.Record("amazon.de", false)
+	if m.Allow("amazon.de") {
+		t.Fatal("Expected breaker to open at an error rate of 0.5")
+	}
+	if !m.Allow("amazon.co.uk") {
+		t.Error("Expected other marketplaces to stay closed")
+	}
+	if got := m.RetryAfter("amazon.de"); got != time.Minute {
+		t.Errorf("Expected retry after 1m, got %s", got)
+	}
+}
+
+func TestMarketplaceBreakerHalfOpenProbe(t *testing.T) {
+	now := time.Now()
+	m := newTestBreaker(&now)
+	for n := 0; n < 4; n++ {
+		m.Record("amazon.de", true)
+	}
+
+	now = now.Add(time.Minute)
+	if !m.Allow("amazon.de") {
+		t.Fatal("Expected a probe after the cooldown")
+	}
+	if m.Allow("amazon.de") {
+		t.Fatal("Expected a single probe while half open")
+	}
+
+	// A failed probe reopens the breaker
+	m.Record("amazon.de", true)
+	if m.Allow("amazon.de") {
+		t.Fatal("Expected breaker to reopen after a failed probe")
+	}
+
+	now = now.Add(time.Minute)
+	if !m.Allow("amazon.de") {
+		t.Fatal("Expected a second probe after the cooldown")
+	}
+	m.Record("amazon.de", false)
+	if !m.Allow("amazon.de") || !m.Allow("amazon.de") {
+		t.Error("Expected breaker to close after a successful probe")
+	}
+
+	states := m.States()
+	if len(states) != 1 || states[0].State != BreakerClosed || states[0].Trips != 2 {
+		t.Errorf("Unexpected states: %+v", states)
+	}
+}
+
+func TestMarketplaceBreakerDisabled(t *testing.T) {
+	m := NewMarketplaceBreaker(BreakerConfig{})
+	for n := 0; n < 50; n++ {
+		m.Record("amazon.de", true)
+	}
+	if !m.Allow("amazon.de") {
+		t.Error("Expected a zero error rate to disable the breaker")
+	}
+
+	var nilBreaker *MarketplaceBreaker
+	if !nilBreaker.Allow("amazon.de") || nilBreaker.RetryAfter("amazon.de") != 0 {
+		t.Error("Expected a nil breaker to allow everything")
+	}
+}
+
+func TestMarketplaceOf(t *testing.T) {
+	if got := marketplaceOf("https://www.amazon.de/dp/B08N5WRWNW"); got != "amazon.de" {
+		t.Errorf("Expected amazon.de, got %s", got)
+	}
+}
@@ -1,32 +1,84 @@
 package browser
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math/rand"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/playwright-community/playwright-go"
+
+	"github.com/maltedev/amazon-size-scraper/internal/scrapeerr"
 )
 
+// ErrBrowserDisconnected is returned when the underlying browser process is gone
+var ErrBrowserDisconnected = errors.New("browser disconnected")
+
+// ErrCaptcha marks pages blocked by a robot check that could not be bypassed
+var ErrCaptcha = scrapeerr.ErrCaptcha
+
+// ErrDogPage marks Amazon's "Tut uns Leid" error page
+var ErrDogPage = errors.New("Amazon error page detected")
+
 type Browser struct {
-	pw      *playwright.Playwright
-	browser playwright.Browser
-	context playwright.BrowserContext
-	logger  *slog.Logger
+	mu        sync.RWMutex
+	pw        *playwright.Playwright
+	browser   playwright.Browser
+	context   playwright.BrowserContext
+	opts      *Options
+	connected atomic.Bool
+	restarts  atomic.Int64
+	relaunch  sync.Mutex // Serializes relaunches, so concurrent callers start a single new browser
+	logger    *slog.Logger
+
+	blockedRequests atomic.Int64
+	proxies         proxyPool       // Per-context proxies handed out by NewContext
+	sessions        *SessionMetrics // Anti-detection signals per context
+	breaker         *MarketplaceBreaker
+
+	navMu   sync.Mutex
+	learned map[string]NavigationStrategy // Host -> strategy that succeeded after escalation
+
+	humanMu   sync.Mutex
+	humanRand *rand.Rand // Draws mouse paths and pauses of HumanizeTask
 }
 
 type Options struct {
-	Headless        bool
-	Timeout         time.Duration
-	UserAgent       string
-	ViewportWidth   int
-	ViewportHeight  int
-	AcceptLanguage  string
-	TimezoneID      string
-	Locale          string
-	ProxyServer     string
-	ExtraHeaders    map[string]string
+	Headless       bool
+	Timeout        time.Duration
+	UserAgent      string
+	ViewportWidth  int
+	ViewportHeight int
+	AcceptLanguage string
+	TimezoneID     string
+	Locale         string
+	ProxyServer    string   // Deprecated: use Proxy
+	Proxy          *Proxy   // Proxy of the browser and its shared context, may carry credentials
+	ContextProxies []*Proxy // Proxies assigned round robin to contexts from NewContext
+	ExtraHeaders   map[string]string
+	DiagnosticsDir string // Screenshots and DOM snippets of failed pages, empty disables capture
+	Breaker        BreakerConfig // Pause a marketplace after too many failed navigations, zero ErrorRate disables
+	Fingerprint    string // Label of the anti-detection setup in session metrics, empty derives one from user agent and viewport
+	BypassAgeGate  bool   // Confirm age verification interstitials instead of failing with ErrAgeVerification
+
+	Navigation          NavigationStrategy            // Default strategy, empty means direct
+	NavigationOverrides map[string]NavigationStrategy // Per marketplace host or proxy server
+	EscalateNavigation  bool                          // Try the next strategy after a failed attempt
+
+	ResourcePolicies map[string]ResourcePolicy // Blocked resources per task type, nil disables blocking
+	DownloadImages   bool                      // Never block images, e.g. when images are stored or OCRed
+	AnalyticsDomains []string                  // Hosts blocked by the analytics category, nil uses DefaultAnalyticsDomains
+
+	SizeChartLayouts     []SizeChartLayout // Size chart selector registry, nil uses DefaultSizeChartLayouts
+	SizeChartTimeout     time.Duration     // Wait for a size chart layout to appear, 0 uses DefaultSizeChartTimeout
+	SizeChartNetworkIdle bool              // Wait for network idle before polling for the size chart
+
+	HumanizeProfiles map[string]string // Humanization profile per task type, missing tasks use the default task or normal
 }
 
 func DefaultOptions() *Options {
@@ -39,11 +91,18 @@ func DefaultOptions() *Options {
 		AcceptLanguage: "de-DE,de;q=0.9,en;q=0.8",
 		TimezoneID:     "Europe/Berlin",
 		Locale:         "de-DE",
+		DiagnosticsDir: "diagnostics",
 		ExtraHeaders: map[string]string{
 			"Accept":          "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8",
 			"Accept-Encoding": "gzip, deflate, br",
 			"DNT":             "1",
 		},
+
+		Navigation:         NavigateDirect,
+		EscalateNavigation: true,
+
+		ResourcePolicies: DefaultResourcePolicies(),
+		HumanizeProfiles: DefaultHumanizeProfiles(HumanizeNormal),
 	}
 }
 
@@ -52,66 +111,215 @@ func New(opts *Options) (*Browser, error) {
 		opts = DefaultOptions()
 	}
 
+	b := &Browser{
+		opts:     opts,
+		logger:   slog.Default().With("component", "browser"),
+		proxies:  proxyPool{proxies: opts.ContextProxies},
+		sessions: NewSessionMetrics(),
+		breaker:  NewMarketplaceBreaker(opts.Breaker),
+
+		humanRand: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+
+	if err := b.launch(); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// launch starts playwright, the browser and a fresh context with the stored options
+func (b *Browser) launch() error {
+	opts := b.opts
+
 	pw, err := playwright.Run()
 	if err != nil {
-		return nil, fmt.Errorf("failed to start playwright: %w", err)
+		return fmt.Errorf("failed to start playwright: %w", err)
 	}
 
+	browser, err := pw.Chromium.Launch(b.launchOptions(opts.Headless))
+	if err != nil {
+		pw.Stop()
+		return fmt.Errorf("failed to launch browser: %w", err)
+	}
+
+	context, err := browser.NewContext(b.contextOptions())
+	if err != nil {
+		browser.Close()
+		pw.Stop()
+		return fmt.Errorf("failed to create browser context: %w", err)
+	}
+	b.trackSession(context, b.launchProxy())
+
+	b.mu.Lock()
+	b.pw = pw
+	b.browser = browser
+	b.context = context
+	b.connected.Store(true)
+	// Track crashes so callers can relaunch instead of failing every NewPage. The handler waits for b.mu,
+	// so a crash right after launch still sees the new browser.
+	browser.OnDisconnected(func(playwright.Browser) {
+		b.disconnected(browser)
+	})
+	b.mu.Unlock()
+
+	return nil
+}
+
+// disconnected marks the browser disconnected if browser is still the current one, a late event of a
+// browser replaced by a relaunch leaves its successor alone
+func (b *Browser) disconnected(browser playwright.Browser) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.browser != browser {
+		return
+	}
+	if b.connected.CompareAndSwap(true, false) {
+		b.logger.Warn("browser disconnected")
+	}
+}
+
+// launchOptions returns the anti-detection flags and launch proxy shared by all browser processes
+func (b *Browser) launchOptions(headless bool, extraArgs ...string) playwright.BrowserTypeLaunchOptions {
 	launchOpts := playwright.BrowserTypeLaunchOptions{
-		Headless: &opts.Headless,
-		Args: []string{
+		Headless: playwright.Bool(headless),
+		Args: append([]string{
 			"--disable-blink-features=AutomationControlled",
 			"--disable-dev-shm-usage",
 			"--no-sandbox",
 			"--disable-setuid-sandbox",
 			"--window-size=1920,1080",
 			"--start-maximized",
-			"--user-agent=" + opts.UserAgent,
-		},
+			"--user-agent=" + b.opts.UserAgent,
+		}, extraArgs...),
 	}
 
-	if opts.ProxyServer != "" {
-		launchOpts.Proxy = &playwright.Proxy{
-			Server: opts.ProxyServer,
-		}
-	}
-
-	browser, err := pw.Chromium.Launch(launchOpts)
-	if err != nil {
-		pw.Stop()
-		return nil, fmt.Errorf("failed to launch browser: %w", err)
+	if proxy := b.launchProxy(); proxy != nil {
+		launchOpts.Proxy = proxy.playwright()
 	}
+	return launchOpts
+}
 
-	contextOpts := playwright.BrowserNewContextOptions{
-		UserAgent:      &opts.UserAgent,
-		AcceptDownloads: playwright.Bool(false),
+// contextOptions returns the fingerprint settings shared by all browser contexts
+func (b *Browser) contextOptions() playwright.BrowserNewContextOptions {
+	opts := b.opts
+	return playwright.BrowserNewContextOptions{
+		UserAgent:         &opts.UserAgent,
+		AcceptDownloads:   playwright.Bool(false),
 		JavaScriptEnabled: playwright.Bool(true), // Explicitly enable JavaScript
-		Locale:         &opts.Locale,
-		TimezoneId:     &opts.TimezoneID,
+		Locale:            &opts.Locale,
+		TimezoneId:        &opts.TimezoneID,
 		Viewport: &playwright.Size{
 			Width:  opts.ViewportWidth,
 			Height: opts.ViewportHeight,
 		},
 		ExtraHttpHeaders: opts.ExtraHeaders,
 	}
+}
+
+// NewContext creates an isolated browser context with its own cookies and the next context proxy,
+// the caller must close it
+func (b *Browser) NewContext() (playwright.BrowserContext, error) {
+	return b.NewContextWithProxy(b.proxies.Next())
+}
+
+// NewContextWithProxy creates an isolated browser context using the proxy, nil uses the launch proxy
+func (b *Browser) NewContextWithProxy(proxy *Proxy) (playwright.BrowserContext, error) {
+	if !b.IsConnected() {
+		return nil, ErrBrowserDisconnected
+	}
+
+	b.mu.RLock()
+	browser := b.browser
+	b.mu.RUnlock()
+
+	opts := b.contextOptions()
+	if proxy != nil {
+		opts.Proxy = proxy.playwright()
+	}
 
-	context, err := browser.NewContext(contextOpts)
+	context, err := browser.NewContext(opts)
 	if err != nil {
-		browser.Close()
-		pw.Stop()
 		return nil, fmt.Errorf("failed to create browser context: %w", err)
 	}
+	if proxy != nil {
+		b.logger.Debug("created browser context", "proxy", proxy.String())
+	} else {
+		proxy = b.launchProxy()
+	}
+	b.trackSession(context, proxy)
+	return context, nil
+}
 
-	return &Browser{
-		pw:      pw,
-		browser: browser,
-		context: context,
-		logger:  slog.Default().With("component", "browser"),
-	}, nil
+// IsConnected reports whether the browser process is still alive
+func (b *Browser) IsConnected() bool {
+	return b.connected.Load()
+}
+
+// Restarts returns how often the browser has been relaunched
+func (b *Browser) Restarts() int64 {
+	return b.restarts.Load()
+}
+
+// Relaunch tears down the current browser and starts a new one with the same options
+func (b *Browser) Relaunch() error {
+	b.relaunch.Lock()
+	defer b.relaunch.Unlock()
+	return b.relaunchLocked()
+}
+
+// RelaunchIfDisconnected relaunches the browser unless it is connected. Callers that saw the same
+// crash wait for the first relaunch instead of tearing down the browser it started.
+func (b *Browser) RelaunchIfDisconnected() error {
+	if b.IsConnected() {
+		return nil
+	}
+
+	b.relaunch.Lock()
+	defer b.relaunch.Unlock()
+	if b.IsConnected() {
+		return nil
+	}
+	return b.relaunchLocked()
+}
+
+// relaunchLocked restarts the browser, the caller holds b.relaunch
+func (b *Browser) relaunchLocked() error {
+	b.logger.Warn("relaunching browser", "restarts", b.restarts.Load())
+
+	b.connected.Store(false)
+	if err := b.shutdown(); err != nil {
+		// The old process is usually already dead, so cleanup errors are expected
+		b.logger.Debug("cleanup of crashed browser failed", "error", err)
+	}
+
+	if err := b.launch(); err != nil {
+		return fmt.Errorf("failed to relaunch browser: %w", err)
+	}
+
+	b.restarts.Add(1)
+	b.logger.Info("browser relaunched", "restarts", b.restarts.Load())
+	return nil
 }
 
 func (b *Browser) NewPage() (playwright.Page, error) {
-	page, err := b.context.NewPage()
+	return b.newPage(nil)
+}
+
+// newPage opens a page in the given context, nil uses the shared context
+func (b *Browser) newPage(context playwright.BrowserContext) (playwright.Page, error) {
+	if !b.IsConnected() {
+		return nil, ErrBrowserDisconnected
+	}
+
+	if context == nil {
+		b.mu.RLock()
+		context = b.context
+		b.mu.RUnlock()
+	}
+
+	page, err := context.NewPage()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create new page: %w", err)
 	}
@@ -122,26 +330,40 @@ func (b *Browser) NewPage() (playwright.Page, error) {
 }
 
 func (b *Browser) Context() playwright.BrowserContext {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
 	return b.context
 }
 
 func (b *Browser) Close() error {
+	b.connected.Store(false)
+	return b.shutdown()
+}
+
+// shutdown closes context, browser and playwright driver. They are closed outside b.mu, since closing
+// the browser fires its disconnect handler, which takes b.mu.
+func (b *Browser) shutdown() error {
+	b.mu.Lock()
+	browserContext, browser, pw := b.context, b.browser, b.pw
+	b.context, b.browser, b.pw = nil, nil, nil
+	b.mu.Unlock()
+
 	var errs []error
 
-	if b.context != nil {
-		if err := b.context.Close(); err != nil {
+	if browserContext != nil {
+		if err := browserContext.Close(); err != nil {
 			errs = append(errs, fmt.Errorf("failed to close context: %w", err))
 		}
 	}
 
-	if b.browser != nil {
-		if err := b.browser.Close(); err != nil {
+	if browser != nil {
+		if err := browser.Close(); err != nil {
 			errs = append(errs, fmt.Errorf("failed to close browser: %w", err))
 		}
 	}
 
-	if b.pw != nil {
-		if err := b.pw.Stop(); err != nil {
+	if pw != nil {
+		if err := pw.Stop(); err != nil {
 			errs = append(errs, fmt.Errorf("failed to stop playwright: %w", err))
 		}
 	}
@@ -153,45 +375,108 @@ func (b *Browser) Close() error {
 	return nil
 }
 
+// NavigateWithRetry navigates using the configured strategy, escalating it after failed attempts if enabled
 func (b *Browser) NavigateWithRetry(page playwright.Page, url string, maxRetries int) error {
+	return b.NavigateWithRetryContext(context.Background(), page, url, maxRetries)
+}
+
+// NavigateWithRetryContext is NavigateWithRetry with waits between attempts aborted when ctx is done
+func (b *Browser) NavigateWithRetryContext(ctx context.Context, page playwright.Page, url string, maxRetries int) error {
 	var lastErr error
-	
+	gone := 0 // Attempts that found the listing deleted
+
+	initial := b.NavigationStrategyFor(url)
+	strategy := initial
+	marketplace := marketplaceOf(url)
+
 	for i := 0; i < maxRetries; i++ {
+		if !b.breaker.Allow(marketplace) {
+			return fmt.Errorf("%w: %s, retry in %s", ErrMarketplaceCooldown, marketplace, b.breaker.RetryAfter(marketplace).Round(time.Second))
+		}
+
 		if i > 0 {
-			b.logger.Info("retrying navigation", "attempt", i+1, "url", url)
-			time.Sleep(time.Duration(i+1) * time.Second)
+			if b.opts.EscalateNavigation {
+				strategy = escalate(strategy)
+			}
+			b.logger.Info("retrying navigation", "attempt", i+1, "url", url, "strategy", strategy)
+			if err := Sleep(ctx, time.Duration(i+1)*time.Second); err != nil {
+				return err
+			}
 		}
 		
-		_, err := page.Goto(url, playwright.PageGotoOptions{
-			WaitUntil: playwright.WaitUntilStateDomcontentloaded,
-			Timeout:   playwright.Float(30000),
-		})
+		started := time.Now()
+		err := b.navigate(ctx, page, url, strategy)
+		b.sessions.navigation(page.Context(), time.Since(started), err == nil)
 		
 		if err == nil {
 			// Check for bot protection after successful navigation
-			protected, err := b.CheckAndBypassBotProtection(page)
-			if err != nil {
-				b.logger.Error("failed to check bot protection", "error", err)
+			var protected bool
+			protected, err = b.checkBotProtection(ctx, page)
+			if IsInterstitial(err) {
+				// Amazon served the page as intended, another attempt gets the same interstitial
+				b.recordOutcome(ctx, marketplace, nil)
+				return err
+			}
+			if err == nil {
+				b.recordOutcome(ctx, marketplace, nil)
+				if protected {
+					b.logger.Info("bot protection bypassed")
+				}
+				if strategy != initial {
+					b.learn(url, strategy)
+				}
+				return nil
+			}
+			if !IsProductGone(err) {
+				b.recordOutcome(ctx, marketplace, err)
+				b.logger.Error("failed to check bot protection", "error", err, "strategy", strategy)
 				lastErr = err
 				continue
 			}
-			if protected {
-				b.logger.Info("bot protection bypassed")
-			}
-			return nil
+		}
+
+		if IsProductGone(err) {
+			// Amazon answered, a deleted listing says nothing about blocking. Retrying rules out a
+			// listing that was only briefly unavailable.
+			b.recordOutcome(ctx, marketplace, nil)
+			gone++
+			lastErr = err
+			b.logger.Warn("listing not found", "error", err, "attempt", i+1, "url", url)
+			continue
 		}
 		
+		b.recordOutcome(ctx, marketplace, err)
 		lastErr = err
-		b.logger.Error("navigation failed", "error", err, "attempt", i+1)
+		b.logger.Error("navigation failed", "error", err, "attempt", i+1, "strategy", strategy)
 	}
-	
-	return fmt.Errorf("failed after %d retries: %w", maxRetries, lastErr)
+
+	if IsProductGone(lastErr) && gone < maxRetries {
+		// Only a listing missing on every attempt counts as gone
+		return fmt.Errorf("%w after %d retries, listing missing in %d: %s", scrapeerr.ErrNavigation, maxRetries, gone, lastErr)
+	}
+	return fmt.Errorf("%w after %d retries: %w", scrapeerr.ErrNavigation, maxRetries, lastErr)
+}
+
+// recordOutcome feeds a navigation attempt to the marketplace breaker, attempts cut off by ctx do not count
+func (b *Browser) recordOutcome(ctx context.Context, marketplace string, err error) {
+	if err != nil && ctx.Err() != nil {
+		b.breaker.release(marketplace)
+		return
+	}
+	b.breaker.Record(marketplace, err != nil)
 }
 
 // CheckAndBypassBotProtection checks for Amazon bot protection and attempts to bypass it
 func (b *Browser) CheckAndBypassBotProtection(page playwright.Page) (bool, error) {
+	return b.checkBotProtection(context.Background(), page)
+}
+
+// checkBotProtection implements CheckAndBypassBotProtection with ctx-aware waits
+func (b *Browser) checkBotProtection(ctx context.Context, page playwright.Page) (bool, error) {
 	// Wait a bit for page to fully load
-	time.Sleep(2 * time.Second)
+	if err := Sleep(ctx, 2*time.Second); err != nil {
+		return false, err
+	}
 	
 	// Check page title for bot check indicators
 	title, err := page.Title()
@@ -211,6 +496,7 @@ func (b *Browser) CheckAndBypassBotProtection(page playwright.Page) (bool, error
 	if strings.Contains(content, "Klicke auf die Schaltfläche unten") ||
 	   strings.Contains(content, "Weiter shoppen") {
 		b.logger.Info("bot protection detected, attempting bypass")
+		session := page.Context()
 		
 		// Try different button selectors
 		buttonSelectors := []string{
@@ -238,23 +524,44 @@ func (b *Browser) CheckAndBypassBotProtection(page playwright.Page) (bool, error
 			}
 			
 			// Wait for navigation
-			time.Sleep(3 * time.Second)
+			if err := Sleep(ctx, 3*time.Second); err != nil {
+				return false, err
+			}
 			
 			// Verify we're past the check
 			newContent, _ := page.Content()
 			
 			if !strings.Contains(newContent, "Klicke auf die Schaltfläche unten") {
 				b.logger.Info("successfully bypassed bot protection")
+				b.sessions.botCheck(session, true)
 				return true, nil
 			}
 		}
 		
-		return false, fmt.Errorf("could not find button to bypass bot protection")
+		b.sessions.botCheck(session, false)
+		return false, fmt.Errorf("%w: could not find button to bypass bot protection", ErrCaptcha)
+	}
+
+	// The image captcha cannot be bypassed by clicking through
+	if strings.Contains(content, "captchacharacters") || strings.Contains(content, "/errors/validateCaptcha") {
+		b.sessions.captcha(page.Context())
+		return false, fmt.Errorf("%w: robot check requires solving a captcha", ErrCaptcha)
 	}
 	
+	// Age gates and sign-in forms in place of the requested page
+	if err := b.checkInterstitial(ctx, page, content); err != nil {
+		return false, err
+	}
+
+	// Dog page of a deleted listing
+	if detectGone(content) {
+		return false, fmt.Errorf("%w: %s", ErrProductGone, page.URL())
+	}
+
 	// Check for "Tut uns Leid" error page
 	if strings.Contains(title, "Tut uns Leid") || strings.Contains(content, "Tut uns Leid") {
-		return false, fmt.Errorf("Amazon error page detected")
+		b.sessions.dogPage(page.Context())
+		return false, ErrDogPage
 	}
 	
 	return false, nil
@@ -262,17 +569,10 @@ func (b *Browser) CheckAndBypassBotProtection(page playwright.Page) (bool, error
 
 // HumanizeInteraction adds human-like behavior to page interactions
 func (b *Browser) HumanizeInteraction(page playwright.Page) error {
-	// Random mouse movements
-	for i := 0; i < 3; i++ {
-		x := float64(100 + i*200)
-		y := float64(100 + i*150)
-		page.Mouse().Move(x, y)
-		time.Sleep(time.Millisecond * time.Duration(200+i*100))
-	}
-	
-	// Random scroll
-	page.Evaluate(`window.scrollBy(0, Math.random() * 300)`)
-	time.Sleep(time.Second)
-	
-	return nil
-}
\ No newline at end of file
+	return b.HumanizeInteractionContext(context.Background(), page)
+}
+
+// HumanizeInteractionContext is HumanizeTask with the profile of the default task
+func (b *Browser) HumanizeInteractionContext(ctx context.Context, page playwright.Page) error {
+	return b.HumanizeTask(ctx, page, TaskDefault)
+}
@@ -0,0 +1,212 @@
+package browser
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// DefaultDebugPort is the remote debugging port of debug sessions
+const DefaultDebugPort = 9222
+
+// DefaultDebugTTL is how long a debug session stays open unless it is closed before
+const DefaultDebugTTL = 15 * time.Minute
+
+var (
+	// ErrDebugSessionActive is returned when a debug session is started while another one is open
+	ErrDebugSessionActive = errors.New("a debug session is already open")
+	// ErrDebugSessionNotFound is returned for unknown and expired debug sessions
+	ErrDebugSessionNotFound = errors.New("debug session not found")
+)
+
+// DebugConfig configures debug sessions, which reproduce bot checks in a browser a developer watches
+type DebugConfig struct {
+	Headed bool          // Show the browser window, needs a display, e.g. Xvfb viewed through noVNC in containers
+	Port   int           // Remote debugging port, bound to localhost, 0 uses DefaultDebugPort
+	TTL    time.Duration // Sessions are closed after this long, 0 uses DefaultDebugTTL
+	VNCURL string        // noVNC page showing the display of headed sessions, empty if there is none
+}
+
+// DebugSession is a dedicated browser with remote debugging enabled, opened on one product page. It
+// uses the fingerprint and proxy of the scraper but none of its contexts, cookies or metrics.
+type DebugSession struct {
+	ID              string    `json:"id"`
+	ASIN            string    `json:"asin"`
+	URL             string    `json:"url"`
+	Headed          bool      `json:"headed"`
+	NavigationError string    `json:"navigation_error,omitempty"` // The page stays open for inspection
+	StartedAt       time.Time `json:"started_at"`
+	ExpiresAt       time.Time `json:"expires_at"`
+
+	endpoint string // DevTools HTTP endpoint on localhost
+	pw       *playwright.Playwright
+	browser  playwright.Browser
+	timer    *time.Timer
+}
+
+// Endpoint returns the DevTools HTTP endpoint of the session, e.g. http://127.0.0.1:9222
+func (s *DebugSession) Endpoint() string {
+	return s.endpoint
+}
+
+func (s *DebugSession) close() error {
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	var errs []error
+	if err := s.browser.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to close debug browser: %w", err))
+	}
+	if err := s.pw.Stop(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to stop playwright: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// DebugSessions opens one debug session at a time, remote debugging has a single port and a second
+// browser watched by nobody only adds load
+type DebugSessions struct {
+	browser *Browser
+	cfg     DebugConfig
+
+	mu      sync.Mutex
+	session *DebugSession
+}
+
+// NewDebugSessions creates the debug sessions of b
+func NewDebugSessions(b *Browser, cfg DebugConfig) *DebugSessions {
+	if cfg.Port == 0 {
+		cfg.Port = DefaultDebugPort
+	}
+	if cfg.TTL == 0 {
+		cfg.TTL = DefaultDebugTTL
+	}
+	return &DebugSessions{browser: b, cfg: cfg}
+}
+
+// VNCURL returns the noVNC page of headed sessions, empty if there is none
+func (d *DebugSessions) VNCURL() string {
+	if !d.cfg.Headed {
+		return ""
+	}
+	return d.cfg.VNCURL
+}
+
+// Start launches a debug browser and opens url, the product page of asin, with the navigation strategy
+// of the scraper. Bot checks are not bypassed, so the developer sees what the scraper ran into.
+func (d *DebugSessions) Start(ctx context.Context, asin, url string) (*DebugSession, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.session != nil {
+		return nil, fmt.Errorf("%w: %s for %s", ErrDebugSessionActive, d.session.ID, d.session.ASIN)
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate session ID: %w", err)
+	}
+
+	pw, err := playwright.Run()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start playwright: %w", err)
+	}
+	browser, err := pw.Chromium.Launch(d.browser.launchOptions(!d.cfg.Headed,
+		fmt.Sprintf("--remote-debugging-port=%d", d.cfg.Port),
+		"--remote-debugging-address=127.0.0.1",
+	))
+	if err != nil {
+		pw.Stop()
+		return nil, fmt.Errorf("failed to launch debug browser: %w", err)
+	}
+	session := &DebugSession{
+		ID:        hex.EncodeToString(id),
+		ASIN:      asin,
+		URL:       url,
+		Headed:    d.cfg.Headed,
+		StartedAt: time.Now(),
+		ExpiresAt: time.Now().Add(d.cfg.TTL),
+		endpoint:  fmt.Sprintf("http://127.0.0.1:%d", d.cfg.Port),
+		pw:        pw,
+		browser:   browser,
+	}
+
+	page, err := d.openPage(browser)
+	if err != nil {
+		session.close()
+		return nil, err
+	}
+	if err := d.browser.navigate(ctx, page, url, d.browser.NavigationStrategyFor(url)); err != nil {
+		session.NavigationError = err.Error()
+	}
+
+	session.timer = time.AfterFunc(d.cfg.TTL, func() {
+		if err := d.Close(session.ID); err == nil {
+			d.browser.logger.Info("debug session expired", "id", session.ID, "asin", asin)
+		}
+	})
+	d.session = session
+	d.browser.logger.Info("debug session started",
+		"id", session.ID,
+		"asin", asin,
+		"headed", d.cfg.Headed,
+		"port", d.cfg.Port,
+		"expires_at", session.ExpiresAt)
+	return session, nil
+}
+
+// openPage opens a page in a context with the fingerprint of the scraper
+func (d *DebugSessions) openPage(browser playwright.Browser) (playwright.Page, error) {
+	context, err := browser.NewContext(d.browser.contextOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create debug context: %w", err)
+	}
+	page, err := context.NewPage()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create debug page: %w", err)
+	}
+	return page, nil
+}
+
+// Get returns the open session with id
+func (d *DebugSessions) Get(id string) (*DebugSession, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.session == nil || d.session.ID != id {
+		return nil, ErrDebugSessionNotFound
+	}
+	return d.session, nil
+}
+
+// Current returns the open session, nil if there is none
+func (d *DebugSessions) Current() *DebugSession {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.session
+}
+
+// Close closes the session with id and its browser
+func (d *DebugSessions) Close(id string) error {
+	d.mu.Lock()
+	session := d.session
+	if session == nil || session.ID != id {
+		d.mu.Unlock()
+		return ErrDebugSessionNotFound
+	}
+	d.session = nil
+	d.mu.Unlock()
+	return session.close()
+}
+
+// CloseAll closes the open session, e.g. on shutdown
+func (d *DebugSessions) CloseAll() error {
+	if session := d.Current(); session != nil {
+		return d.Close(session.ID)
+	}
+	return nil
+}
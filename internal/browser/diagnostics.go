@@ -0,0 +1,80 @@
+package browser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// maxDOMSnippetBytes caps the stored DOM snippet so a full page dump does not fill the disk
+const maxDOMSnippetBytes = 256 * 1024
+
+// Diagnostics references the artifacts captured when an extraction fails
+type Diagnostics struct {
+	ScreenshotPath string `json:"screenshot_path,omitempty"`
+	DOMSnippetPath string `json:"dom_snippet_path,omitempty"`
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// CaptureFailure stores a screenshot and the DOM of the modal area for a failed page.
+// It returns nil when no diagnostics directory is configured.
+func (b *Browser) CaptureFailure(page playwright.Page, name string) (*Diagnostics, error) {
+	dir := b.opts.DiagnosticsDir
+	if dir == "" || page == nil {
+		return nil, nil
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create diagnostics dir: %w", err)
+	}
+
+	base := fmt.Sprintf("%s-%s", unsafeFileChars.ReplaceAllString(name, "_"), time.Now().Format("20060102-150405"))
+	diag := &Diagnostics{}
+
+	screenshotPath := filepath.Join(dir, base+".png")
+	if _, err := page.Screenshot(playwright.PageScreenshotOptions{
+		Path:     playwright.String(screenshotPath),
+		FullPage: playwright.Bool(false),
+	}); err != nil {
+		b.logger.Warn("failed to capture screenshot", "name", name, "error", err)
+	} else {
+		diag.ScreenshotPath = screenshotPath
+	}
+
+	// Prefer the size chart popover/modal, fall back to the product detail area
+	snippet, err := page.Evaluate(`() => {
+		const el = document.querySelector('.a-popover-content, .a-modal-content, [id*="popover"]')
+			|| document.querySelector('#dp-container, #dp')
+			|| document.body;
+		return el ? el.outerHTML : '';
+	}`)
+	if err != nil {
+		b.logger.Warn("failed to capture DOM snippet", "name", name, "error", err)
+	} else if html, ok := snippet.(string); ok && html != "" {
+		if len(html) > maxDOMSnippetBytes {
+			html = html[:maxDOMSnippetBytes]
+		}
+		domPath := filepath.Join(dir, base+".html")
+		if err := os.WriteFile(domPath, []byte(html), 0o644); err != nil {
+			b.logger.Warn("failed to write DOM snippet", "name", name, "error", err)
+		} else {
+			diag.DOMSnippetPath = domPath
+		}
+	}
+
+	if diag.ScreenshotPath == "" && diag.DOMSnippetPath == "" {
+		return nil, fmt.Errorf("failed to capture diagnostics for %s", name)
+	}
+
+	return diag, nil
+}
+
+// DiagnosticsDir returns the directory failure diagnostics are written to
+func (b *Browser) DiagnosticsDir() string {
+	return b.opts.DiagnosticsDir
+}
@@ -0,0 +1,41 @@
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/maltedev/amazon-size-scraper/internal/scrapeerr"
+)
+
+// ErrProductGone marks a listing Amazon answers with 404 or 410 or its "not a functioning page" dog
+// page. Navigations only return it when every attempt agreed, a single one may be a hiccup.
+var ErrProductGone = fmt.Errorf("listing gone: %w", scrapeerr.ErrProductNotFound)
+
+// IsProductGone reports whether err means the listing was deleted
+func IsProductGone(err error) bool {
+	return errors.Is(err, ErrProductGone)
+}
+
+// goneMarkers identify the dog page of a deleted listing. The generic "Tut uns Leid" page is also
+// served for server errors and stays a retryable ErrDogPage.
+var goneMarkers = []string{
+	"keine funktionierende Seite",
+	"not a functioning page",
+	"n'est pas une page active",
+	"non è una pagina funzionante",
+	"no es una página operativa",
+}
+
+// goneStatus returns ErrProductGone for the status codes of a deleted page
+func goneStatus(status int) error {
+	if status == http.StatusNotFound || status == http.StatusGone {
+		return fmt.Errorf("%w: HTTP %d", ErrProductGone, status)
+	}
+	return nil
+}
+
+// detectGone reports whether content is the dog page of a deleted listing
+func detectGone(content string) bool {
+	return containsAny(content, goneMarkers)
+}
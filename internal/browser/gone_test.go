@@ -0,0 +1,40 @@
+package browser
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestGoneStatus(t *testing.T) {
+	for status, want := range map[int]bool{200: false, 301: false, 404: true, 410: true, 503: false} {
+		if got := IsProductGone(goneStatus(status)); got != want {
+			t.Errorf("goneStatus(%d) gone = %v, want %v", status, got, want)
+		}
+	}
+}
+
+func TestDetectGone(t *testing.T) {
+	tests := []struct {
+		content string
+		want    bool
+	}{
+		{`<title>Amazon.de Seite wurde nicht gefunden</title><b>Die eingegebene Webadresse ist keine funktionierende Seite auf unserer Website.</b>`, true},
+		{`<b>The Web address you entered is not a functioning page on our site.</b>`, true},
+		{`<title>Tut uns Leid!</title><b>Ein Fehler ist aufgetreten, als Ihre Anfrage bearbeitet wurde.</b>`, false},
+		{`<span id="productTitle">Jack &amp; Jones T-Shirt</span>`, false},
+	}
+	for _, tt := range tests {
+		if got := detectGone(tt.content); got != tt.want {
+			t.Errorf("detectGone(%q) = %v, want %v", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestIsProductGone(t *testing.T) {
+	if !IsProductGone(fmt.Errorf("failed after 3 retries: %w", goneStatus(404))) {
+		t.Error("Expected wrapped 404 to be gone")
+	}
+	if IsProductGone(ErrDogPage) {
+		t.Error("Expected the generic dog page not to be gone")
+	}
+}
@@ -0,0 +1,217 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// Humanization profiles, from the slowest and most thorough to the fastest
+const (
+	HumanizeOff          = "off"
+	HumanizeConservative = "conservative"
+	HumanizeNormal       = "normal"
+	HumanizeAggressive   = "aggressive"
+)
+
+// HumanizeProfile shapes the mouse paths, dwell times and scroll-read pattern after a page loaded
+type HumanizeProfile struct {
+	MinMoves, MaxMoves     int           // Mouse paths per page
+	MinSteps, MaxSteps     int           // Points along each Bezier path
+	Dwell                  time.Duration // Median pause after a mouse path
+	MinScrolls, MaxScrolls int           // Reading scrolls per page
+	MinScroll, MaxScroll   int           // Pixels per scroll
+	ScrollBack             float64       // Chance a scroll goes back up to re-read
+	Read                   time.Duration // Median pause after a scroll
+	Spread                 float64       // Sigma of the log-normal dwell and read times
+}
+
+// humanizeProfiles are the built-in profiles, conservative is the safest against bot detection
+var humanizeProfiles = map[string]HumanizeProfile{
+	HumanizeConservative: {
+		MinMoves: 3, MaxMoves: 6, MinSteps: 25, MaxSteps: 45, Dwell: 500 * time.Millisecond,
+		MinScrolls: 2, MaxScrolls: 5, MinScroll: 150, MaxScroll: 450, ScrollBack: 0.25,
+		Read: 1800 * time.Millisecond, Spread: 0.5,
+	},
+	HumanizeNormal: {
+		MinMoves: 2, MaxMoves: 4, MinSteps: 15, MaxSteps: 30, Dwell: 250 * time.Millisecond,
+		MinScrolls: 1, MaxScrolls: 3, MinScroll: 200, MaxScroll: 500, ScrollBack: 0.15,
+		Read: 800 * time.Millisecond, Spread: 0.4,
+	},
+	HumanizeAggressive: {
+		MinMoves: 1, MaxMoves: 2, MinSteps: 8, MaxSteps: 12, Dwell: 100 * time.Millisecond,
+		MinScrolls: 0, MaxScrolls: 1, MinScroll: 300, MaxScroll: 600,
+		Read: 300 * time.Millisecond, Spread: 0.3,
+	},
+}
+
+// LookupHumanizeProfile returns the built-in profile of the name, ok is false for off and unknown names
+func LookupHumanizeProfile(name string) (HumanizeProfile, bool) {
+	profile, ok := humanizeProfiles[name]
+	return profile, ok
+}
+
+// ValidHumanizeProfile reports whether name is off or a built-in profile
+func ValidHumanizeProfile(name string) bool {
+	_, ok := humanizeProfiles[name]
+	return ok || name == HumanizeOff
+}
+
+// DefaultHumanizeProfiles uses the same profile for every task
+func DefaultHumanizeProfiles(profile string) map[string]string {
+	return map[string]string{TaskDefault: profile}
+}
+
+// ParseHumanizeProfiles parses "search=aggressive;product=conservative" and overrides the given profiles per task
+func ParseHumanizeProfiles(s string, base map[string]string) (map[string]string, error) {
+	profiles := make(map[string]string, len(base))
+	for task, profile := range base {
+		profiles[task] = profile
+	}
+
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		task, profile, ok := strings.Cut(entry, "=")
+		task, profile = strings.TrimSpace(task), strings.TrimSpace(profile)
+		if !ok || task == "" {
+			return nil, fmt.Errorf("invalid humanize profile: %s", entry)
+		}
+		if !ValidHumanizeProfile(profile) {
+			return nil, fmt.Errorf("unknown humanize profile: %s", profile)
+		}
+		profiles[task] = profile
+	}
+	return profiles, nil
+}
+
+// humanizeProfile returns the profile for the task, falling back to the default task and then to normal
+func (b *Browser) humanizeProfile(task string) (HumanizeProfile, bool) {
+	name, ok := b.opts.HumanizeProfiles[task]
+	if !ok {
+		name, ok = b.opts.HumanizeProfiles[TaskDefault]
+	}
+	if !ok {
+		name = HumanizeNormal
+	}
+	return LookupHumanizeProfile(name)
+}
+
+type point struct{ X, Y float64 }
+
+// humanStep is a mouse path or a scroll followed by a pause
+type humanStep struct {
+	Path   []point
+	Scroll float64
+	Pause  time.Duration
+}
+
+// plan draws the steps of one page visit within a viewport of width x height
+func (p HumanizeProfile) plan(rng *rand.Rand, width, height float64) []humanStep {
+	var steps []humanStep
+
+	pos := point{width * (0.2 + 0.6*rng.Float64()), height * (0.2 + 0.6*rng.Float64())}
+	for range between(rng, p.MinMoves, p.MaxMoves) {
+		// Targets stay away from the edges where the browser chrome would be
+		to := point{width * (0.05 + 0.9*rng.Float64()), height * (0.1 + 0.8*rng.Float64())}
+		path := bezierPath(rng, pos, to, between(rng, p.MinSteps, p.MaxSteps))
+		steps = append(steps, humanStep{Path: path, Pause: lognormal(rng, p.Dwell, p.Spread)})
+		pos = to
+	}
+
+	for range between(rng, p.MinScrolls, p.MaxScrolls) {
+		distance := float64(between(rng, p.MinScroll, p.MaxScroll))
+		if rng.Float64() < p.ScrollBack {
+			distance = -distance / 2
+		}
+		steps = append(steps, humanStep{Scroll: distance, Pause: lognormal(rng, p.Read, p.Spread)})
+	}
+	return steps
+}
+
+// bezierPath returns n points on a cubic Bezier curve from a to b whose control points bend the path
+// to a random side, eased so the cursor accelerates and slows down like a hand
+func bezierPath(rng *rand.Rand, a, b point, n int) []point {
+	n = max(n, 2)
+	dx, dy := b.X-a.X, b.Y-a.Y
+	bend := func() point {
+		// Offset perpendicular to the direct line by up to a third of its length
+		t, offset := rng.Float64(), (rng.Float64()-0.5)*2/3
+		return point{a.X + dx*t - dy*offset, a.Y + dy*t + dx*offset}
+	}
+	c1, c2 := bend(), bend()
+
+	path := make([]point, n)
+	for i := range path {
+		t := float64(i) / float64(n-1)
+		t = t * t * (3 - 2*t)
+		u := 1 - t
+		path[i] = point{
+			u*u*u*a.X + 3*u*u*t*c1.X + 3*u*t*t*c2.X + t*t*t*b.X,
+			u*u*u*a.Y + 3*u*u*t*c1.Y + 3*u*t*t*c2.Y + t*t*t*b.Y,
+		}
+	}
+	return path
+}
+
+// lognormal draws a duration around median, clamped to a quarter and four times of it
+func lognormal(rng *rand.Rand, median time.Duration, sigma float64) time.Duration {
+	d := time.Duration(float64(median) * math.Exp(sigma*rng.NormFloat64()))
+	return min(max(d, median/4), median*4)
+}
+
+func between(rng *rand.Rand, lo, hi int) int {
+	if hi <= lo {
+		return lo
+	}
+	return lo + rng.Intn(hi-lo+1)
+}
+
+// HumanizeTask moves the mouse along random curves and scrolls through the page like a reader, as
+// configured by the humanization profile of the task
+func (b *Browser) HumanizeTask(ctx context.Context, page playwright.Page, task string) error {
+	profile, ok := b.humanizeProfile(task)
+	if !ok {
+		return nil
+	}
+
+	width, height := float64(b.opts.ViewportWidth), float64(b.opts.ViewportHeight)
+	if size := page.ViewportSize(); size != nil {
+		width, height = float64(size.Width), float64(size.Height)
+	}
+	if width <= 0 || height <= 0 {
+		width, height = 1920, 1080
+	}
+
+	b.humanMu.Lock()
+	steps := profile.plan(b.humanRand, width, height)
+	b.humanMu.Unlock()
+
+	for _, step := range steps {
+		// Humanization is best effort, a page that stopped responding fails in the extraction after it
+		for _, p := range step.Path {
+			if err := page.Mouse().Move(p.X, p.Y); err != nil {
+				b.logger.DebugContext(ctx, "humanization stopped", "task", task, "error", err)
+				return nil
+			}
+		}
+		if step.Scroll != 0 {
+			if err := page.Mouse().Wheel(0, step.Scroll); err != nil {
+				b.logger.DebugContext(ctx, "humanization stopped", "task", task, "error", err)
+				return nil
+			}
+		}
+		if err := Sleep(ctx, step.Pause); err != nil {
+			return err
+		}
+	}
+	return nil
+}
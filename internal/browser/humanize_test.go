@@ -0,0 +1,121 @@
+package browser
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestParseHumanizeProfiles(t *testing.T) {
+	profiles, err := ParseHumanizeProfiles("search=aggressive; product = conservative", DefaultHumanizeProfiles(HumanizeNormal))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{TaskDefault: HumanizeNormal, TaskSearch: HumanizeAggressive, TaskProduct: HumanizeConservative}
+	for task, profile := range want {
+		if profiles[task] != profile {
+			t.Errorf("%s profile = %q, want %q", task, profiles[task], profile)
+		}
+	}
+
+	for _, invalid := range []string{"search", "=normal", "search=sloppy"} {
+		if _, err := ParseHumanizeProfiles(invalid, nil); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
+
+func TestHumanizeProfile(t *testing.T) {
+	b := &Browser{opts: &Options{HumanizeProfiles: map[string]string{
+		TaskDefault: HumanizeAggressive,
+		TaskReviews: HumanizeOff,
+	}}}
+
+	if profile, ok := b.humanizeProfile(TaskSearch); !ok || profile.MaxMoves != humanizeProfiles[HumanizeAggressive].MaxMoves {
+		t.Errorf("search should fall back to the default task, got %+v", profile)
+	}
+	if _, ok := b.humanizeProfile(TaskReviews); ok {
+		t.Error("no humanization expected for a task that is off")
+	}
+
+	b.opts.HumanizeProfiles = nil
+	if profile, ok := b.humanizeProfile(TaskProduct); !ok || profile.Dwell != humanizeProfiles[HumanizeNormal].Dwell {
+		t.Errorf("missing profiles should use normal, got %+v", profile)
+	}
+}
+
+func TestHumanizePlan(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for name, profile := range humanizeProfiles {
+		for range 50 {
+			moves, scrolls := 0, 0
+			for _, step := range profile.plan(rng, 1280, 800) {
+				if step.Pause < profile.Dwell/4 && step.Pause < profile.Read/4 {
+					t.Fatalf("%s: pause %s below the clamp", name, step.Pause)
+				}
+				if step.Scroll != 0 {
+					scrolls++
+					if math.Abs(step.Scroll) > float64(profile.MaxScroll) {
+						t.Fatalf("%s: scroll %v exceeds %d", name, step.Scroll, profile.MaxScroll)
+					}
+					continue
+				}
+				moves++
+				if n := len(step.Path); n < profile.MinSteps || n > profile.MaxSteps {
+					t.Fatalf("%s: path of %d points, want %d-%d", name, n, profile.MinSteps, profile.MaxSteps)
+				}
+			}
+			if moves < profile.MinMoves || moves > profile.MaxMoves {
+				t.Fatalf("%s: %d mouse paths, want %d-%d", name, moves, profile.MinMoves, profile.MaxMoves)
+			}
+			if scrolls < profile.MinScrolls || scrolls > profile.MaxScrolls {
+				t.Fatalf("%s: %d scrolls, want %d-%d", name, scrolls, profile.MinScrolls, profile.MaxScrolls)
+			}
+		}
+	}
+}
+
+func TestBezierPath(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	a, b := point{100, 100}, point{900, 500}
+
+	path := bezierPath(rng, a, b, 20)
+	if len(path) != 20 {
+		t.Fatalf("len(path) = %d, want 20", len(path))
+	}
+	if path[0] != a || path[len(path)-1] != b {
+		t.Errorf("path runs from %v to %v, want %v to %v", path[0], path[len(path)-1], a, b)
+	}
+
+	// Control points bend the path off the straight line
+	straight := true
+	for _, p := range path[1 : len(path)-1] {
+		cross := (b.X-a.X)*(p.Y-a.Y) - (b.Y-a.Y)*(p.X-a.X)
+		if math.Abs(cross) > 1e-6 {
+			straight = false
+		}
+	}
+	if straight {
+		t.Error("path should not be a straight line")
+	}
+}
+
+func TestLognormal(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	median := 400 * time.Millisecond
+
+	var below int
+	for range 1000 {
+		d := lognormal(rng, median, 0.5)
+		if d < median/4 || d > median*4 {
+			t.Fatalf("lognormal = %s, want within %s-%s", d, median/4, median*4)
+		}
+		if d < median {
+			below++
+		}
+	}
+	if below < 400 || below > 600 {
+		t.Errorf("%d of 1000 draws below the median, want about half", below)
+	}
+}
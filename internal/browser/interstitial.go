@@ -0,0 +1,109 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+
+	"github.com/maltedev/amazon-size-scraper/internal/scrapeerr"
+)
+
+// Interstitials Amazon shows instead of the requested page. Retrying does not get past them, so
+// navigations give up at once and the pages are skipped with their own failure code.
+var (
+	ErrAgeVerification = errors.New("age verification required")
+	ErrSignInRequired  = fmt.Errorf("sign-in required: %w", scrapeerr.ErrBlocked)
+)
+
+// Interstitial page types
+const (
+	InterstitialAgeGate = "age_gate"
+	InterstitialSignIn  = "sign_in"
+)
+
+// IsInterstitial reports whether err is an age gate or sign-in interstitial
+func IsInterstitial(err error) bool {
+	return errors.Is(err, ErrAgeVerification) || errors.Is(err, ErrSignInRequired)
+}
+
+// ageGateMarkers identify the "black curtain" Amazon puts in front of adult products and categories
+var ageGateMarkers = []string{
+	"black-curtain-yes-button",
+	"black-curtain-warning",
+	"/gp/product/black-curtain-redirect",
+}
+
+// signInMarkers identify the sign-in form, the navigation bar's sign-in links do not match
+var signInMarkers = []string{
+	`name="signIn"`,
+	`id="ap_email"`,
+	`id="ap_password"`,
+}
+
+// detectInterstitial returns the interstitial type of a page, or "" for regular pages
+func detectInterstitial(pageURL, content string) string {
+	if strings.Contains(pageURL, "black-curtain") || containsAny(content, ageGateMarkers) {
+		return InterstitialAgeGate
+	}
+	if strings.Contains(pageURL, "/ap/signin") || containsAny(content, signInMarkers) {
+		return InterstitialSignIn
+	}
+	return ""
+}
+
+func containsAny(s string, markers []string) bool {
+	for _, m := range markers {
+		if strings.Contains(s, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkInterstitial passes the age gate if Options.BypassAgeGate is set and fails on other interstitials
+func (b *Browser) checkInterstitial(ctx context.Context, page playwright.Page, content string) error {
+	switch detectInterstitial(page.URL(), content) {
+	case InterstitialAgeGate:
+		if !b.opts.BypassAgeGate {
+			return fmt.Errorf("%w: %s", ErrAgeVerification, page.URL())
+		}
+		return b.bypassAgeGate(ctx, page)
+	case InterstitialSignIn:
+		return fmt.Errorf("%w: %s", ErrSignInRequired, page.URL())
+	}
+	return nil
+}
+
+// bypassAgeGate confirms the age verification, Amazon remembers it for the session
+func (b *Browser) bypassAgeGate(ctx context.Context, page playwright.Page) error {
+	b.logger.Info("age verification detected, confirming", "url", page.URL())
+
+	for _, selector := range []string{`#black-curtain-yes-button a`, `#black-curtain-yes-button`, `a[href*="black-curtain-redirect"]`} {
+		button := page.Locator(selector).First()
+		if count, err := button.Count(); err != nil || count == 0 {
+			continue
+		}
+		if err := button.Click(); err != nil {
+			b.logger.Warn("failed to click age verification", "selector", selector, "error", err)
+			continue
+		}
+		page.WaitForLoadState(playwright.PageWaitForLoadStateOptions{State: playwright.LoadStateDomcontentloaded})
+		if err := Sleep(ctx, time.Second); err != nil {
+			return err
+		}
+
+		content, err := page.Content()
+		if err != nil {
+			return fmt.Errorf("failed to get page content: %w", err)
+		}
+		if detectInterstitial(page.URL(), content) != InterstitialAgeGate {
+			b.logger.Info("passed age verification", "url", page.URL())
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: could not confirm age verification on %s", ErrAgeVerification, page.URL())
+}
@@ -0,0 +1,35 @@
+package browser
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDetectInterstitial(t *testing.T) {
+	tests := []struct {
+		url     string
+		content string
+		want    string
+	}{
+		{"https://www.amazon.de/dp/B08N5WRWNW", `<div id="black-curtain-warning"><span id="black-curtain-yes-button"><a href="/gp/product/black-curtain-redirect.html">Ja</a></span></div>`, InterstitialAgeGate},
+		{"https://www.amazon.de/gp/product/black-curtain-redirect.html?redirectUrl=%2Fs", "", InterstitialAgeGate},
+		{"https://www.amazon.de/ap/signin?openid.return_to=x", "", InterstitialSignIn},
+		{"https://www.amazon.de/s?k=shirt", `<form name="signIn" method="post"><input id="ap_email"></form>`, InterstitialSignIn},
+		{"https://www.amazon.de/dp/B08N5WRWNW", `<a href="/ap/signin?openid.return_to=x" id="nav-link-accountList">Anmelden</a>`, ""},
+	}
+
+	for _, tt := range tests {
+		if got := detectInterstitial(tt.url, tt.content); got != tt.want {
+			t.Errorf("detectInterstitial(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestIsInterstitial(t *testing.T) {
+	if !IsInterstitial(fmt.Errorf("%w: x", ErrAgeVerification)) || !IsInterstitial(ErrSignInRequired) {
+		t.Error("Expected age gate and sign-in errors to be interstitials")
+	}
+	if IsInterstitial(ErrCaptcha) {
+		t.Error("Expected captcha not to be an interstitial")
+	}
+}
@@ -0,0 +1,193 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// NavigationStrategy controls how a page is reached
+type NavigationStrategy string
+
+const (
+	// NavigateDirect opens the target URL directly
+	NavigateDirect NavigationStrategy = "direct"
+	// NavigateWarmHomepage visits the marketplace homepage first so bot checks are passed before the target
+	NavigateWarmHomepage NavigationStrategy = "warm-homepage"
+	// NavigateRefererSpoof opens the target with a search results page as referer
+	NavigateRefererSpoof NavigationStrategy = "referer-spoof"
+)
+
+// navigationEscalation is the order strategies are tried in after a failed attempt
+var navigationEscalation = []NavigationStrategy{NavigateDirect, NavigateWarmHomepage, NavigateRefererSpoof}
+
+// ParseNavigationStrategy validates a strategy name, empty defaults to direct
+func ParseNavigationStrategy(s string) (NavigationStrategy, error) {
+	if s == "" {
+		return NavigateDirect, nil
+	}
+	for _, strategy := range navigationEscalation {
+		if NavigationStrategy(s) == strategy {
+			return strategy, nil
+		}
+	}
+	return "", fmt.Errorf("unknown navigation strategy: %s", s)
+}
+
+// ParseNavigationOverrides parses "amazon.fr=warm-homepage,proxy:8080=referer-spoof" into a lookup map
+func ParseNavigationOverrides(s string) (map[string]NavigationStrategy, error) {
+	overrides := make(map[string]NavigationStrategy)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid navigation override: %s", pair)
+		}
+
+		strategy, err := ParseNavigationStrategy(strings.TrimSpace(value))
+		if err != nil {
+			return nil, err
+		}
+		overrides[strings.ToLower(strings.TrimSpace(key))] = strategy
+	}
+	return overrides, nil
+}
+
+// NavigationStrategyFor returns the strategy used for the first attempt on a URL
+func (b *Browser) NavigationStrategyFor(target string) NavigationStrategy {
+	host := hostOf(target)
+
+	b.navMu.Lock()
+	learned, ok := b.learned[host]
+	b.navMu.Unlock()
+	if ok {
+		return learned
+	}
+
+	if proxy := b.launchProxy(); proxy != nil {
+		if strategy, ok := b.opts.NavigationOverrides[strings.ToLower(proxy.Server)]; ok {
+			return strategy
+		}
+	}
+	if strategy, ok := lookupHost(b.opts.NavigationOverrides, host); ok {
+		return strategy
+	}
+	if b.opts.Navigation != "" {
+		return b.opts.Navigation
+	}
+	return NavigateDirect
+}
+
+// navigate performs a single navigation attempt with the given strategy
+func (b *Browser) navigate(ctx context.Context, page playwright.Page, target string, strategy NavigationStrategy) error {
+	gotoOpts := playwright.PageGotoOptions{
+		WaitUntil: playwright.WaitUntilStateDomcontentloaded,
+		Timeout:   playwright.Float(30000),
+	}
+
+	switch strategy {
+	case NavigateWarmHomepage:
+		if home := HomepageOf(target); home != "" && home != strings.TrimRight(target, "/") {
+			if _, err := page.Goto(home, gotoOpts); err != nil {
+				return fmt.Errorf("failed to warm homepage: %w", err)
+			}
+			if _, err := b.checkBotProtection(ctx, page); err != nil {
+				return fmt.Errorf("failed to pass homepage bot check: %w", err)
+			}
+			if err := Sleep(ctx, time.Second); err != nil {
+				return err
+			}
+		}
+	case NavigateRefererSpoof:
+		if referer := searchRefererOf(target); referer != "" {
+			gotoOpts.Referer = playwright.String(referer)
+		}
+	}
+
+	resp, err := page.Goto(target, gotoOpts)
+	if err != nil {
+		return err
+	}
+	if resp != nil {
+		return goneStatus(resp.Status())
+	}
+	return nil
+}
+
+// escalate returns the next strategy after a failed attempt, staying on the last one
+func escalate(strategy NavigationStrategy) NavigationStrategy {
+	for i, s := range navigationEscalation {
+		if s == strategy && i+1 < len(navigationEscalation) {
+			return navigationEscalation[i+1]
+		}
+	}
+	return strategy
+}
+
+// learn remembers a strategy that worked after escalation so later pages on the host start with it
+func (b *Browser) learn(target string, strategy NavigationStrategy) {
+	b.navMu.Lock()
+	defer b.navMu.Unlock()
+
+	if b.learned == nil {
+		b.learned = make(map[string]NavigationStrategy)
+	}
+	b.learned[hostOf(target)] = strategy
+}
+
+// lookupHost matches overrides against the host and its parent domains, e.g. amazon.de for www.amazon.de
+func lookupHost(overrides map[string]NavigationStrategy, host string) (NavigationStrategy, bool) {
+	for host != "" {
+		if strategy, ok := overrides[host]; ok {
+			return strategy, true
+		}
+		_, parent, found := strings.Cut(host, ".")
+		if !found || !strings.Contains(parent, ".") {
+			break
+		}
+		host = parent
+	}
+	return "", false
+}
+
+func hostOf(target string) string {
+	u, err := url.Parse(target)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Host)
+}
+
+// HomepageOf returns the scheme and host of a URL, e.g. "https://www.amazon.de", empty for a URL without host
+func HomepageOf(target string) string {
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// searchRefererOf builds a search results URL for the ASIN in a /dp/ link, or the homepage otherwise
+func searchRefererOf(target string) string {
+	home := HomepageOf(target)
+	if home == "" {
+		return ""
+	}
+
+	u, _ := url.Parse(target)
+	if _, rest, ok := strings.Cut(u.Path, "/dp/"); ok {
+		asin, _, _ := strings.Cut(rest, "/")
+		if asin != "" {
+			return home + "/s?k=" + url.QueryEscape(asin)
+		}
+	}
+	return home + "/"
+}
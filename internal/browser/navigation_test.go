@@ -0,0 +1,87 @@
+package browser
+
+import "testing"
+
+func TestParseNavigationOverrides(t *testing.T) {
+	overrides, err := ParseNavigationOverrides("amazon.fr=warm-homepage, proxy.local:3128=referer-spoof,")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if overrides["amazon.fr"] != NavigateWarmHomepage || overrides["proxy.local:3128"] != NavigateRefererSpoof {
+		t.Errorf("unexpected overrides: %v", overrides)
+	}
+
+	for _, invalid := range []string{"amazon.fr", "=direct", "amazon.fr=teleport"} {
+		if _, err := ParseNavigationOverrides(invalid); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
+
+func TestEscalate(t *testing.T) {
+	if got := escalate(NavigateDirect); got != NavigateWarmHomepage {
+		t.Errorf("escalate(direct) = %s", got)
+	}
+	if got := escalate(NavigateWarmHomepage); got != NavigateRefererSpoof {
+		t.Errorf("escalate(warm-homepage) = %s", got)
+	}
+	if got := escalate(NavigateRefererSpoof); got != NavigateRefererSpoof {
+		t.Errorf("escalate(referer-spoof) = %s", got)
+	}
+}
+
+func TestNavigationStrategyFor(t *testing.T) {
+	b := &Browser{opts: &Options{
+		Navigation: NavigateDirect,
+		NavigationOverrides: map[string]NavigationStrategy{
+			"amazon.fr": NavigateWarmHomepage,
+		},
+	}}
+
+	if got := b.NavigationStrategyFor("https://www.amazon.de/dp/B0TEST0001"); got != NavigateDirect {
+		t.Errorf("amazon.de strategy = %s, want direct", got)
+	}
+	if got := b.NavigationStrategyFor("https://www.amazon.fr/dp/B0TEST0001"); got != NavigateWarmHomepage {
+		t.Errorf("amazon.fr strategy = %s, want warm-homepage", got)
+	}
+
+	// Proxy overrides win over marketplace overrides
+	b.opts.ProxyServer = "http://proxy.local:3128"
+	b.opts.NavigationOverrides["http://proxy.local:3128"] = NavigateRefererSpoof
+	if got := b.NavigationStrategyFor("https://www.amazon.fr/dp/B0TEST0001"); got != NavigateRefererSpoof {
+		t.Errorf("proxy strategy = %s, want referer-spoof", got)
+	}
+
+	// Escalated strategies are remembered per host
+	b.learn("https://www.amazon.de/dp/B0TEST0002", NavigateWarmHomepage)
+	if got := b.NavigationStrategyFor("https://www.amazon.de/dp/B0TEST0003"); got != NavigateWarmHomepage {
+		t.Errorf("learned strategy = %s, want warm-homepage", got)
+	}
+}
+
+func TestSearchRefererOf(t *testing.T) {
+	tests := map[string]string{
+		"https://www.amazon.de/dp/B0TEST0001":           "https://www.amazon.de/s?k=B0TEST0001",
+		"https://www.amazon.de/Some-Title/dp/B0TEST01/": "https://www.amazon.de/s?k=B0TEST01",
+		"https://www.amazon.de/s?k=herren":              "https://www.amazon.de/",
+	}
+	for target, want := range tests {
+		if got := searchRefererOf(target); got != want {
+			t.Errorf("searchRefererOf(%q) = %q, want %q", target, got, want)
+		}
+	}
+}
+
+func TestHomepageOf(t *testing.T) {
+	tests := map[string]string{
+		"https://www.amazon.de/s?k=herren&page=2": "https://www.amazon.de",
+		"https://www.amazon.co.uk/dp/B0TEST0001":  "https://www.amazon.co.uk",
+		"/dp/B0TEST0001":                          "",
+		"::not a url":                             "",
+	}
+	for target, want := range tests {
+		if got := HomepageOf(target); got != want {
+			t.Errorf("HomepageOf(%q) = %q, want %q", target, got, want)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package browser
+
+import (
+	"fmt"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// RenderPDF prints an HTML document to an A4 PDF, only supported by headless Chromium
+func (b *Browser) RenderPDF(html string) ([]byte, error) {
+	page, err := b.NewPage()
+	if err != nil {
+		return nil, err
+	}
+	defer page.Close()
+
+	if err := page.SetContent(html, playwright.PageSetContentOptions{
+		WaitUntil: playwright.WaitUntilStateLoad,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to load document: %w", err)
+	}
+
+	pdf, err := page.PDF(playwright.PagePdfOptions{
+		Format:          playwright.String("A4"),
+		PrintBackground: playwright.Bool(true),
+		Margin: &playwright.Margin{
+			Top:    playwright.String("15mm"),
+			Bottom: playwright.String("15mm"),
+			Left:   playwright.String("12mm"),
+			Right:  playwright.String("12mm"),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to print PDF: %w", err)
+	}
+	return pdf, nil
+}
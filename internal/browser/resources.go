@@ -0,0 +1,208 @@
+package browser
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// Task types used to pick a resource policy for a page
+const (
+	TaskDefault   = "default"
+	TaskSearch    = "search"
+	TaskProduct   = "product"
+	TaskSizeChart = "size_chart"
+	TaskReviews   = "reviews"
+
+	// TaskSizeChartOCR is used for size charts when the OCR fallback needs rendered images
+	TaskSizeChartOCR = "size_chart_ocr"
+)
+
+// Resource categories a policy can block
+const (
+	ResourceImage     = "image"
+	ResourceMedia     = "media"
+	ResourceFont      = "font"
+	ResourceAnalytics = "analytics"
+)
+
+// DefaultAnalyticsDomains are tracker and ad hosts never needed for DOM extraction
+var DefaultAnalyticsDomains = []string{
+	"google-analytics.com",
+	"googletagmanager.com",
+	"doubleclick.net",
+	"amazon-adsystem.com",
+	"fls-eu.amazon.de",
+	"unagi.amazon.de",
+	"unagi-eu.amazon.com",
+	"aax-eu.amazon.de",
+	"aax-eu.amazon-adsystem.com",
+}
+
+// ResourcePolicy lists the resource categories blocked for a task
+type ResourcePolicy struct {
+	Images    bool
+	Media     bool
+	Fonts     bool
+	Analytics bool
+}
+
+// DefaultResourcePolicies blocks everything extraction does not need, images stay enabled for unknown tasks
+func DefaultResourcePolicies() map[string]ResourcePolicy {
+	all := ResourcePolicy{Images: true, Media: true, Fonts: true, Analytics: true}
+	withImages := ResourcePolicy{Media: true, Fonts: true, Analytics: true}
+	return map[string]ResourcePolicy{
+		TaskDefault:      withImages,
+		TaskSearch:       all,
+		TaskProduct:      all,
+		TaskSizeChart:    all,
+		TaskSizeChartOCR: withImages,
+		TaskReviews:      all,
+	}
+}
+
+// ParseResourcePolicy parses a comma separated category list such as "image,font"
+func ParseResourcePolicy(s string) (ResourcePolicy, error) {
+	var policy ResourcePolicy
+	for _, category := range strings.Split(s, ",") {
+		switch strings.TrimSpace(category) {
+		case "":
+		case ResourceImage:
+			policy.Images = true
+		case ResourceMedia:
+			policy.Media = true
+		case ResourceFont:
+			policy.Fonts = true
+		case ResourceAnalytics:
+			policy.Analytics = true
+		default:
+			return ResourcePolicy{}, fmt.Errorf("unknown resource category: %s", category)
+		}
+	}
+	return policy, nil
+}
+
+// ParseResourcePolicies parses "search=image,font;size_chart=font" and overrides the given policies per task
+func ParseResourcePolicies(s string, base map[string]ResourcePolicy) (map[string]ResourcePolicy, error) {
+	policies := make(map[string]ResourcePolicy, len(base))
+	for task, policy := range base {
+		policies[task] = policy
+	}
+
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		task, categories, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(task) == "" {
+			return nil, fmt.Errorf("invalid resource policy: %s", entry)
+		}
+
+		policy, err := ParseResourcePolicy(categories)
+		if err != nil {
+			return nil, err
+		}
+		policies[strings.TrimSpace(task)] = policy
+	}
+	return policies, nil
+}
+
+// NewTaskPage creates a page that blocks the resources listed in the policy for the task
+func (b *Browser) NewTaskPage(task string) (playwright.Page, error) {
+	return b.NewTaskPageIn(nil, task)
+}
+
+// NewTaskPageIn is NewTaskPage for a context created with NewContext, nil uses the shared context
+func (b *Browser) NewTaskPageIn(context playwright.BrowserContext, task string) (playwright.Page, error) {
+	page, err := b.newPage(context)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, ok := b.resourcePolicy(task)
+	if !ok {
+		return page, nil
+	}
+
+	if err := page.Route("**/*", func(route playwright.Route) {
+		if b.shouldBlock(policy, route.Request()) {
+			b.blockedRequests.Add(1)
+			route.Abort("blockedbyclient")
+			return
+		}
+		route.Continue()
+	}); err != nil {
+		page.Close()
+		return nil, fmt.Errorf("failed to install resource blocking: %w", err)
+	}
+
+	return page, nil
+}
+
+// BlockedRequests returns how many requests resource policies have aborted
+func (b *Browser) BlockedRequests() int64 {
+	return b.blockedRequests.Load()
+}
+
+// resourcePolicy returns the policy for the task, falling back to the default task
+func (b *Browser) resourcePolicy(task string) (ResourcePolicy, bool) {
+	if len(b.opts.ResourcePolicies) == 0 {
+		return ResourcePolicy{}, false
+	}
+
+	policy, ok := b.opts.ResourcePolicies[task]
+	if !ok {
+		policy, ok = b.opts.ResourcePolicies[TaskDefault]
+	}
+	if b.opts.DownloadImages {
+		policy.Images = false
+	}
+	return policy, ok && policy != ResourcePolicy{}
+}
+
+// shouldBlock decides whether a request is aborted under the policy
+func (b *Browser) shouldBlock(policy ResourcePolicy, req playwright.Request) bool {
+	switch req.ResourceType() {
+	case "image":
+		if policy.Images {
+			return true
+		}
+	case "media":
+		if policy.Media {
+			return true
+		}
+	case "font":
+		if policy.Fonts {
+			return true
+		}
+	}
+
+	if policy.Analytics {
+		domains := b.opts.AnalyticsDomains
+		if domains == nil {
+			domains = DefaultAnalyticsDomains
+		}
+		return isAnalyticsHost(req.URL(), domains)
+	}
+	return false
+}
+
+// isAnalyticsHost reports whether the URL host is one of the domains or a subdomain of them
+func isAnalyticsHost(rawURL string, domains []string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	for _, domain := range domains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package browser
+
+import "testing"
+
+func TestParseResourcePolicies(t *testing.T) {
+	policies, err := ParseResourcePolicies("search=font, analytics; reviews=", DefaultResourcePolicies())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := (ResourcePolicy{Fonts: true, Analytics: true}); policies[TaskSearch] != want {
+		t.Errorf("search policy = %+v, want %+v", policies[TaskSearch], want)
+	}
+	if policies[TaskReviews] != (ResourcePolicy{}) {
+		t.Errorf("reviews policy = %+v, want nothing blocked", policies[TaskReviews])
+	}
+	if !policies[TaskProduct].Images {
+		t.Error("product policy should keep blocking images")
+	}
+
+	for _, invalid := range []string{"search", "=image", "search=video"} {
+		if _, err := ParseResourcePolicies(invalid, nil); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
+
+func TestResourcePolicy(t *testing.T) {
+	b := &Browser{opts: &Options{ResourcePolicies: DefaultResourcePolicies()}}
+
+	if policy, ok := b.resourcePolicy(TaskSizeChart); !ok || !policy.Images {
+		t.Errorf("size chart policy = %+v, want images blocked", policy)
+	}
+	if policy, ok := b.resourcePolicy("unknown"); !ok || policy.Images || !policy.Fonts {
+		t.Errorf("unknown task should fall back to the default policy, got %+v", policy)
+	}
+
+	b.opts.DownloadImages = true
+	if policy, _ := b.resourcePolicy(TaskProduct); policy.Images {
+		t.Error("images must not be blocked when image download is enabled")
+	}
+
+	b.opts.ResourcePolicies = nil
+	if _, ok := b.resourcePolicy(TaskProduct); ok {
+		t.Error("no policy expected when blocking is disabled")
+	}
+}
+
+func TestIsAnalyticsHost(t *testing.T) {
+	tests := map[string]bool{
+		"https://www.google-analytics.com/collect":  true,
+		"https://fls-eu.amazon.de/1/batch":          true,
+		"https://www.amazon.de/dp/B0TEST0001":       false,
+		"https://m.media-amazon.com/images/I/x.jpg": false,
+		"https://notdoubleclick.net/pixel":          false,
+	}
+	for rawURL, want := range tests {
+		if got := isAnalyticsHost(rawURL, DefaultAnalyticsDomains); got != want {
+			t.Errorf("isAnalyticsHost(%q) = %v, want %v", rawURL, got, want)
+		}
+	}
+}
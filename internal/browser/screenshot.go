@@ -0,0 +1,89 @@
+package browser
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// Screenshot formats
+const (
+	ScreenshotPNG  = "png"
+	ScreenshotWebP = "webp"
+)
+
+// ErrUnsupportedScreenshotFormat is returned for screenshot formats other than PNG and WebP
+var ErrUnsupportedScreenshotFormat = errors.New("unsupported screenshot format")
+
+// ScreenshotOptions selects the region and encoding of a screenshot
+type ScreenshotOptions struct {
+	FullPage bool   // Whole scrollable page instead of the viewport
+	Format   string // ScreenshotPNG or ScreenshotWebP, empty is PNG
+}
+
+// Screenshot captures the page. WebP is encoded by Chromium through the DevTools protocol, Playwright
+// itself only supports PNG and JPEG.
+func (b *Browser) Screenshot(page playwright.Page, opts ScreenshotOptions) ([]byte, error) {
+	switch opts.Format {
+	case "", ScreenshotPNG:
+		image, err := page.Screenshot(playwright.PageScreenshotOptions{
+			FullPage: playwright.Bool(opts.FullPage),
+			Type:     playwright.ScreenshotTypePng,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to capture screenshot: %w", err)
+		}
+		return image, nil
+	case ScreenshotWebP:
+		return b.screenshotWebP(page, opts.FullPage)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedScreenshotFormat, opts.Format)
+	}
+}
+
+func (b *Browser) screenshotWebP(page playwright.Page, fullPage bool) ([]byte, error) {
+	session, err := page.Context().NewCDPSession(page)
+	if err != nil {
+		return nil, fmt.Errorf("%w: webp needs chromium: %v", ErrUnsupportedScreenshotFormat, err)
+	}
+	defer session.Detach()
+
+	params := map[string]interface{}{"format": ScreenshotWebP}
+	if fullPage {
+		metrics, err := session.Send("Page.getLayoutMetrics", nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get page size: %w", err)
+		}
+		width, height := contentSize(metrics)
+		if width > 0 && height > 0 {
+			params["captureBeyondViewport"] = true
+			params["clip"] = map[string]interface{}{"x": 0, "y": 0, "width": width, "height": height, "scale": 1}
+		}
+	}
+
+	result, err := session.Send("Page.captureScreenshot", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture screenshot: %w", err)
+	}
+	fields, _ := result.(map[string]interface{})
+	data, _ := fields["data"].(string)
+	image, err := base64.StdEncoding.DecodeString(data)
+	if err != nil || len(image) == 0 {
+		return nil, fmt.Errorf("failed to decode screenshot: %v", err)
+	}
+	return image, nil
+}
+
+// contentSize reads the CSS size of the whole page from a Page.getLayoutMetrics result
+func contentSize(metrics interface{}) (float64, float64) {
+	m, _ := metrics.(map[string]interface{})
+	size, ok := m["cssContentSize"].(map[string]interface{})
+	if !ok {
+		size, _ = m["contentSize"].(map[string]interface{})
+	}
+	width, _ := size["width"].(float64)
+	height, _ := size["height"].(float64)
+	return width, height
+}
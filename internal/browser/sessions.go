@@ -0,0 +1,256 @@
+package browser
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// SessionScore aggregates the anti-detection signals of all sessions sharing a fingerprint and proxy
+type SessionScore struct {
+	Fingerprint string `json:"fingerprint"`
+	Proxy       string `json:"proxy"`
+	Sessions    int64  `json:"sessions"`
+	Navigations int64  `json:"navigations"`
+
+	CaptchaSessions         int64   `json:"captcha_sessions"`           // Sessions that hit at least one robot check
+	AvgNavigationsToCaptcha float64 `json:"avg_navigations_to_captcha"` // Navigations before the first robot check of a session
+	DogPages                int64   `json:"dog_pages"`
+	DogPageRate             float64 `json:"dog_page_rate"`    // Dog pages per navigation
+	AvgLoadTimeMs           float64 `json:"avg_load_time_ms"` // Successful navigations only
+	BotChecks               int64   `json:"bot_checks"`       // Click-through challenges
+	BotChecksBypassed       int64   `json:"bot_checks_bypassed"`
+	BotCheckBypassRate      float64 `json:"bot_check_bypass_rate"`
+}
+
+// sessionTotals are the raw counters behind a SessionScore
+type sessionTotals struct {
+	sessions             int64
+	navigations          int64
+	captchaSessions      int64
+	navigationsToCaptcha int64
+	dogPages             int64
+	loads                int64
+	loadTime             time.Duration
+	botChecks            int64
+	botChecksBypassed    int64
+}
+
+// session is a browser context, its navigations are counted until the first robot check
+type session struct {
+	key         sessionKey
+	navigations int64
+	captcha     bool
+}
+
+type sessionKey struct {
+	fingerprint string
+	proxy       string
+}
+
+// SessionMetrics records per session signals and aggregates them per fingerprint and proxy
+type SessionMetrics struct {
+	mu       sync.Mutex
+	sessions map[playwright.BrowserContext]*session
+	scores   map[sessionKey]*sessionTotals
+}
+
+// NewSessionMetrics creates an empty recorder
+func NewSessionMetrics() *SessionMetrics {
+	return &SessionMetrics{
+		sessions: make(map[playwright.BrowserContext]*session),
+		scores:   make(map[sessionKey]*sessionTotals),
+	}
+}
+
+// start registers a context as a new session of the fingerprint and proxy
+func (m *SessionMetrics) start(context playwright.BrowserContext, fingerprint string, proxy *Proxy) {
+	key := sessionKey{fingerprint: fingerprint, proxy: "direct"}
+	if proxy != nil {
+		key.proxy = proxy.String()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[context] = &session{key: key}
+	m.score(key).sessions++
+}
+
+// end forgets a closed context, its signals stay in the scorecard
+func (m *SessionMetrics) end(context playwright.BrowserContext) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, context)
+}
+
+// navigation records a navigation attempt, load is the page load time of a successful one
+func (m *SessionMetrics) navigation(context playwright.BrowserContext, load time.Duration, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, found := m.sessions[context]
+	if !found {
+		return
+	}
+	s.navigations++
+	score := m.score(s.key)
+	score.navigations++
+	if ok {
+		score.loads++
+		score.loadTime += load
+	}
+}
+
+// captcha records a robot check, only the first one of a session counts towards navigations to captcha
+func (m *SessionMetrics) captcha(context playwright.BrowserContext) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, found := m.sessions[context]
+	if !found || s.captcha {
+		return
+	}
+	s.captcha = true
+	score := m.score(s.key)
+	score.captchaSessions++
+	// The navigation that hit the robot check is already counted
+	score.navigationsToCaptcha += max(s.navigations-1, 0)
+}
+
+// botCheck records a click-through challenge and whether clicking through succeeded
+func (m *SessionMetrics) botCheck(context playwright.BrowserContext, bypassed bool) {
+	m.captcha(context)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, found := m.sessions[context]
+	if !found {
+		return
+	}
+	score := m.score(s.key)
+	score.botChecks++
+	if bypassed {
+		score.botChecksBypassed++
+	}
+}
+
+// dogPage records Amazon's "Tut uns Leid" error page
+func (m *SessionMetrics) dogPage(context playwright.BrowserContext) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if s, found := m.sessions[context]; found {
+		m.score(s.key).dogPages++
+	}
+}
+
+func (m *SessionMetrics) score(key sessionKey) *sessionTotals {
+	totals, ok := m.scores[key]
+	if !ok {
+		totals = &sessionTotals{}
+		m.scores[key] = totals
+	}
+	return totals
+}
+
+// Scorecard returns the aggregated signals sorted by fingerprint and proxy
+func (m *SessionMetrics) Scorecard() []SessionScore {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	scores := make([]SessionScore, 0, len(m.scores))
+	for key, t := range m.scores {
+		score := SessionScore{
+			Fingerprint:       key.fingerprint,
+			Proxy:             key.proxy,
+			Sessions:          t.sessions,
+			Navigations:       t.navigations,
+			CaptchaSessions:   t.captchaSessions,
+			DogPages:          t.dogPages,
+			BotChecks:         t.botChecks,
+			BotChecksBypassed: t.botChecksBypassed,
+		}
+		if t.captchaSessions > 0 {
+			score.AvgNavigationsToCaptcha = float64(t.navigationsToCaptcha) / float64(t.captchaSessions)
+		}
+		if t.navigations > 0 {
+			score.DogPageRate = float64(t.dogPages) / float64(t.navigations)
+		}
+		if t.loads > 0 {
+			score.AvgLoadTimeMs = float64(t.loadTime.Milliseconds()) / float64(t.loads)
+		}
+		if t.botChecks > 0 {
+			score.BotCheckBypassRate = float64(t.botChecksBypassed) / float64(t.botChecks)
+		}
+		scores = append(scores, score)
+	}
+
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Fingerprint != scores[j].Fingerprint {
+			return scores[i].Fingerprint < scores[j].Fingerprint
+		}
+		return scores[i].Proxy < scores[j].Proxy
+	})
+	return scores
+}
+
+// WriteMetrics writes the scorecard in the Prometheus text format
+func (m *SessionMetrics) WriteMetrics(w io.Writer) {
+	scores := m.Scorecard()
+
+	metrics := []struct {
+		name, help, kind string
+		value            func(SessionScore) float64
+	}{
+		{"scraper_browser_sessions_total", "Browser contexts started.", "counter", func(s SessionScore) float64 { return float64(s.Sessions) }},
+		{"scraper_browser_navigations_total", "Navigation attempts.", "counter", func(s SessionScore) float64 { return float64(s.Navigations) }},
+		{"scraper_browser_captcha_sessions_total", "Sessions that hit a robot check.", "counter", func(s SessionScore) float64 { return float64(s.CaptchaSessions) }},
+		{"scraper_browser_navigations_to_captcha", "Average navigations before the first robot check of a session.", "gauge", func(s SessionScore) float64 { return s.AvgNavigationsToCaptcha }},
+		{"scraper_browser_dog_page_rate", "Dog pages per navigation.", "gauge", func(s SessionScore) float64 { return s.DogPageRate }},
+		{"scraper_browser_load_time_ms", "Average page load time of successful navigations.", "gauge", func(s SessionScore) float64 { return s.AvgLoadTimeMs }},
+		{"scraper_browser_bot_check_bypass_rate", "Share of click-through bot checks bypassed.", "gauge", func(s SessionScore) float64 { return s.BotCheckBypassRate }},
+	}
+
+	for _, metric := range metrics {
+		fmt.Fprintf(w, "# HELP %s %s\n", metric.name, metric.help)
+		fmt.Fprintf(w, "# TYPE %s %s\n", metric.name, metric.kind)
+		for _, s := range scores {
+			fmt.Fprintf(w, "%s{fingerprint=%q,proxy=%q} %g\n", metric.name, s.Fingerprint, s.Proxy, metric.value(s))
+		}
+	}
+}
+
+// fingerprint labels the anti-detection setup of the browser, defaulting to browser version and viewport
+func (b *Browser) fingerprint() string {
+	if b.opts.Fingerprint != "" {
+		return b.opts.Fingerprint
+	}
+
+	agent := b.opts.UserAgent
+	for _, token := range strings.Fields(agent) {
+		if strings.HasPrefix(token, "Chrome/") || strings.HasPrefix(token, "Firefox/") {
+			agent = token
+			break
+		}
+	}
+	return fmt.Sprintf("%s %dx%d", agent, b.opts.ViewportWidth, b.opts.ViewportHeight)
+}
+
+// trackSession starts a session for a new context and ends it when the context closes
+func (b *Browser) trackSession(context playwright.BrowserContext, proxy *Proxy) {
+	b.sessions.start(context, b.fingerprint(), proxy)
+	context.OnClose(func(c playwright.BrowserContext) {
+		b.sessions.end(c)
+	})
+}
+
+// SessionMetrics returns the per session anti-detection signals of the browser
+func (b *Browser) SessionMetrics() *SessionMetrics {
+	return b.sessions
+}
@@ -0,0 +1,209 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+
+	"github.com/maltedev/amazon-size-scraper/internal/scrapeerr"
+)
+
+// ErrSizeChartNotFound is returned when no registered size chart layout became visible in time
+var ErrSizeChartNotFound = fmt.Errorf("size chart did not appear: %w", scrapeerr.ErrNoSizeTable)
+
+// Defaults for waiting on the size chart after the Größentabelle click
+const (
+	DefaultSizeChartTimeout      = 10 * time.Second
+	DefaultSizeChartPollInterval = 250 * time.Millisecond
+)
+
+// SizeChartLayout is one way Amazon presents the size chart after the Größentabelle click
+type SizeChartLayout struct {
+	Name  string
+	Table string // CSS selector of the size table, only visible matches count
+	Kind  string // Empty for tables, SizeChartKindWidget for the root of the React widget
+}
+
+// DefaultSizeChartLayouts is the selector registry of known size chart layouts, tried in order
+func DefaultSizeChartLayouts() []SizeChartLayout {
+	return []SizeChartLayout{
+		{Name: "popover", Table: ".a-popover-content table"},
+		{Name: "modal", Table: ".a-modal-content table"},
+		{Name: "side-sheet", Table: ".a-sheet-content table, #a-sheet-content table"},
+		{Name: "apparel-size-chart", Table: apparelSizeChartWidget, Kind: SizeChartKindWidget},
+		{Name: "size-chart-v2", Table: "#sizeChartV2Data table, [data-csa-c-content-id*=\"size-chart\"] table"},
+		{Name: "legacy-popover", Table: "[id*=\"popover\"] table"},
+	}
+}
+
+// ParseSizeChartLayouts parses "name=selector;name=selector" and puts the layouts before the defaults
+func ParseSizeChartLayouts(s string, defaults []SizeChartLayout) ([]SizeChartLayout, error) {
+	var layouts []SizeChartLayout
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, selector, ok := strings.Cut(entry, "=")
+		name, selector = strings.TrimSpace(name), strings.TrimSpace(selector)
+		if !ok || name == "" || selector == "" {
+			return nil, fmt.Errorf("invalid size chart layout: %s", entry)
+		}
+		layouts = append(layouts, SizeChartLayout{Name: name, Table: selector})
+	}
+	return append(layouts, defaults...), nil
+}
+
+// SizeChart is the visible size table found by WaitForSizeChart
+type SizeChart struct {
+	Layout SizeChartLayout
+	Table  playwright.Locator
+}
+
+// WaitForSizeChart waits until the table of a registered layout is visible after the size chart
+// was opened, optionally letting the network settle first. It polls instead of sleeping a fixed time,
+// so fast pages return immediately and slow ones get up to the configured timeout.
+func (b *Browser) WaitForSizeChart(ctx context.Context, page playwright.Page) (*SizeChart, error) {
+	timeout := b.opts.SizeChartTimeout
+	if timeout <= 0 {
+		timeout = DefaultSizeChartTimeout
+	}
+	deadline := time.Now().Add(timeout)
+
+	if b.opts.SizeChartNetworkIdle {
+		// Charts loaded via XHR only render once their request finished, a busy page just uses up the wait
+		page.WaitForLoadState(playwright.PageWaitForLoadStateOptions{
+			State:   playwright.LoadStateNetworkidle,
+			Timeout: playwright.Float(float64(timeout.Milliseconds())),
+		})
+	}
+
+	layouts := b.opts.SizeChartLayouts
+	if len(layouts) == 0 {
+		layouts = DefaultSizeChartLayouts()
+	}
+
+	for {
+		for _, layout := range layouts {
+			table := page.Locator(layout.Table + " >> visible=true")
+			if count, err := table.Count(); err == nil && count > 0 {
+				b.logger.Debug("size chart visible", "layout", layout.Name, "waited", timeout-time.Until(deadline))
+				return &SizeChart{Layout: layout, Table: table.First()}, nil
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%w within %s", ErrSizeChartNotFound, timeout)
+		}
+		if err := Sleep(ctx, DefaultSizeChartPollInterval); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// ExtractTable returns the size table as {headers: [...], rows: [[...]]}, the first row being the header
+func (c *SizeChart) ExtractTable() (interface{}, error) {
+	if c.Layout.Kind == SizeChartKindWidget {
+		return c.extractWidget()
+	}
+	return c.Table.Evaluate(`table => {
+		const data = { headers: [], rows: [] };
+		for (let i = 0; i < table.rows.length; i++) {
+			const cells = Array.from(table.rows[i].cells).map(cell => cell.textContent.trim());
+			if (i === 0) {
+				data.headers = cells;
+			} else {
+				data.rows.push(cells);
+			}
+		}
+		return data;
+	}`, nil)
+}
+
+// Page sections searched for size tables rendered without the Größentabelle modal
+const (
+	SectionDescription = "description"
+	SectionAPlus       = "aplus"
+)
+
+// inlineSizeTableSections maps each section to the selector of its tables, searched in this order
+var inlineSizeTableSections = []map[string]string{
+	{"name": SectionDescription, "selector": "#productDescription table, #productDescription_feature_div table"},
+	{"name": SectionAPlus, "selector": "#aplus table, #aplus_feature_div table, .aplus-v2 table, .aplus-module table"},
+}
+
+// InlineSizeTable is a candidate table from the product description or A+ content
+type InlineSizeTable struct {
+	Section string      // SectionDescription or SectionAPlus
+	Data    interface{} // {headers: [...], rows: [[...]]} like SizeChart.ExtractTable
+}
+
+// FindInlineSizeTables returns every table of the description and A+ content in page order, callers
+// decide which one is a size table. Layout tables wrapping other tables are skipped.
+func FindInlineSizeTables(page playwright.Page) ([]InlineSizeTable, error) {
+	result, err := page.Evaluate(`(sections) => {
+		const seen = new Set();
+		const tables = [];
+		for (const section of sections) {
+			for (const table of document.querySelectorAll(section.selector)) {
+				if (seen.has(table) || table.querySelector('table')) continue;
+				seen.add(table);
+
+				const data = { section: section.name, headers: [], rows: [] };
+				for (let i = 0; i < table.rows.length; i++) {
+					const cells = Array.from(table.rows[i].cells).map(cell => cell.textContent.trim());
+					if (i === 0) {
+						data.headers = cells;
+					} else {
+						data.rows.push(cells);
+					}
+				}
+				tables.push(data);
+			}
+		}
+		return tables;
+	}`, inlineSizeTableSections)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find inline tables: %w", err)
+	}
+
+	items, _ := result.([]interface{})
+	tables := make([]InlineSizeTable, 0, len(items))
+	for _, item := range items {
+		data, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		section, _ := data["section"].(string)
+		tables = append(tables, InlineSizeTable{Section: section, Data: data})
+	}
+	return tables, nil
+}
+
+// descriptionTextSelectors are the bullet points and description blocks of a product page
+const descriptionTextSelectors = "#feature-bullets, #productDescription, #productDescription_feature_div, #aplus_feature_div"
+
+// ProductDescriptionText returns the visible text of the bullet points and product description, one block
+// per line group, for measurements that are only mentioned in prose
+func ProductDescriptionText(page playwright.Page) (string, error) {
+	result, err := page.Evaluate(`(selector) => {
+		const seen = new Set();
+		const blocks = [];
+		for (const el of document.querySelectorAll(selector)) {
+			if (seen.has(el)) continue;
+			seen.add(el);
+			const text = (el.innerText || '').trim();
+			if (text) blocks.push(text);
+		}
+		return blocks.join('\n');
+	}`, descriptionTextSelectors)
+	if err != nil {
+		return "", fmt.Errorf("failed to read description text: %w", err)
+	}
+	text, _ := result.(string)
+	return text, nil
+}
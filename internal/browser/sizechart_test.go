@@ -0,0 +1,57 @@
+package browser
+
+import "testing"
+
+func TestDefaultSizeChartLayouts(t *testing.T) {
+	layouts := DefaultSizeChartLayouts()
+	seen := make(map[string]bool)
+	for _, layout := range layouts {
+		if layout.Name == "" || layout.Table == "" {
+			t.Errorf("incomplete layout %+v", layout)
+		}
+		if seen[layout.Name] {
+			t.Errorf("duplicate layout %q", layout.Name)
+		}
+		seen[layout.Name] = true
+	}
+	for _, name := range []string{"popover", "modal", "side-sheet"} {
+		if !seen[name] {
+			t.Errorf("missing default layout %q", name)
+		}
+	}
+	// The broad legacy selector also matches hidden popovers of other widgets, so it goes last
+	if last := layouts[len(layouts)-1].Name; last != "legacy-popover" {
+		t.Errorf("last layout = %q, want legacy-popover", last)
+	}
+}
+
+func TestParseSizeChartLayouts(t *testing.T) {
+	defaults := DefaultSizeChartLayouts()
+
+	layouts, err := ParseSizeChartLayouts(" flyout = #sizeFlyout table ;;drawer=.drawer table[data-x=\"a=b\"]", defaults)
+	if err != nil {
+		t.Fatalf("ParseSizeChartLayouts() error = %v", err)
+	}
+	if len(layouts) != len(defaults)+2 {
+		t.Fatalf("ParseSizeChartLayouts() = %d layouts, want %d", len(layouts), len(defaults)+2)
+	}
+	if layouts[0] != (SizeChartLayout{Name: "flyout", Table: "#sizeFlyout table"}) {
+		t.Errorf("layouts[0] = %+v", layouts[0])
+	}
+	if layouts[1].Table != `.drawer table[data-x="a=b"]` {
+		t.Errorf("layouts[1] = %+v", layouts[1])
+	}
+	if layouts[2] != defaults[0] {
+		t.Errorf("custom layouts should come before the defaults, got %+v", layouts[2])
+	}
+
+	if layouts, err := ParseSizeChartLayouts("", defaults); err != nil || len(layouts) != len(defaults) {
+		t.Errorf("ParseSizeChartLayouts(\"\") = %d layouts, %v", len(layouts), err)
+	}
+
+	for _, invalid := range []string{"flyout", "=table", "flyout="} {
+		if _, err := ParseSizeChartLayouts(invalid, defaults); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package browser
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SizeChartKindWidget marks a layout whose selector matches the root of the apparel-size-chart React
+// widget instead of a table. Its data comes from the embedded JSON state, or from the rendered grid
+// inside the widget's shadow roots when there is none.
+const SizeChartKindWidget = "widget"
+
+// apparelSizeChartWidget is the root of the apparel-size-chart widget
+const apparelSizeChartWidget = `apparel-size-chart, [data-component-type="apparel-size-chart"], [data-csa-c-type="apparel-size-chart"], #apparel-size-chart`
+
+// extractWidget collects the JSON state and grid rows of the widget, descending into open shadow roots
+// which document.querySelectorAll does not reach
+func (c *SizeChart) extractWidget() (interface{}, error) {
+	result, err := c.Table.Evaluate(`root => {
+		const states = [];
+		const rows = [];
+		const walk = (el) => {
+			for (const attr of ['data-size-chart', 'data-props', 'data-state']) {
+				const value = el.getAttribute(attr);
+				if (value) states.push(value);
+			}
+			if (el.tagName === 'SCRIPT' && /json|a-state/.test(el.type || '')) {
+				states.push(el.textContent);
+			}
+			const role = el.getAttribute('role');
+			if (el.tagName === 'TR' || role === 'row') {
+				const cells = [];
+				for (const cell of el.children) {
+					const cellRole = cell.getAttribute('role');
+					if (['TD', 'TH'].includes(cell.tagName) || ['cell', 'gridcell', 'columnheader', 'rowheader'].includes(cellRole)) {
+						cells.push((cell.textContent || '').trim());
+					}
+				}
+				if (cells.length) rows.push(cells);
+			}
+			if (el.shadowRoot) {
+				for (const child of el.shadowRoot.children) walk(child);
+			}
+			for (const child of el.children) walk(child);
+		};
+		walk(root);
+		return { states, rows };
+	}`, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read size chart widget: %w", err)
+	}
+
+	data, _ := result.(map[string]interface{})
+	var states []string
+	if items, ok := data["states"].([]interface{}); ok {
+		for _, item := range items {
+			if s, ok := item.(string); ok {
+				states = append(states, s)
+			}
+		}
+	}
+	var grid [][]string
+	if items, ok := data["rows"].([]interface{}); ok {
+		for _, item := range items {
+			cells, _ := item.([]interface{})
+			row := make([]string, 0, len(cells))
+			for _, cell := range cells {
+				s, _ := cell.(string)
+				row = append(row, s)
+			}
+			grid = append(grid, row)
+		}
+	}
+	return widgetTable(states, grid)
+}
+
+// widgetTable returns the first JSON state holding a size table, else the grid with its first row as
+// header, as {headers: [...], rows: [[...]]} like SizeChart.ExtractTable
+func widgetTable(states []string, grid [][]string) (interface{}, error) {
+	for _, state := range states {
+		var doc interface{}
+		if err := json.Unmarshal([]byte(state), &doc); err != nil {
+			continue
+		}
+		if headers, rows, ok := findStateTable(doc); ok {
+			return tableData(headers, rows), nil
+		}
+	}
+	if len(grid) >= 2 && len(grid[0]) > 0 {
+		return tableData(grid[0], grid[1:]), nil
+	}
+	return nil, fmt.Errorf("%w: widget has neither state nor grid", ErrSizeChartNotFound)
+}
+
+// findStateTable searches the widget state depth-first for an object with header columns and rows.
+// Columns are strings or objects with a label, rows are arrays or objects with cells or values.
+func findStateTable(doc interface{}) ([]string, [][]string, bool) {
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		var columns []interface{}
+		for _, key := range []string{"headers", "columns", "header"} {
+			if c, ok := v[key].([]interface{}); ok && len(c) > 0 {
+				columns = c
+				break
+			}
+		}
+		if rawRows, ok := v["rows"].([]interface{}); ok && len(columns) > 0 && len(rawRows) > 0 {
+			headers := make([]string, 0, len(columns))
+			for _, c := range columns {
+				headers = append(headers, cellText(c))
+			}
+			var rows [][]string
+			for _, r := range rawRows {
+				if row := rowCells(r); len(row) > 0 {
+					rows = append(rows, row)
+				}
+			}
+			if len(rows) > 0 {
+				return headers, rows, true
+			}
+		}
+		// Sorted keys keep the result stable for states holding more than one table
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if headers, rows, ok := findStateTable(v[key]); ok {
+				return headers, rows, true
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			if headers, rows, ok := findStateTable(child); ok {
+				return headers, rows, true
+			}
+		}
+	}
+	return nil, nil, false
+}
+
+// rowCells returns the cell texts of a state row
+func rowCells(row interface{}) []string {
+	var cells []interface{}
+	switch v := row.(type) {
+	case []interface{}:
+		cells = v
+	case map[string]interface{}:
+		for _, key := range []string{"cells", "values", "columns"} {
+			if c, ok := v[key].([]interface{}); ok {
+				cells = c
+				break
+			}
+		}
+	}
+	texts := make([]string, 0, len(cells))
+	for _, cell := range cells {
+		texts = append(texts, cellText(cell))
+	}
+	return texts
+}
+
+// cellText returns the display text of a state cell
+func cellText(cell interface{}) string {
+	switch v := cell.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case map[string]interface{}:
+		for _, key := range []string{"label", "text", "value", "name", "title", "displayValue"} {
+			if _, ok := v[key]; ok {
+				return cellText(v[key])
+			}
+		}
+	}
+	return ""
+}
+
+// tableData builds the {headers, rows} shape the size table parsers expect
+func tableData(headers []string, rows [][]string) map[string]interface{} {
+	h := make([]interface{}, len(headers))
+	for i, header := range headers {
+		h[i] = header
+	}
+	r := make([]interface{}, len(rows))
+	for i, row := range rows {
+		cells := make([]interface{}, len(row))
+		for j, cell := range row {
+			cells[j] = cell
+		}
+		r[i] = cells
+	}
+	return map[string]interface{}{"headers": h, "rows": r}
+}
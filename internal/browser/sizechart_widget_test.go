@@ -0,0 +1,45 @@
+package browser
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestWidgetTable_State(t *testing.T) {
+	states := []string{
+		`{"key": "apparel-size-chart"}`,
+		`{"sizeChart": {"unit": "cm", "columns": [{"label": "Größe"}, {"label": "Brustumfang"}, {"label": "Länge"}],
+			"rows": [{"cells": [{"value": "M"}, {"value": 104}, {"value": 74.5}]}, ["L", "110", "76"]]}}`,
+	}
+	got, err := widgetTable(states, [][]string{{"ignored"}, {"grid"}})
+	if err != nil {
+		t.Fatalf("widgetTable() error = %v", err)
+	}
+	want := map[string]interface{}{
+		"headers": []interface{}{"Größe", "Brustumfang", "Länge"},
+		"rows":    []interface{}{[]interface{}{"M", "104", "74.5"}, []interface{}{"L", "110", "76"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("widgetTable() = %v, want %v", got, want)
+	}
+}
+
+func TestWidgetTable_Grid(t *testing.T) {
+	grid := [][]string{{"Größe", "Länge"}, {"M", "74"}}
+	got, err := widgetTable([]string{`not json`, `{"rows": []}`}, grid)
+	if err != nil {
+		t.Fatalf("widgetTable() error = %v", err)
+	}
+	want := map[string]interface{}{
+		"headers": []interface{}{"Größe", "Länge"},
+		"rows":    []interface{}{[]interface{}{"M", "74"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("widgetTable() = %v, want %v", got, want)
+	}
+
+	if _, err := widgetTable(nil, [][]string{{"Größe"}}); !errors.Is(err, ErrSizeChartNotFound) {
+		t.Errorf("widgetTable() without data error = %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+)
+
+// SupervisorStats contains crash recovery counters for monitoring
+type SupervisorStats struct {
+	Connected bool  `json:"connected"`
+	Restarts  int64 `json:"restarts"`
+	Replays   int64 `json:"replays"`
+	Failures  int64 `json:"failures"`
+	Timeouts  int64 `json:"timeouts"`
+}
+
+// Supervisor relaunches a crashed browser and replays the task that was in flight
+type Supervisor struct {
+	browser    *Browser
+	maxReplays int
+	replays    atomic.Int64
+	failures   atomic.Int64
+	timeouts   atomic.Int64
+	logger     *slog.Logger
+}
+
+// NewSupervisor creates a supervisor for the given browser
+func NewSupervisor(b *Browser, maxReplays int) *Supervisor {
+	if maxReplays <= 0 {
+		maxReplays = 1
+	}
+
+	return &Supervisor{
+		browser:    b,
+		maxReplays: maxReplays,
+		logger:     slog.Default().With("component", "browser_supervisor"),
+	}
+}
+
+// Browser returns the supervised browser
+func (s *Supervisor) Browser() *Browser {
+	return s.browser
+}
+
+// Run executes task, relaunching the browser and replaying the task if it crashed
+func (s *Supervisor) Run(ctx context.Context, name string, task func() error) error {
+	for attempt := 0; ; attempt++ {
+		if err := s.ensureConnected(); err != nil {
+			return err
+		}
+
+		restarts := s.browser.Restarts()
+		err := task()
+		if err == nil {
+			return nil
+		}
+
+		// A task cut off by its deadline closes its page, which looks like a crash but must not be replayed
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			if errors.Is(ctxErr, context.DeadlineExceeded) {
+				s.timeouts.Add(1)
+			}
+			return fmt.Errorf("task %s aborted: %w (%v)", name, ctxErr, err)
+		}
+
+		if !s.crashed(err) {
+			return err
+		}
+
+		s.logger.Warn("browser crashed during task", "task", name, "attempt", attempt+1, "error", err)
+		// A concurrent task may already have relaunched the browser this task crashed with
+		if s.browser.Restarts() == restarts {
+			s.browser.connected.Store(false)
+		}
+
+		if attempt >= s.maxReplays {
+			s.failures.Add(1)
+			return fmt.Errorf("task %s failed after %d replays: %w", name, attempt, err)
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		s.replays.Add(1)
+		s.logger.Info("replaying task after browser crash", "task", name)
+	}
+}
+
+// Stats returns the current crash recovery counters
+func (s *Supervisor) Stats() SupervisorStats {
+	return SupervisorStats{
+		Connected: s.browser.IsConnected(),
+		Restarts:  s.browser.Restarts(),
+		Replays:   s.replays.Load(),
+		Failures:  s.failures.Load(),
+		Timeouts:  s.timeouts.Load(),
+	}
+}
+
+// ensureConnected relaunches the browser if it is no longer connected
+func (s *Supervisor) ensureConnected() error {
+	if err := s.browser.RelaunchIfDisconnected(); err != nil {
+		s.failures.Add(1)
+		return err
+	}
+
+	return nil
+}
+
+// crashed reports whether err was caused by a dead browser rather than the page itself
+func (s *Supervisor) crashed(err error) bool {
+	if errors.Is(err, ErrBrowserDisconnected) || !s.browser.IsConnected() {
+		return true
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, marker := range []string{"target closed", "browser has been closed", "target page, context or browser has been closed"} {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,178 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+func newConnectedBrowser() *Browser {
+	b := &Browser{opts: DefaultOptions()}
+	b.connected.Store(true)
+	return b
+}
+
+func TestSupervisorRunPassesThroughTaskErrors(t *testing.T) {
+	s := NewSupervisor(newConnectedBrowser(), 1)
+
+	calls := 0
+	taskErr := errors.New("size table button not found")
+	err := s.Run(context.Background(), "test", func() error {
+		calls++
+		return taskErr
+	})
+
+	if !errors.Is(err, taskErr) {
+		t.Errorf("Expected task error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected task to run once, ran %d times", calls)
+	}
+	if stats := s.Stats(); stats.Replays != 0 {
+		t.Errorf("Expected no replays, got %d", stats.Replays)
+	}
+}
+
+func TestSupervisorDetectsCrashErrors(t *testing.T) {
+	s := NewSupervisor(newConnectedBrowser(), 1)
+
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"Disconnected sentinel", fmt.Errorf("failed to create page: %w", ErrBrowserDisconnected), true},
+		{"Target closed", errors.New("failed to navigate: Target closed"), true},
+		{"Browser closed", errors.New("Target page, context or browser has been closed"), true},
+		{"Navigation timeout", errors.New("Timeout 30000ms exceeded"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.crashed(tt.err); got != tt.expected {
+				t.Errorf("crashed(%q) = %v, want %v", tt.err, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRelaunchIfDisconnectedKeepsConnectedBrowser(t *testing.T) {
+	b := newConnectedBrowser()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := b.RelaunchIfDisconnected(); err != nil {
+				t.Errorf("Expected no relaunch, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if b.Restarts() != 0 {
+		t.Errorf("Expected no restarts, got %d", b.Restarts())
+	}
+}
+
+func TestSupervisorRunKeepsBrowserRelaunchedByOtherTask(t *testing.T) {
+	b := newConnectedBrowser()
+	s := NewSupervisor(b, 0)
+
+	err := s.Run(context.Background(), "test", func() error {
+		// Another task relaunched the browser while this one ran on the crashed process
+		b.restarts.Add(1)
+		return errors.New("failed to navigate: Target closed")
+	})
+
+	if err == nil {
+		t.Fatal("Expected the crash to fail the task without replays")
+	}
+	if !b.IsConnected() {
+		t.Error("Expected the relaunched browser to stay connected")
+	}
+}
+
+func TestNewPageFailsWhenDisconnected(t *testing.T) {
+	b := &Browser{opts: DefaultOptions()}
+
+	if _, err := b.NewPage(); !errors.Is(err, ErrBrowserDisconnected) {
+		t.Errorf("Expected ErrBrowserDisconnected, got %v", err)
+	}
+}
+
+type fakeBrowser struct {
+	playwright.Browser
+}
+
+func TestDisconnectIgnoresReplacedBrowser(t *testing.T) {
+	b := newConnectedBrowser()
+	b.logger = slog.Default()
+
+	crashed, current := &fakeBrowser{}, &fakeBrowser{}
+	b.browser = current
+
+	// A late disconnect of the browser a relaunch replaced
+	b.disconnected(crashed)
+	if !b.IsConnected() {
+		t.Fatal("Expected the disconnect of a replaced browser to keep the current one connected")
+	}
+
+	b.disconnected(current)
+	if b.IsConnected() {
+		t.Error("Expected the disconnect of the current browser to mark it disconnected")
+	}
+}
+
+func TestSupervisorRunCountsDeadlineAsTimeout(t *testing.T) {
+	b := newConnectedBrowser()
+	s := NewSupervisor(b, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	calls := 0
+	err := s.Run(ctx, "test", func() error {
+		calls++
+		// The deadline closes the page, which surfaces as a crash-like error
+		<-ctx.Done()
+		return errors.New("failed to navigate: Target closed")
+	})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected no replay after the deadline, ran %d times", calls)
+	}
+	if !b.IsConnected() {
+		t.Error("Expected browser to stay connected")
+	}
+	if stats := s.Stats(); stats.Timeouts != 1 || stats.Replays != 0 {
+		t.Errorf("Expected 1 timeout and no replays, got %+v", stats)
+	}
+}
+
+func TestSleepReturnsWhenContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	if err := Sleep(ctx, time.Minute); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("Sleep did not return early")
+	}
+
+	if err := Sleep(context.Background(), time.Millisecond); err != nil {
+		t.Errorf("Expected nil, got %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+package browser
+
+import (
+	"context"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// Sleep waits for d or until ctx is done, returning the context error in the latter case
+func Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// ClosePageOnDone closes the page once ctx is done, so pending Playwright calls fail instead of
+// running into their own timeouts. The returned function stops watching and must be called.
+func ClosePageOnDone(ctx context.Context, page playwright.Page) func() {
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			page.Close()
+		case <-done:
+		}
+	}()
+	return func() { close(done) }
+}
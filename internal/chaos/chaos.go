@@ -0,0 +1,99 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrInjected marks failures caused on purpose by fault injection
+var ErrInjected = errors.New("injected fault")
+
+// Config controls which faults are injected into the event pipeline
+type Config struct {
+	PublishFailureRate float64       // Share of relay publishes failed without reaching Redis
+	DuplicateRate      float64       // Share of published stream messages added twice
+	MaxDelay           time.Duration // Upper bound of the random delay before each outbox batch
+}
+
+// Validate checks that rates are probabilities and the delay is not negative
+func (c Config) Validate() error {
+	for name, rate := range map[string]float64{"publish failure": c.PublishFailureRate, "duplicate": c.DuplicateRate} {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("%s rate must be between 0 and 1: %v", name, rate)
+		}
+	}
+	if c.MaxDelay < 0 {
+		return fmt.Errorf("max delay must not be negative")
+	}
+	return nil
+}
+
+// Injector decides randomly which faults to inject, a nil Injector injects nothing
+type Injector struct {
+	cfg  Config
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// New creates an injector, the seed makes a fault sequence reproducible
+func New(cfg Config, seed int64) (*Injector, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &Injector{cfg: cfg, rand: rand.New(rand.NewSource(seed))}, nil
+}
+
+// Config returns the configured fault rates
+func (i *Injector) Config() Config {
+	if i == nil {
+		return Config{}
+	}
+	return i.cfg
+}
+
+// FailPublish returns ErrInjected for the configured share of publishes
+func (i *Injector) FailPublish() error {
+	if i.roll(i.Config().PublishFailureRate) {
+		return fmt.Errorf("%w: publish failed", ErrInjected)
+	}
+	return nil
+}
+
+// Duplicate reports whether a published message should be added a second time
+func (i *Injector) Duplicate() bool {
+	return i.roll(i.Config().DuplicateRate)
+}
+
+// Delay waits a random duration up to MaxDelay, returning early when ctx is done
+func (i *Injector) Delay(ctx context.Context) error {
+	max := i.Config().MaxDelay
+	if max <= 0 {
+		return nil
+	}
+
+	i.mu.Lock()
+	d := time.Duration(i.rand.Int63n(int64(max) + 1))
+	i.mu.Unlock()
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (i *Injector) roll(rate float64) bool {
+	if i == nil || rate <= 0 {
+		return false
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.rand.Float64() < rate
+}
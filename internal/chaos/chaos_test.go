@@ -0,0 +1,79 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNilInjectorInjectsNothing(t *testing.T) {
+	var i *Injector
+
+	if err := i.FailPublish(); err != nil {
+		t.Errorf("Expected no publish failure, got %v", err)
+	}
+	if i.Duplicate() {
+		t.Error("Expected no duplicate")
+	}
+	if err := i.Delay(context.Background()); err != nil {
+		t.Errorf("Expected no delay error, got %v", err)
+	}
+}
+
+func TestInjectorRates(t *testing.T) {
+	always, err := New(Config{PublishFailureRate: 1, DuplicateRate: 1}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := always.FailPublish(); !errors.Is(err, ErrInjected) {
+		t.Errorf("Expected injected failure, got %v", err)
+	}
+	if !always.Duplicate() {
+		t.Error("Expected duplicate")
+	}
+
+	half, _ := New(Config{PublishFailureRate: 0.5}, 1)
+	failed := 0
+	for n := 0; n < 1000; n++ {
+		if half.FailPublish() != nil {
+			failed++
+		}
+	}
+	if failed < 400 || failed > 600 {
+		t.Errorf("Expected about half of the publishes to fail, got %d of 1000", failed)
+	}
+}
+
+func TestInjectorDelayStopsWithContext(t *testing.T) {
+	i, _ := New(Config{MaxDelay: time.Hour}, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := i.Delay(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{"Disabled", Config{}, false},
+		{"Valid", Config{PublishFailureRate: 0.1, DuplicateRate: 0.05, MaxDelay: time.Second}, false},
+		{"Rate above one", Config{DuplicateRate: 1.5}, true},
+		{"Negative rate", Config{PublishFailureRate: -0.1}, true},
+		{"Negative delay", Config{MaxDelay: -time.Second}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/events"
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/jobs"
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/scraper"
+	"github.com/maltedev/amazon-size-scraper/internal/analytics"
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/spf13/cobra"
+)
+
+func newAnalyzeCommand(a *app) *cobra.Command {
+	var opts jobs.SizeAnalysisOptions
+
+	cmd := &cobra.Command{
+		Use:   "analyze",
+		Short: "Analyze which search results have size tables with length and width",
+		Long: "Collect the products of a search and classify their size tables as complete (length and width), " +
+			"missing length, missing width, missing both or no table. The run is a size analysis job, every " +
+			"result and the statistics are stored and can be read through /api/v1/analytics/size-analyses.",
+		Example: "  scraper analyze --query 't shirt größentabelle länge' --category fashion --sample 50",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.SearchQuery == "" {
+				return fmt.Errorf("please provide a search with --query")
+			}
+			return a.runAnalyze(cmd.Context(), opts)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&opts.SearchQuery, "query", "", "Search query whose results are analyzed")
+	flags.StringVar(&opts.Category, "category", "fashion", "Amazon search category, empty searches all categories")
+	flags.StringVar(&opts.Marketplace, "marketplace", getEnv("SCRAPER_MARKETPLACE", jobs.DefaultMarketplace), "Amazon marketplace to search")
+	flags.IntVar(&opts.MaxPages, "pages", 1, "Result pages to collect products from")
+	flags.IntVar(&opts.SampleSize, "sample", jobs.DefaultAnalysisSample, fmt.Sprintf("Products to analyze, at most %d", jobs.MaxAnalysisSample))
+	return cmd
+}
+
+func (a *app) runAnalyze(ctx context.Context, opts jobs.SizeAnalysisOptions) error {
+	logger := a.logger
+
+	db, err := database.New(ctx, database.Config{
+		Host:        a.cfg.Database.Host,
+		Port:        a.cfg.Database.Port,
+		User:        a.cfg.Database.User,
+		Password:    a.cfg.Database.Password,
+		Database:    a.cfg.Database.DBName,
+		MaxConns:    2,
+		MinConns:    1,
+		MaxConnLife: 5 * time.Minute,
+		MaxConnIdle: 1 * time.Minute,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	b, err := a.newBrowser()
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+
+	manager := jobs.NewManager(db, scraper.NewService(b, db, logger), events.NewPublisher(db, logger), logger)
+	job, analysis, err := manager.AnalyzeSizes(ctx, opts)
+	if job != nil {
+		fmt.Printf("Size analysis job: %s\n", job.ID)
+	}
+	if err != nil {
+		return err
+	}
+
+	stats := analysis.Statistics
+	fmt.Printf("Analyzed %d products of %s\n", stats.Total, analysis.SearchURL)
+	for _, row := range []struct {
+		label string
+		n     int
+	}{
+		{"Complete (length + width)", stats.Complete},
+		{"Missing length only", stats.MissingLength},
+		{"Missing width only", stats.MissingWidth},
+		{"Missing both", stats.MissingBoth},
+		{"No size table", stats.NoTable},
+		{"Errors", stats.Errors},
+	} {
+		fmt.Printf("  %-26s %4d (%.1f%%)\n", row.label, row.n, stats.Share(row.n)*100)
+	}
+
+	complete, err := db.ListSizeAnalysisResults(ctx, analysis.ID, analytics.StatusComplete)
+	if err != nil {
+		return err
+	}
+	if len(complete) > 0 {
+		fmt.Println("Products with length and width:")
+		for _, r := range complete {
+			fmt.Printf("  %s %s\n", r.ASIN, r.Title)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/events"
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/jobs"
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/spf13/cobra"
+)
+
+func newBackfillCommand(a *app) *cobra.Command {
+	var (
+		opts     jobs.BackfillOptions
+		asins    string
+		statuses string
+		since    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "backfill",
+		Short: "Re-emit NEW_PRODUCT_DETECTED events for stored products",
+		Long: "Re-emit NEW_PRODUCT_DETECTED events for stored products, e.g. after an event schema change. " +
+			"Payloads are rebuilt from the products table without scraping and inserted into the outbox in " +
+			"batches, the relay publishes them as usual. An interrupted run continues with --after.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Filter.ASINs = splitList(asins)
+			opts.Filter.Statuses = splitList(statuses)
+			if since != "" {
+				t, err := time.Parse("2006-01-02", since)
+				if err != nil {
+					return fmt.Errorf("invalid --since date, expected YYYY-MM-DD: %w", err)
+				}
+				opts.Filter.UpdatedSince = &t
+			}
+			return a.runBackfill(cmd.Context(), opts)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&asins, "asins", "", "Comma separated ASINs, empty selects all products")
+	flags.StringVar(&statuses, "status", "", "Comma separated product statuses, e.g. completed")
+	flags.StringVar(&opts.Filter.Category, "category", "", "Only products of this category")
+	flags.StringVar(&since, "since", "", "Only products updated on or after this date (YYYY-MM-DD)")
+	flags.BoolVar(&opts.Filter.RequireSizeTable, "with-size-table", false, "Only products with a stored size table")
+	flags.IntVar(&opts.BatchSize, "batch-size", jobs.DefaultBackfillBatchSize, "Outbox events inserted per transaction")
+	flags.DurationVar(&opts.Throttle, "throttle", time.Second, "Pause between batches")
+	flags.IntVar(&opts.Limit, "limit", 0, "Stop after this many products (0 is unlimited)")
+	flags.StringVar(&opts.After, "after", "", "Start after this ASIN, e.g. the last ASIN of an interrupted run")
+	flags.BoolVar(&opts.DryRun, "dry-run", false, "Count matching products without inserting events")
+	return cmd
+}
+
+func (a *app) runBackfill(ctx context.Context, opts jobs.BackfillOptions) error {
+	logger := a.logger
+
+	db, err := database.New(ctx, database.Config{
+		Host:        a.cfg.Database.Host,
+		Port:        a.cfg.Database.Port,
+		User:        a.cfg.Database.User,
+		Password:    a.cfg.Database.Password,
+		Database:    a.cfg.Database.DBName,
+		MaxConns:    2,
+		MinConns:    1,
+		MaxConnLife: 5 * time.Minute,
+		MaxConnIdle: 1 * time.Minute,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	manager := jobs.NewManager(db, nil, events.NewPublisher(db, logger), logger)
+	result, err := manager.Backfill(ctx, opts)
+	if result != nil {
+		fmt.Printf("Backfill: %d scanned, %d events, %d skipped, last ASIN %q\n",
+			result.Scanned, result.Published, result.Skipped, result.LastASIN)
+	}
+	return err
+}
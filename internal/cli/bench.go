@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/maltedev/amazon-size-scraper/internal/bench"
+	"github.com/spf13/cobra"
+)
+
+func newBenchCommand(a *app) *cobra.Command {
+	var (
+		dir, filter, output string
+		baseline, save      string
+		tolerance           float64
+	)
+
+	cmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Benchmark the product page parser and size table pipeline on a corpus of product pages",
+		Long: "Benchmark ParseProductPage, ExtractMaterialComposition and the size table parser. One op parses every page of the corpus once, " +
+			"the amazontest fixtures by default or the saved pages of --dir. With --baseline the run fails when a benchmark got slower or " +
+			"allocates more than --tolerance allows.",
+		Example: "  scraper bench --save bench.json\n  scraper bench --baseline bench.json --tolerance 0.2\n  scraper bench --dir pages/ --filter SizeTable",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			corpus, err := bench.DefaultCorpus()
+			if dir != "" {
+				corpus, err = bench.LoadDir(dir)
+			}
+			if err != nil {
+				return err
+			}
+			if len(corpus) == 0 {
+				return fmt.Errorf("no product pages in %s", dir)
+			}
+
+			results := bench.Run(bench.Benchmarks(corpus), filter)
+			if output == "json" {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				enc.Encode(results)
+			} else {
+				fmt.Printf("%d product pages\n", len(corpus))
+				tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+				fmt.Fprintln(tw, "benchmark\titerations\tns/op\tB/op\tallocs/op\t")
+				for _, r := range results {
+					fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t\n", r.Name, r.Iterations, r.NsPerOp, r.BytesPerOp, r.AllocsPerOp)
+				}
+				tw.Flush()
+			}
+
+			if save != "" {
+				if err := bench.WriteResults(save, results); err != nil {
+					return fmt.Errorf("failed to save results: %w", err)
+				}
+			}
+			if baseline == "" {
+				return nil
+			}
+
+			base, err := bench.ReadResults(baseline)
+			if err != nil {
+				return err
+			}
+			regressions := bench.Compare(base, results, tolerance)
+			for _, r := range regressions {
+				a.logger.Error("benchmark regression", "benchmark", r.Name, "metric", r.Metric, "baseline", r.Baseline, "current", r.Current, "change", fmt.Sprintf("%+.0f%%", r.Change*100))
+			}
+			if len(regressions) > 0 {
+				return fmt.Errorf("%d benchmark regressions against %s", len(regressions), baseline)
+			}
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&dir, "dir", "", "Directory of saved product pages (*.html, named by ASIN), empty uses the amazontest fixtures")
+	flags.StringVar(&filter, "filter", "", "Only run benchmarks whose name contains this")
+	flags.StringVar(&output, "output", "table", "Output format: table or json")
+	flags.StringVar(&save, "save", "", "Write the results as JSON baseline to this file")
+	flags.StringVar(&baseline, "baseline", "", "Fail when results regress against this baseline file")
+	flags.Float64Var(&tolerance, "tolerance", 0.2, "Allowed relative increase of ns/op and allocs/op over the baseline")
+	return cmd
+}
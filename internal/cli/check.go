@@ -0,0 +1,204 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/config"
+	"github.com/maltedev/amazon-size-scraper/internal/browser"
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/maltedev/amazon-size-scraper/internal/eventroute"
+	"github.com/maltedev/amazon-size-scraper/internal/redisconn"
+	"github.com/maltedev/amazon-size-scraper/internal/schema"
+	"github.com/spf13/cobra"
+)
+
+// Status of a check
+const (
+	checkOK      = "ok"
+	checkFailed  = "failed"
+	checkSkipped = "skipped"
+)
+
+// checkResult is one line of the readiness summary
+type checkResult struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Detail     string `json:"detail,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+func newCheckCommand(a *app) *cobra.Command {
+	var (
+		pageURL, output string
+		timeout         time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check config, Postgres, Redis, the schema version and the browser before deploying",
+		Long: "Runs the startup steps of serve without serving: validates the config, connects to Postgres and Redis, compares the " +
+			"migration version with the one this build expects, launches a headless browser and opens a page Amazon serves without " +
+			"a robot check. Prints a readiness summary and fails when any check failed.",
+		Example: "  scraper check\n  scraper check --output json --timeout 1m",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			results := runChecks(cmd.Context(), pageURL, timeout)
+
+			if output == "json" {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				enc.Encode(results)
+			} else {
+				tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(tw, "check\tstatus\ttime\tdetail")
+				for _, r := range results {
+					fmt.Fprintf(tw, "%s\t%s\t%dms\t%s\n", r.Name, r.Status, r.DurationMS, r.Detail)
+				}
+				tw.Flush()
+			}
+
+			failed := 0
+			for _, r := range results {
+				if r.Status != checkOK {
+					failed++
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("not ready: %d of %d checks did not pass", failed, len(results))
+			}
+			a.logger.Info("ready to deploy")
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&pageURL, "url", "", "Page the browser opens, default robots.txt of SCRAPER_MARKETPLACE")
+	flags.StringVar(&output, "output", "table", "Output format: table or json")
+	flags.DurationVar(&timeout, "timeout", 30*time.Second, "Time limit of each check")
+	return cmd
+}
+
+// runChecks runs every check in order, a check whose dependency failed is skipped
+func runChecks(ctx context.Context, pageURL string, timeout time.Duration) []checkResult {
+	var results []checkResult
+	run := func(name string, fn func(ctx context.Context) (string, error)) bool {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		start := time.Now()
+		detail, err := fn(ctx)
+		r := checkResult{Name: name, Status: checkOK, Detail: detail, DurationMS: time.Since(start).Milliseconds()}
+		if err != nil {
+			r.Status, r.Detail = checkFailed, err.Error()
+		}
+		results = append(results, r)
+		return err == nil
+	}
+	skip := func(reason string, names ...string) {
+		for _, name := range names {
+			results = append(results, checkResult{Name: name, Status: checkSkipped, Detail: reason})
+		}
+	}
+
+	var (
+		cfg         *config.Config
+		browserOpts *browser.Options
+	)
+	if !run("config", func(ctx context.Context) (string, error) {
+		var err error
+		if cfg, err = config.Load(); err != nil {
+			return "", err
+		}
+		if browserOpts, err = serveBrowserOptions(cfg); err != nil {
+			return "", err
+		}
+		routes, err := eventroute.ParseTable(cfg.Events.Routes, cfg.Events.DefaultTarget)
+		if err == nil {
+			err = routes.Validate(schema.NewRegistry().Known, cfg.Events.KafkaRESTURL != "")
+		}
+		if err != nil {
+			return "", fmt.Errorf("invalid event routes: %w", err)
+		}
+		return fmt.Sprintf("environment %s, marketplace %s", cfg.Server.Environment, cfg.Scraper.Marketplace), nil
+	}) {
+		skip("config is invalid", "postgres", "schema", "redis", "browser")
+		return results
+	}
+
+	var db *database.DB
+	if run("postgres", func(ctx context.Context) (string, error) {
+		var err error
+		if db, err = database.New(ctx, serveDBConfig(cfg)); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s:%d/%s, %d replicas", cfg.Database.Host, cfg.Database.Port, cfg.Database.Name, len(db.Replicas())), nil
+	}) {
+		defer db.Close()
+		run("schema", func(ctx context.Context) (string, error) {
+			version, dirty, err := db.MigrationVersion(ctx)
+			if err != nil {
+				return "", err
+			}
+			return checkSchemaVersion(version, dirty)
+		})
+	} else {
+		skip("postgres is unreachable", "schema")
+	}
+
+	run("redis", func(ctx context.Context) (string, error) {
+		client, err := redisconn.New(cfg.Redis.Conn)
+		if err != nil {
+			return "", err
+		}
+		defer client.Close()
+		if err := client.Ping(ctx).Err(); err != nil {
+			return "", fmt.Errorf("failed to connect to Redis: %w", err)
+		}
+		return cfg.Redis.Conn.String(), nil
+	})
+
+	if pageURL == "" {
+		pageURL = "https://www." + cfg.Scraper.Marketplace + "/robots.txt"
+	}
+	run("browser", func(ctx context.Context) (string, error) {
+		opts := *browserOpts
+		opts.Headless = true
+		b, err := browser.New(&opts)
+		if err != nil {
+			return "", fmt.Errorf("failed to launch browser: %w", err)
+		}
+		defer b.Close()
+
+		page, err := b.NewPage()
+		if err != nil {
+			return "", fmt.Errorf("failed to create page: %w", err)
+		}
+		defer page.Close()
+
+		if err := b.NavigateWithRetryContext(ctx, page, pageURL, 1); err != nil {
+			return "", err
+		}
+		return "opened " + pageURL, nil
+	})
+
+	return results
+}
+
+// checkSchemaVersion compares the migration version of the database with SchemaVersion, a newer
+// schema passes because migrations run before the code that needs them is deployed
+func checkSchemaVersion(version int, dirty bool) (string, error) {
+	switch {
+	case dirty:
+		return "", fmt.Errorf("migration %d failed halfway, fix it and force the version with migrate", version)
+	case version < database.SchemaVersion:
+		return "", fmt.Errorf("schema version %d, this build needs %d, run the migrations", version, database.SchemaVersion)
+	case version > database.SchemaVersion:
+		return fmt.Sprintf("schema version %d, newer than %d of this build", version, database.SchemaVersion), nil
+	default:
+		return fmt.Sprintf("schema version %d", version), nil
+	}
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+)
+
+func TestCheckSchemaVersion(t *testing.T) {
+	tests := []struct {
+		version int
+		dirty   bool
+		wantErr bool
+	}{
+		{database.SchemaVersion, false, false},
+		{database.SchemaVersion + 1, false, false},
+		{database.SchemaVersion - 1, false, true},
+		{0, false, true},
+		{database.SchemaVersion, true, true},
+	}
+
+	for _, tt := range tests {
+		_, err := checkSchemaVersion(tt.version, tt.dirty)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("checkSchemaVersion(%d, %v) error = %v, wantErr %v", tt.version, tt.dirty, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSchemaVersionMatchesMigrations(t *testing.T) {
+	entries, err := os.ReadDir("../../migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	latest := 0
+	for _, e := range entries {
+		number, _, ok := strings.Cut(e.Name(), "_")
+		if !ok || !strings.HasSuffix(e.Name(), ".up.sql") {
+			continue
+		}
+		if n, err := strconv.Atoi(number); err == nil && n > latest {
+			latest = n
+		}
+	}
+	if latest != database.SchemaVersion {
+		t.Errorf("latest migration is %03d, database.SchemaVersion is %d", latest, database.SchemaVersion)
+	}
+}
@@ -0,0 +1,539 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/maltedev/amazon-size-scraper/internal/parser"
+	"github.com/maltedev/amazon-size-scraper/internal/redisconn"
+	"github.com/maltedev/amazon-size-scraper/internal/scraper"
+	"github.com/maltedev/amazon-size-scraper/internal/sink"
+	"github.com/maltedev/amazon-size-scraper/internal/storage"
+	"github.com/playwright-community/playwright-go"
+	"github.com/spf13/cobra"
+)
+
+func newCrawlCommand(a *app) *cobra.Command {
+	var (
+		searchURL   string
+		storageFile string
+		maxPages    int
+		fixEncoding bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "crawl",
+		Short: "Collect product links from search or category pages into a storage file",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if searchURL == "" {
+				return fmt.Errorf("please provide a search/category URL with --url")
+			}
+			if fixEncoding {
+				fixed := fixURLEncoding(searchURL)
+				a.logger.Info("URL fixed", "original", searchURL, "fixed", fixed)
+				searchURL = fixed
+			}
+
+			linkStorage, err := storage.NewLinkStorage(storageFile)
+			if err != nil {
+				return fmt.Errorf("failed to initialize storage: %w", err)
+			}
+
+			a.logger.Info("Starting Amazon Crawler", "mode", "collect")
+			return a.collectLinks(cmd.Context(), searchURL, maxPages, fixEncoding, linkStorage)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&searchURL, "url", "", "Amazon search/category URL")
+	flags.StringVar(&storageFile, "storage", "products.json", "Storage file for product links")
+	flags.IntVar(&maxPages, "pages", 10, "Maximum pages to crawl (0 = unlimited)")
+	flags.BoolVar(&fixEncoding, "fix-encoding", false, "Decode double encoded query strings of the start and next page URLs")
+	return cmd
+}
+
+func newProcessCommand(a *app) *cobra.Command {
+	var (
+		storageFile string
+		sinkSpecs   []string
+		redisAddr   string
+		sinkOpts    = sink.Options{Redis: redisconn.FromEnv()}
+	)
+
+	cmd := &cobra.Command{
+		Use:   "process",
+		Short: "Scrape the pending product links of a storage file",
+		Long: "Scrape the pending product links of a storage file. Products with dimensions are written to every --sink:\n\n" +
+			"  ndjson:<file>        one JSON object per line, ndjson:- writes to stdout\n" +
+			"  webhook:<url>        POST of each product as JSON\n" +
+			"  redis:<stream>       XADD to the stream at --redis-addr\n" +
+			"  postgres             upsert into crawl_results using DB_*",
+		Example: "  scraper process --sink ndjson:results.ndjson --sink webhook:https://example.com/products",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			linkStorage, err := storage.NewLinkStorage(storageFile)
+			if err != nil {
+				return fmt.Errorf("failed to initialize storage: %w", err)
+			}
+
+			sinkOpts.Database = database.Config{
+				Host:     a.cfg.Database.Host,
+				Port:     a.cfg.Database.Port,
+				User:     a.cfg.Database.User,
+				Password: a.cfg.Database.Password,
+				Database: a.cfg.Database.DBName,
+				MaxConns: 2,
+				MinConns: 1,
+			}
+			sinkOpts.Redis.Addrs = redisconn.SplitAddrs(redisAddr)
+			out, err := sink.OpenAll(cmd.Context(), sinkSpecs, sinkOpts)
+			if err != nil {
+				return err
+			}
+			defer out.Close()
+
+			a.logger.Info("Starting Amazon Crawler", "mode", "process", "sinks", sinkSpecs)
+			return a.processLinks(cmd.Context(), linkStorage, out)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&storageFile, "storage", "products.json", "Storage file for product links")
+	flags.StringArrayVar(&sinkSpecs, "sink", splitList(getEnv("CRAWLER_SINKS", "")), "Output sink for scraped products, repeatable (default from CRAWLER_SINKS, comma separated)")
+	flags.StringVar(&redisAddr, "redis-addr", getEnv("REDIS_ADDR", "localhost:6379"), "Redis address of redis sinks, comma separated for Sentinel and Cluster (REDIS_MODE)")
+	flags.StringVar(&sinkOpts.Redis.Password, "redis-password", getEnv("REDIS_PASSWORD", ""), "Redis password of redis sinks")
+	flags.DurationVar(&sinkOpts.WebhookTimeout, "webhook-timeout", getEnvDuration("CRAWLER_WEBHOOK_TIMEOUT", 10*time.Second), "Timeout per webhook call")
+	// Only one scraper runs at a time, the flag is kept for compatibility with the crawler binary
+	flags.Int("concurrent", 1, "Number of concurrent scrapers")
+	flags.MarkHidden("concurrent")
+	return cmd
+}
+
+// fixURLEncoding decodes query strings that were encoded twice, which amazon.de answers with an error page
+func fixURLEncoding(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	decodedQuery, err := url.QueryUnescape(u.RawQuery)
+	if err != nil {
+		return rawURL
+	}
+
+	u.RawQuery = decodedQuery
+	return u.String()
+}
+
+func (a *app) collectLinks(ctx context.Context, startURL string, maxPages int, fixEncoding bool, storage *storage.LinkStorage) error {
+	logger := a.logger
+	b, err := a.newBrowser()
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+
+	page, err := b.NewPage()
+	if err != nil {
+		return fmt.Errorf("failed to create page: %w", err)
+	}
+	defer page.Close()
+
+	currentURL := startURL
+	pageCount := 0
+	totalProducts := 0
+
+	tracker := a.newProgress("crawl")
+	tracker.Start()
+	defer tracker.Stop()
+
+	for {
+		if maxPages > 0 && pageCount >= maxPages {
+			logger.Info("Reached max pages limit", "pages", pageCount)
+			break
+		}
+
+		pageCount++
+		logger.Info("Crawling page", "page", pageCount, "url", currentURL)
+
+		// Navigate to page
+		if ctx.Err() != nil {
+			break
+		}
+		if err := b.NavigateWithRetryContext(ctx, page, currentURL, 3); err != nil {
+			logger.Error("Failed to navigate", "error", err, "url", currentURL)
+			break
+		}
+
+		// Wait for products to load
+		logger.Info("Waiting for page to load...")
+
+		// Take screenshot for debugging
+		screenshotPath := fmt.Sprintf("page-%d.png", pageCount)
+		if _, err := page.Screenshot(playwright.PageScreenshotOptions{
+			Path: &screenshotPath,
+		}); err == nil {
+			logger.Info("Screenshot saved", "file", screenshotPath)
+		}
+
+		// Check page title
+		title, _ := page.Title()
+		logger.Info("Page title", "title", title)
+
+		// Check for various product selectors
+		selectors := []string{
+			"[data-component-type='s-search-result']",
+			"div[data-asin]",
+			"[data-index]",
+			".s-result-item",
+			".s-main-slot .s-result-item",
+		}
+
+		foundSelector := ""
+		for _, selector := range selectors {
+			count, _ := page.Locator(selector).Count()
+			logger.Info("Checking selector", "selector", selector, "count", count)
+			if count > 0 {
+				foundSelector = selector
+				break
+			}
+		}
+
+		if foundSelector == "" {
+			// Check for captcha
+			if captchaCount, _ := page.Locator("#captchacharacters").Count(); captchaCount > 0 {
+				logger.Error("CAPTCHA detected! Manual intervention required")
+				time.Sleep(30 * time.Second) // Give time to solve manually
+			}
+		}
+
+		time.Sleep(3 * time.Second)
+
+		// Extract product links
+		products := extractProductLinks(page, logger)
+
+		if len(products) == 0 {
+			logger.Warn("No products found on page", "page", pageCount)
+			// Try alternative selectors
+			products = extractAlternativeProducts(page, logger)
+		}
+
+		logger.Info("Found products on page", "count", len(products), "page", pageCount)
+		totalProducts += len(products)
+		tracker.AddPage(len(products))
+
+		// Save to storage
+		if err := storage.AddBatch(products); err != nil {
+			logger.Error("Failed to save products", "error", err)
+		}
+
+		// Print summary
+		for _, p := range products {
+			fmt.Printf("✓ %s - %s\n", p.ASIN, p.Title)
+		}
+
+		// Check for next page
+		nextURL := findNextPageURL(page, logger)
+		if nextURL == "" {
+			logger.Info("No more pages found")
+			break
+		}
+
+		if fixEncoding {
+			nextURL = fixURLEncoding(nextURL)
+		}
+		currentURL = nextURL
+
+		// Rate limit between pages
+		logger.Info("Waiting before next page...")
+		time.Sleep(3 * time.Second)
+	}
+
+	// Print final stats
+	stats := storage.GetStats()
+	logger.Info("Collection completed",
+		"total_pages", pageCount,
+		"total_products", totalProducts,
+		"storage_stats", stats)
+	return nil
+}
+
+func extractProductLinks(page playwright.Page, logger *slog.Logger) []*storage.ProductLink {
+	var links []*storage.ProductLink
+
+	// Try multiple selectors for products
+	productSelectors := []string{
+		"[data-component-type='s-search-result']",
+		"div[data-asin]:not([data-asin=''])",
+		"[data-index]",
+		".s-result-item[data-asin]",
+	}
+
+	var products []playwright.Locator
+	for _, selector := range productSelectors {
+		found, err := page.Locator(selector).All()
+		if err == nil && len(found) > 0 {
+			logger.Info("Using product selector", "selector", selector, "count", len(found))
+			products = found
+			break
+		}
+	}
+
+	if len(products) == 0 {
+		logger.Error("No products found with any selector")
+		return links
+	}
+
+	for _, product := range products {
+		// Extract ASIN
+		asin, _ := product.GetAttribute("data-asin")
+		if asin == "" {
+			continue
+		}
+
+		// Extract title
+		var title string
+		titleSelectors := []string{
+			"h2 a span",
+			"h2 span",
+			".s-title-instructions-style span",
+			".a-size-base-plus",
+		}
+
+		for _, selector := range titleSelectors {
+			if elem := product.Locator(selector).First(); elem != nil {
+				if t, err := elem.TextContent(); err == nil && t != "" {
+					title = strings.TrimSpace(t)
+					break
+				}
+			}
+		}
+
+		// Extract URL
+		var url string
+		if linkElem := product.Locator("h2 a").First(); linkElem != nil {
+			if href, err := linkElem.GetAttribute("href"); err == nil && href != "" {
+				if strings.HasPrefix(href, "/") {
+					url = "https://www.amazon.de" + href
+				} else {
+					url = href
+				}
+			}
+		}
+
+		// Extract price
+		var price string
+		priceSelectors := []string{
+			".a-price-whole",
+			".a-price span",
+			".a-price",
+		}
+
+		for _, selector := range priceSelectors {
+			if elem := product.Locator(selector).First(); elem != nil {
+				if p, err := elem.TextContent(); err == nil && p != "" {
+					price = strings.TrimSpace(p)
+					break
+				}
+			}
+		}
+
+		link := &storage.ProductLink{
+			ASIN:  asin,
+			Title: title,
+			URL:   url,
+			Price: price,
+		}
+
+		links = append(links, link)
+	}
+
+	return links
+}
+
+func extractAlternativeProducts(page playwright.Page, logger *slog.Logger) []*storage.ProductLink {
+	var links []*storage.ProductLink
+
+	// Try alternative product container selectors
+	selectors := []string{
+		"[data-asin]:not([data-asin=''])",
+		".s-result-item[data-asin]",
+		".sg-col-inner [data-asin]",
+	}
+
+	for _, selector := range selectors {
+		products, err := page.Locator(selector).All()
+		if err != nil || len(products) == 0 {
+			continue
+		}
+
+		logger.Info("Found products with alternative selector", "selector", selector, "count", len(products))
+
+		for _, product := range products {
+			asin, _ := product.GetAttribute("data-asin")
+			if asin == "" {
+				continue
+			}
+
+			// Try to extract title from various locations
+			title := ""
+			titleSelectors := []string{
+				"h2",
+				".a-link-normal",
+				"[data-cy='title-recipe']",
+			}
+
+			for _, ts := range titleSelectors {
+				if elem := product.Locator(ts).First(); elem != nil {
+					if t, err := elem.TextContent(); err == nil && t != "" {
+						title = strings.TrimSpace(t)
+						break
+					}
+				}
+			}
+
+			link := &storage.ProductLink{
+				ASIN:  asin,
+				Title: title,
+				URL:   fmt.Sprintf("https://www.amazon.de/dp/%s", asin),
+			}
+
+			links = append(links, link)
+		}
+
+		if len(links) > 0 {
+			break
+		}
+	}
+
+	return links
+}
+
+func findNextPageURL(page playwright.Page, logger *slog.Logger) string {
+	// Multiple strategies to find next page
+	nextSelectors := []string{
+		".s-pagination-next:not(.s-pagination-disabled)",
+		"a.s-pagination-item.s-pagination-next",
+		"li.a-last a",
+		"span.s-pagination-strip a:has-text('Weiter')",
+		"a:has-text('Weiter')",
+	}
+
+	for _, selector := range nextSelectors {
+		elem := page.Locator(selector).First()
+		if count, _ := elem.Count(); count > 0 {
+			if href, err := elem.GetAttribute("href"); err == nil && href != "" {
+				logger.Info("Found next page", "selector", selector)
+				if strings.HasPrefix(href, "/") {
+					return "https://www.amazon.de" + href
+				}
+				return href
+			}
+		}
+	}
+
+	return ""
+}
+
+func (a *app) processLinks(ctx context.Context, storage *storage.LinkStorage, out sink.Sink) error {
+	logger := a.logger
+	// Show current stats
+	stats := storage.GetStats()
+	logger.Info("Processing links", "stats", stats)
+
+	pending := storage.GetPending()
+	if len(pending) == 0 {
+		logger.Info("No pending links to process")
+		return nil
+	}
+
+	logger.Info("Links to process", "count", len(pending))
+
+	b, err := a.newBrowser()
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+
+	p := parser.NewAmazonParser()
+	s := scraper.NewAmazonScraper(b, p, logger)
+
+	tracker := a.newProgress("process")
+	tracker.SetTotal(len(pending))
+	tracker.Start()
+	defer tracker.Stop()
+
+	// Process each link
+	for i, link := range pending {
+		select {
+		case <-ctx.Done():
+			logger.Info("Context cancelled, stopping processing")
+			return nil
+		default:
+		}
+
+		logger.Info("Processing product",
+			"progress", fmt.Sprintf("%d/%d", i+1, len(pending)),
+			"asin", link.ASIN,
+			"title", link.Title)
+
+		// Update status to processing
+		storage.UpdateStatus(link.ASIN, "processing", "")
+
+		// Scrape the product
+		product, err := s.ScrapeByASIN(ctx, link.ASIN)
+		if err != nil {
+			logger.Error("Failed to scrape product", "asin", link.ASIN, "error", err)
+			storage.UpdateStatus(link.ASIN, "failed", err.Error())
+			tracker.Done(err)
+			continue
+		}
+
+		// Check if we got dimensions
+		if product.Dimensions.IsValid() {
+			logger.Info("✓ Found dimensions",
+				"asin", link.ASIN,
+				"dimensions", fmt.Sprintf("%.1fx%.1fx%.1f %s",
+					product.Dimensions.Length,
+					product.Dimensions.Width,
+					product.Dimensions.Height,
+					product.Dimensions.Unit))
+
+			if err := out.Write(ctx, product); err != nil {
+				// Not marked completed, so undelivered products show up in the storage stats
+				logger.Error("Failed to write product to sinks", "asin", link.ASIN, "error", err)
+				storage.UpdateStatus(link.ASIN, "failed", err.Error())
+				tracker.Done(err)
+				continue
+			}
+			storage.UpdateStatus(link.ASIN, "completed", "")
+		} else {
+			logger.Warn("✗ No dimensions found", "asin", link.ASIN)
+			storage.UpdateStatus(link.ASIN, "completed", "no dimensions")
+		}
+		tracker.Done(nil)
+
+		// Rate limiting
+		time.Sleep(a.cfg.Scraper.RateLimitMin)
+	}
+
+	// Final stats
+	finalStats := storage.GetStats()
+	logger.Info("Processing completed", "stats", finalStats)
+	return nil
+}
+
+// splitList splits a comma separated list, dropping empty entries
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
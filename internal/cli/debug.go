@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/browser"
+	"github.com/playwright-community/playwright-go"
+	"github.com/spf13/cobra"
+)
+
+func newDebugCommand(a *app) *cobra.Command {
+	var pageURL, screenshot, htmlFile string
+
+	cmd := &cobra.Command{
+		Use:   "debug",
+		Short: "Open a page, save a screenshot and its HTML and report which product and captcha selectors match",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if pageURL == "" {
+				return fmt.Errorf("please provide a URL with --url")
+			}
+			return a.runDebug(cmd.Context(), pageURL, screenshot, htmlFile)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&pageURL, "url", "", "URL to debug")
+	flags.StringVar(&screenshot, "screenshot", "debug.png", "Screenshot filename")
+	flags.StringVar(&htmlFile, "html", "debug.html", "HTML output filename")
+	return cmd
+}
+
+func (a *app) runDebug(ctx context.Context, pageURL, screenshot, htmlFile string) error {
+	logger := a.logger
+	logger.Info("Starting Debug Mode")
+
+	b, err := a.newBrowser()
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+
+	page, err := b.NewPage()
+	if err != nil {
+		return fmt.Errorf("failed to create page: %w", err)
+	}
+	defer page.Close()
+
+	logger.Info("Navigating to URL", "url", pageURL)
+
+	if err := b.NavigateWithRetryContext(ctx, page, pageURL, 3); err != nil {
+		return fmt.Errorf("failed to navigate: %w", err)
+	}
+
+	// Wait for page to load
+	if err := browser.Sleep(ctx, 5*time.Second); err != nil {
+		return err
+	}
+
+	// Take screenshot
+	if _, err := page.Screenshot(playwright.PageScreenshotOptions{
+		Path:     playwright.String(screenshot),
+		FullPage: playwright.Bool(true),
+	}); err != nil {
+		logger.Error("Failed to take screenshot", "error", err)
+	} else {
+		logger.Info("Screenshot saved", "file", screenshot)
+	}
+
+	// Save HTML
+	content, err := page.Content()
+	if err != nil {
+		logger.Error("Failed to get content", "error", err)
+	} else {
+		if err := os.WriteFile(htmlFile, []byte(content), 0644); err != nil {
+			logger.Error("Failed to save HTML", "error", err)
+		} else {
+			logger.Info("HTML saved", "file", htmlFile)
+		}
+	}
+
+	// Try to find products with different selectors
+	selectors := []string{
+		"[data-component-type='s-search-result']",
+		"[data-asin]",
+		".s-result-item",
+		".sg-col-inner",
+		"div[data-index]",
+		".s-card-container",
+		".s-search-results",
+	}
+
+	for _, selector := range selectors {
+		count, _ := page.Locator(selector).Count()
+		if count > 0 {
+			logger.Info("Found elements", "selector", selector, "count", count)
+
+			// Get first few data-asin values
+			elements, _ := page.Locator(selector).All()
+			for i, elem := range elements {
+				if i >= 3 {
+					break
+				}
+
+				asin, _ := elem.GetAttribute("data-asin")
+				text, _ := elem.TextContent()
+				if asin != "" {
+					logger.Info("Sample element", "index", i, "asin", asin, "text_length", len(text))
+				}
+			}
+		}
+	}
+
+	// Check for captcha or blocks
+	captchaSelectors := []string{
+		"#captchacharacters",
+		"form[action*='Captcha']",
+		".a-box-inner:has-text('Robot')",
+		"img[src*='captcha']",
+	}
+
+	for _, selector := range captchaSelectors {
+		if count, _ := page.Locator(selector).Count(); count > 0 {
+			logger.Warn("Captcha detected!", "selector", selector)
+		}
+	}
+
+	// Check page title
+	title, _ := page.Title()
+	logger.Info("Page title", "title", title)
+
+	// Keep the browser open for inspection until interrupted
+	fmt.Println("\nPress Ctrl+C to exit...")
+	<-ctx.Done()
+	return nil
+}
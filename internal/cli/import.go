@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/jobs"
+	"github.com/maltedev/amazon-size-scraper/internal/asin"
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/maltedev/amazon-size-scraper/internal/importer"
+	"github.com/spf13/cobra"
+)
+
+func newImportCommand(a *app) *cobra.Command {
+	var (
+		source      string
+		marketplace string
+		createJob   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Create pending products from an ASIN list in a CSV file, Google Sheet or URL list",
+		Long: "Create pending products from an ASIN list. --file takes a CSV or text file with one ASIN or product " +
+			"URL per line, \"-\" for stdin, an http(s) URL or a Google Sheets link shared with anyone holding the link. " +
+			"Invalid entries are reported, ASINs already in the database are left untouched.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if source == "" {
+				return fmt.Errorf("please provide an ASIN list with --file")
+			}
+			return a.runImport(cmd.Context(), source, marketplace, createJob)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&source, "file", "", "ASIN list: file path, - for stdin, URL or Google Sheets link")
+	flags.StringVar(&marketplace, "marketplace", getEnv("SCRAPER_MARKETPLACE", jobs.DefaultMarketplace), "Amazon marketplace the product URLs point to")
+	flags.BoolVar(&createJob, "job", false, "Create an import job to track the scraping progress of the list")
+	return cmd
+}
+
+func (a *app) runImport(ctx context.Context, source, marketplace string, createJob bool) error {
+	logger := a.logger
+
+	body, err := importer.Open(ctx, source)
+	if err != nil {
+		return err
+	}
+	list, err := importer.Parse(body)
+	body.Close()
+	if err != nil {
+		return err
+	}
+	list.ResolveShortLinks(ctx, asin.NewResolver(10*time.Second))
+
+	for _, invalid := range list.Invalid {
+		logger.Warn("skipping invalid entry", "line", invalid.Line, "value", invalid.Value)
+	}
+	if len(list.ASINs) == 0 {
+		return fmt.Errorf("no valid ASINs in %s", source)
+	}
+
+	db, err := database.New(ctx, database.Config{
+		Host:        a.cfg.Database.Host,
+		Port:        a.cfg.Database.Port,
+		User:        a.cfg.Database.User,
+		Password:    a.cfg.Database.Password,
+		Database:    a.cfg.Database.DBName,
+		MaxConns:    2,
+		MinConns:    1,
+		MaxConnLife: 5 * time.Minute,
+		MaxConnIdle: 1 * time.Minute,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	manager := jobs.NewManager(db, nil, nil, logger)
+	job, result, err := manager.ImportProducts(ctx, source, marketplace, list.ASINs, createJob)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported %d ASINs: %d new, %d already known, %d duplicates, %d invalid\n",
+		len(list.ASINs), result.Created, result.Existing, list.Duplicates, len(list.Invalid))
+	if job != nil {
+		fmt.Printf("Import job: %s\n", job.ID)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"os"
+	"strings"
+)
+
+// ExecuteLegacy runs the subcommand that replaced a retired binary with that binary's defaults,
+// so scripts calling e.g. "crawler -mode process -storage products.json" keep working
+func ExecuteLegacy(binary string, args []string) int {
+	return Execute(legacyArgs(binary, args))
+}
+
+// legacyArgs maps the arguments of a retired binary to the equivalent subcommand invocation.
+// Defaults that differed from the CLI come first so explicitly passed flags still win.
+func legacyArgs(binary string, args []string) []string {
+	args = doubleDash(args)
+
+	switch binary {
+	case "scraper":
+		return prepend(args, "product")
+	case "crawler":
+		mode, args := takeFlag(args, "mode", "collect")
+		if mode == "collect" {
+			mode = "crawl"
+		}
+		return prepend(args, mode)
+	case "crawler-fixed":
+		return prepend(args, "crawl", "--fix-encoding", "--storage", "products-fixed.json", "--pages", "5", "--headless=false")
+	case "search":
+		return prepend(args, "search")
+	case "size-scraper":
+		if headless := os.Getenv("HEADLESS"); headless != "" {
+			args = prepend(args, "--headless="+headless)
+		}
+		return prepend(args, "sizes")
+	case "debug":
+		return prepend(args, "debug", "--headless=false")
+	case "camoufox":
+		mode, args := takeFlag(args, "mode", "collect")
+		return prepend(args, "camoufox", mode, "--headless=false")
+	case "amazon-scraper":
+		return prepend(args, "serve")
+	}
+	return args
+}
+
+// isLegacyFlag reports whether arg is a Go flag style long flag like "-asins", which pflag would read as shorthands
+func isLegacyFlag(arg string) bool {
+	return len(arg) > 2 && arg[0] == '-' && arg[1] != '-' && (arg[1] >= 'a' && arg[1] <= 'z' || arg[1] >= 'A' && arg[1] <= 'Z')
+}
+
+// doubleDash rewrites Go flag style "-name" and "-name=value" arguments to "--name"
+func doubleDash(args []string) []string {
+	out := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "--" {
+			copy(out[i:], args[i:])
+			break
+		}
+		if isLegacyFlag(arg) {
+			arg = "-" + arg
+		}
+		out[i] = arg
+	}
+	return out
+}
+
+// takeFlag removes --name from args and returns its value, or def when it is not set
+func takeFlag(args []string, name, def string) (string, []string) {
+	value := def
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		switch {
+		case strings.HasPrefix(args[i], "--"+name+"="):
+			value = strings.TrimPrefix(args[i], "--"+name+"=")
+		case args[i] == "--"+name && i+1 < len(args):
+			value = args[i+1]
+			i++
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+	return value, rest
+}
+
+func prepend(args []string, first ...string) []string {
+	return append(first, args...)
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLegacyArgs(t *testing.T) {
+	tests := []struct {
+		binary string
+		args   string
+		want   string
+	}{
+		{"scraper", "-asins B08N5WRWNW -output=json", "product --asins B08N5WRWNW --output=json"},
+		{"crawler", "-url https://www.amazon.de/s?k=x -pages 2", "crawl --url https://www.amazon.de/s?k=x --pages 2"},
+		{"crawler", "-mode process -storage links.json", "process --storage links.json"},
+		{"crawler", "-mode=process", "process"},
+		{"crawler-fixed", "-url u -pages 2", "crawl --fix-encoding --storage products-fixed.json --pages 5 --headless=false --url u --pages 2"},
+		{"camoufox", "-mode test -headless=true", "camoufox test --headless=false --headless=true"},
+		{"camoufox", "-url u", "camoufox collect --headless=false --url u"},
+		{"amazon-scraper", "", "serve"},
+		{"search", "--url u -h", "search --url u -h"},
+	}
+
+	for _, tt := range tests {
+		got := strings.Join(legacyArgs(tt.binary, strings.Fields(tt.args)), " ")
+		if got != tt.want {
+			t.Errorf("legacyArgs(%q, %q) = %q, want %q", tt.binary, tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestDoubleDash(t *testing.T) {
+	got := strings.Join(doubleDash([]string{"-search", "u", "-h", "-1", "--db-port", "5433", "--", "-notaflag"}), " ")
+	if want := "--search u -h -1 --db-port 5433 -- -notaflag"; got != want {
+		t.Errorf("doubleDash() = %q, want %q", got, want)
+	}
+}
+
+func TestRootCommand(t *testing.T) {
+	root := NewRootCommand()
+	for _, name := range []string{"product", "crawl", "process", "search", "sizes", "debug", "camoufox", "serve", "migrate-legacy"} {
+		cmd, _, err := root.Find([]string{name})
+		if err != nil || cmd.Name() != name {
+			t.Errorf("missing subcommand %q", name)
+		}
+	}
+
+	// Every retired binary maps to flags its subcommand knows
+	for _, binary := range []string{"scraper", "crawler", "crawler-fixed", "search", "size-scraper", "debug", "camoufox", "amazon-scraper"} {
+		args := legacyArgs(binary, nil)
+		cmd, flags, err := NewRootCommand().Find(args)
+		if err != nil {
+			t.Errorf("%s: %v", binary, err)
+			continue
+		}
+		if err := cmd.ParseFlags(flags); err != nil {
+			t.Errorf("%s: %v", binary, err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/config"
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/events"
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/jobs"
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/maltedev/amazon-size-scraper/internal/eventroute"
+	"github.com/maltedev/amazon-size-scraper/internal/labels"
+	"github.com/spf13/cobra"
+)
+
+func newMigrateLegacyCommand(a *app) *cobra.Command {
+	var opts jobs.LegacyMigrationOptions
+
+	cmd := &cobra.Command{
+		Use:   "migrate-legacy",
+		Short: "Convert product rows of the deprecated layout to the lifecycle schema",
+		Long: "Converts products stored by the retired size scraper, a size table without validation or content hash and " +
+			"material in its own columns, to the lifecycle schema: canonical measurement keys, validation report and " +
+			"quality score, normalized measurements and size conversions, the material attribute and a content hash. " +
+			"Batches are committed with a watermark in data_migrations, so an interrupted run continues where it stopped " +
+			"and a finished one finds nothing left to convert. Database, label and validation settings are read from " +
+			"the environment like serve.",
+		Example: "  scraper migrate-legacy --dry-run\n" +
+			"  scraper migrate-legacy --events --batch-size 200 --throttle 2s",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := a.runMigrateLegacy(cmd.Context(), opts)
+			if result != nil {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				enc.Encode(result)
+			}
+			return err
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.IntVar(&opts.BatchSize, "batch-size", jobs.DefaultLegacyBatchSize, "Products converted per transaction")
+	flags.DurationVar(&opts.Throttle, "throttle", time.Second, "Pause between batches")
+	flags.IntVar(&opts.Limit, "limit", 0, "Stop after this many products (0 is unlimited)")
+	flags.BoolVar(&opts.Events, "events", false, "Insert a NEW_PRODUCT_DETECTED outbox event for each converted product")
+	flags.BoolVar(&opts.DryRun, "dry-run", false, "Convert and count legacy products without writing")
+	flags.BoolVar(&opts.Restart, "restart", false, "Ignore the watermark of earlier runs, e.g. to retry skipped products")
+	return cmd
+}
+
+func (a *app) runMigrateLegacy(ctx context.Context, opts jobs.LegacyMigrationOptions) (*jobs.LegacyMigrationResult, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	opts.Labels, err = labels.Load(cfg.Scraper.Marketplace, cfg.Scraper.LabelsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load size table labels: %w", err)
+	}
+	if cfg.Scraper.ValidationFile != "" {
+		validationCfg, err := database.LoadValidationConfig(cfg.Scraper.ValidationFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load size table validation rules: %w", err)
+		}
+		opts.Validator = database.NewSizeTableValidatorFromConfig(validationCfg)
+	}
+
+	db, err := database.New(ctx, serveDBConfig(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	// Events go to the targets serve routes them to
+	routes, err := eventroute.ParseTable(cfg.Events.Routes, cfg.Events.DefaultTarget)
+	if err != nil {
+		return nil, fmt.Errorf("invalid event routes: %w", err)
+	}
+	publisher := events.NewPublisher(db, a.logger)
+	publisher.SetRoutes(routes)
+
+	manager := jobs.NewManager(db, nil, publisher, a.logger)
+	return manager.MigrateLegacyProducts(ctx, opts)
+}
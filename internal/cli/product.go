@@ -0,0 +1,279 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/asin"
+	"github.com/maltedev/amazon-size-scraper/internal/models"
+	"github.com/maltedev/amazon-size-scraper/internal/parser"
+	"github.com/maltedev/amazon-size-scraper/internal/queue"
+	"github.com/maltedev/amazon-size-scraper/internal/ratelimit"
+	"github.com/maltedev/amazon-size-scraper/internal/redisconn"
+	"github.com/maltedev/amazon-size-scraper/internal/scraper"
+	"github.com/spf13/cobra"
+)
+
+// productQueueOptions selects the task queue of the product command
+type productQueueOptions struct {
+	backend     string // memory or redis
+	name        string
+	redis       redisconn.Config
+	redisAddr   string
+	redisOpts   queue.RedisOptions
+	enqueueOnly bool
+}
+
+func newProductCommand(a *app) *cobra.Command {
+	var urls, asins, inputFile, output, marketplace string
+	qopts := productQueueOptions{redis: redisconn.FromEnv(), redisOpts: queue.DefaultRedisOptions()}
+
+	cmd := &cobra.Command{
+		Use:   "product",
+		Short: "Scrape product pages by URL or ASIN",
+		Example: "  scraper product --asins B08N5WRWNW,B08N5LGQNG --output csv\n  scraper product --file urls.txt\n" +
+			"  scraper product --urls 'https://www.amazon.co.uk/gp/product/B08N5WRWNW?ref_=abc'\n" +
+			"  scraper product --queue redis --file urls.txt --enqueue-only\n  scraper product --queue redis --queue-wait 1m",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return a.runProduct(cmd, urls, asins, inputFile, output, marketplace, qopts)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&urls, "urls", "", "Comma-separated list of Amazon product URLs to scrape")
+	flags.StringVar(&asins, "asins", "", "Comma-separated list of Amazon ASINs to scrape")
+	flags.StringVar(&inputFile, "file", "", "File containing URLs or ASINs (one per line)")
+	flags.StringVar(&output, "output", "stdout", "Output format: stdout, json, csv")
+	flags.StringVar(&marketplace, "marketplace", getEnv("SCRAPER_MARKETPLACE", asin.DefaultMarketplace), "Marketplace of ASINs and links that do not name one, URLs of other marketplaces are scraped there")
+	flags.StringVar(&qopts.backend, "queue", getEnv("SCRAPER_QUEUE", "memory"), "Task queue: memory, or redis to keep tasks across restarts and share them between processes")
+	flags.StringVar(&qopts.name, "queue-name", "products", "Name of the redis queue, processes using the same name share its tasks")
+	flags.StringVar(&qopts.redisAddr, "redis-addr", getEnv("REDIS_ADDR", "localhost:6379"), "Redis address of the redis queue, comma separated for Sentinel and Cluster (REDIS_MODE)")
+	flags.DurationVar(&qopts.redisOpts.VisibilityTimeout, "queue-visibility", qopts.redisOpts.VisibilityTimeout, "Redeliver redis tasks not finished within this time, e.g. after a crash")
+	flags.IntVar(&qopts.redisOpts.MaxRetries, "queue-max-retries", qopts.redisOpts.MaxRetries, "Redeliveries of a redis task before it moves to the dead-letter list")
+	flags.DurationVar(&qopts.redisOpts.Wait, "queue-wait", 0, "Keep waiting this long for tasks pushed by other processes once the redis queue is empty")
+	flags.BoolVar(&qopts.enqueueOnly, "enqueue-only", false, "Push the given products to the redis queue and exit without scraping")
+	return cmd
+}
+
+// openProductQueue creates the task queue and a cleanup closing it
+func openProductQueue(opts productQueueOptions) (queue.Queue, func(), error) {
+	switch opts.backend {
+	case "memory":
+		if opts.enqueueOnly {
+			return nil, nil, fmt.Errorf("--enqueue-only requires --queue redis")
+		}
+		q := queue.NewInMemoryQueue()
+		return q, func() { q.Close() }, nil
+	case "redis":
+		opts.redis.Addrs = redisconn.SplitAddrs(opts.redisAddr)
+		rdb, err := redisconn.New(opts.redis)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid redis config: %w", err)
+		}
+		q := queue.NewRedisQueue(rdb, opts.name, opts.redisOpts)
+		return q, func() { q.Close(); rdb.Close() }, nil
+	}
+	return nil, nil, fmt.Errorf("unknown queue %q, use memory or redis", opts.backend)
+}
+
+func (a *app) runProduct(cmd *cobra.Command, urls, asins, inputFile, output, marketplace string, qopts productQueueOptions) error {
+	ctx := cmd.Context()
+	logger := a.logger
+	logger.Info("Starting Amazon Size Scraper", "queue", qopts.backend)
+
+	taskQueue, closeQueue, err := openProductQueue(qopts)
+	if err != nil {
+		return err
+	}
+	defer closeQueue()
+	acker, _ := taskQueue.(queue.Acker)
+
+	if err := a.loadTasks(ctx, taskQueue, urls, asins, inputFile, marketplace); err != nil {
+		return fmt.Errorf("failed to load tasks: %w", err)
+	}
+	if qopts.enqueueOnly {
+		logger.Info("Tasks queued", "queue", qopts.name, "size", taskQueue.Size())
+		return nil
+	}
+
+	if taskQueue.Size() == 0 && qopts.redisOpts.Wait == 0 {
+		cmd.Usage()
+		return fmt.Errorf("no tasks to process, use --urls, --asins or --file to specify products to scrape")
+	}
+
+	b, err := a.newBrowser()
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+
+	p := parser.NewAmazonParser()
+	s := scraper.NewAmazonScraper(b, p, logger)
+	s.SetMarketplace(marketplace)
+
+	rateLimiter := ratelimit.NewAdaptiveRateLimiter(
+		a.cfg.Scraper.RateLimitMin,
+		a.cfg.Scraper.RateLimitMax,
+	)
+
+	logger.Info("Starting scraping", "tasks", taskQueue.Size())
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Context cancelled, exiting")
+			return nil
+		default:
+		}
+
+		task, err := taskQueue.Pop(ctx)
+		if err != nil {
+			if err == queue.ErrQueueEmpty || err == queue.ErrQueueClosed {
+				logger.Info("Queue empty, finishing")
+				break
+			}
+			logger.Error("Failed to get task from queue", "error", err)
+			continue
+		}
+
+		if err := rateLimiter.Wait(ctx); err != nil {
+			logger.Error("Rate limiter error", "error", err)
+			continue
+		}
+
+		logger.Info("Processing task", "url", task.URL, "asin", task.ASIN)
+
+		var product *models.Product
+		if task.URL != "" {
+			product, err = s.ScrapeByURL(ctx, task.URL)
+		} else {
+			product, err = s.ScrapeByASIN(ctx, task.ASIN)
+		}
+		if err != nil {
+			logger.Error("Failed to scrape product", "asin", task.ASIN, "error", err)
+			rateLimiter.RecordError()
+
+			if task.Retries < a.cfg.Scraper.MaxRetries {
+				task.Retries++
+				taskQueue.Push(task)
+				logger.Info("Retrying task", "asin", task.ASIN, "retry", task.Retries)
+			} else if acker != nil {
+				if err := acker.DeadLetter(task, err.Error()); err != nil {
+					logger.Error("Failed to dead-letter task", "asin", task.ASIN, "error", err)
+				}
+			}
+			continue
+		}
+
+		rateLimiter.RecordSuccess()
+		if acker != nil {
+			if err := acker.Ack(task); err != nil {
+				logger.Error("Failed to acknowledge task", "asin", task.ASIN, "error", err)
+			}
+		}
+
+		if err := outputResult(product, output); err != nil {
+			logger.Error("Failed to output result", "error", err)
+		}
+	}
+
+	logger.Info("Scraping completed")
+	return nil
+}
+
+// loadTasks queues the products of urls, asinList and inputFile with their canonical URL. Entries may
+// be ASINs or any product URL form including amzn.to short links, entries without a marketplace get
+// marketplace. Invalid entries are logged and skipped.
+func (a *app) loadTasks(ctx context.Context, q queue.Queue, urls, asinList, inputFile, marketplace string) error {
+	var taskList []string
+
+	if urls != "" {
+		taskList = append(taskList, strings.Split(urls, ",")...)
+	}
+
+	if asinList != "" {
+		taskList = append(taskList, strings.Split(asinList, ",")...)
+	}
+
+	if inputFile != "" {
+		data, err := os.ReadFile(inputFile)
+		if err != nil {
+			return fmt.Errorf("failed to read input file: %w", err)
+		}
+		lines := strings.Split(string(data), "\n")
+		for _, line := range lines {
+			line = strings.TrimSpace(line)
+			if line != "" && !strings.HasPrefix(line, "#") {
+				taskList = append(taskList, line)
+			}
+		}
+	}
+
+	resolver := asin.NewResolver(10 * time.Second)
+	seen := make(map[asin.Product]bool)
+	for _, item := range taskList {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		product, err := resolver.ResolveProduct(ctx, item, marketplace)
+		if err != nil {
+			a.logger.Warn("Skipping invalid task", "value", item, "error", err)
+			continue
+		}
+		if seen[product] {
+			continue
+		}
+		seen[product] = true
+
+		err = q.Push(&queue.Task{
+			ID:        product.String(), // Pushing a queued product again replaces its task
+			URL:       product.URL(),
+			ASIN:      product.ASIN,
+			Priority:  1,
+			CreatedAt: time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func outputResult(product *models.Product, format string) error {
+	switch format {
+	case "json":
+		// Implementation for JSON output
+		fmt.Printf("%+v\n", product)
+	case "csv":
+		// Implementation for CSV output
+		fmt.Printf("%s,%s,%.2fx%.2fx%.2f %s,%.2f %s\n",
+			product.ASIN,
+			product.Title,
+			product.Dimensions.Length,
+			product.Dimensions.Width,
+			product.Dimensions.Height,
+			product.Dimensions.Unit,
+			product.Weight.Value,
+			product.Weight.Unit,
+		)
+	default:
+		fmt.Printf("Product: %s\n", product.Title)
+		fmt.Printf("ASIN: %s\n", product.ASIN)
+		fmt.Printf("Dimensions: %.2f x %.2f x %.2f %s\n",
+			product.Dimensions.Length,
+			product.Dimensions.Width,
+			product.Dimensions.Height,
+			product.Dimensions.Unit,
+		)
+		fmt.Printf("Weight: %.2f %s\n", product.Weight.Value, product.Weight.Unit)
+		fmt.Printf("Price: %.2f %s\n", product.Price.Amount, product.Price.Currency)
+		fmt.Println("---")
+	}
+	return nil
+}
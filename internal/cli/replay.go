@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/config"
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/maltedev/amazon-size-scraper/internal/eventroute"
+	"github.com/maltedev/amazon-size-scraper/internal/redisconn"
+	"github.com/spf13/cobra"
+)
+
+func newReplayCommand(a *app) *cobra.Command {
+	var (
+		eventType, target string
+		aggregateIDs      []string
+		since, until      string
+		limit             int
+		dryRun, all       bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "replay",
+		Short: "Publish processed outbox events again, e.g. for a consumer that lost data",
+		Long: "Publishes the processed outbox events matching the filters again, oldest first, in the configured schema version " +
+			"and with the replayed metadata flag. Events go to --target or to their original target and stay processed. " +
+			"Database, Redis and event settings are read from the environment like serve.",
+		Example: "  scraper replay --event-type NEW_PRODUCT_DETECTED --since 2024-05-01T00:00:00Z --target redis:stream:rebuild\n" +
+			"  scraper replay --aggregate-ids B08N5WRWNW,B08N5LGQNG --dry-run",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := database.ReplayOptions{
+				Filter: database.OutboxFilter{EventType: eventType, AggregateIDs: aggregateIDs},
+				Target: target,
+				Limit:  limit,
+				DryRun: dryRun,
+			}
+			for _, bound := range []struct {
+				value  string
+				target *time.Time
+			}{{since, &opts.Filter.CreatedAfter}, {until, &opts.Filter.CreatedBefore}} {
+				if bound.value == "" {
+					continue
+				}
+				t, err := time.Parse(time.RFC3339, bound.value)
+				if err != nil {
+					return fmt.Errorf("--since and --until must be RFC 3339 timestamps: %w", err)
+				}
+				*bound.target = t
+			}
+			if !all && eventType == "" && len(aggregateIDs) == 0 && since == "" && until == "" {
+				return fmt.Errorf("please filter with --event-type, --aggregate-ids, --since or --until, or replay everything with --all")
+			}
+
+			result, err := a.runReplay(cmd.Context(), opts)
+			if result != nil {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				enc.Encode(result)
+			}
+			if err != nil {
+				return err
+			}
+			if result.Failed > 0 {
+				return fmt.Errorf("%d of %d events failed to publish", result.Failed, result.Matched)
+			}
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&eventType, "event-type", "", "Only replay events of this type, e.g. NEW_PRODUCT_DETECTED")
+	flags.StringSliceVar(&aggregateIDs, "aggregate-ids", nil, "Only replay events of these aggregates, e.g. ASINs or job IDs")
+	flags.StringVar(&since, "since", "", "Only replay events created at or after this RFC 3339 time")
+	flags.StringVar(&until, "until", "", "Only replay events created before this RFC 3339 time")
+	flags.StringVar(&target, "target", "", "Publish to this target instead of each event's original one, e.g. redis:stream:rebuild, kafka:topic or webhook:https://...")
+	flags.IntVar(&limit, "limit", 0, "Stop after this many events, 0 replays all that match")
+	flags.BoolVar(&dryRun, "dry-run", false, "Only count the matching events")
+	flags.BoolVar(&all, "all", false, "Replay every processed event when no filter is given")
+	return cmd
+}
+
+func (a *app) runReplay(ctx context.Context, opts database.ReplayOptions) (*database.ReplayResult, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	db, err := database.New(ctx, serveDBConfig(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	redisClient, err := redisconn.New(cfg.Redis.Conn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis config: %w", err)
+	}
+	defer redisClient.Close()
+	if err := redisClient.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+
+	// Only the encoding settings of serve's relay apply, a replay injects no faults and needs no leadership
+	relay := database.NewRelay(db, redisClient, a.logger, database.RelayConfig{
+		SchemaVersion: cfg.Events.SchemaVersion,
+		MaxStreamLen:  cfg.Redis.StreamMaxLen,
+
+		PayloadEncoding:   cfg.Events.PayloadCompression,
+		CompressThreshold: cfg.Events.CompressThreshold,
+		MaxPayloadSize:    cfg.Events.MaxPayloadSize,
+
+		Dispatcher: eventroute.NewHTTPDispatcher(cfg.Events.KafkaRESTURL, time.Duration(cfg.Events.WebhookTimeout)*time.Second),
+	})
+	return relay.Replay(ctx, opts)
+}
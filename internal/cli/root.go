@@ -0,0 +1,150 @@
+// Package cli implements the scraper command line. Every workflow that used to be its own
+// binary under cmd/ is a subcommand sharing config loading, logging, signals and browser setup.
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/maltedev/amazon-size-scraper/internal/browser"
+	"github.com/maltedev/amazon-size-scraper/internal/config"
+	"github.com/maltedev/amazon-size-scraper/internal/progress"
+	"github.com/maltedev/amazon-size-scraper/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+// app holds the global config and flags shared by all subcommands
+type app struct {
+	cfg       *config.Config
+	logger    *slog.Logger
+	headless  bool
+	logLevel  string
+	logFormat string
+	progress  bool
+}
+
+// NewRootCommand builds the scraper command tree
+func NewRootCommand() *cobra.Command {
+	a := &app{}
+
+	root := &cobra.Command{
+		Use:           "scraper",
+		Short:         "Amazon size scraper",
+		Long:          "Crawls Amazon search results and extracts product size tables. Configuration is read from the environment, flags override it.",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return a.load(cmd)
+		},
+	}
+
+	flags := root.PersistentFlags()
+	flags.BoolVar(&a.headless, "headless", true, "Run the browser in headless mode, overrides BROWSER_HEADLESS")
+	flags.StringVar(&a.logLevel, "log-level", "", "Log level: debug, info, warn or error (default from LOG_LEVEL)")
+	flags.StringVar(&a.logFormat, "log-format", "", "Log format: json or text (default from LOG_FORMAT)")
+	flags.BoolVar(&a.progress, "progress", getEnvBool("SCRAPER_PROGRESS", true), "Show a progress line on a terminal and a summary table after crawls")
+
+	root.AddCommand(
+		newProductCommand(a),
+		newCrawlCommand(a),
+		newProcessCommand(a),
+		newSearchCommand(a),
+		newSizesCommand(a),
+		newImportCommand(a),
+		newBackfillCommand(a),
+		newDebugCommand(a),
+		newBenchCommand(a),
+		newCamoufoxCommand(a),
+		newServeCommand(a),
+		newCheckCommand(a),
+		newReplayCommand(a),
+		newMigrateLegacyCommand(a),
+		newAnalyzeCommand(a),
+	)
+	return root
+}
+
+// Execute runs the scraper CLI and returns the process exit code. Arguments in the Go flag style of
+// the former scraper binary, e.g. "-asins B08N5WRWNW", run the product command.
+func Execute(args []string) int {
+	if len(args) > 0 && isLegacyFlag(args[0]) {
+		args = legacyArgs("scraper", args)
+	}
+	// Go flag style long flags work on every command, e.g. "scraper import -file asins.csv"
+	args = doubleDash(args)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	root := NewRootCommand()
+	root.SetArgs(args)
+	if err := root.ExecuteContext(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	return 0
+}
+
+// load reads the environment config and applies the global flags
+func (a *app) load(cmd *cobra.Command) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	if !cmd.Flags().Changed("headless") {
+		a.headless = cfg.Browser.Headless
+	}
+	if a.logLevel != "" {
+		cfg.Logging.Level = a.logLevel
+	}
+	if a.logFormat != "" {
+		cfg.Logging.Format = a.logFormat
+	}
+
+	a.cfg = cfg
+	a.logger = logger.New(cfg.Logging.Level, cfg.Logging.Format)
+	slog.SetDefault(a.logger)
+	return nil
+}
+
+// newProgress creates a progress tracker on stderr, nil when disabled with --progress=false
+func (a *app) newProgress(label string) *progress.Tracker {
+	if !a.progress {
+		return nil
+	}
+	return progress.New(label, os.Stderr)
+}
+
+// browserOptions builds browser options from the shared config and the --headless flag
+func (a *app) browserOptions() *browser.Options {
+	opts := &browser.Options{
+		Headless:       a.headless,
+		Timeout:        a.cfg.Browser.Timeout,
+		ViewportWidth:  a.cfg.Browser.ViewportWidth,
+		ViewportHeight: a.cfg.Browser.ViewportHeight,
+		AcceptLanguage: a.cfg.Browser.AcceptLanguage,
+		TimezoneID:     a.cfg.Browser.TimezoneID,
+		Locale:         a.cfg.Browser.Locale,
+	}
+	if len(a.cfg.Scraper.UserAgents) > 0 {
+		opts.UserAgent = a.cfg.Scraper.UserAgents[0]
+	}
+	return opts
+}
+
+// newBrowser launches a browser with the shared options
+func (a *app) newBrowser() (*browser.Browser, error) {
+	b, err := browser.New(a.browserOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize browser: %w", err)
+	}
+	return b, nil
+}
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+
+	"github.com/maltedev/amazon-size-scraper/internal/parser"
+	"github.com/maltedev/amazon-size-scraper/internal/scraper"
+	"github.com/spf13/cobra"
+)
+
+func newSearchCommand(a *app) *cobra.Command {
+	var (
+		searchURL   string
+		outputFile  string
+		maxPages    int
+		scrapeItems bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "search",
+		Short: "Print the products of Amazon search result pages, optionally scraping each product",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if searchURL == "" {
+				return fmt.Errorf("please provide a search URL with --url")
+			}
+			return a.runSearch(cmd.Context(), searchURL, outputFile, maxPages, scrapeItems)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&searchURL, "url", "", "Amazon search URL")
+	flags.StringVar(&outputFile, "output", "", "Output CSV file (optional)")
+	flags.IntVar(&maxPages, "pages", 1, "Maximum number of pages to scrape")
+	flags.BoolVar(&scrapeItems, "scrape", false, "Also scrape individual product pages for dimensions")
+	return cmd
+}
+
+func (a *app) runSearch(ctx context.Context, searchURL, outputFile string, maxPages int, scrapeItems bool) error {
+	logger := a.logger
+	logger.Info("Starting Amazon Search Scraper")
+
+	b, err := a.newBrowser()
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+
+	p := parser.NewAmazonParser()
+	searchScraper := scraper.NewSearchScraper(b, p, logger)
+	productScraper := scraper.NewAmazonScraper(b, p, logger)
+
+	var allResults []scraper.SearchResult
+	currentURL := searchURL
+
+	for page := 1; page <= maxPages && currentURL != ""; page++ {
+		logger.Info("Scraping page", "page", page, "url", currentURL)
+
+		results, err := searchScraper.ScrapeSearchResults(ctx, currentURL)
+		if err != nil {
+			logger.Error("Failed to scrape search results", "error", err, "page", page)
+			break
+		}
+
+		logger.Info("Found products on page", "count", len(results), "page", page)
+		allResults = append(allResults, results...)
+
+		// Print results
+		for _, result := range results {
+			fmt.Printf("ASIN: %s\n", result.ASIN)
+			fmt.Printf("Title: %s\n", result.Title)
+			fmt.Printf("Price: %s\n", result.Price)
+			fmt.Printf("URL: %s\n", result.URL)
+			if result.HasTable {
+				fmt.Println("➜ Might have size table!")
+			}
+
+			// Optionally scrape the product page for dimensions
+			if scrapeItems && result.ASIN != "" {
+				fmt.Println("  Scraping product details...")
+				product, err := productScraper.ScrapeByASIN(ctx, result.ASIN)
+				if err != nil {
+					logger.Error("Failed to scrape product", "asin", result.ASIN, "error", err)
+				} else if product.Dimensions.IsValid() {
+					fmt.Printf("  ✓ Dimensions: %.1f x %.1f x %.1f %s\n",
+						product.Dimensions.Length,
+						product.Dimensions.Width,
+						product.Dimensions.Height,
+						product.Dimensions.Unit)
+				} else {
+					fmt.Println("  ✗ No dimensions found")
+				}
+			}
+			fmt.Println("---")
+		}
+
+		if page < maxPages {
+			// Try to get next page URL
+			newPage, err := b.NewPage()
+			if err != nil {
+				logger.Error("Failed to create page for navigation", "error", err)
+				break
+			}
+
+			if err := b.NavigateWithRetry(newPage, currentURL, 3); err != nil {
+				logger.Error("Failed to navigate for next page", "error", err)
+				newPage.Close()
+				break
+			}
+
+			nextURL, err := searchScraper.GetNextPageURL(newPage)
+			newPage.Close()
+
+			if err != nil || nextURL == "" {
+				logger.Info("No more pages available")
+				break
+			}
+
+			currentURL = nextURL
+		}
+	}
+
+	logger.Info("Total products found", "count", len(allResults))
+
+	// Save to CSV if requested
+	if outputFile != "" {
+		if err := saveToCSV(allResults, outputFile); err != nil {
+			logger.Error("Failed to save CSV", "error", err)
+		} else {
+			logger.Info("Results saved to CSV", "file", outputFile)
+		}
+	}
+	return nil
+}
+
+func saveToCSV(results []scraper.SearchResult, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	// Write header
+	if err := writer.Write([]string{"ASIN", "Title", "URL", "Price", "HasSizeInfo"}); err != nil {
+		return err
+	}
+
+	// Write data
+	for _, result := range results {
+		record := []string{
+			result.ASIN,
+			result.Title,
+			result.URL,
+			result.Price,
+			fmt.Sprintf("%v", result.HasTable),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,626 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/cors"
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/api"
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/config"
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/control"
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/events"
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/jobs"
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/scraper"
+	"github.com/maltedev/amazon-size-scraper/internal/browser"
+	"github.com/maltedev/amazon-size-scraper/internal/chaos"
+	"github.com/maltedev/amazon-size-scraper/internal/currency"
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/maltedev/amazon-size-scraper/internal/eventroute"
+	"github.com/maltedev/amazon-size-scraper/internal/labels"
+	"github.com/maltedev/amazon-size-scraper/internal/llm"
+	"github.com/maltedev/amazon-size-scraper/internal/ocr"
+	"github.com/maltedev/amazon-size-scraper/internal/paapi"
+	"github.com/maltedev/amazon-size-scraper/internal/quota"
+	"github.com/maltedev/amazon-size-scraper/internal/redisconn"
+	"github.com/maltedev/amazon-size-scraper/internal/reviewsummary"
+	"github.com/maltedev/amazon-size-scraper/internal/schedule"
+	"github.com/maltedev/amazon-size-scraper/internal/schema"
+	"github.com/maltedev/amazon-size-scraper/internal/signedurl"
+	"github.com/maltedev/amazon-size-scraper/internal/sizecache"
+	"github.com/maltedev/amazon-size-scraper/internal/stages"
+	logging "github.com/maltedev/amazon-size-scraper/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+func newServeCommand(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Run the scraper HTTP API, job worker and outbox relay",
+		Long:  "Runs the Oxylabs replacement API. It is configured through the environment variables documented in README.scraper.md.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runServe(cmd.Context(), a.logger)
+		},
+	}
+}
+
+func runServe(ctx context.Context, logger *slog.Logger) error {
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Setup context with cancellation
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Database connection
+	db, err := database.New(ctx, serveDBConfig(cfg))
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	// Browser setup
+	browserOpts, err := serveBrowserOptions(cfg)
+	if err != nil {
+		return err
+	}
+	b, err := browser.New(browserOpts)
+	if err != nil {
+		return fmt.Errorf("failed to initialize browser: %w", err)
+	}
+	defer b.Close()
+
+	// Initialize event publisher with database (for transactional outbox)
+	publisher := events.NewPublisher(db, logger)
+
+	// Event types are routed to Redis streams, Kafka topics or webhooks, checked before anything is published
+	routes, err := eventroute.ParseTable(cfg.Events.Routes, cfg.Events.DefaultTarget)
+	if err != nil {
+		return fmt.Errorf("invalid event routes: %w", err)
+	}
+	if err := routes.Validate(schema.NewRegistry().Known, cfg.Events.KafkaRESTURL != ""); err != nil {
+		return fmt.Errorf("invalid event routes: %w", err)
+	}
+	publisher.SetRoutes(routes)
+	logger.Info("event routes configured", "routes", routes.Routes())
+
+	// Initialize Redis client for Relay
+	redisClient, err := redisconn.New(cfg.Redis.Conn)
+	if err != nil {
+		return fmt.Errorf("invalid Redis config: %w", err)
+	}
+	defer redisClient.Close()
+
+	// Test Redis connection
+	if err := redisClient.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+	logger.Info("connected to Redis", "redis", cfg.Redis.Conn.String())
+
+	// Fault injection lets consumers be tested against failed, delayed and duplicated events
+	var faults *chaos.Injector
+	if cfg.Chaos.Enabled {
+		seed := cfg.Chaos.Seed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		faults, err = chaos.New(chaos.Config{
+			PublishFailureRate: cfg.Chaos.PublishFailureRate,
+			DuplicateRate:      cfg.Chaos.DuplicateRate,
+			MaxDelay:           time.Duration(cfg.Chaos.MaxDelayMillis) * time.Millisecond,
+		}, seed)
+		if err != nil {
+			return fmt.Errorf("invalid chaos config: %w", err)
+		}
+		logger.Warn("chaos mode enabled, injecting faults into the event pipeline",
+			"environment", cfg.Server.Environment,
+			"publish_failure_rate", cfg.Chaos.PublishFailureRate,
+			"duplicate_rate", cfg.Chaos.DuplicateRate,
+			"max_delay_ms", cfg.Chaos.MaxDelayMillis,
+			"seed", seed)
+	}
+
+	// Initialize and start Relay for outbox processing, with several replicas only the elected leader publishes
+	var elector database.Elector
+	if cfg.Events.RelayLeaderElection {
+		elector = database.NewAdvisoryLock(db, int64(cfg.Events.RelayLockKey))
+	}
+	relay := database.NewRelay(db, redisClient, logger, database.RelayConfig{
+		PollInterval:  5 * time.Second,
+		BatchSize:     100,
+		SchemaVersion: cfg.Events.SchemaVersion,
+		MaxStreamLen:  cfg.Redis.StreamMaxLen,
+		MaxBacklog:    cfg.Redis.MaxBacklog,
+		MaxLag:        cfg.Redis.MaxLag,
+
+		PayloadEncoding:   cfg.Events.PayloadCompression,
+		CompressThreshold: cfg.Events.CompressThreshold,
+		MaxPayloadSize:    cfg.Events.MaxPayloadSize,
+
+		Faults:     faults,
+		Dispatcher: eventroute.NewHTTPDispatcher(cfg.Events.KafkaRESTURL, time.Duration(cfg.Events.WebhookTimeout)*time.Second),
+
+		Elector:    elector,
+		InstanceID: cfg.Events.InstanceID,
+	})
+	go func() {
+		if err := relay.Start(ctx); err != nil && err != context.Canceled {
+			logger.Error("relay stopped with error", "error", err)
+		}
+	}()
+
+	// Initialize services
+	scraperService := scraper.NewService(b, db, logger)
+
+	labelDict, err := labels.Load(cfg.Scraper.Marketplace, cfg.Scraper.LabelsFile)
+	if err != nil {
+		return fmt.Errorf("failed to load size table labels: %w", err)
+	}
+	scraperService.SetLabels(labelDict)
+
+	if cfg.Scraper.ValidationFile != "" {
+		validationCfg, err := database.LoadValidationConfig(cfg.Scraper.ValidationFile)
+		if err != nil {
+			return fmt.Errorf("failed to load size table validation rules: %w", err)
+		}
+		scraperService.SetValidator(database.NewSizeTableValidatorFromConfig(validationCfg))
+	}
+
+	ocrEngine, err := ocr.New(cfg.Scraper.OCREngine, cfg.Scraper.OCRLanguages)
+	if err != nil {
+		return fmt.Errorf("failed to initialize size chart OCR: %w", err)
+	}
+	if ocrEngine != nil {
+		scraperService.SetOCR(ocrEngine)
+		logger.Info("size chart OCR fallback enabled", "engine", cfg.Scraper.OCREngine)
+	}
+
+	// Deployments trade extraction depth for throughput by skipping optional stages
+	extractStages, err := stages.Parse(cfg.Scraper.SkipStages)
+	if err != nil {
+		return fmt.Errorf("invalid extraction stages: %w", err)
+	}
+	scraperService.SetStages(extractStages)
+	if skipped := extractStages.Disabled(); len(skipped) > 0 {
+		logger.Info("extraction stages disabled", "stages", skipped)
+	}
+
+	// Consumers that also want e.g. chest-only products relax which size tables are kept
+	policy := database.MeasurementPolicy{
+		RequireLength: cfg.Scraper.RequireLength,
+		RequireChest:  cfg.Scraper.RequireChest,
+		AcceptPartial: cfg.Scraper.AcceptPartial,
+	}
+	scraperService.SetMeasurementPolicy(policy)
+	if policy != database.DefaultMeasurementPolicy() {
+		logger.Info("measurement policy changed", "policy", policy)
+	}
+
+	// A stuck page wait must not hang a worker beyond the task deadline
+	scraperService.SetTaskTimeout(time.Duration(cfg.Scraper.TaskTimeoutSeconds) * time.Second)
+
+	// All page fetches of a marketplace share one limiter, however many crawl workers run, its rate and
+	// the workers per job follow the time-of-day calendar
+	var calendar schedule.Calendar
+	if cfg.Scraper.ScheduleFile != "" {
+		if calendar, err = schedule.LoadCalendar(cfg.Scraper.ScheduleFile); err != nil {
+			return err
+		}
+	}
+	if cfg.Scraper.NightCrawl {
+		calendar.Windows = append(calendar.Windows, schedule.NightWindow())
+	}
+	crawlSchedule, err := schedule.New(schedule.Profile{
+		Workers:          cfg.Scraper.ConcurrentWorkers,
+		RateLimitSeconds: float64(cfg.Scraper.RateLimitSeconds),
+	}, calendar)
+	if err != nil {
+		return fmt.Errorf("invalid crawl schedule: %w", err)
+	}
+	scraperService.SetSchedule(crawlSchedule)
+
+	// Page fetches are counted per API key and job in Redis, shared by all instances
+	quotaBudgets, err := quota.ParseBudgets(cfg.Scraper.QuotaBudgets)
+	if err != nil {
+		return fmt.Errorf("invalid quota budgets: %w", err)
+	}
+	quotaStore := quota.NewRedisStore(redisClient, "scraper:quota")
+	scraperService.SetQuota(quota.NewTracker(quotaStore, int64(cfg.Scraper.QuotaDailyBudget), quotaBudgets))
+
+	if fallback := paapi.NewClient(paapi.Config{
+		AccessKey:  cfg.PAAPI.AccessKey,
+		SecretKey:  cfg.PAAPI.SecretKey,
+		PartnerTag: cfg.PAAPI.PartnerTag,
+		Timeout:    time.Duration(cfg.PAAPI.Timeout) * time.Second,
+	}); fallback != nil {
+		scraperService.SetFallback(fallback)
+		logger.Info("PA-API fallback enabled", "partner_tag", cfg.PAAPI.PartnerTag)
+	}
+
+	jobManager := jobs.NewManager(db, scraperService, publisher, logger)
+	jobManager.SetQuotaAction(cfg.Scraper.QuotaAction)
+	jobManager.SetDedupWindow(time.Duration(cfg.Scraper.JobDedupWindow) * time.Second)
+	jobManager.SetCrawlWorkers(cfg.Scraper.ConcurrentWorkers)
+	jobManager.SetReportDir(cfg.Scraper.ReportDir)
+	if cfg.Scraper.ReportingCurrency != "" {
+		rates, err := currency.ParseRates(cfg.Scraper.ReportingCurrency, cfg.Scraper.FXRates)
+		if err != nil {
+			return fmt.Errorf("invalid exchange rates: %w", err)
+		}
+		jobManager.SetCurrencyConverter(currency.NewConverter(rates, cfg.Scraper.ReportingCurrency))
+	}
+	if cfg.LLM.BaseURL != "" {
+		client := llm.NewHTTPClient(llm.Config{
+			BaseURL: cfg.LLM.BaseURL,
+			APIKey:  cfg.LLM.APIKey,
+			Model:   cfg.LLM.Model,
+			Timeout: time.Duration(cfg.LLM.Timeout) * time.Second,
+		})
+		jobManager.SetReviewSummarizer(reviewsummary.New(client))
+		logger.Info("review summaries enabled", "model", cfg.LLM.Model)
+	}
+
+	// Start job worker
+	go jobManager.StartWorker(ctx)
+
+	// The orchestrator drives scrapes and cancels jobs through the control stream as well as the API
+	if cfg.Events.ControlStream != "" {
+		controlConsumer := control.NewConsumer(redisClient, jobManager, publisher, control.Config{
+			Stream:   cfg.Events.ControlStream,
+			Group:    cfg.Events.ControlGroup,
+			Consumer: cfg.Events.InstanceID,
+			Workers:  cfg.Events.ControlWorkers,
+		}, logger)
+		go func() {
+			if err := controlConsumer.Run(ctx); err != nil && err != context.Canceled {
+				logger.Error("control consumer stopped with error", "error", err)
+			}
+		}()
+	}
+
+	// Initialize API handlers
+	handlers := api.NewHandlers(scraperService, jobManager, logger)
+	handlers.SetDatabase(db)
+	handlers.SetRelay(relay)
+	handlers.SetBatchWorkers(cfg.Scraper.ConcurrentWorkers)
+	if cache := sizecache.New(cfg.Scraper.SizeChartCacheSize, time.Duration(cfg.Scraper.SizeChartCacheTTL)*time.Second); cache != nil {
+		if cfg.Scraper.SizeChartCacheRedis {
+			cache.SetRedis(redisClient, "scraper:size-chart")
+		}
+		handlers.SetSizeChartCache(cache)
+	}
+	scraperService.SetScreenshotArchive(cfg.Scraper.ScreenshotDir, time.Duration(cfg.Scraper.ScreenshotMaxAge)*time.Second)
+	if signer := signedurl.New(cfg.Scraper.ScreenshotSecret); signer != nil {
+		handlers.SetScreenshotSigner(signer, time.Duration(cfg.Scraper.ScreenshotURLTTL)*time.Second)
+	}
+	if cfg.Scraper.DebugSessions {
+		debugSessions := browser.NewDebugSessions(b, browser.DebugConfig{
+			Headed: cfg.Scraper.DebugHeaded,
+			Port:   cfg.Scraper.DebugPort,
+			TTL:    time.Duration(cfg.Scraper.DebugTTL) * time.Second,
+			VNCURL: cfg.Scraper.DebugVNCURL,
+		})
+		defer debugSessions.CloseAll()
+		handlers.SetDebugSessions(debugSessions)
+		logger.Warn("debug sessions enabled", "headed", cfg.Scraper.DebugHeaded, "port", cfg.Scraper.DebugPort)
+	}
+
+	// Setup Chi router
+	r := chi.NewRouter()
+
+	// Middleware
+	r.Use(middleware.RequestID)
+	r.Use(middleware.RealIP)
+	r.Use(logging.Middleware(logger))
+	r.Use(middleware.Recoverer)
+	r.Use(requestTimeout(60 * time.Second))
+
+	// CORS
+	r.Use(cors.Handler(cors.Options{
+		AllowedOrigins:   []string{"http://localhost:*", "https://localhost:*"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-API-Key", "X-Request-Id", logging.TraceHeader, "traceparent"},
+		ExposedHeaders:   []string{"Link", "X-Request-Id", logging.TraceHeader},
+		AllowCredentials: true,
+		MaxAge:           300,
+	}))
+
+	// Health check
+	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		// Check outbox status
+		pendingCount, _ := relay.GetPendingCount(context.Background())
+		deadLetterCount, _ := relay.GetDeadLetterCount(context.Background())
+
+		browserStats := scraperService.BrowserStats()
+
+		health := map[string]interface{}{
+			"status": "ok",
+			"outbox": map[string]interface{}{
+				"pending":     pendingCount,
+				"dead_letter": deadLetterCount,
+				"paused":      relay.IsPaused(),
+				"routes":      routes.Routes(),
+				"relay":       relay.Leadership(),
+			},
+			"browser":      browserStats,
+			"database":     db.Stats(),
+			"replicas":     db.Replicas(),
+			"marketplaces": b.MarketplaceBreaker().States(),
+			"schedule":     crawlSchedule.States(),
+			"chaos":        cfg.Chaos.Enabled,
+		}
+
+		status := http.StatusOK
+		if pendingCount > 1000 {
+			health["status"] = "warning"
+			health["message"] = "High number of pending outbox events"
+		}
+		if deadLetterCount > 100 {
+			health["status"] = "error"
+			health["message"] = "High number of dead letter events"
+			status = http.StatusServiceUnavailable
+		}
+
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(health)
+	})
+
+	// Quota usage and browser session metrics
+	r.Get("/metrics", handlers.Metrics)
+
+	// API Routes
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Use(api.QuotaSubject)
+
+		// Scraper endpoints (Oxylabs replacement)
+		r.Route("/scraper", func(r chi.Router) {
+			// Size chart endpoint - replaces Oxylabs size chart API
+			r.Post("/size-chart", handlers.GetSizeChart)
+			r.Post("/size-chart/batch", handlers.BatchSizeCharts)
+
+			// Reviews endpoint - replaces Oxylabs reviews API
+			r.Post("/reviews", handlers.GetReviews)
+
+			// Job management endpoints
+			r.Post("/jobs", handlers.CreateJob)
+			r.Get("/jobs/{jobID}", handlers.GetJob)
+			r.Get("/jobs", handlers.ListJobs)
+			r.Get("/jobs/{jobID}/products", handlers.GetJobProducts)
+			r.Get("/jobs/{jobID}/report", handlers.GetJobReport)
+			r.Get("/jobs/{jobID}/events", handlers.StreamJobEvents)
+			r.With(handlers.Audit("job.cancel", "job", "jobID")).Post("/jobs/{jobID}/cancel", handlers.CancelJob)
+
+			// ASIN list imports
+			r.With(handlers.Audit("product.import", "job", "")).Post("/imports", handlers.ImportProducts)
+
+			// Saved search definitions that create jobs
+			r.Post("/templates", handlers.CreateTemplate)
+			r.Get("/templates", handlers.ListTemplates)
+			r.Get("/templates/{templateID}", handlers.GetTemplate)
+			r.Put("/templates/{templateID}", handlers.UpdateTemplate)
+			r.Delete("/templates/{templateID}", handlers.DeleteTemplate)
+			r.Post("/templates/{templateID}/run", handlers.RunTemplate)
+
+			// Mapping of breadcrumbs and browse nodes to internal category codes
+			r.Get("/taxonomy", handlers.GetTaxonomy)
+			r.Put("/taxonomy/mappings", handlers.SaveCategoryMapping)
+			r.Delete("/taxonomy/mappings/{kind}/{value}", handlers.DeleteCategoryMapping)
+			r.With(handlers.Audit("taxonomy.remap", "taxonomy", "")).Post("/taxonomy/remap", handlers.RemapCategories)
+
+			// Spellings of a brand mapped to its canonical name
+			r.Get("/brands/aliases", handlers.ListBrandAliases)
+			r.Put("/brands/aliases", handlers.SaveBrandAlias)
+			r.Delete("/brands/aliases/{alias}", handlers.DeleteBrandAlias)
+
+			// Product diagnostics for failed extractions
+			r.Get("/products/{asin}", handlers.GetProduct)
+			r.Get("/products/{asin}/screenshot", handlers.GetProductScreenshot)
+			r.Get("/products/{asin}/group", handlers.GetProductGroup)
+			r.Get("/products/{asin}/history", handlers.GetProductHistory)
+			r.Get("/products/{asin}/fit-summary", handlers.GetFitSummary)
+			r.With(handlers.Audit("fit_summary.refresh", "product", "asin")).Post("/products/{asin}/fit-summary", handlers.SummarizeReviews)
+			r.With(handlers.Audit("size_table.import", "product", "asin")).Post("/products/{asin}/size-table/import", handlers.ImportSizeTable)
+			r.With(handlers.Audit("product.refresh", "product", "")).Post("/products/batch", handlers.BatchScrapeProducts)
+			r.Post("/resolve", handlers.ResolveProductURL)
+
+			// Product screenshots for manual QA, opened through signed URLs
+			r.Post("/screenshot/sign", handlers.SignScreenshotURL)
+			r.Get("/screenshot", handlers.GetScreenshot)
+
+			// Normalized size measurement export
+			r.Get("/size-measurements", handlers.ExportSizeMeasurements)
+			r.Get("/size-conversions", handlers.ExportSizeConversions)
+
+			// Anti-detection scorecard per fingerprint and proxy
+			r.Get("/sessions", handlers.GetSessionScorecard)
+		})
+
+		// Stats endpoint
+		r.Get("/stats", handlers.GetStats)
+
+		// Size chart coverage of product samples, tracked over time
+		r.Post("/analytics/coverage", handlers.AnalyzeCoverage)
+		r.Get("/analytics/coverage", handlers.ListCoverageReports)
+
+		// Size table analyses of live search results, run by the job worker
+		r.Post("/analytics/size-analyses", handlers.QueueSizeAnalysis)
+		r.Get("/analytics/size-analyses", handlers.ListSizeAnalyses)
+		r.Get("/analytics/size-analyses/{analysisID}", handlers.GetSizeAnalysis)
+
+		// ASIN overlap of two jobs or saved searches
+		r.Get("/analytics/overlap", handlers.CompareJobs)
+
+		// Maintenance endpoints
+		r.With(handlers.Audit("product.backfill", "product", "")).Post("/admin/backfill", handlers.Backfill)
+
+		// Outbox inspection for debugging event delivery and replay of processed events
+		r.Route("/outbox", func(r chi.Router) {
+			r.Use(api.AdminAuth(cfg.Server.AdminToken))
+			r.Get("/events", handlers.ListOutboxEvents)
+			r.Get("/events/{eventID}", handlers.GetOutboxEvent)
+			r.With(handlers.Audit("outbox.replay", "outbox_event", "")).Post("/replay", handlers.ReplayOutboxEvents)
+		})
+
+		// Audit log of cancellations, imports, refreshes and replays, read-only
+		r.Route("/audit", func(r chi.Router) {
+			r.Use(api.AdminAuth(cfg.Server.AdminToken))
+			r.Get("/", handlers.ListAuditLog)
+		})
+
+		// Debug browsers for reproducing bot checks, their DevTools endpoint is proxied behind the admin token
+		r.Route("/debug/sessions", func(r chi.Router) {
+			r.Use(api.AdminAuth(cfg.Server.AdminToken))
+			r.Post("/", handlers.StartDebugSession)
+			r.Get("/{sessionID}", handlers.GetDebugSession)
+			r.Delete("/{sessionID}", handlers.CloseDebugSession)
+			r.HandleFunc("/{sessionID}/cdp/*", handlers.ProxyDebugSession)
+		})
+	})
+
+	// Start server
+	server := &http.Server{
+		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
+		Handler:      r,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 60 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+
+	// Graceful shutdown
+	go func() {
+		<-ctx.Done()
+
+		logger.Info("shutting down server...")
+		cancel()
+
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer shutdownCancel()
+
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logger.Error("server shutdown failed", "error", err)
+		}
+	}()
+
+	logger.Info("server starting", "port", cfg.Server.Port)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("server failed: %w", err)
+	}
+
+	logger.Info("server stopped")
+	return nil
+}
+
+// requestTimeout cancels requests after d, except Server-Sent Event and NDJSON streams which stay open
+// while the client listens and WebSocket connections of debug sessions
+func requestTimeout(d time.Duration) func(http.Handler) http.Handler {
+	timeout := middleware.Timeout(d)
+	return func(next http.Handler) http.Handler {
+		limited := timeout(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			accept := r.Header.Get("Accept")
+			if strings.Contains(accept, "text/event-stream") || strings.Contains(accept, api.ContentTypeNDJSON) || strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+				next.ServeHTTP(w, r)
+				return
+			}
+			limited.ServeHTTP(w, r)
+		})
+	}
+}
+
+// serveBrowserOptions builds the browser options of the API from its config, shared by serve and check
+func serveBrowserOptions(cfg *config.Config) (*browser.Options, error) {
+	navigation, err := browser.ParseNavigationStrategy(cfg.Scraper.Navigation)
+	if err != nil {
+		return nil, fmt.Errorf("invalid navigation strategy: %w", err)
+	}
+	navigationOverrides, err := browser.ParseNavigationOverrides(cfg.Scraper.NavigationOverrides)
+	if err != nil {
+		return nil, fmt.Errorf("invalid navigation overrides: %w", err)
+	}
+	var resourcePolicies map[string]browser.ResourcePolicy
+	if cfg.Scraper.BlockResources {
+		resourcePolicies, err = browser.ParseResourcePolicies(cfg.Scraper.ResourcePolicies, browser.DefaultResourcePolicies())
+		if err != nil {
+			return nil, fmt.Errorf("invalid resource policies: %w", err)
+		}
+	}
+
+	humanizeProfiles, err := browser.ParseHumanizeProfiles(cfg.Scraper.HumanizeProfiles, browser.DefaultHumanizeProfiles(cfg.Scraper.Humanize))
+	if err != nil {
+		return nil, fmt.Errorf("invalid humanize profiles: %w", err)
+	}
+	// Tests drive the scraper against fixtures, humanization would only slow them down
+	if cfg.Server.Environment == "test" {
+		humanizeProfiles = browser.DefaultHumanizeProfiles(browser.HumanizeOff)
+	}
+
+	proxy, err := browser.ParseProxy(cfg.Scraper.Proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy: %w", err)
+	}
+	contextProxies, err := browser.ParseProxies(cfg.Scraper.ProxyPool)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy pool: %w", err)
+	}
+
+	sizeChartLayouts, err := browser.ParseSizeChartLayouts(cfg.Scraper.SizeChartLayouts, browser.DefaultSizeChartLayouts())
+	if err != nil {
+		return nil, fmt.Errorf("invalid size chart layouts: %w", err)
+	}
+
+	return &browser.Options{
+		Headless:            cfg.Scraper.Headless,
+		Timeout:             time.Duration(cfg.Scraper.TimeoutSeconds) * time.Second,
+		DiagnosticsDir:      cfg.Scraper.DiagnosticsDir,
+		Navigation:          navigation,
+		NavigationOverrides: navigationOverrides,
+		EscalateNavigation:  cfg.Scraper.NavigationEscalate,
+		ResourcePolicies:    resourcePolicies,
+		DownloadImages:      cfg.Scraper.DownloadImages,
+		Proxy:               proxy,
+		ContextProxies:      contextProxies,
+		Fingerprint:         cfg.Scraper.Fingerprint,
+		BypassAgeGate:       cfg.Scraper.AgeGate == "bypass",
+		Breaker: browser.BreakerConfig{
+			ErrorRate:   cfg.Scraper.BreakerErrorRate,
+			Window:      cfg.Scraper.BreakerWindow,
+			MinRequests: cfg.Scraper.BreakerMinRequests,
+			Cooldown:    time.Duration(cfg.Scraper.BreakerCooldown) * time.Second,
+		},
+		SizeChartLayouts:     sizeChartLayouts,
+		SizeChartTimeout:     time.Duration(cfg.Scraper.SizeChartTimeout) * time.Second,
+		SizeChartNetworkIdle: cfg.Scraper.SizeChartWaitIdle,
+		HumanizeProfiles:     humanizeProfiles,
+	}, nil
+}
+
+// serveDBConfig builds the database config of the API from its config
+func serveDBConfig(cfg *config.Config) database.Config {
+	return database.Config{
+		Host:     cfg.Database.Host,
+		Port:     cfg.Database.Port,
+		User:     cfg.Database.User,
+		Password: cfg.Database.Password,
+		Database: cfg.Database.Name,
+		MaxConns: cfg.Database.MaxConns,
+
+		StatementTimeout:  time.Duration(cfg.Database.StatementTimeout) * time.Second,
+		HealthCheckPeriod: time.Duration(cfg.Database.HealthCheckPeriod) * time.Second,
+		ConnectRetries:    cfg.Database.ConnectRetries,
+		ConnectBackoff:    time.Duration(cfg.Database.ConnectBackoffMS) * time.Millisecond,
+
+		ReplicaDSNs:     cfg.Database.ReplicaDSNs,
+		ReplicaCooldown: time.Duration(cfg.Database.ReplicaCooldown) * time.Second,
+	}
+}
@@ -0,0 +1,340 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/events"
+	"github.com/maltedev/amazon-size-scraper/internal/browser"
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/maltedev/amazon-size-scraper/internal/labels"
+	"github.com/maltedev/amazon-size-scraper/internal/scraper"
+	"github.com/maltedev/amazon-size-scraper/internal/stages"
+	"github.com/spf13/cobra"
+)
+
+func newSizesCommand(a *app) *cobra.Command {
+	var (
+		searchURL   string
+		storeURL    string
+		storePages  int
+		concurrent  int
+		scrapeOnly  bool
+		marketplace string
+		labelsFile  string
+		navigation  string
+		navOverride string
+		navEscalate bool
+		daemon      bool
+		daemonOpts  scraper.DaemonOptions
+		autoscale   scraper.AutoscaleOptions
+		lease       time.Duration
+		retry       scraper.RetryPolicy
+		skipStages  string
+		policy      database.MeasurementPolicy
+	)
+	var dbHost, dbUser, dbPassword, dbName string
+	var dbPort int
+
+	cmd := &cobra.Command{
+		Use:   "sizes",
+		Short: "Crawl a search into the database and extract the size tables of all pending products",
+		Long: "Crawl a search into the database and extract the size tables of all pending products.\n\n" +
+			"With --daemon the scrapers keep running and pick up products added later. Each product is leased " +
+			"to one worker, products of crashed workers are taken over once their lease expires and on " +
+			"SIGINT/SIGTERM the in-flight products get --drain-timeout to finish. With --max-concurrent the " +
+			"number of workers is scaled between --min-concurrent and --max-concurrent by the pending " +
+			"products and the error rate instead of staying at --concurrent.\n\n" +
+			"With --store the products of a brand store (/stores/...) or seller storefront (/sp?seller=..., " +
+			"/s?me=...) are enqueued instead of or in addition to a search.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Database flags override DB_* from the shared config
+			flags := cmd.Flags()
+			if flags.Changed("db-host") {
+				a.cfg.Database.Host = dbHost
+			}
+			if flags.Changed("db-port") {
+				a.cfg.Database.Port = dbPort
+			}
+			if flags.Changed("db-user") {
+				a.cfg.Database.User = dbUser
+			}
+			if flags.Changed("db-password") {
+				a.cfg.Database.Password = dbPassword
+			}
+			if flags.Changed("db-name") {
+				a.cfg.Database.DBName = dbName
+			}
+			extractStages, err := stages.Parse(skipStages)
+			if err != nil {
+				return err
+			}
+			return a.runSizes(cmd.Context(), searchURL, storeURL, storePages, concurrent, scrapeOnly, marketplace, labelsFile, navigation, navOverride, navEscalate, lease, retry, daemon, daemonOpts, autoscale, extractStages, policy)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&searchURL, "search", "", "Amazon search URL to crawl")
+	flags.StringVar(&storeURL, "store", "", "Amazon brand store or seller storefront URL to crawl")
+	flags.IntVar(&storePages, "store-pages", getEnvInt("SCRAPER_STORE_PAGES", scraper.DefaultStorePages), "Maximum number of brand store pages to visit")
+	flags.StringVar(&dbHost, "db-host", "", "Database host (default from DB_HOST)")
+	flags.IntVar(&dbPort, "db-port", 0, "Database port (default from DB_PORT)")
+	flags.StringVar(&dbUser, "db-user", "", "Database user (default from DB_USER)")
+	flags.StringVar(&dbPassword, "db-password", "", "Database password (default from DB_PASSWORD)")
+	flags.StringVar(&dbName, "db-name", "", "Database name (default from DB_NAME)")
+	flags.IntVar(&concurrent, "concurrent", getEnvInt("CONCURRENT_SCRAPERS", 1), "Number of concurrent product scrapers")
+	flags.BoolVar(&scrapeOnly, "scrape-only", false, "Only scrape products, don't crawl search results")
+	flags.StringVar(&marketplace, "marketplace", getEnv("SCRAPER_MARKETPLACE", "amazon.de"), "Amazon marketplace used to pick size table labels")
+	flags.StringVar(&labelsFile, "labels", getEnv("SCRAPER_LABELS_FILE", ""), "JSON file with additional size table labels")
+	flags.StringVar(&navigation, "navigation", getEnv("SCRAPER_NAVIGATION", "direct"), "Navigation strategy: direct, warm-homepage or referer-spoof")
+	flags.StringVar(&navOverride, "navigation-overrides", getEnv("SCRAPER_NAVIGATION_OVERRIDES", ""), "Per marketplace/proxy strategies, e.g. amazon.fr=warm-homepage")
+	flags.BoolVar(&navEscalate, "navigation-escalate", getEnvBool("SCRAPER_NAVIGATION_ESCALATE", true), "Escalate the navigation strategy after a failed attempt")
+	flags.BoolVar(&daemon, "daemon", getEnvBool("SCRAPER_DAEMON", false), "Keep scraping new pending products until stopped")
+	flags.StringVar(&skipStages, "skip-stages", getEnv("SCRAPER_SKIP_STAGES", ""), "Optional extraction stages to skip, e.g. material")
+	flags.BoolVar(&policy.RequireLength, "require-length", getEnvBool("MEASUREMENT_REQUIRE_LENGTH", true), "Only keep products whose size table has a length measurement")
+	flags.BoolVar(&policy.RequireChest, "require-chest", getEnvBool("MEASUREMENT_REQUIRE_CHEST", false), "Only keep products whose size table has a chest measurement")
+	flags.BoolVar(&policy.AcceptPartial, "accept-partial", getEnvBool("MEASUREMENT_ACCEPT_PARTIAL", false), "Also keep products with only some of the required measurements")
+	flags.DurationVar(&lease, "lease", getEnvDuration("SCRAPER_LEASE", scraper.DefaultLease), "How long a claimed product stays reserved for a worker without a heartbeat")
+	flags.IntVar(&retry.MaxAttempts, "max-attempts", getEnvInt("SCRAPER_MAX_ATTEMPTS", scraper.DefaultMaxAttempts), "Failed scrapes of a product before it is marked failed")
+	flags.DurationVar(&retry.Backoff, "retry-backoff", getEnvDuration("SCRAPER_RETRY_BACKOFF", scraper.DefaultRetryBackoff), "Wait before a failed product is claimed again, doubled per failed scrape")
+	flags.DurationVar(&daemonOpts.PollInterval, "poll-interval", getEnvDuration("SCRAPER_POLL_INTERVAL", scraper.DefaultPollInterval), "Wait between checks for new pending products in daemon mode")
+	flags.DurationVar(&daemonOpts.DrainTimeout, "drain-timeout", getEnvDuration("SCRAPER_DRAIN_TIMEOUT", scraper.DefaultDrainTimeout), "How long in-flight products may finish after shutdown in daemon mode")
+	flags.IntVar(&autoscale.Min, "min-concurrent", getEnvInt("SCRAPER_MIN_CONCURRENT", 1), "Fewest concurrent product scrapers when autoscaling")
+	flags.IntVar(&autoscale.Max, "max-concurrent", getEnvInt("SCRAPER_MAX_CONCURRENT", 0), "Most concurrent product scrapers, enables autoscaling in daemon mode")
+	flags.DurationVar(&autoscale.Interval, "autoscale-interval", getEnvDuration("SCRAPER_AUTOSCALE_INTERVAL", scraper.DefaultAutoscaleInterval), "Wait between autoscaling decisions")
+	flags.IntVar(&autoscale.ProductsPerWorker, "products-per-worker", getEnvInt("SCRAPER_PRODUCTS_PER_WORKER", scraper.DefaultProductsPerWorker), "Pending products per scraper the autoscaler aims for")
+	flags.Float64Var(&autoscale.MaxErrorRate, "max-error-rate", getEnvFloat("SCRAPER_MAX_ERROR_RATE", scraper.DefaultMaxErrorRate), "Share of failed scrapes above which the autoscaler removes scrapers")
+	return cmd
+}
+
+func (a *app) runSizes(ctx context.Context, searchURL, storeURL string, storePages, concurrent int, scrapeOnly bool, marketplace, labelsFile, navigation, navOverride string, navEscalate bool, lease time.Duration, retry scraper.RetryPolicy, daemon bool, daemonOpts scraper.DaemonOptions, autoscale scraper.AutoscaleOptions, extractStages stages.Flags, policy database.MeasurementPolicy) error {
+	logger := a.logger
+	autoscaling := daemon && autoscale.Max > 0
+
+	// Database connection
+	dbConfig := database.Config{
+		Host:        a.cfg.Database.Host,
+		Port:        a.cfg.Database.Port,
+		User:        a.cfg.Database.User,
+		Password:    a.cfg.Database.Password,
+		Database:    a.cfg.Database.DBName,
+		MaxConns:    int32(max(concurrent, autoscale.Max) * 2),
+		MinConns:    1,
+		MaxConnLife: 5 * time.Minute,
+		MaxConnIdle: 1 * time.Minute,
+	}
+
+	db, err := database.New(ctx, dbConfig)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	logger.Info("connected to database")
+
+	// Browser setup
+	browserOpts := browser.DefaultOptions()
+	browserOpts.Headless = a.headless
+	browserOpts.EscalateNavigation = navEscalate
+
+	if browserOpts.Navigation, err = browser.ParseNavigationStrategy(navigation); err != nil {
+		return fmt.Errorf("invalid navigation strategy: %w", err)
+	}
+	if browserOpts.NavigationOverrides, err = browser.ParseNavigationOverrides(navOverride); err != nil {
+		return fmt.Errorf("invalid navigation overrides: %w", err)
+	}
+
+	tracker := a.newProgress("sizes")
+	tracker.Start()
+	defer tracker.Stop()
+
+	// Phase 1: Search and store crawling (if URLs provided and not scrape-only)
+	if (searchURL != "" || storeURL != "") && !scrapeOnly {
+		logger.Info("starting search crawl phase", "url", searchURL, "store", storeURL)
+
+		b, err := browser.New(browserOpts)
+		if err != nil {
+			return fmt.Errorf("failed to create browser: %w", err)
+		}
+
+		searchCrawler := scraper.NewSearchCrawler(b, db)
+		searchCrawler.SetProgress(tracker)
+		if searchURL != "" {
+			if err := searchCrawler.CrawlSearch(ctx, searchURL); err != nil {
+				b.Close()
+				return fmt.Errorf("search crawl failed: %w", err)
+			}
+		}
+
+		if storeURL != "" {
+			storeCrawler := scraper.NewStoreCrawler(searchCrawler)
+			storeCrawler.SetMaxPages(storePages)
+			if err := storeCrawler.Crawl(ctx, storeURL); err != nil {
+				b.Close()
+				return fmt.Errorf("store crawl failed: %w", err)
+			}
+		}
+
+		b.Close()
+		logger.Info("search crawl completed")
+
+		// Get stats
+		counts, _ := db.CountProductsByStatus(ctx)
+		logger.Info("product statistics",
+			"pending", counts[database.StatusPending],
+			"completed", counts[database.StatusCompleted],
+			"failed", counts[database.StatusFailed])
+	}
+
+	// Phase 2: Product scraping
+	if tracker != nil && !daemon {
+		// A daemon has no end to estimate
+		if counts, err := db.CountProductsByStatus(ctx); err == nil {
+			tracker.SetTotal(counts[database.StatusPending])
+		}
+	}
+	logger.Info("starting product scraping phase", "concurrent", concurrent, "daemon", daemon, "autoscale", autoscaling)
+
+	labelDict, err := labels.Load(marketplace, labelsFile)
+	if err != nil {
+		return fmt.Errorf("failed to load size table labels: %w", err)
+	}
+
+	// Retired products are announced to the default target, like backfilled events
+	publisher := events.NewPublisher(db, logger)
+
+	// Every scraper gets its own browser
+	newScraper := func() (*scraper.ProductScraper, func(), error) {
+		b, err := browser.New(browserOpts)
+		if err != nil {
+			return nil, nil, err
+		}
+		s := scraper.NewProductScraper(b, db)
+		s.SetLabels(labelDict)
+		s.SetClaimLease(lease)
+		s.SetRetryPolicy(retry)
+		s.SetStages(extractStages)
+		s.SetMeasurementPolicy(policy)
+		s.SetProgress(tracker)
+		s.SetPublisher(publisher)
+		return s, func() { b.Close() }, nil
+	}
+
+	if autoscaling {
+		if err := scraper.NewAutoscaler(db, autoscale, daemonOpts, newScraper).Run(ctx); err != nil {
+			return err
+		}
+		a.logFinalCounts(ctx, db)
+		return nil
+	}
+
+	// Create multiple browsers for concurrent scraping
+	scrapers := make([]*scraper.ProductScraper, concurrent)
+	closers := make([]func(), concurrent)
+
+	for i := 0; i < concurrent; i++ {
+		s, closeBrowser, err := newScraper()
+		if err != nil {
+			// Clean up already created browsers
+			for j := 0; j < i; j++ {
+				closers[j]()
+			}
+			return fmt.Errorf("failed to create browser %d: %w", i, err)
+		}
+		scrapers[i], closers[i] = s, closeBrowser
+	}
+
+	// Start concurrent scrapers
+	errChan := make(chan error, concurrent)
+	for i, s := range scrapers {
+		go func(index int, s *scraper.ProductScraper) {
+			logger.Info("starting scraper", "index", index)
+			var err error
+			if daemon {
+				err = s.RunDaemon(ctx, daemonOpts)
+			} else {
+				err = s.ScrapeAllPending(ctx, 10)
+			}
+			if err != nil {
+				errChan <- fmt.Errorf("scraper %d failed: %w", index, err)
+			} else {
+				errChan <- nil
+			}
+		}(i, s)
+	}
+
+	// Wait for all scrapers to complete
+	var scrapeErrors []error
+	for i := 0; i < concurrent; i++ {
+		if err := <-errChan; err != nil {
+			scrapeErrors = append(scrapeErrors, err)
+		}
+	}
+
+	// Clean up browsers
+	for _, closeBrowser := range closers {
+		closeBrowser()
+	}
+
+	if len(scrapeErrors) > 0 {
+		logger.Error("some scrapers failed", "errors", scrapeErrors)
+	}
+
+	a.logFinalCounts(ctx, db)
+	return nil
+}
+
+// logFinalCounts logs the product statistics after scraping, ctx is already cancelled after a daemon shutdown
+func (a *app) logFinalCounts(ctx context.Context, db *database.DB) {
+	counts, _ := db.CountProductsByStatus(context.WithoutCancel(ctx))
+	a.logger.Info("scraping completed",
+		"pending", counts[database.StatusPending],
+		"processing", counts[database.StatusProcessing],
+		"completed", counts[database.StatusCompleted],
+		"failed", counts[database.StatusFailed],
+		"retired", counts[database.StatusRetired])
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		var i int
+		fmt.Sscanf(value, "%d", &i)
+		return i
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		return value == "true" || value == "1"
+	}
+	return defaultValue
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return defaultValue
+}
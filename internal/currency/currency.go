@@ -0,0 +1,98 @@
+package currency
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/maltedev/amazon-size-scraper/internal/numparse"
+)
+
+// Supported ISO 4217 currency codes
+const (
+	EUR = "EUR"
+	GBP = "GBP"
+	USD = "USD"
+	PLN = "PLN"
+	SEK = "SEK"
+)
+
+// marketplaces maps Amazon marketplace domains to their currency
+var marketplaces = map[string]string{
+	"amazon.de":     EUR,
+	"amazon.fr":     EUR,
+	"amazon.it":     EUR,
+	"amazon.es":     EUR,
+	"amazon.nl":     EUR,
+	"amazon.com.be": EUR,
+	"amazon.co.uk":  GBP,
+	"amazon.com":    USD,
+	"amazon.pl":     PLN,
+	"amazon.se":     SEK,
+}
+
+// markers maps currency symbols and codes found in price texts to currencies, longest first
+var markers = []struct {
+	marker   string
+	currency string
+}{
+	{"US$", USD},
+	{"EUR", EUR},
+	{"GBP", GBP},
+	{"USD", USD},
+	{"PLN", PLN},
+	{"SEK", SEK},
+	{"zł", PLN},
+	{"kr", SEK},
+	{"€", EUR},
+	{"£", GBP},
+	{"$", USD},
+}
+
+// numberPattern matches a price amount with optional thousand and decimal separators
+var numberPattern = regexp.MustCompile(`\d[\d.,\s\x{00a0}\x{202f}]*`)
+
+// ForMarketplace returns the currency of an Amazon marketplace domain or URL, defaulting to EUR
+func ForMarketplace(marketplace string) string {
+	host := strings.ToLower(strings.TrimSpace(marketplace))
+	if u, err := url.Parse(host); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	host = strings.TrimPrefix(host, "www.")
+
+	if currency, ok := marketplaces[host]; ok {
+		return currency
+	}
+	return EUR
+}
+
+// Parse extracts the amount and currency from price texts like "1.234,56 €", "£1,234.56" or "129,00 zł".
+// The fallback currency is used when the text names none, a zero amount means no price was found.
+func Parse(text, fallback string) (float64, string) {
+	currency := Detect(text)
+	if currency == "" {
+		currency = fallback
+	}
+
+	match := strings.TrimSpace(numberPattern.FindString(text))
+	if match == "" {
+		return 0, currency
+	}
+
+	amount, err := strconv.ParseFloat(numparse.Normalize(match, numparse.Auto), 64)
+	if err != nil {
+		return 0, currency
+	}
+	return amount, currency
+}
+
+// Detect returns the currency named by a symbol or code in the text, empty if there is none
+func Detect(text string) string {
+	for _, m := range markers {
+		if strings.Contains(text, m.marker) {
+			return m.currency
+		}
+	}
+	return ""
+}
@@ -0,0 +1,76 @@
+package currency
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text         string
+		fallback     string
+		wantAmount   float64
+		wantCurrency string
+	}{
+		{"29,99 €", "", 29.99, EUR},
+		{"1.234,56 €", "", 1234.56, EUR},
+		{"EUR 19,90", "", 19.90, EUR},
+		{"£1,234.56", "", 1234.56, GBP},
+		{"$12.99", "", 12.99, USD},
+		{"US$ 1,299", "", 1299, USD},
+		{"129,00 zł", "", 129, PLN},
+		{"1 299,00 kr", "", 1299, SEK},
+		{"1\u00a0299,00\u00a0kr", "", 1299, SEK},
+		{"24,95", GBP, 24.95, GBP},
+		{"Derzeit nicht verfügbar", EUR, 0, EUR},
+	}
+
+	for _, tt := range tests {
+		amount, currency := Parse(tt.text, tt.fallback)
+		if amount != tt.wantAmount || currency != tt.wantCurrency {
+			t.Errorf("Parse(%q) = %v %s, want %v %s", tt.text, amount, currency, tt.wantAmount, tt.wantCurrency)
+		}
+	}
+}
+
+func TestForMarketplace(t *testing.T) {
+	tests := map[string]string{
+		"amazon.de":                      EUR,
+		"https://www.amazon.co.uk/dp/B0": GBP,
+		"www.amazon.pl":                  PLN,
+		"amazon.se":                      SEK,
+		"amazon.com":                     USD,
+		"unknown.example":                EUR,
+	}
+	for marketplace, want := range tests {
+		if got := ForMarketplace(marketplace); got != want {
+			t.Errorf("ForMarketplace(%q) = %s, want %s", marketplace, got, want)
+		}
+	}
+}
+
+func TestConverter(t *testing.T) {
+	rates, err := ParseRates(EUR, "GBP=1.17, USD=0.92,SEK=0.087")
+	if err != nil {
+		t.Fatalf("ParseRates() error = %v", err)
+	}
+
+	c := NewConverter(rates, EUR)
+	ctx := context.Background()
+
+	if got, err := c.Convert(ctx, 10, GBP); err != nil || got != 11.70 {
+		t.Errorf("Convert(10 GBP) = %v, %v; want 11.70", got, err)
+	}
+	if got, err := c.Convert(ctx, 19.99, EUR); err != nil || got != 19.99 {
+		t.Errorf("Convert(19.99 EUR) = %v, %v; want 19.99", got, err)
+	}
+	if _, err := c.Convert(ctx, 10, PLN); err == nil {
+		t.Error("expected error for missing PLN rate")
+	}
+
+	for _, invalid := range []string{"GBP", "GBP=abc", "GBP=-1"} {
+		if _, err := ParseRates(EUR, invalid); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package currency
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RateProvider returns how many units of the target currency one unit of the source currency buys
+type RateProvider interface {
+	Rate(ctx context.Context, from, to string) (float64, error)
+}
+
+// StaticRates is a RateProvider backed by fixed rates, each currency valued in a common base currency
+type StaticRates map[string]float64
+
+// ParseRates parses "GBP=1.17,USD=0.92" into rates valued in the base currency, which is added with rate 1
+func ParseRates(base, s string) (StaticRates, error) {
+	rates := StaticRates{strings.ToUpper(base): 1}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		code, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid exchange rate: %s", pair)
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("invalid exchange rate: %s", pair)
+		}
+		rates[strings.ToUpper(strings.TrimSpace(code))] = rate
+	}
+	return rates, nil
+}
+
+// Rate implements RateProvider
+func (r StaticRates) Rate(ctx context.Context, from, to string) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+
+	fromRate, ok := r[from]
+	if !ok {
+		return 0, fmt.Errorf("no exchange rate for %s", from)
+	}
+	toRate, ok := r[to]
+	if !ok {
+		return 0, fmt.Errorf("no exchange rate for %s", to)
+	}
+	return fromRate / toRate, nil
+}
+
+// Converter converts prices into a single reporting currency
+type Converter struct {
+	provider  RateProvider
+	reporting string
+}
+
+// NewConverter creates a converter into the reporting currency using the rate provider
+func NewConverter(provider RateProvider, reporting string) *Converter {
+	return &Converter{
+		provider:  provider,
+		reporting: strings.ToUpper(reporting),
+	}
+}
+
+// Reporting returns the reporting currency
+func (c *Converter) Reporting() string {
+	return c.reporting
+}
+
+// Convert converts the amount into the reporting currency, rounded to cents
+func (c *Converter) Convert(ctx context.Context, amount float64, from string) (float64, error) {
+	rate, err := c.provider.Rate(ctx, strings.ToUpper(from), c.reporting)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get exchange rate: %w", err)
+	}
+
+	converted := amount * rate
+	return float64(int64(converted*100+0.5)) / 100, nil
+}
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AuditEntry is one admin action taken through the API
+type AuditEntry struct {
+	ID           int64     `json:"id"`
+	Actor        string    `json:"actor"`         // admin, api_key:<hash prefix> or anonymous
+	Action       string    `json:"action"`        // e.g. job.cancel
+	ResourceType string    `json:"resource_type"` // e.g. job
+	ResourceID   string    `json:"resource_id,omitempty"`
+	Method       string    `json:"method"`
+	Path         string    `json:"path"`
+	Status       int       `json:"status"` // HTTP status of the response
+	RequestID    string    `json:"request_id,omitempty"`
+	TraceID      string    `json:"trace_id,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// AuditFilter selects audit log entries, zero fields match everything
+type AuditFilter struct {
+	Actor         string
+	Action        string
+	ResourceType  string
+	ResourceID    string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+}
+
+// where returns the SQL conditions of the filter and their arguments
+func (f AuditFilter) where() (string, []any) {
+	var conds []string
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if f.Actor != "" {
+		add("actor = $%d", f.Actor)
+	}
+	if f.Action != "" {
+		add("action = $%d", f.Action)
+	}
+	if f.ResourceType != "" {
+		add("resource_type = $%d", f.ResourceType)
+	}
+	if f.ResourceID != "" {
+		add("resource_id = $%d", f.ResourceID)
+	}
+	if !f.CreatedAfter.IsZero() {
+		add("created_at >= $%d", f.CreatedAfter)
+	}
+	if !f.CreatedBefore.IsZero() {
+		add("created_at < $%d", f.CreatedBefore)
+	}
+
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conds, " AND "), args
+}
+
+// InsertAuditEntry appends an entry to the audit log
+func (db *DB) InsertAuditEntry(ctx context.Context, e *AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (actor, action, resource_type, resource_id, method, path, status, request_id, trace_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), NULLIF($9, ''))`
+
+	_, err := db.Exec(ctx, query, e.Actor, e.Action, e.ResourceType, e.ResourceID, e.Method, e.Path, e.Status, e.RequestID, e.TraceID)
+	if err != nil {
+		return fmt.Errorf("failed to insert audit entry: %w", err)
+	}
+	return nil
+}
+
+// ListAuditEntries returns the entries matching the filter, newest first, and the total number of matches
+func (db *DB) ListAuditEntries(ctx context.Context, filter AuditFilter, limit, offset int) ([]AuditEntry, int, error) {
+	where, args := filter.where()
+
+	var total int
+	if err := db.ReadQueryRow(ctx, `SELECT COUNT(*) FROM audit_log `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit entries: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, actor, action, resource_type, resource_id, method, path, status,
+			COALESCE(request_id, ''), COALESCE(trace_id, ''), created_at
+		FROM audit_log
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d`, where, len(args)+1, len(args)+2)
+
+	rows, err := db.ReadQuery(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []AuditEntry{}
+	for rows.Next() {
+		var e AuditEntry
+		err := rows.Scan(&e.ID, &e.Actor, &e.Action, &e.ResourceType, &e.ResourceID, &e.Method, &e.Path, &e.Status,
+			&e.RequestID, &e.TraceID, &e.CreatedAt)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, total, rows.Err()
+}
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/maltedev/amazon-size-scraper/internal/analytics"
+)
+
+// CoverageReport is the size chart coverage of a random product sample
+type CoverageReport struct {
+	ID         string  `json:"id"`
+	JobID      *string `json:"job_id,omitempty"`
+	Category   string  `json:"category,omitempty"` // Category code, empty for all categories
+	SampleSize int     `json:"sample_size"`        // Requested, more than sampled when the category has fewer products
+	analytics.Coverage
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SampleSizeTables returns the size tables of up to n random scraped products of a category code, all
+// categories if it is empty. Products without a size table are returned as nil.
+func (db *DB) SampleSizeTables(ctx context.Context, category string, n int) ([]*SizeTable, error) {
+	query := `
+		SELECT size_table
+		FROM products
+		WHERE ($1 = '' OR category_code = $1)
+		  AND status NOT IN ('pending', 'processing', 'PENDING')
+		ORDER BY random()
+		LIMIT $2`
+
+	rows, err := db.ReadQuery(ctx, query, category, n)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample products: %w", err)
+	}
+	defer rows.Close()
+
+	var tables []*SizeTable
+	for rows.Next() {
+		var raw []byte
+		if err := rows.Scan(&raw); err != nil {
+			return nil, fmt.Errorf("failed to scan size table: %w", err)
+		}
+		var st *SizeTable
+		if len(raw) > 0 {
+			// Undecodable tables count as missing
+			if err := json.Unmarshal(raw, &st); err != nil {
+				st = nil
+			}
+		}
+		tables = append(tables, st)
+	}
+	return tables, rows.Err()
+}
+
+// InsertCoverageReport stores a coverage report, assigning its ID and creation time
+func (db *DB) InsertCoverageReport(ctx context.Context, r *CoverageReport) error {
+	r.ID = uuid.New().String()
+	r.CreatedAt = time.Now()
+	measurementTypes := r.MeasurementTypes
+	if measurementTypes == nil {
+		measurementTypes = map[string]int{}
+	}
+
+	query := `
+		INSERT INTO coverage_reports
+		(id, job_id, category, sample_size, sampled, with_size_table, with_length, with_width, complete, measurement_types, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+
+	_, err := db.Exec(ctx, query, r.ID, r.JobID, r.Category, r.SampleSize, r.Sampled, r.WithSizeTable,
+		r.WithLength, r.WithWidth, r.Complete, measurementTypes, r.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert coverage report: %w", err)
+	}
+	return nil
+}
+
+// ListCoverageReports returns the reports of a category code created after since, newest first. An empty
+// category returns the reports of all categories.
+func (db *DB) ListCoverageReports(ctx context.Context, category string, since time.Time, limit int) ([]*CoverageReport, error) {
+	query := `
+		SELECT id, job_id, category, sample_size, sampled, with_size_table, with_length, with_width, complete,
+			measurement_types, created_at
+		FROM coverage_reports
+		WHERE ($1 = '' OR category = $1) AND created_at >= $2
+		ORDER BY created_at DESC
+		LIMIT $3`
+
+	rows, err := db.ReadQuery(ctx, query, category, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list coverage reports: %w", err)
+	}
+	defer rows.Close()
+
+	reports := []*CoverageReport{}
+	for rows.Next() {
+		r := &CoverageReport{}
+		err := rows.Scan(&r.ID, &r.JobID, &r.Category, &r.SampleSize, &r.Sampled, &r.WithSizeTable, &r.WithLength,
+			&r.WithWidth, &r.Complete, &r.MeasurementTypes, &r.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan coverage report: %w", err)
+		}
+		reports = append(reports, r)
+	}
+	return reports, rows.Err()
+}
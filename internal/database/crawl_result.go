@@ -0,0 +1,26 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// UpsertCrawlResult stores the JSON of a product scraped by the crawler, replacing an earlier result
+func (db *DB) UpsertCrawlResult(ctx context.Context, asin, url string, data json.RawMessage, scrapedAt time.Time) error {
+	query := `
+		INSERT INTO crawl_results (asin, url, data, scraped_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (asin) DO UPDATE SET
+			url = EXCLUDED.url,
+			data = EXCLUDED.data,
+			scraped_at = EXCLUDED.scraped_at,
+			updated_at = CURRENT_TIMESTAMP`
+
+	if _, err := db.pool.Exec(ctx, query, asin, url, data, scrapedAt); err != nil {
+		return fmt.Errorf("failed to upsert crawl result: %w", err)
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -11,7 +12,8 @@ import (
 )
 
 type DB struct {
-	pool *pgxpool.Pool
+	pool     *pgxpool.Pool
+	replicas *replicaSet // Read replicas of ReadQuery and ReadQueryRow, nil without any
 }
 
 type Config struct {
@@ -24,6 +26,14 @@ type Config struct {
 	MinConns     int32
 	MaxConnLife  time.Duration
 	MaxConnIdle  time.Duration
+
+	StatementTimeout  time.Duration // Server side limit per statement, 0 keeps the server default
+	HealthCheckPeriod time.Duration // How often idle connections are checked, 0 uses the pgxpool default
+	ConnectRetries    int           // Retries of a failed connection attempt, on startup and when the pool reconnects
+	ConnectBackoff    time.Duration // Wait before the first retry, doubled per retry up to maxConnectBackoff
+
+	ReplicaDSNs     []string      // Read-only replicas for read queries, empty sends every query to the primary
+	ReplicaCooldown time.Duration // How long an unavailable replica is skipped, 0 uses defaultReplicaCooldown
 }
 
 func New(ctx context.Context, cfg Config) (*DB, error) {
@@ -35,11 +45,7 @@ func New(ctx context.Context, cfg Config) (*DB, error) {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
 
-	// Set pool configuration
-	poolConfig.MaxConns = cfg.MaxConns
-	poolConfig.MinConns = cfg.MinConns
-	poolConfig.MaxConnLifetime = cfg.MaxConnLife
-	poolConfig.MaxConnIdleTime = cfg.MaxConnIdle
+	configurePool(poolConfig, cfg)
 
 	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
@@ -52,10 +58,39 @@ func New(ctx context.Context, cfg Config) (*DB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return &DB{pool: pool}, nil
+	replicas, err := openReplicas(ctx, cfg)
+	if err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	return &DB{pool: pool, replicas: replicas}, nil
+}
+
+// configurePool applies the pool settings of cfg, shared by the primary and the replicas
+func configurePool(poolConfig *pgxpool.Config, cfg Config) {
+	poolConfig.MaxConns = cfg.MaxConns
+	poolConfig.MinConns = cfg.MinConns
+	poolConfig.MaxConnLifetime = cfg.MaxConnLife
+	poolConfig.MaxConnIdleTime = cfg.MaxConnIdle
+	if cfg.HealthCheckPeriod > 0 {
+		poolConfig.HealthCheckPeriod = cfg.HealthCheckPeriod
+	}
+	if cfg.StatementTimeout > 0 {
+		poolConfig.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10)
+	}
+
+	// Connections opened on startup or after a database restart are retried instead of failing the
+	// acquiring query
+	backoff := cfg.ConnectBackoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	poolConfig.ConnConfig.DialFunc = retryDial(poolConfig.ConnConfig.DialFunc, cfg.ConnectRetries, backoff)
 }
 
 func (db *DB) Close() {
+	db.replicas.close()
 	db.pool.Close()
 }
 
@@ -0,0 +1,114 @@
+//go:build integration
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// productFixture stands in for the product table owned by the product lifecycle service,
+// which migration 004 alters
+const productFixture = `
+CREATE TABLE IF NOT EXISTS product (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	asin VARCHAR(20) UNIQUE NOT NULL,
+	title TEXT,
+	status VARCHAR(20),
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW()
+)`
+
+// startPostgres runs a Postgres container for the test and returns its config
+func startPostgres(ctx context.Context, t *testing.T) Config {
+	t.Helper()
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "postgres:16-alpine",
+			ExposedPorts: []string{"5432/tcp"},
+			Env: map[string]string{
+				"POSTGRES_USER":     "postgres",
+				"POSTGRES_PASSWORD": "postgres",
+				"POSTGRES_DB":       "tall_affiliate_test",
+			},
+			WaitingFor: wait.ForLog("database system is ready to accept connections").
+				WithOccurrence(2).
+				WithStartupTimeout(60 * time.Second),
+		},
+		Started: true,
+	})
+	testcontainers.CleanupContainer(t, container)
+	require.NoError(t, err)
+
+	host, err := container.Host(ctx)
+	require.NoError(t, err)
+	port, err := container.MappedPort(ctx, "5432/tcp")
+	require.NoError(t, err)
+
+	return Config{
+		Host:     host,
+		Port:     port.Int(),
+		User:     "postgres",
+		Password: "postgres",
+		Database: "tall_affiliate_test",
+		MaxConns: 10,
+		MinConns: 1,
+	}
+}
+
+// setupIntegrationDB connects to a fresh Postgres container with all numbered up migrations applied
+func setupIntegrationDB(t *testing.T) *DB {
+	t.Helper()
+	ctx := context.Background()
+
+	db, err := New(ctx, startPostgres(ctx, t))
+	require.NoError(t, err)
+	t.Cleanup(db.Close)
+
+	_, err = db.Exec(ctx, productFixture)
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join("..", "..", "migrations", "[0-9]*.up.sql"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	sort.Strings(files)
+
+	for _, file := range files {
+		sql, err := os.ReadFile(file)
+		require.NoError(t, err)
+		_, err = db.Exec(ctx, string(sql))
+		require.NoError(t, err, "migration %s", filepath.Base(file))
+	}
+
+	return db
+}
+
+// insertPendingProducts stores n pending products with ASINs B000000000 onwards
+func insertPendingProducts(ctx context.Context, t *testing.T, db *DB, n int) []string {
+	t.Helper()
+
+	products := make([]*Product, n)
+	asins := make([]string, n)
+	for i := range products {
+		asins[i] = fmt.Sprintf("B%09d", i)
+		products[i] = &Product{
+			ASIN:   asins[i],
+			Title:  "Test Product " + asins[i],
+			URL:    "https://www.amazon.de/dp/" + asins[i],
+			Status: StatusPending,
+		}
+	}
+	require.NoError(t, db.InsertProducts(ctx, products))
+
+	return asins
+}
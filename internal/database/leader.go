@@ -0,0 +1,82 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// DefaultRelayLockKey is the advisory lock key relay instances elect their leader with
+const DefaultRelayLockKey int64 = 0x6f7574626f78 // "outbox"
+
+// AdvisoryLock elects a leader among the instances sharing a database with a session-level Postgres
+// advisory lock. The leader keeps the session on a connection taken out of the pool, the lock is released
+// when it resigns or the session ends, e.g. because the instance crashed.
+type AdvisoryLock struct {
+	db   *DB
+	key  int64
+	mu   sync.Mutex
+	conn *pgx.Conn // Session holding the lock, nil when not leading
+}
+
+// NewAdvisoryLock creates an election on the given lock key
+func NewAdvisoryLock(db *DB, key int64) *AdvisoryLock {
+	if key == 0 {
+		key = DefaultRelayLockKey
+	}
+	return &AdvisoryLock{db: db, key: key}
+}
+
+// Lead reports whether this instance holds the lock, trying to take it if it does not
+func (l *AdvisoryLock) Lead(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn != nil {
+		err := l.conn.Ping(ctx)
+		if err == nil {
+			return true, nil
+		}
+		// The session may be gone and its lock with it, only a new session can win it back
+		l.conn.Close(context.WithoutCancel(ctx))
+		l.conn = nil
+		return false, fmt.Errorf("lost leader session: %w", err)
+	}
+
+	pooled, err := l.db.pool.Acquire(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	var locked bool
+	if err := pooled.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", l.key).Scan(&locked); err != nil {
+		pooled.Release()
+		return false, fmt.Errorf("failed to try advisory lock: %w", err)
+	}
+	if !locked {
+		pooled.Release()
+		return false, nil
+	}
+
+	// The lock belongs to the session, so the connection must not go back to the pool
+	l.conn = pooled.Hijack()
+	return true, nil
+}
+
+// Resign releases the lock so another instance takes over without waiting for the session to end
+func (l *AdvisoryLock) Resign(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+	_, err := l.conn.Exec(ctx, "SELECT pg_advisory_unlock($1)", l.key)
+	l.conn.Close(ctx)
+	l.conn = nil
+	if err != nil {
+		return fmt.Errorf("failed to release advisory lock: %w", err)
+	}
+	return nil
+}
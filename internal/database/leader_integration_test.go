@@ -0,0 +1,100 @@
+//go:build integration
+
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdvisoryLock(t *testing.T) {
+	ctx := context.Background()
+	db, err := New(ctx, startPostgres(ctx, t))
+	require.NoError(t, err)
+	t.Cleanup(db.Close)
+
+	t.Run("acquire and keep the lock", func(t *testing.T) {
+		lock := NewAdvisoryLock(db, 1001)
+		t.Cleanup(func() { lock.Resign(ctx) })
+
+		leading, err := lock.Lead(ctx)
+		require.NoError(t, err)
+		assert.True(t, leading)
+
+		// Later polls keep the session instead of locking again
+		leading, err = lock.Lead(ctx)
+		require.NoError(t, err)
+		assert.True(t, leading)
+	})
+
+	t.Run("only one instance leads", func(t *testing.T) {
+		first := NewAdvisoryLock(db, 1002)
+		second := NewAdvisoryLock(db, 1002)
+		other := NewAdvisoryLock(db, 1003)
+		t.Cleanup(func() {
+			first.Resign(ctx)
+			second.Resign(ctx)
+			other.Resign(ctx)
+		})
+
+		leading, err := first.Lead(ctx)
+		require.NoError(t, err)
+		require.True(t, leading)
+
+		leading, err = second.Lead(ctx)
+		require.NoError(t, err)
+		assert.False(t, leading)
+
+		// Another key is a separate election
+		leading, err = other.Lead(ctx)
+		require.NoError(t, err)
+		assert.True(t, leading)
+
+		require.NoError(t, first.Resign(ctx))
+		leading, err = second.Lead(ctx)
+		require.NoError(t, err)
+		assert.True(t, leading)
+	})
+
+	t.Run("lose the lock with the session", func(t *testing.T) {
+		leader := NewAdvisoryLock(db, 1004)
+		follower := NewAdvisoryLock(db, 1004)
+		t.Cleanup(func() {
+			leader.Resign(ctx)
+			follower.Resign(ctx)
+		})
+
+		leading, err := leader.Lead(ctx)
+		require.NoError(t, err)
+		require.True(t, leading)
+
+		// Dropping the leader's connection ends its session and releases the lock
+		pid := leader.conn.PgConn().PID()
+		var terminated bool
+		require.NoError(t, db.QueryRow(ctx, "SELECT pg_terminate_backend($1)", pid).Scan(&terminated))
+		require.True(t, terminated)
+		require.Eventually(t, func() bool {
+			var alive bool
+			err := db.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM pg_stat_activity WHERE pid = $1)", pid).Scan(&alive)
+			return err == nil && !alive
+		}, 5*time.Second, 50*time.Millisecond)
+
+		leading, err = leader.Lead(ctx)
+		assert.Error(t, err)
+		assert.False(t, leading)
+		assert.Nil(t, leader.conn)
+
+		leading, err = follower.Lead(ctx)
+		require.NoError(t, err)
+		assert.True(t, leading)
+
+		// The former leader competes again with a new session
+		leading, err = leader.Lead(ctx)
+		require.NoError(t, err)
+		assert.False(t, leading)
+	})
+}
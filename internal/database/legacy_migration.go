@@ -0,0 +1,221 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// LegacyProductsMigration is the data_migrations entry of the conversion of legacy product rows
+const LegacyProductsMigration = "legacy_products"
+
+// LegacyProduct is a product row written by the deprecated InsertProduct and UpdateProduct* functions:
+// a size table without validation report, content hash or normalized measurements, and the material in
+// its own columns instead of the attributes
+type LegacyProduct struct {
+	ASIN                string
+	Title               string
+	Brand               string
+	Category            string
+	URL                 string
+	Status              ProductStatus
+	Rating              *float64
+	ReviewCount         *int
+	SizeTable           json.RawMessage
+	MaterialComposition json.RawMessage
+	MaterialFullText    string
+	Attributes          json.RawMessage
+}
+
+// LegacyConversion is a legacy product converted to the lifecycle columns
+type LegacyConversion struct {
+	ASIN        string
+	SizeTable   *SizeTable // Nil keeps the stored size table
+	Report      *ValidationReport
+	Attributes  map[string]string // Merged into the stored attributes, stored values win
+	ContentHash string
+	Events      []*OutboxEvent // Inserted with the conversion, e.g. NEW_PRODUCT_DETECTED
+}
+
+// MigrationState is the progress of a data migration
+type MigrationState struct {
+	Name        string     `json:"name"`
+	Watermark   string     `json:"watermark"` // Last ASIN committed, a run continues after it
+	Migrated    int        `json:"migrated"`
+	Skipped     int        `json:"skipped"`
+	Events      int        `json:"events"`
+	StartedAt   time.Time  `json:"started_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// GetMigrationState returns the progress of a data migration, nil if it never ran
+func (db *DB) GetMigrationState(ctx context.Context, name string) (*MigrationState, error) {
+	s := &MigrationState{Name: name}
+	err := db.pool.QueryRow(ctx, `
+		SELECT watermark, migrated, skipped, events, started_at, updated_at, completed_at
+		FROM data_migrations
+		WHERE name = $1`, name,
+	).Scan(&s.Watermark, &s.Migrated, &s.Skipped, &s.Events, &s.StartedAt, &s.UpdatedAt, &s.CompletedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get migration state: %w", err)
+	}
+	return s, nil
+}
+
+// ResetMigrationState forgets the progress of a data migration, its next run starts from the beginning
+func (db *DB) ResetMigrationState(ctx context.Context, name string) error {
+	if _, err := db.pool.Exec(ctx, `DELETE FROM data_migrations WHERE name = $1`, name); err != nil {
+		return fmt.Errorf("failed to reset migration state: %w", err)
+	}
+	return nil
+}
+
+// CompleteMigration records that a data migration reached the end of its rows
+func (db *DB) CompleteMigration(ctx context.Context, name string) error {
+	query := `
+		INSERT INTO data_migrations (name, completed_at) VALUES ($1, CURRENT_TIMESTAMP)
+		ON CONFLICT (name) DO UPDATE SET completed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP`
+	if _, err := db.pool.Exec(ctx, query, name); err != nil {
+		return fmt.Errorf("failed to complete migration: %w", err)
+	}
+	return nil
+}
+
+// ListLegacyProducts returns up to limit legacy products with an ASIN after the given one, ordered by
+// ASIN. Legacy rows have no content hash but a size table or material columns; converted rows carry a
+// content hash and are never listed again.
+func (db *DB) ListLegacyProducts(ctx context.Context, after string, limit int) ([]*LegacyProduct, error) {
+	query := `
+		SELECT asin, title, brand, category, url, status, rating, review_count,
+			   size_table, material_composition, material_full_text, attributes
+		FROM products
+		WHERE asin > $1
+		  AND content_hash IS NULL
+		  AND (size_table IS NOT NULL OR material_composition IS NOT NULL OR COALESCE(material_full_text, '') <> '')
+		ORDER BY asin
+		LIMIT $2`
+
+	rows, err := db.pool.Query(ctx, query, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query legacy products: %w", err)
+	}
+	defer rows.Close()
+
+	var products []*LegacyProduct
+	for rows.Next() {
+		p := &LegacyProduct{}
+		var brand, category, materialText sql.NullString
+		var sizeTable, material, attributes []byte
+		if err := rows.Scan(
+			&p.ASIN, &p.Title, &brand, &category, &p.URL, &p.Status, &p.Rating, &p.ReviewCount,
+			&sizeTable, &material, &materialText, &attributes,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan legacy product: %w", err)
+		}
+		p.Brand = brand.String
+		p.Category = category.String
+		p.MaterialFullText = materialText.String
+		if sizeTable != nil {
+			p.SizeTable = json.RawMessage(sizeTable)
+		}
+		if material != nil {
+			p.MaterialComposition = json.RawMessage(material)
+		}
+		if attributes != nil {
+			p.Attributes = json.RawMessage(attributes)
+		}
+		products = append(products, p)
+	}
+
+	return products, rows.Err()
+}
+
+// SaveLegacyConversions writes a batch of converted legacy products with their events and moves the
+// watermark of the migration to the last ASIN of the batch, all in one transaction. Rows converted in the
+// meantime, e.g. by a scrape, are left alone and their events dropped. Returns the rows and events written.
+func (db *DB) SaveLegacyConversions(ctx context.Context, name, watermark string, conversions []*LegacyConversion, skipped int) (migrated, published int, err error) {
+	outbox := NewOutboxRepository(db)
+	query := `
+		UPDATE products SET
+			size_table = COALESCE($2, size_table),
+			validation_report = COALESCE($3, validation_report),
+			quality_score = COALESCE($4, quality_score),
+			attributes = CASE WHEN $5::jsonb IS NULL THEN attributes ELSE $5::jsonb || COALESCE(attributes, '{}'::jsonb) END,
+			provenance = COALESCE(provenance, '{}'::jsonb) || COALESCE($6, '{}'::jsonb),
+			content_hash = $7,
+			last_checked_at = CURRENT_TIMESTAMP,
+			last_changed_at = COALESCE(last_changed_at, CURRENT_TIMESTAMP),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE asin = $1 AND content_hash IS NULL`
+
+	err = db.Transaction(ctx, func(tx pgx.Tx) error {
+		for _, c := range conversions {
+			var sizeJSON, reportJSON, attributesJSON []byte
+			var score *float64
+			var err error
+			provenance := Provenance{}
+			if c.SizeTable != nil {
+				if sizeJSON, err = json.Marshal(c.SizeTable); err != nil {
+					return fmt.Errorf("failed to marshal size table of %s: %w", c.ASIN, err)
+				}
+				provenance.RecordSizeTable(c.SizeTable, time.Now())
+			}
+			if c.Report != nil {
+				if reportJSON, err = json.Marshal(c.Report); err != nil {
+					return fmt.Errorf("failed to marshal validation report of %s: %w", c.ASIN, err)
+				}
+				score = &c.Report.Score
+			}
+			if len(c.Attributes) > 0 {
+				if attributesJSON, err = json.Marshal(c.Attributes); err != nil {
+					return fmt.Errorf("failed to marshal attributes of %s: %w", c.ASIN, err)
+				}
+			}
+
+			tag, err := tx.Exec(ctx, query, c.ASIN, sizeJSON, reportJSON, score, attributesJSON, provenance.JSON(), c.ContentHash)
+			if err != nil {
+				return fmt.Errorf("failed to migrate product %s: %w", c.ASIN, err)
+			}
+			if tag.RowsAffected() == 0 {
+				continue
+			}
+			migrated++
+
+			for _, event := range c.Events {
+				if err := outbox.InsertWithTx(ctx, tx, event); err != nil {
+					return fmt.Errorf("failed to insert outbox event: %w", err)
+				}
+				published++
+			}
+		}
+
+		_, err := tx.Exec(ctx, `
+			INSERT INTO data_migrations (name, watermark, migrated, skipped, events)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (name) DO UPDATE SET
+				watermark = EXCLUDED.watermark,
+				migrated = data_migrations.migrated + EXCLUDED.migrated,
+				skipped = data_migrations.skipped + EXCLUDED.skipped,
+				events = data_migrations.events + EXCLUDED.events,
+				updated_at = CURRENT_TIMESTAMP,
+				completed_at = NULL`,
+			name, watermark, migrated, skipped, published)
+		if err != nil {
+			return fmt.Errorf("failed to save migration watermark: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return migrated, published, nil
+}
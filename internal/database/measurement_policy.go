@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// MeasurementPolicy decides which size tables are good enough to keep a product. A measurement counts
+// when it is positive, extractors never store other values. Consumers that also want e.g. chest-only
+// products relax the policy.
+type MeasurementPolicy struct {
+	RequireLength bool `json:"require_length"`
+	RequireChest  bool `json:"require_chest"`
+	AcceptPartial bool `json:"accept_partial"` // Keep tables that have some but not all required measurements
+}
+
+// DefaultMeasurementPolicy requires length and chest in the same size, the rule of the job worker
+func DefaultMeasurementPolicy() MeasurementPolicy {
+	return MeasurementPolicy{RequireLength: true, RequireChest: true}
+}
+
+// LengthMeasurementPolicy only requires a length, the rule of the lifecycle consumer and scraper sizes
+func LengthMeasurementPolicy() MeasurementPolicy {
+	return MeasurementPolicy{RequireLength: true}
+}
+
+// AppliedMeasurementPolicy is the policy a product's size table was accepted under, stored with the product
+type AppliedMeasurementPolicy struct {
+	MeasurementPolicy
+	Satisfied bool `json:"satisfied"` // False if the table was only kept as partial
+}
+
+// JSON returns the applied policy for a JSONB column
+func (a AppliedMeasurementPolicy) JSON() json.RawMessage {
+	data, _ := json.Marshal(a)
+	return data
+}
+
+// Required returns the measurements the policy requires in one size
+func (p MeasurementPolicy) Required() []string {
+	var keys []string
+	if p.RequireLength {
+		keys = append(keys, "length")
+	}
+	if p.RequireChest {
+		keys = append(keys, "chest")
+	}
+	return keys
+}
+
+// Satisfied reports whether at least one size has every required measurement. Without requirements any
+// positive measurement satisfies the policy.
+func (p MeasurementPolicy) Satisfied(st *SizeTable) bool {
+	if st == nil || len(st.Sizes) == 0 {
+		return false
+	}
+	for _, m := range st.Measurements {
+		if !p.RequireLength && !p.RequireChest {
+			if hasAnyMeasurement(m) {
+				return true
+			}
+			continue
+		}
+		if p.RequireLength && m["length"] <= 0 {
+			continue
+		}
+		if p.RequireChest && m["chest"] <= 0 {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// Accepts reports whether a product with the size table is kept: the policy is satisfied, or partial
+// tables are accepted and some size has one of the required measurements
+func (p MeasurementPolicy) Accepts(st *SizeTable) bool {
+	if p.Satisfied(st) {
+		return true
+	}
+	if !p.AcceptPartial || st == nil || len(st.Sizes) == 0 {
+		return false
+	}
+	for _, m := range st.Measurements {
+		if (p.RequireLength && m["length"] > 0) || (p.RequireChest && m["chest"] > 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply returns the record of the policy for an accepted size table
+func (p MeasurementPolicy) Apply(st *SizeTable) AppliedMeasurementPolicy {
+	return AppliedMeasurementPolicy{MeasurementPolicy: p, Satisfied: p.Satisfied(st)}
+}
+
+// UpdateProductMeasurementPolicy records the policy a product's size table was accepted under
+func (db *DB) UpdateProductMeasurementPolicy(ctx context.Context, asin string, applied AppliedMeasurementPolicy) error {
+	query := `UPDATE products SET measurement_policy = $2 WHERE asin = $1`
+	if _, err := db.pool.Exec(ctx, query, asin, applied.JSON()); err != nil {
+		return fmt.Errorf("failed to update measurement policy: %w", err)
+	}
+	return nil
+}
+
+func hasAnyMeasurement(m map[string]float64) bool {
+	for _, v := range m {
+		if v > 0 {
+			return true
+		}
+	}
+	return false
+}
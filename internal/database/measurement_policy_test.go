@@ -0,0 +1,73 @@
+package database
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMeasurementPolicy(t *testing.T) {
+	table := func(measurements map[string]map[string]float64) *SizeTable {
+		st := &SizeTable{Measurements: measurements, Unit: "cm"}
+		for size := range measurements {
+			st.Sizes = append(st.Sizes, size)
+		}
+		return st
+	}
+	complete := table(map[string]map[string]float64{"M": {"chest": 100, "length": 72}})
+	split := table(map[string]map[string]float64{"M": {"chest": 100}, "L": {"length": 74}})
+	chestOnly := table(map[string]map[string]float64{"M": {"chest": 100, "waist": 90}})
+	zeroLength := table(map[string]map[string]float64{"M": {"chest": 100, "length": 0}})
+	waistOnly := table(map[string]map[string]float64{"M": {"waist": 90}})
+
+	length := LengthMeasurementPolicy()
+	relaxed := MeasurementPolicy{RequireChest: true}
+	partial := MeasurementPolicy{RequireLength: true, RequireChest: true, AcceptPartial: true}
+	none := MeasurementPolicy{}
+
+	tests := []struct {
+		name      string
+		policy    MeasurementPolicy
+		st        *SizeTable
+		satisfied bool
+		accepted  bool
+	}{
+		{"default complete", DefaultMeasurementPolicy(), complete, true, true},
+		{"default needs both in one size", DefaultMeasurementPolicy(), split, false, false},
+		{"default chest only", DefaultMeasurementPolicy(), chestOnly, false, false},
+		{"default zero length", DefaultMeasurementPolicy(), zeroLength, false, false},
+		{"length only", length, split, true, true},
+		{"length only chest only", length, chestOnly, false, false},
+		{"length only zero length", length, zeroLength, false, false},
+		{"chest required", relaxed, chestOnly, true, true},
+		{"chest required without chest", relaxed, waistOnly, false, false},
+		{"partial chest only", partial, chestOnly, false, true},
+		{"partial split", partial, split, false, true},
+		{"partial waist only", partial, waistOnly, false, false},
+		{"nothing required", none, waistOnly, true, true},
+		{"nil table", partial, nil, false, false},
+		{"empty table", none, &SizeTable{Measurements: map[string]map[string]float64{}}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.satisfied, tt.policy.Satisfied(tt.st))
+			assert.Equal(t, tt.accepted, tt.policy.Accepts(tt.st))
+		})
+	}
+}
+
+func TestAppliedMeasurementPolicyJSON(t *testing.T) {
+	partial := MeasurementPolicy{RequireLength: true, RequireChest: true, AcceptPartial: true}
+	chestOnly := &SizeTable{Sizes: []string{"M"}, Measurements: map[string]map[string]float64{"M": {"chest": 100}}}
+
+	var stored map[string]bool
+	assert.NoError(t, json.Unmarshal(partial.Apply(chestOnly).JSON(), &stored))
+	assert.Equal(t, map[string]bool{
+		"require_length": true,
+		"require_chest":  true,
+		"accept_partial": true,
+		"satisfied":      false,
+	}, stored)
+}
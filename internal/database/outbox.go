@@ -8,6 +8,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/maltedev/amazon-size-scraper/internal/eventroute"
 )
 
 const (
@@ -38,6 +39,9 @@ type OutboxEvent struct {
 	CreatedAt     time.Time       `db:"created_at"`
 	ProcessedAt   *time.Time      `db:"processed_at"`
 	NextRetryAt   *time.Time      `db:"next_retry_at"`
+	TraceID       string          `db:"trace_id"` // Correlates consumer logs with the emitting request or job
+	Metadata      Metadata        `db:"metadata"` // Caller metadata of the job or request, e.g. a campaign ID
+	Replayed      bool            `db:"-"`        // Published again by Relay.Replay, marked in the metadata
 }
 
 // OutboxRepository handles outbox event persistence
@@ -59,7 +63,7 @@ func (r *OutboxRepository) InsertWithTx(ctx context.Context, tx pgx.Tx, event *O
 		event.Status = OutboxStatusPending
 	}
 	if event.TargetStream == "" {
-		event.TargetStream = "stream:product_lifecycle"
+		event.TargetStream = eventroute.DefaultTarget
 	}
 
 	now := time.Now()
@@ -72,15 +76,15 @@ func (r *OutboxRepository) InsertWithTx(ctx context.Context, tx pgx.Tx, event *O
 		INSERT INTO outbox_event (
 			id, aggregate_type, aggregate_id, event_type, 
 			payload, target_stream, status, retry_count, 
-			created_at, next_retry_at
+			created_at, next_retry_at, trace_id, metadata
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NULLIF($11, ''), $12
 		)`
 
 	_, err := tx.Exec(ctx, query,
 		event.ID, event.AggregateType, event.AggregateID, event.EventType,
 		event.Payload, event.TargetStream, event.Status, event.RetryCount,
-		event.CreatedAt, event.NextRetryAt,
+		event.CreatedAt, event.NextRetryAt, event.TraceID, event.Metadata.JSON(),
 	)
 
 	if err != nil {
@@ -90,22 +94,30 @@ func (r *OutboxRepository) InsertWithTx(ctx context.Context, tx pgx.Tx, event *O
 	return nil
 }
 
-// GetPending retrieves pending events ready for processing
-func (r *OutboxRepository) GetPending(ctx context.Context, limit int) ([]*OutboxEvent, error) {
+// GetPending retrieves pending events ready for processing, skipping events of the excluded target
+// streams
+func (r *OutboxRepository) GetPending(ctx context.Context, limit int, excludeStreams []string) ([]*OutboxEvent, error) {
+	if excludeStreams == nil {
+		// A NULL array would exclude every event
+		excludeStreams = []string{}
+	}
+
 	query := `
 		SELECT 
 			id, aggregate_type, aggregate_id, event_type, 
 			payload, target_stream, status, retry_count, 
-			error_message, created_at, processed_at, next_retry_at
+			error_message, created_at, processed_at, next_retry_at,
+			COALESCE(trace_id, ''), metadata
 		FROM outbox_event
 		WHERE status IN ($1, $2)
 			AND next_retry_at <= $3
+			AND NOT (target_stream = ANY($5))
 		ORDER BY created_at ASC
 		LIMIT $4`
 
 	rows, err := r.db.pool.Query(ctx, query, 
 		OutboxStatusPending, OutboxStatusFailed, 
-		time.Now(), limit)
+		time.Now(), limit, excludeStreams)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get pending events: %w", err)
 	}
@@ -118,6 +130,7 @@ func (r *OutboxRepository) GetPending(ctx context.Context, limit int) ([]*Outbox
 			&event.ID, &event.AggregateType, &event.AggregateID, &event.EventType,
 			&event.Payload, &event.TargetStream, &event.Status, &event.RetryCount,
 			&event.ErrorMessage, &event.CreatedAt, &event.ProcessedAt, &event.NextRetryAt,
+			&event.TraceID, &event.Metadata,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan event: %w", err)
@@ -174,6 +187,10 @@ func (r *OutboxRepository) MarkFailed(ctx context.Context, id uuid.UUID, process
 	}
 
 	query := `
+		WITH history AS (
+			INSERT INTO outbox_event_error (event_id, attempt, error_message)
+			VALUES ($5, $2, $3)
+		)
 		UPDATE outbox_event 
 		SET status = $1, retry_count = $2, error_message = $3, next_retry_at = $4
 		WHERE id = $5`
@@ -186,6 +203,71 @@ func (r *OutboxRepository) MarkFailed(ctx context.Context, id uuid.UUID, process
 	return nil
 }
 
+// OutboxOutcome is the publish result of one event in a relay batch
+type OutboxOutcome struct {
+	ID         uuid.UUID
+	RetryCount int   // Retry count the event was fetched with
+	Err        error // Nil if the event was published
+}
+
+// MarkBatch records the outcomes of a relay batch in a single update, failures are scheduled for retry like MarkFailed
+func (r *OutboxRepository) MarkBatch(ctx context.Context, outcomes []OutboxOutcome) error {
+	if len(outcomes) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(outcomes))
+	statuses := make([]string, len(outcomes))
+	retryCounts := make([]int, len(outcomes))
+	errorMsgs := make([]*string, len(outcomes))
+	nextRetries := make([]*time.Time, len(outcomes))
+
+	for i, outcome := range outcomes {
+		ids[i] = outcome.ID.String()
+		statuses[i] = OutboxStatusProcessed
+		retryCounts[i] = outcome.RetryCount
+
+		if outcome.Err != nil {
+			retryCounts[i]++
+			errorMsg := outcome.Err.Error()
+			nextRetryAt := calculateNextRetryTime(retryCounts[i])
+
+			statuses[i] = OutboxStatusFailed
+			if retryCounts[i] >= MaxRetryCount {
+				statuses[i] = OutboxStatusDeadLetter
+			}
+			errorMsgs[i] = &errorMsg
+			nextRetries[i] = &nextRetryAt
+		}
+	}
+
+	// Failures are appended to the error history in the same statement
+	query := `
+		WITH history AS (
+			INSERT INTO outbox_event_error (event_id, attempt, error_message)
+			SELECT b.id::uuid, b.retry_count, b.error_message
+			FROM unnest($3::text[], $5::int[], $6::text[]) AS b(id, retry_count, error_message)
+			WHERE b.error_message IS NOT NULL
+		)
+		UPDATE outbox_event o
+		SET status = b.status,
+		    processed_at = CASE WHEN b.status = $1 THEN $2 ELSE o.processed_at END,
+		    retry_count = b.retry_count,
+		    error_message = COALESCE(b.error_message, o.error_message),
+		    next_retry_at = COALESCE(b.next_retry_at, o.next_retry_at)
+		FROM unnest($3::text[], $4::text[], $5::int[], $6::text[], $7::timestamptz[])
+		     AS b(id, status, retry_count, error_message, next_retry_at)
+		WHERE o.id = b.id::uuid`
+
+	_, err := r.db.pool.Exec(ctx, query, OutboxStatusProcessed, time.Now(),
+		ids, statuses, retryCounts, errorMsgs, nextRetries)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox batch: %w", err)
+	}
+
+	return nil
+}
+
 // calculateNextRetryTime calculates exponential backoff for retries
 func calculateNextRetryTime(retryCount int) time.Time {
 	// Exponential backoff: 1s, 2s, 4s, 8s, 16s...
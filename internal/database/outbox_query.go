@@ -0,0 +1,166 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrOutboxEventNotFound is returned when no outbox event has the requested ID
+var ErrOutboxEventNotFound = errors.New("outbox event not found")
+
+// OutboxFilter selects outbox events for inspection, zero fields match everything
+type OutboxFilter struct {
+	Status        string
+	EventType     string
+	AggregateID   string
+	AggregateIDs  []string // Matches any of them, combined with AggregateID
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	PayloadSearch string // Case-insensitive substring of the JSON payload
+}
+
+// OutboxEventError is one failed publish attempt of an outbox event
+type OutboxEventError struct {
+	Attempt    int       `json:"attempt"`
+	Message    string    `json:"message"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// where returns the SQL conditions of the filter and their arguments
+func (f OutboxFilter) where() (string, []any) {
+	var conds []string
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if f.Status != "" {
+		add("status = $%d", f.Status)
+	}
+	if f.EventType != "" {
+		add("event_type = $%d", f.EventType)
+	}
+	if f.AggregateID != "" {
+		add("aggregate_id = $%d", f.AggregateID)
+	}
+	if len(f.AggregateIDs) > 0 {
+		add("aggregate_id = ANY($%d)", f.AggregateIDs)
+	}
+	if !f.CreatedAfter.IsZero() {
+		add("created_at >= $%d", f.CreatedAfter)
+	}
+	if !f.CreatedBefore.IsZero() {
+		add("created_at < $%d", f.CreatedBefore)
+	}
+	if f.PayloadSearch != "" {
+		add("payload::text ILIKE '%%' || $%d || '%%'", escapeLike(f.PayloadSearch))
+	}
+
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conds, " AND "), args
+}
+
+// escapeLike makes wildcards in a search term match literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// List returns the events matching the filter, newest first, and the total number of matches
+func (r *OutboxRepository) List(ctx context.Context, filter OutboxFilter, limit, offset int) ([]*OutboxEvent, int, error) {
+	where, args := filter.where()
+
+	var total int
+	if err := r.db.ReadQueryRow(ctx, `SELECT COUNT(*) FROM outbox_event `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count outbox events: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT 
+			id, aggregate_type, aggregate_id, event_type, 
+			payload, target_stream, status, retry_count, 
+			error_message, created_at, processed_at, next_retry_at,
+			COALESCE(trace_id, ''), metadata
+		FROM outbox_event
+		%s
+		ORDER BY created_at DESC, id
+		LIMIT $%d OFFSET $%d`, where, len(args)+1, len(args)+2)
+
+	rows, err := r.db.ReadQuery(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*OutboxEvent
+	for rows.Next() {
+		event := &OutboxEvent{}
+		err := rows.Scan(
+			&event.ID, &event.AggregateType, &event.AggregateID, &event.EventType,
+			&event.Payload, &event.TargetStream, &event.Status, &event.RetryCount,
+			&event.ErrorMessage, &event.CreatedAt, &event.ProcessedAt, &event.NextRetryAt,
+			&event.TraceID, &event.Metadata,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan event: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	return events, total, rows.Err()
+}
+
+// Get returns an outbox event with its error history, oldest attempt first
+func (r *OutboxRepository) Get(ctx context.Context, id uuid.UUID) (*OutboxEvent, []OutboxEventError, error) {
+	query := `
+		SELECT 
+			id, aggregate_type, aggregate_id, event_type, 
+			payload, target_stream, status, retry_count, 
+			error_message, created_at, processed_at, next_retry_at,
+			COALESCE(trace_id, ''), metadata
+		FROM outbox_event
+		WHERE id = $1`
+
+	event := &OutboxEvent{}
+	err := r.db.ReadQueryRow(ctx, query, id).Scan(
+		&event.ID, &event.AggregateType, &event.AggregateID, &event.EventType,
+		&event.Payload, &event.TargetStream, &event.Status, &event.RetryCount,
+		&event.ErrorMessage, &event.CreatedAt, &event.ProcessedAt, &event.NextRetryAt,
+		&event.TraceID, &event.Metadata,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, fmt.Errorf("%w: %s", ErrOutboxEventNotFound, id)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get outbox event: %w", err)
+	}
+
+	rows, err := r.db.ReadQuery(ctx, `
+		SELECT attempt, error_message, occurred_at
+		FROM outbox_event_error
+		WHERE event_id = $1
+		ORDER BY attempt, id`, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get outbox event errors: %w", err)
+	}
+	defer rows.Close()
+
+	history := []OutboxEventError{}
+	for rows.Next() {
+		var e OutboxEventError
+		if err := rows.Scan(&e.Attempt, &e.Message, &e.OccurredAt); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan outbox event error: %w", err)
+		}
+		history = append(history, e)
+	}
+
+	return event, history, rows.Err()
+}
@@ -0,0 +1,138 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/maltedev/amazon-size-scraper/internal/eventroute"
+)
+
+// maxReplayErrors caps the publish errors a replay reports, the count covers all of them
+const maxReplayErrors = 10
+
+// ReplayOptions selects processed outbox events to publish again, e.g. for a consumer that lost data
+// and rebuilds its state from the scraper's events
+type ReplayOptions struct {
+	Filter OutboxFilter // Status is ignored, only processed events are replayed
+	Target string       // Target every event is published to, empty uses the original target of each event
+	Limit  int          // Stop after this many events, 0 replays all that match
+	DryRun bool         // Count the matching events without publishing them
+}
+
+// ReplayResult summarizes a replay
+type ReplayResult struct {
+	Matched   int      `json:"matched"`
+	Published int      `json:"published"`
+	Failed    int      `json:"failed"`
+	Errors    []string `json:"errors,omitempty"` // The first publish errors, with the event ID
+	DryRun    bool     `json:"dry_run,omitempty"`
+}
+
+// Replay publishes processed outbox events matching opts again, oldest first, in the relay's schema
+// version and with the replayed metadata flag. The events keep their ID and stay processed, a failed
+// publish is reported and not retried.
+func (r *Relay) Replay(ctx context.Context, opts ReplayOptions) (*ReplayResult, error) {
+	if opts.Target != "" {
+		target, err := eventroute.ParseTarget(opts.Target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid replay target: %w", err)
+		}
+		opts.Target = target.String()
+	}
+
+	filter := opts.Filter
+	filter.Status = OutboxStatusProcessed
+	where, args := filter.where()
+
+	result := &ReplayResult{DryRun: opts.DryRun}
+	var (
+		afterTime time.Time
+		afterID   uuid.UUID
+	)
+	for opts.Limit == 0 || result.Matched < opts.Limit {
+		size := r.batchSize
+		if opts.Limit > 0 {
+			size = min(size, opts.Limit-result.Matched)
+		}
+
+		query := fmt.Sprintf(`
+			SELECT
+				id, aggregate_type, aggregate_id, event_type,
+				payload, target_stream, status, retry_count,
+				error_message, created_at, processed_at, next_retry_at,
+				COALESCE(trace_id, ''), metadata
+			FROM outbox_event
+			%s AND (created_at, id) > ($%d, $%d)
+			ORDER BY created_at, id
+			LIMIT $%d`, where, len(args)+1, len(args)+2, len(args)+3)
+
+		events, err := r.replayBatch(ctx, query, append(args, afterTime, afterID, size)...)
+		if err != nil {
+			return result, err
+		}
+		if len(events) == 0 {
+			break
+		}
+		last := events[len(events)-1]
+		afterTime, afterID = last.CreatedAt, last.ID
+		result.Matched += len(events)
+		if opts.DryRun {
+			continue
+		}
+
+		for _, event := range events {
+			event.Replayed = true
+			if opts.Target != "" {
+				event.TargetStream = opts.Target
+			}
+		}
+		for i, outcome := range r.publishBatch(ctx, events) {
+			if outcome.Err == nil {
+				result.Published++
+				continue
+			}
+			result.Failed++
+			if len(result.Errors) < maxReplayErrors {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", events[i].ID, outcome.Err))
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+	}
+
+	r.logger.InfoContext(ctx, "outbox events replayed",
+		"matched", result.Matched,
+		"published", result.Published,
+		"failed", result.Failed,
+		"target", opts.Target,
+		"dry_run", opts.DryRun)
+	return result, nil
+}
+
+// replayBatch reads one page of events to replay
+func (r *Relay) replayBatch(ctx context.Context, query string, args ...any) ([]*OutboxEvent, error) {
+	rows, err := r.db.ReadQuery(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events to replay: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*OutboxEvent
+	for rows.Next() {
+		event := &OutboxEvent{}
+		err := rows.Scan(
+			&event.ID, &event.AggregateType, &event.AggregateID, &event.EventType,
+			&event.Payload, &event.TargetStream, &event.Status, &event.RetryCount,
+			&event.ErrorMessage, &event.CreatedAt, &event.ProcessedAt, &event.NextRetryAt,
+			&event.TraceID, &event.Metadata,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
@@ -28,7 +28,7 @@ func TestOutboxRepository_InsertWithTx(t *testing.T) {
 			TargetStream:  "stream:product_lifecycle",
 		}
 
-		err := db.pool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		err := pgx.BeginFunc(ctx, db.pool, func(tx pgx.Tx) error {
 			return repo.InsertWithTx(ctx, tx, event)
 		})
 
@@ -49,7 +49,7 @@ func TestOutboxRepository_InsertWithTx(t *testing.T) {
 		}
 
 		// Start transaction that will be rolled back
-		err := db.pool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		err := pgx.BeginFunc(ctx, db.pool, func(tx pgx.Tx) error {
 			if err := repo.InsertWithTx(ctx, tx, event); err != nil {
 				return err
 			}
@@ -60,7 +60,7 @@ func TestOutboxRepository_InsertWithTx(t *testing.T) {
 		assert.Error(t, err)
 
 		// Verify event was not persisted
-		events, err := repo.GetPending(ctx, 10)
+		events, err := repo.GetPending(ctx, 10, nil)
 		require.NoError(t, err)
 		for _, e := range events {
 			assert.NotEqual(t, "B002TEST", e.AggregateID)
@@ -103,7 +103,7 @@ func TestOutboxRepository_InsertWithTx(t *testing.T) {
 
 		for _, tc := range testCases {
 			t.Run(tc.name, func(t *testing.T) {
-				err := db.pool.BeginFunc(ctx, func(tx pgx.Tx) error {
+				err := pgx.BeginFunc(ctx, db.pool, func(tx pgx.Tx) error {
 					return repo.InsertWithTx(ctx, tx, tc.event)
 				})
 				assert.Error(t, err)
@@ -162,14 +162,14 @@ func TestOutboxRepository_GetPending(t *testing.T) {
 	}
 
 	for _, event := range events {
-		err := db.pool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		err := pgx.BeginFunc(ctx, db.pool, func(tx pgx.Tx) error {
 			return repo.InsertWithTx(ctx, tx, event)
 		})
 		require.NoError(t, err)
 	}
 
 	t.Run("get pending events with limit", func(t *testing.T) {
-		pending, err := repo.GetPending(ctx, 2)
+		pending, err := repo.GetPending(ctx, 2, nil)
 		require.NoError(t, err)
 		assert.Len(t, pending, 2)
 		
@@ -180,7 +180,7 @@ func TestOutboxRepository_GetPending(t *testing.T) {
 	})
 
 	t.Run("get pending events ordered by created_at", func(t *testing.T) {
-		pending, err := repo.GetPending(ctx, 10)
+		pending, err := repo.GetPending(ctx, 10, nil)
 		require.NoError(t, err)
 		
 		// Verify ordering
@@ -198,7 +198,7 @@ func TestOutboxRepository_GetPending(t *testing.T) {
 			future, "B004TEST")
 		require.NoError(t, err)
 
-		pending, err := repo.GetPending(ctx, 10)
+		pending, err := repo.GetPending(ctx, 10, nil)
 		require.NoError(t, err)
 		
 		// Should not include the event with future retry time
@@ -224,7 +224,7 @@ func TestOutboxRepository_MarkProcessed(t *testing.T) {
 		TargetStream:  "stream:product_lifecycle",
 	}
 
-	err := db.pool.BeginFunc(ctx, func(tx pgx.Tx) error {
+	err := pgx.BeginFunc(ctx, db.pool, func(tx pgx.Tx) error {
 		return repo.InsertWithTx(ctx, tx, event)
 	})
 	require.NoError(t, err)
@@ -268,7 +268,7 @@ func TestOutboxRepository_MarkFailed(t *testing.T) {
 			TargetStream:  "stream:product_lifecycle",
 		}
 
-		err := db.pool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		err := pgx.BeginFunc(ctx, db.pool, func(tx pgx.Tx) error {
 			return repo.InsertWithTx(ctx, tx, event)
 		})
 		require.NoError(t, err)
@@ -304,7 +304,7 @@ func TestOutboxRepository_MarkFailed(t *testing.T) {
 			RetryCount:    4, // One below max
 		}
 
-		err := db.pool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		err := pgx.BeginFunc(ctx, db.pool, func(tx pgx.Tx) error {
 			return repo.InsertWithTx(ctx, tx, event)
 		})
 		require.NoError(t, err)
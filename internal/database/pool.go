@@ -0,0 +1,126 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// maxConnectBackoff caps the doubled wait between connection retries
+const maxConnectBackoff = 30 * time.Second
+
+// PoolStats is a snapshot of the connection pool for health checks and metrics
+type PoolStats struct {
+	InUse           int32         `json:"in_use"`
+	Idle            int32         `json:"idle"`
+	Total           int32         `json:"total"`
+	Max             int32         `json:"max"`
+	Acquires        int64         `json:"acquires"`
+	AcquireWait     time.Duration `json:"acquire_wait_ns"`
+	EmptyAcquires   int64         `json:"empty_acquires"` // Acquires that had to wait for a connection
+	EmptyWait       time.Duration `json:"empty_acquire_wait_ns"`
+	CanceledAcquire int64         `json:"canceled_acquires"`
+	NewConns        int64         `json:"new_conns"`
+}
+
+// Stats returns the current pool statistics
+func (db *DB) Stats() PoolStats {
+	return poolStats(db.pool)
+}
+
+func poolStats(pool *pgxpool.Pool) PoolStats {
+	s := pool.Stat()
+	return PoolStats{
+		InUse:           s.AcquiredConns(),
+		Idle:            s.IdleConns(),
+		Total:           s.TotalConns(),
+		Max:             s.MaxConns(),
+		Acquires:        s.AcquireCount(),
+		AcquireWait:     s.AcquireDuration(),
+		EmptyAcquires:   s.EmptyAcquireCount(),
+		EmptyWait:       s.EmptyAcquireWaitTime(),
+		CanceledAcquire: s.CanceledAcquireCount(),
+		NewConns:        s.NewConnsCount(),
+	}
+}
+
+// WriteMetrics writes the pool statistics in the Prometheus text format
+func (db *DB) WriteMetrics(w io.Writer) {
+	s := db.Stats()
+
+	gauges := []struct {
+		name, help string
+		value      int32
+	}{
+		{"scraper_db_pool_in_use_conns", "Connections currently acquired by queries.", s.InUse},
+		{"scraper_db_pool_idle_conns", "Idle connections in the pool.", s.Idle},
+		{"scraper_db_pool_total_conns", "Open connections, including ones being established.", s.Total},
+		{"scraper_db_pool_max_conns", "Maximum size of the pool.", s.Max},
+	}
+	for _, g := range gauges {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %d\n", g.name, g.help, g.name, g.name, g.value)
+	}
+
+	counters := []struct {
+		name, help string
+		value      float64
+	}{
+		{"scraper_db_pool_acquires_total", "Connections acquired from the pool.", float64(s.Acquires)},
+		{"scraper_db_pool_acquire_wait_seconds_total", "Time spent acquiring connections.", s.AcquireWait.Seconds()},
+		{"scraper_db_pool_empty_acquires_total", "Acquires that waited because the pool was exhausted.", float64(s.EmptyAcquires)},
+		{"scraper_db_pool_empty_acquire_wait_seconds_total", "Time spent waiting for a connection of an exhausted pool.", s.EmptyWait.Seconds()},
+		{"scraper_db_pool_canceled_acquires_total", "Acquires canceled by their context.", float64(s.CanceledAcquire)},
+		{"scraper_db_pool_new_conns_total", "Connections opened, reconnects included.", float64(s.NewConns)},
+	}
+	for _, c := range counters {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %g\n", c.name, c.help, c.name, c.name, c.value)
+	}
+
+	db.writeReplicaMetrics(w)
+}
+
+// retryDial wraps dial so a refused or unreachable database is retried with backoff
+func retryDial(dial pgconn.DialFunc, retries int, backoff time.Duration) pgconn.DialFunc {
+	if retries <= 0 {
+		return dial
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		var conn net.Conn
+		err := retry(ctx, retries, backoff, func() error {
+			var err error
+			conn, err = dial(ctx, network, addr)
+			return err
+		})
+		return conn, err
+	}
+}
+
+// retry calls fn until it succeeds, retries are used up or ctx is done, doubling the wait each time
+func retry(ctx context.Context, retries int, backoff time.Duration, fn func() error) error {
+	err := fn()
+	for attempt := 1; err != nil && attempt <= retries; attempt++ {
+		slog.Default().WarnContext(ctx, "database connection failed, retrying",
+			"component", "database",
+			"attempt", attempt,
+			"backoff", backoff,
+			"error", err)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		backoff = min(backoff*2, maxConnectBackoff)
+		err = fn()
+	}
+	return err
+}
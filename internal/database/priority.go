@@ -0,0 +1,152 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// PriorityWeights controls how much each signal contributes to a product's priority score
+type PriorityWeights struct {
+	Category    float64
+	Keywords    float64
+	ReviewCount float64
+	Rating      float64
+}
+
+// Prioritizer scores pending products by how likely they are to have a size table
+type Prioritizer struct {
+	weights            PriorityWeights
+	clothingCategories []string
+	apparelKeywords    []string
+}
+
+// DefaultPriorityWeights returns the weights used by NewPrioritizer
+func DefaultPriorityWeights() PriorityWeights {
+	return PriorityWeights{
+		Category:    40,
+		Keywords:    30,
+		ReviewCount: 20,
+		Rating:      10,
+	}
+}
+
+// NewPrioritizer creates a prioritizer with the default clothing categories and apparel keywords
+func NewPrioritizer() *Prioritizer {
+	return NewPrioritizerWithWeights(DefaultPriorityWeights())
+}
+
+// NewPrioritizerWithWeights creates a prioritizer with custom weights
+func NewPrioritizerWithWeights(weights PriorityWeights) *Prioritizer {
+	return &Prioritizer{
+		weights: weights,
+		clothingCategories: []string{
+			"bekleidung", "kleidung", "fashion", "clothing", "apparel",
+			"t-shirt", "shirt", "hemd", "hose", "jacke", "pullover",
+		},
+		apparelKeywords: []string{
+			"t-shirt", "tshirt", "shirt", "hemd", "polo", "hoodie", "pullover",
+			"sweatshirt", "jacke", "hose", "jeans", "kleid", "longsleeve",
+			"langarm", "kurzarm", "regular fit", "slim fit", "oversize",
+		},
+	}
+}
+
+// Score returns a priority score between 0 and 100 for a product
+func (pr *Prioritizer) Score(p *Product) float64 {
+	if p == nil {
+		return 0
+	}
+
+	score := 0.0
+
+	if p.Category.Valid && containsAny(strings.ToLower(p.Category.String), pr.clothingCategories) {
+		score += pr.weights.Category
+	}
+
+	title := strings.ToLower(p.Title)
+	matches := 0
+	for _, keyword := range pr.apparelKeywords {
+		if strings.Contains(title, keyword) {
+			matches++
+		}
+	}
+	if matches > 0 {
+		// First keyword match counts most, additional matches add a little confidence
+		score += pr.weights.Keywords * math.Min(1, 0.7+0.15*float64(matches-1))
+	}
+
+	if p.ReviewCount.Valid && p.ReviewCount.Int32 > 0 {
+		// Logarithmic scale: 10 reviews ~ 1/3, 1000+ reviews = full weight
+		score += pr.weights.ReviewCount * math.Min(1, math.Log10(float64(p.ReviewCount.Int32)+1)/3)
+	}
+
+	if p.Rating.Valid && p.Rating.Float64 > 0 {
+		score += pr.weights.Rating * math.Min(1, p.Rating.Float64/5)
+	}
+
+	return math.Round(score*100) / 100
+}
+
+// containsAny checks if s contains any of the given substrings
+func containsAny(s string, substrings []string) bool {
+	for _, sub := range substrings {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdatePriorityScores recalculates the priority score of all pending products
+func (db *DB) UpdatePriorityScores(ctx context.Context, pr *Prioritizer) (int, error) {
+	query := `
+		SELECT asin, title, brand, category, rating, review_count
+		FROM products
+		WHERE status = $1`
+
+	rows, err := db.pool.Query(ctx, query, StatusPending)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query pending products: %w", err)
+	}
+
+	var products []*Product
+	for rows.Next() {
+		p := &Product{}
+		if err := rows.Scan(&p.ASIN, &p.Title, &p.Brand, &p.Category, &p.Rating, &p.ReviewCount); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan product: %w", err)
+		}
+		products = append(products, p)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	if len(products) == 0 {
+		return 0, nil
+	}
+
+	asins := make([]string, len(products))
+	scores := make([]float64, len(products))
+	for i, p := range products {
+		asins[i] = p.ASIN
+		scores[i] = pr.Score(p)
+	}
+
+	// One statement for all products instead of a round trip per row
+	tag, err := db.pool.Exec(ctx, `
+		UPDATE products SET priority_score = s.score
+		FROM unnest($1::text[], $2::float8[]) AS s(asin, score)
+		WHERE products.asin = s.asin`,
+		asins, scores,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update priority scores: %w", err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,52 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrioritizer_Score(t *testing.T) {
+	pr := NewPrioritizer()
+
+	t.Run("apparel product scores higher than non-apparel", func(t *testing.T) {
+		shirt := &Product{
+			Title:    "Herren T-Shirt Regular Fit aus Baumwolle",
+			Category: sql.NullString{String: "Bekleidung", Valid: true},
+		}
+		mug := &Product{
+			Title:    "Kaffeebecher aus Keramik",
+			Category: sql.NullString{String: "Küche", Valid: true},
+		}
+
+		assert.Greater(t, pr.Score(shirt), pr.Score(mug))
+		assert.Equal(t, 0.0, pr.Score(mug))
+	})
+
+	t.Run("reviews and rating increase score", func(t *testing.T) {
+		base := &Product{Title: "Basic T-Shirt"}
+		reviewed := &Product{
+			Title:       "Basic T-Shirt",
+			Rating:      sql.NullFloat64{Float64: 4.5, Valid: true},
+			ReviewCount: sql.NullInt32{Int32: 1200, Valid: true},
+		}
+
+		assert.Greater(t, pr.Score(reviewed), pr.Score(base))
+	})
+
+	t.Run("score is capped at total weight", func(t *testing.T) {
+		p := &Product{
+			Title:       "Oversize Hoodie Pullover Sweatshirt Langarm Slim Fit T-Shirt",
+			Category:    sql.NullString{String: "Fashion", Valid: true},
+			Rating:      sql.NullFloat64{Float64: 5, Valid: true},
+			ReviewCount: sql.NullInt32{Int32: 100000, Valid: true},
+		}
+
+		assert.LessOrEqual(t, pr.Score(p), 100.0)
+	})
+
+	t.Run("nil product", func(t *testing.T) {
+		assert.Equal(t, 0.0, pr.Score(nil))
+	})
+}
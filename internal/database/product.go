@@ -4,18 +4,29 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/maltedev/amazon-size-scraper/internal/lifecycle"
+	"github.com/maltedev/amazon-size-scraper/internal/models"
 )
 
-type ProductStatus string
+// ProductStatus is a product status, the allowed transitions between them are defined in package lifecycle
+type ProductStatus = lifecycle.State
 
 const (
-	StatusPending   ProductStatus = "pending"
-	StatusCompleted ProductStatus = "completed"
-	StatusFailed    ProductStatus = "failed"
+	StatusPending    = lifecycle.Pending
+	StatusProcessing = lifecycle.Processing // Claimed by a size-scraper worker, see ClaimPendingProducts
+	StatusCompleted  = lifecycle.Completed
+	StatusFailed     = lifecycle.Failed
+	StatusRetired    = lifecycle.Retired // Listing deleted on Amazon, never claimed again, see RetireProduct
+	StatusActive     = lifecycle.Active
+	StatusRejected   = lifecycle.Rejected
+	StatusDiscovered = lifecycle.Discovered
+	StatusScraped    = lifecycle.Scraped
 )
 
 type Product struct {
@@ -23,10 +34,20 @@ type Product struct {
 	Title        string          `db:"title"`
 	Brand        sql.NullString  `db:"brand"`
 	Category     sql.NullString  `db:"category"`
+	CategoryCode sql.NullString  `db:"category_code"`
 	URL          string          `db:"url"`
 	SizeTable    json.RawMessage `db:"size_table"`
+	SizePrices   json.RawMessage `db:"size_prices"`
+	Provenance   json.RawMessage `db:"provenance"` // Origin per major field, see Provenance
+	Attributes   json.RawMessage `db:"attributes"` // Product overview attributes by canonical key
+	Metadata     json.RawMessage `db:"metadata"`   // Caller metadata of the jobs and requests that stored it
 	Status       ProductStatus   `db:"status"`
 	ErrorMessage sql.NullString  `db:"error_message"`
+	Screenshot   sql.NullString  `db:"error_screenshot"`
+	DOMSnippet   sql.NullString  `db:"error_dom_snippet"`
+	Rating       sql.NullFloat64 `db:"rating"`
+	ReviewCount  sql.NullInt32   `db:"review_count"`
+	Priority     float64         `db:"priority_score"`
 	ScrapedAt    sql.NullTime    `db:"scraped_at"`
 	CreatedAt    time.Time       `db:"created_at"`
 	UpdatedAt    time.Time       `db:"updated_at"`
@@ -36,24 +57,103 @@ type SizeTable struct {
 	Sizes        []string                       `json:"sizes"`
 	Measurements map[string]map[string]float64  `json:"measurements"`
 	Unit         string                        `json:"unit"`
+	Source       string                        `json:"source,omitempty"`     // One of the SizeTableSource constants
+	Confidence   float64                       `json:"confidence,omitempty"` // 1.0 for HTML tables, lower for OCR
+	Conversions  map[string]map[string]string  `json:"size_conversions,omitempty"` // Size -> size system -> size in that system
+	System       string                        `json:"size_system,omitempty"`      // Size system of Sizes, e.g. "INT", "EU" or "AGE" and "HEIGHT" for children's charts
 }
 
+// HasMeasurements reports whether any size of the table has a measurement. Tables of sizes alone, such as
+// care or spec tables parsed as size tables, have none.
+func (st *SizeTable) HasMeasurements() bool {
+	for _, m := range st.Measurements {
+		if len(m) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// AddConversion records that size equals value in the size system, e.g. "M" is "50" in "DE"
+func (st *SizeTable) AddConversion(size, system, value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+	if st.Conversions == nil {
+		st.Conversions = make(map[string]map[string]string)
+	}
+	if st.Conversions[size] == nil {
+		st.Conversions[size] = make(map[string]string)
+	}
+	st.Conversions[size][system] = value
+}
+
+// Size table sources
+const (
+	SizeTableSourceHTML   = "html"   // Größentabelle popover or modal
+	SizeTableSourceInline = "inline" // Table in the product description
+	SizeTableSourceAPlus  = "aplus"  // Table in A+ content
+	SizeTableSourceOCR    = "ocr"
+	SizeTableSourceManual = "manual" // Corrected table uploaded by a merchandiser
+
+	SizeTableSourceDescriptionText = "description_text" // Measurements mentioned in bullet points or description prose
+)
+
+// OCRConfidence is the confidence assigned to size tables recognized from images
+const OCRConfidence = 0.6
+
+// DescriptionTextConfidence is the confidence assigned to partial size tables assembled from description text
+const DescriptionTextConfidence = 0.4
+
+// FitFeedback is the customer fit rating of the "Passform" widget of a product page
+type FitFeedback struct {
+	Summary    string  `json:"summary"`              // As shown, e.g. "Fällt normal aus"
+	Fit        string  `json:"fit,omitempty"`        // One of the Fit constants
+	TooSmall   float64 `json:"too_small_percent"`    // Share of ratings saying the product runs small
+	TrueToSize float64 `json:"true_to_size_percent"` // Share of ratings saying it fits as expected
+	TooLarge   float64 `json:"too_large_percent"`    // Share of ratings saying it runs large
+	Ratings    int     `json:"ratings,omitempty"`    // Number of fit ratings
+}
+
+// SizeOffer is the offer of one size of a size-variant product
+type SizeOffer struct {
+	Price     float64 `json:"price,omitempty"` // 0 when the size shows no price
+	Currency  string  `json:"currency,omitempty"`
+	Available bool    `json:"available"`
+	ASIN      string  `json:"asin,omitempty"` // Child ASIN of the size variant
+}
+
+// SizePrices maps the sizes of a product to their offers
+type SizePrices map[string]SizeOffer
+
+// Fit ratings of FitFeedback
+const (
+	FitSmall      = "small"
+	FitTrueToSize = "true_to_size"
+	FitLarge      = "large"
+)
+
 // InsertProduct inserts a new product or updates if exists
 // Deprecated: Use InsertProductLifecycle for the new product table
 func (db *DB) InsertProduct(ctx context.Context, p *Product) error {
 	query := `
-		INSERT INTO products (asin, title, brand, category, url, status)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO products (asin, title, brand, category, url, status, rating, review_count, priority_score)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		ON CONFLICT (asin) DO UPDATE SET
 			title = EXCLUDED.title,
 			brand = EXCLUDED.brand,
 			category = EXCLUDED.category,
 			url = EXCLUDED.url,
+			rating = COALESCE(EXCLUDED.rating, products.rating),
+			review_count = COALESCE(EXCLUDED.review_count, products.review_count),
+			priority_score = EXCLUDED.priority_score,
 			updated_at = CURRENT_TIMESTAMP
 		RETURNING created_at, updated_at`
 
 	err := db.pool.QueryRow(ctx, query,
 		p.ASIN, p.Title, p.Brand, p.Category, p.URL, p.Status,
+		p.Rating, p.ReviewCount, p.Priority,
 	).Scan(&p.CreatedAt, &p.UpdatedAt)
 
 	if err != nil {
@@ -63,6 +163,98 @@ func (db *DB) InsertProduct(ctx context.Context, p *Product) error {
 	return nil
 }
 
+// InsertProducts is the batched InsertProduct, it upserts all products in one statement and fills
+// their timestamps. A product listed twice is written once with its last values.
+func (db *DB) InsertProducts(ctx context.Context, products []*Product) error {
+	byASIN := make(map[string]*Product, len(products))
+	var asins, titles, urls, statuses []string
+	var brands, categories []*string
+	var ratings []*float64
+	var priorities []float64
+	var reviewCounts []*int32
+	for i := len(products) - 1; i >= 0; i-- {
+		p := products[i]
+		if _, dup := byASIN[p.ASIN]; dup {
+			continue
+		}
+		byASIN[p.ASIN] = p
+
+		asins = append(asins, p.ASIN)
+		titles = append(titles, p.Title)
+		urls = append(urls, p.URL)
+		statuses = append(statuses, string(p.Status))
+		brands = append(brands, nullString(p.Brand))
+		categories = append(categories, nullString(p.Category))
+		ratings = append(ratings, nullFloat(p.Rating))
+		reviewCounts = append(reviewCounts, nullInt32(p.ReviewCount))
+		priorities = append(priorities, p.Priority)
+	}
+	if len(asins) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO products (asin, title, brand, category, url, status, rating, review_count, priority_score)
+		SELECT * FROM unnest($1::text[], $2::text[], $3::text[], $4::text[], $5::text[], $6::text[],
+			$7::numeric[], $8::int[], $9::float8[])
+		ON CONFLICT (asin) DO UPDATE SET
+			title = EXCLUDED.title,
+			brand = EXCLUDED.brand,
+			category = EXCLUDED.category,
+			url = EXCLUDED.url,
+			rating = COALESCE(EXCLUDED.rating, products.rating),
+			review_count = COALESCE(EXCLUDED.review_count, products.review_count),
+			priority_score = EXCLUDED.priority_score,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING asin, created_at, updated_at`
+
+	rows, err := db.pool.Query(ctx, query,
+		asins, titles, brands, categories, urls, statuses, ratings, reviewCounts, priorities)
+	if err != nil {
+		return fmt.Errorf("failed to insert products: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var asin string
+		var createdAt, updatedAt time.Time
+		if err := rows.Scan(&asin, &createdAt, &updatedAt); err != nil {
+			return fmt.Errorf("failed to scan inserted product: %w", err)
+		}
+		for _, p := range products {
+			if p.ASIN == asin {
+				p.CreatedAt, p.UpdatedAt = createdAt, updatedAt
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to insert products: %w", err)
+	}
+
+	return nil
+}
+
+func nullString(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
+
+func nullFloat(f sql.NullFloat64) *float64 {
+	if !f.Valid {
+		return nil
+	}
+	return &f.Float64
+}
+
+func nullInt32(i sql.NullInt32) *int32 {
+	if !i.Valid {
+		return nil
+	}
+	return &i.Int32
+}
+
 // UpdateProductSizes updates the size data for a product
 // Deprecated: Use UpdateProductLifecycleSizeTable for the new product table
 func (db *DB) UpdateProductSizes(ctx context.Context, asin string, sizeTable *SizeTable) error {
@@ -75,6 +267,8 @@ func (db *DB) UpdateProductSizes(ctx context.Context, asin string, sizeTable *Si
 		UPDATE products SET
 			size_table = $2,
 			status = $3,
+			claimed_by = NULL,
+			lease_expires_at = NULL,
 			scraped_at = CURRENT_TIMESTAMP,
 			updated_at = CURRENT_TIMESTAMP
 		WHERE asin = $1`
@@ -146,6 +340,8 @@ func (db *DB) UpdateProductWithMaterialAndSize(ctx context.Context, asin string,
 			material_composition = $3,
 			material_full_text = $4,
 			status = $5,
+			claimed_by = NULL,
+			lease_expires_at = NULL,
 			scraped_at = CURRENT_TIMESTAMP,
 			updated_at = CURRENT_TIMESTAMP
 		WHERE asin = $1`
@@ -161,6 +357,21 @@ func (db *DB) UpdateProductWithMaterialAndSize(ctx context.Context, asin string,
 	return nil
 }
 
+// UpdateProductStageTimings stores the milliseconds spent per extraction stage of the last scrape
+func (db *DB) UpdateProductStageTimings(ctx context.Context, asin string, timings map[string]int64) error {
+	timingsJSON, err := json.Marshal(timings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal stage timings: %w", err)
+	}
+
+	query := `UPDATE products SET stage_timings = $2 WHERE asin = $1`
+	if _, err := db.pool.Exec(ctx, query, asin, timingsJSON); err != nil {
+		return fmt.Errorf("failed to update stage timings: %w", err)
+	}
+
+	return nil
+}
+
 // UpdateProductStatus updates the status and error message
 // Deprecated: Use product lifecycle table methods instead
 func (db *DB) UpdateProductStatus(ctx context.Context, asin string, status ProductStatus, errorMsg string) error {
@@ -168,6 +379,8 @@ func (db *DB) UpdateProductStatus(ctx context.Context, asin string, status Produ
 		UPDATE products SET
 			status = $2,
 			error_message = $3,
+			claimed_by = NULL,
+			lease_expires_at = NULL,
 			updated_at = CURRENT_TIMESTAMP
 		WHERE asin = $1`
 
@@ -179,14 +392,50 @@ func (db *DB) UpdateProductStatus(ctx context.Context, asin string, status Produ
 	return nil
 }
 
-// GetPendingProducts returns products that need to be scraped
+// RetireProduct marks a product whose listing no longer exists so it is not scraped again. It reports
+// false if the product was retired already or does not exist.
+func (db *DB) RetireProduct(ctx context.Context, asin, reason string) (bool, error) {
+	_, err := db.TransitionProduct(ctx, lifecycle.Default(), asin, lifecycle.Retire, lifecycle.Facts{Reason: reason})
+	switch {
+	case errors.Is(err, lifecycle.ErrIllegalTransition), errors.Is(err, ErrProductNotFound):
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("failed to retire product: %w", err)
+	}
+	return true, nil
+}
+
+// UpdateProductFailure marks a product as failed and stores the captured diagnostics
+// Deprecated: Use product lifecycle table methods instead
+func (db *DB) UpdateProductFailure(ctx context.Context, asin, errorMsg, screenshotPath, domSnippetPath string) error {
+	query := `
+		UPDATE products SET
+			status = $2,
+			error_message = $3,
+			error_screenshot = NULLIF($4, ''),
+			error_dom_snippet = NULLIF($5, ''),
+			claimed_by = NULL,
+			lease_expires_at = NULL,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE asin = $1`
+
+	_, err := db.pool.Exec(ctx, query, asin, StatusFailed, errorMsg, screenshotPath, domSnippetPath)
+	if err != nil {
+		return fmt.Errorf("failed to update product failure: %w", err)
+	}
+
+	return nil
+}
+
+// GetPendingProducts returns products that need to be scraped, highest priority score first
 // Deprecated: Use product lifecycle table methods instead
 func (db *DB) GetPendingProducts(ctx context.Context, limit int) ([]*Product, error) {
 	query := `
-		SELECT asin, title, brand, category, url, status, created_at, updated_at
+		SELECT asin, title, brand, category, url, status,
+			   rating, review_count, priority_score, created_at, updated_at
 		FROM products
 		WHERE status = $1
-		ORDER BY created_at ASC
+		ORDER BY priority_score DESC, created_at ASC
 		LIMIT $2`
 
 	rows, err := db.pool.Query(ctx, query, StatusPending, limit)
@@ -199,8 +448,8 @@ func (db *DB) GetPendingProducts(ctx context.Context, limit int) ([]*Product, er
 	for rows.Next() {
 		p := &Product{}
 		err := rows.Scan(
-			&p.ASIN, &p.Title, &p.Brand, &p.Category, &p.URL,
-			&p.Status, &p.CreatedAt, &p.UpdatedAt,
+			&p.ASIN, &p.Title, &p.Brand, &p.Category, &p.URL, &p.Status,
+			&p.Rating, &p.ReviewCount, &p.Priority, &p.CreatedAt, &p.UpdatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan product: %w", err)
@@ -215,15 +464,17 @@ func (db *DB) GetPendingProducts(ctx context.Context, limit int) ([]*Product, er
 // Deprecated: Use GetProductLifecycleByASIN for the new product table
 func (db *DB) GetProduct(ctx context.Context, asin string) (*Product, error) {
 	query := `
-		SELECT asin, title, brand, category, url, size_table, 
-			   status, error_message, scraped_at, created_at, updated_at
+		SELECT asin, title, brand, category, category_code, url, size_table, size_prices, provenance, attributes, metadata,
+			   status, error_message, error_screenshot, error_dom_snippet,
+			   scraped_at, created_at, updated_at
 		FROM products
 		WHERE asin = $1`
 
 	p := &Product{}
 	err := db.pool.QueryRow(ctx, query, asin).Scan(
-		&p.ASIN, &p.Title, &p.Brand, &p.Category, &p.URL, &p.SizeTable,
-		&p.Status, &p.ErrorMessage, &p.ScrapedAt, &p.CreatedAt, &p.UpdatedAt,
+		&p.ASIN, &p.Title, &p.Brand, &p.Category, &p.CategoryCode, &p.URL, &p.SizeTable, &p.SizePrices, &p.Provenance, &p.Attributes, &p.Metadata,
+		&p.Status, &p.ErrorMessage, &p.Screenshot, &p.DOMSnippet,
+		&p.ScrapedAt, &p.CreatedAt, &p.UpdatedAt,
 	)
 
 	if err == pgx.ErrNoRows {
@@ -243,7 +494,7 @@ func (db *DB) CountProductsByStatus(ctx context.Context) (map[ProductStatus]int,
 		FROM products
 		GROUP BY status`
 
-	rows, err := db.pool.Query(ctx, query)
+	rows, err := db.ReadQuery(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to count products: %w", err)
 	}
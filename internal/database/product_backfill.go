@@ -0,0 +1,92 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BackfillFilter selects the products whose events are regenerated, empty fields match everything
+type BackfillFilter struct {
+	ASINs            []string   `json:"asins,omitempty"`
+	Statuses         []string   `json:"statuses,omitempty"`
+	Category         string     `json:"category,omitempty"`
+	UpdatedSince     *time.Time `json:"updated_since,omitempty"`
+	RequireSizeTable bool       `json:"require_size_table,omitempty"`
+}
+
+// ListProductsForBackfill returns up to limit products matching filter with an ASIN after the given
+// one, ordered by ASIN so a backfill can walk the table in stable pages
+func (db *DB) ListProductsForBackfill(ctx context.Context, filter BackfillFilter, after string, limit int) ([]*ProductLifecycle, error) {
+	conditions := []string{"asin > $1"}
+	args := []interface{}{after}
+
+	if len(filter.ASINs) > 0 {
+		args = append(args, filter.ASINs)
+		conditions = append(conditions, fmt.Sprintf("asin = ANY($%d)", len(args)))
+	}
+	if len(filter.Statuses) > 0 {
+		args = append(args, filter.Statuses)
+		conditions = append(conditions, fmt.Sprintf("status = ANY($%d)", len(args)))
+	} else {
+		// Retired listings are only republished when asked for by status
+		args = append(args, StatusRetired)
+		conditions = append(conditions, fmt.Sprintf("status <> $%d", len(args)))
+	}
+	if filter.Category != "" {
+		args = append(args, filter.Category)
+		conditions = append(conditions, fmt.Sprintf("category = $%d", len(args)))
+	}
+	if filter.UpdatedSince != nil {
+		args = append(args, *filter.UpdatedSince)
+		conditions = append(conditions, fmt.Sprintf("updated_at >= $%d", len(args)))
+	}
+	if filter.RequireSizeTable {
+		conditions = append(conditions, "size_table IS NOT NULL")
+	}
+	args = append(args, limit)
+
+	query := fmt.Sprintf(`
+		SELECT asin, title, brand, category, COALESCE(category_code, ''), url, status,
+			   rating, review_count, size_table, fit_feedback, size_prices, updated_at
+		FROM products
+		WHERE %s
+		ORDER BY asin
+		LIMIT $%d`, strings.Join(conditions, " AND "), len(args))
+
+	rows, err := db.ReadQuery(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query products for backfill: %w", err)
+	}
+	defer rows.Close()
+
+	var products []*ProductLifecycle
+	for rows.Next() {
+		p := &ProductLifecycle{}
+		var brand, category sql.NullString
+		var sizeTable, fitFeedback, sizePrices []byte
+		if err := rows.Scan(
+			&p.ASIN, &p.Title, &brand, &category, &p.CategoryCode, &p.DetailPageURL, &p.Status,
+			&p.Rating, &p.ReviewCount, &sizeTable, &fitFeedback, &sizePrices, &p.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan product: %w", err)
+		}
+		p.Brand = brand.String
+		p.Category = category.String
+		if sizeTable != nil {
+			p.SizeTable = json.RawMessage(sizeTable)
+		}
+		if fitFeedback != nil {
+			p.FitFeedback = json.RawMessage(fitFeedback)
+		}
+		if sizePrices != nil {
+			p.SizePrices = json.RawMessage(sizePrices)
+		}
+		products = append(products, p)
+	}
+
+	return products, rows.Err()
+}
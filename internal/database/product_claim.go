@@ -0,0 +1,201 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ClaimPendingProducts atomically leases up to limit products to workerID by setting them to
+// processing until now+lease, so concurrent workers never get the same ASIN. Products whose lease
+// expired, e.g. because their worker crashed, are stolen before new pending ones, then the highest
+// priority score wins. Pending products whose failed scrape is backing off are skipped until
+// next_attempt_at. Returns an empty slice when there is nothing to claim.
+func (db *DB) ClaimPendingProducts(ctx context.Context, workerID string, lease time.Duration, limit int) ([]*Product, error) {
+	query := `
+		UPDATE products SET
+			status = $1,
+			claimed_by = $2,
+			lease_expires_at = CURRENT_TIMESTAMP + make_interval(secs => $3),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE asin IN (
+			SELECT asin FROM products
+			WHERE (status = $4 AND (next_attempt_at IS NULL OR next_attempt_at <= CURRENT_TIMESTAMP))
+				OR (status = $1 AND lease_expires_at < CURRENT_TIMESTAMP)
+			ORDER BY status = $1 DESC, priority_score DESC, created_at ASC
+			LIMIT $5
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING asin, title, brand, category, url, status,
+			rating, review_count, priority_score, created_at, updated_at`
+
+	rows, err := db.pool.Query(ctx, query, StatusProcessing, workerID, lease.Seconds(), StatusPending, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim products: %w", err)
+	}
+	defer rows.Close()
+
+	var products []*Product
+	for rows.Next() {
+		p := &Product{}
+		err := rows.Scan(
+			&p.ASIN, &p.Title, &p.Brand, &p.Category, &p.URL, &p.Status,
+			&p.Rating, &p.ReviewCount, &p.Priority, &p.CreatedAt, &p.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan product: %w", err)
+		}
+		products = append(products, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to claim products: %w", err)
+	}
+
+	// RETURNING does not keep the order of the subquery
+	sort.SliceStable(products, func(i, j int) bool {
+		if products[i].Priority != products[j].Priority {
+			return products[i].Priority > products[j].Priority
+		}
+		return products[i].CreatedAt.Before(products[j].CreatedAt)
+	})
+
+	return products, nil
+}
+
+// HeartbeatWorker records that workerID is alive and renews the lease of every product it holds
+func (db *DB) HeartbeatWorker(ctx context.Context, workerID string, lease time.Duration) error {
+	hostname, _ := os.Hostname()
+
+	return db.WithTx(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO scraper_workers (worker_id, hostname)
+			VALUES ($1, $2)
+			ON CONFLICT (worker_id) DO UPDATE SET
+				last_heartbeat = CURRENT_TIMESTAMP`,
+			workerID, hostname,
+		); err != nil {
+			return fmt.Errorf("failed to record heartbeat: %w", err)
+		}
+
+		if _, err := tx.Exec(ctx, `
+			UPDATE products SET
+				lease_expires_at = CURRENT_TIMESTAMP + make_interval(secs => $3)
+			WHERE status = $1 AND claimed_by = $2`,
+			StatusProcessing, workerID, lease.Seconds(),
+		); err != nil {
+			return fmt.Errorf("failed to renew leases: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// ReleaseProductClaim puts a product workerID still holds back to pending. Products that were
+// completed or failed in the meantime are left alone, so it is safe to call after every scrape.
+func (db *DB) ReleaseProductClaim(ctx context.Context, asin, workerID string) error {
+	query := `
+		UPDATE products SET
+			status = $3,
+			claimed_by = NULL,
+			lease_expires_at = NULL,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE asin = $1 AND status = $2 AND claimed_by = $4`
+
+	if _, err := db.pool.Exec(ctx, query, asin, StatusProcessing, StatusPending, workerID); err != nil {
+		return fmt.Errorf("failed to release product claim: %w", err)
+	}
+
+	return nil
+}
+
+// FailProductClaim records a failed scrape of a product workerID still holds. The product goes back
+// to pending and is not claimed again before backoff, doubled per earlier failure, has passed; after
+// maxAttempts failures it is marked failed. Products the scrape already moved out of processing are
+// left alone. Reports whether the product was marked failed.
+func (db *DB) FailProductClaim(ctx context.Context, asin, workerID string, maxAttempts int, backoff time.Duration) (bool, error) {
+	query := `
+		UPDATE products SET
+			status = CASE WHEN scrape_attempts + 1 >= $5 THEN $6 ELSE $3 END,
+			scrape_attempts = scrape_attempts + 1,
+			next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $7 * power(2, LEAST(scrape_attempts, 10))),
+			claimed_by = NULL,
+			lease_expires_at = NULL,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE asin = $1 AND status = $2 AND claimed_by = $4
+		RETURNING status`
+
+	var status ProductStatus
+	err := db.pool.QueryRow(ctx, query, asin, StatusProcessing, StatusPending, workerID,
+		maxAttempts, StatusFailed, backoff.Seconds()).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to record failed scrape: %w", err)
+	}
+
+	return status == StatusFailed, nil
+}
+
+// ReleaseWorker releases every product workerID still holds and removes the worker, called when a
+// worker shuts down cleanly
+func (db *DB) ReleaseWorker(ctx context.Context, workerID string) error {
+	return db.WithTx(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `
+			UPDATE products SET
+				status = $2,
+				claimed_by = NULL,
+				lease_expires_at = NULL,
+				updated_at = CURRENT_TIMESTAMP
+			WHERE status = $1 AND claimed_by = $3`,
+			StatusProcessing, StatusPending, workerID,
+		); err != nil {
+			return fmt.Errorf("failed to release worker claims: %w", err)
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM scraper_workers WHERE worker_id = $1`, workerID); err != nil {
+			return fmt.Errorf("failed to remove worker: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// ReleaseStaleClaims puts products whose lease expired back to pending and removes workers without
+// a heartbeat for staleAfter. Returns the number of released products.
+func (db *DB) ReleaseStaleClaims(ctx context.Context, staleAfter time.Duration) (int64, error) {
+	var released int64
+
+	err := db.WithTx(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+			UPDATE products SET
+				status = $2,
+				claimed_by = NULL,
+				lease_expires_at = NULL,
+				updated_at = CURRENT_TIMESTAMP
+			WHERE status = $1 AND lease_expires_at < CURRENT_TIMESTAMP`,
+			StatusProcessing, StatusPending,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to release stale claims: %w", err)
+		}
+		released = tag.RowsAffected()
+
+		if _, err := tx.Exec(ctx, `
+			DELETE FROM scraper_workers
+			WHERE last_heartbeat < CURRENT_TIMESTAMP - make_interval(secs => $1)`,
+			staleAfter.Seconds(),
+		); err != nil {
+			return fmt.Errorf("failed to remove stale workers: %w", err)
+		}
+
+		return nil
+	})
+
+	return released, err
+}
@@ -0,0 +1,189 @@
+//go:build integration
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimPendingProductsConcurrently(t *testing.T) {
+	ctx := context.Background()
+	db := setupIntegrationDB(t)
+	asins := insertPendingProducts(ctx, t, db, 40)
+
+	var mu sync.Mutex
+	claims := make(map[string][]string) // ASIN -> workers that claimed it
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		workerID := fmt.Sprintf("worker-%d", w)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				products, err := db.ClaimPendingProducts(ctx, workerID, time.Minute, 3)
+				if !assert.NoError(t, err) || len(products) == 0 {
+					return
+				}
+				mu.Lock()
+				for _, p := range products {
+					claims[p.ASIN] = append(claims[p.ASIN], workerID)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.Len(t, claims, len(asins))
+	for asin, workers := range claims {
+		assert.Len(t, workers, 1, "product %s claimed by %v", asin, workers)
+	}
+}
+
+func TestFailProductClaimBacksOff(t *testing.T) {
+	ctx := context.Background()
+	db := setupIntegrationDB(t)
+	asin := insertPendingProducts(ctx, t, db, 1)[0]
+
+	claimed, err := db.ClaimPendingProducts(ctx, "worker-1", time.Minute, 1)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+
+	failed, err := db.FailProductClaim(ctx, asin, "worker-1", 2, time.Hour)
+	require.NoError(t, err)
+	assert.False(t, failed)
+
+	product, err := db.GetProduct(ctx, asin)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, product.Status)
+
+	// The failed product is not claimed again while it backs off
+	claimed, err = db.ClaimPendingProducts(ctx, "worker-1", time.Minute, 1)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	_, err = db.Exec(ctx, `UPDATE products SET next_attempt_at = NULL WHERE asin = $1`, asin)
+	require.NoError(t, err)
+	claimed, err = db.ClaimPendingProducts(ctx, "worker-2", time.Minute, 1)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+
+	// Another worker's claim is left alone
+	failed, err = db.FailProductClaim(ctx, asin, "worker-1", 2, time.Hour)
+	require.NoError(t, err)
+	assert.False(t, failed)
+
+	failed, err = db.FailProductClaim(ctx, asin, "worker-2", 2, time.Hour)
+	require.NoError(t, err)
+	assert.True(t, failed)
+
+	product, err = db.GetProduct(ctx, asin)
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, product.Status)
+}
+
+func TestReleaseStaleClaims(t *testing.T) {
+	ctx := context.Background()
+	db := setupIntegrationDB(t)
+	insertPendingProducts(ctx, t, db, 2)
+
+	require.NoError(t, db.HeartbeatWorker(ctx, "crashed", time.Minute))
+	require.NoError(t, db.HeartbeatWorker(ctx, "alive", time.Minute))
+
+	// The crashed worker's lease is already over, the live worker's is not
+	_, err := db.ClaimPendingProducts(ctx, "crashed", -time.Minute, 1)
+	require.NoError(t, err)
+	_, err = db.ClaimPendingProducts(ctx, "alive", time.Minute, 1)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, `UPDATE scraper_workers SET last_heartbeat = CURRENT_TIMESTAMP - INTERVAL '1 hour' WHERE worker_id = 'crashed'`)
+	require.NoError(t, err)
+
+	released, err := db.ReleaseStaleClaims(ctx, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), released)
+
+	var processing, workers int
+	require.NoError(t, db.QueryRow(ctx, `SELECT COUNT(*) FROM products WHERE status = $1 AND claimed_by = 'alive'`, StatusProcessing).Scan(&processing))
+	require.NoError(t, db.QueryRow(ctx, `SELECT COUNT(*) FROM scraper_workers`).Scan(&workers))
+	assert.Equal(t, 1, processing)
+	assert.Equal(t, 1, workers)
+}
+
+func TestHeartbeatWorkerRenewsLeases(t *testing.T) {
+	ctx := context.Background()
+	db := setupIntegrationDB(t)
+	insertPendingProducts(ctx, t, db, 1)
+
+	claimed, err := db.ClaimPendingProducts(ctx, "worker-1", -time.Minute, 1)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+
+	require.NoError(t, db.HeartbeatWorker(ctx, "worker-1", time.Minute))
+
+	// The renewed lease keeps other workers from stealing the product
+	stolen, err := db.ClaimPendingProducts(ctx, "worker-2", time.Minute, 1)
+	require.NoError(t, err)
+	assert.Empty(t, stolen)
+
+	var workers int
+	require.NoError(t, db.QueryRow(ctx, `SELECT COUNT(*) FROM scraper_workers WHERE worker_id = 'worker-1'`).Scan(&workers))
+	assert.Equal(t, 1, workers)
+}
+
+func TestReleaseWorker(t *testing.T) {
+	ctx := context.Background()
+	db := setupIntegrationDB(t)
+	insertPendingProducts(ctx, t, db, 3)
+
+	require.NoError(t, db.HeartbeatWorker(ctx, "worker-1", time.Minute))
+	claimed, err := db.ClaimPendingProducts(ctx, "worker-1", time.Minute, 2)
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+
+	require.NoError(t, db.ReleaseWorker(ctx, "worker-1"))
+
+	var pending, workers int
+	require.NoError(t, db.QueryRow(ctx, `SELECT COUNT(*) FROM products WHERE status = $1 AND claimed_by IS NULL`, StatusPending).Scan(&pending))
+	require.NoError(t, db.QueryRow(ctx, `SELECT COUNT(*) FROM scraper_workers`).Scan(&workers))
+	assert.Equal(t, 3, pending)
+	assert.Equal(t, 0, workers)
+}
+
+func TestClaimsAreNotRecordedInHistory(t *testing.T) {
+	ctx := context.Background()
+	db := setupIntegrationDB(t)
+	asin := insertPendingProducts(ctx, t, db, 1)[0]
+
+	// Claiming, releasing and retrying a product leaves it unchanged
+	_, err := db.ClaimPendingProducts(ctx, "worker-1", time.Minute, 1)
+	require.NoError(t, err)
+	require.NoError(t, db.ReleaseProductClaim(ctx, asin, "worker-1"))
+	_, err = db.ClaimPendingProducts(ctx, "worker-1", time.Minute, 1)
+	require.NoError(t, err)
+	_, err = db.FailProductClaim(ctx, asin, "worker-1", 3, 0)
+	require.NoError(t, err)
+
+	history, err := db.ListProductHistory(ctx, asin, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+
+	// Completing a claimed product records the version before the claim
+	_, err = db.ClaimPendingProducts(ctx, "worker-1", time.Minute, 1)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, `UPDATE products SET status = $2 WHERE asin = $1`, asin, StatusCompleted)
+	require.NoError(t, err)
+
+	history, err = db.ListProductHistory(ctx, asin, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, StatusPending, history[0].Status)
+	assert.Equal(t, []string{"status"}, history[0].ChangedFields)
+}
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ProductVersion is the title, price, status and size table of a product during a period of time
+type ProductVersion struct {
+	Title         string          `json:"title"`
+	Price         *float64        `json:"price,omitempty"`
+	SizePrices    json.RawMessage `json:"size_prices,omitempty"`
+	Status        ProductStatus   `json:"status"`
+	SizeTable     json.RawMessage `json:"size_table,omitempty"`
+	ChangedFields []string        `json:"changed_fields,omitempty"` // Fields the next version changed
+	ValidFrom     time.Time       `json:"valid_from"`
+	ValidTo       *time.Time      `json:"valid_to,omitempty"` // Nil for the current version
+}
+
+// productVersions selects the history of a product with the start of each version, which is the
+// end of the previous one or the product's creation
+const productVersions = `
+	SELECT h.title, h.price, h.size_prices, h.status, h.size_table, h.changed_fields,
+		COALESCE(LAG(h.changed_at) OVER (ORDER BY h.changed_at, h.id), p.created_at) AS valid_from,
+		h.changed_at AS valid_to, h.id
+	FROM products_history h
+	JOIN products p ON p.asin = h.asin
+	WHERE h.asin = $1`
+
+// ListProductHistory returns the previous versions of a product, newest first
+func (db *DB) ListProductHistory(ctx context.Context, asin string, limit, offset int) ([]ProductVersion, error) {
+	query := `SELECT title, price, size_prices, status, size_table, changed_fields, valid_from, valid_to
+		FROM (` + productVersions + `) v
+		ORDER BY valid_to DESC, id DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := db.ReadQuery(ctx, query, asin, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query product history: %w", err)
+	}
+	defer rows.Close()
+
+	versions := []ProductVersion{}
+	for rows.Next() {
+		v, err := scanProductVersion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan product version: %w", err)
+		}
+		versions = append(versions, *v)
+	}
+	return versions, rows.Err()
+}
+
+// GetCurrentProductVersion returns the stored values of a product and since when they are valid,
+// nil if the product does not exist
+func (db *DB) GetCurrentProductVersion(ctx context.Context, asin string) (*ProductVersion, error) {
+	query := `
+		SELECT title, current_price, size_prices, status, size_table, NULL::text[],
+			COALESCE((SELECT MAX(changed_at) FROM products_history WHERE asin = $1), created_at),
+			NULL::timestamptz
+		FROM products
+		WHERE asin = $1`
+
+	v, err := scanProductVersion(db.ReadQueryRow(ctx, query, asin))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current product version: %w", err)
+	}
+	return v, nil
+}
+
+// GetProductVersionAt returns the version of a product that was valid at the given time, nil if the
+// product did not exist yet
+func (db *DB) GetProductVersionAt(ctx context.Context, asin string, at time.Time) (*ProductVersion, error) {
+	query := `SELECT title, price, size_prices, status, size_table, changed_fields, valid_from, valid_to
+		FROM (` + productVersions + `) v
+		WHERE valid_to > $2
+		ORDER BY valid_to, id
+		LIMIT 1`
+
+	v, err := scanProductVersion(db.ReadQueryRow(ctx, query, asin, at))
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Not replaced since, the current values apply
+		v, err = db.GetCurrentProductVersion(ctx, asin)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product version: %w", err)
+	}
+	if v == nil || v.ValidFrom.After(at) {
+		return nil, nil
+	}
+	return v, nil
+}
+
+func scanProductVersion(row pgx.Row) (*ProductVersion, error) {
+	v := &ProductVersion{}
+	err := row.Scan(&v.Title, &v.Price, &v.SizePrices, &v.Status, &v.SizeTable, &v.ChangedFields, &v.ValidFrom, &v.ValidTo)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
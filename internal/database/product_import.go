@@ -0,0 +1,53 @@
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+// ImportResult counts the outcome of ImportProducts
+type ImportResult struct {
+	Created  int `json:"created"`  // New pending products
+	Existing int `json:"existing"` // ASINs already in the products table, left untouched
+}
+
+// ImportProducts creates pending products for asins that are not in the products table yet, their
+// URL points to the marketplace, e.g. "amazon.de". When jobID is set all asins, new and existing, are
+// linked to the job so its progress covers the whole list.
+func (db *DB) ImportProducts(ctx context.Context, asins []string, marketplace, jobID string) (*ImportResult, error) {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO products (asin, title, url, status)
+		SELECT asin, '', 'https://www.' || $2 || '/dp/' || asin, $3
+		FROM unnest($1::text[]) AS asin
+		ON CONFLICT (asin) DO NOTHING`
+
+	tag, err := tx.Exec(ctx, query, asins, marketplace, StatusPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import products: %w", err)
+	}
+
+	if jobID != "" {
+		linkQuery := `
+			INSERT INTO job_products (job_id, asin, page_number)
+			SELECT $1, asin, 0
+			FROM unnest($2::text[]) AS asin
+			ON CONFLICT (job_id, asin) DO NOTHING`
+
+		if _, err := tx.Exec(ctx, linkQuery, jobID, asins); err != nil {
+			return nil, fmt.Errorf("failed to link products to job: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit import: %w", err)
+	}
+
+	created := int(tag.RowsAffected())
+	return &ImportResult{Created: created, Existing: len(asins) - created}, nil
+}
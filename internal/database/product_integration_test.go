@@ -0,0 +1,172 @@
+//go:build integration
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsertProductsDuplicateASINs(t *testing.T) {
+	ctx := context.Background()
+	db := setupIntegrationDB(t)
+
+	first := &Product{ASIN: "B000DUP001", Title: "First", URL: "https://www.amazon.de/dp/B000DUP001", Status: StatusPending}
+	other := &Product{ASIN: "B000DUP002", Title: "Other", URL: "https://www.amazon.de/dp/B000DUP002", Status: StatusPending}
+	last := &Product{ASIN: "B000DUP001", Title: "Last", URL: "https://www.amazon.de/dp/B000DUP001?th=1", Status: StatusPending,
+		Brand: sql.NullString{String: "Acme", Valid: true}}
+
+	// A product listed twice would make the upsert touch its row twice and fail
+	require.NoError(t, db.InsertProducts(ctx, []*Product{first, other, last}))
+
+	var count int
+	require.NoError(t, db.QueryRow(ctx, `SELECT COUNT(*) FROM products`).Scan(&count))
+	assert.Equal(t, 2, count)
+
+	stored, err := db.GetProduct(ctx, "B000DUP001")
+	require.NoError(t, err)
+	assert.Equal(t, "Last", stored.Title)
+	assert.Equal(t, "https://www.amazon.de/dp/B000DUP001?th=1", stored.URL)
+	assert.Equal(t, "Acme", stored.Brand.String)
+
+	// Every entry gets the timestamps of its row
+	for _, p := range []*Product{first, other, last} {
+		assert.False(t, p.CreatedAt.IsZero(), p.ASIN)
+		assert.False(t, p.UpdatedAt.IsZero(), p.ASIN)
+	}
+	assert.Equal(t, last.CreatedAt, first.CreatedAt)
+}
+
+func TestInsertProductsUpdatesOnConflict(t *testing.T) {
+	ctx := context.Background()
+	db := setupIntegrationDB(t)
+
+	original := &Product{
+		ASIN:        "B000CONF01",
+		Title:       "Original",
+		URL:         "https://www.amazon.de/dp/B000CONF01",
+		Brand:       sql.NullString{String: "Acme", Valid: true},
+		Rating:      sql.NullFloat64{Float64: 4.5, Valid: true},
+		ReviewCount: sql.NullInt32{Int32: 120, Valid: true},
+		Priority:    1,
+		Status:      StatusPending,
+	}
+	require.NoError(t, db.InsertProducts(ctx, []*Product{original}))
+
+	_, err := db.Exec(ctx, `UPDATE products SET status = $2 WHERE asin = $1`, original.ASIN, StatusCompleted)
+	require.NoError(t, err)
+
+	// A later listing without rating and review count keeps the stored ones
+	relisted := &Product{
+		ASIN:     "B000CONF01",
+		Title:    "Relisted",
+		URL:      "https://www.amazon.de/dp/B000CONF01?psc=1",
+		Brand:    sql.NullString{String: "Acme Sports", Valid: true},
+		Priority: 3,
+		Status:   StatusPending,
+	}
+	require.NoError(t, db.InsertProducts(ctx, []*Product{relisted}))
+
+	stored, err := db.GetProduct(ctx, original.ASIN)
+	require.NoError(t, err)
+	assert.Equal(t, "Relisted", stored.Title)
+	assert.Equal(t, "https://www.amazon.de/dp/B000CONF01?psc=1", stored.URL)
+	assert.Equal(t, "Acme Sports", stored.Brand.String)
+
+	var rating, priority float64
+	var reviewCount int32
+	require.NoError(t, db.QueryRow(ctx, `SELECT rating, review_count, priority_score FROM products WHERE asin = $1`,
+		original.ASIN).Scan(&rating, &reviewCount, &priority))
+	assert.Equal(t, 4.5, rating)
+	assert.Equal(t, int32(120), reviewCount)
+	assert.Equal(t, 3.0, priority)
+
+	// The status of a stored product is not reset by listing it again
+	assert.Equal(t, StatusCompleted, stored.Status)
+
+	assert.Equal(t, original.CreatedAt, relisted.CreatedAt)
+	assert.False(t, relisted.UpdatedAt.Before(original.UpdatedAt))
+}
+
+func TestInsertProductLifecyclesBatch(t *testing.T) {
+	ctx := context.Background()
+	db := setupIntegrationDB(t)
+
+	products := []*ProductLifecycle{
+		{ASIN: "B000LIFE01", Title: "First", DetailPageURL: "https://www.amazon.de/dp/B000LIFE01", Status: string(StatusCompleted), ContentHash: "a"},
+		{ASIN: "B000LIFE02", Title: "Second", DetailPageURL: "https://www.amazon.de/dp/B000LIFE02", Status: string(StatusCompleted), ContentHash: "b"},
+	}
+	require.NoError(t, db.InsertProductLifecycles(ctx, products))
+	for _, p := range products {
+		assert.False(t, p.CreatedAt.IsZero(), p.ASIN)
+	}
+
+	require.NoError(t, db.MergeProductsMetadata(ctx, []string{"B000LIFE01", "B000LIFE02"}, Metadata{"campaign_id": "summer-24"}))
+
+	var count int
+	require.NoError(t, db.QueryRow(ctx,
+		`SELECT COUNT(*) FROM products WHERE metadata->>'campaign_id' = 'summer-24'`).Scan(&count))
+	assert.Equal(t, 2, count)
+}
+
+func TestUpdatePriorityScores(t *testing.T) {
+	ctx := context.Background()
+	db := setupIntegrationDB(t)
+
+	shirt := &Product{ASIN: "B000PRIO01", Title: "Herren T-Shirt Regular Fit", URL: "https://www.amazon.de/dp/B000PRIO01",
+		Category: sql.NullString{String: "Bekleidung", Valid: true}, Status: StatusPending}
+	mug := &Product{ASIN: "B000PRIO02", Title: "Kaffeebecher", URL: "https://www.amazon.de/dp/B000PRIO02", Status: StatusPending}
+	done := &Product{ASIN: "B000PRIO03", Title: "Damen Hoodie", URL: "https://www.amazon.de/dp/B000PRIO03", Priority: 7, Status: StatusPending}
+	require.NoError(t, db.InsertProducts(ctx, []*Product{shirt, mug, done}))
+	_, err := db.Exec(ctx, `UPDATE products SET status = $2 WHERE asin = $1`, done.ASIN, StatusCompleted)
+	require.NoError(t, err)
+
+	pr := NewPrioritizer()
+	scored, err := db.UpdatePriorityScores(ctx, pr)
+	require.NoError(t, err)
+	assert.Equal(t, 2, scored)
+
+	for _, p := range []*Product{shirt, mug} {
+		var priority float64
+		require.NoError(t, db.QueryRow(ctx, `SELECT priority_score FROM products WHERE asin = $1`, p.ASIN).Scan(&priority))
+		assert.Equal(t, pr.Score(p), priority, p.ASIN)
+	}
+
+	// Products that are no longer pending keep their score
+	var priority float64
+	require.NoError(t, db.QueryRow(ctx, `SELECT priority_score FROM products WHERE asin = $1`, done.ASIN).Scan(&priority))
+	assert.Equal(t, 7.0, priority)
+}
+
+func TestSaveSizeMeasurementsLongLabels(t *testing.T) {
+	ctx := context.Background()
+	db := setupIntegrationDB(t)
+
+	// A label that is not recognized is its own canonical size, both are longer than the old columns
+	long := "Einheitsgröße für Damen und Herren im weiten Oversize Schnitt"
+	st := &SizeTable{
+		Sizes: []string{long},
+		Measurements: map[string]map[string]float64{
+			long: {"chest": 120, "length": 74},
+		},
+		Conversions: map[string]map[string]string{
+			long: {"INT": "OS"},
+		},
+		Unit: "cm",
+	}
+	require.NoError(t, db.SaveSizeMeasurements(ctx, "B000LONG01", st))
+
+	rows, err := db.ListSizeMeasurements(ctx, "B000LONG01", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, long, rows[0].SizeLabel)
+	assert.Equal(t, CanonicalSize(long), rows[0].CanonicalSize)
+
+	var conversions int
+	require.NoError(t, db.QueryRow(ctx, `SELECT COUNT(*) FROM size_conversions WHERE asin = $1`, "B000LONG01").Scan(&conversions))
+	assert.Equal(t, 1, conversions)
+}
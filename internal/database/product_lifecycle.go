@@ -20,47 +20,86 @@ type ProductLifecycle struct {
 	DetailPageURL      string          `db:"detail_page_url"`
 	ImageURLs          json.RawMessage `db:"image_urls"`
 	Features           json.RawMessage `db:"features"`
+	Attributes         json.RawMessage `db:"attributes"` // Product overview attributes by canonical key, e.g. material and fit
 	CurrentPrice       *float64        `db:"current_price"`
 	Currency           string          `db:"currency"`
 	Rating             *float64        `db:"rating"`
 	ReviewCount        *int            `db:"review_count"`
 	Status             string          `db:"status"`
 	Category           string          `db:"category"`
+	CategoryPath       string          `db:"category_path"` // Breadcrumbs joined with " > "
+	CategoryCode       string          `db:"category_code"` // Internal category, see package taxonomy
 	AvailableSizes     json.RawMessage `db:"available_sizes"`
 	SizeTable          json.RawMessage `db:"size_table"`
+	ValidationReport   json.RawMessage `db:"validation_report"`
+	QualityScore       *float64        `db:"quality_score"`
+	FitFeedback        json.RawMessage `db:"fit_feedback"`
+	SizePrices         json.RawMessage `db:"size_prices"`
+	StageTimings       json.RawMessage `db:"stage_timings"` // Milliseconds per extraction stage
+	DataSources        json.RawMessage `db:"data_sources"`  // Source per basic field, scraper or pa-api
+	Provenance         json.RawMessage `db:"provenance"`    // Origin per major field, see Provenance
+	MeasurementPolicy  json.RawMessage `db:"measurement_policy"` // AppliedMeasurementPolicy the size table was accepted under
+	ContentHash        string          `db:"content_hash"`
 	CreatedAt          time.Time       `db:"created_at"`
 	UpdatedAt          time.Time       `db:"updated_at"`
 }
 
-// InsertProductLifecycle inserts a new product into the product table or updates if exists
-func (db *DB) InsertProductLifecycle(ctx context.Context, p *ProductLifecycle) error {
-	// Generate ID if not provided
-	if p.ID == uuid.Nil {
-		p.ID = uuid.New()
-	}
-
-	query := `
+// insertProductLifecycleQuery upserts a product, RETURNING asin, created_at, updated_at
+const insertProductLifecycleQuery = `
 		INSERT INTO products (
 			asin, title, brand, url,
-			category, status, size_table
+			category, status, size_table,
+			validation_report, quality_score, fit_feedback, size_prices,
+			content_hash, category_path, category_code, stage_timings, data_sources, provenance, attributes, measurement_policy,
+			last_checked_at, last_changed_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NULLIF($12, ''), NULLIF($13, ''), NULLIF($14, ''), $15, $16, $17, $18, $19, NOW(), NOW()
 		)
 		ON CONFLICT (asin) DO UPDATE SET
 			title = EXCLUDED.title,
 			brand = EXCLUDED.brand,
 			url = EXCLUDED.url,
 			category = EXCLUDED.category,
+			category_path = COALESCE(EXCLUDED.category_path, products.category_path),
+			category_code = COALESCE(EXCLUDED.category_code, products.category_code),
 			size_table = EXCLUDED.size_table,
+			validation_report = EXCLUDED.validation_report,
+			quality_score = EXCLUDED.quality_score,
+			fit_feedback = COALESCE(EXCLUDED.fit_feedback, products.fit_feedback),
+			size_prices = COALESCE(EXCLUDED.size_prices, products.size_prices),
+			stage_timings = EXCLUDED.stage_timings,
+			data_sources = EXCLUDED.data_sources,
+			attributes = COALESCE(EXCLUDED.attributes, products.attributes),
+			measurement_policy = COALESCE(EXCLUDED.measurement_policy, products.measurement_policy),
+			provenance = COALESCE(products.provenance, '{}'::jsonb) || COALESCE(EXCLUDED.provenance, '{}'::jsonb),
+			last_changed_at = CASE
+				WHEN products.content_hash IS DISTINCT FROM EXCLUDED.content_hash THEN NOW()
+				ELSE products.last_changed_at
+			END,
+			content_hash = EXCLUDED.content_hash,
+			last_checked_at = NOW(),
 			status = EXCLUDED.status,
 			updated_at = NOW()
 		RETURNING asin, created_at, updated_at`
 
-	err := db.pool.QueryRow(ctx, query,
+// insertArgs returns the arguments of insertProductLifecycleQuery, generating the ID if not provided
+func (p *ProductLifecycle) insertArgs() []interface{} {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return []interface{}{
 		p.ASIN, p.Title, p.Brand, p.DetailPageURL,
 		p.Category, p.Status, p.SizeTable,
-	).Scan(&p.ASIN, &p.CreatedAt, &p.UpdatedAt)
+		p.ValidationReport, p.QualityScore, p.FitFeedback, p.SizePrices,
+		p.ContentHash, p.CategoryPath, p.CategoryCode, p.StageTimings, p.DataSources, p.Provenance, p.Attributes,
+		p.MeasurementPolicy,
+	}
+}
 
+// InsertProductLifecycle inserts a new product into the product table or updates if exists
+func (db *DB) InsertProductLifecycle(ctx context.Context, p *ProductLifecycle) error {
+	err := db.pool.QueryRow(ctx, insertProductLifecycleQuery, p.insertArgs()...).
+		Scan(&p.ASIN, &p.CreatedAt, &p.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to insert product lifecycle: %w", err)
 	}
@@ -68,6 +107,62 @@ func (db *DB) InsertProductLifecycle(ctx context.Context, p *ProductLifecycle) e
 	return nil
 }
 
+// InsertProductLifecycles is the batched InsertProductLifecycle, it sends all upserts in one round trip
+// and one transaction, so either all products are stored or none
+func (db *DB) InsertProductLifecycles(ctx context.Context, products []*ProductLifecycle) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	return db.WithTx(ctx, func(tx pgx.Tx) error {
+		batch := &pgx.Batch{}
+		for _, p := range products {
+			batch.Queue(insertProductLifecycleQuery, p.insertArgs()...)
+		}
+
+		results := tx.SendBatch(ctx, batch)
+		for _, p := range products {
+			if err := results.QueryRow().Scan(&p.ASIN, &p.CreatedAt, &p.UpdatedAt); err != nil {
+				results.Close()
+				return fmt.Errorf("failed to insert product lifecycle %s: %w", p.ASIN, err)
+			}
+		}
+		if err := results.Close(); err != nil {
+			return fmt.Errorf("failed to insert product lifecycles: %w", err)
+		}
+		return nil
+	})
+}
+
+// InsertFallbackProduct stores the basic data of a product whose page could not be scraped as pending,
+// so a later size scrape picks it up. Stored products are left as they are; reports whether it inserted.
+func (db *DB) InsertFallbackProduct(ctx context.Context, p *ProductLifecycle) (bool, error) {
+	query := `
+		INSERT INTO products (asin, title, brand, url, category_code, status, data_sources, provenance)
+		VALUES ($1, $2, NULLIF($3, ''), $4, NULLIF($5, ''), 'pending', $6, $7)
+		ON CONFLICT (asin) DO NOTHING`
+
+	tag, err := db.pool.Exec(ctx, query, p.ASIN, p.Title, p.Brand, p.DetailPageURL, p.CategoryCode, p.DataSources, p.Provenance)
+	if err != nil {
+		return false, fmt.Errorf("failed to insert fallback product: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// MarkProductUnchanged sets last_checked_at of a product whose stored content hash equals hash and
+// reports whether it did. A product without hash counts as changed.
+func (db *DB) MarkProductUnchanged(ctx context.Context, asin, hash string) (bool, error) {
+	query := `
+		UPDATE products SET last_checked_at = NOW()
+		WHERE asin = $1 AND content_hash = $2`
+
+	tag, err := db.pool.Exec(ctx, query, asin, hash)
+	if err != nil {
+		return false, fmt.Errorf("failed to check content hash: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
 // GetProductLifecycleByASIN retrieves a product from the product table by ASIN
 func (db *DB) GetProductLifecycleByASIN(ctx context.Context, asin string) (*ProductLifecycle, error) {
 	query := `
@@ -123,11 +218,11 @@ func (db *DB) UpdateProductLifecycleSizeTable(ctx context.Context, asin string,
 	query := `
 		UPDATE products SET
 			size_table = $2,
-			status = 'SCRAPED',
+			status = $3,
 			updated_at = NOW()
 		WHERE asin = $1`
 
-	result, err := db.pool.Exec(ctx, query, asin, sizeTableJSON)
+	result, err := db.pool.Exec(ctx, query, asin, sizeTableJSON, StatusScraped)
 	if err != nil {
 		return fmt.Errorf("failed to update product size table: %w", err)
 	}
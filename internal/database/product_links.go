@@ -0,0 +1,136 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ProductFingerprint is the stored fingerprint of a product and the keys it is matched by
+type ProductFingerprint struct {
+	ASIN          string
+	Brand         string
+	TitleKey      string
+	ImageHash     string
+	SizeSignature string
+	MatchKeys     []string
+}
+
+// ProductLink links a duplicate ASIN to the canonical ASIN of its group
+type ProductLink struct {
+	ASIN          string    `json:"asin"`
+	CanonicalASIN string    `json:"canonical_asin"`
+	MatchKey      string    `json:"match_key"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ProductGroup is a canonical ASIN together with the duplicates linked to it
+type ProductGroup struct {
+	CanonicalASIN string        `json:"canonical_asin"`
+	Duplicates    []ProductLink `json:"duplicates"`
+}
+
+// RegisterFingerprint stores the fingerprint and links the product to the canonical ASIN of the
+// oldest product sharing a match key. It returns the canonical ASIN, which is the product's own
+// ASIN when no duplicate was found.
+func (db *DB) RegisterFingerprint(ctx context.Context, fp *ProductFingerprint) (string, error) {
+	canonical := fp.ASIN
+	keys := fp.MatchKeys
+	if keys == nil {
+		keys = []string{}
+	}
+
+	err := db.WithTx(ctx, func(tx pgx.Tx) error {
+		var matchKey string
+		err := tx.QueryRow(ctx, `
+			SELECT COALESCE(l.canonical_asin, f.asin),
+				   (SELECT k FROM unnest(f.match_keys) k WHERE k = ANY($2) LIMIT 1)
+			FROM product_fingerprints f
+			LEFT JOIN product_links l ON l.asin = f.asin
+			WHERE f.asin <> $1 AND f.match_keys && $2
+			ORDER BY f.created_at, f.asin
+			LIMIT 1`,
+			fp.ASIN, keys,
+		).Scan(&canonical, &matchKey)
+		if err != nil && err != pgx.ErrNoRows {
+			return fmt.Errorf("failed to find duplicate fingerprint: %w", err)
+		}
+		if err == pgx.ErrNoRows {
+			canonical = fp.ASIN
+		}
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO product_fingerprints (asin, brand, title_key, image_hash, size_signature, match_keys)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (asin) DO UPDATE SET
+				brand = EXCLUDED.brand,
+				title_key = EXCLUDED.title_key,
+				image_hash = EXCLUDED.image_hash,
+				size_signature = EXCLUDED.size_signature,
+				match_keys = EXCLUDED.match_keys,
+				updated_at = CURRENT_TIMESTAMP`,
+			fp.ASIN, fp.Brand, fp.TitleKey, fp.ImageHash, fp.SizeSignature, keys,
+		); err != nil {
+			return fmt.Errorf("failed to save fingerprint: %w", err)
+		}
+
+		// The product is canonical itself, or its duplicates point back to it
+		if canonical == fp.ASIN {
+			if _, err := tx.Exec(ctx, `DELETE FROM product_links WHERE asin = $1`, fp.ASIN); err != nil {
+				return fmt.Errorf("failed to remove product link: %w", err)
+			}
+			return nil
+		}
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO product_links (asin, canonical_asin, match_key)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (asin) DO UPDATE SET
+				canonical_asin = EXCLUDED.canonical_asin,
+				match_key = EXCLUDED.match_key`,
+			fp.ASIN, canonical, matchKey,
+		); err != nil {
+			return fmt.Errorf("failed to save product link: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return canonical, nil
+}
+
+// GetProductGroup returns the group an ASIN belongs to, a product without duplicates forms its own group
+func (db *DB) GetProductGroup(ctx context.Context, asin string) (*ProductGroup, error) {
+	group := &ProductGroup{CanonicalASIN: asin}
+
+	err := db.ReadQueryRow(ctx, `SELECT canonical_asin FROM product_links WHERE asin = $1`, asin).Scan(&group.CanonicalASIN)
+	if err != nil && err != pgx.ErrNoRows {
+		return nil, fmt.Errorf("failed to get product link: %w", err)
+	}
+
+	rows, err := db.ReadQuery(ctx, `
+		SELECT asin, canonical_asin, match_key, created_at
+		FROM product_links
+		WHERE canonical_asin = $1
+		ORDER BY created_at, asin`,
+		group.CanonicalASIN,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query product links: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var link ProductLink
+		if err := rows.Scan(&link.ASIN, &link.CanonicalASIN, &link.MatchKey, &link.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan product link: %w", err)
+		}
+		group.Duplicates = append(group.Duplicates, link)
+	}
+
+	return group, rows.Err()
+}
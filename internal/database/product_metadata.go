@@ -0,0 +1,40 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Metadata is key/value metadata of the caller of a job or request, e.g. {"campaign_id": "summer-24"}.
+// It is stored with the products they store and carried in the metadata of the events they emit.
+type Metadata map[string]string
+
+// JSON returns the metadata for a JSONB column, nil when empty
+func (m Metadata) JSON() json.RawMessage {
+	if len(m) == 0 {
+		return nil
+	}
+	data, _ := json.Marshal(m)
+	return data
+}
+
+// MergeProductMetadata merges the metadata of a job or request into a product, keys it does not set keep
+// their stored value
+func (db *DB) MergeProductMetadata(ctx context.Context, asin string, m Metadata) error {
+	return db.MergeProductsMetadata(ctx, []string{asin}, m)
+}
+
+// MergeProductsMetadata is the batched MergeProductMetadata, it merges the metadata into all products in
+// one statement
+func (db *DB) MergeProductsMetadata(ctx context.Context, asins []string, m Metadata) error {
+	if len(m) == 0 || len(asins) == 0 {
+		return nil
+	}
+
+	query := `UPDATE products SET metadata = COALESCE(metadata, '{}'::jsonb) || $2 WHERE asin = ANY($1)`
+	if _, err := db.pool.Exec(ctx, query, asins, m.JSON()); err != nil {
+		return fmt.Errorf("failed to update product metadata: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/maltedev/amazon-size-scraper/internal/lifecycle"
+	"github.com/maltedev/amazon-size-scraper/internal/scrapeerr"
+)
+
+// ErrProductNotFound is returned by TransitionProduct for an unknown ASIN
+var ErrProductNotFound = scrapeerr.ErrProductNotFound
+
+// TransitionProduct fires trigger on the product's current status and stores the target status of
+// the transition taken, with facts.Reason as error message. The row stays locked between check and
+// update, so concurrent triggers cannot both pass. Illegal transitions return lifecycle.ErrIllegalTransition.
+func (db *DB) TransitionProduct(ctx context.Context, m *lifecycle.Machine, asin, trigger string, facts lifecycle.Facts) (lifecycle.Transition, error) {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return lifecycle.Transition{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var current ProductStatus
+	if err := tx.QueryRow(ctx, `SELECT status FROM products WHERE asin = $1 FOR UPDATE`, asin).Scan(&current); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return lifecycle.Transition{}, fmt.Errorf("%w: %s", ErrProductNotFound, asin)
+		}
+		return lifecycle.Transition{}, fmt.Errorf("failed to lock product: %w", err)
+	}
+
+	t, err := m.Fire(current, trigger, facts)
+	if err != nil {
+		return lifecycle.Transition{}, fmt.Errorf("product %s: %w", asin, err)
+	}
+
+	query := `
+		UPDATE products SET
+			status = $2,
+			error_message = NULLIF($3, ''),
+			retired_at = CASE WHEN $2 = $4 THEN CURRENT_TIMESTAMP ELSE retired_at END,
+			claimed_by = NULL,
+			lease_expires_at = NULL,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE asin = $1`
+
+	if _, err := tx.Exec(ctx, query, asin, t.To, facts.Reason, StatusRetired); err != nil {
+		return lifecycle.Transition{}, fmt.Errorf("failed to update product status: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return lifecycle.Transition{}, fmt.Errorf("failed to commit status transition: %w", err)
+	}
+	return t, nil
+}
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Fields whose provenance is tracked. The size table holds the garment dimensions.
+const (
+	FieldPrice     = "price"
+	FieldSizeTable = "size_table"
+	FieldMaterial  = "material"
+)
+
+// Provenance sources, how a field was extracted
+const (
+	ProvenanceTable       = "structured_table" // Size chart popover, description or A+ table
+	ProvenanceOCR         = "ocr"              // Text recognized in a size chart image
+	ProvenanceDescription = "description_text" // Values mentioned in bullet points or description prose
+	ProvenanceRegex       = "regex"            // Pattern matching on the page HTML
+	ProvenancePage        = "page"             // Dedicated element of the product page, e.g. the price block
+	ProvenancePAAPI       = "pa-api"           // Product Advertising API fallback
+	ProvenanceManual      = "manual"           // Uploaded as a correction, see SaveManualSizeTable
+)
+
+// FieldProvenance is where and when the stored value of a field was extracted
+type FieldProvenance struct {
+	Source      string    `json:"source"`           // One of the Provenance constants
+	Detail      string    `json:"detail,omitempty"` // Finer origin, e.g. the size table source aplus
+	ExtractedAt time.Time `json:"extracted_at"`
+}
+
+// Provenance maps tracked fields to their origin
+type Provenance map[string]FieldProvenance
+
+// Record sets the origin of a field
+func (p Provenance) Record(field, source, detail string, at time.Time) {
+	p[field] = FieldProvenance{Source: source, Detail: detail, ExtractedAt: at.UTC()}
+}
+
+// RecordSizeTable sets the origin of a size table from its Source, tables without source come from the
+// size chart popover
+func (p Provenance) RecordSizeTable(st *SizeTable, at time.Time) {
+	if st == nil {
+		return
+	}
+	detail := st.Source
+	if detail == "" {
+		detail = SizeTableSourceHTML
+	}
+	source := ProvenanceTable
+	switch detail {
+	case SizeTableSourceOCR:
+		source = ProvenanceOCR
+	case SizeTableSourceDescriptionText:
+		source = ProvenanceDescription
+	case SizeTableSourceManual:
+		source = ProvenanceManual
+	}
+	p.Record(FieldSizeTable, source, detail, at)
+}
+
+// JSON returns the provenance for a JSONB column, nil when empty
+func (p Provenance) JSON() json.RawMessage {
+	if len(p) == 0 {
+		return nil
+	}
+	data, _ := json.Marshal(p)
+	return data
+}
+
+// UpdateProductProvenance merges the origin of freshly extracted fields into a product, fields not in p
+// keep their earlier origin
+func (db *DB) UpdateProductProvenance(ctx context.Context, asin string, p Provenance) error {
+	if len(p) == 0 {
+		return nil
+	}
+
+	query := `UPDATE products SET provenance = COALESCE(provenance, '{}'::jsonb) || $2 WHERE asin = $1`
+	if _, err := db.pool.Exec(ctx, query, asin, p.JSON()); err != nil {
+		return fmt.Errorf("failed to update provenance: %w", err)
+	}
+	return nil
+}
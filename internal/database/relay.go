@@ -4,64 +4,132 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/maltedev/amazon-size-scraper/internal/chaos"
+	"github.com/maltedev/amazon-size-scraper/internal/eventroute"
+	"github.com/maltedev/amazon-size-scraper/internal/schema"
+	logging "github.com/maltedev/amazon-size-scraper/pkg/logger"
 	"github.com/redis/go-redis/v9"
 )
 
 // RedisClient interface for Redis operations (for testing)
 type RedisClient interface {
 	XAdd(ctx context.Context, args *redis.XAddArgs) *redis.StringCmd
+	XLen(ctx context.Context, stream string) *redis.IntCmd
+	XInfoGroups(ctx context.Context, key string) *redis.XInfoGroupsCmd
+	Pipelined(ctx context.Context, fn func(redis.Pipeliner) error) ([]redis.Cmder, error)
 	Close() error
 }
 
+// Dispatcher delivers events to targets other than Redis streams, e.g. webhooks and Kafka topics
+type Dispatcher interface {
+	Deliver(ctx context.Context, target eventroute.Target, event *schema.Event) error
+}
+
+// Elector decides which of several relay instances sharing the outbox publishes it, see AdvisoryLock
+type Elector interface {
+	// Lead reports whether this instance leads, trying to take over the leadership if it does not
+	Lead(ctx context.Context) (bool, error)
+	// Resign gives up the leadership
+	Resign(ctx context.Context) error
+}
+
 // OutboxRepo interface for outbox operations (for testing)
 type OutboxRepo interface {
-	GetPending(ctx context.Context, limit int) ([]*OutboxEvent, error)
+	GetPending(ctx context.Context, limit int, excludeStreams []string) ([]*OutboxEvent, error)
 	MarkProcessed(ctx context.Context, id uuid.UUID) error
 	MarkFailed(ctx context.Context, id uuid.UUID, err error) error
+	MarkBatch(ctx context.Context, outcomes []OutboxOutcome) error
 }
 
 // Relay processes events from the outbox table to Redis streams
 type Relay struct {
-	db        *DB
-	redis     RedisClient
-	outbox    OutboxRepo
-	logger    *slog.Logger
-	interval  time.Duration
-	batchSize int
+	db            *DB
+	redis         RedisClient
+	outbox        OutboxRepo
+	logger        *slog.Logger
+	interval      time.Duration
+	batchSize     int
+	schemaVersion int
+	maxStreamLen  int64
+	maxBacklog    int64
+	maxLag        int64
+	limits        schema.PayloadLimits
+	faults        *chaos.Injector
+	dispatcher    Dispatcher
+	paused        atomic.Bool
+
+	elector      Elector
+	instanceID   string
+	leader       atomic.Bool
+	leaderSince  atomic.Pointer[time.Time]
+	acquisitions atomic.Int64
 }
 
 // RelayConfig contains configuration for the relay
 type RelayConfig struct {
-	PollInterval time.Duration
-	BatchSize    int
+	PollInterval  time.Duration
+	BatchSize     int
+	SchemaVersion int   // Stream layout version, see schema.VersionV1/VersionV2
+	MaxStreamLen  int64 // Approximate MAXLEN applied on XADD, 0 disables trimming
+	MaxBacklog    int64 // Pause publishing above this stream length, 0 disables
+	MaxLag        int64 // Pause publishing above this consumer group lag, 0 disables
+
+	PayloadEncoding   string // Compress large payloads with schema.EncodingGzip or schema.EncodingZstd, empty disables
+	CompressThreshold int    // Payloads above this many bytes are compressed
+	MaxPayloadSize    int    // Hard limit on published payloads, optional fields are dropped to fit, 0 disables
+
+	Faults *chaos.Injector // Failures, delays and duplicates injected in non-production environments, nil disables
+
+	Dispatcher Dispatcher // Delivers events routed to webhooks and Kafka, nil fails them
+
+	Elector    Elector // Lets only one of several instances publish, nil publishes unconditionally
+	InstanceID string  // Names this instance in logs, health and metrics
 }
 
 // NewRelay creates a new relay instance
-func NewRelay(db *DB, redisClient *redis.Client, logger *slog.Logger, config RelayConfig) *Relay {
+func NewRelay(db *DB, redisClient RedisClient, logger *slog.Logger, config RelayConfig) *Relay {
 	if config.PollInterval == 0 {
 		config.PollInterval = 5 * time.Second
 	}
 	if config.BatchSize == 0 {
 		config.BatchSize = 100
 	}
+	if config.SchemaVersion == 0 {
+		config.SchemaVersion = schema.CurrentVersion
+	}
 
 	return &Relay{
-		db:        db,
-		redis:     redisClient,
-		outbox:    NewOutboxRepository(db),
-		logger:    logger.With("component", "relay"),
-		interval:  config.PollInterval,
-		batchSize: config.BatchSize,
+		db:            db,
+		redis:         redisClient,
+		outbox:        NewOutboxRepository(db),
+		logger:        logger.With("component", "relay"),
+		interval:      config.PollInterval,
+		batchSize:     config.BatchSize,
+		schemaVersion: config.SchemaVersion,
+		maxStreamLen:  config.MaxStreamLen,
+		maxBacklog:    config.MaxBacklog,
+		maxLag:        config.MaxLag,
+		limits: schema.PayloadLimits{
+			Encoding:          config.PayloadEncoding,
+			CompressThreshold: config.CompressThreshold,
+			MaxSize:           config.MaxPayloadSize,
+		},
+		faults:     config.Faults,
+		dispatcher: config.Dispatcher,
+		elector:    config.Elector,
+		instanceID: config.InstanceID,
 	}
 }
 
 // Start begins processing events from the outbox
 func (r *Relay) Start(ctx context.Context) error {
-	r.logger.Info("starting relay", 
+	r.logger.InfoContext(ctx, "starting relay", 
 		"interval", r.interval, 
 		"batch_size", r.batchSize)
 
@@ -70,17 +138,18 @@ func (r *Relay) Start(ctx context.Context) error {
 
 	// Process immediately on start
 	if err := r.processEvents(ctx); err != nil {
-		r.logger.Error("failed to process events on startup", "error", err)
+		r.logger.ErrorContext(ctx, "failed to process events on startup", "error", err)
 	}
 
 	for {
 		select {
 		case <-ctx.Done():
-			r.logger.Info("relay stopped")
+			r.resign(ctx)
+			r.logger.InfoContext(ctx, "relay stopped")
 			return ctx.Err()
 		case <-ticker.C:
 			if err := r.processEvents(ctx); err != nil {
-				r.logger.Error("failed to process events", "error", err)
+				r.logger.ErrorContext(ctx, "failed to process events", "error", err)
 				// Continue running even on error
 			}
 		}
@@ -89,109 +158,396 @@ func (r *Relay) Start(ctx context.Context) error {
 
 // processEvents fetches and processes a batch of events
 func (r *Relay) processEvents(ctx context.Context) error {
-	events, err := r.outbox.GetPending(ctx, r.batchSize)
+	if !r.lead(ctx) {
+		return nil
+	}
+	if err := r.faults.Delay(ctx); err != nil {
+		return err
+	}
+
+	batch, throttled, err := r.pendingBatch(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get pending events: %w", err)
+		return err
 	}
+	r.paused.Store(len(throttled) > 0)
 
-	if len(events) == 0 {
+	if len(batch) == 0 {
 		return nil
 	}
 
-	r.logger.Debug("processing events", "count", len(events))
+	r.logger.DebugContext(ctx, "processing events", "count", len(batch))
 
-	for _, event := range events {
-		if err := r.processEvent(ctx, event); err != nil {
-			r.logger.Error("failed to process event", 
+	// Publish the whole batch in one round trip and record every outcome in one update
+	outcomes := r.publishBatch(ctx, batch)
+	if err := r.outbox.MarkBatch(ctx, outcomes); err != nil {
+		return fmt.Errorf("failed to mark batch: %w", err)
+	}
+
+	for i, outcome := range outcomes {
+		event := batch[i]
+		ctx := ctx
+		if event.TraceID != "" {
+			ctx = logging.WithTraceID(ctx, event.TraceID)
+		}
+		if outcome.Err != nil {
+			r.logger.ErrorContext(ctx, "failed to process event",
 				"event_id", event.ID,
 				"aggregate_id", event.AggregateID,
-				"error", err)
-			// Continue with other events
+				"error", outcome.Err)
+			continue
 		}
+		r.logger.InfoContext(ctx, "event processed successfully",
+			"event_id", event.ID,
+			"event_type", event.EventType,
+			"aggregate_id", event.AggregateID,
+			"target_stream", event.TargetStream)
 	}
 
 	return nil
 }
 
-// processEvent processes a single event
-func (r *Relay) processEvent(ctx context.Context, event *OutboxEvent) error {
-	// Publish to Redis
-	if err := r.publishToRedis(ctx, event); err != nil {
-		// Mark as failed
-		if markErr := r.outbox.MarkFailed(ctx, event.ID, err); markErr != nil {
-			r.logger.Error("failed to mark event as failed", 
-				"event_id", event.ID,
-				"error", markErr)
+// pendingBatch fetches the next batch of pending events whose target is not throttled. Backpressure is
+// evaluated once per target stream and batch, other targets are never throttled. Events of a throttled
+// stream stay pending and are excluded from the query, so they cannot fill every batch and starve the
+// other streams. Returns the batch and the throttled streams.
+func (r *Relay) pendingBatch(ctx context.Context) ([]*OutboxEvent, []string, error) {
+	var throttled []string
+	checked := make(map[string]bool)
+	for {
+		events, err := r.outbox.GetPending(ctx, r.batchSize, throttled)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get pending events: %w", err)
+		}
+
+		refetch := false
+		for _, event := range events {
+			if checked[event.TargetStream] {
+				continue
+			}
+			checked[event.TargetStream] = true
+			target, err := eventroute.ParseTarget(event.TargetStream)
+			if err == nil && target.Kind == eventroute.KindRedis && r.shouldThrottle(ctx, target.Name) {
+				throttled = append(throttled, event.TargetStream)
+				refetch = true
+			}
+		}
+		// Every refetch excludes another stream, so this ends after at most one query per stream
+		if !refetch {
+			return events, throttled, nil
+		}
+	}
+}
+
+// publishBatch sends the XADDs of all events in a single pipeline and delivers events routed elsewhere
+// through the dispatcher, returning one outcome per event in order
+func (r *Relay) publishBatch(ctx context.Context, events []*OutboxEvent) []OutboxOutcome {
+	outcomes := make([]OutboxOutcome, len(events))
+	cmds := make([]*redis.StringCmd, len(events))
+	var dispatched []int
+
+	// A failed pipeline still returns the per-command results, so the error is checked per event below
+	_, _ = r.redis.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, event := range events {
+			outcomes[i] = OutboxOutcome{ID: event.ID, RetryCount: event.RetryCount}
+
+			target, err := eventroute.ParseTarget(event.TargetStream)
+			if err != nil {
+				outcomes[i].Err = err
+				continue
+			}
+			if target.Kind != eventroute.KindRedis {
+				dispatched = append(dispatched, i)
+				continue
+			}
+
+			args, err := r.streamArgs(event)
+			if err != nil {
+				outcomes[i].Err = err
+				continue
+			}
+			if err := r.faults.FailPublish(); err != nil {
+				outcomes[i].Err = err
+				continue
+			}
+			cmds[i] = pipe.XAdd(ctx, args)
+			if r.faults.Duplicate() {
+				// Consumers must tolerate redelivery, the duplicate carries the same event ID
+				r.logger.WarnContext(ctx, "injecting duplicate stream message", "event_id", event.ID)
+				pipe.XAdd(ctx, args)
+			}
 		}
+		return nil
+	})
+
+	for i, cmd := range cmds {
+		if cmd == nil {
+			continue
+		}
+		if err := cmd.Err(); err != nil {
+			outcomes[i].Err = fmt.Errorf("failed to publish to redis: %w", err)
+		}
+	}
+
+	for _, i := range dispatched {
+		outcomes[i].Err = r.dispatch(ctx, events[i])
+	}
+	return outcomes
+}
+
+// dispatch delivers an event to its webhook or Kafka target
+func (r *Relay) dispatch(ctx context.Context, event *OutboxEvent) error {
+	target, err := eventroute.ParseTarget(event.TargetStream)
+	if err != nil {
 		return err
 	}
+	if r.dispatcher == nil {
+		return fmt.Errorf("no dispatcher configured for %s targets", target.Kind)
+	}
 
-	// Mark as processed
-	if err := r.outbox.MarkProcessed(ctx, event.ID); err != nil {
-		r.logger.Error("failed to mark event as processed", 
-			"event_id", event.ID,
-			"error", err)
+	streamEvent, err := r.streamEvent(event)
+	if err != nil {
+		return err
+	}
+	if err := r.faults.FailPublish(); err != nil {
+		return err
+	}
+	if err := r.dispatcher.Deliver(ctx, target, streamEvent); err != nil {
 		return err
 	}
+	if r.faults.Duplicate() {
+		r.logger.WarnContext(ctx, "injecting duplicate delivery", "event_id", event.ID)
+		return r.dispatcher.Deliver(ctx, target, streamEvent)
+	}
+	return nil
+}
 
-	r.logger.Info("event processed successfully",
-		"event_id", event.ID,
-		"event_type", event.EventType,
-		"aggregate_id", event.AggregateID,
-		"target_stream", event.TargetStream)
+// shouldThrottle reports whether publishing to stream must pause because consumers fall behind
+func (r *Relay) shouldThrottle(ctx context.Context, stream string) bool {
+	if r.maxBacklog > 0 {
+		length, err := r.redis.XLen(ctx, stream).Result()
+		if err != nil {
+			r.logger.DebugContext(ctx, "failed to get stream length", "stream", stream, "error", err)
+		} else if length > r.maxBacklog {
+			r.logger.WarnContext(ctx, "pausing publishing, stream backlog too large",
+				"stream", stream,
+				"length", length,
+				"max_backlog", r.maxBacklog)
+			return true
+		}
+	}
 
-	return nil
+	if r.maxLag > 0 {
+		groups, err := r.redis.XInfoGroups(ctx, stream).Result()
+		if err != nil {
+			// Stream or groups may not exist yet, nothing to wait for
+			r.logger.DebugContext(ctx, "failed to get consumer groups", "stream", stream, "error", err)
+			return false
+		}
+		for _, g := range groups {
+			// Lag is -1 when Redis cannot determine it, fall back to unacknowledged entries
+			lag := g.Pending
+			if g.Lag > 0 {
+				lag += g.Lag
+			}
+			if lag > r.maxLag {
+				r.logger.WarnContext(ctx, "pausing publishing, consumer group lagging",
+					"stream", stream,
+					"group", g.Name,
+					"lag", lag,
+					"max_lag", r.maxLag)
+				return true
+			}
+		}
+	}
+
+	return false
 }
 
-// publishToRedis publishes an event to Redis stream
-func (r *Relay) publishToRedis(ctx context.Context, event *OutboxEvent) error {
-	// Parse the event payload
-	var payload map[string]interface{}
-	if err := json.Unmarshal(event.Payload, &payload); err != nil {
-		return fmt.Errorf("failed to unmarshal payload: %w", err)
+// lead reports whether this instance may publish, instances without elector always do
+func (r *Relay) lead(ctx context.Context) bool {
+	if r.elector == nil {
+		return true
 	}
 
-	// Create the stream data structure expected by consumers
-	streamData := map[string]interface{}{
-		"id":             event.ID.String(),
-		"type":           event.EventType,
-		"aggregate_type": event.AggregateType,
-		"aggregate_id":   event.AggregateID,
-		"timestamp":      event.CreatedAt.Format(time.RFC3339),
-		"payload":        payload,
-		"metadata": map[string]interface{}{
-			"source":        "amazon-scraper",
-			"outbox_id":     event.ID.String(),
-			"retry_count":   event.RetryCount,
-			"target_stream": event.TargetStream,
-		},
+	leading, err := r.elector.Lead(ctx)
+	if err != nil {
+		r.logger.WarnContext(ctx, "relay leader election failed", "instance", r.instanceID, "error", err)
+	}
+	if leading != r.leader.Swap(leading) {
+		if leading {
+			now := time.Now()
+			r.leaderSince.Store(&now)
+			r.acquisitions.Add(1)
+			r.logger.InfoContext(ctx, "relay became leader", "instance", r.instanceID)
+		} else {
+			r.leaderSince.Store(nil)
+			r.logger.WarnContext(ctx, "relay lost leadership", "instance", r.instanceID)
+		}
+	}
+	return leading
+}
+
+// resign hands the leadership over when the relay stops
+func (r *Relay) resign(ctx context.Context) {
+	if r.elector == nil || !r.leader.Swap(false) {
+		return
+	}
+	r.leaderSince.Store(nil)
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := r.elector.Resign(ctx); err != nil {
+		r.logger.WarnContext(ctx, "failed to resign relay leadership", "instance", r.instanceID, "error", err)
+		return
+	}
+	r.logger.InfoContext(ctx, "relay resigned leadership", "instance", r.instanceID)
+}
+
+// LeaderStatus describes whether this instance publishes the outbox
+type LeaderStatus struct {
+	Instance     string     `json:"instance"`
+	Elected      bool       `json:"elected"` // Leader election is enabled
+	Leader       bool       `json:"leader"`
+	Since        *time.Time `json:"since,omitempty"`
+	Acquisitions int64      `json:"acquisitions"` // Times this instance became leader
+}
+
+// Leadership returns whether this instance currently publishes the outbox
+func (r *Relay) Leadership() LeaderStatus {
+	if r.elector == nil {
+		return LeaderStatus{Instance: r.instanceID, Leader: true}
+	}
+	return LeaderStatus{
+		Instance:     r.instanceID,
+		Elected:      true,
+		Leader:       r.leader.Load(),
+		Since:        r.leaderSince.Load(),
+		Acquisitions: r.acquisitions.Load(),
+	}
+}
+
+// WriteMetrics writes the leadership of this instance in the Prometheus text format
+func (r *Relay) WriteMetrics(w io.Writer) {
+	s := r.Leadership()
+	leader := 0
+	if s.Leader {
+		leader = 1
+	}
+	fmt.Fprintf(w, "# HELP scraper_relay_leader Whether this instance publishes the outbox.\n# TYPE scraper_relay_leader gauge\n")
+	fmt.Fprintf(w, "scraper_relay_leader{instance=%q} %d\n", s.Instance, leader)
+	fmt.Fprintf(w, "# HELP scraper_relay_leader_acquisitions_total Times this instance became outbox relay leader.\n# TYPE scraper_relay_leader_acquisitions_total counter\n")
+	fmt.Fprintf(w, "scraper_relay_leader_acquisitions_total{instance=%q} %d\n", s.Instance, s.Acquisitions)
+}
+
+// IsPaused reports whether the last batch was held back by backpressure
+func (r *Relay) IsPaused() bool {
+	return r.paused.Load()
+}
+
+// streamArgs encodes an event into the XADD arguments for its target stream
+func (r *Relay) streamArgs(event *OutboxEvent) (*redis.XAddArgs, error) {
+	target, err := eventroute.ParseTarget(event.TargetStream)
+	if err != nil {
+		return nil, err
+	}
+	if target.Kind != eventroute.KindRedis {
+		return nil, fmt.Errorf("target %s is not a redis stream", event.TargetStream)
 	}
 
-	// Marshal to JSON for the data field
-	dataJSON, err := json.Marshal(streamData)
+	streamEvent, err := r.streamEvent(event)
 	if err != nil {
-		return fmt.Errorf("failed to marshal stream data: %w", err)
+		return nil, err
+	}
+
+	values, err := schema.EncodeStreamValues(streamEvent, streamEvent.SchemaVersion)
+	if err != nil {
+		return nil, err
 	}
 
 	// Publish to Redis stream
 	args := &redis.XAddArgs{
-		Stream: event.TargetStream,
-		Values: map[string]interface{}{
-			"data":          string(dataJSON),
-			"type":          event.EventType,
-			"timestamp":     fmt.Sprintf("%d", event.CreatedAt.UnixNano()),
-			"original_id":   event.ID.String(),
-			"aggregate_id":  event.AggregateID,
-			"aggregate_type": event.AggregateType,
-			"event_type":    event.EventType,
-		},
+		Stream: target.Name,
+		Values: values,
+	}
+	if r.maxStreamLen > 0 {
+		args.MaxLen = r.maxStreamLen
+		args.Approx = true
 	}
 
-	if _, err := r.redis.XAdd(ctx, args).Result(); err != nil {
-		return fmt.Errorf("failed to publish to redis: %w", err)
+	return args, nil
+}
+
+// streamEvent encodes an outbox event into the message consumers receive, in the relay's schema version
+func (r *Relay) streamEvent(event *OutboxEvent) (*schema.Event, error) {
+	if !json.Valid(event.Payload) {
+		return nil, fmt.Errorf("failed to unmarshal payload: invalid JSON")
 	}
 
-	return nil
+	version := r.schemaVersion
+	if version == 0 {
+		version = schema.CurrentVersion
+	}
+
+	payload := event.Payload
+	if version == schema.VersionV1 && event.AggregateType == "product" {
+		// Downgrade product payloads for consumers still on the tall-affiliate-common v1 structs
+		product, err := schema.DecodeProductPayload(0, event.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode product payload: %w", err)
+		}
+		if payload, err = schema.EncodeProductPayload(product, schema.VersionV1); err != nil {
+			return nil, err
+		}
+	}
+
+	// Keep big payloads under the message limit of the stream
+	payload, encoding, dropped, err := r.limits.Apply(payload)
+	if err != nil {
+		return nil, err
+	}
+	if len(dropped) > 0 {
+		r.logger.Warn("truncated oversized payload",
+			"event_id", event.ID,
+			"aggregate_id", event.AggregateID,
+			"dropped", dropped)
+	}
+
+	// Create the stream data structure expected by consumers
+	streamEvent := &schema.Event{
+		ID:            event.ID.String(),
+		Type:          event.EventType,
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		Timestamp:     event.CreatedAt,
+		Payload:       payload,
+		Metadata: map[string]interface{}{
+			"source":        "amazon-scraper",
+			"outbox_id":     event.ID.String(),
+			"retry_count":   event.RetryCount,
+			"target_stream": event.TargetStream,
+		},
+	}
+	// Metadata of the job or request that emitted the event, e.g. a campaign ID, never replaces the relay's keys
+	for key, value := range event.Metadata {
+		if !schema.ReservedMetadata(key) {
+			streamEvent.Metadata[key] = value
+		}
+	}
+	if encoding != "" {
+		streamEvent.Metadata[schema.MetadataContentEncoding] = encoding
+	}
+	if event.TraceID != "" {
+		streamEvent.Metadata[schema.MetadataTraceID] = event.TraceID
+	}
+	if event.Replayed {
+		streamEvent.Metadata[schema.MetadataReplayed] = true
+	}
+	streamEvent.SchemaVersion = schema.VersionV2
+	if version == schema.VersionV1 {
+		streamEvent.SchemaVersion = schema.VersionV1
+	}
+
+	return streamEvent, nil
 }
 
 // GetPendingCount returns the number of pending events in the outbox
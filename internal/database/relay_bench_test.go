@@ -0,0 +1,72 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+func benchEvents(n int) []*OutboxEvent {
+	events := make([]*OutboxEvent, n)
+	for i := range events {
+		events[i] = &OutboxEvent{
+			ID:            uuid.New(),
+			AggregateType: "product",
+			AggregateID:   fmt.Sprintf("B%09d", i),
+			EventType:     "NEW_PRODUCT_DETECTED",
+			Payload:       json.RawMessage(`{"asin":"B000TEST"}`),
+			TargetStream:  "stream:product_lifecycle",
+			CreatedAt:     time.Now(),
+		}
+	}
+	return events
+}
+
+// BenchmarkRelayPublish compares one XADD round trip per event with a pipelined batch, against an
+// in-process Redis server so both pay a real round trip over TCP
+func BenchmarkRelayPublish(b *testing.B) {
+	ctx := context.Background()
+	server := miniredis.RunT(b)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	// Trimmed like in production, otherwise the stream grows with b.N
+	relay := &Relay{redis: client, logger: slog.Default(), maxStreamLen: 10000}
+
+	for _, size := range []int{10, 100} {
+		events := benchEvents(size)
+
+		b.Run(fmt.Sprintf("sequential/%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, event := range events {
+					args, err := relay.streamArgs(event)
+					if err != nil {
+						b.Fatal(err)
+					}
+					if err := client.XAdd(ctx, args).Err(); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+			b.ReportMetric(float64(b.N*size)/b.Elapsed().Seconds(), "events/s")
+		})
+
+		b.Run(fmt.Sprintf("pipelined/%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, outcome := range relay.publishBatch(ctx, events) {
+					if outcome.Err != nil {
+						b.Fatal(outcome.Err)
+					}
+				}
+			}
+			b.ReportMetric(float64(b.N*size)/b.Elapsed().Seconds(), "events/s")
+		})
+	}
+}
@@ -31,6 +31,43 @@ func (m *MockRedisClient) XAdd(ctx context.Context, args *redis.XAddArgs) *redis
 	return cmd
 }
 
+func (m *MockRedisClient) XLen(ctx context.Context, stream string) *redis.IntCmd {
+	mockArgs := m.Called(ctx, stream)
+	cmd := redis.NewIntCmd(ctx)
+	cmd.SetVal(mockArgs.Get(0).(int64))
+	cmd.SetErr(mockArgs.Error(1))
+	return cmd
+}
+
+func (m *MockRedisClient) XInfoGroups(ctx context.Context, key string) *redis.XInfoGroupsCmd {
+	mockArgs := m.Called(ctx, key)
+	cmd := redis.NewXInfoGroupsCmd(ctx, key)
+	if groups, ok := mockArgs.Get(0).([]redis.XInfoGroup); ok {
+		cmd.SetVal(groups)
+	}
+	cmd.SetErr(mockArgs.Error(1))
+	return cmd
+}
+
+func (m *MockRedisClient) Pipelined(ctx context.Context, fn func(redis.Pipeliner) error) ([]redis.Cmder, error) {
+	pipe := &mockPipeline{client: m}
+	err := fn(pipe)
+	return pipe.cmds, err
+}
+
+// mockPipeline records XAdds against the mock client, other Pipeliner methods are not used by the relay
+type mockPipeline struct {
+	redis.Pipeliner
+	client *MockRedisClient
+	cmds   []redis.Cmder
+}
+
+func (p *mockPipeline) XAdd(ctx context.Context, args *redis.XAddArgs) *redis.StringCmd {
+	cmd := p.client.XAdd(ctx, args)
+	p.cmds = append(p.cmds, cmd)
+	return cmd
+}
+
 func (m *MockRedisClient) Close() error {
 	args := m.Called()
 	return args.Error(0)
@@ -41,8 +78,8 @@ type MockOutboxRepository struct {
 	mock.Mock
 }
 
-func (m *MockOutboxRepository) GetPending(ctx context.Context, limit int) ([]*OutboxEvent, error) {
-	args := m.Called(ctx, limit)
+func (m *MockOutboxRepository) GetPending(ctx context.Context, limit int, excludeStreams []string) ([]*OutboxEvent, error) {
+	args := m.Called(ctx, limit, excludeStreams)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -59,6 +96,32 @@ func (m *MockOutboxRepository) MarkFailed(ctx context.Context, id uuid.UUID, err
 	return args.Error(0)
 }
 
+func (m *MockOutboxRepository) MarkBatch(ctx context.Context, outcomes []OutboxOutcome) error {
+	args := m.Called(ctx, outcomes)
+	return args.Error(0)
+}
+
+// batchOutcome matches a MarkBatch call where the event with id has the given error, nil meaning published
+func batchOutcome(id uuid.UUID, wantErr string) interface{} {
+	return mock.MatchedBy(func(outcomes []OutboxOutcome) bool {
+		for _, o := range outcomes {
+			if o.ID == id {
+				if wantErr == "" {
+					return o.Err == nil
+				}
+				return o.Err != nil && o.Err.Error() == wantErr
+			}
+		}
+		return false
+	})
+}
+
+// streamValues returns the fields of an XADD, the relay always encodes them as a map
+func streamValues(args *redis.XAddArgs) map[string]interface{} {
+	values, _ := args.Values.(map[string]interface{})
+	return values
+}
+
 func TestRelay_ProcessEvents(t *testing.T) {
 	ctx := context.Background()
 	logger := slog.Default()
@@ -94,18 +157,19 @@ func TestRelay_ProcessEvents(t *testing.T) {
 			},
 		}
 
-		mockOutbox.On("GetPending", ctx, 10).Return(events, nil)
+		mockOutbox.On("GetPending", ctx, 10, []string(nil)).Return(events, nil)
 
 		// Expect Redis XAdd for each event
 		for _, event := range events {
 			mockRedis.On("XAdd", ctx, mock.MatchedBy(func(args *redis.XAddArgs) bool {
 				return args.Stream == event.TargetStream &&
-					args.Values["event_type"] == event.EventType &&
-					args.Values["aggregate_id"] == event.AggregateID
+					streamValues(args)["event_type"] == event.EventType &&
+					streamValues(args)["aggregate_id"] == event.AggregateID
 			})).Return(nil)
-			
-			mockOutbox.On("MarkProcessed", ctx, event.ID).Return(nil)
 		}
+		mockOutbox.On("MarkBatch", ctx, mock.MatchedBy(func(outcomes []OutboxOutcome) bool {
+			return len(outcomes) == 2 && outcomes[0].Err == nil && outcomes[1].Err == nil
+		})).Return(nil)
 
 		err := relay.processEvents(ctx)
 		require.NoError(t, err)
@@ -134,16 +198,14 @@ func TestRelay_ProcessEvents(t *testing.T) {
 			TargetStream:  "stream:product_lifecycle",
 		}
 
-		mockOutbox.On("GetPending", ctx, 10).Return([]*OutboxEvent{event}, nil)
+		mockOutbox.On("GetPending", ctx, 10, []string(nil)).Return([]*OutboxEvent{event}, nil)
 		
 		// Simulate Redis error
 		redisErr := errors.New("redis connection failed")
 		mockRedis.On("XAdd", ctx, mock.Anything).Return(redisErr)
 		
 		// Should mark as failed
-		mockOutbox.On("MarkFailed", ctx, event.ID, mock.MatchedBy(func(err error) bool {
-			return err.Error() == "failed to publish to redis: redis connection failed"
-		})).Return(nil)
+		mockOutbox.On("MarkBatch", ctx, batchOutcome(event.ID, "failed to publish to redis: redis connection failed")).Return(nil)
 
 		err := relay.processEvents(ctx)
 		assert.NoError(t, err) // processEvents should not fail on individual event errors
@@ -163,7 +225,7 @@ func TestRelay_ProcessEvents(t *testing.T) {
 			batchSize: 10,
 		}
 
-		mockOutbox.On("GetPending", ctx, 10).Return([]*OutboxEvent{}, nil)
+		mockOutbox.On("GetPending", ctx, 10, []string(nil)).Return([]*OutboxEvent{}, nil)
 
 		err := relay.processEvents(ctx)
 		require.NoError(t, err)
@@ -203,19 +265,22 @@ func TestRelay_ProcessEvents(t *testing.T) {
 			},
 		}
 
-		mockOutbox.On("GetPending", ctx, 10).Return(events, nil)
+		mockOutbox.On("GetPending", ctx, 10, []string(nil)).Return(events, nil)
 
 		// First event fails
 		mockRedis.On("XAdd", ctx, mock.MatchedBy(func(args *redis.XAddArgs) bool {
-			return args.Values["aggregate_id"] == "B001TEST"
+			return streamValues(args)["aggregate_id"] == "B001TEST"
 		})).Return(errors.New("redis error"))
-		mockOutbox.On("MarkFailed", ctx, events[0].ID, mock.Anything).Return(nil)
 
 		// Second event succeeds
 		mockRedis.On("XAdd", ctx, mock.MatchedBy(func(args *redis.XAddArgs) bool {
-			return args.Values["aggregate_id"] == "B002TEST"
+			return streamValues(args)["aggregate_id"] == "B002TEST"
+		})).Return(nil)
+
+		// Both outcomes are recorded in one update
+		mockOutbox.On("MarkBatch", ctx, mock.MatchedBy(func(outcomes []OutboxOutcome) bool {
+			return len(outcomes) == 2 && outcomes[0].Err != nil && outcomes[1].Err == nil
 		})).Return(nil)
-		mockOutbox.On("MarkProcessed", ctx, events[1].ID).Return(nil)
 
 		err := relay.processEvents(ctx)
 		require.NoError(t, err)
@@ -225,7 +290,7 @@ func TestRelay_ProcessEvents(t *testing.T) {
 	})
 }
 
-func TestRelay_PublishToRedis(t *testing.T) {
+func TestRelay_PublishBatch(t *testing.T) {
 	ctx := context.Background()
 	logger := slog.Default()
 
@@ -251,7 +316,7 @@ func TestRelay_PublishToRedis(t *testing.T) {
 
 		mockRedis.On("XAdd", ctx, mock.MatchedBy(func(args *redis.XAddArgs) bool {
 			// Verify the stream data format
-			val, ok := args.Values["data"].(string)
+			val, ok := streamValues(args)["data"].(string)
 			if !ok {
 				return false
 			}
@@ -270,8 +335,8 @@ func TestRelay_PublishToRedis(t *testing.T) {
 				data["timestamp"] != nil
 		})).Return(nil)
 
-		err := relay.publishToRedis(ctx, event)
-		require.NoError(t, err)
+		outcomes := relay.publishBatch(ctx, []*OutboxEvent{event})
+		require.NoError(t, outcomes[0].Err)
 
 		mockRedis.AssertExpectations(t)
 	})
@@ -297,7 +362,7 @@ func TestRelay_PublishToRedis(t *testing.T) {
 		}
 
 		mockRedis.On("XAdd", ctx, mock.MatchedBy(func(args *redis.XAddArgs) bool {
-			val, ok := args.Values["data"].(string)
+			val, ok := streamValues(args)["data"].(string)
 			if !ok {
 				return false
 			}
@@ -316,10 +381,208 @@ func TestRelay_PublishToRedis(t *testing.T) {
 			return metadata["source"] == "amazon-scraper"
 		})).Return(nil)
 
-		err := relay.publishToRedis(ctx, event)
+		outcomes := relay.publishBatch(ctx, []*OutboxEvent{event})
+		require.NoError(t, outcomes[0].Err)
+
+		mockRedis.AssertExpectations(t)
+	})
+
+	t.Run("publish v1 flat layout when configured", func(t *testing.T) {
+		mockRedis := new(MockRedisClient)
+		mockOutbox := new(MockOutboxRepository)
+
+		relay := &Relay{
+			redis:         mockRedis,
+			outbox:        mockOutbox,
+			logger:        logger,
+			schemaVersion: 1,
+		}
+
+		event := &OutboxEvent{
+			ID:            uuid.New(),
+			AggregateType: "product",
+			AggregateID:   "B001TEST",
+			EventType:     "NEW_PRODUCT_DETECTED",
+			Payload:       json.RawMessage(`{"asin":"B001TEST","price":{"amount":19.99,"currency":"EUR"},"images":["a.jpg"]}`),
+			TargetStream:  "stream:product_lifecycle",
+			CreatedAt:     time.Now(),
+		}
+
+		mockRedis.On("XAdd", ctx, mock.MatchedBy(func(args *redis.XAddArgs) bool {
+			if _, hasData := streamValues(args)["data"]; hasData {
+				return false
+			}
+
+			val, ok := streamValues(args)["payload"].(string)
+			if !ok {
+				return false
+			}
+
+			var payload map[string]interface{}
+			if err := json.Unmarshal([]byte(val), &payload); err != nil {
+				return false
+			}
+
+			// Payload must be downgraded to the tall-affiliate-common field names
+			return streamValues(args)["event_type"] == "NEW_PRODUCT_DETECTED" &&
+				payload["current_price"] == 19.99 &&
+				payload["image_urls"] != nil
+		})).Return(nil)
+
+		outcomes := relay.publishBatch(ctx, []*OutboxEvent{event})
+		require.NoError(t, outcomes[0].Err)
+
+		mockRedis.AssertExpectations(t)
+	})
+}
+
+func TestRelay_Backpressure(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.Default()
+
+	newEvent := func() *OutboxEvent {
+		return &OutboxEvent{
+			ID:            uuid.New(),
+			AggregateType: "product",
+			AggregateID:   "B001TEST",
+			EventType:     "NEW_PRODUCT_DETECTED",
+			Payload:       json.RawMessage(`{"asin":"B001TEST"}`),
+			TargetStream:  "stream:product_lifecycle",
+			CreatedAt:     time.Now(),
+		}
+	}
+
+	t.Run("trim stream with approximate maxlen", func(t *testing.T) {
+		mockRedis := new(MockRedisClient)
+		mockOutbox := new(MockOutboxRepository)
+
+		relay := &Relay{
+			redis:        mockRedis,
+			outbox:       mockOutbox,
+			logger:       logger,
+			maxStreamLen: 10000,
+		}
+
+		mockRedis.On("XAdd", ctx, mock.MatchedBy(func(args *redis.XAddArgs) bool {
+			return args.MaxLen == 10000 && args.Approx
+		})).Return(nil)
+
+		outcomes := relay.publishBatch(ctx, []*OutboxEvent{newEvent()})
+		require.NoError(t, outcomes[0].Err)
+
+		mockRedis.AssertExpectations(t)
+	})
+
+	t.Run("pause when stream backlog exceeds threshold", func(t *testing.T) {
+		mockRedis := new(MockRedisClient)
+		mockOutbox := new(MockOutboxRepository)
+
+		relay := &Relay{
+			redis:      mockRedis,
+			outbox:     mockOutbox,
+			logger:     logger,
+			batchSize:  10,
+			maxBacklog: 100,
+		}
+
+		mockOutbox.On("GetPending", ctx, 10, []string(nil)).Return([]*OutboxEvent{newEvent()}, nil)
+		mockOutbox.On("GetPending", ctx, 10, []string{"stream:product_lifecycle"}).Return([]*OutboxEvent{}, nil)
+		mockRedis.On("XLen", ctx, "stream:product_lifecycle").Return(int64(500), nil)
+
+		err := relay.processEvents(ctx)
 		require.NoError(t, err)
 
+		assert.True(t, relay.IsPaused())
+		mockRedis.AssertNotCalled(t, "XAdd", mock.Anything, mock.Anything)
+		mockOutbox.AssertNotCalled(t, "MarkBatch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("pause when consumer group lags", func(t *testing.T) {
+		mockRedis := new(MockRedisClient)
+		mockOutbox := new(MockOutboxRepository)
+
+		relay := &Relay{
+			redis:     mockRedis,
+			outbox:    mockOutbox,
+			logger:    logger,
+			batchSize: 10,
+			maxLag:    50,
+		}
+
+		mockOutbox.On("GetPending", ctx, 10, []string(nil)).Return([]*OutboxEvent{newEvent()}, nil)
+		mockOutbox.On("GetPending", ctx, 10, []string{"stream:product_lifecycle"}).Return([]*OutboxEvent{}, nil)
+		mockRedis.On("XInfoGroups", ctx, "stream:product_lifecycle").Return([]redis.XInfoGroup{
+			{Name: "lifecycle-consumer-group", Pending: 10, Lag: 60},
+		}, nil)
+
+		err := relay.processEvents(ctx)
+		require.NoError(t, err)
+
+		assert.True(t, relay.IsPaused())
+		mockRedis.AssertNotCalled(t, "XAdd", mock.Anything, mock.Anything)
+	})
+
+	t.Run("throttled stream does not starve other streams", func(t *testing.T) {
+		mockRedis := new(MockRedisClient)
+		mockOutbox := new(MockOutboxRepository)
+
+		relay := &Relay{
+			redis:      mockRedis,
+			outbox:     mockOutbox,
+			logger:     logger,
+			batchSize:  2,
+			maxBacklog: 100,
+		}
+
+		// The oldest events all target the throttled stream and fill the first batch
+		mockOutbox.On("GetPending", ctx, 2, []string(nil)).Return([]*OutboxEvent{newEvent(), newEvent()}, nil)
+		other := newEvent()
+		other.TargetStream = "stream:product_updates"
+		mockOutbox.On("GetPending", ctx, 2, []string{"stream:product_lifecycle"}).Return([]*OutboxEvent{other}, nil)
+		mockRedis.On("XLen", ctx, "stream:product_lifecycle").Return(int64(500), nil)
+		mockRedis.On("XLen", ctx, "stream:product_updates").Return(int64(10), nil)
+		mockRedis.On("XAdd", ctx, mock.MatchedBy(func(args *redis.XAddArgs) bool {
+			return args.Stream == "stream:product_updates"
+		})).Return(nil).Once()
+		mockOutbox.On("MarkBatch", ctx, mock.MatchedBy(func(outcomes []OutboxOutcome) bool {
+			return len(outcomes) == 1 && outcomes[0].ID == other.ID && outcomes[0].Err == nil
+		})).Return(nil)
+
+		err := relay.processEvents(ctx)
+		require.NoError(t, err)
+
+		assert.True(t, relay.IsPaused())
 		mockRedis.AssertExpectations(t)
+		mockOutbox.AssertExpectations(t)
+	})
+
+	t.Run("resume when consumers caught up", func(t *testing.T) {
+		mockRedis := new(MockRedisClient)
+		mockOutbox := new(MockOutboxRepository)
+
+		relay := &Relay{
+			redis:     mockRedis,
+			outbox:    mockOutbox,
+			logger:    logger,
+			batchSize: 10,
+			maxLag:    50,
+		}
+		relay.paused.Store(true)
+
+		event := newEvent()
+		mockOutbox.On("GetPending", ctx, 10, []string(nil)).Return([]*OutboxEvent{event}, nil)
+		mockOutbox.On("MarkBatch", ctx, batchOutcome(event.ID, "")).Return(nil)
+		mockRedis.On("XInfoGroups", ctx, "stream:product_lifecycle").Return([]redis.XInfoGroup{
+			{Name: "lifecycle-consumer-group", Pending: 1, Lag: -1},
+		}, nil)
+		mockRedis.On("XAdd", ctx, mock.Anything).Return(nil)
+
+		err := relay.processEvents(ctx)
+		require.NoError(t, err)
+
+		assert.False(t, relay.IsPaused())
+		mockRedis.AssertExpectations(t)
+		mockOutbox.AssertExpectations(t)
 	})
 }
 
@@ -339,7 +602,7 @@ func TestRelay_Start(t *testing.T) {
 		}
 
 		// Return empty events
-		mockOutbox.On("GetPending", mock.Anything, 10).Return([]*OutboxEvent{}, nil).Maybe()
+		mockOutbox.On("GetPending", mock.Anything, 10, mock.Anything).Return([]*OutboxEvent{}, nil).Maybe()
 
 		ctx, cancel := context.WithCancel(context.Background())
 		
@@ -0,0 +1,221 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// defaultReplicaCooldown is how long an unavailable replica is skipped before it is tried again
+const defaultReplicaCooldown = 30 * time.Second
+
+// replica is one read-only pool and the time until which it is considered unavailable
+type replica struct {
+	name      string
+	pool      *pgxpool.Pool
+	downUntil atomic.Int64 // Unix nanoseconds, 0 when healthy
+}
+
+// replicaSet routes reads round robin over the healthy replicas
+type replicaSet struct {
+	replicas  []*replica
+	next      atomic.Uint32
+	cooldown  time.Duration
+	fallbacks atomic.Int64 // Reads sent to the primary because a replica was unavailable
+	now       func() time.Time
+}
+
+func newReplicaSet(replicas []*replica, cooldown time.Duration) *replicaSet {
+	if cooldown <= 0 {
+		cooldown = defaultReplicaCooldown
+	}
+	return &replicaSet{replicas: replicas, cooldown: cooldown, now: time.Now}
+}
+
+// pick returns the next healthy replica, or nil when every replica is down and reads go to the primary
+func (s *replicaSet) pick() *replica {
+	if s == nil || len(s.replicas) == 0 {
+		return nil
+	}
+	now := s.now().UnixNano()
+	start := s.next.Add(1) - 1
+	for i := range s.replicas {
+		r := s.replicas[(int(start)+i)%len(s.replicas)]
+		if r.downUntil.Load() <= now {
+			return r
+		}
+	}
+	return nil
+}
+
+// markDown takes r out of rotation for the cooldown
+func (s *replicaSet) markDown(ctx context.Context, r *replica, err error) {
+	s.fallbacks.Add(1)
+	r.downUntil.Store(s.now().Add(s.cooldown).UnixNano())
+	slog.Default().WarnContext(ctx, "read replica unavailable, falling back to primary",
+		"component", "database",
+		"replica", r.name,
+		"retry_after", s.cooldown,
+		"error", err)
+}
+
+func (s *replicaSet) close() {
+	if s == nil {
+		return
+	}
+	for _, r := range s.replicas {
+		r.pool.Close()
+	}
+}
+
+// replicaUnavailable reports whether err means the replica could not serve the read at all, as
+// opposed to an error of the query itself that the primary would return as well
+func replicaUnavailable(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	var connectErr *pgconn.ConnectError
+	var netErr net.Error
+	if errors.As(err, &connectErr) || errors.As(err, &netErr) || pgconn.SafeToRetry(err) {
+		return true
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// Class 08 is a connection exception, class 57P an operator intervention such as a
+		// shutdown or a standby that is still starting up
+		code := pgErr.Code
+		return len(code) == 5 && (code[:2] == "08" || code[:3] == "57P")
+	}
+	return false
+}
+
+// openReplicas creates a pool per replica DSN with the primary's pool settings. A replica that does
+// not answer the startup ping is kept but starts out of rotation.
+func openReplicas(ctx context.Context, cfg Config) (*replicaSet, error) {
+	var replicas []*replica
+	for i, dsn := range cfg.ReplicaDSNs {
+		poolConfig, err := pgxpool.ParseConfig(dsn)
+		if err != nil {
+			closeReplicas(replicas)
+			return nil, fmt.Errorf("failed to parse replica %d config: %w", i, err)
+		}
+		configurePool(poolConfig, cfg)
+
+		pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+		if err != nil {
+			closeReplicas(replicas)
+			return nil, fmt.Errorf("failed to create replica %d pool: %w", i, err)
+		}
+		replicas = append(replicas, &replica{
+			name: fmt.Sprintf("%s:%d", poolConfig.ConnConfig.Host, poolConfig.ConnConfig.Port),
+			pool: pool,
+		})
+	}
+
+	set := newReplicaSet(replicas, cfg.ReplicaCooldown)
+	for _, r := range replicas {
+		if err := r.pool.Ping(ctx); err != nil {
+			set.markDown(ctx, r, err)
+		}
+	}
+	return set, nil
+}
+
+func closeReplicas(replicas []*replica) {
+	for _, r := range replicas {
+		r.pool.Close()
+	}
+}
+
+// ReadQuery runs a read-only query on a replica, or on the primary when no replica is configured
+// or the chosen one is unavailable. Reads may lag behind writes by the replication delay.
+func (db *DB) ReadQuery(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	r := db.replicas.pick()
+	if r == nil {
+		return db.pool.Query(ctx, sql, args...)
+	}
+	rows, err := r.pool.Query(ctx, sql, args...)
+	if replicaUnavailable(ctx, err) {
+		db.replicas.markDown(ctx, r, err)
+		return db.pool.Query(ctx, sql, args...)
+	}
+	return rows, err
+}
+
+// ReadQueryRow is the single row variant of ReadQuery. The query runs when the row is scanned.
+func (db *DB) ReadQueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return &readRow{db: db, ctx: ctx, sql: sql, args: args}
+}
+
+// readRow defers the query to Scan so an unavailable replica can be retried on the primary
+type readRow struct {
+	db   *DB
+	ctx  context.Context
+	sql  string
+	args []interface{}
+}
+
+func (r *readRow) Scan(dest ...any) error {
+	rep := r.db.replicas.pick()
+	if rep == nil {
+		return r.db.pool.QueryRow(r.ctx, r.sql, r.args...).Scan(dest...)
+	}
+	err := rep.pool.QueryRow(r.ctx, r.sql, r.args...).Scan(dest...)
+	if replicaUnavailable(r.ctx, err) {
+		r.db.replicas.markDown(r.ctx, rep, err)
+		return r.db.pool.QueryRow(r.ctx, r.sql, r.args...).Scan(dest...)
+	}
+	return err
+}
+
+// ReplicaStatus is the routing state of one read replica
+type ReplicaStatus struct {
+	Name      string    `json:"name"`
+	Available bool      `json:"available"`
+	DownUntil time.Time `json:"down_until,omitempty"`
+	PoolStats PoolStats `json:"pool"`
+}
+
+// Replicas returns the state of the configured read replicas
+func (db *DB) Replicas() []ReplicaStatus {
+	if db.replicas == nil {
+		return nil
+	}
+	now := db.replicas.now()
+	out := make([]ReplicaStatus, 0, len(db.replicas.replicas))
+	for _, r := range db.replicas.replicas {
+		status := ReplicaStatus{Name: r.name, Available: true, PoolStats: poolStats(r.pool)}
+		if until := time.Unix(0, r.downUntil.Load()); until.After(now) {
+			status.Available = false
+			status.DownUntil = until
+		}
+		out = append(out, status)
+	}
+	return out
+}
+
+// writeReplicaMetrics writes the replica availability and fallback count in the Prometheus text format
+func (db *DB) writeReplicaMetrics(w io.Writer) {
+	if db.replicas == nil || len(db.replicas.replicas) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "# HELP scraper_db_replica_up Whether the read replica is in rotation.\n# TYPE scraper_db_replica_up gauge\n")
+	for _, r := range db.Replicas() {
+		up := 0
+		if r.Available {
+			up = 1
+		}
+		fmt.Fprintf(w, "scraper_db_replica_up{replica=%q} %d\n", r.Name, up)
+	}
+	fmt.Fprintf(w, "# HELP scraper_db_replica_fallbacks_total Reads sent to the primary because a replica was unavailable.\n# TYPE scraper_db_replica_fallbacks_total counter\nscraper_db_replica_fallbacks_total %d\n",
+		db.replicas.fallbacks.Load())
+}
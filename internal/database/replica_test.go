@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplicaSet_Pick(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	a, b := &replica{name: "a"}, &replica{name: "b"}
+	set := newReplicaSet([]*replica{a, b}, time.Minute)
+	set.now = func() time.Time { return now }
+
+	t.Run("round robin over healthy replicas", func(t *testing.T) {
+		assert.Same(t, a, set.pick())
+		assert.Same(t, b, set.pick())
+		assert.Same(t, a, set.pick())
+	})
+
+	t.Run("unavailable replica is skipped for the cooldown", func(t *testing.T) {
+		set.markDown(context.Background(), a, errors.New("connection refused"))
+		assert.Same(t, b, set.pick())
+		assert.Same(t, b, set.pick())
+		assert.Equal(t, int64(1), set.fallbacks.Load())
+
+		now = now.Add(time.Minute)
+		picked := []*replica{set.pick(), set.pick()}
+		assert.ElementsMatch(t, []*replica{a, b}, picked)
+	})
+
+	t.Run("all replicas down falls back to primary", func(t *testing.T) {
+		set.markDown(context.Background(), a, errors.New("down"))
+		set.markDown(context.Background(), b, errors.New("down"))
+		assert.Nil(t, set.pick())
+	})
+
+	t.Run("no replicas configured", func(t *testing.T) {
+		var none *replicaSet
+		assert.Nil(t, none.pick())
+		assert.Nil(t, newReplicaSet(nil, 0).pick())
+	})
+}
+
+func TestReplicaUnavailable(t *testing.T) {
+	ctx := context.Background()
+
+	assert.False(t, replicaUnavailable(ctx, nil))
+	assert.True(t, replicaUnavailable(ctx, &pgconn.ConnectError{}))
+	assert.True(t, replicaUnavailable(ctx, &pgconn.PgError{Code: "57P03"}), "cannot connect now")
+	assert.True(t, replicaUnavailable(ctx, &pgconn.PgError{Code: "08006"}), "connection failure")
+	assert.False(t, replicaUnavailable(ctx, &pgconn.PgError{Code: "42P01"}), "undefined table fails on the primary too")
+	assert.False(t, replicaUnavailable(ctx, errors.New("scan failed")))
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.False(t, replicaUnavailable(canceled, &pgconn.ConnectError{}), "caller gave up")
+}
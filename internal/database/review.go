@@ -0,0 +1,124 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Review is one stored customer review of a product
+type Review struct {
+	Rating        int    `json:"rating"`
+	Title         string `json:"title"`
+	Text          string `json:"text"`
+	VerifiedBuyer bool   `json:"verified_buyer"`
+	Date          string `json:"date,omitempty"` // As shown, e.g. "Rezension aus Deutschland vom 3. März 2024"
+}
+
+// Fabric quality ratings of FitSummary
+const (
+	FabricPoor      = "poor"
+	FabricAverage   = "average"
+	FabricGood      = "good"
+	FabricExcellent = "excellent"
+	FabricUnknown   = "unknown"
+)
+
+// FitSummary is the structured fit summary a language model derived from the reviews of a product
+type FitSummary struct {
+	RunsSmallScore float64   `json:"runs_small_score"` // -1 runs large, 0 true to size, 1 runs small
+	FabricQuality  string    `json:"fabric_quality"`   // One of the Fabric constants
+	TallFitNotes   string    `json:"tall_fit_notes"`   // What reviewers say about length and fit for tall people
+	ReviewCount    int       `json:"review_count"`     // Reviews the summary is based on
+	Model          string    `json:"model,omitempty"`
+	SummarizedAt   time.Time `json:"summarized_at"`
+}
+
+// ReplaceReviews stores the reviews of a product, replacing those of an earlier extraction
+func (db *DB) ReplaceReviews(ctx context.Context, asin string, reviews []Review) error {
+	return db.WithTx(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM product_reviews WHERE asin = $1`, asin); err != nil {
+			return fmt.Errorf("failed to delete reviews: %w", err)
+		}
+
+		query := `
+			INSERT INTO product_reviews (asin, position, rating, title, body, verified_buyer, review_date)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+		for i, r := range reviews {
+			if _, err := tx.Exec(ctx, query, asin, i+1, r.Rating, r.Title, r.Text, r.VerifiedBuyer, r.Date); err != nil {
+				return fmt.Errorf("failed to insert review: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// ListReviews returns the stored reviews of a product in page order
+func (db *DB) ListReviews(ctx context.Context, asin string) ([]Review, error) {
+	query := `
+		SELECT rating, title, body, verified_buyer, review_date
+		FROM product_reviews
+		WHERE asin = $1
+		ORDER BY position`
+
+	rows, err := db.ReadQuery(ctx, query, asin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reviews: %w", err)
+	}
+	defer rows.Close()
+
+	var reviews []Review
+	for rows.Next() {
+		var r Review
+		if err := rows.Scan(&r.Rating, &r.Title, &r.Text, &r.VerifiedBuyer, &r.Date); err != nil {
+			return nil, fmt.Errorf("failed to scan review: %w", err)
+		}
+		reviews = append(reviews, r)
+	}
+
+	return reviews, rows.Err()
+}
+
+// SaveFitSummary stores the fit summary of a product, replacing an earlier one
+func (db *DB) SaveFitSummary(ctx context.Context, asin string, summary *FitSummary) error {
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("failed to marshal fit summary: %w", err)
+	}
+
+	query := `
+		INSERT INTO review_summaries (asin, fit_summary, updated_at)
+		VALUES ($1, $2, CURRENT_TIMESTAMP)
+		ON CONFLICT (asin) DO UPDATE SET
+			fit_summary = EXCLUDED.fit_summary,
+			updated_at = CURRENT_TIMESTAMP`
+
+	if _, err := db.pool.Exec(ctx, query, asin, data); err != nil {
+		return fmt.Errorf("failed to save fit summary: %w", err)
+	}
+	return nil
+}
+
+// GetFitSummary returns the fit summary of a product, nil if none was made
+func (db *DB) GetFitSummary(ctx context.Context, asin string) (*FitSummary, error) {
+	var data []byte
+	err := db.ReadQueryRow(ctx, `SELECT fit_summary FROM review_summaries WHERE asin = $1`, asin).Scan(&data)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fit summary: %w", err)
+	}
+
+	var summary FitSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return nil, fmt.Errorf("failed to decode fit summary: %w", err)
+	}
+	return &summary, nil
+}
@@ -0,0 +1,34 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// SchemaVersion is the number of the latest migration in migrations/, the version golang-migrate
+// records in schema_migrations once every migration this code relies on is applied
+const SchemaVersion = 46
+
+// MigrationVersion returns the version golang-migrate recorded and whether its last migration
+// failed halfway, version 0 if no migration ran yet
+func (db *DB) MigrationVersion(ctx context.Context) (version int, dirty bool, err error) {
+	var exists bool
+	if err := db.pool.QueryRow(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return 0, false, fmt.Errorf("failed to look up schema_migrations: %w", err)
+	}
+	if !exists {
+		return 0, false, nil
+	}
+
+	err = db.pool.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read migration version: %w", err)
+	}
+	return version, dirty, nil
+}
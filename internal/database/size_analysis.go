@@ -0,0 +1,161 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/maltedev/amazon-size-scraper/internal/analytics"
+)
+
+// SizeAnalysis is a size table analysis of search results with its counts per status, final once
+// CompletedAt is set
+type SizeAnalysis struct {
+	ID         string  `json:"id"`
+	JobID      *string `json:"job_id,omitempty"`
+	SearchURL  string  `json:"search_url"`
+	SampleSize int     `json:"sample_size"` // Products to analyze, fewer when the search has fewer
+	analytics.Statistics
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+const sizeAnalysisColumns = `id, job_id, search_url, sample_size, total, complete, missing_length, missing_width,
+	missing_both, no_table, errors, created_at, completed_at`
+
+func scanSizeAnalysis(row pgx.Row) (*SizeAnalysis, error) {
+	a := &SizeAnalysis{}
+	err := row.Scan(&a.ID, &a.JobID, &a.SearchURL, &a.SampleSize, &a.Total, &a.Complete, &a.MissingLength,
+		&a.MissingWidth, &a.MissingBoth, &a.NoTable, &a.Errors, &a.CreatedAt, &a.CompletedAt)
+	return a, err
+}
+
+// InsertSizeAnalysisWithTx stores a new analysis within tx, assigning its ID and creation time
+func (db *DB) InsertSizeAnalysisWithTx(ctx context.Context, tx pgx.Tx, a *SizeAnalysis) error {
+	a.ID = uuid.New().String()
+	a.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO size_analyses (id, job_id, search_url, sample_size, created_at)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	if _, err := tx.Exec(ctx, query, a.ID, a.JobID, a.SearchURL, a.SampleSize, a.CreatedAt); err != nil {
+		return fmt.Errorf("failed to insert size analysis: %w", err)
+	}
+	return nil
+}
+
+// GetSizeAnalysis returns an analysis by ID, nil if it does not exist
+func (db *DB) GetSizeAnalysis(ctx context.Context, id string) (*SizeAnalysis, error) {
+	a, err := scanSizeAnalysis(db.ReadQueryRow(ctx, `SELECT `+sizeAnalysisColumns+` FROM size_analyses WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get size analysis: %w", err)
+	}
+	return a, nil
+}
+
+// GetSizeAnalysisByJob returns the analysis of a job, nil if the job has none
+func (db *DB) GetSizeAnalysisByJob(ctx context.Context, jobID string) (*SizeAnalysis, error) {
+	a, err := scanSizeAnalysis(db.QueryRow(ctx, `SELECT `+sizeAnalysisColumns+` FROM size_analyses WHERE job_id = $1`, jobID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get size analysis of job: %w", err)
+	}
+	return a, nil
+}
+
+// ListSizeAnalyses returns the latest analyses, newest first
+func (db *DB) ListSizeAnalyses(ctx context.Context, limit int) ([]*SizeAnalysis, error) {
+	rows, err := db.ReadQuery(ctx, `SELECT `+sizeAnalysisColumns+` FROM size_analyses ORDER BY created_at DESC LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list size analyses: %w", err)
+	}
+	defer rows.Close()
+
+	analyses := []*SizeAnalysis{}
+	for rows.Next() {
+		a, err := scanSizeAnalysis(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan size analysis: %w", err)
+		}
+		analyses = append(analyses, a)
+	}
+	return analyses, rows.Err()
+}
+
+// SaveSizeAnalysisResult stores the result of an analyzed product, replacing an earlier result of the
+// same product, e.g. from an interrupted run
+func (db *DB) SaveSizeAnalysisResult(ctx context.Context, analysisID string, r analytics.Result) error {
+	measurements := r.Measurements
+	if measurements == nil {
+		measurements = []string{}
+	}
+
+	query := `
+		INSERT INTO size_analysis_results
+		(analysis_id, asin, title, status, has_table, has_length, has_width, measurements, source, error, analyzed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (analysis_id, asin) DO UPDATE SET
+			title = EXCLUDED.title, status = EXCLUDED.status, has_table = EXCLUDED.has_table,
+			has_length = EXCLUDED.has_length, has_width = EXCLUDED.has_width, measurements = EXCLUDED.measurements,
+			source = EXCLUDED.source, error = EXCLUDED.error, analyzed_at = EXCLUDED.analyzed_at`
+
+	_, err := db.Exec(ctx, query, analysisID, r.ASIN, r.Title, r.Status, r.HasTable, r.HasLength, r.HasWidth,
+		measurements, r.Source, r.Error, r.AnalyzedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save size analysis result: %w", err)
+	}
+	return nil
+}
+
+// ListSizeAnalysisResults returns the results of an analysis in the order they were analyzed, only
+// those of status if it is set
+func (db *DB) ListSizeAnalysisResults(ctx context.Context, analysisID, status string) ([]analytics.Result, error) {
+	query := `
+		SELECT asin, title, status, has_table, has_length, has_width, measurements, source, error, analyzed_at
+		FROM size_analysis_results
+		WHERE analysis_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY analyzed_at, asin`
+
+	rows, err := db.ReadQuery(ctx, query, analysisID, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list size analysis results: %w", err)
+	}
+	defer rows.Close()
+
+	results := []analytics.Result{}
+	for rows.Next() {
+		var r analytics.Result
+		err := rows.Scan(&r.ASIN, &r.Title, &r.Status, &r.HasTable, &r.HasLength, &r.HasWidth, &r.Measurements,
+			&r.Source, &r.Error, &r.AnalyzedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan size analysis result: %w", err)
+		}
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+// CompleteSizeAnalysis stores the final statistics of an analysis
+func (db *DB) CompleteSizeAnalysis(ctx context.Context, id string, stats analytics.Statistics) error {
+	query := `
+		UPDATE size_analyses
+		SET total = $2, complete = $3, missing_length = $4, missing_width = $5, missing_both = $6,
+			no_table = $7, errors = $8, completed_at = NOW()
+		WHERE id = $1`
+
+	_, err := db.Exec(ctx, query, id, stats.Total, stats.Complete, stats.MissingLength, stats.MissingWidth,
+		stats.MissingBoth, stats.NoTable, stats.Errors)
+	if err != nil {
+		return fmt.Errorf("failed to complete size analysis: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// SizeConversion is the size of one size label in another size system, e.g. M is 50 in DE
+type SizeConversion struct {
+	ASIN          string `json:"asin"`
+	SizeLabel     string `json:"size_label"`
+	CanonicalSize string `json:"canonical_size"`
+	System        string `json:"system"`
+	Size          string `json:"size"`
+}
+
+// SizeConversionRows flattens the conversions of a size table in size order
+func SizeConversionRows(asin string, st *SizeTable) []SizeConversion {
+	if st == nil || len(st.Conversions) == 0 {
+		return nil
+	}
+
+	var rows []SizeConversion
+	for _, size := range st.Sizes {
+		systems := make([]string, 0, len(st.Conversions[size]))
+		for system := range st.Conversions[size] {
+			systems = append(systems, system)
+		}
+		sort.Strings(systems)
+
+		for _, system := range systems {
+			rows = append(rows, SizeConversion{
+				ASIN:          asin,
+				SizeLabel:     size,
+				CanonicalSize: CanonicalSize(size),
+				System:        system,
+				Size:          st.Conversions[size][system],
+			})
+		}
+	}
+	return rows
+}
+
+// ReplaceSizeConversions replaces all size conversion rows of a product
+func (db *DB) ReplaceSizeConversions(ctx context.Context, asin string, rows []SizeConversion) error {
+	return db.WithTx(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM size_conversions WHERE asin = $1`, asin); err != nil {
+			return fmt.Errorf("failed to delete size conversions: %w", err)
+		}
+
+		query := `
+			INSERT INTO size_conversions (asin, size_label, canonical_size, system, size)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (asin, size_label, system) DO UPDATE SET
+				canonical_size = EXCLUDED.canonical_size,
+				size = EXCLUDED.size`
+
+		for _, row := range rows {
+			if _, err := tx.Exec(ctx, query, asin, row.SizeLabel, row.CanonicalSize, row.System, row.Size); err != nil {
+				return fmt.Errorf("failed to insert size conversion: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// ListSizeConversions returns size conversion rows, optionally filtered by ASIN and size system
+func (db *DB) ListSizeConversions(ctx context.Context, asin, system string, limit, offset int) ([]SizeConversion, error) {
+	query := `
+		SELECT asin, size_label, canonical_size, system, size
+		FROM size_conversions
+		WHERE ($1 = '' OR asin = $1) AND ($2 = '' OR system = $2)
+		ORDER BY asin, canonical_size, system
+		LIMIT $3 OFFSET $4`
+
+	rows, err := db.ReadQuery(ctx, query, asin, system, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query size conversions: %w", err)
+	}
+	defer rows.Close()
+
+	var conversions []SizeConversion
+	for rows.Next() {
+		var c SizeConversion
+		if err := rows.Scan(&c.ASIN, &c.SizeLabel, &c.CanonicalSize, &c.System, &c.Size); err != nil {
+			return nil, fmt.Errorf("failed to scan size conversion: %w", err)
+		}
+		conversions = append(conversions, c)
+	}
+
+	return conversions, rows.Err()
+}
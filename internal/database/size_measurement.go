@@ -0,0 +1,202 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/maltedev/amazon-size-scraper/internal/labels"
+	"github.com/maltedev/amazon-size-scraper/internal/numparse"
+)
+
+// SizeMeasurement is one measurement of one size in canonical form
+type SizeMeasurement struct {
+	ASIN          string  `json:"asin"`
+	SizeLabel     string  `json:"size_label"`
+	CanonicalSize string  `json:"canonical_size"`
+	Measurement   string  `json:"measurement"`
+	ValueCM       float64 `json:"value_cm"`
+	Interpolated  bool    `json:"interpolated"`
+}
+
+// canonicalSizes maps common letter size spellings to their canonical form
+var canonicalSizes = map[string]string{
+	"XXS": "XXS", "2XS": "XXS",
+	"XS": "XS", "EXTRASMALL": "XS", "XSMALL": "XS",
+	"S": "S", "SMALL": "S", "KLEIN": "S",
+	"M": "M", "MEDIUM": "M", "MITTEL": "M",
+	"L": "L", "LARGE": "L", "GROSS": "L", "GROß": "L",
+	"XL": "XL", "EXTRALARGE": "XL", "XLARGE": "XL",
+	"XXL": "XXL", "2XL": "XXL", "XXLARGE": "XXL",
+	"XXXL": "3XL", "3XL": "3XL", "XXXLARGE": "3XL",
+	"XXXXL": "4XL", "4XL": "4XL",
+	"XXXXXL": "5XL", "5XL": "5XL",
+}
+
+var (
+	sizeSeparators = regexp.MustCompile(`[\s\-_.]+`)
+	numericSize    = regexp.MustCompile(`\d+(?:[.,]\d+)?`)
+)
+
+// CanonicalSize maps a size label such as "X-Large" or "EU 48" to a canonical size ("XL", "48")
+func CanonicalSize(label string) string {
+	// Children's sizes keep their range and unit, e.g. "6-7 Jahre" is "6-7Y"
+	if _, size, ok := labels.KidsSize(label, ""); ok {
+		return size
+	}
+	key := strings.ToUpper(sizeSeparators.ReplaceAllString(strings.TrimSpace(label), ""))
+	if size, ok := canonicalSizes[key]; ok {
+		return size
+	}
+	if number := numericSize.FindString(label); number != "" {
+		return numparse.Normalize(number, numparse.Auto)
+	}
+	return key
+}
+
+// NormalizeSizeTable flattens a size table into per-size rows in centimeters.
+// A missing value is interpolated only when it sits between two known neighbors
+// in the same column and the neighbors do not decrease.
+func NormalizeSizeTable(asin string, st *SizeTable) []SizeMeasurement {
+	if st == nil || len(st.Sizes) == 0 {
+		return nil
+	}
+
+	factor := unitToCM(st.Unit)
+
+	keySet := make(map[string]bool)
+	for _, measurements := range st.Measurements {
+		for key := range measurements {
+			keySet[key] = true
+		}
+	}
+	keys := make([]string, 0, len(keySet))
+	for key := range keySet {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var rows []SizeMeasurement
+	for _, key := range keys {
+		values := make([]float64, len(st.Sizes))
+		known := make([]bool, len(st.Sizes))
+		for i, size := range st.Sizes {
+			if value, ok := st.Measurements[size][key]; ok && value > 0 {
+				values[i] = math.Round(value*factor*10) / 10
+				known[i] = true
+			}
+		}
+
+		for i, size := range st.Sizes {
+			value, interpolated := values[i], false
+			if !known[i] {
+				var ok bool
+				if value, ok = interpolate(values, known, i); !ok {
+					continue
+				}
+				interpolated = true
+			}
+			rows = append(rows, SizeMeasurement{
+				ASIN:          asin,
+				SizeLabel:     size,
+				CanonicalSize: CanonicalSize(size),
+				Measurement:   key,
+				ValueCM:       value,
+				Interpolated:  interpolated,
+			})
+		}
+	}
+
+	return rows
+}
+
+// interpolate linearly fills index i from the nearest known values on both sides
+func interpolate(values []float64, known []bool, i int) (float64, bool) {
+	lo, hi := -1, -1
+	for j := i - 1; j >= 0; j-- {
+		if known[j] {
+			lo = j
+			break
+		}
+	}
+	for j := i + 1; j < len(values); j++ {
+		if known[j] {
+			hi = j
+			break
+		}
+	}
+	if lo < 0 || hi < 0 || values[hi] < values[lo] {
+		return 0, false
+	}
+
+	ratio := float64(i-lo) / float64(hi-lo)
+	value := values[lo] + (values[hi]-values[lo])*ratio
+	return math.Round(value*10) / 10, true
+}
+
+// ReplaceSizeMeasurements replaces all measurement rows of a product
+func (db *DB) ReplaceSizeMeasurements(ctx context.Context, asin string, rows []SizeMeasurement) error {
+	return db.WithTx(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM size_measurements WHERE asin = $1`, asin); err != nil {
+			return fmt.Errorf("failed to delete size measurements: %w", err)
+		}
+
+		query := `
+			INSERT INTO size_measurements (asin, size_label, canonical_size, measurement, value_cm, interpolated)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (asin, size_label, measurement) DO UPDATE SET
+				canonical_size = EXCLUDED.canonical_size,
+				value_cm = EXCLUDED.value_cm,
+				interpolated = EXCLUDED.interpolated`
+
+		for _, row := range rows {
+			if _, err := tx.Exec(ctx, query,
+				asin, row.SizeLabel, row.CanonicalSize, row.Measurement, row.ValueCM, row.Interpolated,
+			); err != nil {
+				return fmt.Errorf("failed to insert size measurement: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// SaveSizeMeasurements normalizes a size table and stores its rows along with its size conversions
+func (db *DB) SaveSizeMeasurements(ctx context.Context, asin string, st *SizeTable) error {
+	if err := db.ReplaceSizeMeasurements(ctx, asin, NormalizeSizeTable(asin, st)); err != nil {
+		return err
+	}
+	return db.ReplaceSizeConversions(ctx, asin, SizeConversionRows(asin, st))
+}
+
+// ListSizeMeasurements returns measurement rows, optionally filtered by ASIN
+func (db *DB) ListSizeMeasurements(ctx context.Context, asin string, limit, offset int) ([]SizeMeasurement, error) {
+	query := `
+		SELECT asin, size_label, canonical_size, measurement, value_cm, interpolated
+		FROM size_measurements
+		WHERE ($1 = '' OR asin = $1)
+		ORDER BY asin, canonical_size, measurement
+		LIMIT $2 OFFSET $3`
+
+	rows, err := db.ReadQuery(ctx, query, asin, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query size measurements: %w", err)
+	}
+	defer rows.Close()
+
+	var measurements []SizeMeasurement
+	for rows.Next() {
+		var m SizeMeasurement
+		if err := rows.Scan(&m.ASIN, &m.SizeLabel, &m.CanonicalSize, &m.Measurement, &m.ValueCM, &m.Interpolated); err != nil {
+			return nil, fmt.Errorf("failed to scan size measurement: %w", err)
+		}
+		measurements = append(measurements, m)
+	}
+
+	return measurements, rows.Err()
+}
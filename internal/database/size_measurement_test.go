@@ -0,0 +1,106 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalSize(t *testing.T) {
+	tests := map[string]string{
+		"M":           "M",
+		"x-large":     "XL",
+		"Extra Large": "XL",
+		"2XL":         "XXL",
+		"XXXL":        "3XL",
+		"Groß":        "L",
+		"EU 48":       "48",
+		"42,5":        "42.5",
+		"6-7 Jahre":   "6-7Y",
+		"116/122 cm":  "116-122cm",
+	}
+
+	for label, want := range tests {
+		assert.Equal(t, want, CanonicalSize(label), label)
+	}
+}
+
+func TestNormalizeSizeTable(t *testing.T) {
+	t.Run("converts inch to cm", func(t *testing.T) {
+		st := &SizeTable{
+			Sizes:        []string{"Medium"},
+			Measurements: map[string]map[string]float64{"Medium": {"chest": 40}},
+			Unit:         "inch",
+		}
+
+		rows := NormalizeSizeTable("B000TEST01", st)
+		assert.Len(t, rows, 1)
+		assert.Equal(t, "M", rows[0].CanonicalSize)
+		assert.Equal(t, "Medium", rows[0].SizeLabel)
+		assert.Equal(t, 101.6, rows[0].ValueCM)
+		assert.False(t, rows[0].Interpolated)
+	})
+
+	t.Run("interpolates gaps between known neighbors", func(t *testing.T) {
+		st := &SizeTable{
+			Sizes: []string{"S", "M", "L", "XL"},
+			Measurements: map[string]map[string]float64{
+				"S":  {"chest": 96},
+				"M":  {},
+				"L":  {"chest": 104},
+				"XL": {},
+			},
+			Unit: "cm",
+		}
+
+		rows := NormalizeSizeTable("B000TEST01", st)
+		assert.Len(t, rows, 3)
+		assert.Equal(t, "M", rows[1].SizeLabel)
+		assert.Equal(t, 100.0, rows[1].ValueCM)
+		assert.True(t, rows[1].Interpolated)
+	})
+
+	t.Run("does not interpolate across decreasing values", func(t *testing.T) {
+		st := &SizeTable{
+			Sizes: []string{"S", "M", "L"},
+			Measurements: map[string]map[string]float64{
+				"S": {"length": 74},
+				"L": {"length": 70},
+			},
+			Unit: "cm",
+		}
+
+		rows := NormalizeSizeTable("B000TEST01", st)
+		assert.Len(t, rows, 2)
+		for _, row := range rows {
+			assert.False(t, row.Interpolated)
+		}
+	})
+
+	t.Run("nil table", func(t *testing.T) {
+		assert.Empty(t, NormalizeSizeTable("B000TEST01", nil))
+	})
+}
+
+func TestSizeConversionRows(t *testing.T) {
+	st := &SizeTable{Sizes: []string{"M", "X-Large"}}
+	st.AddConversion("X-Large", "US", "XL")
+	st.AddConversion("M", "UK", "40")
+	st.AddConversion("M", "DE", " 50 ")
+	st.AddConversion("M", "IT", "")
+
+	rows := SizeConversionRows("B0TEST0001", st)
+	assert.Equal(t, []SizeConversion{
+		{ASIN: "B0TEST0001", SizeLabel: "M", CanonicalSize: "M", System: "DE", Size: "50"},
+		{ASIN: "B0TEST0001", SizeLabel: "M", CanonicalSize: "M", System: "UK", Size: "40"},
+		{ASIN: "B0TEST0001", SizeLabel: "X-Large", CanonicalSize: "XL", System: "US", Size: "XL"},
+	}, rows)
+
+	assert.Nil(t, SizeConversionRows("B0TEST0001", &SizeTable{Sizes: []string{"M"}}))
+}
+
+func TestSizeTableHasMeasurements(t *testing.T) {
+	assert.True(t, (&SizeTable{Measurements: map[string]map[string]float64{"S": {}, "M": {"chest": 100}}}).HasMeasurements())
+	assert.False(t, (&SizeTable{Sizes: []string{"S", "M"}, Measurements: map[string]map[string]float64{"S": {}, "M": {}}}).HasMeasurements())
+	assert.False(t, (&SizeTable{}).HasMeasurements())
+}
@@ -0,0 +1,46 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// SaveManualSizeTable replaces the size table of a product with an uploaded correction, along with its
+// validation report, provenance and normalized measurements. The status is left as it is. Returns
+// ErrProductNotFound for an unknown ASIN.
+func (db *DB) SaveManualSizeTable(ctx context.Context, asin string, st *SizeTable, report *ValidationReport) error {
+	st.Source = SizeTableSourceManual
+	st.Confidence = 1
+
+	sizeJSON, err := json.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("failed to marshal size table: %w", err)
+	}
+	reportJSON, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal validation report: %w", err)
+	}
+	provenance := Provenance{}
+	provenance.RecordSizeTable(st, time.Now())
+
+	query := `
+		UPDATE products SET
+			size_table = $2,
+			validation_report = $3,
+			quality_score = $4,
+			provenance = COALESCE(provenance, '{}'::jsonb) || $5,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE asin = $1`
+
+	tag, err := db.pool.Exec(ctx, query, asin, sizeJSON, reportJSON, report.Score, provenance.JSON())
+	if err != nil {
+		return fmt.Errorf("failed to update size table: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %s", ErrProductNotFound, asin)
+	}
+
+	return db.SaveSizeMeasurements(ctx, asin, st)
+}
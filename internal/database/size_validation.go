@@ -0,0 +1,420 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/labels"
+)
+
+// ruleNonEmpty is the issue of a size table without sizes or measurements
+const ruleNonEmpty = "non_empty"
+
+// Validation issue severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// ValidationIssue describes a single rule violation in a size table
+type ValidationIssue struct {
+	Rule        string `json:"rule"`
+	Severity    string `json:"severity"`
+	Size        string `json:"size,omitempty"`
+	Measurement string `json:"measurement,omitempty"`
+	Message     string `json:"message"`
+}
+
+// ValidationReport is the result of running all rules against a size table
+type ValidationReport struct {
+	Valid     bool              `json:"valid"`
+	Score     float64           `json:"score"` // 0 (unusable) to 1 (no issues)
+	Issues    []ValidationIssue `json:"issues,omitempty"`
+	CheckedAt time.Time         `json:"checked_at"`
+}
+
+// Errors returns the number of error severity issues
+func (r *ValidationReport) Errors() int {
+	count := 0
+	for _, issue := range r.Issues {
+		if issue.Severity == SeverityError {
+			count++
+		}
+	}
+	return count
+}
+
+// Rejection returns the issue a product is dropped for, an empty table or missing required measurements.
+// Other errors only lower the score. Nil if there is none.
+func (r *ValidationReport) Rejection() *ValidationIssue {
+	for i := range r.Issues {
+		issue := &r.Issues[i]
+		if issue.Severity != SeverityError {
+			continue
+		}
+		if issue.Rule == ruleNonEmpty || issue.Rule == (RequiredMeasurementsRule{}).Name() {
+			return issue
+		}
+	}
+	return nil
+}
+
+// SizeTableRule checks one aspect of a size table
+type SizeTableRule interface {
+	Name() string
+	Check(st *SizeTable) []ValidationIssue
+}
+
+// Range is an inclusive plausibility range in centimeters
+type Range struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// ValidationConfig configures the built-in size table rules
+type ValidationConfig struct {
+	Required           []string         `json:"required"`
+	PlausibleRanges    map[string]Range `json:"plausible_ranges"`
+	ChildrenRanges     map[string]Range `json:"children_plausible_ranges"` // Ranges of charts sized by age or body height
+	MonotonicKeys      []string         `json:"monotonic_keys"`
+	MonotonicTolerance float64          `json:"monotonic_tolerance"` // cm a value may shrink before it counts as a violation
+	MaxMissingRatio    float64          `json:"max_missing_ratio"`
+}
+
+// DefaultValidationConfig returns rules tuned for shirts, with smaller ranges for children's charts
+func DefaultValidationConfig() ValidationConfig {
+	return ValidationConfig{
+		Required: []string{"length", "chest"},
+		PlausibleRanges: map[string]Range{
+			"length":   {Min: 40, Max: 120},
+			"chest":    {Min: 35, Max: 180}, // Half chest width and full circumference both occur
+			"shoulder": {Min: 30, Max: 75},
+			"sleeve":   {Min: 10, Max: 100},
+			"waist":    {Min: 30, Max: 180},
+			"hip":      {Min: 35, Max: 190},
+		},
+		ChildrenRanges: map[string]Range{
+			"length":   {Min: 20, Max: 90},
+			"chest":    {Min: 20, Max: 110},
+			"shoulder": {Min: 15, Max: 50},
+			"sleeve":   {Min: 5, Max: 80},
+			"waist":    {Min: 15, Max: 100},
+			"hip":      {Min: 20, Max: 110},
+		},
+		MonotonicKeys:      []string{"length", "chest", "waist", "hip"},
+		MonotonicTolerance: 0.5,
+		MaxMissingRatio:    0.3,
+	}
+}
+
+// LoadValidationConfig reads a JSON validation config, keeping defaults for omitted fields
+func LoadValidationConfig(path string) (ValidationConfig, error) {
+	cfg := DefaultValidationConfig()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read validation config: %w", err)
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse validation config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// SizeTableValidator runs a set of rules against size tables
+type SizeTableValidator struct {
+	rules []SizeTableRule
+}
+
+// NewSizeTableValidator creates a validator with the given rules
+func NewSizeTableValidator(rules ...SizeTableRule) *SizeTableValidator {
+	return &SizeTableValidator{rules: rules}
+}
+
+// NewSizeTableValidatorFromConfig creates a validator with the built-in rules configured by cfg
+func NewSizeTableValidatorFromConfig(cfg ValidationConfig) *SizeTableValidator {
+	return NewSizeTableValidator(
+		RequiredMeasurementsRule{Keys: cfg.Required},
+		DuplicateSizesRule{},
+		PlausibilityRule{Ranges: cfg.PlausibleRanges, ChildrenRanges: cfg.ChildrenRanges},
+		MonotonicRule{Keys: cfg.MonotonicKeys, Tolerance: cfg.MonotonicTolerance},
+		MissingCellsRule{MaxRatio: cfg.MaxMissingRatio},
+	)
+}
+
+// WithPolicy returns a validator whose required measurements are those of the policy instead of the
+// configured ones, so the report agrees with the policy that decides whether a product is kept
+func (v *SizeTableValidator) WithPolicy(p MeasurementPolicy) *SizeTableValidator {
+	rules := make([]SizeTableRule, len(v.rules))
+	for i, rule := range v.rules {
+		switch rule.(type) {
+		case RequiredMeasurementsRule, MeasurementPolicyRule:
+			rule = MeasurementPolicyRule{Policy: p}
+		}
+		rules[i] = rule
+	}
+	return &SizeTableValidator{rules: rules}
+}
+
+// DefaultSizeTableValidator creates a validator with DefaultValidationConfig
+func DefaultSizeTableValidator() *SizeTableValidator {
+	return NewSizeTableValidatorFromConfig(DefaultValidationConfig())
+}
+
+// Validate runs all rules and returns the report
+func (v *SizeTableValidator) Validate(st *SizeTable) *ValidationReport {
+	report := &ValidationReport{CheckedAt: time.Now()}
+
+	if st == nil || len(st.Sizes) == 0 || len(st.Measurements) == 0 {
+		report.Issues = []ValidationIssue{{
+			Rule:     ruleNonEmpty,
+			Severity: SeverityError,
+			Message:  "size table is empty",
+		}}
+		return report
+	}
+
+	for _, rule := range v.rules {
+		report.Issues = append(report.Issues, rule.Check(st)...)
+	}
+
+	warnings := len(report.Issues) - report.Errors()
+	report.Valid = report.Errors() == 0
+	report.Score = math.Max(0, 1-0.25*float64(report.Errors())-0.05*float64(warnings))
+	report.Score = math.Round(report.Score*100) / 100
+
+	return report
+}
+
+// RequiredMeasurementsRule requires at least one size to carry all keys
+type RequiredMeasurementsRule struct {
+	Keys []string
+}
+
+func (r RequiredMeasurementsRule) Name() string { return "required_measurements" }
+
+func (r RequiredMeasurementsRule) Check(st *SizeTable) []ValidationIssue {
+	for _, measurements := range st.Measurements {
+		complete := true
+		for _, key := range r.Keys {
+			if _, ok := measurements[key]; !ok {
+				complete = false
+				break
+			}
+		}
+		if complete {
+			return nil
+		}
+	}
+
+	// Children's charts often give body measurements only, without a garment length
+	severity := SeverityError
+	if childrensChart(st) {
+		severity = SeverityWarning
+	}
+	return []ValidationIssue{{
+		Rule:     r.Name(),
+		Severity: severity,
+		Message:  fmt.Sprintf("no size has all of %s", strings.Join(r.Keys, ", ")),
+	}}
+}
+
+// MeasurementPolicyRule is the RequiredMeasurementsRule of a MeasurementPolicy. A table the policy does
+// not accept is an error, a partial table it keeps is a warning.
+type MeasurementPolicyRule struct {
+	Policy MeasurementPolicy
+}
+
+func (r MeasurementPolicyRule) Name() string { return RequiredMeasurementsRule{}.Name() }
+
+func (r MeasurementPolicyRule) Check(st *SizeTable) []ValidationIssue {
+	if r.Policy.Satisfied(st) {
+		return nil
+	}
+
+	issue := ValidationIssue{Rule: r.Name(), Severity: SeverityError, Message: "no size has a measurement"}
+	if keys := r.Policy.Required(); len(keys) > 0 {
+		issue.Message = fmt.Sprintf("no size has all of %s", strings.Join(keys, ", "))
+	}
+	if r.Policy.Accepts(st) {
+		issue.Severity = SeverityWarning
+	}
+	return []ValidationIssue{issue}
+}
+
+// DuplicateSizesRule flags size labels that appear more than once
+type DuplicateSizesRule struct{}
+
+func (r DuplicateSizesRule) Name() string { return "duplicate_sizes" }
+
+func (r DuplicateSizesRule) Check(st *SizeTable) []ValidationIssue {
+	var issues []ValidationIssue
+	seen := make(map[string]bool)
+	for _, size := range st.Sizes {
+		key := strings.ToUpper(strings.TrimSpace(size))
+		if seen[key] {
+			issues = append(issues, ValidationIssue{
+				Rule:     r.Name(),
+				Severity: SeverityError,
+				Size:     size,
+				Message:  fmt.Sprintf("size %s appears more than once", size),
+			})
+		}
+		seen[key] = true
+	}
+	return issues
+}
+
+// PlausibilityRule flags values outside the expected range for their measurement. Charts sized by age
+// or body height are checked against ChildrenRanges, measurements without one are not checked there.
+type PlausibilityRule struct {
+	Ranges         map[string]Range
+	ChildrenRanges map[string]Range
+}
+
+func (r PlausibilityRule) Name() string { return "plausibility" }
+
+func (r PlausibilityRule) Check(st *SizeTable) []ValidationIssue {
+	factor := unitToCM(st.Unit)
+	ranges := r.Ranges
+	if childrensChart(st) {
+		ranges = r.ChildrenRanges
+	}
+
+	var issues []ValidationIssue
+	for _, size := range st.Sizes {
+		for key, value := range st.Measurements[size] {
+			rng, ok := ranges[key]
+			if !ok {
+				continue
+			}
+			cm := value * factor
+			if cm < rng.Min || cm > rng.Max {
+				issues = append(issues, ValidationIssue{
+					Rule:        r.Name(),
+					Severity:    SeverityError,
+					Size:        size,
+					Measurement: key,
+					Message:     fmt.Sprintf("%s %.1fcm outside %.0f-%.0fcm", key, cm, rng.Min, rng.Max),
+				})
+			}
+		}
+	}
+	return issues
+}
+
+// MonotonicRule expects measurements not to shrink from one size to the next
+type MonotonicRule struct {
+	Keys      []string
+	Tolerance float64
+}
+
+func (r MonotonicRule) Name() string { return "monotonic" }
+
+func (r MonotonicRule) Check(st *SizeTable) []ValidationIssue {
+	var issues []ValidationIssue
+	for _, key := range r.Keys {
+		prevSize := ""
+		prev := 0.0
+		for _, size := range st.Sizes {
+			value, ok := st.Measurements[size][key]
+			if !ok || value <= 0 {
+				continue
+			}
+			if prevSize != "" && value < prev-r.Tolerance {
+				issues = append(issues, ValidationIssue{
+					Rule:        r.Name(),
+					Severity:    SeverityWarning,
+					Size:        size,
+					Measurement: key,
+					Message:     fmt.Sprintf("%s decreases from %s (%.1f) to %s (%.1f)", key, prevSize, prev, size, value),
+				})
+			}
+			prevSize, prev = size, value
+		}
+	}
+	return issues
+}
+
+// MissingCellsRule flags tables where too many size/measurement cells are empty
+type MissingCellsRule struct {
+	MaxRatio float64
+}
+
+func (r MissingCellsRule) Name() string { return "missing_cells" }
+
+func (r MissingCellsRule) Check(st *SizeTable) []ValidationIssue {
+	keys := make(map[string]bool)
+	for _, measurements := range st.Measurements {
+		for key := range measurements {
+			keys[key] = true
+		}
+	}
+
+	total := len(st.Sizes) * len(keys)
+	if total == 0 {
+		return nil
+	}
+
+	missing := 0
+	for _, size := range st.Sizes {
+		for key := range keys {
+			if value, ok := st.Measurements[size][key]; !ok || value <= 0 {
+				missing++
+			}
+		}
+	}
+
+	ratio := float64(missing) / float64(total)
+	if ratio <= r.MaxRatio {
+		return nil
+	}
+
+	return []ValidationIssue{{
+		Rule:     r.Name(),
+		Severity: SeverityWarning,
+		Message:  fmt.Sprintf("%.0f%% of cells missing (max %.0f%%)", ratio*100, r.MaxRatio*100),
+	}}
+}
+
+// UpdateProductValidation stores the validation report and its score as quality score
+func (db *DB) UpdateProductValidation(ctx context.Context, asin string, report *ValidationReport) error {
+	reportJSON, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal validation report: %w", err)
+	}
+
+	query := `
+		UPDATE products SET
+			validation_report = $2,
+			quality_score = $3,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE asin = $1`
+
+	if _, err := db.pool.Exec(ctx, query, asin, reportJSON, report.Score); err != nil {
+		return fmt.Errorf("failed to update product validation: %w", err)
+	}
+
+	return nil
+}
+
+// childrensChart reports whether the sizes of a table are ages or body heights
+func childrensChart(st *SizeTable) bool {
+	return st.System == labels.SystemAge || st.System == labels.SystemHeight
+}
+
+// unitToCM returns the factor converting the table unit to centimeters
+func unitToCM(unit string) float64 {
+	switch strings.ToLower(strings.TrimSpace(unit)) {
+	case "in", "inch", "inches", "zoll":
+		return 2.54
+	default:
+		return 1
+	}
+}
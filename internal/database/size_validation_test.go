@@ -0,0 +1,196 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSizeTableValidator_Validate(t *testing.T) {
+	v := DefaultSizeTableValidator()
+
+	t.Run("clean table is valid with full score", func(t *testing.T) {
+		st := &SizeTable{
+			Sizes: []string{"S", "M", "L"},
+			Measurements: map[string]map[string]float64{
+				"S": {"chest": 96, "length": 70},
+				"M": {"chest": 100, "length": 72},
+				"L": {"chest": 104, "length": 74},
+			},
+			Unit: "cm",
+		}
+
+		report := v.Validate(st)
+		assert.True(t, report.Valid)
+		assert.Equal(t, 1.0, report.Score)
+		assert.Empty(t, report.Issues)
+	})
+
+	t.Run("implausible length is an error", func(t *testing.T) {
+		st := &SizeTable{
+			Sizes: []string{"M"},
+			Measurements: map[string]map[string]float64{
+				"M": {"chest": 100, "length": 7.2},
+			},
+			Unit: "cm",
+		}
+
+		report := v.Validate(st)
+		assert.False(t, report.Valid)
+		assert.Equal(t, "plausibility", report.Issues[0].Rule)
+		assert.Equal(t, "length", report.Issues[0].Measurement)
+	})
+
+	t.Run("inch tables are converted before range check", func(t *testing.T) {
+		st := &SizeTable{
+			Sizes: []string{"M"},
+			Measurements: map[string]map[string]float64{
+				"M": {"chest": 40, "length": 28},
+			},
+			Unit: "inch",
+		}
+
+		assert.True(t, v.Validate(st).Valid)
+	})
+
+	t.Run("shrinking measurements are a warning", func(t *testing.T) {
+		st := &SizeTable{
+			Sizes: []string{"S", "M", "L"},
+			Measurements: map[string]map[string]float64{
+				"S": {"chest": 96, "length": 70},
+				"M": {"chest": 100, "length": 68},
+				"L": {"chest": 104, "length": 74},
+			},
+			Unit: "cm",
+		}
+
+		report := v.Validate(st)
+		assert.True(t, report.Valid)
+		assert.Less(t, report.Score, 1.0)
+		assert.Equal(t, "monotonic", report.Issues[0].Rule)
+		assert.Equal(t, "M", report.Issues[0].Size)
+	})
+
+	t.Run("duplicate sizes are an error", func(t *testing.T) {
+		st := &SizeTable{
+			Sizes: []string{"M", "m"},
+			Measurements: map[string]map[string]float64{
+				"M": {"chest": 100, "length": 72},
+				"m": {"chest": 100, "length": 72},
+			},
+			Unit: "cm",
+		}
+
+		report := v.Validate(st)
+		assert.False(t, report.Valid)
+		assert.Equal(t, "duplicate_sizes", report.Issues[0].Rule)
+	})
+
+	t.Run("too many missing cells is a warning", func(t *testing.T) {
+		st := &SizeTable{
+			Sizes: []string{"S", "M", "L"},
+			Measurements: map[string]map[string]float64{
+				"S": {"chest": 96},
+				"M": {"chest": 100, "length": 72, "sleeve": 60},
+				"L": {"chest": 104},
+			},
+			Unit: "cm",
+		}
+
+		report := v.Validate(st)
+		assert.True(t, report.Valid)
+		assert.Equal(t, "missing_cells", report.Issues[0].Rule)
+	})
+
+	t.Run("missing required measurements", func(t *testing.T) {
+		st := &SizeTable{
+			Sizes: []string{"M"},
+			Measurements: map[string]map[string]float64{
+				"M": {"chest": 100},
+			},
+			Unit: "cm",
+		}
+
+		report := v.Validate(st)
+		assert.False(t, report.Valid)
+		assert.Equal(t, "required_measurements", report.Issues[0].Rule)
+	})
+
+	t.Run("nil table", func(t *testing.T) {
+		report := v.Validate(nil)
+		assert.False(t, report.Valid)
+		assert.Equal(t, 0.0, report.Score)
+	})
+}
+
+func TestValidationReport_Rejection(t *testing.T) {
+	report := &ValidationReport{Issues: []ValidationIssue{
+		{Rule: "monotonic", Severity: SeverityWarning, Message: "length shrinks from S to M"},
+		{Rule: "plausibility", Severity: SeverityError, Message: "length 7.2cm out of range"},
+		{Rule: "required_measurements", Severity: SeverityError, Message: "no size has all of length, chest"},
+	}}
+
+	issue := report.Rejection()
+	if assert.NotNil(t, issue) {
+		assert.Equal(t, "required_measurements", issue.Rule)
+	}
+	assert.Nil(t, (&ValidationReport{Issues: report.Issues[:2]}).Rejection())
+	assert.NotNil(t, DefaultSizeTableValidator().Validate(nil).Rejection())
+}
+
+func TestSizeTableValidator_WithPolicy(t *testing.T) {
+	chestOnly := &SizeTable{
+		Sizes:        []string{"M"},
+		Measurements: map[string]map[string]float64{"M": {"chest": 100}},
+		Unit:         "cm",
+	}
+
+	report := DefaultSizeTableValidator().WithPolicy(MeasurementPolicy{RequireChest: true}).Validate(chestOnly)
+	assert.True(t, report.Valid)
+	assert.Empty(t, report.Issues)
+
+	partial := MeasurementPolicy{RequireLength: true, RequireChest: true, AcceptPartial: true}
+	report = DefaultSizeTableValidator().WithPolicy(partial).Validate(chestOnly)
+	assert.True(t, report.Valid)
+	assert.Equal(t, "required_measurements", report.Issues[0].Rule)
+	assert.Equal(t, SeverityWarning, report.Issues[0].Severity)
+
+	report = DefaultSizeTableValidator().WithPolicy(LengthMeasurementPolicy()).Validate(chestOnly)
+	assert.False(t, report.Valid)
+	assert.Equal(t, "no size has all of length", report.Issues[0].Message)
+}
+
+func TestSizeTableValidator_ChildrensCharts(t *testing.T) {
+	v := DefaultSizeTableValidator()
+	kids := &SizeTable{
+		Sizes: []string{"92-98cm", "104-110cm", "116-122cm"},
+		Measurements: map[string]map[string]float64{
+			"92-98cm":   {"length": 36, "chest": 29},
+			"104-110cm": {"length": 40, "chest": 31},
+			"116-122cm": {"length": 44, "chest": 33},
+		},
+		Unit:   "cm",
+		System: "HEIGHT",
+	}
+
+	report := v.Validate(kids)
+	assert.True(t, report.Valid)
+	assert.Empty(t, report.Issues)
+
+	// The same values are implausible for adult sizes
+	kids.System = "INT"
+	assert.False(t, v.Validate(kids).Valid)
+
+	t.Run("body measurements only", func(t *testing.T) {
+		st := &SizeTable{
+			Sizes:        []string{"6-7Y"},
+			Measurements: map[string]map[string]float64{"6-7Y": {"chest": 64, "waist": 57}},
+			Unit:         "cm",
+			System:       "AGE",
+		}
+		report := v.Validate(st)
+		assert.True(t, report.Valid)
+		assert.Equal(t, "required_measurements", report.Issues[0].Rule)
+		assert.Equal(t, SeverityWarning, report.Issues[0].Severity)
+	})
+}
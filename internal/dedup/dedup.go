@@ -0,0 +1,135 @@
+package dedup
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+)
+
+// Match key prefixes, a shared key links two ASINs to the same physical product
+const (
+	KeyImage      = "img"
+	KeyTitleSizes = "ts"
+)
+
+// Fingerprint identifies a physical product independent of its ASIN and marketplace
+type Fingerprint struct {
+	Brand         string `json:"brand"`
+	TitleKey      string `json:"title_key"`
+	ImageHash     string `json:"image_hash,omitempty"`
+	SizeSignature string `json:"size_signature,omitempty"`
+}
+
+// Product is the data a fingerprint is computed from
+type Product struct {
+	Brand     string
+	Title     string
+	ImageURLs []string
+	SizeTable *database.SizeTable
+}
+
+var (
+	nonAlnum = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+	// imageID matches the Amazon image ID in URLs like /images/I/71abc+gL._AC_UX679_.jpg
+	imageID = regexp.MustCompile(`/images/I/([^./]+)`)
+	// sizeToken matches letter sizes like "s", "xl" or "3xl" in titles
+	sizeToken = regexp.MustCompile(`^(x{0,3}s|m|x{0,4}l|[2-5]x[sl])$`)
+)
+
+// titleNoise are tokens that vary between listings of the same product
+var titleNoise = map[string]bool{
+	"herren": true, "damen": true, "men": true, "mens": true, "women": true, "womens": true,
+	"für": true, "fur": true, "for": true, "und": true, "and": true, "mit": true, "with": true,
+	"der": true, "die": true, "das": true, "the": true, "a": true, "size": true, "grösse": true, "größe": true,
+}
+
+// Compute builds the fingerprint of a product
+func Compute(p Product) Fingerprint {
+	brand := normalize(p.Brand)
+	return Fingerprint{
+		Brand:         brand,
+		TitleKey:      titleKey(p.Title, brand),
+		ImageHash:     imageHash(p.ImageURLs),
+		SizeSignature: sizeSignature(p.SizeTable),
+	}
+}
+
+// MatchKeys returns the keys under which duplicates are looked up. The image key alone is
+// strong enough since Amazon reuses image IDs across marketplaces, title and sizes only match together.
+func (f Fingerprint) MatchKeys() []string {
+	var keys []string
+	if f.ImageHash != "" {
+		keys = append(keys, fmt.Sprintf("%s:%s", KeyImage, hash(f.Brand, f.ImageHash)))
+	}
+	if f.TitleKey != "" && f.SizeSignature != "" {
+		keys = append(keys, fmt.Sprintf("%s:%s", KeyTitleSizes, hash(f.Brand, f.TitleKey, f.SizeSignature)))
+	}
+	return keys
+}
+
+// normalize lowercases the text and collapses everything but letters and digits into single spaces
+func normalize(s string) string {
+	return strings.TrimSpace(nonAlnum.ReplaceAllString(strings.ToLower(s), " "))
+}
+
+// titleKey returns the sorted, deduplicated title tokens without brand, size and filler words
+func titleKey(title, brand string) string {
+	skip := make(map[string]bool)
+	for _, token := range strings.Fields(brand) {
+		skip[token] = true
+	}
+
+	seen := make(map[string]bool)
+	var tokens []string
+	for _, token := range strings.Fields(normalize(title)) {
+		if skip[token] || titleNoise[token] || seen[token] || sizeToken.MatchString(token) {
+			continue
+		}
+		seen[token] = true
+		tokens = append(tokens, token)
+	}
+	sort.Strings(tokens)
+	return strings.Join(tokens, " ")
+}
+
+// imageHash hashes the Amazon image ID of the main image, ignoring size and crop suffixes
+func imageHash(urls []string) string {
+	for _, u := range urls {
+		if m := imageID.FindStringSubmatch(u); m != nil {
+			return hash(m[1])
+		}
+		if u != "" {
+			base := path.Base(u)
+			return hash(strings.SplitN(base, ".", 2)[0])
+		}
+	}
+	return ""
+}
+
+// sizeSignature hashes the canonical sizes and their measurements rounded to whole centimeters
+func sizeSignature(st *database.SizeTable) string {
+	rows := database.NormalizeSizeTable("", st)
+	if len(rows) == 0 {
+		return ""
+	}
+
+	parts := make([]string, 0, len(rows))
+	for _, row := range rows {
+		parts = append(parts, fmt.Sprintf("%s/%s=%d", row.CanonicalSize, row.Measurement, int(math.Round(row.ValueCM))))
+	}
+	sort.Strings(parts)
+	return hash(parts...)
+}
+
+// hash returns a short hex SHA-1 of the joined parts
+func hash(parts ...string) string {
+	sum := sha1.Sum([]byte(strings.Join(parts, "|")))
+	return hex.EncodeToString(sum[:8])
+}
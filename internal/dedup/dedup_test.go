@@ -0,0 +1,97 @@
+package dedup
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+)
+
+func shirtTable(chest ...float64) *database.SizeTable {
+	sizes := []string{"S", "M", "L"}
+	st := &database.SizeTable{
+		Sizes:        sizes,
+		Measurements: make(map[string]map[string]float64),
+		Unit:         "cm",
+	}
+	for i, size := range sizes {
+		st.Measurements[size] = map[string]float64{"chest": chest[i], "length": 70 + float64(i)*2}
+	}
+	return st
+}
+
+func TestCompute_SameProductAcrossMarketplaces(t *testing.T) {
+	de := Compute(Product{
+		Brand:     "Tommy Hilfiger",
+		Title:     "Tommy Hilfiger Herren T-Shirt Core Stretch Slim Fit, Größe XL",
+		ImageURLs: []string{"https://m.media-amazon.com/images/I/71abcDEF+gL._AC_UX679_.jpg"},
+		SizeTable: shirtTable(96, 102, 108),
+	})
+	uk := Compute(Product{
+		Brand:     "TOMMY HILFIGER",
+		Title:     "Tommy Hilfiger Core Stretch Slim Fit T-Shirt Men's",
+		ImageURLs: []string{"https://images-eu.ssl-images-amazon.com/images/I/71abcDEF+gL._AC_SX342_.jpg"},
+		SizeTable: shirtTable(96.2, 101.8, 108),
+	})
+
+	if de.ImageHash == "" || de.ImageHash != uk.ImageHash {
+		t.Errorf("image hashes differ: %q vs %q", de.ImageHash, uk.ImageHash)
+	}
+	if de.SizeSignature == "" || de.SizeSignature != uk.SizeSignature {
+		t.Errorf("size signatures differ: %q vs %q", de.SizeSignature, uk.SizeSignature)
+	}
+	if !reflect.DeepEqual(de.MatchKeys(), uk.MatchKeys()) {
+		t.Errorf("match keys differ: %v vs %v", de.MatchKeys(), uk.MatchKeys())
+	}
+}
+
+func TestCompute_DifferentProducts(t *testing.T) {
+	a := Compute(Product{
+		Brand:     "Tommy Hilfiger",
+		Title:     "Tommy Hilfiger Herren T-Shirt Core Stretch",
+		ImageURLs: []string{"https://m.media-amazon.com/images/I/71abcDEF+gL._AC_UX679_.jpg"},
+		SizeTable: shirtTable(96, 102, 108),
+	})
+	b := Compute(Product{
+		Brand:     "Tommy Hilfiger",
+		Title:     "Tommy Hilfiger Herren Polo Shirt Regular Fit",
+		ImageURLs: []string{"https://m.media-amazon.com/images/I/81xyz123AB._AC_UX679_.jpg"},
+		SizeTable: shirtTable(100, 106, 112),
+	})
+
+	for _, ka := range a.MatchKeys() {
+		for _, kb := range b.MatchKeys() {
+			if ka == kb {
+				t.Errorf("unexpected shared match key %q", ka)
+			}
+		}
+	}
+}
+
+func TestTitleKey(t *testing.T) {
+	tests := []struct {
+		title string
+		brand string
+		want  string
+	}{
+		{"Tommy Hilfiger Herren T-Shirt Core Stretch", "tommy hilfiger", "core shirt stretch t"},
+		{"Core Stretch T-Shirt for Men, XXL", "tommy hilfiger", "core shirt stretch t"},
+		{"Basic Tee 2er Pack 3XL", "", "2er basic pack tee"},
+	}
+	for _, tt := range tests {
+		if got := titleKey(tt.title, tt.brand); got != tt.want {
+			t.Errorf("titleKey(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestMatchKeys_RequireSignals(t *testing.T) {
+	if keys := Compute(Product{Brand: "Acme", Title: "Shirt"}).MatchKeys(); len(keys) != 0 {
+		t.Errorf("expected no match keys without image and size table, got %v", keys)
+	}
+
+	keys := Compute(Product{Brand: "Acme", Title: "Shirt", SizeTable: shirtTable(96, 102, 108)}).MatchKeys()
+	if len(keys) != 1 || keys[0][:len(KeyTitleSizes)] != KeyTitleSizes {
+		t.Errorf("expected only a title/size key, got %v", keys)
+	}
+}
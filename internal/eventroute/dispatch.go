@@ -0,0 +1,82 @@
+package eventroute
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/schema"
+)
+
+// HTTPDispatcher delivers events to webhook targets and to Kafka topics through a Kafka REST proxy
+type HTTPDispatcher struct {
+	client   *http.Client
+	kafkaURL string
+}
+
+// NewHTTPDispatcher creates a dispatcher, an empty kafkaRESTURL rejects Kafka targets
+func NewHTTPDispatcher(kafkaRESTURL string, timeout time.Duration) *HTTPDispatcher {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &HTTPDispatcher{
+		client:   &http.Client{Timeout: timeout},
+		kafkaURL: strings.TrimRight(kafkaRESTURL, "/"),
+	}
+}
+
+// Deliver sends one event to a webhook or Kafka target
+func (d *HTTPDispatcher) Deliver(ctx context.Context, target Target, event *schema.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	switch target.Kind {
+	case KindWebhook:
+		return d.post(ctx, target.Name, "application/json", data, event)
+	case KindKafka:
+		if d.kafkaURL == "" {
+			return fmt.Errorf("no Kafka REST proxy configured for topic %s", target.Name)
+		}
+		// The aggregate ID is the record key so events of a product stay in one partition
+		records, err := json.Marshal(map[string]any{
+			"records": []map[string]any{{"key": event.AggregateID, "value": json.RawMessage(data)}},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal kafka records: %w", err)
+		}
+		endpoint := d.kafkaURL + "/topics/" + url.PathEscape(target.Name)
+		return d.post(ctx, endpoint, "application/vnd.kafka.json.v2+json", records, event)
+	}
+	return fmt.Errorf("unsupported event target kind %q", target.Kind)
+}
+
+func (d *HTTPDispatcher) post(ctx context.Context, endpoint, contentType string, body []byte, event *schema.Event) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	// Receivers deduplicate redeliveries by event ID
+	req.Header.Set("X-Event-ID", event.ID)
+	req.Header.Set("X-Event-Type", event.Type)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to deliver event: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("event target answered %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package eventroute
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/schema"
+)
+
+func TestHTTPDispatcherDeliver(t *testing.T) {
+	var gotPath, gotType, gotEventID string
+	var gotBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotType, gotEventID = r.URL.Path, r.Header.Get("Content-Type"), r.Header.Get("X-Event-ID")
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	d := NewHTTPDispatcher(server.URL+"/", time.Second)
+	event := &schema.Event{ID: "evt-1", Type: "NEW_PRODUCT_DETECTED", AggregateID: "B08N5WRWNW", Payload: json.RawMessage(`{}`)}
+
+	if err := d.Deliver(context.Background(), Target{KindWebhook, server.URL + "/hook"}, event); err != nil {
+		t.Fatal(err)
+	}
+	if gotPath != "/hook" || gotType != "application/json" || gotEventID != "evt-1" || gotBody["id"] != "evt-1" {
+		t.Errorf("Unexpected webhook request: %s %s %s %v", gotPath, gotType, gotEventID, gotBody)
+	}
+
+	if err := d.Deliver(context.Background(), Target{KindKafka, "products"}, event); err != nil {
+		t.Fatal(err)
+	}
+	if gotPath != "/topics/products" || gotType != "application/vnd.kafka.json.v2+json" {
+		t.Errorf("Unexpected kafka request: %s %s", gotPath, gotType)
+	}
+	records, _ := gotBody["records"].([]any)
+	if len(records) != 1 || records[0].(map[string]any)["key"] != "B08N5WRWNW" {
+		t.Errorf("Unexpected kafka records: %v", gotBody)
+	}
+}
+
+func TestHTTPDispatcherFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	event := &schema.Event{ID: "evt-1", Payload: json.RawMessage(`{}`)}
+	d := NewHTTPDispatcher("", time.Second)
+
+	if err := d.Deliver(context.Background(), Target{KindWebhook, server.URL}, event); err == nil {
+		t.Error("Expected error for a 502 answer")
+	}
+	if err := d.Deliver(context.Background(), Target{KindKafka, "products"}, event); err == nil {
+		t.Error("Expected error without a Kafka REST proxy")
+	}
+}
@@ -0,0 +1,188 @@
+// Package eventroute maps event types to the destinations the outbox relay publishes them to.
+package eventroute
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Destination kinds
+const (
+	KindRedis   = "redis"   // Redis stream, the default
+	KindKafka   = "kafka"   // Kafka topic, produced through a Kafka REST proxy
+	KindWebhook = "webhook" // HTTP POST of each event
+)
+
+// DefaultTarget is the stream events without a route are published to
+const DefaultTarget = "stream:product_lifecycle"
+
+var kafkaTopic = regexp.MustCompile(`^[A-Za-z0-9._-]{1,249}$`)
+
+// Target is a single destination of an event
+type Target struct {
+	Kind string
+	Name string // Stream key, topic or webhook URL
+}
+
+// ParseTarget parses "redis:<stream>", "kafka:<topic>" or "webhook:<url>". Anything else is a Redis
+// stream key, so the target_stream of existing outbox rows like "stream:product_lifecycle" stays valid.
+func ParseTarget(s string) (Target, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Target{}, fmt.Errorf("empty event target")
+	}
+
+	kind, name, _ := strings.Cut(s, ":")
+	switch kind {
+	case KindRedis:
+		if name == "" {
+			return Target{}, fmt.Errorf("redis target needs a stream, e.g. redis:stream:product_lifecycle")
+		}
+		return Target{Kind: KindRedis, Name: name}, nil
+	case KindKafka:
+		if !kafkaTopic.MatchString(name) {
+			return Target{}, fmt.Errorf("invalid kafka topic %q", name)
+		}
+		return Target{Kind: KindKafka, Name: name}, nil
+	case KindWebhook:
+		u, err := url.Parse(name)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return Target{}, fmt.Errorf("webhook target needs an http(s) URL, got %q", name)
+		}
+		return Target{Kind: KindWebhook, Name: name}, nil
+	}
+	return Target{Kind: KindRedis, Name: s}, nil
+}
+
+// String returns the form stored in outbox_event.target_stream, Redis streams keep their bare key
+func (t Target) String() string {
+	if t.Kind == KindRedis {
+		return t.Name
+	}
+	return t.Kind + ":" + t.Name
+}
+
+// Table routes event types to one or more targets, a nil Table sends everything to DefaultTarget
+type Table struct {
+	routes   map[string][]Target
+	fallback []Target
+}
+
+// ParseTable parses a routing spec like
+//
+//	NEW_PRODUCT_DETECTED=stream:product_lifecycle,kafka:products;PRODUCT_CREATED=webhook:https://host/hook
+//
+// Event types without an entry go to fallback, empty uses DefaultTarget.
+func ParseTable(spec, fallback string) (*Table, error) {
+	if fallback == "" {
+		fallback = DefaultTarget
+	}
+	fallbackTargets, err := parseTargets(fallback)
+	if err != nil {
+		return nil, fmt.Errorf("invalid default event target: %w", err)
+	}
+
+	t := &Table{routes: make(map[string][]Target), fallback: fallbackTargets}
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		eventType, targets, ok := strings.Cut(entry, "=")
+		eventType = strings.TrimSpace(eventType)
+		if !ok || eventType == "" {
+			return nil, fmt.Errorf("invalid event route %q, expected TYPE=target", entry)
+		}
+		if _, dup := t.routes[eventType]; dup {
+			return nil, fmt.Errorf("duplicate event route for %s", eventType)
+		}
+		parsed, err := parseTargets(targets)
+		if err != nil {
+			return nil, fmt.Errorf("invalid route for %s: %w", eventType, err)
+		}
+		t.routes[eventType] = parsed
+	}
+	return t, nil
+}
+
+func parseTargets(s string) ([]Target, error) {
+	var targets []Target
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ",") {
+		target, err := ParseTarget(part)
+		if err != nil {
+			return nil, err
+		}
+		if seen[target.String()] {
+			continue
+		}
+		seen[target.String()] = true
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// Resolve returns the targets of an event type
+func (t *Table) Resolve(eventType string) []Target {
+	if t == nil {
+		return []Target{{Kind: KindRedis, Name: DefaultTarget}}
+	}
+	if targets, ok := t.routes[eventType]; ok {
+		return targets
+	}
+	return t.fallback
+}
+
+// Validate checks that every routed event type is known and that the kinds used are configured
+func (t *Table) Validate(known func(eventType string) bool, kafkaConfigured bool) error {
+	if t == nil {
+		return nil
+	}
+
+	check := func(targets []Target) error {
+		for _, target := range targets {
+			if target.Kind == KindKafka && !kafkaConfigured {
+				return fmt.Errorf("kafka target %s needs a Kafka REST proxy URL", target.Name)
+			}
+		}
+		return nil
+	}
+
+	if err := check(t.fallback); err != nil {
+		return err
+	}
+	for eventType, targets := range t.routes {
+		if known != nil && !known(eventType) {
+			return fmt.Errorf("route for unknown event type %s", eventType)
+		}
+		if err := check(targets); err != nil {
+			return fmt.Errorf("route for %s: %w", eventType, err)
+		}
+	}
+	return nil
+}
+
+// Routes returns the configured routes as strings for logging and health checks, the fallback under "*"
+func (t *Table) Routes() map[string][]string {
+	if t == nil {
+		return map[string][]string{"*": {DefaultTarget}}
+	}
+
+	routes := map[string][]string{"*": targetStrings(t.fallback)}
+	for eventType, targets := range t.routes {
+		routes[eventType] = targetStrings(targets)
+	}
+	return routes
+}
+
+func targetStrings(targets []Target) []string {
+	s := make([]string, len(targets))
+	for i, target := range targets {
+		s[i] = target.String()
+	}
+	sort.Strings(s)
+	return s
+}
@@ -0,0 +1,86 @@
+package eventroute
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Target
+		wantErr bool
+	}{
+		{"stream:product_lifecycle", Target{KindRedis, "stream:product_lifecycle"}, false},
+		{"redis:stream:audit", Target{KindRedis, "stream:audit"}, false},
+		{"kafka:products.v1", Target{KindKafka, "products.v1"}, false},
+		{"webhook:https://example.com/hook?token=a", Target{KindWebhook, "https://example.com/hook?token=a"}, false},
+		{"kafka:bad topic", Target{}, true},
+		{"webhook:ftp://example.com", Target{}, true},
+		{"redis:", Target{}, true},
+		{"", Target{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseTarget(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTarget(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseTarget(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTableResolve(t *testing.T) {
+	table, err := ParseTable("NEW_PRODUCT_DETECTED=stream:product_lifecycle,kafka:products; PRODUCT_CREATED=webhook:https://example.com/hook", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := table.Resolve("NEW_PRODUCT_DETECTED")
+	want := []Target{{KindRedis, "stream:product_lifecycle"}, {KindKafka, "products"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+
+	if got := table.Resolve("02A_PRODUCT_VALIDATED"); len(got) != 1 || got[0].String() != DefaultTarget {
+		t.Errorf("Expected fallback to %s, got %+v", DefaultTarget, got)
+	}
+
+	var nilTable *Table
+	if got := nilTable.Resolve("NEW_PRODUCT_DETECTED"); len(got) != 1 || got[0].String() != DefaultTarget {
+		t.Errorf("Expected nil table to use %s, got %+v", DefaultTarget, got)
+	}
+}
+
+func TestParseTableErrors(t *testing.T) {
+	for _, spec := range []string{
+		"NEW_PRODUCT_DETECTED",
+		"=stream:x",
+		"NEW_PRODUCT_DETECTED=kafka:a b",
+		"NEW_PRODUCT_DETECTED=stream:a;NEW_PRODUCT_DETECTED=stream:b",
+	} {
+		if _, err := ParseTable(spec, ""); err == nil {
+			t.Errorf("Expected error for %q", spec)
+		}
+	}
+}
+
+func TestTableValidate(t *testing.T) {
+	known := func(eventType string) bool { return eventType == "NEW_PRODUCT_DETECTED" }
+
+	table, _ := ParseTable("NEW_PRODUCT_DETECTED=kafka:products", "")
+	if err := table.Validate(known, false); err == nil {
+		t.Error("Expected kafka route without REST proxy to fail")
+	}
+	if err := table.Validate(known, true); err != nil {
+		t.Errorf("Expected valid routes, got %v", err)
+	}
+
+	table, _ = ParseTable("NEW_PRODUCT_DETECTD=stream:x", "")
+	if err := table.Validate(known, true); err == nil {
+		t.Error("Expected misspelled event type to fail")
+	}
+}
@@ -0,0 +1,246 @@
+// Package importer reads lists of target ASINs from CSV files, Google Sheets and URL lists, and
+// corrected size tables from CSV and XLSX files.
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/asin"
+)
+
+var sheetPattern = regexp.MustCompile(`^https://docs\.google\.com/spreadsheets/d/([\w-]+)`)
+
+// Invalid is a list entry without a valid ASIN
+type Invalid struct {
+	Line  int    `json:"line"`
+	Value string `json:"value"`
+}
+
+// List is a parsed ASIN list
+type List struct {
+	ASINs      []string  `json:"asins"`      // Valid ASINs in list order without duplicates
+	Duplicates int       `json:"duplicates"` // Entries repeating an earlier ASIN of the list
+	Invalid    []Invalid `json:"invalid,omitempty"`
+}
+
+// Parse reads a CSV or plain list with one ASIN or product URL per line. Comma, semicolon and tab
+// separated files are detected from the first line. A column named "asin" is used when the header
+// has one, otherwise the first cell holding an ASIN counts and a first line without any is skipped
+// as header.
+func Parse(r io.Reader) (*List, error) {
+	br := bufio.NewReader(r)
+	if bom, err := br.Peek(3); err == nil && bytes.Equal(bom, []byte("\xef\xbb\xbf")) {
+		br.Discard(3)
+	}
+	first, _ := br.Peek(4096)
+
+	cr := csv.NewReader(br)
+	cr.Comma = detectDelimiter(first)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	cr.TrimLeadingSpace = true
+
+	list := newCollector()
+	column := -1
+
+	for record := 0; ; record++ {
+		fields, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read list: %w", err)
+		}
+		line, _ := cr.FieldPos(0)
+
+		if record == 0 {
+			if column = headerColumn(fields); column >= 0 {
+				continue
+			}
+		}
+
+		asin, ok := recordASIN(fields, column)
+		switch {
+		case ok:
+			list.add(asin)
+		case record == 0 && column < 0:
+			// Header without an "asin" column
+		case !isBlank(fields):
+			list.Invalid = append(list.Invalid, Invalid{Line: line, Value: strings.Join(fields, string(cr.Comma))})
+		}
+	}
+
+	return list.List, nil
+}
+
+// FromValues validates ASINs or product URLs given one per value, Invalid.Line is the 1-based index
+func FromValues(values []string) *List {
+	list := newCollector()
+	for i, v := range values {
+		if asin, ok := asin.Extract(v); ok {
+			list.add(asin)
+		} else {
+			list.Invalid = append(list.Invalid, Invalid{Line: i + 1, Value: v})
+		}
+	}
+	return list.List
+}
+
+// ResolveShortLinks follows amzn.to and a.co links among the invalid entries and adds the ASINs they
+// lead to. Entries that cannot be resolved stay invalid.
+func (l *List) ResolveShortLinks(ctx context.Context, r *asin.Resolver) {
+	c := &collector{List: l, seen: make(map[string]bool)}
+	for _, a := range l.ASINs {
+		c.seen[a] = true
+	}
+
+	invalid := l.Invalid[:0]
+	for _, entry := range l.Invalid {
+		resolved := false
+		for _, f := range strings.FieldsFunc(entry.Value, func(sep rune) bool { return sep == ',' || sep == ';' || sep == '\t' }) {
+			if !asin.IsShortLink(f) {
+				continue
+			}
+			if a, err := r.Resolve(ctx, f); err == nil {
+				c.add(a)
+				resolved = true
+				break
+			}
+		}
+		if !resolved {
+			invalid = append(invalid, entry)
+		}
+	}
+	l.Invalid = invalid
+}
+
+// collector builds a List, dropping duplicate ASINs
+type collector struct {
+	*List
+	seen map[string]bool
+}
+
+func newCollector() *collector {
+	return &collector{List: &List{}, seen: make(map[string]bool)}
+}
+
+func (c *collector) add(asin string) {
+	if c.seen[asin] {
+		c.Duplicates++
+		return
+	}
+	c.seen[asin] = true
+	c.ASINs = append(c.ASINs, asin)
+}
+
+// Open returns the list at source: a file path, "-" for stdin, an http(s) URL or a Google Sheets
+// link, which is fetched as CSV export. The sheet must be shared with anyone holding the link.
+func Open(ctx context.Context, source string) (io.ReadCloser, error) {
+	if source == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		f, err := os.Open(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open list: %w", err)
+		}
+		return f, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, SheetCSVURL(source), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch list: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to fetch list: status %d", resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+// SheetCSVURL returns the CSV export URL of a Google Sheets link, keeping the selected tab.
+// Other URLs are returned unchanged.
+func SheetCSVURL(raw string) string {
+	m := sheetPattern.FindStringSubmatch(raw)
+	if m == nil || strings.Contains(raw, "/export?") {
+		return raw
+	}
+
+	query := url.Values{"format": {"csv"}}
+	if u, err := url.Parse(raw); err == nil {
+		// The tab is in the fragment of edit links and the query of shared links
+		gid := u.Query().Get("gid")
+		if frag, err := url.ParseQuery(u.Fragment); err == nil && frag.Get("gid") != "" {
+			gid = frag.Get("gid")
+		}
+		if gid != "" {
+			query.Set("gid", gid)
+		}
+	}
+	return fmt.Sprintf("https://docs.google.com/spreadsheets/d/%s/export?%s", m[1], query.Encode())
+}
+
+// detectDelimiter picks the most frequent separator of the first line
+func detectDelimiter(data []byte) rune {
+	line, _, _ := bytes.Cut(data, []byte("\n"))
+	best, count := ',', bytes.Count(line, []byte(","))
+	for _, sep := range []rune{';', '\t'} {
+		if n := bytes.Count(line, []byte(string(sep))); n > count {
+			best, count = sep, n
+		}
+	}
+	return best
+}
+
+// headerColumn returns the index of the "asin" column of a header, -1 if there is none
+func headerColumn(fields []string) int {
+	for i, f := range fields {
+		if strings.EqualFold(strings.TrimSpace(f), "asin") {
+			return i
+		}
+	}
+	return -1
+}
+
+// recordASIN returns the ASIN of column, or of the first cell holding one when column is -1
+func recordASIN(fields []string, column int) (string, bool) {
+	if column >= 0 {
+		if column >= len(fields) {
+			return "", false
+		}
+		return asin.Extract(fields[column])
+	}
+	for _, f := range fields {
+		if asin, ok := asin.Extract(f); ok {
+			return asin, true
+		}
+	}
+	return "", false
+}
+
+func isBlank(fields []string) bool {
+	for _, f := range fields {
+		if strings.TrimSpace(f) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,110 @@
+package importer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse_HeaderColumn(t *testing.T) {
+	in := "\xef\xbb\xbfMarke;ASIN;Kommentar\n" +
+		"TallFit;B0TEST0001;ok\n" +
+		"TallFit;b0test0002;\n" +
+		"LongLine;B0TEST0001;doppelt\n" +
+		"LongLine;kein asin;\n"
+
+	list, err := Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"B0TEST0001", "B0TEST0002"}; !reflect.DeepEqual(list.ASINs, want) {
+		t.Errorf("ASINs = %v, want %v", list.ASINs, want)
+	}
+	if list.Duplicates != 1 {
+		t.Errorf("Duplicates = %d, want 1", list.Duplicates)
+	}
+	if want := []Invalid{{Line: 5, Value: "LongLine;kein asin;"}}; !reflect.DeepEqual(list.Invalid, want) {
+		t.Errorf("Invalid = %+v, want %+v", list.Invalid, want)
+	}
+}
+
+func TestParse_URLListWithoutHeader(t *testing.T) {
+	in := "https://www.amazon.de/dp/B0TEST0001?th=1&psc=1\n" +
+		"\n" +
+		"B0TEST0003\n" +
+		"https://www.amazon.de/s?k=shirt\n"
+
+	list, err := Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"B0TEST0001", "B0TEST0003"}; !reflect.DeepEqual(list.ASINs, want) {
+		t.Errorf("ASINs = %v, want %v", list.ASINs, want)
+	}
+	if len(list.Invalid) != 1 || list.Invalid[0].Line != 4 {
+		t.Errorf("Invalid = %+v, want line 4", list.Invalid)
+	}
+}
+
+func TestParse_SkipsHeaderWithoutASINColumn(t *testing.T) {
+	list, err := Parse(strings.NewReader("Produkt,Link\nShirt,https://www.amazon.de/dp/B0TEST0001\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.ASINs) != 1 || len(list.Invalid) != 0 {
+		t.Errorf("list = %+v, want one ASIN and no invalid entries", list)
+	}
+}
+
+func TestSheetCSVURL(t *testing.T) {
+	tests := map[string]string{
+		"https://docs.google.com/spreadsheets/d/1AbC-d_E/edit#gid=42":       "https://docs.google.com/spreadsheets/d/1AbC-d_E/export?format=csv&gid=42",
+		"https://docs.google.com/spreadsheets/d/1AbC-d_E/edit?usp=sharing":  "https://docs.google.com/spreadsheets/d/1AbC-d_E/export?format=csv",
+		"https://docs.google.com/spreadsheets/d/1AbC-d_E/export?format=csv": "https://docs.google.com/spreadsheets/d/1AbC-d_E/export?format=csv",
+		"https://example.com/asins.csv":                                     "https://example.com/asins.csv",
+	}
+	for in, want := range tests {
+		if got := SheetCSVURL(in); got != want {
+			t.Errorf("SheetCSVURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestOpen_URL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/asins.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, "B0TEST0001\n")
+	}))
+	defer srv.Close()
+
+	rc, err := Open(context.Background(), srv.URL+"/asins.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	list, err := Parse(rc)
+	if err != nil || len(list.ASINs) != 1 {
+		t.Errorf("Parse() = %+v, %v", list, err)
+	}
+
+	if _, err := Open(context.Background(), srv.URL+"/missing"); err == nil {
+		t.Error("expected error for 404")
+	}
+}
+
+func TestFromValues(t *testing.T) {
+	list := FromValues([]string{"B0TEST0001", "https://www.amazon.de/dp/B0TEST0001", "nope", "B0TEST0002"})
+	if want := []string{"B0TEST0001", "B0TEST0002"}; !reflect.DeepEqual(list.ASINs, want) {
+		t.Errorf("ASINs = %v, want %v", list.ASINs, want)
+	}
+	if list.Duplicates != 1 || len(list.Invalid) != 1 || list.Invalid[0].Line != 3 {
+		t.Errorf("list = %+v, want 1 duplicate and entry 3 invalid", list)
+	}
+}
@@ -0,0 +1,265 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Table is a spreadsheet read by ReadTable
+type Table struct {
+	Headers []string
+	Rows    [][]string
+}
+
+// Data returns the table as {headers: [...], rows: [[...]]}, the shape the size chart script returns
+func (t *Table) Data() map[string]interface{} {
+	headers := make([]interface{}, len(t.Headers))
+	for i, h := range t.Headers {
+		headers[i] = h
+	}
+	rows := make([]interface{}, len(t.Rows))
+	for i, row := range t.Rows {
+		cells := make([]interface{}, len(row))
+		for j, cell := range row {
+			cells[j] = cell
+		}
+		rows[i] = cells
+	}
+	return map[string]interface{}{"headers": headers, "rows": rows}
+}
+
+// ReadTable reads a CSV file or the first sheet of an XLSX workbook, told apart by the zip signature.
+// The first non-blank row is the header, blank rows are skipped.
+func ReadTable(r io.Reader) (*Table, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read table: %w", err)
+	}
+
+	var records [][]string
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		records, err = readXLSX(data)
+	} else {
+		records, err = readCSV(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Table{}
+	for _, record := range records {
+		for i := range record {
+			record[i] = strings.TrimSpace(record[i])
+		}
+		if isBlank(record) {
+			continue
+		}
+		if t.Headers == nil {
+			t.Headers = record
+			continue
+		}
+		t.Rows = append(t.Rows, record)
+	}
+	if len(t.Headers) == 0 || len(t.Rows) == 0 {
+		return nil, errors.New("table needs a header and at least one row")
+	}
+	return t, nil
+}
+
+// readCSV parses a comma, semicolon or tab separated file
+func readCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.Comma = detectDelimiter(data)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	cr.TrimLeadingSpace = true
+
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+	return records, nil
+}
+
+// xlsxCell is a cell of a worksheet: shared strings (t="s") hold an index into the shared string table,
+// inline strings their text in is, everything else its value in v
+type xlsxCell struct {
+	Ref    string `xml:"r,attr"`
+	Type   string `xml:"t,attr"`
+	Value  string `xml:"v"`
+	Inline struct {
+		Text string `xml:"t"`
+		Runs []struct {
+			Text string `xml:"t"`
+		} `xml:"r"`
+	} `xml:"is"`
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		Cells []xlsxCell `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+type xlsxString struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (s xlsxString) String() string {
+	if len(s.Runs) == 0 {
+		return s.Text
+	}
+	var b strings.Builder
+	for _, r := range s.Runs {
+		b.WriteString(r.Text)
+	}
+	return b.String()
+}
+
+// readXLSX returns the cell texts of the first worksheet. Only values are read, formulas count with
+// their cached result.
+func readXLSX(data []byte) ([][]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open XLSX: %w", err)
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var shared []string
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		var sst struct {
+			Items []xlsxString `xml:"si"`
+		}
+		if err := decodeZipXML(f, &sst); err != nil {
+			return nil, err
+		}
+		for _, item := range sst.Items {
+			shared = append(shared, item.String())
+		}
+	}
+
+	f, ok := files[firstSheet(files)]
+	if !ok {
+		return nil, errors.New("XLSX has no worksheet")
+	}
+	var sheet xlsxSheet
+	if err := decodeZipXML(f, &sheet); err != nil {
+		return nil, err
+	}
+
+	records := make([][]string, 0, len(sheet.Rows))
+	for _, row := range sheet.Rows {
+		var record []string
+		for i, c := range row.Cells {
+			col := i
+			if ref := columnIndex(c.Ref); ref >= 0 {
+				col = ref
+			}
+			for len(record) <= col {
+				record = append(record, "")
+			}
+			record[col] = cellValue(c, shared)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// firstSheet returns the part name of the workbook's first sheet, resolved through the workbook
+// relationships and falling back to sheet1.xml
+func firstSheet(files map[string]*zip.File) string {
+	fallback := "xl/worksheets/sheet1.xml"
+
+	var workbook struct {
+		Sheets []struct {
+			RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	wb, okWB := files["xl/workbook.xml"]
+	rf, okRels := files["xl/_rels/workbook.xml.rels"]
+	if !okWB || !okRels || decodeZipXML(wb, &workbook) != nil || decodeZipXML(rf, &rels) != nil || len(workbook.Sheets) == 0 {
+		return fallback
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID == workbook.Sheets[0].RelID {
+			if strings.HasPrefix(rel.Target, "/") {
+				return strings.TrimPrefix(rel.Target, "/")
+			}
+			return path.Join("xl", rel.Target)
+		}
+	}
+	return fallback
+}
+
+func decodeZipXML(f *zip.File, v any) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", f.Name, err)
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", f.Name, err)
+	}
+	return nil
+}
+
+// cellValue returns the text of a cell
+func cellValue(c xlsxCell, shared []string) string {
+	switch c.Type {
+	case "s":
+		i, err := strconv.Atoi(strings.TrimSpace(c.Value))
+		if err != nil || i < 0 || i >= len(shared) {
+			return ""
+		}
+		return shared[i]
+	case "inlineStr":
+		if len(c.Inline.Runs) == 0 {
+			return c.Inline.Text
+		}
+		var b strings.Builder
+		for _, r := range c.Inline.Runs {
+			b.WriteString(r.Text)
+		}
+		return b.String()
+	default:
+		return c.Value
+	}
+}
+
+// columnIndex returns the zero-based column of a cell reference like "C7", -1 if it has none
+func columnIndex(ref string) int {
+	col := 0
+	n := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+		n++
+	}
+	if n == 0 {
+		return -1
+	}
+	return col - 1
+}
@@ -0,0 +1,69 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadTable_CSV(t *testing.T) {
+	data := "\xef\xbb\xbfGröße;S;M;L\n\nLänge; 70 ;72;74\nBrustumfang;96;102;108\n"
+	table, err := ReadTable(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	want := &Table{
+		Headers: []string{"Größe", "S", "M", "L"},
+		Rows:    [][]string{{"Länge", "70", "72", "74"}, {"Brustumfang", "96", "102", "108"}},
+	}
+	if !reflect.DeepEqual(table, want) {
+		t.Errorf("ReadTable() = %+v, want %+v", table, want)
+	}
+}
+
+func TestReadTable_XLSX(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	parts := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+			<sheets><sheet name="Größen" sheetId="1" r:id="rId3"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+			<Relationship Id="rId3" Type="worksheet" Target="worksheets/data.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst><si><t>Größe</t></si><si><t>M</t></si><si><r><t>Län</t></r><r><t>ge</t></r></si></sst>`,
+		"xl/worksheets/data.xml": `<worksheet><sheetData>
+			<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>
+			<row r="2"><c r="A2" t="s"><v>2</v></c><c r="C2"><v>72.5</v></c></row>
+			<row r="3"><c r="A3" t="inlineStr"><is><t>Brustumfang</t></is></c><c r="C3" t="str"><v>102</v></c></row>
+		</sheetData></worksheet>`,
+	}
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	table, err := ReadTable(&buf)
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	want := &Table{
+		Headers: []string{"Größe", "", "M"},
+		Rows:    [][]string{{"Länge", "", "72.5"}, {"Brustumfang", "", "102"}},
+	}
+	if !reflect.DeepEqual(table, want) {
+		t.Errorf("ReadTable() = %+v, want %+v", table, want)
+	}
+}
+
+func TestReadTable_HeaderOnly(t *testing.T) {
+	if _, err := ReadTable(strings.NewReader("Größe,S,M\n")); err == nil {
+		t.Error("expected an error for a table without rows")
+	}
+}
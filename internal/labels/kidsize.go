@@ -0,0 +1,93 @@
+package labels
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Size systems of children's apparel, sized by age or by body height in centimeters
+const (
+	SystemAge    = "AGE"
+	SystemHeight = "HEIGHT"
+)
+
+// kidsColumnTerms name the columns and rows of children's size charts. Plain "height" stays a
+// measurement, only terms for body height mark a size system.
+var kidsColumnTerms = []struct {
+	term   string
+	system string
+}{
+	{"körpergröße", SystemHeight}, {"koerpergroesse", SystemHeight}, {"körperhöhe", SystemHeight},
+	{"kindergröße", SystemHeight}, {"body height", SystemHeight}, {"child height", SystemHeight},
+	{"stature", SystemHeight}, {"taille enfant", SystemHeight}, {"altezza bambino", SystemHeight},
+	{"estatura", SystemHeight},
+	{"alter", SystemAge}, {"age", SystemAge}, {"âge", SystemAge}, {"età", SystemAge}, {"edad", SystemAge},
+	{"jahre", SystemAge}, {"years", SystemAge},
+}
+
+// Units of children's sizes, the label unit decides between years, months and centimeters
+var (
+	yearUnits = map[string]bool{
+		"j": true, "jahr": true, "jahre": true, "y": true, "yr": true, "yrs": true, "year": true, "years": true,
+		"an": true, "ans": true, "anno": true, "anni": true, "año": true, "años": true, "t": true,
+	}
+	monthUnits = map[string]bool{
+		"m": true, "mo": true, "mon": true, "monat": true, "monate": true, "month": true, "months": true,
+		"mois": true, "mese": true, "mesi": true, "mes": true, "meses": true,
+	}
+)
+
+// kidsSizePattern matches a number or range followed by an optional unit, e.g. "6-7 Jahre", "116/122 cm"
+// or "18-24M"
+var kidsSizePattern = regexp.MustCompile(`^(\d{1,3})(?:\s*(?:-|–|/|bis|to|à|a)\s*(\d{1,3}))?\s*\.?\s*(\p{L}*)\.?$`)
+
+// KidsSizeColumn returns the size system a size chart header names, e.g. SystemAge for "Alter" and
+// SystemHeight for "Körpergröße (cm)"
+func KidsSizeColumn(label string) (string, bool) {
+	label = strings.ToLower(strings.TrimSpace(label))
+	for _, t := range kidsColumnTerms {
+		if strings.Contains(label, t.term) {
+			return t.system, true
+		}
+	}
+	return "", false
+}
+
+// KidsSize returns the size system and canonical label of a children's size: "6-7Y" for "6-7 Jahre",
+// "18-24M" for "18-24 Monate" and "116-122cm" for "116/122 cm". hint is the system of the column or row
+// the label is in, it lets labels without unit count, e.g. "6-7" in an "Alter" column. Parenthesized
+// details such as "6-7 Jahre (116-122 cm)" are ignored.
+func KidsSize(label, hint string) (system, canonical string, ok bool) {
+	label, _, _ = strings.Cut(label, "(")
+	m := kidsSizePattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(label)))
+	if m == nil {
+		return "", "", false
+	}
+	low, _ := strconv.Atoi(m[1])
+	high := low
+	if m[2] != "" {
+		high, _ = strconv.Atoi(m[2])
+	}
+
+	unit, maxValue, minValue := "", 0, 0
+	switch {
+	case yearUnits[m[3]] || (m[3] == "" && hint == SystemAge):
+		system, unit, minValue, maxValue = SystemAge, "Y", 1, 16
+	case monthUnits[m[3]]:
+		system, unit, minValue, maxValue = SystemAge, "M", 0, 48
+	case m[3] == "cm" || (m[3] == "" && hint == SystemHeight):
+		system, unit, minValue, maxValue = SystemHeight, "cm", 44, 188
+	default:
+		return "", "", false
+	}
+	if low < minValue || high > maxValue || high < low {
+		return "", "", false
+	}
+
+	canonical = strconv.Itoa(low)
+	if high != low {
+		canonical += "-" + strconv.Itoa(high)
+	}
+	return system, canonical + unit, true
+}
@@ -0,0 +1,217 @@
+package labels
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Canonical measurement keys used in size tables
+const (
+	Length   = "length"
+	Chest    = "chest"
+	Waist    = "waist"
+	Hip      = "hip"
+	Sleeve   = "sleeve"
+	Shoulder = "shoulder"
+	Width    = "width"
+	Height   = "height"
+)
+
+// Supported locales
+const (
+	LocaleDE = "de"
+	LocaleEN = "en"
+	LocaleFR = "fr"
+	LocaleIT = "it"
+	LocaleES = "es"
+)
+
+// Terms maps canonical keys to the label terms that identify them
+type Terms map[string][]string
+
+// builtin contains the default label terms per locale
+var builtin = map[string]Terms{
+	LocaleDE: {
+		Length:   {"länge", "laenge", "gesamtlänge", "rückenlänge"},
+		Chest:    {"brustumfang", "brustweite", "brust"},
+		Waist:    {"taillenumfang", "taille", "bundweite"},
+		Hip:      {"hüftumfang", "hüfte", "huefte"},
+		Sleeve:   {"ärmellänge", "ärmel", "armlänge", "aermel"},
+		Shoulder: {"schulterbreite", "schulter"},
+		Width:    {"breite"},
+		Height:   {"höhe"},
+	},
+	LocaleEN: {
+		Length:   {"length", "body length", "back length"},
+		Chest:    {"chest", "bust"},
+		Waist:    {"waist"},
+		Hip:      {"hip", "hips"},
+		Sleeve:   {"sleeve", "sleeve length", "arm length"},
+		Shoulder: {"shoulder", "shoulder width"},
+		Width:    {"width"},
+		Height:   {"height"},
+	},
+	LocaleFR: {
+		Length:   {"longueur", "longueur totale", "longueur dos"},
+		Chest:    {"tour de poitrine", "poitrine"},
+		Waist:    {"tour de taille"},
+		Hip:      {"tour de hanches", "hanches", "hanche"},
+		Sleeve:   {"manche", "longueur de manche", "longueur des manches"},
+		Shoulder: {"épaule", "largeur d'épaules", "largeur des épaules"},
+		Width:    {"largeur"},
+		Height:   {"hauteur"},
+	},
+	LocaleIT: {
+		Length:   {"lunghezza", "lunghezza totale"},
+		Chest:    {"circonferenza torace", "torace", "petto"},
+		Waist:    {"circonferenza vita", "girovita", "vita"},
+		Hip:      {"circonferenza fianchi", "fianchi"},
+		Sleeve:   {"manica", "maniche", "lunghezza manica", "lunghezza maniche"},
+		Shoulder: {"spalle", "spalla", "larghezza spalle"},
+		Width:    {"larghezza"},
+		Height:   {"altezza"},
+	},
+	LocaleES: {
+		Length:   {"largo", "longitud", "largo total"},
+		Chest:    {"contorno de pecho", "pecho", "busto"},
+		Waist:    {"contorno de cintura", "cintura"},
+		Hip:      {"contorno de cadera", "cadera", "caderas"},
+		Sleeve:   {"manga", "mangas", "largo de manga"},
+		Shoulder: {"hombro", "hombros", "ancho de hombros"},
+		Width:    {"ancho"},
+		Height:   {"altura", "alto"},
+	},
+}
+
+// marketplaces maps Amazon marketplace domains to their locale
+var marketplaces = map[string]string{
+	"amazon.de":     LocaleDE,
+	"amazon.at":     LocaleDE,
+	"amazon.com":    LocaleEN,
+	"amazon.co.uk":  LocaleEN,
+	"amazon.ca":     LocaleEN,
+	"amazon.com.au": LocaleEN,
+	"amazon.fr":     LocaleFR,
+	"amazon.it":     LocaleIT,
+	"amazon.es":     LocaleES,
+}
+
+type entry struct {
+	term string
+	key  string
+}
+
+// Dictionary maps localized measurement labels to canonical keys
+type Dictionary struct {
+	locale  string
+	entries []entry
+}
+
+// New creates a dictionary for the locale, with English terms as fallback
+func New(locale string) *Dictionary {
+	return NewWithTerms(locale, nil)
+}
+
+// NewWithTerms creates a dictionary for the locale and adds extra terms on top of the built-in ones
+func NewWithTerms(locale string, extra Terms) *Dictionary {
+	locale = strings.ToLower(locale)
+	if _, ok := builtin[locale]; !ok {
+		locale = LocaleDE
+	}
+
+	d := &Dictionary{locale: locale}
+	d.add(builtin[locale])
+	if locale != LocaleEN {
+		// English headers show up on every marketplace
+		d.add(builtin[LocaleEN])
+	}
+	d.add(extra)
+
+	// Longest terms first so "ärmellänge" wins over "länge"
+	sort.Slice(d.entries, func(i, j int) bool {
+		if len(d.entries[i].term) != len(d.entries[j].term) {
+			return len(d.entries[i].term) > len(d.entries[j].term)
+		}
+		return d.entries[i].term < d.entries[j].term
+	})
+
+	return d
+}
+
+// Load creates a dictionary for the marketplace, merging terms from an optional JSON file
+func Load(marketplace, path string) (*Dictionary, error) {
+	locale := LocaleForMarketplace(marketplace)
+	if path == "" {
+		return New(locale), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read label file: %w", err)
+	}
+
+	// File layout: {"fr": {"length": ["longueur dos"]}}
+	var custom map[string]Terms
+	if err := json.Unmarshal(data, &custom); err != nil {
+		return nil, fmt.Errorf("failed to parse label file: %w", err)
+	}
+
+	return NewWithTerms(locale, custom[locale]), nil
+}
+
+// LocaleForMarketplace returns the locale of an Amazon marketplace domain or URL, defaulting to German
+func LocaleForMarketplace(marketplace string) string {
+	host := strings.ToLower(strings.TrimSpace(marketplace))
+	if u, err := url.Parse(host); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	host = strings.TrimPrefix(host, "www.")
+
+	if locale, ok := marketplaces[host]; ok {
+		return locale
+	}
+	return LocaleDE
+}
+
+// Locale returns the dictionary locale
+func (d *Dictionary) Locale() string {
+	return d.locale
+}
+
+// Lookup returns the canonical key for a label
+func (d *Dictionary) Lookup(label string) (string, bool) {
+	label = strings.ToLower(strings.TrimSpace(label))
+	if label == "" {
+		return "", false
+	}
+
+	for _, e := range d.entries {
+		if strings.Contains(label, e.term) {
+			return e.key, true
+		}
+	}
+	return "", false
+}
+
+// Normalize returns the canonical key for a label, or the lowercased label if unknown
+func (d *Dictionary) Normalize(label string) string {
+	if key, ok := d.Lookup(label); ok {
+		return key
+	}
+	return strings.ToLower(strings.TrimSpace(label))
+}
+
+func (d *Dictionary) add(terms Terms) {
+	for key, list := range terms {
+		for _, term := range list {
+			term = strings.ToLower(strings.TrimSpace(term))
+			if term != "" {
+				d.entries = append(d.entries, entry{term: term, key: key})
+			}
+		}
+	}
+}
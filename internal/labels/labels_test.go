@@ -0,0 +1,170 @@
+package labels
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLookupPerLocale(t *testing.T) {
+	tests := []struct {
+		locale string
+		label  string
+		want   string
+	}{
+		{LocaleDE, "Brustumfang (cm)", Chest},
+		{LocaleDE, "Länge", Length},
+		{LocaleDE, "Ärmellänge", Sleeve},
+		{LocaleDE, "Schulterbreite", Shoulder},
+		{LocaleEN, "Sleeve Length", Sleeve},
+		{LocaleEN, "Body Length (in)", Length},
+		{LocaleEN, "Bust", Chest},
+		{LocaleFR, "Tour de poitrine", Chest},
+		{LocaleFR, "Longueur des manches", Sleeve},
+		{LocaleFR, "Tour de taille", Waist},
+		{LocaleFR, "Longueur", Length},
+		{LocaleIT, "Circonferenza torace", Chest},
+		{LocaleIT, "Lunghezza manica", Sleeve},
+		{LocaleIT, "Fianchi", Hip},
+		{LocaleES, "Largo de manga", Sleeve},
+		{LocaleES, "Largo", Length},
+		{LocaleES, "Ancho de hombros", Shoulder},
+		{LocaleES, "Contorno de cintura", Waist},
+		// English fallback on non-English marketplaces
+		{LocaleFR, "Chest", Chest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale+"/"+tt.label, func(t *testing.T) {
+			got, ok := New(tt.locale).Lookup(tt.label)
+			if !ok || got != tt.want {
+				t.Errorf("Lookup(%q) = %q, %v; want %q", tt.label, got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestFrenchTailleIsNotWaist(t *testing.T) {
+	// "Taille" is the size column on amazon.fr, not the waist
+	if key, ok := New(LocaleFR).Lookup("Taille"); ok {
+		t.Errorf("Expected no mapping for Taille, got %q", key)
+	}
+	if key, _ := New(LocaleDE).Lookup("Taille"); key != Waist {
+		t.Errorf("Expected German Taille to map to waist, got %q", key)
+	}
+}
+
+func TestNormalizeUnknownLabel(t *testing.T) {
+	if got := New(LocaleDE).Normalize(" Gewicht "); got != "gewicht" {
+		t.Errorf("Normalize() = %q, want gewicht", got)
+	}
+}
+
+func TestLocaleForMarketplace(t *testing.T) {
+	tests := map[string]string{
+		"amazon.de":                   LocaleDE,
+		"www.amazon.fr":               LocaleFR,
+		"https://www.amazon.it/dp/B0": LocaleIT,
+		"amazon.es":                   LocaleES,
+		"amazon.co.uk":                LocaleEN,
+		"unknown":                     LocaleDE,
+	}
+
+	for marketplace, want := range tests {
+		if got := LocaleForMarketplace(marketplace); got != want {
+			t.Errorf("LocaleForMarketplace(%q) = %q, want %q", marketplace, got, want)
+		}
+	}
+}
+
+func TestLoadMergesFileTerms(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "labels.json")
+	if err := os.WriteFile(path, []byte(`{"fr": {"length": ["hauteur dos"]}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := Load("amazon.fr", path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if d.Locale() != LocaleFR {
+		t.Errorf("Locale() = %q, want fr", d.Locale())
+	}
+	if key, _ := d.Lookup("Hauteur dos"); key != Length {
+		t.Errorf("Expected custom term to map to length, got %q", key)
+	}
+
+	if _, err := Load("amazon.fr", filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected error for missing label file")
+	}
+}
+
+func TestSizeSystem(t *testing.T) {
+	tests := map[string]string{
+		"EU":                SystemEU,
+		"EU-Größe":          SystemEU,
+		"Size (US)":         SystemUS,
+		"Deutsche Größe":    SystemDE,
+		"Internationale":    SystemINT,
+		"Größe":             "",
+		"Länge (cm)":        "",
+		"Contorno de pecho": "",
+		"US / UK":           "",
+	}
+
+	for label, want := range tests {
+		got, ok := SizeSystem(label)
+		if got != want || ok != (want != "") {
+			t.Errorf("SizeSystem(%q) = %q, %v; want %q", label, got, ok, want)
+		}
+	}
+}
+
+func TestKidsSize(t *testing.T) {
+	tests := []struct {
+		label, hint  string
+		system, want string
+	}{
+		{"6-7 Jahre", "", SystemAge, "6-7Y"},
+		{"6–7 J.", "", SystemAge, "6-7Y"},
+		{"8 ans", "", SystemAge, "8Y"},
+		{"10-11 years", "", SystemAge, "10-11Y"},
+		{"18-24 Monate", "", SystemAge, "18-24M"},
+		{"116/122 cm", "", SystemHeight, "116-122cm"},
+		{"128 cm", "", SystemHeight, "128cm"},
+		{"6-7 Jahre (116-122 cm)", "", SystemAge, "6-7Y"},
+		{"6-7", SystemAge, SystemAge, "6-7Y"},
+		{"116-122", SystemHeight, SystemHeight, "116-122cm"},
+		{"116-122", "", "", ""},
+		{"48", "", "", ""},
+		{"XL", SystemAge, "", ""},
+		{"40 Jahre", "", "", ""},
+		{"250 cm", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		system, got, ok := KidsSize(tt.label, tt.hint)
+		if system != tt.system || got != tt.want || ok != (tt.want != "") {
+			t.Errorf("KidsSize(%q, %q) = %q, %q, %v; want %q, %q", tt.label, tt.hint, system, got, ok, tt.system, tt.want)
+		}
+	}
+}
+
+func TestKidsSizeColumn(t *testing.T) {
+	tests := map[string]string{
+		"Alter":            SystemAge,
+		"Age (years)":      SystemAge,
+		"Körpergröße (cm)": SystemHeight,
+		"Body Height":      SystemHeight,
+		"Height":           "",
+		"Brustumfang":      "",
+		"Größe":            "",
+	}
+
+	for label, want := range tests {
+		got, ok := KidsSizeColumn(label)
+		if got != want || ok != (want != "") {
+			t.Errorf("KidsSizeColumn(%q) = %q, %v; want %q", label, got, ok, want)
+		}
+	}
+}
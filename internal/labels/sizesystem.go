@@ -0,0 +1,63 @@
+package labels
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Size systems of international size conversion rows and columns
+const (
+	SystemEU  = "EU"
+	SystemDE  = "DE"
+	SystemFR  = "FR"
+	SystemIT  = "IT"
+	SystemUK  = "UK"
+	SystemUS  = "US"
+	SystemINT = "INT" // International letter sizes
+	SystemJP  = "JP"
+	SystemCN  = "CN"
+	SystemMX  = "MX"
+	SystemAU  = "AU"
+	SystemES  = "ES"
+)
+
+// systemTerms maps label tokens to their size system
+var systemTerms = map[string]string{
+	"eu": SystemEU, "eur": SystemEU, "europa": SystemEU, "europe": SystemEU, "europäisch": SystemEU,
+	"de": SystemDE, "deutsch": SystemDE, "deutsche": SystemDE, "deutschland": SystemDE, "germany": SystemDE, "german": SystemDE,
+	"fr": SystemFR, "france": SystemFR, "français": SystemFR,
+	"it": SystemIT, "ita": SystemIT, "italia": SystemIT, "italy": SystemIT,
+	"uk": SystemUK, "gb": SystemUK,
+	"us": SystemUS, "usa": SystemUS,
+	"int": SystemINT, "intl": SystemINT, "international": SystemINT, "internationale": SystemINT,
+	"jp": SystemJP, "japan": SystemJP,
+	"cn": SystemCN, "china": SystemCN, "asia": SystemCN, "asien": SystemCN,
+	"mx": SystemMX, "mex": SystemMX,
+	"au": SystemAU, "aus": SystemAU,
+	"es": SystemES, "españa": SystemES,
+}
+
+// sizeWords may surround the system in a label, e.g. "EU-Größe" or "Size (US)"
+var sizeWords = map[string]bool{
+	"größe": true, "groesse": true, "größen": true, "size": true, "sizes": true, "taille": true,
+	"taglia": true, "talla": true, "konfektionsgröße": true, "herstellergröße": true,
+}
+
+var labelTokens = regexp.MustCompile(`[\p{L}]+`)
+
+// SizeSystem returns the size system a size table label names, e.g. "US" for "US-Größe". Labels of
+// measurements or with words besides the system and size words are no size system.
+func SizeSystem(label string) (string, bool) {
+	system := ""
+	for _, token := range labelTokens.FindAllString(strings.ToLower(label), -1) {
+		if sizeWords[token] {
+			continue
+		}
+		s, ok := systemTerms[token]
+		if !ok || (system != "" && system != s) {
+			return "", false
+		}
+		system = s
+	}
+	return system, system != ""
+}
@@ -0,0 +1,236 @@
+// Package lifecycle defines the statuses of a product and the transitions between them: which
+// trigger moves a product from which status to which, the guard that must pass and the events a
+// transition emits. Illegal transitions are rejected with ErrIllegalTransition.
+package lifecycle
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/maltedev/amazon-size-scraper/internal/schema"
+)
+
+// State is the status column of a product
+type State string
+
+const (
+	Pending    State = "pending"
+	Processing State = "processing" // Claimed by a size-scraper worker
+	Completed  State = "completed"
+	Failed     State = "failed"
+	Retired    State = "retired"  // Listing deleted on Amazon
+	Active     State = "active"   // Size chart with a length found by the lifecycle consumer
+	Rejected   State = "rejected" // No usable size chart
+
+	// Statuses of the products written by the search jobs and the product extractor
+	Discovered State = "PENDING"
+	Scraped    State = "SCRAPED"
+)
+
+// Triggers of the default transitions
+const (
+	Claim            = "claim"
+	Release          = "release"
+	Complete         = "complete"
+	Fail             = "fail"
+	Retry            = "retry"
+	Retire           = "retire"
+	SizeChartFound   = "size_chart_found"
+	SizeChartMissing = "size_chart_missing"
+	Scrape           = "scrape"
+)
+
+// Guards of the default transitions
+const (
+	GuardAccepted  = "measurements_accepted"
+	GuardRejected  = "measurements_rejected"
+	GuardHasLength = "has_length"
+	GuardNoLength  = "no_length"
+	GuardHasReason = "has_reason"
+)
+
+// ErrIllegalTransition is returned for a trigger that is not allowed from the current status
+var ErrIllegalTransition = errors.New("illegal status transition")
+
+// Facts describe the outcome a trigger is fired with, guards decide on them
+type Facts struct {
+	HasLength bool   // The size table has a length measurement
+	Accepted  bool   // The size table has the measurements the policy requires, see database.MeasurementPolicy
+	Reason    string // Why the product failed, was rejected or retired
+}
+
+// Guard returns an error if a transition must not be taken for facts
+type Guard func(facts Facts) error
+
+// Transition moves a product from one of From to To when Trigger fires and Guard passes
+type Transition struct {
+	Trigger string   `json:"trigger"`
+	From    []State  `json:"from"`
+	To      State    `json:"to"`
+	Guard   string   `json:"guard,omitempty"` // Name of a registered guard
+	Emits   []string `json:"emits,omitempty"` // Event types published after the transition
+}
+
+// Load reads a JSON array of transitions from a file
+func Load(path string) ([]Transition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transitions: %w", err)
+	}
+	var transitions []Transition
+	if err := json.Unmarshal(data, &transitions); err != nil {
+		return nil, fmt.Errorf("failed to parse transitions: %w", err)
+	}
+	return transitions, nil
+}
+
+// DefaultTransitions returns the transitions the scrapers and the lifecycle consumer rely on
+func DefaultTransitions() []Transition {
+	return []Transition{
+		{Trigger: Claim, From: []State{Pending}, To: Processing},
+		{Trigger: Release, From: []State{Processing}, To: Pending},
+		{Trigger: Complete, From: []State{Pending, Processing}, To: Completed},
+		{Trigger: Fail, From: []State{Pending, Processing, Failed}, To: Failed, Guard: GuardHasReason},
+		{Trigger: Retry, From: []State{Failed, Rejected}, To: Pending},
+		{Trigger: Retire, From: []State{Pending, Processing, Completed, Failed, Active, Rejected, Discovered, Scraped}, To: Retired,
+			Guard: GuardHasReason, Emits: []string{schema.EventProductRetired}},
+		{Trigger: SizeChartFound, From: []State{Pending}, To: Active, Guard: GuardAccepted, Emits: []string{schema.EventProductCreated}},
+		{Trigger: SizeChartMissing, From: []State{Pending}, To: Rejected, Guard: GuardRejected},
+		{Trigger: Scrape, From: []State{Discovered, Scraped}, To: Scraped},
+	}
+}
+
+// Machine validates and resolves transitions, safe for concurrent use once guards are registered
+type Machine struct {
+	transitions []Transition
+	guards      map[string]Guard
+}
+
+// New checks transitions for missing fields and ambiguous triggers and registers the default guards
+func New(transitions []Transition) (*Machine, error) {
+	m := &Machine{guards: make(map[string]Guard)}
+	seen := make(map[string]bool)
+	for _, t := range transitions {
+		if t.Trigger == "" || t.To == "" || len(t.From) == 0 {
+			return nil, fmt.Errorf("transition needs a trigger, a source and a target status: %+v", t)
+		}
+		for _, from := range t.From {
+			key := t.Trigger + "\x00" + string(from)
+			if seen[key] {
+				return nil, fmt.Errorf("duplicate transition %s from %s", t.Trigger, from)
+			}
+			seen[key] = true
+		}
+		m.transitions = append(m.transitions, t)
+	}
+
+	m.Register(GuardAccepted, func(f Facts) error {
+		if !f.Accepted {
+			return errors.New("size table lacks required measurements")
+		}
+		return nil
+	})
+	m.Register(GuardRejected, func(f Facts) error {
+		if f.Accepted {
+			return errors.New("size table has the required measurements")
+		}
+		return nil
+	})
+	m.Register(GuardHasLength, func(f Facts) error {
+		if !f.HasLength {
+			return errors.New("size table has no length")
+		}
+		return nil
+	})
+	m.Register(GuardNoLength, func(f Facts) error {
+		if f.HasLength {
+			return errors.New("size table has a length")
+		}
+		return nil
+	})
+	m.Register(GuardHasReason, func(f Facts) error {
+		if f.Reason == "" {
+			return errors.New("reason is required")
+		}
+		return nil
+	})
+	return m, nil
+}
+
+// Default returns a machine with the default transitions
+func Default() *Machine {
+	m, err := New(DefaultTransitions())
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Register makes a guard available to transitions under name
+func (m *Machine) Register(name string, g Guard) {
+	m.guards[name] = g
+}
+
+// Validate checks that every transition refers to a registered guard and, if known is set, emits
+// known event types
+func (m *Machine) Validate(known func(eventType string) bool) error {
+	for _, t := range m.transitions {
+		if _, ok := m.guards[t.Guard]; t.Guard != "" && !ok {
+			return fmt.Errorf("transition %s: unknown guard %s", t.Trigger, t.Guard)
+		}
+		for _, eventType := range t.Emits {
+			if known != nil && !known(eventType) {
+				return fmt.Errorf("transition %s: unknown event type %s", t.Trigger, eventType)
+			}
+		}
+	}
+	return nil
+}
+
+// Can reports whether trigger may fire from status, without checking its guard
+func (m *Machine) Can(from State, trigger string) bool {
+	_, ok := m.find(from, trigger)
+	return ok
+}
+
+// Fire returns the transition trigger takes from status. It fails with ErrIllegalTransition if the
+// trigger is not allowed from status or its guard rejects facts.
+func (m *Machine) Fire(from State, trigger string, facts Facts) (Transition, error) {
+	t, ok := m.find(from, trigger)
+	if !ok {
+		return Transition{}, fmt.Errorf("%w: %s from %q", ErrIllegalTransition, trigger, from)
+	}
+	if t.Guard != "" {
+		g, ok := m.guards[t.Guard]
+		if !ok {
+			return Transition{}, fmt.Errorf("transition %s: unknown guard %s", t.Trigger, t.Guard)
+		}
+		if err := g(facts); err != nil {
+			return Transition{}, fmt.Errorf("%w: %s from %q: %v", ErrIllegalTransition, trigger, from, err)
+		}
+	}
+	return t, nil
+}
+
+// Sources returns the statuses trigger may fire from
+func (m *Machine) Sources(trigger string) []State {
+	var sources []State
+	for _, t := range m.transitions {
+		if t.Trigger == trigger {
+			sources = append(sources, t.From...)
+		}
+	}
+	return sources
+}
+
+func (m *Machine) find(from State, trigger string) (Transition, bool) {
+	for _, t := range m.transitions {
+		if t.Trigger == trigger && slices.Contains(t.From, from) {
+			return t, true
+		}
+	}
+	return Transition{}, false
+}
@@ -0,0 +1,109 @@
+package lifecycle
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/maltedev/amazon-size-scraper/internal/schema"
+)
+
+func TestMachine_Fire(t *testing.T) {
+	m := Default()
+	if err := m.Validate(schema.NewRegistry().Known); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+
+	tests := []struct {
+		from    State
+		trigger string
+		facts   Facts
+		want    State
+		wantErr bool
+	}{
+		{Pending, SizeChartFound, Facts{Accepted: true}, Active, false},
+		{Pending, SizeChartFound, Facts{}, "", true},                // Guard
+		{Pending, SizeChartFound, Facts{HasLength: true}, "", true}, // A length alone does not satisfy the policy
+		{Pending, SizeChartMissing, Facts{Accepted: true}, "", true},
+		{Pending, SizeChartMissing, Facts{}, Rejected, false},
+		{Active, SizeChartMissing, Facts{}, "", true},
+		{Processing, Fail, Facts{Reason: "timeout"}, Failed, false},
+		{Processing, Fail, Facts{}, "", true},
+		{Completed, Claim, Facts{}, "", true},
+		{Retired, Retry, Facts{}, "", true},
+		{Scraped, Retire, Facts{Reason: "HTTP 404"}, Retired, false},
+		{Discovered, Scrape, Facts{}, Scraped, false},
+	}
+	for _, tt := range tests {
+		got, err := m.Fire(tt.from, tt.trigger, tt.facts)
+		if tt.wantErr {
+			if !errors.Is(err, ErrIllegalTransition) {
+				t.Errorf("Fire(%s, %s) error = %v, want ErrIllegalTransition", tt.from, tt.trigger, err)
+			}
+			continue
+		}
+		if err != nil || got.To != tt.want {
+			t.Errorf("Fire(%s, %s) = %s, %v, want %s", tt.from, tt.trigger, got.To, err, tt.want)
+		}
+	}
+}
+
+func TestNew_RejectsAmbiguousTransitions(t *testing.T) {
+	_, err := New([]Transition{
+		{Trigger: "approve", From: []State{Pending}, To: Active},
+		{Trigger: "approve", From: []State{Failed, Pending}, To: Completed},
+	})
+	if err == nil {
+		t.Error("expected an error for two approve transitions from pending")
+	}
+	if _, err := New([]Transition{{Trigger: "approve", To: Active}}); err == nil {
+		t.Error("expected an error for a transition without source")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transitions.json")
+	data := `[{"trigger": "approve", "from": ["pending"], "to": "active", "guard": "manual", "emits": ["PRODUCT_CREATED"]}]`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	transitions, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+	m, err := New(transitions)
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+	if err := m.Validate(nil); err == nil {
+		t.Error("expected an error for the unregistered guard")
+	}
+	m.Register("manual", func(Facts) error { return nil })
+	if err := m.Validate(schema.NewRegistry().Known); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+	if !m.Can(Pending, "approve") || m.Can(Active, "approve") {
+		t.Error("Can() does not follow the loaded transitions")
+	}
+}
+
+func TestLengthGuards(t *testing.T) {
+	// Transition files written before the measurement policy keep deciding on the length
+	m, err := New([]Transition{
+		{Trigger: SizeChartFound, From: []State{Pending}, To: Active, Guard: GuardHasLength},
+		{Trigger: SizeChartMissing, From: []State{Pending}, To: Rejected, Guard: GuardNoLength},
+	})
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+	if _, err := m.Fire(Pending, SizeChartFound, Facts{HasLength: true}); err != nil {
+		t.Errorf("Fire(size_chart_found) with a length = %v", err)
+	}
+	if _, err := m.Fire(Pending, SizeChartFound, Facts{Accepted: true}); !errors.Is(err, ErrIllegalTransition) {
+		t.Errorf("Fire(size_chart_found) without a length = %v, want ErrIllegalTransition", err)
+	}
+	if _, err := m.Fire(Pending, SizeChartMissing, Facts{}); err != nil {
+		t.Errorf("Fire(size_chart_missing) without a length = %v", err)
+	}
+}
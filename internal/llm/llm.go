@@ -0,0 +1,138 @@
+// Package llm is a minimal client for chat completion APIs compatible with OpenAI's, used by optional
+// enrichment stages. Stages depend on the Client interface so tests and other providers can replace it.
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrEmptyResponse is returned when the API answered without a completion
+var ErrEmptyResponse = errors.New("llm returned no completion")
+
+// Message roles
+const (
+	RoleSystem = "system"
+	RoleUser   = "user"
+)
+
+// Message is one chat message of a prompt
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Client completes a chat prompt, JSON mode asks for a single JSON object as answer
+type Client interface {
+	Complete(ctx context.Context, messages []Message, jsonMode bool) (string, error)
+	Model() string
+}
+
+// Config configures the HTTP client
+type Config struct {
+	BaseURL string // API root, e.g. https://api.openai.com/v1 or http://localhost:11434/v1
+	APIKey  string // Sent as bearer token, empty for local servers
+	Model   string
+	Timeout time.Duration
+}
+
+// HTTPClient calls the /chat/completions endpoint of an OpenAI-compatible API
+type HTTPClient struct {
+	cfg  Config
+	http *http.Client
+}
+
+// NewHTTPClient creates a client, nil when no base URL is configured
+func NewHTTPClient(cfg Config) *HTTPClient {
+	if cfg.BaseURL == "" {
+		return nil
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 60 * time.Second
+	}
+	return &HTTPClient{cfg: cfg, http: &http.Client{Timeout: cfg.Timeout}}
+}
+
+// Model returns the model completions are requested from
+func (c *HTTPClient) Model() string {
+	return c.cfg.Model
+}
+
+type chatRequest struct {
+	Model          string          `json:"model"`
+	Messages       []Message       `json:"messages"`
+	Temperature    float64         `json:"temperature"`
+	ResponseFormat *responseFormat `json:"response_format,omitempty"`
+}
+
+type responseFormat struct {
+	Type string `json:"type"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message Message `json:"message"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// Complete sends the prompt at temperature 0 and returns the content of the first choice
+func (c *HTTPClient) Complete(ctx context.Context, messages []Message, jsonMode bool) (string, error) {
+	body := chatRequest{Model: c.cfg.Model, Messages: messages}
+	if jsonMode {
+		body.ResponseFormat = &responseFormat{Type: "json_object"}
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal completion request: %w", err)
+	}
+
+	url := strings.TrimRight(c.cfg.BaseURL, "/") + "/chat/completions"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("failed to create completion request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.APIKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("completion request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read completion: %w", err)
+	}
+
+	var out chatResponse
+	if err := json.Unmarshal(data, &out); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("completion request failed with status %d", resp.StatusCode)
+		}
+		return "", fmt.Errorf("failed to decode completion: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if out.Error != nil {
+			return "", fmt.Errorf("completion request failed with status %d: %s", resp.StatusCode, out.Error.Message)
+		}
+		return "", fmt.Errorf("completion request failed with status %d", resp.StatusCode)
+	}
+	if len(out.Choices) == 0 || out.Choices[0].Message.Content == "" {
+		return "", ErrEmptyResponse
+	}
+
+	return out.Choices[0].Message.Content, nil
+}
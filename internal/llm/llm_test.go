@@ -0,0 +1,67 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPClientComplete(t *testing.T) {
+	var got chatRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer secret" {
+			t.Errorf("Authorization = %q", auth)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "{\"ok\": true}"}}]}`))
+	}))
+	defer srv.Close()
+
+	c := NewHTTPClient(Config{BaseURL: srv.URL + "/v1/", APIKey: "secret", Model: "small"})
+	content, err := c.Complete(context.Background(), []Message{{Role: RoleUser, Content: "hi"}}, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content != `{"ok": true}` {
+		t.Errorf("content = %q", content)
+	}
+	if got.Model != "small" || len(got.Messages) != 1 || got.ResponseFormat == nil || got.ResponseFormat.Type != "json_object" {
+		t.Errorf("unexpected request: %+v", got)
+	}
+}
+
+func TestHTTPClientErrors(t *testing.T) {
+	tests := map[string]struct {
+		status int
+		body   string
+		want   string
+	}{
+		"api error":     {http.StatusUnauthorized, `{"error": {"message": "invalid api key"}}`, "invalid api key"},
+		"gateway error": {http.StatusBadGateway, `<html>bad gateway</html>`, "status 502"},
+		"no choices":    {http.StatusOK, `{"choices": []}`, ErrEmptyResponse.Error()},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			_, err := NewHTTPClient(Config{BaseURL: srv.URL}).Complete(context.Background(), nil, false)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	if NewHTTPClient(Config{}) != nil {
+		t.Error("a client without base URL should be nil")
+	}
+}
@@ -8,6 +8,7 @@ type Product struct {
 	ID          string              `json:"id"`
 	ASIN        string              `json:"asin"`
 	URL         string              `json:"url"`
+	Marketplace string              `json:"marketplace,omitempty"`
 	Title       string              `json:"title"`
 	Brand       string              `json:"brand"`
 	Category    string              `json:"category"`
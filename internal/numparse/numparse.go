@@ -0,0 +1,149 @@
+// Package numparse reads numbers as Amazon pages print them, e.g. "1.234,56" on amazon.de and
+// "1,234.56" on amazon.com, and resolves ranges such as "84 - 94 cm" to a single value.
+package numparse
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Locale selects which separator is the decimal separator when a number has only one of them
+type Locale string
+
+const (
+	DE   Locale = "de-DE" // Decimal comma, "1.234,5"
+	EN   Locale = "en-US" // Decimal point, "1,234.5"
+	Auto Locale = ""      // A separator followed by exactly three digits groups thousands, otherwise it is decimal
+)
+
+// RangePolicy selects the value of a range such as "84 - 94"
+type RangePolicy string
+
+const (
+	RangeMin RangePolicy = "min"
+	RangeMax RangePolicy = "max"
+	RangeAvg RangePolicy = "avg"
+)
+
+// ParseRangePolicy parses "min", "max" or "avg", empty is RangeMax
+func ParseRangePolicy(s string) (RangePolicy, error) {
+	switch p := RangePolicy(strings.ToLower(strings.TrimSpace(s))); p {
+	case "":
+		return RangeMax, nil
+	case RangeMin, RangeMax, RangeAvg:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown range policy %q, want min, max or avg", s)
+	}
+}
+
+// number matches digits with thousand and decimal separators, a space only groups thousands when
+// three digits follow it, e.g. "1 299,00"
+const number = `\d+(?:[.,]\d+|[ \x{00a0}\x{202f}]\d{3}\b)*`
+
+var (
+	numberPattern = regexp.MustCompile(number)
+	rangePattern  = regexp.MustCompile(`(` + number + `)\s*(?:-|–|—|bis|to)\s*(` + number + `)`)
+)
+
+// Normalize converts a number with locale separators into the form strconv.ParseFloat reads, e.g.
+// "1.234,56" into "1234.56". With both separators present the last one is the decimal separator,
+// a separator repeated more than once always groups thousands.
+func Normalize(s string, loc Locale) string {
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\u00a0', '\u202f':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(s))
+	s = strings.TrimRight(s, ".,")
+
+	last := strings.LastIndexAny(s, ".,")
+	if last == -1 {
+		return s
+	}
+
+	sep := s[last]
+	integer, fraction := s[:last], s[last+1:]
+	other := byte(',')
+	if sep == ',' {
+		other = '.'
+	}
+
+	decimal := true
+	switch {
+	case strings.IndexByte(integer, other) >= 0:
+		// "1.234,56" or "1,234.56", the last separator is decimal
+	case strings.IndexByte(integer, sep) >= 0:
+		// "1.234.567" repeats its separator, it only groups thousands
+		decimal = false
+	case len(fraction) != 3 || integer == "0":
+		// "42,5", "0.125"
+	case loc == DE:
+		decimal = sep == ','
+	case loc == EN:
+		decimal = sep == '.'
+	default:
+		// "1.234" or "1,234" only has thousand separators
+		decimal = false
+	}
+
+	integer = strings.NewReplacer(".", "", ",", "").Replace(integer)
+	if !decimal {
+		return integer + fraction
+	}
+	return integer + "." + fraction
+}
+
+// Float returns the first number in text, ok is false if there is none
+func Float(text string, loc Locale) (value float64, ok bool) {
+	match := numberPattern.FindString(text)
+	if match == "" {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(Normalize(match, loc), 64)
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
+
+// Range returns the bounds of the first range in text, e.g. "84 - 94", "84–94" or "84 bis 94"
+func Range(text string, loc Locale) (lo, hi float64, ok bool) {
+	m := rangePattern.FindStringSubmatch(text)
+	if m == nil {
+		return 0, 0, false
+	}
+	lo, okLo := Float(m[1], loc)
+	hi, okHi := Float(m[2], loc)
+	if !okLo || !okHi {
+		return 0, 0, false
+	}
+	return lo, hi, true
+}
+
+// Parser reads single values from texts that may hold a range
+type Parser struct {
+	Locale Locale
+	Range  RangePolicy // Empty is RangeMax
+}
+
+// Value returns the number in text, a range is resolved by the parser's policy. It returns 0 when
+// the text holds no number.
+func (p Parser) Value(text string) float64 {
+	if lo, hi, ok := Range(text, p.Locale); ok {
+		switch p.Range {
+		case RangeMin:
+			return min(lo, hi)
+		case RangeAvg:
+			return (lo + hi) / 2
+		default:
+			return max(lo, hi)
+		}
+	}
+	value, _ := Float(text, p.Locale)
+	return value
+}
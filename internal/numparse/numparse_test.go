@@ -0,0 +1,117 @@
+package numparse
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in   string
+		loc  Locale
+		want string
+	}{
+		{"42", DE, "42"},
+		{"42,5", DE, "42.5"},
+		{"42.5", DE, "42.5"},
+		{"1.234", DE, "1234"},
+		{"1,234", DE, "1.234"},
+		{"1.234,56", DE, "1234.56"},
+		{"1.234.567", DE, "1234567"},
+		{"1 299,00", DE, "1299.00"},
+		{"1\u00a0299,00", DE, "1299.00"},
+		{"1,234", EN, "1234"},
+		{"1.234", EN, "1.234"},
+		{"1,234.56", EN, "1234.56"},
+		{"42,5", EN, "42.5"},
+		{"1.234", Auto, "1234"},
+		{"1,234", Auto, "1234"},
+		{"0,125", Auto, "0.125"},
+		{"4,5", Auto, "4.5"},
+		{"19,", Auto, "19"},
+	}
+
+	for _, tt := range tests {
+		if got := Normalize(tt.in, tt.loc); got != tt.want {
+			t.Errorf("Normalize(%q, %q) = %q, want %q", tt.in, tt.loc, got, tt.want)
+		}
+	}
+}
+
+func TestFloat(t *testing.T) {
+	tests := []struct {
+		in     string
+		loc    Locale
+		want   float64
+		wantOK bool
+	}{
+		{"ca. 72 cm", DE, 72, true},
+		{"4,5 von 5 Sternen", Auto, 4.5, true},
+		{"4.5 out of 5 stars", Auto, 4.5, true},
+		{"1.234 Bewertungen", DE, 1234, true},
+		{"1.299,00 €", DE, 1299, true},
+		{"£1,234.56", EN, 1234.56, true},
+		{"abc", DE, 0, false},
+		{"", DE, 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := Float(tt.in, tt.loc)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("Float(%q, %q) = %v %v, want %v %v", tt.in, tt.loc, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestRange(t *testing.T) {
+	tests := []struct {
+		in     string
+		lo, hi float64
+		ok     bool
+	}{
+		{"84 - 94", 84, 94, true},
+		{"84-94 cm", 84, 94, true},
+		{"84–94", 84, 94, true},
+		{"84,5 bis 94,5 cm", 84.5, 94.5, true},
+		{"32 to 34", 32, 34, true},
+		{"84 cm", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		lo, hi, ok := Range(tt.in, DE)
+		if lo != tt.lo || hi != tt.hi || ok != tt.ok {
+			t.Errorf("Range(%q) = %v %v %v, want %v %v %v", tt.in, lo, hi, ok, tt.lo, tt.hi, tt.ok)
+		}
+	}
+}
+
+func TestParser_Value(t *testing.T) {
+	tests := []struct {
+		policy RangePolicy
+		in     string
+		want   float64
+	}{
+		{RangeMax, "84 - 94 cm", 94},
+		{"", "84 - 94 cm", 94},
+		{RangeMin, "84 - 94 cm", 84},
+		{RangeAvg, "84 - 94 cm", 89},
+		{RangeAvg, "42,5", 42.5},
+		{RangeMax, "XL", 0},
+	}
+
+	for _, tt := range tests {
+		p := Parser{Locale: DE, Range: tt.policy}
+		if got := p.Value(tt.in); got != tt.want {
+			t.Errorf("Parser{Range: %q}.Value(%q) = %v, want %v", tt.policy, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseRangePolicy(t *testing.T) {
+	for in, want := range map[string]RangePolicy{"": RangeMax, "MIN": RangeMin, " avg ": RangeAvg, "max": RangeMax} {
+		got, err := ParseRangePolicy(in)
+		if err != nil || got != want {
+			t.Errorf("ParseRangePolicy(%q) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseRangePolicy("median"); err == nil {
+		t.Error("ParseRangePolicy(\"median\") error = nil, want error")
+	}
+}
@@ -0,0 +1,143 @@
+// Package ocr recognizes text in size chart images so charts shipped only as
+// pictures can be parsed like HTML tables.
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// Engine recognizes text in an image
+type Engine interface {
+	Recognize(ctx context.Context, image []byte) (string, error)
+}
+
+// Supported engine names
+const (
+	EngineTesseract = "tesseract"
+)
+
+// New returns the engine with the given name, or nil if name is empty
+func New(name, languages string) (Engine, error) {
+	switch name {
+	case "":
+		return nil, nil
+	case EngineTesseract:
+		t, err := NewTesseract(languages)
+		if err != nil {
+			return nil, err
+		}
+		return t, nil
+	default:
+		return nil, fmt.Errorf("unsupported ocr engine: %s", name)
+	}
+}
+
+// Tesseract runs the tesseract CLI on images
+type Tesseract struct {
+	binary    string
+	languages string
+}
+
+// NewTesseract locates the tesseract binary on PATH
+func NewTesseract(languages string) (*Tesseract, error) {
+	binary, err := exec.LookPath("tesseract")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find tesseract binary: %w", err)
+	}
+	if languages == "" {
+		languages = "deu+eng"
+	}
+	return &Tesseract{binary: binary, languages: languages}, nil
+}
+
+// Recognize pipes the image through tesseract, keeping column gaps as runs of spaces
+func (t *Tesseract) Recognize(ctx context.Context, image []byte) (string, error) {
+	cmd := exec.CommandContext(ctx, t.binary, "stdin", "stdout",
+		"-l", t.languages,
+		"--psm", "6",
+		"-c", "preserve_interword_spaces=1",
+	)
+	cmd.Stdin = bytes.NewReader(image)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("failed to run tesseract: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+var (
+	columnGap = regexp.MustCompile(`\t+| {2,}`)
+	numeric   = regexp.MustCompile(`^\d+([.,]\d+)?$`)
+)
+
+// sizeTokens are cells that stand alone even when OCR collapsed the column gaps
+var sizeTokens = map[string]bool{
+	"XS": true, "S": true, "M": true, "L": true, "XL": true, "XXL": true,
+	"XXXL": true, "2XL": true, "3XL": true, "4XL": true, "5XL": true, "6XL": true,
+}
+
+// ParseTable splits recognized text into a header row and data rows
+func ParseTable(text string) ([]string, [][]string) {
+	var table [][]string
+	for _, line := range strings.Split(text, "\n") {
+		if cells := splitCells(line); len(cells) >= 2 {
+			table = append(table, cells)
+		}
+	}
+
+	if len(table) < 2 {
+		return nil, nil
+	}
+	return table[0], table[1:]
+}
+
+// splitCells uses column gaps when present, otherwise merges consecutive label words into one cell
+func splitCells(line string) []string {
+	line = strings.TrimSpace(strings.NewReplacer("|", " ", "–", "-").Replace(line))
+	if line == "" {
+		return nil
+	}
+
+	if columnGap.MatchString(line) {
+		var cells []string
+		for _, cell := range columnGap.Split(line, -1) {
+			if cell = strings.TrimSpace(cell); cell != "" {
+				cells = append(cells, cell)
+			}
+		}
+		return cells
+	}
+
+	var cells []string
+	label := false
+	for _, token := range strings.Fields(line) {
+		switch {
+		case token == "-" && len(cells) > 0 && !label:
+			// Ranges like "84 - 94" stay in one cell
+			cells[len(cells)-1] += " -"
+		case len(cells) > 0 && strings.HasSuffix(cells[len(cells)-1], " -"):
+			cells[len(cells)-1] += " " + token
+		case numeric.MatchString(token) || strings.Contains(token, "-") && numeric.MatchString(strings.Split(token, "-")[0]):
+			cells = append(cells, token)
+			label = false
+		case sizeTokens[strings.ToUpper(token)]:
+			cells = append(cells, token)
+			label = false
+		case label:
+			cells[len(cells)-1] += " " + token
+		default:
+			cells = append(cells, token)
+			label = true
+		}
+	}
+	return cells
+}
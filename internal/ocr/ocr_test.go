@@ -0,0 +1,64 @@
+package ocr
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTable(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		wantHeaders []string
+		wantRows    [][]string
+	}{
+		{
+			name:        "column gaps preserved",
+			text:        "Größentabelle\n\nGröße    Brustumfang (cm)    Länge (cm)\nM    100    79\nL    106    81\n",
+			wantHeaders: []string{"Größe", "Brustumfang (cm)", "Länge (cm)"},
+			wantRows:    [][]string{{"M", "100", "79"}, {"L", "106", "81"}},
+		},
+		{
+			name:        "collapsed gaps with sizes in header",
+			text:        "Größe S M L\nLänge (cm) 76 78 80\nBrustumfang (cm) 96 - 100 102 108\n",
+			wantHeaders: []string{"Größe", "S", "M", "L"},
+			wantRows: [][]string{
+				{"Länge (cm)", "76", "78", "80"},
+				{"Brustumfang (cm)", "96 - 100", "102", "108"},
+			},
+		},
+		{
+			name:        "table separators and en dash",
+			text:        "| Größe | M | L |\n| Länge | 70–72 | 74 |",
+			wantHeaders: []string{"Größe", "M", "L"},
+			wantRows:    [][]string{{"Länge", "70-72", "74"}},
+		},
+		{
+			name: "no table",
+			text: "100% Baumwolle\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers, rows := ParseTable(tt.text)
+			if !reflect.DeepEqual(headers, tt.wantHeaders) {
+				t.Errorf("headers = %q, want %q", headers, tt.wantHeaders)
+			}
+			if !reflect.DeepEqual(rows, tt.wantRows) {
+				t.Errorf("rows = %q, want %q", rows, tt.wantRows)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	engine, err := New("", "")
+	if err != nil || engine != nil {
+		t.Errorf("New(\"\") = %v, %v; want disabled", engine, err)
+	}
+
+	if _, err := New("unknown", ""); err == nil {
+		t.Error("expected error for unsupported engine")
+	}
+}
@@ -0,0 +1,272 @@
+// Package paapi is a client for the GetItems operation of the Amazon Product Advertising API 5.0. It serves
+// basic product data (title, brand, price, images) when scraping the product page is blocked; size charts
+// are not available through the API.
+package paapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/ratelimit"
+)
+
+var (
+	// ErrUnsupportedMarketplace is returned for marketplaces without a PA-API endpoint
+	ErrUnsupportedMarketplace = errors.New("marketplace not supported by PA-API")
+	// ErrItemNotFound is returned when the API does not return the requested item
+	ErrItemNotFound = errors.New("item not found in PA-API")
+)
+
+// MaxItemsPerRequest is the number of ASINs GetItems accepts at once
+const MaxItemsPerRequest = 10
+
+const (
+	service = "ProductAdvertisingAPI"
+	target  = "com.amazon.paapi5.v1.ProductAdvertisingAPIv1.GetItems"
+	path    = "/paapi5/getitems"
+)
+
+// endpoint is the PA-API host and signing region of a marketplace
+type endpoint struct {
+	host   string
+	region string
+}
+
+var endpoints = map[string]endpoint{
+	"amazon.de":     {"webservices.amazon.de", "eu-west-1"},
+	"amazon.co.uk":  {"webservices.amazon.co.uk", "eu-west-1"},
+	"amazon.fr":     {"webservices.amazon.fr", "eu-west-1"},
+	"amazon.it":     {"webservices.amazon.it", "eu-west-1"},
+	"amazon.es":     {"webservices.amazon.es", "eu-west-1"},
+	"amazon.nl":     {"webservices.amazon.nl", "eu-west-1"},
+	"amazon.se":     {"webservices.amazon.se", "eu-west-1"},
+	"amazon.pl":     {"webservices.amazon.pl", "eu-west-1"},
+	"amazon.com":    {"webservices.amazon.com", "us-east-1"},
+	"amazon.ca":     {"webservices.amazon.ca", "us-east-1"},
+	"amazon.com.mx": {"webservices.amazon.com.mx", "us-east-1"},
+	"amazon.co.jp":  {"webservices.amazon.co.jp", "us-west-2"},
+	"amazon.com.au": {"webservices.amazon.com.au", "us-west-2"},
+}
+
+// resources are the item fields requested from GetItems
+var resources = []string{
+	"ItemInfo.Title",
+	"ItemInfo.ByLineInfo",
+	"Offers.Listings.Price",
+	"Images.Primary.Large",
+	"Images.Variants.Large",
+}
+
+// Config configures the client with the credentials of an Associates account
+type Config struct {
+	AccessKey  string
+	SecretKey  string
+	PartnerTag string        // Associates tracking ID of the marketplace, e.g. mytag-21
+	Timeout    time.Duration // Per request, 10 seconds by default
+	Interval   time.Duration // Minimum time between requests, 1 second by default (the initial PA-API quota)
+	BaseURL    string        // Replaces the marketplace host, for tests
+}
+
+// Item is the basic product data of an ASIN
+type Item struct {
+	ASIN          string   `json:"asin"`
+	Title         string   `json:"title,omitempty"`
+	Brand         string   `json:"brand,omitempty"`
+	DetailPageURL string   `json:"detail_page_url,omitempty"`
+	Price         *float64 `json:"price,omitempty"`
+	Currency      string   `json:"currency,omitempty"`
+	Images        []string `json:"images,omitempty"` // Primary image first
+}
+
+// Client requests items from the PA-API of a marketplace
+type Client struct {
+	cfg     Config
+	http    *http.Client
+	limiter *ratelimit.SimpleRateLimiter
+	now     func() time.Time
+}
+
+// NewClient creates a client, nil when no credentials are configured
+func NewClient(cfg Config) *Client {
+	if cfg.AccessKey == "" || cfg.SecretKey == "" || cfg.PartnerTag == "" {
+		return nil
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	return &Client{
+		cfg:     cfg,
+		http:    &http.Client{Timeout: cfg.Timeout},
+		limiter: ratelimit.NewSimpleRateLimiter(cfg.Interval, cfg.Interval),
+		now:     time.Now,
+	}
+}
+
+// Supports reports whether the marketplace has a PA-API endpoint
+func Supports(marketplace string) bool {
+	_, ok := endpoints[marketplace]
+	return ok
+}
+
+type getItemsRequest struct {
+	ItemIDs     []string `json:"ItemIds"`
+	ItemIDType  string   `json:"ItemIdType"`
+	PartnerTag  string   `json:"PartnerTag"`
+	PartnerType string   `json:"PartnerType"`
+	Marketplace string   `json:"Marketplace"`
+	Resources   []string `json:"Resources"`
+}
+
+type displayValue struct {
+	DisplayValue string `json:"DisplayValue"`
+}
+
+type image struct {
+	Large struct {
+		URL string `json:"URL"`
+	} `json:"Large"`
+}
+
+type apiError struct {
+	Code    string `json:"Code"`
+	Message string `json:"Message"`
+}
+
+type getItemsResponse struct {
+	ItemsResult struct {
+		Items []struct {
+			ASIN          string `json:"ASIN"`
+			DetailPageURL string `json:"DetailPageURL"`
+			ItemInfo      struct {
+				Title      displayValue `json:"Title"`
+				ByLineInfo struct {
+					Brand displayValue `json:"Brand"`
+				} `json:"ByLineInfo"`
+			} `json:"ItemInfo"`
+			Offers struct {
+				Listings []struct {
+					Price struct {
+						Amount   float64 `json:"Amount"`
+						Currency string  `json:"Currency"`
+					} `json:"Price"`
+				} `json:"Listings"`
+			} `json:"Offers"`
+			Images struct {
+				Primary  image   `json:"Primary"`
+				Variants []image `json:"Variants"`
+			} `json:"Images"`
+		} `json:"Items"`
+	} `json:"ItemsResult"`
+	Errors []apiError `json:"Errors"`
+}
+
+// GetItem returns the item of one ASIN, ErrItemNotFound when the API has none
+func (c *Client) GetItem(ctx context.Context, marketplace, asin string) (*Item, error) {
+	items, err := c.GetItems(ctx, marketplace, []string{asin})
+	if err != nil {
+		return nil, err
+	}
+	item, ok := items[asin]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrItemNotFound, asin)
+	}
+	return item, nil
+}
+
+// GetItems returns the items of up to MaxItemsPerRequest ASINs by ASIN, ASINs the API does not return
+// (unknown or not accessible to the partner tag) are missing from the result
+func (c *Client) GetItems(ctx context.Context, marketplace string, asins []string) (map[string]*Item, error) {
+	ep, ok := endpoints[marketplace]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedMarketplace, marketplace)
+	}
+	if len(asins) == 0 || len(asins) > MaxItemsPerRequest {
+		return nil, fmt.Errorf("GetItems takes 1 to %d ASINs, got %d", MaxItemsPerRequest, len(asins))
+	}
+
+	payload, err := json.Marshal(getItemsRequest{
+		ItemIDs:     asins,
+		ItemIDType:  "ASIN",
+		PartnerTag:  c.cfg.PartnerTag,
+		PartnerType: "Associates",
+		Marketplace: "www." + marketplace,
+		Resources:   resources,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	url := "https://" + ep.host + path
+	if c.cfg.BaseURL != "" {
+		url = strings.TrimRight(c.cfg.BaseURL, "/") + path
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Host = ep.host
+	req.Header.Set("Content-Encoding", "amz-1.0")
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("X-Amz-Target", target)
+
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	sign(req, payload, c.cfg.AccessKey, c.cfg.SecretKey, ep.region, c.now().UTC())
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("PA-API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PA-API response: %w", err)
+	}
+
+	var out getItemsResponse
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("failed to decode PA-API response (status %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if len(out.Errors) > 0 {
+			return nil, fmt.Errorf("PA-API request failed with status %d: %s: %s", resp.StatusCode, out.Errors[0].Code, out.Errors[0].Message)
+		}
+		return nil, fmt.Errorf("PA-API request failed with status %d", resp.StatusCode)
+	}
+
+	items := make(map[string]*Item, len(out.ItemsResult.Items))
+	for _, raw := range out.ItemsResult.Items {
+		item := &Item{
+			ASIN:          raw.ASIN,
+			Title:         strings.TrimSpace(raw.ItemInfo.Title.DisplayValue),
+			Brand:         strings.TrimSpace(raw.ItemInfo.ByLineInfo.Brand.DisplayValue),
+			DetailPageURL: raw.DetailPageURL,
+		}
+		if listings := raw.Offers.Listings; len(listings) > 0 && listings[0].Price.Amount > 0 {
+			amount := listings[0].Price.Amount
+			item.Price = &amount
+			item.Currency = listings[0].Price.Currency
+		}
+		if u := raw.Images.Primary.Large.URL; u != "" {
+			item.Images = append(item.Images, u)
+		}
+		for _, v := range raw.Images.Variants {
+			if v.Large.URL != "" {
+				item.Images = append(item.Images, v.Large.URL)
+			}
+		}
+		items[item.ASIN] = item
+	}
+	return items, nil
+}
@@ -0,0 +1,120 @@
+package paapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const itemsResponse = `{
+  "ItemsResult": {
+    "Items": [{
+      "ASIN": "B08N5WRWNW",
+      "DetailPageURL": "https://www.amazon.de/dp/B08N5WRWNW?tag=mytag-21",
+      "ItemInfo": {
+        "Title": {"DisplayValue": " Herren T-Shirt Extra Lang "},
+        "ByLineInfo": {"Brand": {"DisplayValue": "Tall Co"}}
+      },
+      "Offers": {"Listings": [{"Price": {"Amount": 24.99, "Currency": "EUR"}}]},
+      "Images": {
+        "Primary": {"Large": {"URL": "https://m.media-amazon.com/images/I/main.jpg"}},
+        "Variants": [{"Large": {"URL": "https://m.media-amazon.com/images/I/back.jpg"}}]
+      }
+    }]
+  },
+  "Errors": [{"Code": "ItemNotAccessible", "Message": "The ItemId B000000000 is not accessible through the Product Advertising API."}]
+}`
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	c := NewClient(Config{AccessKey: "AKID", SecretKey: "secret", PartnerTag: "mytag-21", Interval: time.Millisecond, BaseURL: srv.URL})
+	c.now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) }
+	return c
+}
+
+func TestGetItems(t *testing.T) {
+	var got getItemsRequest
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/paapi5/getitems" || r.Host != "webservices.amazon.de" {
+			t.Errorf("request to %s%s", r.Host, r.URL.Path)
+		}
+		if r.Header.Get("X-Amz-Target") != target || r.Header.Get("X-Amz-Date") != "20240301T120000Z" {
+			t.Errorf("unexpected headers: %v", r.Header)
+		}
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/20240301/eu-west-1/ProductAdvertisingAPI/aws4_request, ") ||
+			!strings.Contains(auth, "SignedHeaders=content-encoding;content-type;host;x-amz-date;x-amz-target, Signature=") {
+			t.Errorf("Authorization = %q", auth)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(itemsResponse))
+	})
+
+	items, err := c.GetItems(context.Background(), "amazon.de", []string{"B08N5WRWNW", "B000000000"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.PartnerTag != "mytag-21" || got.Marketplace != "www.amazon.de" || len(got.ItemIDs) != 2 {
+		t.Errorf("unexpected request: %+v", got)
+	}
+
+	item := items["B08N5WRWNW"]
+	if item == nil || len(items) != 1 {
+		t.Fatalf("items = %v", items)
+	}
+	if item.Title != "Herren T-Shirt Extra Lang" || item.Brand != "Tall Co" || item.Currency != "EUR" || *item.Price != 24.99 {
+		t.Errorf("unexpected item: %+v", item)
+	}
+	if len(item.Images) != 2 || !strings.HasSuffix(item.Images[0], "main.jpg") {
+		t.Errorf("Images = %v", item.Images)
+	}
+
+	if _, err := c.GetItem(context.Background(), "amazon.de", "B000000000"); !errors.Is(err, ErrItemNotFound) {
+		t.Errorf("error = %v, want ErrItemNotFound", err)
+	}
+}
+
+func TestGetItemsErrors(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"Errors": [{"Code": "TooManyRequests", "Message": "The request was denied due to request throttling."}]}`))
+	})
+
+	if _, err := c.GetItems(context.Background(), "amazon.de", []string{"B08N5WRWNW"}); err == nil || !strings.Contains(err.Error(), "TooManyRequests") {
+		t.Errorf("error = %v, want the API error", err)
+	}
+	if _, err := c.GetItems(context.Background(), "amazon.com.br", []string{"B08N5WRWNW"}); !errors.Is(err, ErrUnsupportedMarketplace) {
+		t.Errorf("error = %v, want ErrUnsupportedMarketplace", err)
+	}
+	if _, err := c.GetItems(context.Background(), "amazon.de", make([]string, MaxItemsPerRequest+1)); err == nil {
+		t.Error("expected an error for too many ASINs")
+	}
+	if NewClient(Config{AccessKey: "AKID", SecretKey: "secret"}) != nil {
+		t.Error("a client without partner tag should be nil")
+	}
+}
+
+func TestSignIsDeterministic(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	signed := func(secret string) string {
+		req, _ := http.NewRequest(http.MethodPost, "https://webservices.amazon.de/paapi5/getitems", nil)
+		req.Header.Set("X-Amz-Target", target)
+		sign(req, []byte(`{}`), "AKID", secret, "eu-west-1", now)
+		return req.Header.Get("Authorization")
+	}
+
+	if signed("secret") != signed("secret") {
+		t.Error("signing the same request twice should give the same signature")
+	}
+	if signed("secret") == signed("other") {
+		t.Error("the signature should depend on the secret key")
+	}
+}
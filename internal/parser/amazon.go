@@ -1,15 +1,25 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"regexp"
-	"strconv"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/maltedev/amazon-size-scraper/internal/brand"
 	"github.com/maltedev/amazon-size-scraper/internal/models"
+	"github.com/maltedev/amazon-size-scraper/internal/numparse"
+	"github.com/maltedev/amazon-size-scraper/internal/scrapeerr"
 )
 
+// ErrDimensionsNotFound is returned when no product dimensions are listed on the page
+var ErrDimensionsNotFound = errors.New("dimensions not found")
+
+// robotCheck matches the captcha form Amazon serves instead of a product page
+const robotCheck = "#captchacharacters, form[action*='Captcha']"
+
 type AmazonParser struct {
 	dimensionPatterns []*regexp.Regexp
 	weightPatterns    []*regexp.Regexp
@@ -38,43 +48,63 @@ func NewAmazonParser() *AmazonParser {
 }
 
 func (p *AmazonParser) ParseProductPage(html string, asin string) (*models.Product, error) {
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	return p.ParseProductPageReader(strings.NewReader(html), asin)
+}
+
+// ParseProductPageReader parses a product page streamed from r, the HTML is parsed once and shared by
+// all extractors. A robot check page gives scrapeerr.ErrCaptcha.
+func (p *AmazonParser) ParseProductPageReader(r io.Reader, asin string) (*models.Product, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse HTML: %w", err)
 	}
+	if doc.Find(robotCheck).Length() > 0 {
+		return nil, scrapeerr.ErrCaptcha
+	}
+	return p.ParseDocument(doc, asin), nil
+}
 
+// ParseDocument extracts a product from an already parsed page
+func (p *AmazonParser) ParseDocument(doc *goquery.Document, asin string) *models.Product {
 	product := models.NewProduct(asin)
 
 	product.Title = p.extractTitle(doc)
 	product.Brand = p.extractBrand(doc)
 	product.Category = p.extractCategory(doc)
 
-	if material, err := p.ExtractMaterial(html); err == nil {
+	if material, err := p.extractMaterial(doc); err == nil {
 		product.Material = material
 	}
 
-	if dimensions, err := p.ExtractDimensions(html); err == nil {
+	if dimensions, err := p.extractDimensions(doc); err == nil {
 		product.Dimensions = *dimensions
 	}
 
-	if weight, err := p.ExtractWeight(html); err == nil {
+	if weight, err := p.extractWeight(doc); err == nil {
 		product.Weight = *weight
 	}
 
-	if price, err := p.ExtractPrice(html); err == nil {
+	if price, err := p.extractPrice(doc); err == nil {
 		product.Price = *price
 	}
 
 	product.Images = p.extractImages(doc)
 
-	return product, nil
+	return product
 }
 
 func (p *AmazonParser) ExtractDimensions(html string) (*models.Dimension, error) {
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	doc, err := newDocument(html)
 	if err != nil {
 		return nil, err
 	}
+	return p.extractDimensions(doc)
+}
+
+func (p *AmazonParser) extractDimensions(doc *goquery.Document) (*models.Dimension, error) {
+	if dim := p.extractProductInformation(doc).dimensions(); dim != nil {
+		return dim, nil
+	}
 	
 	productDetails := p.extractProductDetails(doc)
 	
@@ -113,14 +143,21 @@ func (p *AmazonParser) ExtractDimensions(html string) (*models.Dimension, error)
 		}
 	}
 	
-	return nil, fmt.Errorf("dimensions not found")
+	return nil, ErrDimensionsNotFound
 }
 
 func (p *AmazonParser) ExtractWeight(html string) (*models.Weight, error) {
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	doc, err := newDocument(html)
 	if err != nil {
 		return nil, err
 	}
+	return p.extractWeight(doc)
+}
+
+func (p *AmazonParser) extractWeight(doc *goquery.Document) (*models.Weight, error) {
+	if weight := p.extractProductInformation(doc).weight(); weight != nil {
+		return weight, nil
+	}
 	
 	productDetails := p.extractProductDetails(doc)
 	
@@ -142,11 +179,14 @@ func (p *AmazonParser) ExtractWeight(html string) (*models.Weight, error) {
 }
 
 func (p *AmazonParser) ExtractPrice(html string) (*models.Price, error) {
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	doc, err := newDocument(html)
 	if err != nil {
 		return nil, err
 	}
+	return p.extractPrice(doc)
+}
 
+func (p *AmazonParser) extractPrice(doc *goquery.Document) (*models.Price, error) {
 	priceSelectors := []string{
 		".a-price-whole",
 		"span.a-price.a-text-price.a-size-medium.apexPriceToPay",
@@ -170,11 +210,14 @@ func (p *AmazonParser) ExtractPrice(html string) (*models.Price, error) {
 }
 
 func (p *AmazonParser) ExtractMaterial(html string) (string, error) {
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	doc, err := newDocument(html)
 	if err != nil {
 		return "", err
 	}
+	return p.extractMaterial(doc)
+}
 
+func (p *AmazonParser) extractMaterial(doc *goquery.Document) (string, error) {
 	// First try structured extraction from the specific HTML pattern you provided
 	var foundMaterial string
 	doc.Find(".a-fixed-left-grid-inner").Each(func(i int, s *goquery.Selection) {
@@ -239,11 +282,15 @@ func (p *AmazonParser) ExtractMaterial(html string) (string, error) {
 
 // ExtractMaterialComposition extracts structured material data and fallback text
 func (p *AmazonParser) ExtractMaterialComposition(html string) (*models.MaterialComposition, string, error) {
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	doc, err := newDocument(html)
 	if err != nil {
 		return nil, "", err
 	}
+	return p.ExtractMaterialCompositionDocument(doc)
+}
 
+// ExtractMaterialCompositionDocument is ExtractMaterialComposition for an already parsed page
+func (p *AmazonParser) ExtractMaterialCompositionDocument(doc *goquery.Document) (*models.MaterialComposition, string, error) {
 	var fullTextParts []string
 	var materialSources []struct {
 		text   string
@@ -384,8 +431,7 @@ func (p *AmazonParser) parseMaterialComposition(text string) *models.MaterialCom
 
 	for _, match := range matches {
 		if len(match) >= 3 {
-			percentStr := strings.Replace(match[1], ",", ".", -1)
-			if percent, err := strconv.ParseFloat(percentStr, 64); err == nil {
+			if percent, ok := numparse.Float(match[1], numparse.DE); ok {
 				material := strings.TrimSpace(match[2])
 				if material != "" {
 					materials = append(materials, models.MaterialItem{
@@ -440,17 +486,16 @@ func (p *AmazonParser) collectAllMaterialText(doc *goquery.Document, textParts *
 	}
 }
 
+func newDocument(html string) (*goquery.Document, error) {
+	return goquery.NewDocumentFromReader(strings.NewReader(html))
+}
+
 func (p *AmazonParser) extractTitle(doc *goquery.Document) string {
 	return strings.TrimSpace(doc.Find("#productTitle").Text())
 }
 
 func (p *AmazonParser) extractBrand(doc *goquery.Document) string {
-	brand := doc.Find("#bylineInfo").Text()
-	brand = strings.TrimPrefix(brand, "Marke: ")
-	brand = strings.TrimPrefix(brand, "Brand: ")
-	brand = strings.TrimPrefix(brand, "Besuchen Sie den ")
-	brand = strings.TrimSuffix(brand, "-Store")
-	return strings.TrimSpace(brand)
+	return brand.Normalize(doc.Find("#bylineInfo").Text())
 }
 
 func (p *AmazonParser) extractCategory(doc *goquery.Document) string {
@@ -495,23 +540,15 @@ func (p *AmazonParser) extractProductDetails(doc *goquery.Document) string {
 }
 
 func (p *AmazonParser) parseFloat(s string) float64 {
-	s = strings.Replace(s, ",", ".", -1)
-	s = strings.TrimSpace(s)
-	val, _ := strconv.ParseFloat(s, 64)
+	val, _ := numparse.Float(s, numparse.DE)
 	return val
 }
 
 func (p *AmazonParser) parsePrice(s string) *models.Price {
-	re := regexp.MustCompile(`(\d+(?:[,.]\d+)?)`)
-	matches := re.FindStringSubmatch(s)
-	
-	if len(matches) > 1 {
-		amount := p.parseFloat(matches[1])
-		if amount > 0 {
-			return &models.Price{
-				Amount:   amount,
-				Currency: "EUR",
-			}
+	if amount := p.parseFloat(s); amount > 0 {
+		return &models.Price{
+			Amount:   amount,
+			Currency: "EUR",
 		}
 	}
 	
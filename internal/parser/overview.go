@@ -0,0 +1,123 @@
+package parser
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// productOverviewRows are the label/value rows of the "product overview" grid above the feature
+// bullets, e.g. "Material | 100% Baumwolle"
+const productOverviewRows = "#productOverview_feature_div tr, #poExpander tr"
+
+// attributeKeys maps the labels of the overview grid and of "Label: value" feature bullets to the
+// canonical attribute keys, so products of amazon.de and amazon.com are filtered the same way
+var attributeKeys = map[string]string{
+	"material":                "material",
+	"materialzusammensetzung": "material",
+	"material composition":    "material",
+	"material type":           "material",
+	"stoff":                   "material",
+	"fabric type":             "material",
+	"passform":                "fit",
+	"passformtyp":             "fit",
+	"fit type":                "fit",
+	"fit":                     "fit",
+	"ärmeltyp":                "sleeve_type",
+	"ärmelart":                "sleeve_type",
+	"sleeve type":             "sleeve_type",
+	"kragenstil":              "collar_style",
+	"kragenform":              "collar_style",
+	"collar style":            "collar_style",
+	"ausschnitt":              "neckline",
+	"halsausschnitt":          "neckline",
+	"ausschnittform":          "neckline",
+	"neck style":              "neckline",
+	"neckline":                "neckline",
+	"verschluss":              "closure",
+	"verschlusstyp":           "closure",
+	"closure type":            "closure",
+	"pflegehinweise":          "care_instructions",
+	"pflegeanleitung":         "care_instructions",
+	"care instructions":       "care_instructions",
+	"muster":                  "pattern",
+	"pattern":                 "pattern",
+	"farbe":                   "color",
+	"color":                   "color",
+	"colour":                  "color",
+	"stil":                    "style",
+	"style":                   "style",
+	"herkunftsland":           "country_of_origin",
+	"country of origin":       "country_of_origin",
+}
+
+// ProductOverview returns the attributes of the product overview grid by canonical key, see
+// AttributeKey. Nil if the page has no grid.
+func ProductOverview(doc *goquery.Document) map[string]string {
+	var attrs map[string]string
+	doc.Find(productOverviewRows).Each(func(i int, s *goquery.Selection) {
+		cells := s.Find("td")
+		if cells.Length() < 2 {
+			return
+		}
+		label := cleanDetailText(cells.Eq(0).Text())
+		value := cleanDetailText(cells.Eq(1).Text())
+		if label == "" || value == "" {
+			return
+		}
+		if attrs == nil {
+			attrs = make(map[string]string)
+		}
+		key := AttributeKey(label)
+		if _, ok := attrs[key]; !ok {
+			attrs[key] = value
+		}
+	})
+	return attrs
+}
+
+// ProductOverviewHTML parses the HTML of the overview grid or of a whole page, see ProductOverview
+func ProductOverviewHTML(html string) (map[string]string, error) {
+	doc, err := newDocument(html)
+	if err != nil {
+		return nil, err
+	}
+	return ProductOverview(doc), nil
+}
+
+// AttributeKey returns the canonical key of an attribute label, e.g. "fit" for "Passform" and
+// "Fit Type". Unknown labels are lower cased with underscores, "Anlass" becomes "anlass".
+func AttributeKey(label string) string {
+	label = strings.ToLower(cleanDetailText(label))
+	if key, ok := attributeKeys[label]; ok {
+		return key
+	}
+	return strings.Join(strings.FieldsFunc(label, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), "_")
+}
+
+// MergeFeatureAttributes adds the attributes of "Label: value" feature bullets, e.g. "Material: 100%
+// Baumwolle", to attrs. Only labels with a canonical key count, bullets like "PERFEKTE PASSFORM: ..."
+// are marketing, and the overview grid wins over bullets.
+func MergeFeatureAttributes(attrs map[string]string, features []string) map[string]string {
+	for _, feature := range features {
+		label, value, ok := strings.Cut(feature, ":")
+		if !ok {
+			continue
+		}
+		key, known := attributeKeys[strings.ToLower(cleanDetailText(label))]
+		value = cleanDetailText(value)
+		if !known || value == "" {
+			continue
+		}
+		if attrs == nil {
+			attrs = make(map[string]string)
+		}
+		if _, ok := attrs[key]; !ok {
+			attrs[key] = value
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const productOverviewHTML = `<div id="productOverview_feature_div"><table class="a-normal a-spacing-micro">
+	<tr class="a-spacing-small po-material"><td class="a-span3"><span class="a-text-bold">Material</span></td><td class="a-span9"><span class="po-break-word">100% Baumwolle</span></td></tr>
+	<tr class="a-spacing-small po-fit_type"><td><span class="a-text-bold">Passform</span></td><td><span>Regular Fit</span></td></tr>
+	<tr class="a-spacing-small"><td><span class="a-text-bold">Ärmeltyp</span></td><td><span>Kurzarm</span></td></tr>
+	<tr class="a-spacing-small"><td><span class="a-text-bold">Kragenstil</span></td><td><span>Rundhals</span></td></tr>
+	<tr class="a-spacing-small"><td><span class="a-text-bold">Anlass</span></td><td><span>Freizeit</span></td></tr>
+	<tr class="a-spacing-small"><td><span class="a-text-bold">Leer</span></td><td></td></tr>
+</table></div>`
+
+func TestProductOverviewHTML(t *testing.T) {
+	attrs, err := ProductOverviewHTML(productOverviewHTML)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"material":     "100% Baumwolle",
+		"fit":          "Regular Fit",
+		"sleeve_type":  "Kurzarm",
+		"collar_style": "Rundhals",
+		"anlass":       "Freizeit",
+	}, attrs)
+
+	none, err := ProductOverviewHTML(`<div id="feature-bullets"></div>`)
+	require.NoError(t, err)
+	assert.Nil(t, none)
+}
+
+func TestAttributeKey(t *testing.T) {
+	for label, want := range map[string]string{
+		"Passform":          "fit",
+		"Fit Type":          "fit",
+		" Sleeve Type : ":   "sleeve_type",
+		"Material type":     "material",
+		"Country of Origin": "country_of_origin",
+		"Stil des Kragens":  "stil_des_kragens",
+	} {
+		assert.Equal(t, want, AttributeKey(label), label)
+	}
+}
+
+func TestMergeFeatureAttributes(t *testing.T) {
+	attrs := MergeFeatureAttributes(map[string]string{"material": "100% Baumwolle"}, []string{
+		"Material: 95% Baumwolle, 5% Elasthan",
+		"Pflegehinweise: Maschinenwäsche 40 °C",
+		"PERFEKTE PASSFORM: extra lang für große Männer",
+		"Weich und atmungsaktiv",
+	})
+	assert.Equal(t, map[string]string{
+		"material":          "100% Baumwolle",
+		"care_instructions": "Maschinenwäsche 40 °C",
+	}, attrs)
+
+	assert.Nil(t, MergeFeatureAttributes(nil, []string{"Ohne Doppelpunkt"}))
+}
@@ -0,0 +1,179 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/maltedev/amazon-size-scraper/internal/models"
+)
+
+var (
+	// Dimensions of a "Produktinformationen" cell, e.g. "25,4 x 20,2 x 3,8 cm; 210 g"
+	cellDimensionPattern = regexp.MustCompile(`(?i)(\d+(?:[,.]\d+)?)\s*[x×]\s*(\d+(?:[,.]\d+)?)\s*[x×]\s*(\d+(?:[,.]\d+)?)\s*(cm|mm|m|zoll|inch|")`)
+	// Weight of a cell, either alone or after the dimensions
+	cellWeightPattern = regexp.MustCompile(`(?i)(?:^|;)\s*(\d+(?:[,.]\d+)?)\s*(kilogramm|gramm|kg|g|mg|pounds?|lb|oz)\b`)
+)
+
+// detailKind is what a product information row describes
+type detailKind int
+
+const (
+	detailOther   detailKind = iota
+	detailItem               // Produktabmessungen, Artikelgewicht
+	detailPackage            // Verpackungsabmessungen, Verpackungsgewicht
+)
+
+// detailRow is a label/value row of the product information
+type detailRow struct {
+	kind  detailKind
+	label string
+	value string
+}
+
+// productInformation is the measurements of the product information rows, item and package
+// measurements are kept apart
+type productInformation struct {
+	item, pkg             *models.Dimension
+	itemWeight, pkgWeight *models.Weight
+}
+
+// extractDetailRows reads the rows of the detail bullet list and the technical details tables
+func (p *AmazonParser) extractDetailRows(doc *goquery.Document) []detailRow {
+	var rows []detailRow
+	add := func(label, value string) {
+		label, value = cleanDetailText(label), cleanDetailText(value)
+		if label == "" || value == "" {
+			return
+		}
+		rows = append(rows, detailRow{kind: classifyDetail(label), label: label, value: value})
+	}
+
+	doc.Find("#detailBullets_feature_div li, .detail-bullet-list li").Each(func(i int, s *goquery.Selection) {
+		label := s.Find(".a-text-bold").First()
+		value := strings.TrimPrefix(s.Text(), label.Text())
+		add(label.Text(), value)
+	})
+
+	doc.Find("#productDetails_techSpec_section_1 tr, #productDetails_detailBullets_sections1 tr, .prodDetTable tr").Each(func(i int, s *goquery.Selection) {
+		add(s.Find("th").First().Text(), s.Find("td").First().Text())
+	})
+
+	return rows
+}
+
+// cleanDetailText drops the direction marks, colon and whitespace Amazon pads labels with
+func cleanDetailText(s string) string {
+	s = strings.NewReplacer("\u200e", "", "\u200f", "", "\u00a0", " ").Replace(s)
+	s = strings.Join(strings.Fields(s), " ")
+	return strings.TrimSpace(strings.TrimSuffix(s, ":"))
+}
+
+func classifyDetail(label string) detailKind {
+	label = strings.ToLower(label)
+	switch {
+	case strings.Contains(label, "verpackung"), strings.Contains(label, "package"):
+		return detailPackage
+	case strings.Contains(label, "abmessungen"), strings.Contains(label, "gewicht"),
+		strings.Contains(label, "dimensions"), strings.Contains(label, "weight"):
+		return detailItem
+	}
+	return detailOther
+}
+
+// extractProductInformation collects the item and package measurements of the detail rows. A
+// combined cell like "25,4 x 20,2 x 3,8 cm; 210 g" yields both dimensions and weight.
+func (p *AmazonParser) extractProductInformation(doc *goquery.Document) productInformation {
+	var info productInformation
+
+	for _, row := range p.extractDetailRows(doc) {
+		if row.kind == detailOther {
+			continue
+		}
+		dim := p.parseDimensionCell(row.value)
+		weight := p.parseWeightCell(row.value)
+
+		if row.kind == detailPackage {
+			if info.pkg == nil {
+				info.pkg = dim
+			}
+			if info.pkgWeight == nil {
+				info.pkgWeight = weight
+			}
+			continue
+		}
+		if info.item == nil {
+			info.item = dim
+		}
+		if info.itemWeight == nil {
+			info.itemWeight = weight
+		}
+	}
+
+	return info
+}
+
+func (p *AmazonParser) parseDimensionCell(value string) *models.Dimension {
+	m := cellDimensionPattern.FindStringSubmatch(value)
+	if m == nil {
+		return nil
+	}
+	dim := &models.Dimension{
+		Length: p.parseFloat(m[1]),
+		Width:  p.parseFloat(m[2]),
+		Height: p.parseFloat(m[3]),
+		Unit:   p.normalizeUnit(m[4]),
+	}
+	if !dim.IsValid() {
+		return nil
+	}
+	return dim
+}
+
+func (p *AmazonParser) parseWeightCell(value string) *models.Weight {
+	m := cellWeightPattern.FindStringSubmatch(value)
+	if m == nil {
+		return nil
+	}
+	weight := &models.Weight{Value: p.parseFloat(m[1]), Unit: p.normalizeWeightUnit(m[2])}
+	if !weight.IsValid() {
+		return nil
+	}
+	return weight
+}
+
+// dimensions merges item and package dimensions. Length, Width and Height hold the item dimensions,
+// or the package dimensions when the page lists only those.
+func (info productInformation) dimensions() *models.Dimension {
+	if info.item == nil && info.pkg == nil {
+		return nil
+	}
+
+	dim := &models.Dimension{}
+	if info.pkg != nil {
+		*dim = *info.pkg
+		dim.PackageL, dim.PackageW, dim.PackageH = info.pkg.Length, info.pkg.Width, info.pkg.Height
+		dim.PackageUnit = info.pkg.Unit
+	}
+	if info.item != nil {
+		dim.Length, dim.Width, dim.Height, dim.Unit = info.item.Length, info.item.Width, info.item.Height, info.item.Unit
+	}
+	return dim
+}
+
+// weight merges item and package weight like dimensions
+func (info productInformation) weight() *models.Weight {
+	if info.itemWeight == nil && info.pkgWeight == nil {
+		return nil
+	}
+
+	weight := &models.Weight{}
+	if info.pkgWeight != nil {
+		*weight = *info.pkgWeight
+		weight.PackageWeight, weight.PackageUnit = info.pkgWeight.Value, info.pkgWeight.Unit
+	}
+	if info.itemWeight != nil {
+		weight.Value, weight.Unit = info.itemWeight.Value, info.itemWeight.Unit
+	}
+	return weight
+}
@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const detailBulletsHTML = `<div id="detailBullets_feature_div"><ul class="a-unordered-list detail-bullet-list">
+	<li><span class="a-list-item"><span class="a-text-bold">Verpackungsabmessungen &rlm; : &lrm;</span> <span>25,4 x 20,2 x 3,8 cm; 210 g</span></span></li>
+	<li><span class="a-list-item"><span class="a-text-bold">Produktabmessungen &rlm; : &lrm;</span> <span>78 x 56 x 1 cm; 180 Gramm</span></span></li>
+	<li><span class="a-list-item"><span class="a-text-bold">ASIN &rlm; : &lrm;</span> <span>B0TEST0001</span></span></li>
+</ul></div>`
+
+func TestExtractDimensions_CombinedCells(t *testing.T) {
+	parser := NewAmazonParser()
+
+	dim, err := parser.ExtractDimensions(detailBulletsHTML)
+	require.NoError(t, err)
+	assert.Equal(t, 78.0, dim.Length)
+	assert.Equal(t, 56.0, dim.Width)
+	assert.Equal(t, 1.0, dim.Height)
+	assert.Equal(t, "cm", dim.Unit)
+	assert.Equal(t, 25.4, dim.PackageL)
+	assert.Equal(t, 20.2, dim.PackageW)
+	assert.Equal(t, 3.8, dim.PackageH)
+	assert.Equal(t, "cm", dim.PackageUnit)
+
+	weight, err := parser.ExtractWeight(detailBulletsHTML)
+	require.NoError(t, err)
+	assert.Equal(t, 180.0, weight.Value)
+	assert.Equal(t, "g", weight.Unit)
+	assert.Equal(t, 210.0, weight.PackageWeight)
+	assert.Equal(t, "g", weight.PackageUnit)
+}
+
+func TestExtractDimensions_PackageOnly(t *testing.T) {
+	parser := NewAmazonParser()
+	html := `<table id="productDetails_techSpec_section_1">
+		<tr><th>Verpackungsabmessungen</th><td>30 × 25 × 4,5 cm; 0,35 kg</td></tr>
+		<tr><th>Artikelgewicht</th><td>300 g</td></tr>
+	</table>`
+
+	dim, err := parser.ExtractDimensions(html)
+	require.NoError(t, err)
+	assert.Equal(t, 30.0, dim.Length, "package dimensions fill in for missing item dimensions")
+	assert.Equal(t, 4.5, dim.Height)
+	assert.Equal(t, 30.0, dim.PackageL)
+
+	weight, err := parser.ExtractWeight(html)
+	require.NoError(t, err)
+	assert.Equal(t, 300.0, weight.Value)
+	assert.Equal(t, "g", weight.Unit)
+	assert.Equal(t, 0.35, weight.PackageWeight)
+	assert.Equal(t, "kg", weight.PackageUnit)
+}
+
+func TestExtractDimensions_NotFound(t *testing.T) {
+	parser := NewAmazonParser()
+	html := `<div id="detailBullets_feature_div"><ul><li><span class="a-text-bold">ASIN :</span> B0TEST0001</li></ul></div>`
+
+	_, err := parser.ExtractDimensions(html)
+	assert.Error(t, err)
+	_, err = parser.ExtractWeight(html)
+	assert.Error(t, err)
+}
+
+func TestParseProductPageReader(t *testing.T) {
+	parser := NewAmazonParser()
+	html := `<html><body><span id="productTitle"> Test Shirt </span>` + detailBulletsHTML +
+		`<span class="a-price-whole">19,99</span></body></html>`
+
+	fromString, err := parser.ParseProductPage(html, "B0TEST0001")
+	require.NoError(t, err)
+	fromReader, err := parser.ParseProductPageReader(strings.NewReader(html), "B0TEST0001")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Test Shirt", fromReader.Title)
+	assert.Equal(t, 78.0, fromReader.Dimensions.Length)
+	assert.Equal(t, 180.0, fromReader.Weight.Value)
+	assert.Equal(t, 19.99, fromReader.Price.Amount)
+	fromReader.ScrapedAt, fromReader.LastUpdated = fromString.ScrapedAt, fromString.LastUpdated
+	assert.Equal(t, fromString, fromReader)
+}
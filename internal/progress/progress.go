@@ -0,0 +1,189 @@
+// Package progress renders a live status line for long-running crawls on a terminal and prints a summary
+// table when they finish. All methods work on a nil *Tracker, so code that reports progress does not
+// need to know whether anyone is watching.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+)
+
+// refreshInterval is how often the status line is redrawn
+const refreshInterval = 500 * time.Millisecond
+
+// Tracker counts crawled pages, found products and scrape outcomes of a run
+type Tracker struct {
+	label       string
+	w           io.Writer
+	interactive bool // Redraw a status line, otherwise only the summary is written
+	now         func() time.Time
+	started     time.Time
+
+	pages     atomic.Int64
+	found     atomic.Int64
+	total     atomic.Int64 // Products expected to be scraped, 0 when unknown
+	succeeded atomic.Int64
+	failed    atomic.Int64
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// New creates a tracker writing to w. The status line is only drawn when w is a terminal, the summary
+// whenever the tracker exists.
+func New(label string, w io.Writer) *Tracker {
+	return &Tracker{
+		label:       label,
+		w:           w,
+		interactive: IsTerminal(w),
+		now:         time.Now,
+		started:     time.Now(),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// IsTerminal reports whether w is a character device such as an interactive terminal
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Start begins redrawing the status line until Stop
+func (t *Tracker) Start() {
+	if t == nil || !t.interactive {
+		return
+	}
+	go func() {
+		defer close(t.done)
+		ticker := time.NewTicker(refreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-t.stop:
+				// Clear the status line so the summary starts on an empty line
+				fmt.Fprint(t.w, "\r\033[K")
+				return
+			case <-ticker.C:
+				fmt.Fprintf(t.w, "\r\033[K%s", t.Line())
+			}
+		}
+	}()
+}
+
+// Stop ends the status line and writes the summary table
+func (t *Tracker) Stop() {
+	if t == nil {
+		return
+	}
+	t.stopOnce.Do(func() {
+		close(t.stop)
+		if t.interactive {
+			<-t.done
+		}
+		t.WriteSummary(t.w)
+	})
+}
+
+// AddPage counts a crawled page and the products found on it
+func (t *Tracker) AddPage(products int) {
+	if t == nil {
+		return
+	}
+	t.pages.Add(1)
+	t.found.Add(int64(products))
+}
+
+// SetTotal sets the number of products expected to be scraped, which the ETA is based on
+func (t *Tracker) SetTotal(n int) {
+	if t == nil {
+		return
+	}
+	t.total.Store(int64(n))
+}
+
+// Done counts a scraped product, failed when err is not nil
+func (t *Tracker) Done(err error) {
+	if t == nil {
+		return
+	}
+	if err != nil {
+		t.failed.Add(1)
+	} else {
+		t.succeeded.Add(1)
+	}
+}
+
+// Line returns the current status, e.g. "sizes 3 pages 120 found 45/120 scraped (40 ok, 5 failed) ETA 12m30s"
+func (t *Tracker) Line() string {
+	var b strings.Builder
+	b.WriteString(t.label)
+	if pages := t.pages.Load(); pages > 0 {
+		fmt.Fprintf(&b, "  %d pages  %d found", pages, t.found.Load())
+	}
+
+	succeeded, failed := t.succeeded.Load(), t.failed.Load()
+	scraped := succeeded + failed
+	total := t.total.Load()
+	if scraped > 0 || total > 0 {
+		if total > 0 {
+			fmt.Fprintf(&b, "  %d/%d scraped", scraped, total)
+		} else {
+			fmt.Fprintf(&b, "  %d scraped", scraped)
+		}
+		fmt.Fprintf(&b, " (%d ok, %d failed)", succeeded, failed)
+	}
+	if eta, ok := t.eta(); ok {
+		fmt.Fprintf(&b, "  ETA %s", eta)
+	}
+	fmt.Fprintf(&b, "  %s", t.elapsed())
+	return b.String()
+}
+
+// eta extrapolates the remaining time from the scrape rate so far
+func (t *Tracker) eta() (time.Duration, bool) {
+	scraped := t.succeeded.Load() + t.failed.Load()
+	total := t.total.Load()
+	if scraped == 0 || total <= scraped {
+		return 0, false
+	}
+	perProduct := t.now().Sub(t.started) / time.Duration(scraped)
+	return (perProduct * time.Duration(total-scraped)).Round(time.Second), true
+}
+
+func (t *Tracker) elapsed() time.Duration {
+	return t.now().Sub(t.started).Round(time.Second)
+}
+
+// WriteSummary writes the final counts as a table
+func (t *Tracker) WriteSummary(w io.Writer) {
+	if t == nil {
+		return
+	}
+	succeeded, failed := t.succeeded.Load(), t.failed.Load()
+	rate := "-"
+	if scraped := succeeded + failed; scraped > 0 {
+		rate = fmt.Sprintf("%.1f%%", float64(succeeded)/float64(scraped)*100)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s summary\t\n", t.label)
+	fmt.Fprintf(tw, "Pages crawled\t%d\n", t.pages.Load())
+	fmt.Fprintf(tw, "Products found\t%d\n", t.found.Load())
+	fmt.Fprintf(tw, "Scraped\t%d\n", succeeded)
+	fmt.Fprintf(tw, "Failed\t%d\n", failed)
+	fmt.Fprintf(tw, "Success rate\t%s\n", rate)
+	fmt.Fprintf(tw, "Duration\t%s\n", t.elapsed())
+	tw.Flush()
+}
@@ -0,0 +1,70 @@
+package progress
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTracker_Line(t *testing.T) {
+	tr := New("sizes", &bytes.Buffer{})
+	now := tr.started
+	tr.now = func() time.Time { return now }
+
+	tr.AddPage(40)
+	tr.AddPage(20)
+	tr.SetTotal(60)
+	for range 15 {
+		tr.Done(nil)
+	}
+	tr.Done(errors.New("captcha"))
+	now = now.Add(4 * time.Minute)
+
+	want := "sizes  2 pages  60 found  16/60 scraped (15 ok, 1 failed)  ETA 11m0s  4m0s"
+	if got := tr.Line(); got != want {
+		t.Errorf("Line() = %q, want %q", got, want)
+	}
+}
+
+func TestTracker_NoETAWithoutTotal(t *testing.T) {
+	tr := New("process", &bytes.Buffer{})
+	tr.Done(nil)
+	if line := tr.Line(); strings.Contains(line, "ETA") || !strings.Contains(line, "1 scraped (1 ok, 0 failed)") {
+		t.Errorf("Line() = %q", line)
+	}
+}
+
+func TestTracker_StopWritesSummary(t *testing.T) {
+	var buf bytes.Buffer
+	tr := New("crawl", &buf)
+	tr.Start()
+	tr.AddPage(10)
+	tr.Done(nil)
+	tr.Done(errors.New("timeout"))
+	tr.Stop()
+	tr.Stop()
+
+	out := buf.String()
+	if strings.Contains(out, "\r") {
+		t.Error("status line drawn on a non-terminal writer")
+	}
+	for _, want := range []string{"crawl summary", "Pages crawled   1", "Products found  10", "Failed          1", "Success rate    50.0%"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("summary missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "crawl summary") != 1 {
+		t.Errorf("summary written more than once:\n%s", out)
+	}
+}
+
+func TestTracker_Nil(t *testing.T) {
+	var tr *Tracker
+	tr.Start()
+	tr.AddPage(1)
+	tr.SetTotal(1)
+	tr.Done(nil)
+	tr.Stop()
+}
@@ -13,12 +13,12 @@ var (
 )
 
 type Task struct {
-	ID        string
-	URL       string
-	ASIN      string
-	Priority  int
-	Retries   int
-	CreatedAt time.Time
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	ASIN      string    `json:"asin"`
+	Priority  int       `json:"priority"`
+	Retries   int       `json:"retries"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type Queue interface {
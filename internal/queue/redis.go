@@ -0,0 +1,267 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Acker is implemented by durable queues that redeliver popped tasks after a visibility timeout until
+// they are acknowledged
+type Acker interface {
+	Ack(task *Task) error
+	DeadLetter(task *Task, reason string) error
+}
+
+// RedisOptions configures a RedisQueue
+type RedisOptions struct {
+	VisibilityTimeout time.Duration // Popped tasks not acknowledged in time are redelivered
+	MaxRetries        int           // Redeliveries before a task moves to the dead-letter list, 0 is unlimited
+	Wait              time.Duration // Pop waits this long for tasks pushed by other processes before ErrQueueEmpty
+	PollInterval      time.Duration // Pause between checks for new tasks while waiting
+}
+
+// DefaultRedisOptions redelivers tasks not acknowledged within 5 minutes up to 3 times
+func DefaultRedisOptions() RedisOptions {
+	return RedisOptions{VisibilityTimeout: 5 * time.Minute, MaxRetries: 3, PollInterval: time.Second}
+}
+
+// DeadLetter is a task that exhausted its retries
+type DeadLetter struct {
+	Task     *Task     `json:"task"`
+	Reason   string    `json:"reason"`
+	FailedAt time.Time `json:"failed_at"`
+}
+
+// RedisQueue is a Queue shared by processes through Redis. Tasks survive restarts: a popped task stays
+// in flight until it is acknowledged, pushed back for a retry or its visibility timeout expires.
+//
+// Keys share the hash tag {name} so the scripts work on Redis Cluster:
+//
+//	queue:{name}:pending   sorted set of task IDs, by priority and push time
+//	queue:{name}:inflight  sorted set of popped task IDs, by visibility deadline
+//	queue:{name}:tasks     hash of task ID to task JSON
+//	queue:{name}:retries   hash of task ID to retry count
+//	queue:{name}:dead      list of DeadLetter JSON
+type RedisQueue struct {
+	rdb    redis.UniversalClient
+	opts   RedisOptions
+	keys   []string // pending, inflight, tasks, retries, dead
+	closed atomic.Bool
+}
+
+// NewRedisQueue creates the queue name on rdb, the caller keeps ownership of rdb
+func NewRedisQueue(rdb redis.UniversalClient, name string, opts RedisOptions) *RedisQueue {
+	defaults := DefaultRedisOptions()
+	if opts.VisibilityTimeout <= 0 {
+		opts.VisibilityTimeout = defaults.VisibilityTimeout
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaults.PollInterval
+	}
+	prefix := "queue:{" + name + "}:"
+	return &RedisQueue{
+		rdb:  rdb,
+		opts: opts,
+		keys: []string{prefix + "pending", prefix + "inflight", prefix + "tasks", prefix + "retries", prefix + "dead"},
+	}
+}
+
+// score orders higher priorities first and tasks of equal priority by push time
+func score(priority int, at time.Time) float64 {
+	return float64(-priority)*1e13 + float64(at.UnixMilli())
+}
+
+// Push adds a task or, for a popped task, returns it to the queue with its Retries count
+func (q *RedisQueue) Push(task *Task) error {
+	if q.closed.Load() {
+		return ErrQueueClosed
+	}
+	data, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to encode task: %w", err)
+	}
+
+	ctx := context.Background()
+	_, err = q.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, q.keys[2], task.ID, data)
+		pipe.HSet(ctx, q.keys[3], task.ID, task.Retries)
+		pipe.ZRem(ctx, q.keys[1], task.ID)
+		pipe.ZAdd(ctx, q.keys[0], redis.Z{Score: score(task.Priority, time.Now()), Member: task.ID})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to push task: %w", err)
+	}
+	return nil
+}
+
+// reclaimScript moves in-flight tasks past their deadline back to pending, or to the dead-letter list
+// once they exceeded the retries
+var reclaimScript = redis.NewScript(`
+local ids = redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', ARGV[1])
+for _, id in ipairs(ids) do
+	redis.call('ZREM', KEYS[2], id)
+	local retries = redis.call('HINCRBY', KEYS[4], id, 1)
+	local data = redis.call('HGET', KEYS[3], id)
+	if data then
+		local task = cjson.decode(data)
+		if tonumber(ARGV[2]) > 0 and retries > tonumber(ARGV[2]) then
+			task.retries = retries - 1
+			redis.call('RPUSH', KEYS[5], cjson.encode({task = task, reason = 'visibility timeout expired', failed_at = ARGV[3]}))
+			redis.call('HDEL', KEYS[3], id)
+			redis.call('HDEL', KEYS[4], id)
+		else
+			redis.call('ZADD', KEYS[1], -(task.priority or 0) * 1e13 + tonumber(ARGV[1]), id)
+		end
+	else
+		redis.call('HDEL', KEYS[4], id)
+	end
+end
+return #ids
+`)
+
+// popScript moves the first pending task in flight until ARGV[1] and returns its JSON and retry count
+var popScript = redis.NewScript(`
+local popped = redis.call('ZPOPMIN', KEYS[1])
+if #popped == 0 then
+	return false
+end
+local id = popped[1]
+redis.call('ZADD', KEYS[2], ARGV[1], id)
+return {id, redis.call('HGET', KEYS[3], id) or '', redis.call('HGET', KEYS[4], id) or '0'}
+`)
+
+// Pop returns the next task, reclaiming expired in-flight tasks first. An empty queue is polled for
+// RedisOptions.Wait before ErrQueueEmpty is returned.
+func (q *RedisQueue) Pop(ctx context.Context) (*Task, error) {
+	waitUntil := time.Now().Add(q.opts.Wait)
+	for {
+		if q.closed.Load() {
+			return nil, ErrQueueClosed
+		}
+
+		task, err := q.pop(ctx)
+		if err != nil || task != nil {
+			return task, err
+		}
+
+		if time.Now().After(waitUntil) {
+			return nil, ErrQueueEmpty
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(q.opts.PollInterval):
+		}
+	}
+}
+
+func (q *RedisQueue) pop(ctx context.Context) (*Task, error) {
+	now := time.Now()
+	if err := reclaimScript.Run(ctx, q.rdb, q.keys, now.UnixMilli(), q.opts.MaxRetries, now.UTC().Format(time.RFC3339)).Err(); err != nil {
+		return nil, fmt.Errorf("failed to reclaim tasks: %w", err)
+	}
+
+	for {
+		deadline := now.Add(q.opts.VisibilityTimeout).UnixMilli()
+		res, err := popScript.Run(ctx, q.rdb, q.keys[:4], deadline).StringSlice()
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to pop task: %w", err)
+		}
+
+		id, data, retries := res[0], res[1], res[2]
+		if data == "" {
+			// Task data was removed by an Ack racing with a redelivery
+			q.rdb.ZRem(ctx, q.keys[1], id)
+			continue
+		}
+		var task Task
+		if err := json.Unmarshal([]byte(data), &task); err != nil {
+			q.DeadLetter(&Task{ID: id}, "undecodable task: "+err.Error())
+			continue
+		}
+		task.Retries, _ = strconv.Atoi(retries)
+		return &task, nil
+	}
+}
+
+// Ack removes a finished task
+func (q *RedisQueue) Ack(task *Task) error {
+	ctx := context.Background()
+	_, err := q.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, q.keys[1], task.ID)
+		pipe.HDel(ctx, q.keys[2], task.ID)
+		pipe.HDel(ctx, q.keys[3], task.ID)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to acknowledge task: %w", err)
+	}
+	return nil
+}
+
+// DeadLetter removes a task that will not be retried and records it in the dead-letter list
+func (q *RedisQueue) DeadLetter(task *Task, reason string) error {
+	data, err := json.Marshal(DeadLetter{Task: task, Reason: reason, FailedAt: time.Now().UTC()})
+	if err != nil {
+		return fmt.Errorf("failed to encode dead letter: %w", err)
+	}
+
+	ctx := context.Background()
+	_, err = q.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.RPush(ctx, q.keys[4], data)
+		pipe.ZRem(ctx, q.keys[0], task.ID)
+		pipe.ZRem(ctx, q.keys[1], task.ID)
+		pipe.HDel(ctx, q.keys[2], task.ID)
+		pipe.HDel(ctx, q.keys[3], task.ID)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to dead-letter task: %w", err)
+	}
+	return nil
+}
+
+// DeadLetters returns the tasks that exhausted their retries, oldest first
+func (q *RedisQueue) DeadLetters(ctx context.Context) ([]DeadLetter, error) {
+	values, err := q.rdb.LRange(ctx, q.keys[4], 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	letters := make([]DeadLetter, 0, len(values))
+	for _, v := range values {
+		var letter DeadLetter
+		if err := json.Unmarshal([]byte(v), &letter); err != nil {
+			continue
+		}
+		letters = append(letters, letter)
+	}
+	return letters, nil
+}
+
+// Size returns the number of pending and in-flight tasks
+func (q *RedisQueue) Size() int {
+	ctx := context.Background()
+	pending, err := q.rdb.ZCard(ctx, q.keys[0]).Result()
+	if err != nil {
+		return 0
+	}
+	inflight, _ := q.rdb.ZCard(ctx, q.keys[1]).Result()
+	return int(pending + inflight)
+}
+
+// Close stops Push and Pop, queued tasks stay in Redis
+func (q *RedisQueue) Close() error {
+	q.closed.Store(true)
+	return nil
+}
@@ -0,0 +1,76 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestScoreOrdersByPriorityThenTime(t *testing.T) {
+	now := time.Now()
+	if score(2, now.Add(time.Hour)) >= score(1, now) {
+		t.Error("Expected higher priority first")
+	}
+	if score(1, now) >= score(1, now.Add(time.Millisecond)) {
+		t.Error("Expected older tasks of equal priority first")
+	}
+}
+
+// testRedisQueue connects to REDIS_TEST_ADDR, the queue is removed after the test
+func testRedisQueue(t *testing.T, opts RedisOptions) *RedisQueue {
+	addr := os.Getenv("REDIS_TEST_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_TEST_ADDR not set")
+	}
+	rdb := redis.NewClient(&redis.Options{Addr: addr})
+	q := NewRedisQueue(rdb, "test-"+t.Name(), opts)
+	t.Cleanup(func() {
+		rdb.Del(context.Background(), q.keys...)
+		rdb.Close()
+	})
+	return q
+}
+
+func TestRedisQueue_RedeliversAndDeadLetters(t *testing.T) {
+	q := testRedisQueue(t, RedisOptions{VisibilityTimeout: 50 * time.Millisecond, MaxRetries: 1})
+	ctx := context.Background()
+
+	if err := q.Push(&Task{ID: "B0TEST0001", ASIN: "B0TEST0001", Priority: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Push(&Task{ID: "B0TEST0002", ASIN: "B0TEST0002", Priority: 5}); err != nil {
+		t.Fatal(err)
+	}
+
+	task, err := q.Pop(ctx)
+	if err != nil || task.ID != "B0TEST0002" {
+		t.Fatalf("Pop() = %+v, %v; want the higher priority task", task, err)
+	}
+	if err := q.Ack(task); err != nil {
+		t.Fatal(err)
+	}
+
+	// Not acknowledged, redelivered once with a retry counted, then dead-lettered
+	q.Pop(ctx)
+	time.Sleep(60 * time.Millisecond)
+	task, err = q.Pop(ctx)
+	if err != nil || task.ID != "B0TEST0001" || task.Retries != 1 {
+		t.Fatalf("Pop() = %+v, %v; want redelivery with 1 retry", task, err)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if _, err := q.Pop(ctx); !errors.Is(err, ErrQueueEmpty) {
+		t.Fatalf("Expected ErrQueueEmpty after the retries, got %v", err)
+	}
+
+	letters, err := q.DeadLetters(ctx)
+	if err != nil || len(letters) != 1 || letters[0].Task.ID != "B0TEST0001" {
+		t.Errorf("DeadLetters() = %+v, %v", letters, err)
+	}
+	if size := q.Size(); size != 0 {
+		t.Errorf("Size() = %d, want 0", size)
+	}
+}
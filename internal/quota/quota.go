@@ -0,0 +1,234 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrBudgetExceeded is returned when a subject has used up its daily fetch budget
+var ErrBudgetExceeded = errors.New("daily fetch budget exceeded")
+
+// Subject kinds, a subject is "<kind>:<id>" such as "api:content-service" or "job:<uuid>"
+const (
+	KindAPI = "api"
+	KindJob = "job"
+
+	// Internal is charged for fetches without a subject in the context
+	Internal = "internal"
+)
+
+// Actions applied to jobs whose budget is exceeded, API requests are always rejected
+const (
+	ActionReject = "reject" // Fail the job
+	ActionQueue  = "queue"  // Return the job to pending until the budget resets
+)
+
+// ParseAction validates an exceeded-budget action, empty defaults to queue
+func ParseAction(s string) (string, error) {
+	switch s {
+	case "":
+		return ActionQueue, nil
+	case ActionReject, ActionQueue:
+		return s, nil
+	}
+	return "", fmt.Errorf("unknown quota action: %s", s)
+}
+
+// APISubject returns the subject charged for requests with the given API key
+func APISubject(key string) string {
+	if key == "" {
+		key = "anonymous"
+	}
+	return KindAPI + ":" + key
+}
+
+// JobSubject returns the subject charged for fetches of a job
+func JobSubject(jobID string) string {
+	return KindJob + ":" + jobID
+}
+
+type subjectKey struct{}
+
+// WithSubject returns a context whose fetches are charged to the subject
+func WithSubject(ctx context.Context, subject string) context.Context {
+	return context.WithValue(ctx, subjectKey{}, subject)
+}
+
+// SubjectFrom returns the subject charged for fetches made with the context
+func SubjectFrom(ctx context.Context) string {
+	if subject, ok := ctx.Value(subjectKey{}).(string); ok && subject != "" {
+		return subject
+	}
+	return Internal
+}
+
+// ParseBudgets parses "api:content-service=5000,job=500" into daily budgets keyed by subject or kind
+func ParseBudgets(s string) (map[string]int64, error) {
+	budgets := make(map[string]int64)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		subject, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(subject) == "" {
+			return nil, fmt.Errorf("invalid quota budget: %s", pair)
+		}
+		budget, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil || budget < 0 {
+			return nil, fmt.Errorf("invalid quota budget: %s", pair)
+		}
+		budgets[strings.TrimSpace(subject)] = budget
+	}
+	return budgets, nil
+}
+
+// Store persists daily fetch counters
+type Store interface {
+	// Add increments the counter of the subject on the day and returns the new total
+	Add(ctx context.Context, day, subject string, n int64) (int64, error)
+	// Usage returns all counters of the day
+	Usage(ctx context.Context, day string) (map[string]int64, error)
+}
+
+// Usage is the fetch count and budget of one subject on the current day
+type Usage struct {
+	Subject  string `json:"subject"`
+	Fetches  int64  `json:"fetches"`
+	Budget   int64  `json:"budget"` // 0 means unlimited
+	Rejected int64  `json:"rejected"`
+}
+
+// Tracker counts page fetches per subject and enforces daily budgets
+type Tracker struct {
+	store         Store
+	defaultBudget int64
+	budgets       map[string]int64
+	now           func() time.Time
+
+	mu       sync.Mutex
+	rejected map[string]int64 // Rejections since start, keyed by subject
+}
+
+// NewTracker creates a tracker, defaultBudget applies to subjects without a budget, 0 is unlimited
+func NewTracker(store Store, defaultBudget int64, budgets map[string]int64) *Tracker {
+	return &Tracker{
+		store:         store,
+		defaultBudget: defaultBudget,
+		budgets:       budgets,
+		now:           time.Now,
+		rejected:      make(map[string]int64),
+	}
+}
+
+// Budget returns the daily budget of a subject, looked up by subject, then kind, then the default
+func (t *Tracker) Budget(subject string) int64 {
+	if budget, ok := t.budgets[subject]; ok {
+		return budget
+	}
+	kind, _, _ := strings.Cut(subject, ":")
+	if budget, ok := t.budgets[kind]; ok {
+		return budget
+	}
+	return t.defaultBudget
+}
+
+// Consume charges n fetches to the subject of the context, returning ErrBudgetExceeded
+// without charging anything when the fetches would exceed the daily budget
+func (t *Tracker) Consume(ctx context.Context, n int64) error {
+	subject := SubjectFrom(ctx)
+	day := t.day()
+
+	total, err := t.store.Add(ctx, day, subject, n)
+	if err != nil {
+		return fmt.Errorf("failed to count fetches: %w", err)
+	}
+
+	budget := t.Budget(subject)
+	if budget <= 0 || total <= budget {
+		return nil
+	}
+
+	// Undo the charge so rejected fetches do not count against the budget
+	if _, err := t.store.Add(ctx, day, subject, -n); err != nil {
+		return fmt.Errorf("failed to count fetches: %w", err)
+	}
+
+	t.mu.Lock()
+	t.rejected[subject]++
+	t.mu.Unlock()
+
+	return fmt.Errorf("%w: %s used %d of %d", ErrBudgetExceeded, subject, total-n, budget)
+}
+
+// Usage returns today's usage of every subject with fetches or rejections, sorted by subject
+func (t *Tracker) Usage(ctx context.Context) ([]Usage, error) {
+	counts, err := t.store.Usage(ctx, t.day())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fetch usage: %w", err)
+	}
+
+	t.mu.Lock()
+	for subject := range t.rejected {
+		if _, ok := counts[subject]; !ok {
+			counts[subject] = 0
+		}
+	}
+	usage := make([]Usage, 0, len(counts))
+	for subject, fetches := range counts {
+		usage = append(usage, Usage{
+			Subject:  subject,
+			Fetches:  fetches,
+			Budget:   t.Budget(subject),
+			Rejected: t.rejected[subject],
+		})
+	}
+	t.mu.Unlock()
+
+	sort.Slice(usage, func(i, j int) bool { return usage[i].Subject < usage[j].Subject })
+	return usage, nil
+}
+
+// ResetIn returns the time until the daily budgets reset at midnight UTC
+func (t *Tracker) ResetIn() time.Duration {
+	now := t.now().UTC()
+	midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	return midnight.Sub(now)
+}
+
+// WriteMetrics writes today's usage in the Prometheus text format
+func (t *Tracker) WriteMetrics(ctx context.Context, w io.Writer) error {
+	usage, err := t.Usage(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w, "# HELP scraper_quota_fetches Amazon page fetches charged today.")
+	fmt.Fprintln(w, "# TYPE scraper_quota_fetches gauge")
+	for _, u := range usage {
+		fmt.Fprintf(w, "scraper_quota_fetches{subject=%q} %d\n", u.Subject, u.Fetches)
+	}
+	fmt.Fprintln(w, "# HELP scraper_quota_budget Daily fetch budget, 0 is unlimited.")
+	fmt.Fprintln(w, "# TYPE scraper_quota_budget gauge")
+	for _, u := range usage {
+		fmt.Fprintf(w, "scraper_quota_budget{subject=%q} %d\n", u.Subject, u.Budget)
+	}
+	fmt.Fprintln(w, "# HELP scraper_quota_rejected_total Fetches rejected because the budget was exceeded.")
+	fmt.Fprintln(w, "# TYPE scraper_quota_rejected_total counter")
+	for _, u := range usage {
+		fmt.Fprintf(w, "scraper_quota_rejected_total{subject=%q} %d\n", u.Subject, u.Rejected)
+	}
+	return nil
+}
+
+func (t *Tracker) day() string {
+	return t.now().UTC().Format("2006-01-02")
+}
@@ -0,0 +1,130 @@
+package quota
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTracker_Consume(t *testing.T) {
+	tracker := NewTracker(NewMemoryStore(), 0, map[string]int64{"job": 3, "api:partner": 1})
+	ctx := WithSubject(context.Background(), JobSubject("j1"))
+
+	if err := tracker.Consume(ctx, 2); err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+	if err := tracker.Consume(ctx, 2); !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("expected ErrBudgetExceeded, got %v", err)
+	}
+	// The rejected fetches were not charged, so the last one still fits
+	if err := tracker.Consume(ctx, 1); err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+
+	// Other jobs have their own budget
+	if err := tracker.Consume(WithSubject(context.Background(), JobSubject("j2")), 3); err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+
+	// Fetches without a subject are unlimited by default
+	for i := 0; i < 10; i++ {
+		if err := tracker.Consume(context.Background(), 1); err != nil {
+			t.Fatalf("Consume() error = %v", err)
+		}
+	}
+
+	usage, err := tracker.Usage(context.Background())
+	if err != nil {
+		t.Fatalf("Usage() error = %v", err)
+	}
+	want := []Usage{
+		{Subject: Internal, Fetches: 10},
+		{Subject: "job:j1", Fetches: 3, Budget: 3, Rejected: 1},
+		{Subject: "job:j2", Fetches: 3, Budget: 3},
+	}
+	if len(usage) != len(want) {
+		t.Fatalf("Usage() = %+v, want %+v", usage, want)
+	}
+	for i := range want {
+		if usage[i] != want[i] {
+			t.Errorf("Usage()[%d] = %+v, want %+v", i, usage[i], want[i])
+		}
+	}
+}
+
+func TestTracker_DailyReset(t *testing.T) {
+	tracker := NewTracker(NewMemoryStore(), 1, nil)
+	now := time.Date(2024, 5, 1, 23, 30, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return now }
+	ctx := WithSubject(context.Background(), APISubject(""))
+
+	if err := tracker.Consume(ctx, 1); err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+	if err := tracker.Consume(ctx, 1); !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("expected ErrBudgetExceeded, got %v", err)
+	}
+	if got := tracker.ResetIn(); got != 30*time.Minute {
+		t.Errorf("ResetIn() = %v, want 30m", got)
+	}
+
+	now = now.Add(time.Hour)
+	if err := tracker.Consume(ctx, 1); err != nil {
+		t.Errorf("expected fresh budget after midnight, got %v", err)
+	}
+}
+
+func TestTracker_Budget(t *testing.T) {
+	tracker := NewTracker(NewMemoryStore(), 100, map[string]int64{"api": 50, "api:partner": 500})
+
+	tests := map[string]int64{
+		"api:partner": 500,
+		"api:other":   50,
+		"job:j1":      100,
+	}
+	for subject, want := range tests {
+		if got := tracker.Budget(subject); got != want {
+			t.Errorf("Budget(%q) = %d, want %d", subject, got, want)
+		}
+	}
+}
+
+func TestParseBudgets(t *testing.T) {
+	budgets, err := ParseBudgets("api:content-service=5000, job=500")
+	if err != nil {
+		t.Fatalf("ParseBudgets() error = %v", err)
+	}
+	if budgets["api:content-service"] != 5000 || budgets["job"] != 500 {
+		t.Errorf("ParseBudgets() = %v", budgets)
+	}
+
+	for _, invalid := range []string{"job", "=5", "job=abc", "job=-1"} {
+		if _, err := ParseBudgets(invalid); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
+
+func TestTracker_WriteMetrics(t *testing.T) {
+	tracker := NewTracker(NewMemoryStore(), 10, nil)
+	if err := tracker.Consume(WithSubject(context.Background(), APISubject("partner")), 4); err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tracker.WriteMetrics(context.Background(), &buf); err != nil {
+		t.Fatalf("WriteMetrics() error = %v", err)
+	}
+	for _, line := range []string{
+		`scraper_quota_fetches{subject="api:partner"} 4`,
+		`scraper_quota_budget{subject="api:partner"} 10`,
+		`scraper_quota_rejected_total{subject="api:partner"} 0`,
+	} {
+		if !strings.Contains(buf.String(), line) {
+			t.Errorf("metrics missing %q:\n%s", line, buf.String())
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// usageTTL keeps a day's counters around long enough to be inspected the day after
+const usageTTL = 48 * time.Hour
+
+// MemoryStore keeps counters in process memory, they are lost on restart
+type MemoryStore struct {
+	mu     sync.Mutex
+	counts map[string]map[string]int64
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{counts: make(map[string]map[string]int64)}
+}
+
+// Add implements Store, counters of earlier days are dropped when a new day starts
+func (s *MemoryStore) Add(ctx context.Context, day, subject string, n int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts, ok := s.counts[day]
+	if !ok {
+		counts = make(map[string]int64)
+		s.counts[day] = counts
+		for d := range s.counts {
+			if d < day {
+				delete(s.counts, d)
+			}
+		}
+	}
+	counts[subject] += n
+	return counts[subject], nil
+}
+
+// Usage implements Store
+func (s *MemoryStore) Usage(ctx context.Context, day string) (map[string]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	usage := make(map[string]int64, len(s.counts[day]))
+	for subject, n := range s.counts[day] {
+		usage[subject] = n
+	}
+	return usage, nil
+}
+
+// RedisStore keeps counters in one Redis hash per day so budgets are shared between instances
+type RedisStore struct {
+	client redis.Cmdable
+	prefix string
+}
+
+// NewRedisStore creates a store writing to "<prefix>:<day>" hashes
+func NewRedisStore(client redis.Cmdable, prefix string) *RedisStore {
+	if prefix == "" {
+		prefix = "scraper:quota"
+	}
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// Add implements Store
+func (s *RedisStore) Add(ctx context.Context, day, subject string, n int64) (int64, error) {
+	key := s.key(day)
+
+	var incr *redis.IntCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.HIncrBy(ctx, key, subject, n)
+		pipe.Expire(ctx, key, usageTTL)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment fetch counter: %w", err)
+	}
+	return incr.Val(), nil
+}
+
+// Usage implements Store
+func (s *RedisStore) Usage(ctx context.Context, day string) (map[string]int64, error) {
+	values, err := s.client.HGetAll(ctx, s.key(day)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fetch counters: %w", err)
+	}
+
+	usage := make(map[string]int64, len(values))
+	for subject, value := range values {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+		usage[subject] = n
+	}
+	return usage, nil
+}
+
+func (s *RedisStore) key(day string) string {
+	return s.prefix + ":" + day
+}
@@ -0,0 +1,185 @@
+// Package redisconn creates Redis clients for standalone, Sentinel and Cluster deployments.
+package redisconn
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Deployment modes
+const (
+	ModeStandalone = "standalone"
+	ModeSentinel   = "sentinel"
+	ModeCluster    = "cluster"
+)
+
+// Config describes how to reach Redis
+type Config struct {
+	Mode             string   // ModeStandalone, ModeSentinel or ModeCluster, empty is standalone
+	Addrs            []string // Server address, Sentinel addresses or Cluster seed nodes
+	MasterName       string   // Sentinel master name
+	Username         string   // ACL user, empty uses the default user
+	Password         string
+	SentinelPassword string // Password of the Sentinels if it differs from the servers'
+	DB               int    // Not supported by Cluster
+
+	TLS           bool
+	TLSCAFile     string // PEM CA bundle verifying the servers, empty uses the system roots
+	TLSServerName string // Overrides the name verified against the server certificate
+	TLSSkipVerify bool
+}
+
+// FromEnv reads the REDIS_* variables shared by the service and the standalone tools
+func FromEnv() Config {
+	return Config{
+		Mode:             getEnv("REDIS_MODE", ModeStandalone),
+		Addrs:            SplitAddrs(getEnv("REDIS_ADDR", "localhost:6379")),
+		MasterName:       getEnv("REDIS_MASTER_NAME", ""),
+		Username:         getEnv("REDIS_USERNAME", ""),
+		Password:         getEnv("REDIS_PASSWORD", ""),
+		SentinelPassword: getEnv("REDIS_SENTINEL_PASSWORD", ""),
+		DB:               getEnvInt("REDIS_DB", 0),
+		TLS:              getEnvBool("REDIS_TLS", false),
+		TLSCAFile:        getEnv("REDIS_TLS_CA_FILE", ""),
+		TLSServerName:    getEnv("REDIS_TLS_SERVER_NAME", ""),
+		TLSSkipVerify:    getEnvBool("REDIS_TLS_SKIP_VERIFY", false),
+	}
+}
+
+// SplitAddrs splits a comma separated address list, dropping empty entries
+func SplitAddrs(s string) []string {
+	var addrs []string
+	for _, addr := range strings.Split(s, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+// Validate checks that the settings fit the deployment mode
+func (c Config) Validate() error {
+	if len(c.Addrs) == 0 {
+		return fmt.Errorf("redis address is required")
+	}
+
+	switch c.Mode {
+	case "", ModeStandalone:
+		if len(c.Addrs) > 1 {
+			return fmt.Errorf("standalone redis takes a single address, use sentinel or cluster mode for %d", len(c.Addrs))
+		}
+	case ModeSentinel:
+		if c.MasterName == "" {
+			return fmt.Errorf("sentinel mode requires a master name")
+		}
+	case ModeCluster:
+		if c.DB != 0 {
+			return fmt.Errorf("redis cluster only supports database 0")
+		}
+	default:
+		return fmt.Errorf("unsupported redis mode: %s", c.Mode)
+	}
+
+	if !c.TLS && (c.TLSCAFile != "" || c.TLSServerName != "" || c.TLSSkipVerify) {
+		return fmt.Errorf("redis TLS options are set but TLS is disabled")
+	}
+	return nil
+}
+
+// New creates a client for the configured mode. The client is not connected yet, callers Ping it.
+func New(cfg Config) (redis.UniversalClient, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := cfg.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &redis.UniversalOptions{
+		Addrs:            cfg.Addrs,
+		MasterName:       cfg.MasterName,
+		Username:         cfg.Username,
+		Password:         cfg.Password,
+		SentinelPassword: cfg.SentinelPassword,
+		DB:               cfg.DB,
+		TLSConfig:        tlsConfig,
+	}
+
+	switch cfg.Mode {
+	case ModeSentinel:
+		return redis.NewFailoverClient(opts.Failover()), nil
+	case ModeCluster:
+		return redis.NewClusterClient(opts.Cluster()), nil
+	default:
+		return redis.NewClient(opts.Simple()), nil
+	}
+}
+
+// String describes the deployment for logs without credentials
+func (c Config) String() string {
+	mode := c.Mode
+	if mode == "" {
+		mode = ModeStandalone
+	}
+	s := mode + " " + strings.Join(c.Addrs, ",")
+	if c.MasterName != "" {
+		s += " master=" + c.MasterName
+	}
+	if c.TLS {
+		s += " tls"
+	}
+	return s
+}
+
+func (c Config) tlsConfig() (*tls.Config, error) {
+	if !c.TLS {
+		return nil, nil
+	}
+
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         c.TLSServerName,
+		InsecureSkipVerify: c.TLSSkipVerify,
+	}
+	if c.TLSCAFile != "" {
+		pem, err := os.ReadFile(c.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read redis CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in redis CA file %s", c.TLSCAFile)
+		}
+		config.RootCAs = pool
+	}
+	return config, nil
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,67 @@
+package redisconn
+
+import (
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{"Standalone", Config{Addrs: []string{"localhost:6379"}}, false},
+		{"No address", Config{}, true},
+		{"Standalone with several addresses", Config{Addrs: []string{"a:6379", "b:6379"}}, true},
+		{"Sentinel", Config{Mode: ModeSentinel, Addrs: []string{"a:26379", "b:26379"}, MasterName: "mymaster"}, false},
+		{"Sentinel without master", Config{Mode: ModeSentinel, Addrs: []string{"a:26379"}}, true},
+		{"Cluster", Config{Mode: ModeCluster, Addrs: []string{"a:7000", "b:7000"}}, false},
+		{"Cluster with database", Config{Mode: ModeCluster, Addrs: []string{"a:7000"}, DB: 1}, true},
+		{"Unknown mode", Config{Mode: "ring", Addrs: []string{"a:6379"}}, true},
+		{"TLS options without TLS", Config{Addrs: []string{"a:6379"}, TLSSkipVerify: true}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewClientPerMode(t *testing.T) {
+	standalone, err := New(Config{Addrs: []string{"localhost:6379"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer standalone.Close()
+	if _, ok := standalone.(*redis.Client); !ok {
+		t.Errorf("Expected *redis.Client, got %T", standalone)
+	}
+
+	cluster, err := New(Config{Mode: ModeCluster, Addrs: []string{"a:7000", "b:7000"}, TLS: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cluster.Close()
+	if _, ok := cluster.(*redis.ClusterClient); !ok {
+		t.Errorf("Expected *redis.ClusterClient, got %T", cluster)
+	}
+}
+
+func TestNewFailsOnMissingCAFile(t *testing.T) {
+	_, err := New(Config{Addrs: []string{"localhost:6379"}, TLS: true, TLSCAFile: "/nonexistent/ca.pem"})
+	if err == nil {
+		t.Error("Expected error for a missing CA file")
+	}
+}
+
+func TestSplitAddrs(t *testing.T) {
+	got := SplitAddrs(" a:26379, ,b:26379 ")
+	if len(got) != 2 || got[0] != "a:26379" || got[1] != "b:26379" {
+		t.Errorf("Unexpected addresses: %v", got)
+	}
+}
@@ -0,0 +1,143 @@
+// Package reviewsummary derives a structured fit summary from customer reviews with a language model.
+package reviewsummary
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/maltedev/amazon-size-scraper/internal/llm"
+)
+
+var (
+	// ErrNoReviews is returned when there are no review texts to summarize
+	ErrNoReviews = errors.New("no reviews to summarize")
+	// ErrInvalidSummary is returned when the model's answer is not a valid fit summary
+	ErrInvalidSummary = errors.New("invalid fit summary")
+)
+
+const (
+	// maxReviews is how many reviews go into one prompt
+	maxReviews = 20
+	// maxReviewLength cuts long review texts, in runes
+	maxReviewLength = 1500
+	// maxNotesLength cuts long tall fit notes, in runes
+	maxNotesLength = 500
+)
+
+const systemPrompt = `You summarize Amazon clothing reviews for customers who are tall.
+Answer with a single JSON object with exactly these fields:
+- "runs_small_score": number from -1 (runs large) over 0 (true to size) to 1 (runs small), based on what reviewers say about size
+- "fabric_quality": one of "poor", "average", "good", "excellent", or "unknown" if reviews do not mention the fabric
+- "tall_fit_notes": at most three sentences in English on length of body and sleeves and fit for tall people, empty if reviews do not mention it
+Reviews may be in German or English. Do not invent information that is not in the reviews.`
+
+// Summarizer sends review texts to a language model and validates the fit summary it answers with
+type Summarizer struct {
+	client llm.Client
+	now    func() time.Time
+}
+
+// New creates a summarizer, nil when no client is configured
+func New(client llm.Client) *Summarizer {
+	if client == nil {
+		return nil
+	}
+	return &Summarizer{client: client, now: time.Now}
+}
+
+// Summarize returns the fit summary of the reviews, reviews without text are left out
+func (s *Summarizer) Summarize(ctx context.Context, reviews []database.Review) (*database.FitSummary, error) {
+	prompt, count := userPrompt(reviews)
+	if count == 0 {
+		return nil, ErrNoReviews
+	}
+
+	answer, err := s.client.Complete(ctx, []llm.Message{
+		{Role: llm.RoleSystem, Content: systemPrompt},
+		{Role: llm.RoleUser, Content: prompt},
+	}, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize reviews: %w", err)
+	}
+
+	summary, err := parseSummary(answer)
+	if err != nil {
+		return nil, err
+	}
+	summary.ReviewCount = count
+	summary.Model = s.client.Model()
+	summary.SummarizedAt = s.now().UTC()
+	return summary, nil
+}
+
+// userPrompt lists the reviews with their ratings and returns how many it contains
+func userPrompt(reviews []database.Review) (string, int) {
+	var b strings.Builder
+	count := 0
+	for _, r := range reviews {
+		text := strings.TrimSpace(r.Text)
+		if text == "" {
+			continue
+		}
+		if count == maxReviews {
+			break
+		}
+		count++
+
+		if runes := []rune(text); len(runes) > maxReviewLength {
+			text = string(runes[:maxReviewLength]) + "…"
+		}
+		fmt.Fprintf(&b, "Review %d (%d/5 stars", count, r.Rating)
+		if r.VerifiedBuyer {
+			b.WriteString(", verified purchase")
+		}
+		b.WriteString(")")
+		if title := strings.TrimSpace(r.Title); title != "" {
+			fmt.Fprintf(&b, ": %s", title)
+		}
+		fmt.Fprintf(&b, "\n%s\n\n", text)
+	}
+	return b.String(), count
+}
+
+// parseSummary decodes the model's answer, tolerating a markdown code fence around the JSON
+func parseSummary(answer string) (*database.FitSummary, error) {
+	answer = strings.TrimSpace(answer)
+	if strings.HasPrefix(answer, "```") {
+		answer = strings.TrimPrefix(strings.TrimPrefix(answer, "```json"), "```")
+		answer = strings.TrimSpace(strings.TrimSuffix(answer, "```"))
+	}
+
+	var raw struct {
+		RunsSmallScore *float64 `json:"runs_small_score"`
+		FabricQuality  string   `json:"fabric_quality"`
+		TallFitNotes   string   `json:"tall_fit_notes"`
+	}
+	if err := json.Unmarshal([]byte(answer), &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSummary, err)
+	}
+	if raw.RunsSmallScore == nil || math.IsNaN(*raw.RunsSmallScore) {
+		return nil, fmt.Errorf("%w: runs_small_score missing", ErrInvalidSummary)
+	}
+
+	summary := &database.FitSummary{
+		RunsSmallScore: math.Max(-1, math.Min(1, *raw.RunsSmallScore)),
+		FabricQuality:  strings.ToLower(strings.TrimSpace(raw.FabricQuality)),
+		TallFitNotes:   strings.TrimSpace(raw.TallFitNotes),
+	}
+	switch summary.FabricQuality {
+	case database.FabricPoor, database.FabricAverage, database.FabricGood, database.FabricExcellent:
+	default:
+		summary.FabricQuality = database.FabricUnknown
+	}
+	if runes := []rune(summary.TallFitNotes); len(runes) > maxNotesLength {
+		summary.TallFitNotes = string(runes[:maxNotesLength]) + "…"
+	}
+	return summary, nil
+}
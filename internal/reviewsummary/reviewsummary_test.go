@@ -0,0 +1,117 @@
+package reviewsummary
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/maltedev/amazon-size-scraper/internal/llm"
+)
+
+// mockClient answers every prompt with a fixed completion and records the prompts
+type mockClient struct {
+	answer   string
+	err      error
+	messages []llm.Message
+	jsonMode bool
+}
+
+func (m *mockClient) Complete(ctx context.Context, messages []llm.Message, jsonMode bool) (string, error) {
+	m.messages, m.jsonMode = messages, jsonMode
+	return m.answer, m.err
+}
+
+func (m *mockClient) Model() string {
+	return "mock-model"
+}
+
+var reviews = []database.Review{
+	{Rating: 4, Title: "Gute Qualität", Text: "Fällt etwas klein aus, bei 1,98 m reicht die Länge aber.", VerifiedBuyer: true},
+	{Rating: 2, Text: "   "},
+	{Rating: 5, Text: "Great fabric, long enough for my 6'5\" frame."},
+}
+
+func TestSummarize(t *testing.T) {
+	client := &mockClient{answer: `{"runs_small_score": 0.4, "fabric_quality": "Good", "tall_fit_notes": "Long enough for tall reviewers."}`}
+	s := New(client)
+	s.now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	summary, err := s.Summarize(context.Background(), reviews)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := database.FitSummary{
+		RunsSmallScore: 0.4,
+		FabricQuality:  database.FabricGood,
+		TallFitNotes:   "Long enough for tall reviewers.",
+		ReviewCount:    2,
+		Model:          "mock-model",
+		SummarizedAt:   time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+	if *summary != want {
+		t.Errorf("summary = %+v, want %+v", *summary, want)
+	}
+
+	if !client.jsonMode || len(client.messages) != 2 || client.messages[0].Role != llm.RoleSystem {
+		t.Fatalf("unexpected prompt: %+v", client.messages)
+	}
+	prompt := client.messages[1].Content
+	if !strings.Contains(prompt, "Review 1 (4/5 stars, verified purchase): Gute Qualität") || !strings.Contains(prompt, "Review 2 (5/5 stars)") {
+		t.Errorf("unexpected user prompt:\n%s", prompt)
+	}
+}
+
+func TestSummarizeErrors(t *testing.T) {
+	if _, err := New(&mockClient{}).Summarize(context.Background(), []database.Review{{Rating: 3}}); !errors.Is(err, ErrNoReviews) {
+		t.Errorf("error = %v, want ErrNoReviews", err)
+	}
+
+	failed := errors.New("rate limited")
+	if _, err := New(&mockClient{err: failed}).Summarize(context.Background(), reviews); !errors.Is(err, failed) {
+		t.Errorf("error = %v, want the client error", err)
+	}
+
+	for _, answer := range []string{"The product runs small.", `{"fabric_quality": "good"}`} {
+		if _, err := New(&mockClient{answer: answer}).Summarize(context.Background(), reviews); !errors.Is(err, ErrInvalidSummary) {
+			t.Errorf("answer %q: error = %v, want ErrInvalidSummary", answer, err)
+		}
+	}
+
+	if New(nil) != nil {
+		t.Error("a summarizer without client should be nil")
+	}
+}
+
+func TestParseSummary(t *testing.T) {
+	summary, err := parseSummary("```json\n{\"runs_small_score\": 3, \"fabric_quality\": \"meh\", \"tall_fit_notes\": \"" + strings.Repeat("a", 600) + "\"}\n```")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.RunsSmallScore != 1 {
+		t.Errorf("RunsSmallScore = %v, want clamped to 1", summary.RunsSmallScore)
+	}
+	if summary.FabricQuality != database.FabricUnknown {
+		t.Errorf("FabricQuality = %q, want unknown", summary.FabricQuality)
+	}
+	if n := len([]rune(summary.TallFitNotes)); n != maxNotesLength+1 {
+		t.Errorf("TallFitNotes has %d runes, want cut to %d", n, maxNotesLength+1)
+	}
+}
+
+func TestUserPromptLimits(t *testing.T) {
+	many := make([]database.Review, maxReviews+5)
+	for i := range many {
+		many[i] = database.Review{Rating: 3, Text: strings.Repeat("ö", maxReviewLength+10)}
+	}
+	prompt, count := userPrompt(many)
+	if count != maxReviews {
+		t.Errorf("count = %d, want %d", count, maxReviews)
+	}
+	if strings.Contains(prompt, strings.Repeat("ö", maxReviewLength+1)) {
+		t.Error("long review texts should be cut")
+	}
+}
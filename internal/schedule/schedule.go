@@ -0,0 +1,324 @@
+// Package schedule adjusts the crawl throughput of each marketplace by its local time of day, so workers
+// crawl faster while Amazon sees little traffic and slow down when load patterns make bans more likely.
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	_ "time/tzdata" // Marketplace time zones must resolve in containers without zoneinfo
+
+	"github.com/maltedev/amazon-size-scraper/internal/ratelimit"
+)
+
+// Built-in profiles
+const (
+	ProfileDefault = "default" // Base throughput outside of every window
+	ProfileNight   = "night"   // Twice the workers at half the rate limit of the default profile
+)
+
+// Profile is the throughput of a marketplace while one of its windows is active, zero fields inherit
+// from the default profile
+type Profile struct {
+	Workers          int     `json:"workers,omitempty"`            // Search result pages fetched in parallel per job
+	RateLimitSeconds float64 `json:"rate_limit_seconds,omitempty"` // Pause between page fetches of the marketplace
+}
+
+// RateLimit returns the pause between page fetches
+func (p Profile) RateLimit() time.Duration {
+	return time.Duration(p.RateLimitSeconds * float64(time.Second))
+}
+
+// Window applies a profile between Start and End, in the local time of each marketplace. A window whose
+// end is before its start runs past midnight and belongs to the day it starts on.
+type Window struct {
+	Profile      string   `json:"profile"`
+	Start        string   `json:"start"`                  // HH:MM
+	End          string   `json:"end"`                    // HH:MM, exclusive
+	Days         []string `json:"days,omitempty"`         // mon-sun or ranges such as mon-fri, empty is every day
+	Marketplaces []string `json:"marketplaces,omitempty"` // Empty applies to all marketplaces
+
+	start, end   int // Minutes of day
+	days         [7]bool
+	marketplaces map[string]bool
+}
+
+// Calendar is the configurable schedule, loaded from a JSON file
+type Calendar struct {
+	Profiles  map[string]Profile `json:"profiles,omitempty"`
+	Timezones map[string]string  `json:"timezones,omitempty"` // Marketplace -> IANA zone, extends the built-in zones
+	Windows   []Window           `json:"windows"`             // The first matching window wins
+}
+
+// NightWindow is the built-in night crawl between 01:00 and 06:00 marketplace time
+func NightWindow() Window {
+	return Window{Profile: ProfileNight, Start: "01:00", End: "06:00"}
+}
+
+// LoadCalendar reads a calendar from a JSON file
+func LoadCalendar(path string) (Calendar, error) {
+	var cal Calendar
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cal, fmt.Errorf("failed to read schedule calendar: %w", err)
+	}
+	if err := json.Unmarshal(data, &cal); err != nil {
+		return cal, fmt.Errorf("failed to parse schedule calendar: %w", err)
+	}
+
+	return cal, nil
+}
+
+// marketplaceZones are the time zones of the marketplaces' customers, others use UTC
+var marketplaceZones = map[string]string{
+	"amazon.de":     "Europe/Berlin",
+	"amazon.at":     "Europe/Vienna",
+	"amazon.fr":     "Europe/Paris",
+	"amazon.it":     "Europe/Rome",
+	"amazon.es":     "Europe/Madrid",
+	"amazon.nl":     "Europe/Amsterdam",
+	"amazon.se":     "Europe/Stockholm",
+	"amazon.pl":     "Europe/Warsaw",
+	"amazon.co.uk":  "Europe/London",
+	"amazon.com":    "America/New_York",
+	"amazon.ca":     "America/Toronto",
+	"amazon.com.mx": "America/Mexico_City",
+	"amazon.co.jp":  "Asia/Tokyo",
+	"amazon.com.au": "Australia/Sydney",
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// State is the active profile of one marketplace for monitoring
+type State struct {
+	Marketplace      string  `json:"marketplace"`
+	Profile          string  `json:"profile"`
+	Workers          int     `json:"workers"`
+	RateLimitSeconds float64 `json:"rate_limit_seconds"`
+}
+
+// Schedule resolves the active profile of a marketplace and rate limits its page fetches, shared by all
+// workers of an instance
+type Schedule struct {
+	profiles map[string]Profile
+	windows  []Window
+	zones    map[string]*time.Location
+	now      func() time.Time
+	logger   *slog.Logger
+
+	mu       sync.Mutex
+	limiters map[string]*ratelimit.SimpleRateLimiter
+	active   map[string]string // Marketplace -> profile the limiter is set to
+}
+
+// New creates a schedule from the default profile and a calendar, the night profile defaults to twice
+// the workers at half the rate limit of base
+func New(base Profile, cal Calendar) (*Schedule, error) {
+	if base.Workers < 1 {
+		return nil, fmt.Errorf("default profile needs at least 1 worker")
+	}
+	if base.RateLimitSeconds < 0 {
+		return nil, fmt.Errorf("default profile rate limit must not be negative")
+	}
+
+	s := &Schedule{
+		profiles: map[string]Profile{
+			ProfileDefault: base,
+			ProfileNight:   {Workers: base.Workers * 2, RateLimitSeconds: base.RateLimitSeconds / 2},
+		},
+		zones:    make(map[string]*time.Location),
+		now:      time.Now,
+		logger:   slog.Default().With("component", "schedule"),
+		limiters: make(map[string]*ratelimit.SimpleRateLimiter),
+		active:   make(map[string]string),
+	}
+
+	for name, p := range cal.Profiles {
+		if p.Workers < 0 || p.RateLimitSeconds < 0 {
+			return nil, fmt.Errorf("profile %s: workers and rate limit must not be negative", name)
+		}
+		if p.Workers == 0 {
+			p.Workers = base.Workers
+		}
+		if p.RateLimitSeconds == 0 {
+			p.RateLimitSeconds = base.RateLimitSeconds
+		}
+		s.profiles[name] = p
+	}
+
+	zones := make(map[string]string, len(marketplaceZones)+len(cal.Timezones))
+	for marketplace, zone := range marketplaceZones {
+		zones[marketplace] = zone
+	}
+	for marketplace, zone := range cal.Timezones {
+		zones[strings.ToLower(marketplace)] = zone
+	}
+	for marketplace, zone := range zones {
+		loc, err := time.LoadLocation(zone)
+		if err != nil {
+			return nil, fmt.Errorf("time zone of %s: %w", marketplace, err)
+		}
+		s.zones[marketplace] = loc
+	}
+
+	for i, w := range cal.Windows {
+		if err := w.parse(); err != nil {
+			return nil, fmt.Errorf("window %d: %w", i+1, err)
+		}
+		if _, ok := s.profiles[w.Profile]; !ok {
+			return nil, fmt.Errorf("window %d: unknown profile %q", i+1, w.Profile)
+		}
+		s.windows = append(s.windows, w)
+	}
+
+	return s, nil
+}
+
+// Profile returns the name and settings of the profile active for the marketplace right now
+func (s *Schedule) Profile(marketplace string) (string, Profile) {
+	name := s.activeProfile(marketplace, s.now())
+	return name, s.profiles[name]
+}
+
+// Wait blocks until the next page fetch from the marketplace is allowed by the rate limit of its active profile
+func (s *Schedule) Wait(ctx context.Context, marketplace string) error {
+	name, p := s.Profile(marketplace)
+
+	s.mu.Lock()
+	limiter, ok := s.limiters[marketplace]
+	if !ok {
+		limiter = ratelimit.NewSimpleRateLimiter(p.RateLimit(), p.RateLimit())
+		s.limiters[marketplace] = limiter
+	}
+	prev := s.active[marketplace]
+	s.active[marketplace] = name
+	s.mu.Unlock()
+
+	if prev != name {
+		// Outside of mu, the limiter holds its lock while a fetch waits
+		if ok {
+			limiter.SetDelay(p.RateLimit(), p.RateLimit())
+		}
+		s.logger.InfoContext(ctx, "marketplace profile changed", "marketplace", marketplace, "from", prev, "to", name,
+			"workers", p.Workers, "rate_limit", p.RateLimit())
+	}
+
+	return limiter.Wait(ctx)
+}
+
+// States returns the active profile of every marketplace fetched from so far, sorted by marketplace
+func (s *Schedule) States() []State {
+	s.mu.Lock()
+	marketplaces := make([]string, 0, len(s.limiters))
+	for marketplace := range s.limiters {
+		marketplaces = append(marketplaces, marketplace)
+	}
+	s.mu.Unlock()
+	sort.Strings(marketplaces)
+
+	states := make([]State, 0, len(marketplaces))
+	for _, marketplace := range marketplaces {
+		name, p := s.Profile(marketplace)
+		states = append(states, State{Marketplace: marketplace, Profile: name, Workers: p.Workers, RateLimitSeconds: p.RateLimitSeconds})
+	}
+	return states
+}
+
+// activeProfile returns the profile of the first window containing t in the marketplace's local time
+func (s *Schedule) activeProfile(marketplace string, t time.Time) string {
+	loc, ok := s.zones[marketplace]
+	if !ok {
+		loc = time.UTC
+	}
+	t = t.In(loc)
+	minute := t.Hour()*60 + t.Minute()
+
+	for _, w := range s.windows {
+		if w.contains(marketplace, t.Weekday(), minute) {
+			return w.Profile
+		}
+	}
+	return ProfileDefault
+}
+
+// contains reports whether the window is active for the marketplace at the minute of the weekday
+func (w *Window) contains(marketplace string, day time.Weekday, minute int) bool {
+	if len(w.marketplaces) > 0 && !w.marketplaces[marketplace] {
+		return false
+	}
+	if w.start <= w.end {
+		return w.days[day] && minute >= w.start && minute < w.end
+	}
+	// Past midnight the window still belongs to the day before
+	if minute >= w.start {
+		return w.days[day]
+	}
+	return minute < w.end && w.days[(day+6)%7]
+}
+
+// parse validates the window and fills its parsed fields
+func (w *Window) parse() error {
+	var err error
+	if w.start, err = parseClock(w.Start); err != nil {
+		return err
+	}
+	if w.end, err = parseClock(w.End); err != nil {
+		return err
+	}
+	if w.start == w.end {
+		return fmt.Errorf("start and end must differ: %s", w.Start)
+	}
+
+	if len(w.Days) == 0 {
+		for d := range w.days {
+			w.days[d] = true
+		}
+	}
+	for _, days := range w.Days {
+		from, to, isRange := strings.Cut(strings.ToLower(strings.TrimSpace(days)), "-")
+		if !isRange {
+			to = from
+		}
+		first, ok := weekdays[from]
+		last, ok2 := weekdays[to]
+		if !ok || !ok2 {
+			return fmt.Errorf("invalid days: %q", days)
+		}
+		for d := first; ; d = (d + 1) % 7 {
+			w.days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+
+	if len(w.Marketplaces) > 0 {
+		w.marketplaces = make(map[string]bool, len(w.Marketplaces))
+		for _, marketplace := range w.Marketplaces {
+			w.marketplaces[strings.ToLower(strings.TrimSpace(marketplace))] = true
+		}
+	}
+	return nil
+}
+
+// parseClock parses HH:MM into minutes of day, 24:00 is the end of the day
+func parseClock(s string) (int, error) {
+	hh, mm, ok := strings.Cut(strings.TrimSpace(s), ":")
+	h, errH := strconv.Atoi(hh)
+	m, errM := strconv.Atoi(mm)
+	if !ok || errH != nil || errM != nil || h < 0 || m < 0 || m > 59 || h > 24 || (h == 24 && m > 0) {
+		return 0, fmt.Errorf("invalid time of day, want HH:MM: %q", s)
+	}
+	return h*60 + m, nil
+}
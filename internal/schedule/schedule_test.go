@@ -0,0 +1,115 @@
+package schedule
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestProfile(t *testing.T) {
+	s, err := New(Profile{Workers: 2, RateLimitSeconds: 4}, Calendar{
+		Profiles: map[string]Profile{"peak": {Workers: 1, RateLimitSeconds: 10}},
+		Windows: []Window{
+			{Profile: "peak", Start: "18:00", End: "21:00", Days: []string{"mon-fri"}, Marketplaces: []string{"amazon.de"}},
+			NightWindow(),
+			{Profile: ProfileNight, Start: "23:00", End: "01:00", Days: []string{"sat"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	tests := []struct {
+		name        string
+		marketplace string
+		at          time.Time
+		want        string
+	}{
+		{"peak on a weekday", "amazon.de", time.Date(2024, 3, 4, 19, 30, 0, 0, berlin), "peak"},
+		{"no peak on sunday", "amazon.de", time.Date(2024, 3, 3, 19, 30, 0, 0, berlin), ProfileDefault},
+		{"peak only for amazon.de", "amazon.com", time.Date(2024, 3, 4, 19, 30, 0, 0, time.FixedZone("EST", -5*3600)), ProfileDefault},
+		{"night in berlin", "amazon.de", time.Date(2024, 3, 4, 3, 0, 0, 0, berlin), ProfileNight},
+		{"night in marketplace time", "amazon.com", time.Date(2024, 3, 4, 3, 0, 0, 0, berlin), ProfileDefault},
+		{"end is exclusive", "amazon.de", time.Date(2024, 3, 4, 6, 0, 0, 0, berlin), ProfileDefault},
+		{"past midnight on saturday", "amazon.de", time.Date(2024, 3, 2, 23, 30, 0, 0, berlin), ProfileNight},
+		{"past midnight into sunday", "amazon.de", time.Date(2024, 3, 3, 0, 30, 0, 0, berlin), ProfileNight},
+		{"friday night is not saturday", "amazon.de", time.Date(2024, 3, 1, 23, 30, 0, 0, berlin), ProfileDefault},
+		{"unknown marketplace uses utc", "amazon.example", time.Date(2024, 3, 4, 2, 0, 0, 0, time.UTC), ProfileNight},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.now = func() time.Time { return tt.at }
+			if got, _ := s.Profile(tt.marketplace); got != tt.want {
+				t.Errorf("Profile(%s) = %q, want %q", tt.marketplace, got, tt.want)
+			}
+		})
+	}
+
+	if p := s.profiles[ProfileNight]; p.Workers != 4 || p.RateLimitSeconds != 2 {
+		t.Errorf("night profile = %+v, want twice the workers at half the rate limit", p)
+	}
+}
+
+func TestNewInheritsDefaultProfile(t *testing.T) {
+	s, err := New(Profile{Workers: 3, RateLimitSeconds: 3}, Calendar{
+		Profiles: map[string]Profile{ProfileNight: {Workers: 8}, "slow": {RateLimitSeconds: 9}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p := s.profiles[ProfileNight]; p.Workers != 8 || p.RateLimitSeconds != 3 {
+		t.Errorf("night profile = %+v, want 8 workers at the default rate limit", p)
+	}
+	if p := s.profiles["slow"]; p.Workers != 3 || p.RateLimit() != 9*time.Second {
+		t.Errorf("slow profile = %+v, want the default workers at 9s", p)
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	base := Profile{Workers: 2, RateLimitSeconds: 3}
+	for name, cal := range map[string]Calendar{
+		"unknown profile": {Windows: []Window{{Profile: "turbo", Start: "01:00", End: "02:00"}}},
+		"invalid time":    {Windows: []Window{{Profile: ProfileNight, Start: "1am", End: "02:00"}}},
+		"empty window":    {Windows: []Window{{Profile: ProfileNight, Start: "02:00", End: "02:00"}}},
+		"invalid days":    {Windows: []Window{{Profile: ProfileNight, Start: "01:00", End: "02:00", Days: []string{"monday"}}}},
+		"negative rate":   {Profiles: map[string]Profile{"x": {RateLimitSeconds: -1}}},
+		"unknown zone":    {Timezones: map[string]string{"amazon.de": "Europe/Nowhere"}},
+	} {
+		if _, err := New(base, cal); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if _, err := New(Profile{}, Calendar{}); err == nil {
+		t.Error("expected error for a default profile without workers")
+	}
+}
+
+func TestWaitPerMarketplace(t *testing.T) {
+	s, err := New(Profile{Workers: 1, RateLimitSeconds: 0.2}, Calendar{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx := context.Background()
+	started := time.Now()
+	for _, marketplace := range []string{"amazon.de", "amazon.com", "amazon.co.uk"} {
+		if err := s.Wait(ctx, marketplace); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if elapsed := time.Since(started); elapsed > 150*time.Millisecond {
+		t.Errorf("first fetches of different marketplaces waited %s", elapsed)
+	}
+
+	if err := s.Wait(ctx, "amazon.de"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(started); elapsed < 200*time.Millisecond {
+		t.Errorf("second fetch of a marketplace waited only %s", elapsed)
+	}
+
+	if states := s.States(); len(states) != 3 || states[0].Marketplace != "amazon.co.uk" || states[0].Profile != ProfileDefault {
+		t.Errorf("unexpected states: %+v", states)
+	}
+}
@@ -0,0 +1,181 @@
+package schema
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// EncodingGzip marks a gzip compressed, base64 encoded payload
+	EncodingGzip = "gzip"
+	// EncodingZstd marks a zstd compressed, base64 encoded payload
+	EncodingZstd = "zstd"
+
+	// MetadataContentEncoding is the metadata key and stream field carrying the payload encoding
+	MetadataContentEncoding = "content_encoding"
+)
+
+// ErrPayloadTooLarge is returned when a payload exceeds the size limit even after truncation
+var ErrPayloadTooLarge = errors.New("payload exceeds size limit")
+
+// truncatableFields are dropped in this order until a payload fits the size limit
+var truncatableFields = []string{"features", "images", "image_urls", "browse_node_tags", "available_sizes"}
+
+// PayloadLimits controls compression and the hard size limit of published payloads
+type PayloadLimits struct {
+	Encoding          string // Empty disables compression, otherwise EncodingGzip or EncodingZstd
+	CompressThreshold int    // Only payloads larger than this many bytes are compressed
+	MaxSize           int    // Hard limit on the encoded payload in bytes, 0 disables
+}
+
+// ParseEncoding validates a content encoding name, empty means uncompressed
+func ParseEncoding(s string) (string, error) {
+	switch s {
+	case "", EncodingGzip, EncodingZstd:
+		return s, nil
+	}
+	return "", fmt.Errorf("unknown payload encoding: %s", s)
+}
+
+// Apply enforces the size limit and compresses the payload, returning the payload to publish,
+// its content encoding and the fields dropped to make it fit
+func (l PayloadLimits) Apply(payload json.RawMessage) (json.RawMessage, string, []string, error) {
+	encoded, encoding, err := l.encode(payload)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if l.MaxSize <= 0 || len(encoded) <= l.MaxSize {
+		return encoded, encoding, nil, nil
+	}
+
+	// Drop optional fields until the payload fits, the product core and size table are never dropped
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return nil, "", nil, fmt.Errorf("%w: %d bytes", ErrPayloadTooLarge, len(encoded))
+	}
+
+	var dropped []string
+	for _, field := range truncatableFields {
+		if _, ok := fields[field]; !ok {
+			continue
+		}
+		delete(fields, field)
+		dropped = append(dropped, field)
+
+		truncated, err := json.Marshal(fields)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("failed to encode truncated payload: %w", err)
+		}
+		if encoded, encoding, err = l.encode(truncated); err != nil {
+			return nil, "", nil, err
+		}
+		if len(encoded) <= l.MaxSize {
+			return encoded, encoding, dropped, nil
+		}
+	}
+
+	return nil, "", dropped, fmt.Errorf("%w: %d bytes after dropping %v", ErrPayloadTooLarge, len(encoded), dropped)
+}
+
+// encode compresses payloads above the threshold into a base64 JSON string
+func (l PayloadLimits) encode(payload json.RawMessage) (json.RawMessage, string, error) {
+	if l.Encoding == "" || len(payload) <= l.CompressThreshold {
+		return payload, "", nil
+	}
+
+	compressed, err := compress(payload, l.Encoding)
+	if err != nil {
+		return nil, "", err
+	}
+
+	encoded, err := json.Marshal(base64.StdEncoding.EncodeToString(compressed))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode compressed payload: %w", err)
+	}
+	return encoded, l.Encoding, nil
+}
+
+// DecodePayload reverses the payload compression, payloads without encoding are returned unchanged
+func DecodePayload(payload json.RawMessage, encoding string) (json.RawMessage, error) {
+	if encoding == "" {
+		return payload, nil
+	}
+
+	var b64 string
+	if err := json.Unmarshal(payload, &b64); err != nil {
+		return nil, fmt.Errorf("failed to decode %s payload: %w", encoding, err)
+	}
+	compressed, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s payload: %w", encoding, err)
+	}
+
+	return decompress(compressed, encoding)
+}
+
+func compress(data []byte, encoding string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	switch encoding {
+	case EncodingGzip:
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, fmt.Errorf("failed to gzip payload: %w", err)
+		}
+		if err := w.Close(); err != nil {
+			return nil, fmt.Errorf("failed to gzip payload: %w", err)
+		}
+	case EncodingZstd:
+		w, err := zstd.NewWriter(&buf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create zstd writer: %w", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			w.Close()
+			return nil, fmt.Errorf("failed to zstd payload: %w", err)
+		}
+		if err := w.Close(); err != nil {
+			return nil, fmt.Errorf("failed to zstd payload: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown payload encoding: %s", encoding)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func decompress(data []byte, encoding string) ([]byte, error) {
+	var r io.Reader
+
+	switch encoding {
+	case EncodingGzip:
+		gr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read gzip payload: %w", err)
+		}
+		defer gr.Close()
+		r = gr
+	case EncodingZstd:
+		zr, err := zstd.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read zstd payload: %w", err)
+		}
+		defer zr.Close()
+		r = zr
+	default:
+		return nil, fmt.Errorf("unknown payload encoding: %s", encoding)
+	}
+
+	out, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress %s payload: %w", encoding, err)
+	}
+	return out, nil
+}
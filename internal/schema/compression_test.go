@@ -0,0 +1,106 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// bigPayload builds a product payload with many features and images
+func bigPayload(t *testing.T) json.RawMessage {
+	t.Helper()
+
+	p := &ProductPayload{ASIN: "B0TEST", Title: "Shirt", SizeTable: json.RawMessage(`{"sizes":["S","M","L"]}`)}
+	for i := 0; i < 200; i++ {
+		p.Features = append(p.Features, fmt.Sprintf("Feature %d %s", i, strings.Repeat("x", 40)))
+		p.Images = append(p.Images, fmt.Sprintf("https://m.media-amazon.com/images/I/%04d.jpg", i))
+	}
+
+	payload, err := EncodeProductPayload(p, VersionV2)
+	if err != nil {
+		t.Fatalf("EncodeProductPayload() error = %v", err)
+	}
+	return payload
+}
+
+func TestPayloadCompressionRoundTrip(t *testing.T) {
+	payload := bigPayload(t)
+
+	for _, encoding := range []string{EncodingGzip, EncodingZstd} {
+		limits := PayloadLimits{Encoding: encoding, CompressThreshold: 1024}
+		encoded, gotEncoding, dropped, err := limits.Apply(payload)
+		if err != nil {
+			t.Fatalf("%s: Apply() error = %v", encoding, err)
+		}
+		if gotEncoding != encoding || len(dropped) > 0 {
+			t.Errorf("%s: encoding = %q, dropped = %v", encoding, gotEncoding, dropped)
+		}
+		if len(encoded) >= len(payload) {
+			t.Errorf("%s: encoded %d bytes, want less than %d", encoding, len(encoded), len(payload))
+		}
+
+		for _, version := range []int{VersionV1, VersionV2} {
+			event := &Event{
+				ID:        "evt-1",
+				Type:      EventNewProductDetected,
+				Payload:   encoded,
+				Timestamp: time.Now(),
+				Metadata:  map[string]any{MetadataContentEncoding: encoding},
+			}
+			values, err := EncodeStreamValues(event, version)
+			if err != nil {
+				t.Fatalf("EncodeStreamValues(v%d) error = %v", version, err)
+			}
+			if values[MetadataContentEncoding] != encoding {
+				t.Errorf("v%d: content_encoding field = %v", version, values[MetadataContentEncoding])
+			}
+
+			decoded, err := DecodeStreamMessage(values)
+			if err != nil {
+				t.Fatalf("%s v%d: DecodeStreamMessage() error = %v", encoding, version, err)
+			}
+			if string(decoded.Payload) != string(payload) {
+				t.Errorf("%s v%d: decoded payload differs from original", encoding, version)
+			}
+		}
+	}
+}
+
+func TestPayloadBelowThresholdIsNotCompressed(t *testing.T) {
+	payload := json.RawMessage(`{"asin":"B0TEST"}`)
+
+	encoded, encoding, _, err := PayloadLimits{Encoding: EncodingGzip, CompressThreshold: 1024}.Apply(payload)
+	if err != nil || encoding != "" || string(encoded) != string(payload) {
+		t.Errorf("Apply() = %s, %q, %v; want payload unchanged", encoded, encoding, err)
+	}
+}
+
+func TestPayloadSizeLimitTruncates(t *testing.T) {
+	payload := bigPayload(t)
+
+	// Dropping features is enough to fit
+	limits := PayloadLimits{MaxSize: len(payload) / 2}
+	encoded, _, dropped, err := limits.Apply(payload)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if len(dropped) != 1 || dropped[0] != "features" {
+		t.Errorf("dropped = %v, want [features]", dropped)
+	}
+	if len(encoded) > limits.MaxSize {
+		t.Errorf("encoded %d bytes, limit %d", len(encoded), limits.MaxSize)
+	}
+
+	p, err := DecodeProductPayload(VersionV2, encoded)
+	if err != nil || p.ASIN != "B0TEST" || len(p.Features) != 0 || len(p.Images) != 200 || len(p.SizeTable) == 0 {
+		t.Errorf("truncated payload = %+v, err = %v", p, err)
+	}
+
+	// The core product never fits into 50 bytes
+	if _, _, _, err := (PayloadLimits{MaxSize: 50}).Apply(payload); !errors.Is(err, ErrPayloadTooLarge) {
+		t.Errorf("Apply() error = %v, want ErrPayloadTooLarge", err)
+	}
+}
@@ -0,0 +1,57 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Limits of caller metadata attached to jobs and requests, it travels with every event they emit
+const (
+	MaxMetadataKeys        = 20
+	MaxMetadataKeyLength   = 64
+	MaxMetadataValueLength = 256
+)
+
+// reservedMetadata are the metadata keys the relay sets itself
+var reservedMetadata = map[string]bool{
+	"source":                true,
+	"outbox_id":             true,
+	"retry_count":           true,
+	"target_stream":         true,
+	MetadataTraceID:         true,
+	MetadataReplayed:        true,
+	MetadataContentEncoding: true,
+}
+
+// ReservedMetadata reports whether key is set by the relay and cannot be used by callers
+func ReservedMetadata(key string) bool {
+	return reservedMetadata[key]
+}
+
+// ValidateMetadata checks caller metadata such as {"campaign_id": "summer-24"}: at most MaxMetadataKeys
+// keys, no empty, reserved or overlong keys and no overlong values
+func ValidateMetadata(metadata map[string]string) error {
+	if len(metadata) > MaxMetadataKeys {
+		return fmt.Errorf("metadata has %d keys, at most %d are allowed", len(metadata), MaxMetadataKeys)
+	}
+
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		switch {
+		case key == "":
+			return fmt.Errorf("metadata keys must not be empty")
+		case len(key) > MaxMetadataKeyLength:
+			return fmt.Errorf("metadata key %q is longer than %d bytes", key, MaxMetadataKeyLength)
+		case reservedMetadata[key]:
+			return fmt.Errorf("metadata key %q is reserved", key)
+		case len(metadata[key]) > MaxMetadataValueLength:
+			return fmt.Errorf("metadata value of %q is longer than %d bytes", key, MaxMetadataValueLength)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateMetadata(t *testing.T) {
+	many := make(map[string]string)
+	for i := 0; i <= MaxMetadataKeys; i++ {
+		many[strings.Repeat("k", i+1)] = "v"
+	}
+
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		wantErr  string
+	}{
+		{name: "none"},
+		{name: "campaign", metadata: map[string]string{"campaign_id": "summer-24", "channel": ""}},
+		{name: "empty key", metadata: map[string]string{"": "x"}, wantErr: "must not be empty"},
+		{name: "reserved key", metadata: map[string]string{MetadataTraceID: "x"}, wantErr: "reserved"},
+		{name: "long key", metadata: map[string]string{strings.Repeat("k", MaxMetadataKeyLength+1): "x"}, wantErr: "longer than"},
+		{name: "long value", metadata: map[string]string{"note": strings.Repeat("v", MaxMetadataValueLength+1)}, wantErr: "longer than"},
+		{name: "too many keys", metadata: many, wantErr: "at most"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMetadata(tt.metadata)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateMetadata() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateMetadata() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package schema
+
+import (
+	"sort"
+	"sync"
+)
+
+// Event types shared with tall-affiliate-common
+const (
+	EventProductDetected    = "01_PRODUCT_DETECTED"
+	EventNewProductDetected = "NEW_PRODUCT_DETECTED"
+	EventProductValidated   = "02A_PRODUCT_VALIDATED"
+	EventProductCreated     = "PRODUCT_CREATED"
+	// EventReviewsEnriched carries the fit summary derived from a product's reviews
+	EventReviewsEnriched = "PRODUCT_REVIEWS_ENRICHED"
+	// EventProductRetired announces a product whose listing was deleted on Amazon
+	EventProductRetired = "PRODUCT_RETIRED"
+	// EventSizeTableUpdated carries a size table corrected by hand
+	EventSizeTableUpdated = "SIZE_TABLE_UPDATED"
+	// Job lifecycle events carry the summary of a crawl job, see events.JobPayload
+	EventJobStarted   = "JOB_STARTED"
+	EventJobCompleted = "JOB_COMPLETED"
+	EventJobFailed    = "JOB_FAILED"
+	EventJobCancelled = "JOB_CANCELLED"
+	// Command results acknowledge the commands of the scraper control stream
+	EventCommandCompleted = "SCRAPER_COMMAND_COMPLETED"
+	EventCommandFailed    = "SCRAPER_COMMAND_FAILED"
+)
+
+// Registry tracks which schema versions are supported per event type
+type Registry struct {
+	mu       sync.RWMutex
+	versions map[string][]int
+}
+
+// NewRegistry creates a registry with the known product lifecycle events
+func NewRegistry() *Registry {
+	r := &Registry{versions: make(map[string][]int)}
+	r.Register(EventProductDetected, VersionV1, VersionV2)
+	r.Register(EventNewProductDetected, VersionV1, VersionV2)
+	r.Register(EventProductValidated, VersionV1, VersionV2)
+	r.Register(EventProductCreated, VersionV1)
+	r.Register(EventReviewsEnriched, VersionV2)
+	r.Register(EventProductRetired, VersionV2)
+	r.Register(EventSizeTableUpdated, VersionV2)
+	r.Register(EventJobStarted, VersionV2)
+	r.Register(EventJobCompleted, VersionV2)
+	r.Register(EventJobFailed, VersionV2)
+	r.Register(EventJobCancelled, VersionV2)
+	r.Register(EventCommandCompleted, VersionV2)
+	r.Register(EventCommandFailed, VersionV2)
+	return r
+}
+
+// Register adds supported versions for an event type
+func (r *Registry) Register(eventType string, versions ...int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing := r.versions[eventType]
+	for _, v := range versions {
+		if !containsVersion(existing, v) {
+			existing = append(existing, v)
+		}
+	}
+	sort.Ints(existing)
+	r.versions[eventType] = existing
+}
+
+// Supports reports whether the event type is known in the given version
+func (r *Registry) Supports(eventType string, version int) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return containsVersion(r.versions[eventType], version)
+}
+
+// Known reports whether the event type is registered at all
+func (r *Registry) Known(eventType string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.versions[eventType]
+	return ok
+}
+
+// Negotiate returns the highest supported version not above want, or 0 if none
+func (r *Registry) Negotiate(eventType string, want int) int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	best := 0
+	for _, v := range r.versions[eventType] {
+		if v <= want && v > best {
+			best = v
+		}
+	}
+	return best
+}
+
+func containsVersion(versions []int, version int) bool {
+	for _, v := range versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,396 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	// VersionV1 is the flat tall-affiliate-common layout (event_type + payload at stream level)
+	VersionV1 = 1
+	// VersionV2 is the relay layout with a JSON "data" envelope and explicit schema_version
+	VersionV2 = 2
+
+	// CurrentVersion is the version emitted when nothing else is configured
+	CurrentVersion = VersionV2
+
+	// MetadataTraceID is the metadata key and stream field carrying the trace ID of the emitting request or job
+	MetadataTraceID = "trace_id"
+
+	// MetadataReplayed is the metadata key marking events published again from the processed outbox
+	MetadataReplayed = "replayed"
+)
+
+// Event is the canonical event envelope shared with tall-affiliate-common
+type Event struct {
+	ID            string          `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload"`
+	Timestamp     time.Time       `json:"timestamp"`
+	Metadata      map[string]any  `json:"metadata,omitempty"`
+	SchemaVersion int             `json:"schema_version"`
+}
+
+// Price represents product pricing information in v2 payloads
+type Price struct {
+	Amount            float64 `json:"amount"`
+	Currency          string  `json:"currency"`
+	ReportingAmount   float64 `json:"reporting_amount,omitempty"`
+	ReportingCurrency string  `json:"reporting_currency,omitempty"`
+}
+
+// ProductPayloadV1 matches tall-affiliate-common ProductCreatedPayload
+type ProductPayloadV1 struct {
+	ASIN           string   `json:"asin"`
+	Title          string   `json:"title"`
+	Brand          string   `json:"brand,omitempty"`
+	Category       string   `json:"category,omitempty"`
+	Gender         string   `json:"gender,omitempty"`
+	CurrentPrice   float64  `json:"current_price,omitempty"`
+	Currency       string   `json:"currency,omitempty"`
+	DetailPageURL  string   `json:"detail_page_url,omitempty"`
+	ImageUrls      []string `json:"image_urls,omitempty"`
+	Features       []string `json:"features,omitempty"`
+	BrowseNodeID   string   `json:"browse_node_id,omitempty"`
+	BrowseNodeTags []string `json:"browse_node_tags,omitempty"`
+}
+
+// ProductPayloadV2 matches the NEW_PRODUCT_DETECTED payload emitted by the scraper
+type ProductPayloadV2 struct {
+	SchemaVersion  int               `json:"schema_version"`
+	EventID        string            `json:"event_id"`
+	EventType      string            `json:"event_type"`
+	Timestamp      time.Time         `json:"timestamp"`
+	ASIN           string            `json:"asin"`
+	Title          string            `json:"title"`
+	Brand          string            `json:"brand,omitempty"`
+	DetailPageURL  string            `json:"detail_page_url"`
+	Category       string            `json:"category,omitempty"`
+	CategoryCode   string            `json:"category_code,omitempty"`
+	BrowseNode     string            `json:"browse_node,omitempty"`
+	Price          *Price            `json:"price,omitempty"`
+	Rating         *float64          `json:"rating,omitempty"`
+	ReviewCount    *int              `json:"review_count,omitempty"`
+	Images         []string          `json:"images,omitempty"`
+	Features       []string          `json:"features,omitempty"`
+	Attributes     map[string]string `json:"attributes,omitempty"` // Product overview attributes by canonical key
+	AvailableSizes []string          `json:"available_sizes,omitempty"`
+	SizeTable      json.RawMessage   `json:"size_table,omitempty"`
+	FitFeedback    json.RawMessage   `json:"fit_feedback,omitempty"`
+	SizePrices     json.RawMessage   `json:"size_prices,omitempty"`
+	DataSources    map[string]string `json:"data_sources,omitempty"` // Field -> "scraper" or "pa-api"
+	Source         string            `json:"source"`
+}
+
+// ProductPayload is the version independent view of a product payload used by consumers
+type ProductPayload struct {
+	ASIN           string
+	Title          string
+	Brand          string
+	Category       string
+	CategoryCode   string // Internal category such as tshirt or hoodie, empty if unmapped
+	BrowseNode     string
+	DetailPageURL  string
+	Price          *Price
+	Images         []string
+	Features       []string
+	Attributes     map[string]string // Product overview attributes such as material and fit, v2 only
+	AvailableSizes []string
+	SizeTable      json.RawMessage // Kept raw to avoid depending on the database package
+	FitFeedback    json.RawMessage
+	SizePrices     json.RawMessage   // Size -> price, currency, availability and child ASIN
+	DataSources    map[string]string // Source of title, brand, price and images, "scraper" or "pa-api"
+}
+
+// DecodeProductPayload decodes a product payload of either version into the canonical view
+func DecodeProductPayload(version int, raw json.RawMessage) (*ProductPayload, error) {
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("empty payload")
+	}
+
+	// Unknown versions are sniffed from the field layout
+	if version != VersionV1 && version != VersionV2 {
+		version = detectPayloadVersion(raw)
+	}
+
+	switch version {
+	case VersionV1:
+		var v1 ProductPayloadV1
+		if err := json.Unmarshal(raw, &v1); err != nil {
+			return nil, fmt.Errorf("failed to decode v1 payload: %w", err)
+		}
+		p := &ProductPayload{
+			ASIN:          v1.ASIN,
+			Title:         v1.Title,
+			Brand:         v1.Brand,
+			Category:      v1.Category,
+			BrowseNode:    v1.BrowseNodeID,
+			DetailPageURL: v1.DetailPageURL,
+			Images:        v1.ImageUrls,
+			Features:      v1.Features,
+		}
+		if v1.CurrentPrice > 0 {
+			p.Price = &Price{Amount: v1.CurrentPrice, Currency: v1.Currency}
+		}
+		return p, nil
+
+	default:
+		var v2 ProductPayloadV2
+		if err := json.Unmarshal(raw, &v2); err != nil {
+			return nil, fmt.Errorf("failed to decode v2 payload: %w", err)
+		}
+		return &ProductPayload{
+			ASIN:           v2.ASIN,
+			Title:          v2.Title,
+			Brand:          v2.Brand,
+			Category:       v2.Category,
+			CategoryCode:   v2.CategoryCode,
+			BrowseNode:     v2.BrowseNode,
+			DetailPageURL:  v2.DetailPageURL,
+			Price:          v2.Price,
+			Images:         v2.Images,
+			Features:       v2.Features,
+			Attributes:     v2.Attributes,
+			AvailableSizes: v2.AvailableSizes,
+			SizeTable:      v2.SizeTable,
+			FitFeedback:    v2.FitFeedback,
+			SizePrices:     v2.SizePrices,
+			DataSources:    v2.DataSources,
+		}, nil
+	}
+}
+
+// detectPayloadVersion guesses the payload version from its field names
+func detectPayloadVersion(raw json.RawMessage) int {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return CurrentVersion
+	}
+
+	if v, ok := fields["schema_version"]; ok {
+		var version int
+		if json.Unmarshal(v, &version) == nil && version > 0 {
+			return version
+		}
+	}
+
+	for _, key := range []string{"current_price", "image_urls", "browse_node_id"} {
+		if _, ok := fields[key]; ok {
+			return VersionV1
+		}
+	}
+
+	return VersionV2
+}
+
+// EncodeStreamValues builds the Redis stream fields for an event in the requested layout
+func EncodeStreamValues(event *Event, version int) (map[string]interface{}, error) {
+	if version != VersionV1 {
+		version = VersionV2
+	}
+	event.SchemaVersion = version
+
+	var values map[string]interface{}
+	if version == VersionV1 {
+		values = map[string]interface{}{
+			"event_type":     event.Type,
+			"event_id":       event.ID,
+			"aggregate_id":   event.AggregateID,
+			"aggregate_type": event.AggregateType,
+			"timestamp":      event.Timestamp.Format(time.RFC3339),
+			"payload":        string(event.Payload),
+			"schema_version": strconv.Itoa(version),
+		}
+	} else {
+		dataJSON, err := json.Marshal(event)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal stream data: %w", err)
+		}
+
+		values = map[string]interface{}{
+			"data":           string(dataJSON),
+			"type":           event.Type,
+			"timestamp":      fmt.Sprintf("%d", event.Timestamp.UnixNano()),
+			"original_id":    event.ID,
+			"aggregate_id":   event.AggregateID,
+			"aggregate_type": event.AggregateType,
+			"event_type":     event.Type,
+			"schema_version": strconv.Itoa(version),
+		}
+	}
+
+	// Compressed payloads are flagged at stream level so consumers know before parsing
+	if encoding, ok := event.Metadata[MetadataContentEncoding].(string); ok && encoding != "" {
+		values[MetadataContentEncoding] = encoding
+	}
+	// The trace ID is a stream field as well, v1 messages carry no metadata
+	if traceID := event.TraceID(); traceID != "" {
+		values[MetadataTraceID] = traceID
+	}
+
+	return values, nil
+}
+
+// DecodeStreamMessage decodes Redis stream fields of either layout into an Event
+func DecodeStreamMessage(values map[string]interface{}) (*Event, error) {
+	event := &Event{}
+
+	// v2: full envelope in the "data" field
+	if dataStr, ok := values["data"].(string); ok && dataStr != "" {
+		if err := json.Unmarshal([]byte(dataStr), event); err != nil {
+			return nil, fmt.Errorf("failed to decode data field: %w", err)
+		}
+		if event.SchemaVersion == 0 {
+			event.SchemaVersion = VersionV2
+		}
+	}
+
+	// v1 (or partial v2): flat fields, accepting both event_type and type
+	if event.Type == "" {
+		event.Type = stringValue(values, "event_type")
+		if event.Type == "" {
+			event.Type = stringValue(values, "type")
+		}
+	}
+	if event.ID == "" {
+		event.ID = stringValue(values, "event_id")
+		if event.ID == "" {
+			event.ID = stringValue(values, "original_id")
+		}
+	}
+	if event.AggregateID == "" {
+		event.AggregateID = stringValue(values, "aggregate_id")
+		if event.AggregateID == "" {
+			event.AggregateID = stringValue(values, "asin")
+		}
+	}
+	if event.AggregateType == "" {
+		event.AggregateType = stringValue(values, "aggregate_type")
+	}
+	if len(event.Payload) == 0 {
+		if payload := stringValue(values, "payload"); payload != "" {
+			event.Payload = json.RawMessage(payload)
+		}
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = parseTimestamp(stringValue(values, "timestamp"))
+	}
+	if event.SchemaVersion == 0 {
+		if v, err := strconv.Atoi(stringValue(values, "schema_version")); err == nil {
+			event.SchemaVersion = v
+		} else {
+			event.SchemaVersion = VersionV1
+		}
+	}
+
+	if event.Type == "" {
+		return nil, fmt.Errorf("event type missing in stream message")
+	}
+
+	// Compressed payloads are decoded here so consumers always see plain JSON
+	encoding := stringValue(values, MetadataContentEncoding)
+	if encoding == "" {
+		encoding, _ = event.Metadata[MetadataContentEncoding].(string)
+	}
+	if encoding != "" {
+		payload, err := DecodePayload(event.Payload, encoding)
+		if err != nil {
+			return nil, err
+		}
+		event.Payload = payload
+		delete(event.Metadata, MetadataContentEncoding)
+	}
+
+	if traceID := stringValue(values, MetadataTraceID); traceID != "" && event.TraceID() == "" {
+		if event.Metadata == nil {
+			event.Metadata = make(map[string]any)
+		}
+		event.Metadata[MetadataTraceID] = traceID
+	}
+
+	return event, nil
+}
+
+// TraceID returns the trace ID from the event metadata, or "" when there is none
+func (e *Event) TraceID() string {
+	traceID, _ := e.Metadata[MetadataTraceID].(string)
+	return traceID
+}
+
+// stringValue returns a stream field as string
+func stringValue(values map[string]interface{}, key string) string {
+	v, ok := values[key]
+	if !ok || v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// parseTimestamp accepts RFC3339 and unix nanosecond timestamps
+func parseTimestamp(s string) time.Time {
+	if s == "" {
+		return time.Time{}
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t
+	}
+	if ns, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(0, ns)
+	}
+	return time.Time{}
+}
+
+// EncodeProductPayload encodes the canonical product view in the requested payload version
+func EncodeProductPayload(p *ProductPayload, version int) (json.RawMessage, error) {
+	var v any
+	if version == VersionV1 {
+		v1 := ProductPayloadV1{
+			ASIN:          p.ASIN,
+			Title:         p.Title,
+			Brand:         p.Brand,
+			Category:      p.Category,
+			BrowseNodeID:  p.BrowseNode,
+			DetailPageURL: p.DetailPageURL,
+			ImageUrls:     p.Images,
+			Features:      p.Features,
+		}
+		if p.Price != nil {
+			v1.CurrentPrice = p.Price.Amount
+			v1.Currency = p.Price.Currency
+		}
+		v = v1
+	} else {
+		v = ProductPayloadV2{
+			SchemaVersion:  VersionV2,
+			ASIN:           p.ASIN,
+			Title:          p.Title,
+			Brand:          p.Brand,
+			Category:       p.Category,
+			CategoryCode:   p.CategoryCode,
+			BrowseNode:     p.BrowseNode,
+			DetailPageURL:  p.DetailPageURL,
+			Price:          p.Price,
+			Images:         p.Images,
+			Features:       p.Features,
+			Attributes:     p.Attributes,
+			AvailableSizes: p.AvailableSizes,
+			SizeTable:      p.SizeTable,
+			FitFeedback:    p.FitFeedback,
+			SizePrices:     p.SizePrices,
+			DataSources:    p.DataSources,
+		}
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode v%d payload: %w", version, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,142 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDecodeProductPayloadVersions(t *testing.T) {
+	tests := []struct {
+		name      string
+		version   int
+		raw       string
+		wantPrice float64
+		wantImage string
+	}{
+		{
+			name:      "v1 tall-affiliate-common layout",
+			version:   VersionV1,
+			raw:       `{"asin":"B0TEST","title":"Shirt","current_price":19.99,"currency":"EUR","image_urls":["a.jpg"]}`,
+			wantPrice: 19.99,
+			wantImage: "a.jpg",
+		},
+		{
+			name:      "v2 scraper layout",
+			version:   VersionV2,
+			raw:       `{"schema_version":2,"asin":"B0TEST","title":"Shirt","price":{"amount":19.99,"currency":"EUR"},"images":["a.jpg"]}`,
+			wantPrice: 19.99,
+			wantImage: "a.jpg",
+		},
+		{
+			name:      "unknown version sniffed as v1",
+			version:   0,
+			raw:       `{"asin":"B0TEST","title":"Shirt","current_price":19.99,"image_urls":["a.jpg"]}`,
+			wantPrice: 19.99,
+			wantImage: "a.jpg",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := DecodeProductPayload(tt.version, json.RawMessage(tt.raw))
+			if err != nil {
+				t.Fatalf("DecodeProductPayload() error = %v", err)
+			}
+			if p.ASIN != "B0TEST" {
+				t.Errorf("ASIN = %q, want B0TEST", p.ASIN)
+			}
+			if p.Price == nil || p.Price.Amount != tt.wantPrice {
+				t.Errorf("Price = %+v, want %v", p.Price, tt.wantPrice)
+			}
+			if len(p.Images) != 1 || p.Images[0] != tt.wantImage {
+				t.Errorf("Images = %v, want [%s]", p.Images, tt.wantImage)
+			}
+		})
+	}
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+	payload, err := EncodeProductPayload(&ProductPayload{ASIN: "B0TEST", Title: "Shirt"}, VersionV2)
+	if err != nil {
+		t.Fatalf("EncodeProductPayload() error = %v", err)
+	}
+
+	for _, version := range []int{VersionV1, VersionV2} {
+		event := &Event{
+			ID:            "evt-1",
+			Type:          EventNewProductDetected,
+			AggregateType: "product",
+			AggregateID:   "B0TEST",
+			Payload:       payload,
+			Timestamp:     time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+			Metadata:      map[string]any{MetadataTraceID: "trace-1"},
+		}
+
+		values, err := EncodeStreamValues(event, version)
+		if err != nil {
+			t.Fatalf("EncodeStreamValues(v%d) error = %v", version, err)
+		}
+
+		decoded, err := DecodeStreamMessage(values)
+		if err != nil {
+			t.Fatalf("DecodeStreamMessage(v%d) error = %v", version, err)
+		}
+
+		if decoded.Type != EventNewProductDetected || decoded.AggregateID != "B0TEST" || decoded.ID != "evt-1" {
+			t.Errorf("v%d: unexpected event %+v", version, decoded)
+		}
+		if decoded.SchemaVersion != version {
+			t.Errorf("v%d: SchemaVersion = %d", version, decoded.SchemaVersion)
+		}
+		if decoded.TraceID() != "trace-1" {
+			t.Errorf("v%d: TraceID() = %q, want trace-1", version, decoded.TraceID())
+		}
+		if !decoded.Timestamp.Equal(event.Timestamp) {
+			t.Errorf("v%d: Timestamp = %v, want %v", version, decoded.Timestamp, event.Timestamp)
+		}
+
+		p, err := DecodeProductPayload(decoded.SchemaVersion, decoded.Payload)
+		if err != nil || p.Title != "Shirt" {
+			t.Errorf("v%d: payload = %+v, err = %v", version, p, err)
+		}
+	}
+}
+
+func TestDecodeStreamMessageLegacyFields(t *testing.T) {
+	event, err := DecodeStreamMessage(map[string]interface{}{
+		"type":    EventProductDetected,
+		"asin":    "B0LEGACY",
+		"payload": `{"asin":"B0LEGACY"}`,
+	})
+	if err != nil {
+		t.Fatalf("DecodeStreamMessage() error = %v", err)
+	}
+	if event.AggregateID != "B0LEGACY" {
+		t.Errorf("AggregateID = %q, want B0LEGACY", event.AggregateID)
+	}
+	if event.SchemaVersion != VersionV1 {
+		t.Errorf("SchemaVersion = %d, want %d", event.SchemaVersion, VersionV1)
+	}
+
+	if _, err := DecodeStreamMessage(map[string]interface{}{"payload": "{}"}); err == nil {
+		t.Error("Expected error for message without event type")
+	}
+}
+
+func TestRegistryNegotiate(t *testing.T) {
+	r := NewRegistry()
+
+	if got := r.Negotiate(EventNewProductDetected, VersionV2); got != VersionV2 {
+		t.Errorf("Negotiate(NEW_PRODUCT_DETECTED, 2) = %d, want 2", got)
+	}
+	if got := r.Negotiate(EventProductCreated, VersionV2); got != VersionV1 {
+		t.Errorf("Negotiate(PRODUCT_CREATED, 2) = %d, want 1", got)
+	}
+	if got := r.Negotiate("UNKNOWN", VersionV2); got != 0 {
+		t.Errorf("Negotiate(UNKNOWN, 2) = %d, want 0", got)
+	}
+	if r.Known("UNKNOWN") {
+		t.Error("Expected UNKNOWN event type to be unregistered")
+	}
+}
@@ -0,0 +1,67 @@
+// Package scrapeerr holds the errors shared by the browser, the scrapers, the parser and the database,
+// so workers and consumers can tell a missing size table from a blocked or failed navigation with
+// errors.Is no matter which package failed. The codes carry them across the HTTP API.
+package scrapeerr
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNoSizeTable means the page loaded but holds no size table that could be read
+	ErrNoSizeTable = errors.New("no size table found")
+	// ErrBlocked means Amazon refused to serve the page, e.g. a robot check or a marketplace cooldown
+	ErrBlocked = errors.New("blocked by Amazon")
+	// ErrCaptcha is the robot check kind of ErrBlocked
+	ErrCaptcha = fmt.Errorf("captcha challenge: %w", ErrBlocked)
+	// ErrProductNotFound means the product is unknown, to the database or because Amazon deleted the listing
+	ErrProductNotFound = errors.New("product not found")
+	// ErrNavigation means the page could not be loaded, the joined cause tells why
+	ErrNavigation = errors.New("navigation failed")
+)
+
+// Codes of the errors in API responses
+const (
+	CodeNoSizeTable     = "no_size_table"
+	CodeBlocked         = "blocked"
+	CodeCaptcha         = "captcha"
+	CodeProductNotFound = "product_not_found"
+	CodeNavigation      = "navigation"
+)
+
+// codes is ordered from the most to the least specific, a failed navigation may wrap a captcha
+var codes = []struct {
+	code string
+	err  error
+}{
+	{CodeCaptcha, ErrCaptcha},
+	{CodeBlocked, ErrBlocked},
+	{CodeProductNotFound, ErrProductNotFound},
+	{CodeNoSizeTable, ErrNoSizeTable},
+	{CodeNavigation, ErrNavigation},
+}
+
+// Code returns the code of the shared error err wraps, empty for other errors
+func Code(err error) string {
+	for _, c := range codes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+	return ""
+}
+
+// FromCode rebuilds an error from the code and message of an API response, it wraps the shared error
+// of the code. An unknown code gives a plain error, no code and no message give nil.
+func FromCode(code, message string) error {
+	for _, c := range codes {
+		if c.code == code {
+			return fmt.Errorf("%w: %s", c.err, message)
+		}
+	}
+	if message == "" {
+		return nil
+	}
+	return errors.New(message)
+}
@@ -0,0 +1,45 @@
+package scrapeerr
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{fmt.Errorf("%w: robot check", ErrCaptcha), CodeCaptcha},
+		{fmt.Errorf("%w after 3 retries: %w", ErrNavigation, ErrCaptcha), CodeCaptcha},
+		{fmt.Errorf("cooldown: %w", ErrBlocked), CodeBlocked},
+		{fmt.Errorf("%w: B0TEST", ErrProductNotFound), CodeProductNotFound},
+		{fmt.Errorf("size chart did not appear: %w", ErrNoSizeTable), CodeNoSizeTable},
+		{fmt.Errorf("%w after 3 retries: timeout", ErrNavigation), CodeNavigation},
+		{errors.New("boom"), ""},
+		{nil, ""},
+	}
+
+	for _, tt := range tests {
+		if got := Code(tt.err); got != tt.want {
+			t.Errorf("Code(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestFromCode(t *testing.T) {
+	err := FromCode(CodeCaptcha, "robot check requires solving a captcha")
+	if !errors.Is(err, ErrCaptcha) || !errors.Is(err, ErrBlocked) {
+		t.Errorf("FromCode(captcha) = %v, want ErrCaptcha wrapping ErrBlocked", err)
+	}
+	if err := FromCode(CodeNoSizeTable, ""); !errors.Is(err, ErrNoSizeTable) {
+		t.Errorf("FromCode(no_size_table) = %v, want ErrNoSizeTable", err)
+	}
+	if err := FromCode("teapot", "short and stout"); err == nil || Code(err) != "" {
+		t.Errorf("FromCode(unknown) = %v, want plain error", err)
+	}
+	if err := FromCode("", ""); err != nil {
+		t.Errorf("FromCode(\"\", \"\") = %v, want nil", err)
+	}
+}
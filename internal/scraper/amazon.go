@@ -4,54 +4,80 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"regexp"
 	"strings"
 	"time"
 
+	"github.com/maltedev/amazon-size-scraper/internal/asin"
 	"github.com/maltedev/amazon-size-scraper/internal/browser"
 	"github.com/maltedev/amazon-size-scraper/internal/models"
 	"github.com/maltedev/amazon-size-scraper/internal/parser"
+	"github.com/maltedev/amazon-size-scraper/internal/scrapeerr"
 	"github.com/playwright-community/playwright-go"
 )
 
-const (
-	amazonDEBaseURL = "https://www.amazon.de"
-	productURLPattern = `(?i)(?:https?://)?(?:www\.)?amazon\.de/.*?/dp/([A-Z0-9]{10})`
-)
+const amazonDEBaseURL = "https://www.amazon.de"
 
 type AmazonScraper struct {
-	browser    *browser.Browser
-	parser     parser.Parser
-	logger     *slog.Logger
-	rateLimit  time.Duration
-	lastScrape time.Time
+	browser     *browser.Browser
+	parser      parser.Parser
+	resolver    *asin.Resolver
+	marketplace string // Marketplace of bare ASINs and links that do not name one
+	logger      *slog.Logger
+	rateLimit   time.Duration
+	lastScrape  time.Time
 }
 
 func NewAmazonScraper(b *browser.Browser, p parser.Parser, logger *slog.Logger) *AmazonScraper {
 	return &AmazonScraper{
-		browser:   b,
-		parser:    p,
-		logger:    logger,
-		rateLimit: 5 * time.Second,
+		browser:     b,
+		parser:      p,
+		resolver:    asin.NewResolver(10 * time.Second),
+		marketplace: asin.DefaultMarketplace,
+		logger:      logger,
+		rateLimit:   5 * time.Second,
 	}
 }
 
+// SetMarketplace sets the marketplace ScrapeByASIN and links without a marketplace scrape from
+func (s *AmazonScraper) SetMarketplace(marketplace string) {
+	s.marketplace = marketplace
+}
+
 func (s *AmazonScraper) ScrapeProduct(ctx context.Context, url string) (*models.Product, error) {
-	asin, err := s.ExtractASIN(url)
+	return s.ScrapeByURL(ctx, url)
+}
+
+// ScrapeByURL scrapes the product a link points to: /dp/ and /gp/product/ URLs with or without ref
+// tags, sponsored /sspa/click redirects, short links and any marketplace. The link is resolved to an
+// ASIN and marketplace first, then the canonical product page is scraped.
+func (s *AmazonScraper) ScrapeByURL(ctx context.Context, rawURL string) (*models.Product, error) {
+	target, err := s.ResolveURL(ctx, rawURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract ASIN: %w", err)
+		return nil, err
 	}
-	
-	return s.ScrapeByASIN(ctx, asin)
+	return s.scrape(ctx, target)
+}
+
+// ResolveURL returns the ASIN and marketplace of a product link without visiting the product
+func (s *AmazonScraper) ResolveURL(ctx context.Context, rawURL string) (asin.Product, error) {
+	target, err := s.resolver.ResolveProduct(ctx, rawURL, s.marketplace)
+	if err != nil {
+		return asin.Product{}, fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	return target, nil
 }
 
-func (s *AmazonScraper) ScrapeByASIN(ctx context.Context, asin string) (*models.Product, error) {
+func (s *AmazonScraper) ScrapeByASIN(ctx context.Context, id string) (*models.Product, error) {
+	return s.scrape(ctx, asin.Product{ASIN: id, Marketplace: s.marketplace})
+}
+
+func (s *AmazonScraper) scrape(ctx context.Context, target asin.Product) (*models.Product, error) {
 	s.enforceRateLimit()
 	
-	url := fmt.Sprintf("%s/dp/%s", amazonDEBaseURL, asin)
-	s.logger.Info("scraping product", "asin", asin, "url", url)
+	url := target.URL()
+	s.logger.Info("scraping product", "asin", target.ASIN, "marketplace", target.Marketplace, "url", url)
 	
-	page, err := s.browser.NewPage()
+	page, err := s.browser.NewTaskPage(browser.TaskProduct)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create page: %w", err)
 	}
@@ -66,7 +92,7 @@ func (s *AmazonScraper) ScrapeByASIN(ctx context.Context, asin string) (*models.
 	}
 	
 	if blocked := s.checkIfBlocked(page); blocked {
-		return nil, ErrBlocked
+		return nil, scrapeerr.ErrCaptcha
 	}
 	
 	time.Sleep(2 * time.Second)
@@ -76,26 +102,24 @@ func (s *AmazonScraper) ScrapeByASIN(ctx context.Context, asin string) (*models.
 		return nil, fmt.Errorf("failed to get page content: %w", err)
 	}
 	
-	product, err := s.parser.ParseProductPage(html, asin)
+	product, err := s.parser.ParseProductPage(html, target.ASIN)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse product: %w", err)
 	}
 	
 	product.URL = url
-	product.ASIN = asin
+	product.ASIN = target.ASIN
+	product.Marketplace = target.Marketplace
 	
 	return product, nil
 }
 
 func (s *AmazonScraper) ExtractASIN(url string) (string, error) {
-	re := regexp.MustCompile(productURLPattern)
-	matches := re.FindStringSubmatch(url)
-	
-	if len(matches) < 2 {
+	id, ok := asin.Extract(url)
+	if !ok {
 		return "", ErrInvalidURL
 	}
-	
-	return matches[1], nil
+	return id, nil
 }
 
 func (s *AmazonScraper) Close() error {
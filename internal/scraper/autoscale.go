@@ -0,0 +1,207 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+)
+
+// Autoscaler defaults
+const (
+	DefaultAutoscaleInterval = 30 * time.Second
+	DefaultProductsPerWorker = 20
+	DefaultMaxErrorRate      = 0.5
+)
+
+// minErrorSamples is the number of scrapes in an interval below which the error rate is not trusted
+const minErrorSamples = 3
+
+// AutoscaleOptions bound the number of daemon workers and set when it changes
+type AutoscaleOptions struct {
+	Min               int           // Workers kept running even without pending products
+	Max               int           // Upper bound, each worker runs its own browser
+	Interval          time.Duration // Wait between scaling decisions
+	ProductsPerWorker int           // Pending products one worker is expected to handle
+	MaxErrorRate      float64       // Failed share of the scrapes in an interval above which workers are removed
+}
+
+// Decide returns the number of workers to run next, moving at most one step from current. A high error
+// rate usually means Amazon is blocking, so it removes a worker instead of adding one; otherwise the
+// target follows the pending products.
+func (o AutoscaleOptions) Decide(current, pending int, errorRate float64) int {
+	next := current
+	switch want := (pending + o.ProductsPerWorker - 1) / o.ProductsPerWorker; {
+	case errorRate > o.MaxErrorRate:
+		next--
+	case want > current:
+		next++
+	case want < current:
+		next--
+	}
+	return min(max(next, o.Min), o.Max)
+}
+
+func (o *AutoscaleOptions) normalize() {
+	if o.Min < 1 {
+		o.Min = 1
+	}
+	if o.Max < o.Min {
+		o.Max = o.Min
+	}
+	if o.Interval <= 0 {
+		o.Interval = DefaultAutoscaleInterval
+	}
+	if o.ProductsPerWorker <= 0 {
+		o.ProductsPerWorker = DefaultProductsPerWorker
+	}
+	if o.MaxErrorRate <= 0 {
+		o.MaxErrorRate = DefaultMaxErrorRate
+	}
+}
+
+// outcomes counts the scrapes of all workers of an autoscaler since the last decision
+type outcomes struct {
+	succeeded atomic.Int64
+	failed    atomic.Int64
+}
+
+func (o *outcomes) record(err error) {
+	if err != nil {
+		o.failed.Add(1)
+	} else {
+		o.succeeded.Add(1)
+	}
+}
+
+// errorRate returns the failed share of the scrapes since the last call and resets the counts, 0 with
+// too few scrapes to tell
+func (o *outcomes) errorRate() float64 {
+	succeeded, failed := o.succeeded.Swap(0), o.failed.Swap(0)
+	if succeeded+failed < minErrorSamples {
+		return 0
+	}
+	return float64(failed) / float64(succeeded+failed)
+}
+
+// NewWorkerFunc creates a product scraper with its own browser and the func that closes the browser
+type NewWorkerFunc func() (*ProductScraper, func(), error)
+
+type poolWorker struct {
+	cancel context.CancelFunc
+}
+
+// Autoscaler runs daemon workers and grows or shrinks their number between Min and Max by the pending
+// products and the error rate of the recent scrapes
+type Autoscaler struct {
+	db        *database.DB
+	opts      AutoscaleOptions
+	daemon    DaemonOptions
+	newWorker NewWorkerFunc
+	outcomes  outcomes
+	logger    *slog.Logger
+
+	workers []poolWorker // Running workers, the last one is removed first
+	wg      sync.WaitGroup
+}
+
+// NewAutoscaler creates an autoscaler starting workers with newWorker
+func NewAutoscaler(db *database.DB, opts AutoscaleOptions, daemon DaemonOptions, newWorker NewWorkerFunc) *Autoscaler {
+	opts.normalize()
+	return &Autoscaler{
+		db:        db,
+		opts:      opts,
+		daemon:    daemon,
+		newWorker: newWorker,
+		logger:    slog.Default().With("component", "autoscaler"),
+	}
+}
+
+// Run starts Min workers and rescales them every interval until ctx is cancelled, then waits for all of
+// them, including workers still draining after a scale down, to stop
+func (a *Autoscaler) Run(ctx context.Context) error {
+	defer a.wg.Wait()
+	defer a.stopAll()
+
+	for len(a.workers) < a.opts.Min {
+		if err := a.grow(ctx); err != nil {
+			return err
+		}
+	}
+	a.logger.InfoContext(ctx, "autoscaler started", "min", a.opts.Min, "max", a.opts.Max, "workers", len(a.workers))
+
+	ticker := time.NewTicker(a.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			a.rescale(ctx)
+		}
+	}
+}
+
+func (a *Autoscaler) rescale(ctx context.Context) {
+	counts, err := a.db.CountProductsByStatus(ctx)
+	if err != nil {
+		a.logger.ErrorContext(ctx, "failed to count pending products", "error", err)
+		return
+	}
+	pending := counts[database.StatusPending]
+	errorRate := a.outcomes.errorRate()
+
+	current := len(a.workers)
+	next := a.opts.Decide(current, pending, errorRate)
+	switch {
+	case next > current:
+		if err := a.grow(ctx); err != nil {
+			a.logger.ErrorContext(ctx, "failed to add worker", "error", err)
+			return
+		}
+	case next < current:
+		a.shrink()
+	default:
+		return
+	}
+	a.logger.InfoContext(ctx, "rescaled workers", "from", current, "to", len(a.workers), "pending", pending, "errorRate", errorRate)
+}
+
+// grow starts a worker with its own browser
+func (a *Autoscaler) grow(ctx context.Context) error {
+	ps, closeBrowser, err := a.newWorker()
+	if err != nil {
+		return fmt.Errorf("failed to create worker %d: %w", len(a.workers), err)
+	}
+	ps.outcomes = &a.outcomes
+
+	workerCtx, cancel := context.WithCancel(ctx)
+	a.workers = append(a.workers, poolWorker{cancel: cancel})
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		defer closeBrowser()
+		if err := ps.RunDaemon(workerCtx, a.daemon); err != nil {
+			a.logger.ErrorContext(ctx, "worker failed", "worker", ps.workerID, "error", err)
+		}
+	}()
+	return nil
+}
+
+// shrink stops the newest worker, its in-flight product gets the drain timeout to finish
+func (a *Autoscaler) shrink() {
+	last := len(a.workers) - 1
+	a.workers[last].cancel()
+	a.workers = a.workers[:last]
+}
+
+func (a *Autoscaler) stopAll() {
+	for _, w := range a.workers {
+		w.cancel()
+	}
+	a.workers = nil
+}
@@ -0,0 +1,54 @@
+package scraper
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAutoscaleOptions_Decide(t *testing.T) {
+	opts := AutoscaleOptions{Min: 1, Max: 4, ProductsPerWorker: 10, MaxErrorRate: 0.5}
+
+	tests := []struct {
+		name      string
+		current   int
+		pending   int
+		errorRate float64
+		want      int
+	}{
+		{"grows one step with backlog", 1, 100, 0, 2},
+		{"stops at max", 4, 100, 0, 4},
+		{"holds when backlog fits", 2, 15, 0, 2},
+		{"shrinks when backlog drains", 3, 5, 0, 2},
+		{"keeps min without backlog", 1, 0, 0, 1},
+		{"shrinks on high error rate despite backlog", 3, 100, 0.8, 2},
+		{"keeps min on high error rate", 1, 100, 0.8, 1},
+		{"raises to min", 0, 0, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := opts.Decide(tt.current, tt.pending, tt.errorRate); got != tt.want {
+				t.Errorf("Decide(%d, %d, %v) = %d, want %d", tt.current, tt.pending, tt.errorRate, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutcomes_ErrorRate(t *testing.T) {
+	var o outcomes
+	o.record(errors.New("blocked"))
+	o.record(nil)
+	if got := o.errorRate(); got != 0 {
+		t.Errorf("errorRate() = %v with too few scrapes, want 0", got)
+	}
+
+	o.record(errors.New("blocked"))
+	o.record(errors.New("blocked"))
+	o.record(errors.New("blocked"))
+	o.record(nil)
+	if got := o.errorRate(); got != 0.75 {
+		t.Errorf("errorRate() = %v, want 0.75", got)
+	}
+	if got := o.errorRate(); got != 0 {
+		t.Errorf("errorRate() = %v after reset, want 0", got)
+	}
+}
@@ -0,0 +1,215 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/browser"
+)
+
+// Defaults for product claims and the continuous crawl loop
+const (
+	DefaultLease        = 2 * time.Minute
+	DefaultPollInterval = 10 * time.Second
+	DefaultDrainTimeout = 60 * time.Second
+	DefaultMaxAttempts  = 3
+	DefaultRetryBackoff = 5 * time.Minute
+)
+
+// RetryPolicy decides when a product whose scrape failed is claimed again
+type RetryPolicy struct {
+	MaxAttempts int           // Failed scrapes before the product is marked failed
+	Backoff     time.Duration // Wait before the first retry, doubled per failed scrape
+}
+
+// DefaultRetryPolicy returns the retry policy of new scrapers
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: DefaultMaxAttempts, Backoff: DefaultRetryBackoff}
+}
+
+// DaemonOptions configures RunDaemon
+type DaemonOptions struct {
+	PollInterval time.Duration // Wait between claim attempts while no product is pending
+	DrainTimeout time.Duration // How long the in-flight product may take to finish after shutdown
+}
+
+// workerSeq numbers the scrapers of this process for their worker IDs
+var workerSeq atomic.Int64
+
+// WorkerID returns a worker ID unique to this process and index
+func WorkerID(index int) string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d-%d", host, os.Getpid(), index)
+}
+
+// SetClaimLease sets how long claimed products stay reserved without a heartbeat
+func (ps *ProductScraper) SetClaimLease(d time.Duration) {
+	if d > 0 {
+		ps.lease = d
+	}
+}
+
+// SetRetryPolicy sets how failed scrapes are retried, zero fields keep the current values
+func (ps *ProductScraper) SetRetryPolicy(p RetryPolicy) {
+	if p.MaxAttempts > 0 {
+		ps.retry.MaxAttempts = p.MaxAttempts
+	}
+	if p.Backoff > 0 {
+		ps.retry.Backoff = p.Backoff
+	}
+}
+
+// RunDaemon claims and scrapes products until ctx is cancelled, picking up products added after it
+// started. On shutdown the in-flight product gets DrainTimeout to finish before its claim is
+// released back to pending.
+func (ps *ProductScraper) RunDaemon(ctx context.Context, opts DaemonOptions) error {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+	if opts.DrainTimeout <= 0 {
+		opts.DrainTimeout = DefaultDrainTimeout
+	}
+	logger := ps.logger.With("worker", ps.workerID)
+	logger.InfoContext(ctx, "daemon started", "lease", ps.lease)
+
+	stop := ps.startHeartbeat(ctx)
+	defer stop()
+
+	for ctx.Err() == nil {
+		products, err := ps.db.ClaimPendingProducts(ctx, ps.workerID, ps.lease, 1)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.ErrorContext(ctx, "failed to claim product", "error", err)
+				browser.Sleep(ctx, opts.PollInterval)
+			}
+			continue
+		}
+		if len(products) == 0 {
+			logger.DebugContext(ctx, "no pending products, waiting", "interval", opts.PollInterval)
+			browser.Sleep(ctx, opts.PollInterval)
+			continue
+		}
+
+		product := products[0]
+		logger.DebugContext(ctx, "claimed product", "asin", product.ASIN, "priority", product.Priority)
+		ps.scrapeClaimed(ctx, product.ASIN, opts.DrainTimeout)
+	}
+
+	logger.InfoContext(ctx, "daemon stopped")
+	return nil
+}
+
+// scrapeClaimed scrapes a claimed product, letting it finish within drain after ctx is cancelled
+func (ps *ProductScraper) scrapeClaimed(ctx context.Context, asin string, drain time.Duration) {
+	scrapeCtx, cancel := drainContext(ctx, drain)
+	defer cancel()
+
+	err := ps.ScrapeProduct(scrapeCtx, asin)
+	if err != nil {
+		ps.logger.ErrorContext(ctx, "failed to scrape product", "asin", asin, "error", err)
+	}
+	if ps.outcomes != nil {
+		ps.outcomes.record(err)
+	}
+	ps.progress.Done(err)
+	ps.releaseClaim(ctx, asin, err)
+}
+
+// releaseClaim puts the product back to pending if the scrape left it in processing. After a failed
+// scrape the product backs off per the retry policy, so it is not claimed again right away.
+func (ps *ProductScraper) releaseClaim(ctx context.Context, asin string, scrapeErr error) {
+	// ctx may be cancelled already, releasing must still reach the database
+	releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+
+	// A scrape cut off by shutdown did not fail, the next worker picks the product up right away
+	if scrapeErr == nil || ctx.Err() != nil {
+		if err := ps.db.ReleaseProductClaim(releaseCtx, asin, ps.workerID); err != nil {
+			ps.logger.ErrorContext(ctx, "failed to release product claim", "asin", asin, "error", err)
+		}
+		return
+	}
+
+	failed, err := ps.db.FailProductClaim(releaseCtx, asin, ps.workerID, ps.retry.MaxAttempts, ps.retry.Backoff)
+	if err != nil {
+		ps.logger.ErrorContext(ctx, "failed to record failed scrape", "asin", asin, "error", err)
+		return
+	}
+	if failed {
+		ps.logger.WarnContext(ctx, "giving up on product after failed scrapes", "asin", asin, "attempts", ps.retry.MaxAttempts)
+	}
+}
+
+// startHeartbeat registers the worker and renews its claims every third of the lease, releasing
+// stale claims of crashed workers along the way. The returned func stops it and releases whatever
+// the worker still holds.
+func (ps *ProductScraper) startHeartbeat(ctx context.Context) func() {
+	beat := func(ctx context.Context) {
+		if err := ps.db.HeartbeatWorker(ctx, ps.workerID, ps.lease); err != nil {
+			ps.logger.WarnContext(ctx, "failed to send heartbeat", "worker", ps.workerID, "error", err)
+		}
+		if released, err := ps.db.ReleaseStaleClaims(ctx, ps.lease); err != nil {
+			ps.logger.WarnContext(ctx, "failed to release stale claims", "error", err)
+		} else if released > 0 {
+			ps.logger.InfoContext(ctx, "released stale claims", "count", released)
+		}
+	}
+
+	// Heartbeats continue while in-flight products drain after ctx is cancelled
+	hbCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	done := make(chan struct{})
+	beat(hbCtx)
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(ps.lease / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-hbCtx.Done():
+				return
+			case <-ticker.C:
+				beat(hbCtx)
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+
+		releaseCtx, cancelRelease := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+		defer cancelRelease()
+		if err := ps.db.ReleaseWorker(releaseCtx, ps.workerID); err != nil {
+			ps.logger.ErrorContext(ctx, "failed to release worker", "worker", ps.workerID, "error", err)
+		}
+	}
+}
+
+// drainContext returns a context that survives the cancellation of parent by drain, so the
+// in-flight product can finish during a graceful shutdown
+func drainContext(parent context.Context, drain time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(parent))
+	stop := context.AfterFunc(parent, func() {
+		timer := time.NewTimer(drain)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+			cancel()
+		case <-ctx.Done():
+		}
+	})
+
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
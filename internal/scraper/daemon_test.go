@@ -0,0 +1,36 @@
+package scraper
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDrainContext(t *testing.T) {
+	t.Run("outlives parent until drain timeout", func(t *testing.T) {
+		parent, cancelParent := context.WithCancel(context.Background())
+		ctx, cancel := drainContext(parent, 50*time.Millisecond)
+		defer cancel()
+
+		cancelParent()
+		select {
+		case <-ctx.Done():
+			t.Fatal("drain context cancelled together with parent")
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+			t.Fatal("drain context not cancelled after drain timeout")
+		}
+	})
+
+	t.Run("cancel stops it without parent", func(t *testing.T) {
+		ctx, cancel := drainContext(context.Background(), time.Hour)
+		cancel()
+		if ctx.Err() == nil {
+			t.Fatal("drain context not cancelled")
+		}
+	})
+}
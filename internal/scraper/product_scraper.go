@@ -4,39 +4,65 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"regexp"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/playwright-community/playwright-go"
+	"github.com/maltedev/amazon-size-scraper/internal/amazon-scraper/events"
 	"github.com/maltedev/amazon-size-scraper/internal/browser"
 	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/maltedev/amazon-size-scraper/internal/labels"
+	"github.com/maltedev/amazon-size-scraper/internal/lifecycle"
 	"github.com/maltedev/amazon-size-scraper/internal/models"
+	"github.com/maltedev/amazon-size-scraper/internal/numparse"
 	"github.com/maltedev/amazon-size-scraper/internal/parser"
+	"github.com/maltedev/amazon-size-scraper/internal/progress"
+	"github.com/maltedev/amazon-size-scraper/internal/stages"
+	logging "github.com/maltedev/amazon-size-scraper/pkg/logger"
 )
 
 type ProductScraper struct {
-	browser   *browser.Browser
-	db        *database.DB
-	parser    parser.Parser
-	logger    *slog.Logger
-	rateLimit time.Duration
+	browser     *browser.Browser
+	db          *database.DB
+	parser      parser.Parser
+	prioritizer *database.Prioritizer
+	labels      *labels.Dictionary
+	validator   *database.SizeTableValidator
+	states      *lifecycle.Machine
+	stages      stages.Flags
+	policy      database.MeasurementPolicy
+	logger      *slog.Logger
+	rateLimit   time.Duration
+	workerID    string        // Owner of the products this scraper claims
+	lease       time.Duration // Claims expire unless renewed by a heartbeat within this time
+	retry       RetryPolicy   // Backoff of products whose scrape failed
+	outcomes    *outcomes     // Scrape results reported to the autoscaler, nil without one
+	progress    *progress.Tracker
+	publisher   *events.Publisher // Announces retired products, nil to only mark them
 }
 
 func NewProductScraper(b *browser.Browser, db *database.DB) *ProductScraper {
 	return &ProductScraper{
-		browser:   b,
-		db:        db,
-		parser:    parser.NewAmazonParser(),
-		logger:    slog.Default().With("component", "product_scraper"),
-		rateLimit: 5 * time.Second,
+		browser:     b,
+		db:          db,
+		parser:      parser.NewAmazonParser(),
+		prioritizer: database.NewPrioritizer(),
+		labels:      labels.New(labels.LocaleDE),
+		validator:   database.DefaultSizeTableValidator(),
+		states:      lifecycle.Default(),
+		policy:      database.LengthMeasurementPolicy(),
+		logger:      slog.Default().With("component", "product_scraper"),
+		rateLimit:   5 * time.Second,
+		workerID:    WorkerID(int(workerSeq.Add(1))),
+		lease:       DefaultLease,
+		retry:       DefaultRetryPolicy(),
 	}
 }
 
 // ScrapeProduct scrapes size data from a single product
 func (ps *ProductScraper) ScrapeProduct(ctx context.Context, asin string) error {
-	ps.logger.Info("scraping product", "asin", asin)
+	ctx = logging.WithASIN(ctx, asin)
+	ps.logger.InfoContext(ctx, "scraping product", "asin", asin)
 	
 	// Get product from database
 	product, err := ps.db.GetProduct(ctx, asin)
@@ -44,66 +70,77 @@ func (ps *ProductScraper) ScrapeProduct(ctx context.Context, asin string) error
 		return fmt.Errorf("failed to get product: %w", err)
 	}
 	if product == nil {
-		return fmt.Errorf("product not found: %s", asin)
+		return fmt.Errorf("%w: %s", ErrProductNotFound, asin)
 	}
 	
 	// Skip if already completed
 	if product.Status == database.StatusCompleted {
-		ps.logger.Info("product already scraped", "asin", asin)
+		ps.logger.InfoContext(ctx, "product already scraped", "asin", asin)
 		return nil
 	}
 	
-	page, err := ps.browser.NewPage()
+	page, err := ps.browser.NewTaskPage(browser.TaskProduct)
 	if err != nil {
 		return fmt.Errorf("failed to create page: %w", err)
 	}
 	defer page.Close()
+
+	// Every stage is timed, optional stages disabled by the deployment are skipped
+	timer := ps.stages.NewTimer()
 	
 	// Navigate to product page
-	if err := ps.browser.NavigateWithRetry(page, product.URL, 3); err != nil {
+	if err := timer.Run(stages.Navigate, func() error {
+		return ps.browser.NavigateWithRetry(page, product.URL, 3)
+	}); err != nil {
+		if browser.IsProductGone(err) {
+			ps.retireProduct(ctx, asin, err)
+			return nil // Not an error, the listing was deleted
+		}
 		ps.updateProductError(ctx, asin, fmt.Sprintf("Navigation failed: %v", err))
 		return fmt.Errorf("failed to navigate: %w", err)
 	}
 	
 	// Add human-like behavior
-	ps.browser.HumanizeInteraction(page)
+	timer.Run(stages.Humanize, func() error { return ps.browser.HumanizeTask(ctx, page, browser.TaskProduct) })
 	
 	// Look for size table button
-	sizeTable, err := ps.extractSizeTable(page)
+	var sizeTable *database.SizeTable
+	err = timer.Run(stages.SizeTable, func() error {
+		var err error
+		sizeTable, err = ps.extractSizeTable(ctx, page)
+		return err
+	})
 	if err != nil {
-		ps.logger.Warn("no size table found", "asin", asin, "error", err)
-		ps.updateProductError(ctx, asin, "No size table found")
+		ps.logger.WarnContext(ctx, "no size table found", "asin", asin, "error", err)
+		ps.updateProductFailure(ctx, asin, "No size table found", page)
 		return nil // Not an error, just no size data
 	}
 	
 	// Extract dimensions from size table
-	ps.logger.Debug("size table contents", "sizes", sizeTable.Sizes, "measurements", sizeTable.Measurements)
-	
-	// Check if any size has length measurement
-	hasLength := false
-	for _, measurements := range sizeTable.Measurements {
-		if length, ok := measurements["length"]; ok && length > 0 {
-			hasLength = true
-			break
-		}
-	}
+	ps.logger.DebugContext(ctx, "size table contents", "sizes", sizeTable.Sizes, "measurements", sizeTable.Measurements)
 	
-	// Skip products that don't have length measurements
-	if !hasLength {
-		ps.logger.Info("skipping product - no length measurement found", "asin", asin)
-		ps.updateProductError(ctx, asin, "No length measurement in size table")
+	// Skip products whose size table lacks the measurements the policy requires
+	if !ps.policy.Accepts(sizeTable) {
+		ps.logger.InfoContext(ctx, "skipping product - size table missing required measurements", "asin", asin, "policy", ps.policy)
+		ps.updateProductFailure(ctx, asin, "Size table missing required measurements", page)
 		return nil
 	}
 	
 	// Extract material information
-	materialComposition, materialFullText, err := ps.extractMaterial(page)
+	var materialComposition *models.MaterialComposition
+	var materialFullText string
+	err = timer.Run(stages.Material, func() error {
+		var err error
+		materialComposition, materialFullText, err = ps.extractMaterial(page)
+		return err
+	})
 	if err != nil {
-		ps.logger.Warn("failed to extract material", "asin", asin, "error", err)
+		ps.logger.WarnContext(ctx, "failed to extract material", "asin", asin, "error", err)
 		// Continue without material data - not a fatal error
 		materialComposition = nil
 		materialFullText = ""
-	} else {
-		ps.logger.Info("extracted material", "asin", asin,
+	} else if ps.stages.Enabled(stages.Material) {
+		ps.logger.InfoContext(ctx, "extracted material", "asin", asin,
 			"hasComposition", materialComposition != nil,
 			"fullTextLength", len(materialFullText))
 	}
@@ -113,9 +150,41 @@ func (ps *ProductScraper) ScrapeProduct(ctx context.Context, asin string) error
 		return fmt.Errorf("failed to update product with material and size: %w", err)
 	}
 
-	ps.logger.Info("successfully scraped product", "asin", asin,
+	// Record where the stored size table and material came from
+	provenance := database.Provenance{}
+	now := time.Now()
+	provenance.RecordSizeTable(sizeTable, now)
+	if materialComposition != nil || materialFullText != "" {
+		provenance.Record(database.FieldMaterial, database.ProvenanceRegex, "", now)
+	}
+	if err := ps.db.UpdateProductProvenance(ctx, asin, provenance); err != nil {
+		ps.logger.WarnContext(ctx, "failed to store provenance", "asin", asin, "error", err)
+	}
+
+	// Store validation report for quality scoring, the size table is kept either way
+	report := ps.validator.WithPolicy(ps.policy).Validate(sizeTable)
+	if err := ps.db.UpdateProductValidation(ctx, asin, report); err != nil {
+		ps.logger.WarnContext(ctx, "failed to store validation report", "asin", asin, "error", err)
+	}
+
+	// Flatten into per-size rows for downstream matching
+	if err := ps.db.SaveSizeMeasurements(ctx, asin, sizeTable); err != nil {
+		ps.logger.WarnContext(ctx, "failed to store size measurements", "asin", asin, "error", err)
+	}
+
+	if err := ps.db.UpdateProductStageTimings(ctx, asin, timer.Timings()); err != nil {
+		ps.logger.WarnContext(ctx, "failed to store stage timings", "asin", asin, "error", err)
+	}
+
+	if err := ps.db.UpdateProductMeasurementPolicy(ctx, asin, ps.policy.Apply(sizeTable)); err != nil {
+		ps.logger.WarnContext(ctx, "failed to store measurement policy", "asin", asin, "error", err)
+	}
+
+	ps.logger.InfoContext(ctx, "successfully scraped product", "asin", asin,
+		"qualityScore", report.Score,
 		"sizeCount", len(sizeTable.Sizes),
-		"hasMaterial", materialComposition != nil)
+		"hasMaterial", materialComposition != nil,
+		"duration_ms", timer.Timings().Total())
 	
 	// Rate limiting
 	time.Sleep(ps.rateLimit)
@@ -146,7 +215,7 @@ func (ps *ProductScraper) extractMaterial(page playwright.Page) (*models.Materia
 }
 
 // extractSizeTable finds and extracts the size table from the product page
-func (ps *ProductScraper) extractSizeTable(page playwright.Page) (*database.SizeTable, error) {
+func (ps *ProductScraper) extractSizeTable(ctx context.Context, page playwright.Page) (*database.SizeTable, error) {
 	// Use JavaScript to find and click size table link
 	clicked, err := page.Evaluate(`() => {
 		const links = document.querySelectorAll('a');
@@ -165,45 +234,23 @@ func (ps *ProductScraper) extractSizeTable(page playwright.Page) (*database.Size
 	}
 	
 	if !clicked.(bool) {
+		// Some listings render the size table in the description or A+ content instead
+		if sizeTable := ps.extractInlineSizeTable(page); sizeTable != nil {
+			return sizeTable, nil
+		}
 		return nil, fmt.Errorf("size table button not found")
 	}
 	
-	ps.logger.Info("clicked size table button")
-	
-	// Wait for modal/popup to appear
-	time.Sleep(3 * time.Second)
-	
-	// Extract table data using JavaScript
-	tableData, err := page.Evaluate(`() => {
-		const tables = document.querySelectorAll('.a-popover-content table, .a-modal-content table, [id*="popover"] table');
-		if (tables.length === 0) {
-			return null;
-		}
-		
-		const table = tables[0];
-		const data = {
-			headers: [],
-			rows: []
-		};
-		
-		// Get all rows
-		for (let i = 0; i < table.rows.length; i++) {
-			const row = table.rows[i];
-			const rowData = [];
-			for (let j = 0; j < row.cells.length; j++) {
-				rowData.push(row.cells[j].textContent.trim());
-			}
-			
-			if (i === 0) {
-				data.headers = rowData;
-			} else {
-				data.rows.push(rowData);
-			}
-		}
-		
-		return data;
-	}`)
+	ps.logger.InfoContext(ctx, "clicked size table button")
 	
+	// Wait for the chart in any registered layout instead of sleeping a fixed time
+	chart, err := ps.browser.WaitForSizeChart(ctx, page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wait for size chart: %w", err)
+	}
+	ps.logger.DebugContext(ctx, "size chart opened", "layout", chart.Layout.Name)
+
+	tableData, err := chart.ExtractTable()
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract table data: %w", err)
 	}
@@ -212,10 +259,10 @@ func (ps *ProductScraper) extractSizeTable(page playwright.Page) (*database.Size
 		return nil, fmt.Errorf("size table not found in modal")
 	}
 	
-	ps.logger.Info("extracted table data")
+	ps.logger.InfoContext(ctx, "extracted table data")
 	
 	// Parse the JavaScript data into our structure
-	ps.logger.Debug("raw table data", "data", tableData)
+	ps.logger.DebugContext(ctx, "raw table data", "data", tableData)
 	return ps.parseJSTableData(tableData)
 }
 
@@ -347,6 +394,30 @@ func (ps *ProductScraper) parseJSTableData(data interface{}) (*database.SizeTabl
 	return sizeTable, nil
 }
 
+// extractInlineSizeTable parses the first size table with measurements in the product description or A+ content
+func (ps *ProductScraper) extractInlineSizeTable(page playwright.Page) *database.SizeTable {
+	tables, err := browser.FindInlineSizeTables(page)
+	if err != nil {
+		ps.logger.Warn("failed to find inline size tables", "error", err)
+		return nil
+	}
+
+	for _, table := range tables {
+		sizeTable, err := ps.parseJSTableData(table.Data)
+		if err != nil || len(sizeTable.Sizes) == 0 || !sizeTable.HasMeasurements() {
+			continue
+		}
+
+		sizeTable.Source = database.SizeTableSourceInline
+		if table.Section == browser.SectionAPlus {
+			sizeTable.Source = database.SizeTableSourceAPlus
+		}
+		ps.logger.Info("extracted inline size table", "source", sizeTable.Source)
+		return sizeTable
+	}
+	return nil
+}
+
 // isSizeLabel checks if a string is a size label
 func isSizeLabel(s string) bool {
 	s = strings.ToUpper(strings.TrimSpace(s))
@@ -416,102 +487,129 @@ func (ps *ProductScraper) parseTable(table playwright.Locator) (*database.SizeTa
 
 // normalizeLabel normalizes measurement labels to standard names
 func (ps *ProductScraper) normalizeLabel(label string) string {
-	label = strings.ToLower(label)
-	
-	// Map German labels to standard names
-	mappings := map[string]string{
-		"länge":       "length",
-		"breite":      "width",
-		"brustumfang": "chest",
-		"brust":       "chest",
-		"schulter":    "shoulder",
-		"ärmel":       "sleeve",
-		"höhe":        "height",
-		"taille":      "waist",
-		"hüfte":       "hip",
-	}
-	
-	for german, english := range mappings {
-		if strings.Contains(label, german) {
-			return english
-		}
+	if ps.labels == nil {
+		ps.labels = labels.New(labels.LocaleDE)
 	}
-	
-	// Handle special cases
-	if strings.Contains(label, "länge") || strings.Contains(label, "laenge") {
-		return "length"
-	}
-	
-	return label
+	return ps.labels.Normalize(label)
+}
+
+// SetLabels sets the measurement label dictionary used to normalize size table labels
+func (ps *ProductScraper) SetLabels(d *labels.Dictionary) {
+	ps.labels = d
+}
+
+// SetStages sets which optional extraction stages run, all of them by default
+func (ps *ProductScraper) SetStages(f stages.Flags) {
+	ps.stages = f
+}
+
+// SetMeasurementPolicy sets which size tables are kept, only a length is required by default
+func (ps *ProductScraper) SetMeasurementPolicy(p database.MeasurementPolicy) {
+	ps.policy = p
+}
+
+// SetPublisher publishes PRODUCT_RETIRED for products whose listing was deleted
+func (ps *ProductScraper) SetPublisher(p *events.Publisher) {
+	ps.publisher = p
+}
+
+// SetProgress reports every scraped product to a progress tracker
+func (ps *ProductScraper) SetProgress(t *progress.Tracker) {
+	ps.progress = t
 }
 
-// parseValue extracts numeric value from text
+// parseValue extracts numeric value from text, ranges (e.g., "84 - 94") count with their average
 func (ps *ProductScraper) parseValue(text string) float64 {
-	// Handle ranges (e.g., "84 - 94") by taking the average
-	if strings.Contains(text, "-") {
-		parts := strings.Split(text, "-")
-		if len(parts) == 2 {
-			val1 := ps.parseValue(parts[0])
-			val2 := ps.parseValue(parts[1])
-			if val1 > 0 && val2 > 0 {
-				return (val1 + val2) / 2
-			}
-		}
-	}
-	
-	// Remove all non-numeric characters except comma and dot
-	re := regexp.MustCompile(`[^\d,.]`)
-	cleaned := re.ReplaceAllString(text, "")
-	
-	// Replace German decimal comma with dot
-	cleaned = strings.Replace(cleaned, ",", ".", 1)
-	
-	// Parse the value
-	value, err := strconv.ParseFloat(cleaned, 64)
+	return numparse.Parser{Locale: numparse.DE, Range: numparse.RangeAvg}.Value(text)
+}
+
+
+// retireProduct takes a product whose listing is gone out of the pending selection for good
+func (ps *ProductScraper) retireProduct(ctx context.Context, asin string, cause error) {
+	reason := cause.Error()
+	retired, err := ps.db.RetireProduct(ctx, asin, reason)
 	if err != nil {
-		return 0
+		ps.logger.ErrorContext(ctx, "failed to retire product", "asin", asin, "error", err)
+		return
+	}
+	ps.logger.InfoContext(ctx, "retired product, listing gone", "asin", asin, "reason", reason)
+	if !retired || ps.publisher == nil {
+		return
+	}
+	if err := ps.publisher.PublishProductRetired(ctx, &events.ProductRetiredPayload{ASIN: asin, Reason: reason}); err != nil {
+		ps.logger.ErrorContext(ctx, "failed to publish product retirement", "asin", asin, "error", err)
 	}
-	
-	return value
 }
 
-
 // updateProductError updates the product status with an error
 func (ps *ProductScraper) updateProductError(ctx context.Context, asin, errorMsg string) {
-	if err := ps.db.UpdateProductStatus(ctx, asin, database.StatusFailed, errorMsg); err != nil {
-		ps.logger.Error("failed to update product error status", "asin", asin, "error", err)
+	if _, err := ps.db.TransitionProduct(ctx, ps.states, asin, lifecycle.Fail, lifecycle.Facts{Reason: errorMsg}); err != nil {
+		ps.logger.ErrorContext(ctx, "failed to update product error status", "asin", asin, "error", err)
+	}
+}
+
+// updateProductFailure captures page diagnostics and stores them with the error
+func (ps *ProductScraper) updateProductFailure(ctx context.Context, asin, errorMsg string, page playwright.Page) {
+	diag, err := ps.browser.CaptureFailure(page, asin)
+	if err != nil {
+		ps.logger.WarnContext(ctx, "failed to capture diagnostics", "asin", asin, "error", err)
+	}
+	if diag == nil {
+		ps.updateProductError(ctx, asin, errorMsg)
+		return
+	}
+
+	if err := ps.db.UpdateProductFailure(ctx, asin, errorMsg, diag.ScreenshotPath, diag.DOMSnippetPath); err != nil {
+		ps.logger.ErrorContext(ctx, "failed to update product error status", "asin", asin, "error", err)
 	}
 }
 
-// ScrapeAllPending scrapes all pending products
+// ScrapeAllPending claims and scrapes pending products in batches of limit, highest priority score
+// first, until none are left. Claims make concurrent scrapers split the products instead of
+// scraping the same ASINs.
 func (ps *ProductScraper) ScrapeAllPending(ctx context.Context, limit int) error {
+	// Rescore pending products so the most promising ones are scraped first
+	scored, err := ps.db.UpdatePriorityScores(ctx, ps.prioritizer)
+	if err != nil {
+		ps.logger.WarnContext(ctx, "failed to update priority scores", "error", err)
+	} else {
+		ps.logger.InfoContext(ctx, "updated priority scores", "count", scored)
+	}
+
+	stop := ps.startHeartbeat(ctx)
+	defer stop()
+
 	for {
-		// Get pending products
-		products, err := ps.db.GetPendingProducts(ctx, limit)
+		// Claim pending products
+		products, err := ps.db.ClaimPendingProducts(ctx, ps.workerID, ps.lease, limit)
 		if err != nil {
-			return fmt.Errorf("failed to get pending products: %w", err)
+			return fmt.Errorf("failed to claim pending products: %w", err)
 		}
-		
+
 		if len(products) == 0 {
-			ps.logger.Info("no pending products found")
+			ps.logger.InfoContext(ctx, "no pending products found")
 			break
 		}
-		
-		ps.logger.Info("found pending products", "count", len(products))
-		
+
+		ps.logger.InfoContext(ctx, "claimed pending products", "count", len(products))
+
 		// Scrape each product
 		for _, product := range products {
 			select {
 			case <-ctx.Done():
 				return ctx.Err()
 			default:
-				if err := ps.ScrapeProduct(ctx, product.ASIN); err != nil {
-					ps.logger.Error("failed to scrape product", "asin", product.ASIN, "error", err)
+				ps.logger.DebugContext(ctx, "scraping pending product", "asin", product.ASIN, "priority", product.Priority)
+				err := ps.ScrapeProduct(ctx, product.ASIN)
+				if err != nil {
+					ps.logger.ErrorContext(ctx, "failed to scrape product", "asin", product.ASIN, "error", err)
 					// Continue with next product
 				}
+				ps.progress.Done(err)
+				ps.releaseClaim(ctx, product.ASIN, err)
 			}
 		}
 	}
-	
+
 	return nil
-}
\ No newline at end of file
+}
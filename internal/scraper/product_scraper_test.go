@@ -0,0 +1,71 @@
+package scraper
+
+import (
+	"context"
+
+	"testing"
+
+	"github.com/maltedev/amazon-size-scraper/internal/amazontest"
+)
+
+func TestProductScraper_ExtractSizeTable(t *testing.T) {
+	server := amazontest.NewServer()
+	defer server.Close()
+	for _, p := range amazontest.InlineChartProducts() {
+		server.AddProduct(p)
+	}
+	b := amazontest.NewBrowser(t)
+
+	ps := NewProductScraper(b, nil)
+
+	tests := []struct {
+		name    string
+		asin    string
+		wantErr bool
+		sizes   int
+		size    string
+		length  float64
+		chest   float64
+	}{
+		{"sizes in header row", amazontest.ASINVerticalChart, false, 4, "M", 78, 102},
+		{"sizes in first column", amazontest.ASINHorizontalChart, false, 3, "L", 81, 106},
+		{"no size chart", amazontest.ASINNoChart, true, 0, "", 0, 0},
+		{"description table", amazontest.ASINDescriptionChart, false, 3, "XL", 86, 114},
+		{"A+ table", amazontest.ASINAPlusChart, false, 2, "L", 79, 110},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := b.NewPage()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer page.Close()
+
+			if err := b.NavigateWithRetry(page, server.ProductURL(tt.asin), 1); err != nil {
+				t.Fatalf("navigate: %v", err)
+			}
+
+			st, err := ps.extractSizeTable(context.Background(), page)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error for product without size chart")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("extractSizeTable() error = %v", err)
+			}
+
+			if len(st.Sizes) != tt.sizes {
+				t.Errorf("sizes = %v, want %d", st.Sizes, tt.sizes)
+			}
+			if got := st.Measurements[tt.size]["length"]; got != tt.length {
+				t.Errorf("%s length = %v, want %v", tt.size, got, tt.length)
+			}
+			if got := st.Measurements[tt.size]["chest"]; got != tt.chest {
+				t.Errorf("%s chest = %v, want %v", tt.size, got, tt.chest)
+			}
+		})
+	}
+}
@@ -5,19 +5,22 @@ import (
 	"errors"
 	
 	"github.com/maltedev/amazon-size-scraper/internal/models"
+	"github.com/maltedev/amazon-size-scraper/internal/parser"
+	"github.com/maltedev/amazon-size-scraper/internal/scrapeerr"
 )
 
 var (
 	ErrInvalidURL       = errors.New("invalid Amazon URL")
-	ErrProductNotFound  = errors.New("product not found")
-	ErrDimensionsNotFound = errors.New("dimensions not found")
+	ErrProductNotFound  = scrapeerr.ErrProductNotFound
+	ErrDimensionsNotFound = parser.ErrDimensionsNotFound
 	ErrRateLimited      = errors.New("rate limited by Amazon")
-	ErrBlocked          = errors.New("blocked by Amazon anti-bot")
+	ErrBlocked          = scrapeerr.ErrBlocked
 )
 
 type Scraper interface {
 	ScrapeProduct(ctx context.Context, url string) (*models.Product, error)
 	ScrapeByASIN(ctx context.Context, asin string) (*models.Product, error)
+	ScrapeByURL(ctx context.Context, url string) (*models.Product, error)
 	ExtractASIN(url string) (string, error)
 	Close() error
 }
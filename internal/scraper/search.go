@@ -42,7 +42,7 @@ func (s *SearchScraper) ScrapeSearchResults(ctx context.Context, searchURL strin
 	
 	s.logger.Info("scraping search results", "url", searchURL)
 	
-	page, err := s.browser.NewPage()
+	page, err := s.browser.NewTaskPage(browser.TaskSearch)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create page: %w", err)
 	}
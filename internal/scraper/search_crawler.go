@@ -4,43 +4,65 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/playwright-community/playwright-go"
+	"github.com/maltedev/amazon-size-scraper/internal/brand"
 	"github.com/maltedev/amazon-size-scraper/internal/browser"
 	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/maltedev/amazon-size-scraper/internal/numparse"
+	"github.com/maltedev/amazon-size-scraper/internal/progress"
 )
 
 type SearchCrawler struct {
-	browser    *browser.Browser
-	db         *database.DB
-	logger     *slog.Logger
-	rateLimit  time.Duration
+	browser     *browser.Browser
+	db          *database.DB
+	prioritizer *database.Prioritizer
+	logger      *slog.Logger
+	rateLimit   time.Duration
+	baseURL     string
+	progress    *progress.Tracker
 }
 
 type ProductListing struct {
-	ASIN     string
-	Title    string
-	URL      string
-	Brand    string
-	Category string
+	ASIN        string
+	Title       string
+	URL         string
+	Brand       string
+	Category    string
+	Rating      float64
+	ReviewCount int
 }
 
 func NewSearchCrawler(b *browser.Browser, db *database.DB) *SearchCrawler {
 	return &SearchCrawler{
-		browser:   b,
-		db:        db,
-		logger:    slog.Default().With("component", "search_crawler"),
-		rateLimit: 5 * time.Second,
+		browser:     b,
+		db:          db,
+		prioritizer: database.NewPrioritizer(),
+		logger:      slog.Default().With("component", "search_crawler"),
+		rateLimit:   5 * time.Second,
+		baseURL:     amazonDEBaseURL,
 	}
 }
 
+// SetProgress reports crawled pages and found products to a progress tracker
+func (sc *SearchCrawler) SetProgress(t *progress.Tracker) {
+	sc.progress = t
+}
+
+// SetBaseURL overrides the marketplace origin used for the homepage and product links
+func (sc *SearchCrawler) SetBaseURL(baseURL string) {
+	sc.baseURL = strings.TrimRight(baseURL, "/")
+}
+
 // CrawlSearch crawls all products from a search URL
 func (sc *SearchCrawler) CrawlSearch(ctx context.Context, searchURL string) error {
 	sc.logger.Info("starting search crawl", "url", searchURL)
 	
-	page, err := sc.browser.NewPage()
+	page, err := sc.browser.NewTaskPage(browser.TaskSearch)
 	if err != nil {
 		return fmt.Errorf("failed to create page: %w", err)
 	}
@@ -48,7 +70,7 @@ func (sc *SearchCrawler) CrawlSearch(ctx context.Context, searchURL string) erro
 	
 	// First navigate to Amazon.de to handle bot check
 	sc.logger.Info("navigating to Amazon.de first")
-	if err := sc.browser.NavigateWithRetry(page, "https://www.amazon.de", 3); err != nil {
+	if err := sc.browser.NavigateWithRetry(page, sc.baseURL, 3); err != nil {
 		sc.logger.Warn("failed to navigate to homepage", "error", err)
 	}
 	
@@ -58,7 +80,7 @@ func (sc *SearchCrawler) CrawlSearch(ctx context.Context, searchURL string) erro
 	}
 	
 	// Add human-like behavior
-	sc.browser.HumanizeInteraction(page)
+	sc.browser.HumanizeTask(ctx, page, browser.TaskSearch)
 	
 	pageNum := 1
 	totalProducts := 0
@@ -76,20 +98,17 @@ func (sc *SearchCrawler) CrawlSearch(ctx context.Context, searchURL string) erro
 		
 		sc.logger.Info("found products on page", "page", pageNum, "count", len(products))
 		
-		// Save products to database
-		for _, product := range products {
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			default:
-				if err := sc.saveProduct(ctx, product); err != nil {
-					sc.logger.Error("failed to save product", "asin", product.ASIN, "error", err)
-					// Continue with other products
-				}
-			}
+		// Save the page's products to database in one round trip
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := sc.saveProducts(ctx, products); err != nil {
+			sc.logger.Error("failed to save products", "page", pageNum, "count", len(products), "error", err)
+			// Continue with the next page
 		}
 		
 		totalProducts += len(products)
+		sc.progress.AddPage(len(products))
 		
 		// Check for next page
 		hasNext, err := sc.goToNextPage(page)
@@ -148,7 +167,7 @@ func (sc *SearchCrawler) extractProductsFromPage(page playwright.Page) ([]*Produ
 		
 		product := &ProductListing{
 			ASIN: asin,
-			URL:  fmt.Sprintf("https://www.amazon.de/dp/%s", asin),
+			URL:  fmt.Sprintf("%s/dp/%s", sc.baseURL, asin),
 		}
 		
 		// Extract title
@@ -163,12 +182,27 @@ func (sc *SearchCrawler) extractProductsFromPage(page playwright.Page) ([]*Produ
 		// Extract brand if available
 		brandEl := productEl.Locator(`[class*="s-line-clamp"] .s-size-override-12`).First()
 		if brandEl != nil {
-			brand, err := brandEl.TextContent()
-			if err == nil && brand != "" {
-				product.Brand = strings.TrimSpace(brand)
+			text, err := brandEl.TextContent()
+			if err == nil && text != "" {
+				product.Brand = strings.TrimSpace(text)
 			}
 		}
 		
+		// Extract rating and review count used for priority scoring
+		ratingEl := productEl.Locator(`i.a-icon-star-small span.a-icon-alt, span.a-icon-alt`).First()
+		if count, _ := ratingEl.Count(); count > 0 {
+			if ratingText, err := ratingEl.TextContent(); err == nil {
+				product.Rating = parseRatingText(ratingText)
+			}
+		}
+
+		reviewEl := productEl.Locator(`[aria-label$="Bewertungen"], span.s-underline-text`).First()
+		if count, _ := reviewEl.Count(); count > 0 {
+			if reviewText, err := reviewEl.TextContent(); err == nil {
+				product.ReviewCount = parseCountText(reviewText)
+			}
+		}
+
 		// Set category from search (we're looking for t-shirts)
 		product.Category = "T-Shirt"
 		
@@ -228,8 +262,20 @@ func (sc *SearchCrawler) goToNextPage(page playwright.Page) (bool, error) {
 	return false, nil
 }
 
-// saveProduct saves a product to the database
-func (sc *SearchCrawler) saveProduct(ctx context.Context, product *ProductListing) error {
+// saveProducts saves the products of a result page to the database with a single batched insert
+func (sc *SearchCrawler) saveProducts(ctx context.Context, products []*ProductListing) error {
+	if len(products) == 0 {
+		return nil
+	}
+	dbProducts := make([]*database.Product, 0, len(products))
+	for _, product := range products {
+		dbProducts = append(dbProducts, sc.dbProduct(product))
+	}
+	return sc.db.InsertProducts(ctx, dbProducts)
+}
+
+// dbProduct converts a listing to a pending product scored by the prioritizer
+func (sc *SearchCrawler) dbProduct(product *ProductListing) *database.Product {
 	dbProduct := &database.Product{
 		ASIN:     product.ASIN,
 		Title:    product.Title,
@@ -237,8 +283,8 @@ func (sc *SearchCrawler) saveProduct(ctx context.Context, product *ProductListin
 		Status:   database.StatusPending,
 	}
 	
-	if product.Brand != "" {
-		dbProduct.Brand.String = product.Brand
+	if name := brand.Normalize(product.Brand); name != "" {
+		dbProduct.Brand.String = name
 		dbProduct.Brand.Valid = true
 	}
 	
@@ -246,6 +292,42 @@ func (sc *SearchCrawler) saveProduct(ctx context.Context, product *ProductListin
 		dbProduct.Category.String = product.Category
 		dbProduct.Category.Valid = true
 	}
-	
-	return sc.db.InsertProduct(ctx, dbProduct)
+
+	if product.Rating > 0 {
+		dbProduct.Rating.Float64 = product.Rating
+		dbProduct.Rating.Valid = true
+	}
+
+	if product.ReviewCount > 0 {
+		dbProduct.ReviewCount.Int32 = int32(product.ReviewCount)
+		dbProduct.ReviewCount.Valid = true
+	}
+
+	dbProduct.Priority = sc.prioritizer.Score(dbProduct)
+	return dbProduct
+}
+
+var (
+	ratingTextPattern = regexp.MustCompile(`(\d+[,.]?\d*)\s*von\s*5`)
+	nonDigitPattern   = regexp.MustCompile(`\D`)
+)
+
+// parseRatingText extracts the rating from text like "4,5 von 5 Sternen"
+func parseRatingText(text string) float64 {
+	match := ratingTextPattern.FindStringSubmatch(text)
+	if len(match) < 2 {
+		return 0
+	}
+	rating, _ := numparse.Float(match[1], numparse.Auto)
+	return rating
+}
+
+// parseCountText extracts a count from text like "1.234" or "(1.234)"
+func parseCountText(text string) int {
+	digits := nonDigitPattern.ReplaceAllString(text, "")
+	count, err := strconv.Atoi(digits)
+	if err != nil {
+		return 0
+	}
+	return count
 }
\ No newline at end of file
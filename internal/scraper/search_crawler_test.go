@@ -0,0 +1,118 @@
+package scraper
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/maltedev/amazon-size-scraper/internal/amazontest"
+)
+
+func TestSearchCrawler_ExtractsAndPaginates(t *testing.T) {
+	server := amazontest.NewServer()
+	defer server.Close()
+	b := amazontest.NewBrowser(t)
+
+	sc := &SearchCrawler{browser: b, logger: slog.Default()}
+	sc.SetBaseURL(server.URL)
+
+	page, err := b.NewPage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer page.Close()
+
+	if err := b.NavigateWithRetry(page, server.SearchURL("herren"), 1); err != nil {
+		t.Fatalf("navigate: %v", err)
+	}
+
+	products, err := sc.extractProductsFromPage(page)
+	if err != nil {
+		t.Fatalf("extractProductsFromPage() error = %v", err)
+	}
+	if len(products) != 2 {
+		t.Fatalf("page 1 products = %d, want 2", len(products))
+	}
+
+	first := products[0]
+	if first.ASIN != amazontest.ASINVerticalChart {
+		t.Errorf("ASIN = %q, want %q", first.ASIN, amazontest.ASINVerticalChart)
+	}
+	if !strings.HasPrefix(first.URL, server.URL+"/dp/") {
+		t.Errorf("URL = %q, want server product URL", first.URL)
+	}
+	if first.Brand != "TallFit" || !strings.Contains(first.Title, "Tall T-Shirt") {
+		t.Errorf("brand/title = %q/%q", first.Brand, first.Title)
+	}
+	if first.Rating != 4.5 || first.ReviewCount != 1234 {
+		t.Errorf("rating/reviews = %v/%d, want 4.5/1234", first.Rating, first.ReviewCount)
+	}
+
+	hasNext, err := sc.goToNextPage(page)
+	if err != nil || !hasNext {
+		t.Fatalf("goToNextPage() = %v, %v; want true", hasNext, err)
+	}
+
+	products, err = sc.extractProductsFromPage(page)
+	if err != nil {
+		t.Fatalf("extractProductsFromPage() page 2 error = %v", err)
+	}
+	if len(products) != 2 || products[0].ASIN != amazontest.ASINNoChart {
+		t.Errorf("page 2 products = %d starting with %v", len(products), products)
+	}
+
+	if hasNext, _ := sc.goToNextPage(page); hasNext {
+		t.Error("expected last page to have no next page")
+	}
+}
+
+func TestBrowser_BypassesBotCheck(t *testing.T) {
+	server := amazontest.NewServer()
+	defer server.Close()
+	b := amazontest.NewBrowser(t)
+
+	page, err := b.NewPage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer page.Close()
+
+	server.ServeBotChecks(1)
+	if err := b.NavigateWithRetry(page, server.ProductURL(amazontest.ASINVerticalChart), 1); err != nil {
+		t.Fatalf("navigate: %v", err)
+	}
+
+	if count, _ := page.Locator("#productTitle").Count(); count != 1 {
+		t.Error("expected product page after bot check bypass")
+	}
+}
+
+func TestAmazonScraper_DetectsCaptcha(t *testing.T) {
+	server := amazontest.NewServer()
+	defer server.Close()
+	b := amazontest.NewBrowser(t)
+
+	page, err := b.NewPage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer page.Close()
+
+	s := NewAmazonScraper(b, nil, slog.Default())
+
+	server.SetBlocked(true)
+	if _, err := page.Goto(server.SearchURL("herren")); err != nil {
+		t.Fatal(err)
+	}
+	if !s.checkIfBlocked(page) {
+		t.Error("expected captcha page to be detected")
+	}
+
+	server.SetBlocked(false)
+	if _, err := page.Goto(server.SearchURL("herren")); err != nil {
+		t.Fatal(err)
+	}
+	if s.checkIfBlocked(page) {
+		t.Error("search page detected as blocked")
+	}
+}
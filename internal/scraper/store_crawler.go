@@ -0,0 +1,272 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/browser"
+	"github.com/playwright-community/playwright-go"
+)
+
+// DefaultStorePages limits how many pages of a brand store are visited
+const DefaultStorePages = 50
+
+// storePathPattern matches /stores/page/<id> and /stores/<Brand>/page/<id>
+var storePathPattern = regexp.MustCompile(`^/stores/(?:page/[^/]+|([^/]+)/page/[^/]+)`)
+
+// StoreCrawler enqueues the products of Amazon brand stores and seller storefronts as pending
+type StoreCrawler struct {
+	search   *SearchCrawler
+	logger   *slog.Logger
+	maxPages int
+}
+
+// storeTile is a product tile found on a store page
+type storeTile struct {
+	ASIN  string
+	Title string
+}
+
+// storePage holds the tiles of a store page and its links to other store pages
+type storePage struct {
+	Tiles []storeTile
+	Links []string
+}
+
+// NewStoreCrawler creates a store crawler that saves products like the search crawler
+func NewStoreCrawler(search *SearchCrawler) *StoreCrawler {
+	return &StoreCrawler{
+		search:   search,
+		logger:   slog.Default().With("component", "store_crawler"),
+		maxPages: DefaultStorePages,
+	}
+}
+
+// SetMaxPages limits the number of store pages visited
+func (sc *StoreCrawler) SetMaxPages(n int) {
+	if n > 0 {
+		sc.maxPages = n
+	}
+}
+
+// Crawl enqueues all products of a brand store (/stores/...) or seller storefront (/sp?seller=...,
+// /s?me=...). Store pages are discovered through the store navigation, seller storefronts are
+// search result lists and crawled like a search.
+func (sc *StoreCrawler) Crawl(ctx context.Context, storeURL string) error {
+	if searchURL, ok := SellerStorefrontURL(storeURL); ok {
+		sc.logger.InfoContext(ctx, "crawling seller storefront", "url", searchURL)
+		return sc.search.CrawlSearch(ctx, searchURL)
+	}
+
+	start, err := url.Parse(storeURL)
+	if err != nil || !storePathPattern.MatchString(start.Path) {
+		return fmt.Errorf("not a brand store or seller URL: %s", storeURL)
+	}
+	brand := storeBrand(start.Path)
+	sc.logger.InfoContext(ctx, "starting store crawl", "url", storeURL, "brand", brand)
+
+	page, err := sc.search.browser.NewTaskPage(browser.TaskSearch)
+	if err != nil {
+		return fmt.Errorf("failed to create page: %w", err)
+	}
+	defer page.Close()
+
+	queue := []string{storePageKey(start)}
+	visited := map[string]bool{queue[0]: true}
+	seen := make(map[string]bool)
+
+	for pages := 0; len(queue) > 0 && pages < sc.maxPages; pages++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		current := queue[0]
+		queue = queue[1:]
+
+		target, err := start.Parse(current)
+		if err != nil {
+			continue
+		}
+		if err := sc.search.browser.NavigateWithRetryContext(ctx, page, target.String(), 3); err != nil {
+			if pages == 0 {
+				return fmt.Errorf("failed to navigate to store: %w", err)
+			}
+			sc.logger.WarnContext(ctx, "failed to navigate to store page", "url", target, "error", err)
+			continue
+		}
+
+		result, err := sc.extractStorePage(page)
+		if err != nil {
+			sc.logger.WarnContext(ctx, "failed to extract store page", "url", target, "error", err)
+			continue
+		}
+
+		var listings []*ProductListing
+		for _, tile := range result.Tiles {
+			if seen[tile.ASIN] {
+				continue
+			}
+			seen[tile.ASIN] = true
+
+			listings = append(listings, &ProductListing{
+				ASIN:  tile.ASIN,
+				Title: tile.Title,
+				URL:   fmt.Sprintf("%s/dp/%s", sc.search.baseURL, tile.ASIN),
+				Brand: brand,
+			})
+		}
+		if err := sc.search.saveProducts(ctx, listings); err != nil {
+			sc.logger.ErrorContext(ctx, "failed to save products", "url", target, "count", len(listings), "error", err)
+		}
+		added := len(listings)
+
+		for _, link := range result.Links {
+			if key, ok := sameStorePage(start, link); ok && !visited[key] {
+				visited[key] = true
+				queue = append(queue, key)
+			}
+		}
+
+		sc.search.progress.AddPage(added)
+		sc.logger.InfoContext(ctx, "processed store page", "url", target, "products", added, "queued", len(queue))
+		browser.Sleep(ctx, sc.search.rateLimit)
+	}
+
+	sc.logger.InfoContext(ctx, "store crawl completed", "total_products", len(seen), "pages", len(visited)-len(queue))
+	return nil
+}
+
+// extractStorePage scrolls through the page so lazily loaded tiles render and collects the product
+// tiles and the links to other store pages
+func (sc *StoreCrawler) extractStorePage(page playwright.Page) (*storePage, error) {
+	previous := -1
+	for i := 0; i < 10; i++ {
+		count, err := page.Locator(`a[href*="/dp/"]`).Count()
+		if err != nil {
+			return nil, fmt.Errorf("failed to count tiles: %w", err)
+		}
+		if count == previous {
+			break
+		}
+		previous = count
+
+		if _, err := page.Evaluate(`() => window.scrollTo(0, document.body.scrollHeight)`); err != nil {
+			return nil, fmt.Errorf("failed to scroll: %w", err)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	raw, err := page.Evaluate(`() => {
+		const tiles = [];
+		const seen = new Set();
+		for (const a of document.querySelectorAll('a[href*="/dp/"]')) {
+			const m = a.getAttribute('href').match(/\/dp\/([A-Z0-9]{10})/);
+			if (!m || seen.has(m[1])) continue;
+			seen.add(m[1]);
+			const tile = a.closest('[data-testid="product-grid-item"], li, [data-asin]') || a;
+			const title = tile.querySelector('[data-testid="product-grid-title"], [class*="Title"], h2, h3');
+			tiles.push({asin: m[1], title: (title || a).textContent.trim()});
+		}
+		for (const el of document.querySelectorAll('[data-asin]')) {
+			const asin = el.getAttribute('data-asin');
+			if (!/^[A-Z0-9]{10}$/.test(asin) || seen.has(asin)) continue;
+			seen.add(asin);
+			tiles.push({asin: asin, title: el.textContent.trim()});
+		}
+		const links = [...document.querySelectorAll('a[href*="/stores/"]')].map(a => a.href);
+		return {tiles: tiles, links: links};
+	}`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract store page: %w", err)
+	}
+
+	result := &storePage{}
+	data, _ := raw.(map[string]interface{})
+	if tiles, ok := data["tiles"].([]interface{}); ok {
+		for _, t := range tiles {
+			tile, _ := t.(map[string]interface{})
+			asin, _ := tile["asin"].(string)
+			title, _ := tile["title"].(string)
+			if asin != "" {
+				result.Tiles = append(result.Tiles, storeTile{ASIN: asin, Title: title})
+			}
+		}
+	}
+	if links, ok := data["links"].([]interface{}); ok {
+		for _, l := range links {
+			if link, ok := l.(string); ok {
+				result.Links = append(result.Links, link)
+			}
+		}
+	}
+	return result, nil
+}
+
+// SellerStorefrontURL turns a seller profile (/sp?seller=X) or storefront (/s?me=X) URL into the
+// storefront search URL, ok is false for any other URL
+func SellerStorefrontURL(raw string) (string, bool) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+
+	var seller string
+	switch u.Path {
+	case "/sp":
+		seller = u.Query().Get("seller")
+	case "/s":
+		seller = u.Query().Get("me")
+	}
+	if seller == "" {
+		return "", false
+	}
+
+	storefront := *u
+	storefront.Path = "/s"
+	storefront.RawQuery = url.Values{"me": {seller}}.Encode()
+	storefront.Fragment = ""
+	return storefront.String(), true
+}
+
+// IsStoreURL reports whether raw is a brand store or seller storefront URL
+func IsStoreURL(raw string) bool {
+	if _, ok := SellerStorefrontURL(raw); ok {
+		return true
+	}
+	u, err := url.Parse(raw)
+	return err == nil && storePathPattern.MatchString(u.Path)
+}
+
+// storeBrand returns the brand name of /stores/<Brand>/page/<id>, "" for brandless store URLs
+func storeBrand(path string) string {
+	match := storePathPattern.FindStringSubmatch(path)
+	if len(match) < 2 || match[1] == "" {
+		return ""
+	}
+	brand, err := url.PathUnescape(match[1])
+	if err != nil {
+		return match[1]
+	}
+	return brand
+}
+
+// storePageKey returns the path identifying a store page, dropping tracking query parameters
+func storePageKey(u *url.URL) string {
+	return strings.TrimRight(u.EscapedPath(), "/")
+}
+
+// sameStorePage returns the key of link if it is a page of the same store as start
+func sameStorePage(start *url.URL, link string) (string, bool) {
+	u, err := start.Parse(link)
+	if err != nil || u.Host != start.Host || !storePathPattern.MatchString(u.Path) {
+		return "", false
+	}
+	if storeBrand(u.Path) != storeBrand(start.Path) {
+		return "", false
+	}
+	return storePageKey(u), true
+}
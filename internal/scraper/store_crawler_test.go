@@ -0,0 +1,110 @@
+package scraper
+
+import (
+	"log/slog"
+	"net/url"
+	"testing"
+
+	"github.com/maltedev/amazon-size-scraper/internal/amazontest"
+)
+
+func TestSellerStorefrontURL(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"https://www.amazon.de/sp?ie=UTF8&seller=A1SELLER&isAmazonFulfilled=1", "https://www.amazon.de/s?me=A1SELLER", true},
+		{"https://www.amazon.de/s?me=A1SELLER&marketplaceID=A1PA6795UKMFR9#top", "https://www.amazon.de/s?me=A1SELLER", true},
+		{"https://www.amazon.de/s?k=herren+shirt", "", false},
+		{"https://www.amazon.de/stores/TallFit/page/ABC", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := SellerStorefrontURL(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("SellerStorefrontURL(%q) = %q, %v; want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestIsStoreURL(t *testing.T) {
+	for in, want := range map[string]bool{
+		"https://www.amazon.de/stores/Jack%26Jones/page/6A1B?ref_=ast_bln": true,
+		"https://www.amazon.de/stores/page/6A1B":                           true,
+		"https://www.amazon.de/sp?seller=A1SELLER":                         true,
+		"https://www.amazon.de/stores/TallFit":                             false,
+		"https://www.amazon.de/dp/B0TEST0001":                              false,
+	} {
+		if got := IsStoreURL(in); got != want {
+			t.Errorf("IsStoreURL(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestStoreBrandAndSamePage(t *testing.T) {
+	start, _ := url.Parse("https://www.amazon.de/stores/Jack%26Jones/page/HOME?ref_=ast_bln")
+
+	if got := storeBrand(start.Path); got != "Jack&Jones" {
+		t.Errorf("storeBrand() = %q, want Jack&Jones", got)
+	}
+
+	tests := []struct {
+		link string
+		want string
+		ok   bool
+	}{
+		{"/stores/Jack%26Jones/page/SHIRTS?ref_=ast_bln", "/stores/Jack%26Jones/page/SHIRTS", true},
+		{"https://www.amazon.de/stores/Jack%26Jones/page/HOME/", "/stores/Jack%26Jones/page/HOME", true},
+		{"/stores/OtherBrand/page/SHIRTS", "", false},
+		{"https://www.amazon.com/stores/Jack%26Jones/page/SHIRTS", "", false},
+		{"/dp/B0TEST0001", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := sameStorePage(start, tt.link)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("sameStorePage(%q) = %q, %v; want %q, %v", tt.link, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestStoreCrawler_ExtractsLazyTilesAndPages(t *testing.T) {
+	server := amazontest.NewServer()
+	defer server.Close()
+	server.SetPageSize(1)
+	b := amazontest.NewBrowser(t)
+
+	sc := &StoreCrawler{search: &SearchCrawler{browser: b, logger: slog.Default()}, logger: slog.Default()}
+
+	page, err := b.NewPage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer page.Close()
+
+	if err := b.NavigateWithRetry(page, server.StoreURL("TallFit"), 1); err != nil {
+		t.Fatalf("navigate: %v", err)
+	}
+
+	result, err := sc.extractStorePage(page)
+	if err != nil {
+		t.Fatalf("extractStorePage() error = %v", err)
+	}
+	if len(result.Tiles) != 1 || result.Tiles[0].ASIN != amazontest.ASINVerticalChart {
+		t.Fatalf("tiles = %+v, want the lazily loaded %s", result.Tiles, amazontest.ASINVerticalChart)
+	}
+	if result.Tiles[0].Title == "" {
+		t.Error("expected tile title")
+	}
+
+	start, _ := url.Parse(server.StoreURL("TallFit"))
+	pages := make(map[string]bool)
+	for _, link := range result.Links {
+		if key, ok := sameStorePage(start, link); ok {
+			pages[key] = true
+		}
+	}
+	if !pages["/stores/TallFit/page/STOREPAGE2"] || len(pages) != 2 {
+		t.Errorf("store pages = %v, want STOREPAGE1 and STOREPAGE2", pages)
+	}
+}
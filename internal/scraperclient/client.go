@@ -0,0 +1,274 @@
+package scraperclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	logging "github.com/maltedev/amazon-size-scraper/pkg/logger"
+)
+
+var (
+	// ErrUnavailable is returned when the scraper service is down or the circuit is open
+	ErrUnavailable = errors.New("scraper service unavailable")
+	// ErrCircuitOpen is returned without sending a request while the circuit is open
+	ErrCircuitOpen = fmt.Errorf("circuit open: %w", ErrUnavailable)
+)
+
+// Config configures retries, timeouts and the circuit breaker
+type Config struct {
+	BaseURL          string
+	Timeout          time.Duration // Per attempt
+	MaxAttempts      int
+	Backoff          time.Duration // Base delay, doubled per attempt
+	MaxBackoff       time.Duration
+	Jitter           float64 // Fraction of the delay randomized, 0-1
+	FailureThreshold int     // Consecutive failed calls before the circuit opens
+	Cooldown         time.Duration
+	RetryStatuses    []int         // Response statuses retried besides network errors, nil retries 429 and 5xx
+	MaxRetryAfter    time.Duration // Longest Retry-After waited for instead of the backoff, a longer one ends the retries; 0 ignores Retry-After
+}
+
+// DefaultConfig returns the client defaults
+func DefaultConfig(baseURL string) Config {
+	return Config{
+		BaseURL:          baseURL,
+		Timeout:          30 * time.Second,
+		MaxAttempts:      3,
+		Backoff:          time.Second,
+		MaxBackoff:       10 * time.Second,
+		Jitter:           0.2,
+		FailureThreshold: 5,
+		Cooldown:         30 * time.Second,
+		MaxRetryAfter:    30 * time.Second,
+	}
+}
+
+// Client is a resilient JSON client for the scraper service
+type Client struct {
+	cfg     Config
+	http    *http.Client
+	breaker *Breaker
+	logger  *slog.Logger
+	sleep   func(ctx context.Context, d time.Duration) error
+}
+
+// New creates a client
+func New(cfg Config, logger *slog.Logger) *Client {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 1
+	}
+	return &Client{
+		cfg:     cfg,
+		http:    &http.Client{Timeout: cfg.Timeout},
+		breaker: NewBreaker(cfg.FailureThreshold, cfg.Cooldown),
+		logger:  logger.With("component", "scraper_client"),
+		sleep:   sleepCtx,
+	}
+}
+
+// Breaker exposes the circuit breaker state
+func (c *Client) Breaker() *Breaker {
+	return c.breaker
+}
+
+// PostJSON posts body to path and decodes the response into out. Network errors and responses of a
+// retried status are retried with a fresh request per attempt, after the backoff or the response's
+// Retry-After. The breaker counts a call as failed when its last attempt got no answer or a 5xx,
+// whether or not that status is retried; a call the caller cancelled is not counted.
+func (c *Client) PostJSON(ctx context.Context, path string, body, out interface{}) error {
+	if !c.breaker.Allow() {
+		return ErrCircuitOpen
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		c.breaker.Abort()
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	url := strings.TrimRight(c.cfg.BaseURL, "/") + path
+
+	var lastErr error
+	var last outcome
+	var retryAfter time.Duration
+	attempts := 0
+	for attempt := 0; attempt < c.cfg.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			delay := c.backoff(attempt)
+			if retryAfter > 0 {
+				delay = retryAfter
+			}
+			if err := c.sleep(ctx, delay); err != nil {
+				c.cancelled(last)
+				return err
+			}
+		}
+
+		attempts++
+		result, err := c.do(ctx, url, payload, out)
+		if err == nil {
+			c.breaker.Success()
+			return nil
+		}
+		if ctx.Err() != nil {
+			c.cancelled(last)
+			return err
+		}
+		last = result
+		if !result.retry {
+			c.record(result)
+			return err
+		}
+
+		lastErr = err
+		c.logger.WarnContext(ctx, "scraper request failed", "url", url, "attempt", attempt+1, "error", err)
+		var retry bool
+		if retryAfter, retry = c.retryAfter(result.wait); !retry {
+			// Waiting that long would hold the message, the caller parks it instead
+			break
+		}
+	}
+
+	c.record(last)
+	return fmt.Errorf("%w after %d attempts: %v", ErrUnavailable, attempts, lastErr)
+}
+
+// record reports the last attempt of a failed call to the breaker. A service that answered below 500
+// is up even though it rejected the request, without an answer nothing is known.
+func (c *Client) record(last outcome) {
+	switch {
+	case !last.answered:
+		c.breaker.Abort()
+	case last.down:
+		c.breaker.Failure()
+	default:
+		c.breaker.Success()
+	}
+}
+
+// cancelled reports a call the caller gave up on, which only counts as failed if the attempt before
+// found the service down
+func (c *Client) cancelled(last outcome) {
+	if last.down {
+		c.breaker.Failure()
+		return
+	}
+	c.breaker.Abort()
+}
+
+// retryAfter returns the delay a Retry-After of wait asks for, 0 to use the backoff, and whether to
+// retry at all
+func (c *Client) retryAfter(wait time.Duration) (time.Duration, bool) {
+	if wait <= 0 || c.cfg.MaxRetryAfter <= 0 {
+		return 0, true
+	}
+	if wait > c.cfg.MaxRetryAfter {
+		return 0, false
+	}
+	return wait, true
+}
+
+// retryStatus reports whether a response status is retried
+func (c *Client) retryStatus(status int) bool {
+	if c.cfg.RetryStatuses == nil {
+		return status == http.StatusTooManyRequests || status >= 500
+	}
+	return slices.Contains(c.cfg.RetryStatuses, status)
+}
+
+// outcome classifies a failed attempt
+type outcome struct {
+	answered bool          // The attempt reached the service, false before the first attempt and on cancellation
+	down     bool          // No response or a 5xx, counted as failure by the breaker
+	retry    bool          // Network error or a retried status
+	wait     time.Duration // Retry-After of the response, if any
+}
+
+// do sends a single attempt and classifies its failure. The request sets GetBody, so redirects resend
+// the payload as well.
+func (c *Client) do(ctx context.Context, url string, payload []byte, out interface{}) (outcome, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return outcome{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if traceID := logging.TraceID(ctx); traceID != "" {
+		// The scraper logs the request under the same trace ID
+		req.Header.Set(logging.TraceHeader, traceID)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return outcome{}, ctx.Err()
+		}
+		return outcome{answered: true, down: true, retry: true}, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		result := outcome{
+			answered: true,
+			down:     resp.StatusCode >= 500,
+			retry:    c.retryStatus(resp.StatusCode),
+		}
+		if result.retry {
+			result.wait = parseRetryAfter(resp.Header.Get("Retry-After"))
+		}
+		return result, fmt.Errorf("API returned status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return outcome{answered: true}, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return outcome{}, nil
+}
+
+// parseRetryAfter returns the wait of a Retry-After header in seconds or as HTTP date, 0 if it is
+// missing or invalid
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(time.Until(t), 0)
+	}
+	return 0
+}
+
+// backoff returns the exponential delay before the given attempt with jitter applied
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.cfg.Backoff << (attempt - 1)
+	if c.cfg.MaxBackoff > 0 && delay > c.cfg.MaxBackoff {
+		delay = c.cfg.MaxBackoff
+	}
+	if c.cfg.Jitter > 0 {
+		spread := float64(delay) * c.cfg.Jitter
+		delay += time.Duration(spread * (rand.Float64()*2 - 1))
+	}
+	return delay
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
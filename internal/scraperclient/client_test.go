@@ -0,0 +1,289 @@
+package scraperclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestClient(url string, threshold int) *Client {
+	cfg := DefaultConfig(url)
+	cfg.FailureThreshold = threshold
+	c := New(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	c.sleep = func(context.Context, time.Duration) error { return nil }
+	return c
+}
+
+func TestPostJSONSendsFreshBodyPerAttempt(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["asin"] != "B000TEST01" {
+			t.Errorf("attempt %d got body %v, err %v", atomic.LoadInt32(&calls)+1, body, err)
+		}
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]bool{"size_chart_found": true})
+	}))
+	defer server.Close()
+
+	c := newTestClient(server.URL, 5)
+	var out struct {
+		Found bool `json:"size_chart_found"`
+	}
+	if err := c.PostJSON(context.Background(), "/api/v1/scraper/size-chart", map[string]string{"asin": "B000TEST01"}, &out); err != nil {
+		t.Fatalf("PostJSON() error = %v", err)
+	}
+	if !out.Found || calls != 3 {
+		t.Errorf("found = %v after %d calls, want true after 3", out.Found, calls)
+	}
+}
+
+func TestPostJSONOpensCircuit(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	c := newTestClient(server.URL, 2)
+	for i := 0; i < 2; i++ {
+		if err := c.PostJSON(context.Background(), "/", nil, &struct{}{}); !errors.Is(err, ErrUnavailable) {
+			t.Fatalf("call %d error = %v, want ErrUnavailable", i, err)
+		}
+	}
+	if c.Breaker().State() != StateOpen {
+		t.Fatalf("state = %s, want open", c.Breaker().State())
+	}
+
+	before := atomic.LoadInt32(&calls)
+	if err := c.PostJSON(context.Background(), "/", nil, &struct{}{}); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("error = %v, want ErrCircuitOpen", err)
+	}
+	if atomic.LoadInt32(&calls) != before {
+		t.Error("request sent while circuit open")
+	}
+}
+
+func TestPostJSONClientErrorIsNotRetried(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	c := newTestClient(server.URL, 1)
+	err := c.PostJSON(context.Background(), "/", nil, &struct{}{})
+	if err == nil || errors.Is(err, ErrUnavailable) {
+		t.Errorf("error = %v, want non-availability error", err)
+	}
+	if calls != 1 || c.Breaker().State() != StateClosed {
+		t.Errorf("calls = %d state = %s, want 1 closed", calls, c.Breaker().State())
+	}
+}
+
+func TestBreakerHalfOpenProbe(t *testing.T) {
+	now := time.Now()
+	b := NewBreaker(1, time.Minute)
+	b.now = func() time.Time { return now }
+
+	b.Failure()
+	if b.Allow() {
+		t.Fatal("open breaker allowed a request")
+	}
+	if b.RetryAfter() != time.Minute {
+		t.Errorf("RetryAfter() = %v, want 1m", b.RetryAfter())
+	}
+
+	now = now.Add(time.Minute)
+	if !b.Allow() {
+		t.Fatal("expected probe after cooldown")
+	}
+	if b.Allow() {
+		t.Error("second concurrent probe allowed")
+	}
+
+	b.Failure()
+	if b.State() != StateOpen {
+		t.Errorf("failed probe state = %s, want open", b.State())
+	}
+
+	now = now.Add(time.Minute)
+	b.Allow()
+	b.Success()
+	if b.State() != StateClosed {
+		t.Errorf("successful probe state = %s, want closed", b.State())
+	}
+}
+
+func TestPostJSONHonorsRetryAfter(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "4")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]bool{})
+	}))
+	defer server.Close()
+
+	c := newTestClient(server.URL, 5)
+	var delays []time.Duration
+	c.sleep = func(_ context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+	if err := c.PostJSON(context.Background(), "/", nil, &struct{}{}); err != nil {
+		t.Fatalf("PostJSON() error = %v", err)
+	}
+	if len(delays) != 1 || delays[0] != 4*time.Second {
+		t.Errorf("delays = %v, want the Retry-After of 4s", delays)
+	}
+}
+
+func TestPostJSONLongRetryAfterEndsRetries(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		// An exhausted daily budget resets in hours
+		w.Header().Set("Retry-After", "7200")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	c := newTestClient(server.URL, 5)
+	err := c.PostJSON(context.Background(), "/", nil, &struct{}{})
+	if !errors.Is(err, ErrUnavailable) {
+		t.Errorf("error = %v, want ErrUnavailable", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestPostJSONRetryStatuses(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	c := newTestClient(server.URL, 5)
+	c.cfg.RetryStatuses = []int{http.StatusBadGateway, http.StatusServiceUnavailable}
+	err := c.PostJSON(context.Background(), "/", nil, &struct{}{})
+	if err == nil || errors.Is(err, ErrUnavailable) {
+		t.Errorf("error = %v, want the 500 returned without retries", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestPostJSONBreakerCountsServerErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		retryStatuses []int
+		want          string
+	}{
+		{"5xx not retried", http.StatusInternalServerError, []int{http.StatusBadGateway}, StateOpen},
+		{"5xx retried", http.StatusServiceUnavailable, nil, StateOpen},
+		{"429 retried", http.StatusTooManyRequests, nil, StateClosed},
+		{"4xx retried", http.StatusConflict, []int{http.StatusConflict}, StateClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			c := newTestClient(server.URL, 1)
+			c.cfg.RetryStatuses = tt.retryStatuses
+			if err := c.PostJSON(context.Background(), "/", nil, &struct{}{}); err == nil {
+				t.Fatal("PostJSON() succeeded, want an error")
+			}
+			if state := c.Breaker().State(); state != tt.want {
+				t.Errorf("state = %s, want %s", state, tt.want)
+			}
+		})
+	}
+}
+
+func TestPostJSONCancelledProbeIsNotCounted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]bool{})
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	c := newTestClient(server.URL, 1)
+	c.breaker.now = func() time.Time { return now }
+	c.breaker.Failure()
+	now = now.Add(c.cfg.Cooldown)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := c.PostJSON(ctx, "/", nil, &struct{}{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("error = %v, want context.Canceled", err)
+	}
+	if state := c.Breaker().State(); state != StateHalfOpen {
+		t.Errorf("state = %s, want half_open", state)
+	}
+
+	// The cancelled probe neither closed nor reopened the breaker, the next call probes again
+	if err := c.PostJSON(context.Background(), "/", nil, &struct{}{}); err != nil {
+		t.Fatalf("PostJSON() error = %v", err)
+	}
+	if state := c.Breaker().State(); state != StateClosed {
+		t.Errorf("state = %s, want closed", state)
+	}
+}
+
+func TestPostJSONResendsBodyOnRedirect(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		http.Redirect(w, r, "/new", http.StatusTemporaryRedirect)
+	})
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["asin"] != "B000TEST01" {
+			t.Errorf("redirected request got body %v, err %v", body, err)
+		}
+		json.NewEncoder(w).Encode(map[string]bool{})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := newTestClient(server.URL, 5)
+	if err := c.PostJSON(context.Background(), "/old", map[string]string{"asin": "B000TEST01"}, &struct{}{}); err != nil {
+		t.Fatalf("PostJSON() error = %v", err)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	for value, want := range map[string]time.Duration{"": 0, "12": 12 * time.Second, "-3": 0, "soon": 0} {
+		if got := parseRetryAfter(value); got != want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", value, got, want)
+		}
+	}
+	date := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	if got := parseRetryAfter(date); got <= 50*time.Second || got > time.Minute {
+		t.Errorf("parseRetryAfter(%q) = %v, want about a minute", date, got)
+	}
+}
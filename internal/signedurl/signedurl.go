@@ -0,0 +1,87 @@
+// Package signedurl signs query parameters with an expiry, so links to API resources can be handed to
+// people and services without an API key.
+package signedurl
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Query parameters added by Sign
+const (
+	ExpiresParam   = "expires"
+	SignatureParam = "sig"
+)
+
+var (
+	// ErrMissingSignature is returned by Verify for queries without signature or expiry
+	ErrMissingSignature = errors.New("missing signature")
+	// ErrInvalidSignature is returned by Verify for tampered queries or signatures of another secret
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrExpired is returned by Verify once the expiry of a signed query passed
+	ErrExpired = errors.New("signed url expired")
+)
+
+// Signer signs and verifies queries with an HMAC-SHA256 of the path, the parameters and the expiry
+type Signer struct {
+	secret []byte
+	now    func() time.Time
+}
+
+// New creates a signer, an empty secret returns nil
+func New(secret string) *Signer {
+	if secret == "" {
+		return nil
+	}
+	return &Signer{secret: []byte(secret), now: time.Now}
+}
+
+// Sign returns a copy of query with the expiry and signature for path added
+func (s *Signer) Sign(path string, query url.Values, ttl time.Duration) url.Values {
+	signed := url.Values{}
+	for key, values := range query {
+		if key != ExpiresParam && key != SignatureParam {
+			signed[key] = append([]string(nil), values...)
+		}
+	}
+	signed.Set(ExpiresParam, strconv.FormatInt(s.now().Add(ttl).Unix(), 10))
+	signed.Set(SignatureParam, s.signature(path, signed))
+	return signed
+}
+
+// Verify checks the signature and expiry of a query signed for path
+func (s *Signer) Verify(path string, query url.Values) error {
+	sig, expires := query.Get(SignatureParam), query.Get(ExpiresParam)
+	if sig == "" || expires == "" {
+		return ErrMissingSignature
+	}
+	if !hmac.Equal([]byte(sig), []byte(s.signature(path, query))) {
+		return ErrInvalidSignature
+	}
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if s.now().After(time.Unix(unix, 0)) {
+		return ErrExpired
+	}
+	return nil
+}
+
+// signature covers every parameter except the signature, url.Values.Encode sorts them by key
+func (s *Signer) signature(path string, query url.Values) string {
+	unsigned := url.Values{}
+	for key, values := range query {
+		if key != SignatureParam {
+			unsigned[key] = values
+		}
+	}
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(path + "?" + unsigned.Encode()))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,62 @@
+package signedurl
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	s := New("secret")
+	s.now = func() time.Time { return now }
+
+	query := s.Sign("/screenshot", url.Values{"asin": {"B08N5WRWNW"}, "region": {"full"}}, time.Hour)
+	if err := s.Verify("/screenshot", query); err != nil {
+		t.Fatalf("Verify() = %v, want nil", err)
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		modify func(q url.Values)
+		secret string
+		after  time.Duration
+		want   error
+	}{
+		{name: "changed parameter", path: "/screenshot", modify: func(q url.Values) { q.Set("asin", "B07XJ8C8F5") }, want: ErrInvalidSignature},
+		{name: "added parameter", path: "/screenshot", modify: func(q url.Values) { q.Set("format", "webp") }, want: ErrInvalidSignature},
+		{name: "extended expiry", path: "/screenshot", modify: func(q url.Values) { q.Set(ExpiresParam, "9999999999") }, want: ErrInvalidSignature},
+		{name: "other path", path: "/products", want: ErrInvalidSignature},
+		{name: "other secret", path: "/screenshot", secret: "other", want: ErrInvalidSignature},
+		{name: "missing signature", path: "/screenshot", modify: func(q url.Values) { q.Del(SignatureParam) }, want: ErrMissingSignature},
+		{name: "expired", path: "/screenshot", after: 2 * time.Hour, want: ErrExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := url.Values{}
+			for k, v := range query {
+				q[k] = append([]string(nil), v...)
+			}
+			if tt.modify != nil {
+				tt.modify(q)
+			}
+			verifier := s
+			if tt.secret != "" {
+				verifier = New(tt.secret)
+			}
+			verifier.now = func() time.Time { return now.Add(tt.after) }
+
+			if err := verifier.Verify(tt.path, q); !errors.Is(err, tt.want) {
+				t.Errorf("Verify() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewWithoutSecret(t *testing.T) {
+	if s := New(""); s != nil {
+		t.Errorf("New(\"\") = %v, want nil", s)
+	}
+}
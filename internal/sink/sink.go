@@ -0,0 +1,97 @@
+// Package sink delivers scraped products to Postgres, NDJSON files, HTTP webhooks or Redis streams.
+package sink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/maltedev/amazon-size-scraper/internal/models"
+	"github.com/maltedev/amazon-size-scraper/internal/redisconn"
+)
+
+// Sink receives every successfully scraped product
+type Sink interface {
+	Write(ctx context.Context, product *models.Product) error
+	Close() error
+}
+
+// Options holds the connection settings sinks take from the environment rather than their spec
+type Options struct {
+	Database       database.Config
+	Redis          redisconn.Config
+	WebhookTimeout time.Duration
+}
+
+// Open creates a sink from a "kind:target" spec:
+//
+//	ndjson:results.ndjson   one JSON object per line, "-" writes to stdout
+//	webhook:https://host/x  POST of each product as JSON
+//	redis:stream:crawl      XADD to the stream of Options.Redis
+//	postgres                upsert into crawl_results using Options.Database
+func Open(ctx context.Context, spec string, opts Options) (Sink, error) {
+	kind, target, _ := strings.Cut(strings.TrimSpace(spec), ":")
+
+	switch kind {
+	case "ndjson":
+		if target == "" {
+			return nil, fmt.Errorf("ndjson sink needs a file, e.g. ndjson:results.ndjson")
+		}
+		return NewNDJSON(target)
+	case "webhook":
+		if !strings.HasPrefix(target, "http://") && !strings.HasPrefix(target, "https://") {
+			return nil, fmt.Errorf("webhook sink needs an http(s) URL, e.g. webhook:https://example.com/products")
+		}
+		return NewWebhook(target, opts.WebhookTimeout), nil
+	case "redis":
+		if target == "" {
+			return nil, fmt.Errorf("redis sink needs a stream, e.g. redis:stream:crawl_results")
+		}
+		return NewRedis(ctx, opts.Redis, target)
+	case "postgres":
+		return NewPostgres(ctx, opts.Database)
+	}
+	return nil, fmt.Errorf("unknown sink %q, use ndjson, webhook, redis or postgres", kind)
+}
+
+// OpenAll opens every spec and returns them as one sink, closing the already opened ones on error
+func OpenAll(ctx context.Context, specs []string, opts Options) (Sink, error) {
+	var sinks Multi
+	for _, spec := range specs {
+		s, err := Open(ctx, spec, opts)
+		if err != nil {
+			sinks.Close()
+			return nil, fmt.Errorf("failed to open sink %q: %w", spec, err)
+		}
+		sinks = append(sinks, s)
+	}
+	return sinks, nil
+}
+
+// Multi writes each product to all of its sinks
+type Multi []Sink
+
+// Write writes to every sink even if one fails and returns the joined errors
+func (m Multi) Write(ctx context.Context, product *models.Product) error {
+	var errs []error
+	for _, s := range m {
+		if err := s.Write(ctx, product); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes all sinks
+func (m Multi) Close() error {
+	var errs []error
+	for _, s := range m {
+		if err := s.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,113 @@
+package sink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/maltedev/amazon-size-scraper/internal/models"
+)
+
+func TestOpen(t *testing.T) {
+	for _, spec := range []string{"", "ndjson", "webhook:ftp://x", "redis", "s3:bucket"} {
+		if _, err := Open(context.Background(), spec, Options{}); err == nil {
+			t.Errorf("Open(%q) expected error", spec)
+		}
+	}
+
+	s, err := Open(context.Background(), "webhook:https://example.com/products", Options{})
+	if err != nil {
+		t.Fatalf("Open(webhook) error = %v", err)
+	}
+	if w, ok := s.(*Webhook); !ok || w.url != "https://example.com/products" {
+		t.Errorf("Open(webhook) = %#v", s)
+	}
+}
+
+func TestNDJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.ndjson")
+	s, err := Open(context.Background(), "ndjson:"+path, Options{})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	for _, asin := range []string{"B000000001", "B000000002"} {
+		if err := s.Write(context.Background(), models.NewProduct(asin)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var asins []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var p models.Product
+		if err := json.Unmarshal(scanner.Bytes(), &p); err != nil {
+			t.Fatalf("invalid line %q: %v", scanner.Text(), err)
+		}
+		asins = append(asins, p.ASIN)
+	}
+	if got := strings.Join(asins, ","); got != "B000000001,B000000002" {
+		t.Errorf("lines = %s", got)
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	var received models.Product
+	status := http.StatusAccepted
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	s := NewWebhook(server.URL, 0)
+	if err := s.Write(context.Background(), models.NewProduct("B000000001")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if received.ASIN != "B000000001" {
+		t.Errorf("received ASIN = %q", received.ASIN)
+	}
+
+	status = http.StatusInternalServerError
+	if err := s.Write(context.Background(), models.NewProduct("B000000002")); err == nil {
+		t.Error("expected error for status 500")
+	}
+}
+
+func TestMultiWritesAllSinks(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "results.ndjson")
+	s, err := OpenAll(context.Background(), []string{"webhook:" + server.URL, "ndjson:" + path}, Options{})
+	if err != nil {
+		t.Fatalf("OpenAll() error = %v", err)
+	}
+	defer s.Close()
+
+	if err := s.Write(context.Background(), models.NewProduct("B000000001")); err == nil {
+		t.Error("expected the webhook error")
+	}
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), "B000000001") || calls != 1 {
+		t.Errorf("ndjson = %q, webhook calls = %d", data, calls)
+	}
+}
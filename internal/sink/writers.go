@@ -0,0 +1,185 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/maltedev/amazon-size-scraper/internal/database"
+	"github.com/maltedev/amazon-size-scraper/internal/models"
+	"github.com/maltedev/amazon-size-scraper/internal/redisconn"
+	"github.com/redis/go-redis/v9"
+)
+
+// NDJSON appends one JSON object per product to a file
+type NDJSON struct {
+	mu  sync.Mutex
+	w   io.Writer
+	out *os.File
+}
+
+// NewNDJSON opens path for appending, "-" writes to stdout
+func NewNDJSON(path string) (*NDJSON, error) {
+	if path == "-" {
+		return &NDJSON{w: os.Stdout}, nil
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ndjson file: %w", err)
+	}
+	return &NDJSON{w: f, out: f}, nil
+}
+
+// Write implements Sink
+func (s *NDJSON) Write(ctx context.Context, product *models.Product) error {
+	line, err := json.Marshal(product)
+	if err != nil {
+		return fmt.Errorf("failed to marshal product: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.w.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write ndjson: %w", err)
+	}
+	return nil
+}
+
+// Close implements Sink
+func (s *NDJSON) Close() error {
+	if s.out == nil {
+		return nil
+	}
+	return s.out.Close()
+}
+
+// Webhook POSTs each product as JSON to a URL
+type Webhook struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhook creates a webhook sink, timeout defaults to 10 seconds
+func NewWebhook(url string, timeout time.Duration) *Webhook {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &Webhook{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+// Write implements Sink, any non-2xx response is an error
+func (s *Webhook) Write(ctx context.Context, product *models.Product) error {
+	body, err := json.Marshal(product)
+	if err != nil {
+		return fmt.Errorf("failed to marshal product: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Close implements Sink
+func (s *Webhook) Close() error {
+	return nil
+}
+
+// Redis adds each product to a Redis stream
+type Redis struct {
+	client redis.UniversalClient
+	stream string
+}
+
+// NewRedis connects to the configured deployment and checks the connection
+func NewRedis(ctx context.Context, cfg redisconn.Config, stream string) (*Redis, error) {
+	client, err := redisconn.New(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis config: %w", err)
+	}
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	return &Redis{client: client, stream: stream}, nil
+}
+
+// Write implements Sink
+func (s *Redis) Write(ctx context.Context, product *models.Product) error {
+	data, err := json.Marshal(product)
+	if err != nil {
+		return fmt.Errorf("failed to marshal product: %w", err)
+	}
+
+	err = s.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: s.stream,
+		Values: map[string]interface{}{
+			"asin":       product.ASIN,
+			"data":       string(data),
+			"scraped_at": product.ScrapedAt.Format(time.RFC3339),
+		},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("failed to publish to redis: %w", err)
+	}
+	return nil
+}
+
+// Close implements Sink
+func (s *Redis) Close() error {
+	return s.client.Close()
+}
+
+// Postgres upserts each product into the crawl_results table
+type Postgres struct {
+	db *database.DB
+}
+
+// NewPostgres connects to the database
+func NewPostgres(ctx context.Context, cfg database.Config) (*Postgres, error) {
+	db, err := database.New(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	return &Postgres{db: db}, nil
+}
+
+// Write implements Sink
+func (s *Postgres) Write(ctx context.Context, product *models.Product) error {
+	data, err := json.Marshal(product)
+	if err != nil {
+		return fmt.Errorf("failed to marshal product: %w", err)
+	}
+
+	scrapedAt := product.ScrapedAt
+	if scrapedAt.IsZero() {
+		scrapedAt = time.Now()
+	}
+	return s.db.UpsertCrawlResult(ctx, product.ASIN, product.URL, data, scrapedAt)
+}
+
+// Close implements Sink
+func (s *Postgres) Close() error {
+	s.db.Close()
+	return nil
+}
@@ -0,0 +1,184 @@
+// Package sizecache caches size chart responses so repeated requests for a product within the TTL are
+// answered without a browser session. Entries live in an in-process LRU and optionally in Redis, which
+// shares them between instances and survives restarts.
+package sizecache
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Layers a hit was served from
+const (
+	LayerMemory = "memory"
+	LayerRedis  = "redis"
+)
+
+type entry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// Cache is an LRU of encoded responses with a fixed TTL, backed by Redis when configured
+type Cache struct {
+	ttl  time.Duration
+	size int
+	now  func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List // Most recently used first
+	entries map[string]*list.Element
+
+	redis  redis.Cmdable
+	prefix string
+
+	memoryHits  atomic.Int64
+	redisHits   atomic.Int64
+	misses      atomic.Int64
+	bypasses    atomic.Int64
+	redisErrors atomic.Int64
+}
+
+// New creates a cache of up to size entries kept for ttl, nil when ttl is not positive
+func New(size int, ttl time.Duration) *Cache {
+	if ttl <= 0 {
+		return nil
+	}
+	if size <= 0 {
+		size = 1000
+	}
+	return &Cache{
+		ttl:     ttl,
+		size:    size,
+		now:     time.Now,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// SetRedis stores entries in Redis under "<prefix>:<key>" as well, nil disables the Redis layer
+func (c *Cache) SetRedis(client redis.Cmdable, prefix string) {
+	if prefix == "" {
+		prefix = "scraper:size-chart"
+	}
+	c.redis, c.prefix = client, prefix
+}
+
+// TTL returns how long entries are kept
+func (c *Cache) TTL() time.Duration {
+	return c.ttl
+}
+
+// Get returns a cached value and the layer it came from. Redis hits are copied into memory; Redis errors
+// count as misses.
+func (c *Cache) Get(ctx context.Context, key string) ([]byte, string, bool) {
+	if value, ok := c.getMemory(key); ok {
+		c.memoryHits.Add(1)
+		return value, LayerMemory, true
+	}
+
+	if c.redis != nil {
+		value, err := c.redis.Get(ctx, c.prefix+":"+key).Bytes()
+		switch {
+		case err == nil:
+			c.redisHits.Add(1)
+			c.setMemory(key, value)
+			return value, LayerRedis, true
+		case err != redis.Nil:
+			c.redisErrors.Add(1)
+		}
+	}
+
+	c.misses.Add(1)
+	return nil, "", false
+}
+
+// Set stores a value in every layer
+func (c *Cache) Set(ctx context.Context, key string, value []byte) error {
+	c.setMemory(key, value)
+	if c.redis == nil {
+		return nil
+	}
+	if err := c.redis.Set(ctx, c.prefix+":"+key, value, c.ttl).Err(); err != nil {
+		c.redisErrors.Add(1)
+		return fmt.Errorf("failed to cache in redis: %w", err)
+	}
+	return nil
+}
+
+// Bypass counts a request that skipped the cache on purpose
+func (c *Cache) Bypass() {
+	c.bypasses.Add(1)
+}
+
+// Len returns the number of entries in memory, expired ones included until they are evicted
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *Cache) getMemory(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*entry)
+	if !c.now().Before(e.expires) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return e.value, true
+}
+
+func (c *Cache) setMemory(key string, value []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := c.now().Add(c.ttl)
+	if el, ok := c.entries[key]; ok {
+		e := el.Value.(*entry)
+		e.value, e.expires = value, expires
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&entry{key: key, value: value, expires: expires})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*entry).key)
+	}
+}
+
+// WriteMetrics writes hit, miss and bypass counts in the Prometheus text format
+func (c *Cache) WriteMetrics(w io.Writer) {
+	fmt.Fprintf(w, "# HELP scraper_size_chart_cache_hits_total Size chart requests answered from the cache.\n# TYPE scraper_size_chart_cache_hits_total counter\n")
+	fmt.Fprintf(w, "scraper_size_chart_cache_hits_total{layer=%q} %d\n", LayerMemory, c.memoryHits.Load())
+	fmt.Fprintf(w, "scraper_size_chart_cache_hits_total{layer=%q} %d\n", LayerRedis, c.redisHits.Load())
+
+	counters := []struct {
+		name, help string
+		value      int64
+	}{
+		{"scraper_size_chart_cache_misses_total", "Size chart requests not found in the cache.", c.misses.Load()},
+		{"scraper_size_chart_cache_bypasses_total", "Size chart requests that skipped the cache.", c.bypasses.Load()},
+		{"scraper_size_chart_cache_redis_errors_total", "Failed Redis reads and writes of the cache.", c.redisErrors.Load()},
+	}
+	for _, m := range counters {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", m.name, m.help, m.name, m.name, m.value)
+	}
+	fmt.Fprintf(w, "# HELP scraper_size_chart_cache_entries Size charts cached in memory.\n# TYPE scraper_size_chart_cache_entries gauge\nscraper_size_chart_cache_entries %d\n", c.Len())
+}
@@ -0,0 +1,85 @@
+package sizecache
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCache_TTL(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	c := New(10, time.Minute)
+	c.now = func() time.Time { return now }
+
+	if _, _, ok := c.Get(ctx, "B08N5WRWNW"); ok {
+		t.Fatal("expected a miss on an empty cache")
+	}
+	if err := c.Set(ctx, "B08N5WRWNW", []byte(`{"size_chart_found":true}`)); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	value, layer, ok := c.Get(ctx, "B08N5WRWNW")
+	if !ok || layer != LayerMemory || string(value) != `{"size_chart_found":true}` {
+		t.Fatalf("Get() = %q, %q, %v", value, layer, ok)
+	}
+
+	now = now.Add(time.Minute)
+	if _, _, ok := c.Get(ctx, "B08N5WRWNW"); ok {
+		t.Error("expected the entry to expire after the TTL")
+	}
+	if c.Len() != 0 {
+		t.Errorf("Len() = %d, want expired entry removed", c.Len())
+	}
+}
+
+func TestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c := New(2, time.Hour)
+
+	c.Set(ctx, "a", []byte("1"))
+	c.Set(ctx, "b", []byte("2"))
+	c.Get(ctx, "a") // b is now the least recently used
+	c.Set(ctx, "c", []byte("3"))
+
+	if _, _, ok := c.Get(ctx, "b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, _, ok := c.Get(ctx, key); !ok {
+			t.Errorf("expected %s to be cached", key)
+		}
+	}
+}
+
+func TestNew_Disabled(t *testing.T) {
+	if c := New(10, 0); c != nil {
+		t.Error("expected no cache without TTL")
+	}
+}
+
+func TestCache_WriteMetrics(t *testing.T) {
+	ctx := context.Background()
+	c := New(10, time.Hour)
+	c.Get(ctx, "a")
+	c.Set(ctx, "a", []byte("1"))
+	c.Get(ctx, "a")
+	c.Bypass()
+
+	var buf bytes.Buffer
+	c.WriteMetrics(&buf)
+	out := buf.String()
+	for _, want := range []string{
+		`scraper_size_chart_cache_hits_total{layer="memory"} 1`,
+		`scraper_size_chart_cache_hits_total{layer="redis"} 0`,
+		"scraper_size_chart_cache_misses_total 1",
+		"scraper_size_chart_cache_bypasses_total 1",
+		"scraper_size_chart_cache_entries 1",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics missing %q:\n%s", want, out)
+		}
+	}
+}
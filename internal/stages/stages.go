@@ -0,0 +1,105 @@
+// Package stages toggles the optional extraction stages of a product page and times every stage, so a
+// deployment can trade extraction depth for throughput.
+package stages
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Extraction stages, the optional ones can be disabled
+const (
+	Navigate  = "navigate"   // Page load including bot checks
+	Humanize  = "humanize"   // Mouse movement and scrolling
+	Basic     = "basic"      // Title, brand and breadcrumbs
+	SizeTable = "size_table" // Size chart, required for every product
+
+	Images   = "images"   // Image URLs
+	Features = "features" // Bullet points
+	Price    = "price"    // Current price
+	Reviews  = "reviews"  // Rating, review count and customer fit feedback
+	Sizes    = "sizes"    // Available sizes with price and availability per size
+	Material = "material" // Material composition
+)
+
+// Optional lists the stages that can be disabled
+var Optional = []string{Images, Features, Price, Reviews, Sizes, Material}
+
+// Flags are the enabled extraction stages, the zero value enables all of them
+type Flags struct {
+	disabled map[string]bool
+}
+
+// Parse parses a comma separated list of disabled stages, e.g. "reviews,images,material"
+func Parse(disabled string) (Flags, error) {
+	f := Flags{disabled: make(map[string]bool)}
+	for _, stage := range strings.Split(disabled, ",") {
+		stage = strings.ToLower(strings.TrimSpace(stage))
+		if stage == "" {
+			continue
+		}
+		if !slices.Contains(Optional, stage) {
+			return Flags{}, fmt.Errorf("unknown or required extraction stage: %q (optional: %s)", stage, strings.Join(Optional, ", "))
+		}
+		f.disabled[stage] = true
+	}
+	return f, nil
+}
+
+// Enabled reports whether the stage runs
+func (f Flags) Enabled(stage string) bool {
+	return !f.disabled[stage]
+}
+
+// Disabled returns the disabled stages, sorted
+func (f Flags) Disabled() []string {
+	stages := make([]string, 0, len(f.disabled))
+	for stage := range f.disabled {
+		stages = append(stages, stage)
+	}
+	sort.Strings(stages)
+	return stages
+}
+
+// Timings are the milliseconds spent per stage of one product, disabled stages are missing
+type Timings map[string]int64
+
+// Timer runs the enabled stages of one product and records how long each took
+type Timer struct {
+	flags   Flags
+	timings Timings
+	now     func() time.Time
+}
+
+// NewTimer creates a timer for the extraction of one product
+func (f Flags) NewTimer() *Timer {
+	return &Timer{flags: f, timings: make(Timings), now: time.Now}
+}
+
+// Run runs fn when the stage is enabled and adds its duration to the stage, a disabled stage returns nil
+func (t *Timer) Run(stage string, fn func() error) error {
+	if !t.flags.Enabled(stage) {
+		return nil
+	}
+	started := t.now()
+	err := fn()
+	t.timings[stage] += t.now().Sub(started).Milliseconds()
+	return err
+}
+
+// Timings returns the recorded stage timings
+func (t *Timer) Timings() Timings {
+	return t.timings
+}
+
+// Total returns the milliseconds spent in all stages
+func (t Timings) Total() int64 {
+	var total int64
+	for _, ms := range t {
+		total += ms
+	}
+	return total
+}
@@ -0,0 +1,62 @@
+package stages
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	f, err := Parse(" reviews, Images,,material ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for stage, want := range map[string]bool{Reviews: false, Images: false, Material: false, Price: true, SizeTable: true} {
+		if got := f.Enabled(stage); got != want {
+			t.Errorf("Enabled(%s) = %v, want %v", stage, got, want)
+		}
+	}
+	if got := f.Disabled(); len(got) != 3 || got[0] != Images || got[2] != Reviews {
+		t.Errorf("Disabled() = %v", got)
+	}
+
+	for _, invalid := range []string{"size_table", "navigate", "ratings"} {
+		if _, err := Parse(invalid); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+
+	var zero Flags
+	if !zero.Enabled(Reviews) {
+		t.Error("zero flags should enable every stage")
+	}
+}
+
+func TestTimer(t *testing.T) {
+	f, _ := Parse("images")
+	timer := f.NewTimer()
+
+	clock := time.Unix(0, 0)
+	timer.now = func() time.Time { return clock }
+
+	ran := false
+	if err := timer.Run(Images, func() error { ran = true; return nil }); err != nil || ran {
+		t.Errorf("disabled stage ran: %v, %v", ran, err)
+	}
+
+	failed := errors.New("no price")
+	err := timer.Run(Price, func() error { clock = clock.Add(40 * time.Millisecond); return failed })
+	if !errors.Is(err, failed) {
+		t.Errorf("Run should return the stage error, got %v", err)
+	}
+	timer.Run(Price, func() error { clock = clock.Add(10 * time.Millisecond); return nil })
+	timer.Run(SizeTable, func() error { clock = clock.Add(time.Second); return nil })
+
+	timings := timer.Timings()
+	if _, ok := timings[Images]; ok {
+		t.Error("disabled stage should not be timed")
+	}
+	if timings[Price] != 50 || timings[SizeTable] != 1000 || timings.Total() != 1050 {
+		t.Errorf("unexpected timings: %v", timings)
+	}
+}
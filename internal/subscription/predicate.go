@@ -0,0 +1,175 @@
+package subscription
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// operators tried at each position, two character operators first
+var operators = []string{"==", "!=", ">=", "<=", ">", "<"}
+
+// Predicate compares the value at a JSONPath in the event payload, e.g. $.category == "fashion"
+type Predicate struct {
+	Path  []string // Object keys and array indexes, $.price.amount is ["price", "amount"]
+	Op    string   // One of operators, or "exists" for a bare path
+	Value any      // string, float64, bool or nil
+	raw   string
+}
+
+// ParsePredicate parses `<path> <op> <value>` or a bare `<path>` that matches when the path exists.
+// Values are JSON literals, unquoted words are taken as strings.
+func ParsePredicate(s string) (Predicate, error) {
+	s = strings.TrimSpace(s)
+	p := Predicate{raw: s}
+
+	// The first operator splits path and value, so values may contain operator characters
+	pathExpr, valueExpr := s, ""
+scan:
+	for i := range s {
+		for _, op := range operators {
+			if strings.HasPrefix(s[i:], op) {
+				pathExpr, valueExpr, p.Op = s[:i], strings.TrimSpace(s[i+len(op):]), op
+				break scan
+			}
+		}
+	}
+
+	path, err := parsePath(strings.TrimSpace(pathExpr))
+	if err != nil {
+		return Predicate{}, fmt.Errorf("invalid predicate %q: %w", s, err)
+	}
+	p.Path = path
+
+	if p.Op == "" {
+		p.Op = "exists"
+		return p, nil
+	}
+	if valueExpr == "" {
+		return Predicate{}, fmt.Errorf("invalid predicate %q: missing value", s)
+	}
+	if err := json.Unmarshal([]byte(valueExpr), &p.Value); err != nil {
+		p.Value = valueExpr
+	}
+	if _, isNumber := p.Value.(float64); !isNumber && (p.Op == ">" || p.Op == ">=" || p.Op == "<" || p.Op == "<=") {
+		return Predicate{}, fmt.Errorf("invalid predicate %q: %s needs a number", s, p.Op)
+	}
+	return p, nil
+}
+
+// parsePath splits $.a.b[0].c into ["a", "b", "0", "c"], the leading $ is optional
+func parsePath(expr string) ([]string, error) {
+	expr = strings.TrimPrefix(strings.TrimPrefix(expr, "$"), ".")
+	if expr == "" {
+		return nil, fmt.Errorf("empty path")
+	}
+
+	var path []string
+	for _, part := range strings.Split(expr, ".") {
+		key, rest, _ := strings.Cut(part, "[")
+		if key != "" {
+			path = append(path, key)
+		}
+		for rest != "" {
+			index, tail, ok := strings.Cut(rest, "]")
+			if _, err := strconv.Atoi(index); !ok || err != nil {
+				return nil, fmt.Errorf("invalid array index in %q", part)
+			}
+			path = append(path, index)
+			rest = strings.TrimPrefix(tail, "[")
+		}
+		if key == "" && !strings.Contains(part, "[") {
+			return nil, fmt.Errorf("empty path segment in %q", expr)
+		}
+	}
+	return path, nil
+}
+
+// Match evaluates the predicate against a decoded JSON document
+func (p Predicate) Match(doc any) bool {
+	value, ok := lookup(doc, p.Path)
+	if p.Op == "exists" {
+		return ok && value != nil
+	}
+	if !ok {
+		return p.Op == "!="
+	}
+
+	switch want := p.Value.(type) {
+	case float64:
+		got, ok := number(value)
+		if !ok {
+			return p.Op == "!="
+		}
+		switch p.Op {
+		case "==":
+			return got == want
+		case "!=":
+			return got != want
+		case ">":
+			return got > want
+		case ">=":
+			return got >= want
+		case "<":
+			return got < want
+		case "<=":
+			return got <= want
+		}
+	default:
+		equal := equalValues(value, want)
+		if p.Op == "==" {
+			return equal
+		}
+		return !equal
+	}
+	return false
+}
+
+// String returns the predicate as written in the config
+func (p Predicate) String() string {
+	return p.raw
+}
+
+func lookup(doc any, path []string) (any, bool) {
+	current := doc
+	for _, key := range path {
+		switch node := current.(type) {
+		case map[string]any:
+			value, ok := node[key]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			current = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// equalValues compares strings case-insensitively, categories and brands are not cased consistently
+func equalValues(got, want any) bool {
+	if s, ok := want.(string); ok {
+		g, ok := got.(string)
+		return ok && strings.EqualFold(g, s)
+	}
+	return got == want
+}
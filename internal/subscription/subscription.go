@@ -0,0 +1,158 @@
+// Package subscription routes stream events to named handlers by event type, aggregate type and
+// payload predicates.
+package subscription
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/maltedev/amazon-size-scraper/internal/schema"
+)
+
+// Subscription selects the events a handler receives, empty lists match everything
+type Subscription struct {
+	Name           string   `json:"name"`
+	EventTypes     []string `json:"event_types,omitempty"`
+	AggregateTypes []string `json:"aggregate_types,omitempty"`
+	Where          []string `json:"where,omitempty"` // Payload predicates, all must match
+	Handler        string   `json:"handler"`
+
+	predicates []Predicate
+}
+
+// Handler processes a matched event
+type Handler func(ctx context.Context, event *schema.Event) error
+
+// Load reads a JSON array of subscriptions from a file
+func Load(path string) ([]Subscription, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read subscriptions: %w", err)
+	}
+	var subs []Subscription
+	if err := json.Unmarshal(data, &subs); err != nil {
+		return nil, fmt.Errorf("failed to parse subscriptions: %w", err)
+	}
+	return subs, nil
+}
+
+// Dispatcher runs the handlers of all subscriptions an event matches
+type Dispatcher struct {
+	subs     []Subscription
+	handlers map[string]Handler
+}
+
+// NewDispatcher parses the predicates of subs, handlers are registered afterwards
+func NewDispatcher(subs []Subscription) (*Dispatcher, error) {
+	d := &Dispatcher{handlers: make(map[string]Handler)}
+	names := make(map[string]bool)
+	for _, sub := range subs {
+		if sub.Name == "" || sub.Handler == "" {
+			return nil, fmt.Errorf("subscription needs a name and a handler: %+v", sub)
+		}
+		if names[sub.Name] {
+			return nil, fmt.Errorf("duplicate subscription %s", sub.Name)
+		}
+		names[sub.Name] = true
+
+		for _, expr := range sub.Where {
+			p, err := ParsePredicate(expr)
+			if err != nil {
+				return nil, fmt.Errorf("subscription %s: %w", sub.Name, err)
+			}
+			sub.predicates = append(sub.predicates, p)
+		}
+		d.subs = append(d.subs, sub)
+	}
+	return d, nil
+}
+
+// Register makes a handler available to subscriptions under name
+func (d *Dispatcher) Register(name string, h Handler) {
+	d.handlers[name] = h
+}
+
+// Validate checks that every subscription refers to a registered handler and, if known is set, to
+// known event types
+func (d *Dispatcher) Validate(known func(eventType string) bool) error {
+	for _, sub := range d.subs {
+		if _, ok := d.handlers[sub.Handler]; !ok {
+			return fmt.Errorf("subscription %s: unknown handler %s", sub.Name, sub.Handler)
+		}
+		for _, eventType := range sub.EventTypes {
+			if known != nil && !known(eventType) {
+				return fmt.Errorf("subscription %s: unknown event type %s", sub.Name, eventType)
+			}
+		}
+	}
+	return nil
+}
+
+// Match returns the names of the subscriptions the event matches
+func (d *Dispatcher) Match(event *schema.Event) []string {
+	var names []string
+	for _, sub := range d.matching(event) {
+		names = append(names, sub.Name)
+	}
+	return names
+}
+
+// Dispatch runs each handler of the matched subscriptions once. It returns the number of handlers run
+// and their joined errors, so callers can check them with errors.Is.
+func (d *Dispatcher) Dispatch(ctx context.Context, event *schema.Event) (int, error) {
+	var errs []error
+	ran := make(map[string]bool)
+	for _, sub := range d.matching(event) {
+		if ran[sub.Handler] {
+			continue
+		}
+		ran[sub.Handler] = true
+
+		h, ok := d.handlers[sub.Handler]
+		if !ok {
+			errs = append(errs, fmt.Errorf("subscription %s: unknown handler %s", sub.Name, sub.Handler))
+			continue
+		}
+		if err := h(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("handler %s: %w", sub.Handler, err))
+		}
+	}
+	return len(ran), errors.Join(errs...)
+}
+
+func (d *Dispatcher) matching(event *schema.Event) []Subscription {
+	var doc any
+	decoded := false
+
+	var matched []Subscription
+	for _, sub := range d.subs {
+		if len(sub.EventTypes) > 0 && !slices.Contains(sub.EventTypes, event.Type) {
+			continue
+		}
+		if len(sub.AggregateTypes) > 0 && !slices.Contains(sub.AggregateTypes, event.AggregateType) {
+			continue
+		}
+		if len(sub.predicates) > 0 && !decoded {
+			// An undecodable payload fails every predicate
+			json.Unmarshal(event.Payload, &doc)
+			decoded = true
+		}
+		if matchAll(sub.predicates, doc) {
+			matched = append(matched, sub)
+		}
+	}
+	return matched
+}
+
+func matchAll(predicates []Predicate, doc any) bool {
+	for _, p := range predicates {
+		if !p.Match(doc) {
+			return false
+		}
+	}
+	return true
+}
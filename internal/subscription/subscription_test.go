@@ -0,0 +1,144 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/maltedev/amazon-size-scraper/internal/schema"
+)
+
+func TestParsePredicate(t *testing.T) {
+	tests := []struct {
+		in      string
+		path    []string
+		op      string
+		value   any
+		wantErr bool
+	}{
+		{`$.category == "fashion"`, []string{"category"}, "==", "fashion", false},
+		{`category==fashion`, []string{"category"}, "==", "fashion", false},
+		{`$.rating >= 4.5`, []string{"rating"}, ">=", 4.5, false},
+		{`$.size_table.sizes[0] != "XS"`, []string{"size_table", "sizes", "0"}, "!=", "XS", false},
+		{`$.title == "a=b"`, []string{"title"}, "==", "a=b", false},
+		{`$.fit_feedback`, []string{"fit_feedback"}, "exists", nil, false},
+		{`$.brand > "A"`, nil, "", nil, true},
+		{`$.brand ==`, nil, "", nil, true},
+		{`$.sizes[x] == 1`, nil, "", nil, true},
+		{`$ == 1`, nil, "", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParsePredicate(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParsePredicate(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if !reflect.DeepEqual(got.Path, tt.path) || got.Op != tt.op || got.Value != tt.value {
+			t.Errorf("ParsePredicate(%q) = %v %s %v, want %v %s %v", tt.in, got.Path, got.Op, got.Value, tt.path, tt.op, tt.value)
+		}
+	}
+}
+
+func TestPredicateMatch(t *testing.T) {
+	doc := map[string]any{
+		"category": "Fashion",
+		"rating":   "4.6",
+		"sizes":    []any{"S", "M"},
+		"brand":    nil,
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`$.category == "fashion"`, true},
+		{`$.category != "fashion"`, false},
+		{`$.rating > 4.5`, true},
+		{`$.rating < 4`, false},
+		{`$.sizes[1] == "m"`, true},
+		{`$.sizes[5] == "M"`, false},
+		{`$.sizes`, true},
+		{`$.brand`, false},
+		{`$.missing == 1`, false},
+		{`$.missing != 1`, true},
+	}
+
+	for _, tt := range tests {
+		p, err := ParsePredicate(tt.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := p.Match(doc); got != tt.want {
+			t.Errorf("%s matched %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestDispatch(t *testing.T) {
+	d, err := NewDispatcher([]Subscription{
+		{Name: "sizes", EventTypes: []string{"NEW_PRODUCT_DETECTED"}, Handler: "extract"},
+		{Name: "fashion", AggregateTypes: []string{"product"}, Where: []string{`$.category == "fashion"`}, Handler: "notify"},
+		{Name: "fashion-sizes", EventTypes: []string{"NEW_PRODUCT_DETECTED"}, Where: []string{`$.category == "fashion"`}, Handler: "extract"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errUnavailable := errors.New("unavailable")
+	calls := map[string]int{}
+	d.Register("extract", func(ctx context.Context, event *schema.Event) error {
+		calls["extract"]++
+		return errUnavailable
+	})
+	d.Register("notify", func(ctx context.Context, event *schema.Event) error {
+		calls["notify"]++
+		return nil
+	})
+	if err := d.Validate(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	event := &schema.Event{Type: "NEW_PRODUCT_DETECTED", AggregateType: "product", Payload: []byte(`{"category": "fashion"}`)}
+	if got := d.Match(event); !reflect.DeepEqual(got, []string{"sizes", "fashion", "fashion-sizes"}) {
+		t.Errorf("Unexpected matches %v", got)
+	}
+
+	handled, err := d.Dispatch(context.Background(), event)
+	if handled != 2 || calls["extract"] != 1 || calls["notify"] != 1 {
+		t.Errorf("Expected each handler once, got %d handled, calls %v", handled, calls)
+	}
+	if !errors.Is(err, errUnavailable) {
+		t.Errorf("Expected handler error to be preserved, got %v", err)
+	}
+
+	other := &schema.Event{Type: "PRODUCT_CREATED", AggregateType: "product", Payload: []byte(`{"category": "home"}`)}
+	if handled, err := d.Dispatch(context.Background(), other); handled != 0 || err != nil {
+		t.Errorf("Expected no handler for unmatched event, got %d, %v", handled, err)
+	}
+}
+
+func TestDispatcherValidation(t *testing.T) {
+	if _, err := NewDispatcher([]Subscription{{Name: "a", Handler: "h"}, {Name: "a", Handler: "h"}}); err == nil {
+		t.Error("Expected duplicate subscription names to fail")
+	}
+	if _, err := NewDispatcher([]Subscription{{Name: "a", Handler: "h", Where: []string{"$.x >"}}}); err == nil {
+		t.Error("Expected invalid predicate to fail")
+	}
+
+	d, err := NewDispatcher([]Subscription{{Name: "a", EventTypes: []string{"UNKNOWN"}, Handler: "h"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Validate(nil); err == nil {
+		t.Error("Expected unregistered handler to fail")
+	}
+	d.Register("h", func(ctx context.Context, event *schema.Event) error { return nil })
+	if err := d.Validate(func(string) bool { return false }); err == nil {
+		t.Error("Expected unknown event type to fail")
+	}
+}
@@ -0,0 +1,192 @@
+// Package taxonomy maps Amazon breadcrumbs and browse nodes to the internal category codes used by
+// downstream matching.
+package taxonomy
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Internal category codes
+const (
+	Unknown   = ""
+	TShirt    = "tshirt"
+	Shirt     = "shirt"
+	Polo      = "polo"
+	Hoodie    = "hoodie"
+	Sweater   = "sweater"
+	Jacket    = "jacket"
+	Coat      = "coat"
+	Pants     = "pants"
+	Jeans     = "jeans"
+	Shorts    = "shorts"
+	Dress     = "dress"
+	Skirt     = "skirt"
+	Underwear = "underwear"
+	Socks     = "socks"
+)
+
+// Categories lists all category codes in display order
+var Categories = []string{
+	TShirt, Shirt, Polo, Hoodie, Sweater, Jacket, Coat, Pants, Jeans, Shorts, Dress, Skirt, Underwear, Socks,
+}
+
+// Valid reports whether code is a known category code
+func Valid(code string) bool {
+	return slices.Contains(Categories, code)
+}
+
+// Mapping kinds
+const (
+	KindBreadcrumb = "breadcrumb" // Term contained in a breadcrumb segment, e.g. "t-shirts"
+	KindNode       = "node"       // Browse node ID, e.g. 1981807031
+)
+
+// Mapping assigns a category code to a breadcrumb term or a browse node
+type Mapping struct {
+	Kind     string `json:"kind"`
+	Value    string `json:"value"`
+	Category string `json:"category"`
+	Builtin  bool   `json:"builtin,omitempty"` // Part of DefaultMappings and not stored
+}
+
+// Normalize lowercases breadcrumb terms and validates the mapping
+func (m *Mapping) Normalize() error {
+	m.Value = strings.TrimSpace(m.Value)
+	if m.Kind == KindBreadcrumb {
+		m.Value = strings.ToLower(m.Value)
+	}
+
+	switch {
+	case m.Kind != KindBreadcrumb && m.Kind != KindNode:
+		return fmt.Errorf("kind must be %s or %s: %q", KindBreadcrumb, KindNode, m.Kind)
+	case m.Value == "":
+		return fmt.Errorf("value is required")
+	case m.Kind == KindNode && strings.Trim(m.Value, "0123456789") != "":
+		return fmt.Errorf("browse node must be numeric: %q", m.Value)
+	case !Valid(m.Category):
+		return fmt.Errorf("unknown category: %q", m.Category)
+	}
+	return nil
+}
+
+// builtin contains the default breadcrumb terms per category, German and English
+var builtin = map[string][]string{
+	TShirt:    {"t-shirts", "t-shirt", "tshirts", "shirts & t-shirts"},
+	Shirt:     {"hemden", "hemd", "shirts", "blusen"},
+	Polo:      {"poloshirts", "polos", "polo-shirts"},
+	Hoodie:    {"hoodies", "kapuzenpullover", "kapuzenjacken", "sweatshirts & hoodies"},
+	Sweater:   {"pullover", "sweatshirts", "strickjacken", "sweaters", "jumpers"},
+	Jacket:    {"jacken", "jackets", "westen"},
+	Coat:      {"mäntel", "maentel", "coats", "parkas"},
+	Pants:     {"hosen", "trousers", "pants", "jogginghosen", "chinos", "leggings"},
+	Jeans:     {"jeans", "jeanshosen"},
+	Shorts:    {"shorts", "bermudas", "kurze hosen"},
+	Dress:     {"kleider", "dresses"},
+	Skirt:     {"röcke", "roecke", "skirts"},
+	Underwear: {"unterwäsche", "unterhosen", "unterhemden", "boxershorts", "slips", "underwear", "boxer shorts"},
+	Socks:     {"socken", "strümpfe", "socks"},
+}
+
+// DefaultMappings returns the built-in breadcrumb mappings
+func DefaultMappings() []Mapping {
+	var mappings []Mapping
+	for _, category := range Categories {
+		for _, term := range builtin[category] {
+			mappings = append(mappings, Mapping{Kind: KindBreadcrumb, Value: term, Category: category, Builtin: true})
+		}
+	}
+	return mappings
+}
+
+// Mapper maps breadcrumbs and browse nodes to category codes, safe for concurrent use
+type Mapper struct {
+	mu    sync.RWMutex
+	terms map[string]string // Breadcrumb term -> category
+	nodes map[string]string // Browse node -> category
+}
+
+// NewMapper creates a mapper with the default mappings extended by custom, custom wins on conflicts
+func NewMapper(custom []Mapping) *Mapper {
+	m := &Mapper{}
+	m.SetMappings(custom)
+	return m
+}
+
+// SetMappings replaces the custom mappings, the default mappings stay in place
+func (m *Mapper) SetMappings(custom []Mapping) {
+	terms := make(map[string]string)
+	nodes := make(map[string]string)
+	for _, mapping := range append(DefaultMappings(), custom...) {
+		switch mapping.Kind {
+		case KindBreadcrumb:
+			terms[strings.ToLower(mapping.Value)] = mapping.Category
+		case KindNode:
+			nodes[mapping.Value] = mapping.Category
+		}
+	}
+
+	m.mu.Lock()
+	m.terms, m.nodes = terms, nodes
+	m.mu.Unlock()
+}
+
+// Map returns the category of a product from its breadcrumb path, top level first, and the browse
+// nodes of its breadcrumb links. A mapped browse node wins, otherwise the deepest breadcrumb segment
+// containing a term decides, the longest term within a segment so "unterhosen" beats "hosen".
+func (m *Mapper) Map(breadcrumbs, nodes []string) string {
+	if m == nil {
+		return Unknown
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for i := len(nodes) - 1; i >= 0; i-- {
+		if category, ok := m.nodes[nodes[i]]; ok {
+			return category
+		}
+	}
+
+	for i := len(breadcrumbs) - 1; i >= 0; i-- {
+		segment := strings.ToLower(strings.TrimSpace(breadcrumbs[i]))
+		best, category := "", Unknown
+		for term, c := range m.terms {
+			longer := len(term) > len(best) || (len(term) == len(best) && term < best)
+			if longer && strings.Contains(segment, term) {
+				best, category = term, c
+			}
+		}
+		if category != Unknown {
+			return category
+		}
+	}
+	return Unknown
+}
+
+// SplitBreadcrumbs splits a stored breadcrumb string such as "Herren > Tops > T-Shirts"
+func SplitBreadcrumbs(s string) []string {
+	var segments []string
+	for _, segment := range strings.Split(s, ">") {
+		if segment = strings.TrimSpace(segment); segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+// BrowseNode returns the node parameter of a breadcrumb link, e.g. /b/?node=1981807031
+func BrowseNode(href string) string {
+	u, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	node := u.Query().Get("node")
+	if node == "" || strings.Trim(node, "0123456789") != "" {
+		return ""
+	}
+	return node
+}
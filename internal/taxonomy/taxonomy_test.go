@@ -0,0 +1,90 @@
+package taxonomy
+
+import "testing"
+
+func TestMap(t *testing.T) {
+	m := NewMapper(nil)
+
+	tests := []struct {
+		breadcrumbs string
+		want        string
+	}{
+		{"Herren > Tops > T-Shirts", TShirt},
+		{"Herren > Tops, T-Shirts & Hemden > Poloshirts", Polo},
+		{"Herren > Sweatshirts & Hoodies", Hoodie},
+		{"Herren > Unterwäsche > Unterhosen", Underwear},
+		{"Herren > Hosen > Jeans", Jeans},
+		{"Damen > Hosen", Pants},
+		{"Men > Clothing > Shorts", Shorts},
+		{"Herren > Bekleidung", Unknown},
+		{"", Unknown},
+	}
+	for _, tt := range tests {
+		if got := m.Map(SplitBreadcrumbs(tt.breadcrumbs), nil); got != tt.want {
+			t.Errorf("Map(%q) = %q, want %q", tt.breadcrumbs, got, tt.want)
+		}
+	}
+}
+
+func TestMapCustomMappings(t *testing.T) {
+	m := NewMapper([]Mapping{
+		{Kind: KindNode, Value: "1981807031", Category: Hoodie},
+		{Kind: KindBreadcrumb, Value: "longsleeves", Category: TShirt},
+		{Kind: KindBreadcrumb, Value: "hosen", Category: Shorts},
+	})
+
+	if got := m.Map(SplitBreadcrumbs("Herren > T-Shirts"), []string{"1981807031"}); got != Hoodie {
+		t.Errorf("browse node mapping should win over breadcrumbs, got %q", got)
+	}
+	if got := m.Map([]string{"Herren", "Longsleeves"}, []string{"123"}); got != TShirt {
+		t.Errorf("custom breadcrumb term = %q, want %q", got, TShirt)
+	}
+	if got := m.Map([]string{"Hosen"}, nil); got != Shorts {
+		t.Errorf("custom mapping should override the built-in one, got %q", got)
+	}
+
+	m.SetMappings(nil)
+	if got := m.Map([]string{"Hosen"}, []string{"1981807031"}); got != Pants {
+		t.Errorf("Map after SetMappings(nil) = %q, want %q", got, Pants)
+	}
+
+	var nilMapper *Mapper
+	if got := nilMapper.Map([]string{"T-Shirts"}, nil); got != Unknown {
+		t.Errorf("nil mapper = %q, want unknown", got)
+	}
+}
+
+func TestMappingNormalize(t *testing.T) {
+	mapping := Mapping{Kind: KindBreadcrumb, Value: "  Longsleeves ", Category: TShirt}
+	if err := mapping.Normalize(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mapping.Value != "longsleeves" {
+		t.Errorf("Value = %q, want lowercased and trimmed", mapping.Value)
+	}
+
+	for _, invalid := range []Mapping{
+		{Kind: "title", Value: "x", Category: TShirt},
+		{Kind: KindBreadcrumb, Value: " ", Category: TShirt},
+		{Kind: KindNode, Value: "abc", Category: TShirt},
+		{Kind: KindNode, Value: "123", Category: "cardigan"},
+	} {
+		if err := invalid.Normalize(); err == nil {
+			t.Errorf("expected error for %+v", invalid)
+		}
+	}
+}
+
+func TestBrowseNode(t *testing.T) {
+	tests := map[string]string{
+		"/b/ref=dp_bc_aui_C_3?ie=UTF8&node=1981807031": "1981807031",
+		"https://www.amazon.de/b?node=12419321031":     "12419321031",
+		"/gp/browse.html?node=abc":                     "",
+		"/Herren-T-Shirts/b":                           "",
+	}
+	for href, want := range tests {
+		if got := BrowseNode(href); got != want {
+			t.Errorf("BrowseNode(%q) = %q, want %q", href, got, want)
+		}
+	}
+}
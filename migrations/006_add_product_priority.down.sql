@@ -0,0 +1,8 @@
+-- Remove index first
+DROP INDEX IF EXISTS idx_products_pending_priority;
+
+-- Remove priority columns from products table
+ALTER TABLE products
+DROP COLUMN IF EXISTS priority_score,
+DROP COLUMN IF EXISTS review_count,
+DROP COLUMN IF EXISTS rating;
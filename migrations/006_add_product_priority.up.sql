@@ -0,0 +1,14 @@
+-- Add priority scoring fields used to order pending products before deep scraping
+ALTER TABLE products
+ADD COLUMN IF NOT EXISTS rating DECIMAL(3,2),
+ADD COLUMN IF NOT EXISTS review_count INT,
+ADD COLUMN IF NOT EXISTS priority_score DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+-- Pending products are consumed by score, oldest first within the same score
+CREATE INDEX idx_products_pending_priority ON products(priority_score DESC, created_at ASC)
+    WHERE status = 'pending';
+
+-- Add comments
+COMMENT ON COLUMN products.rating IS 'Average star rating captured from search results';
+COMMENT ON COLUMN products.review_count IS 'Number of customer reviews captured from search results';
+COMMENT ON COLUMN products.priority_score IS 'Likelihood score that the product has a size table (higher is scraped first)';
@@ -0,0 +1,2 @@
+ALTER TABLE products DROP COLUMN IF EXISTS error_dom_snippet;
+ALTER TABLE products DROP COLUMN IF EXISTS error_screenshot;
@@ -0,0 +1,3 @@
+-- Store references to screenshot and DOM snippet captured when extraction fails
+ALTER TABLE products ADD COLUMN IF NOT EXISTS error_screenshot TEXT;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS error_dom_snippet TEXT;
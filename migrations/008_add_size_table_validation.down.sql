@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_products_quality_score;
+
+ALTER TABLE products DROP COLUMN IF EXISTS quality_score;
+ALTER TABLE products DROP COLUMN IF EXISTS validation_report;
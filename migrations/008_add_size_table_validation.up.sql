@@ -0,0 +1,5 @@
+-- Store the size table validation report and the derived quality score
+ALTER TABLE products ADD COLUMN IF NOT EXISTS validation_report JSONB;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS quality_score DOUBLE PRECISION;
+
+CREATE INDEX IF NOT EXISTS idx_products_quality_score ON products(quality_score);
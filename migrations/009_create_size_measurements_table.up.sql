@@ -0,0 +1,13 @@
+-- Flat per-size measurement rows derived from size_table for downstream matching
+CREATE TABLE IF NOT EXISTS size_measurements (
+    asin VARCHAR(20) NOT NULL,
+    size_label VARCHAR(50) NOT NULL,
+    canonical_size VARCHAR(20) NOT NULL,
+    measurement VARCHAR(50) NOT NULL,
+    value_cm DOUBLE PRECISION NOT NULL,
+    interpolated BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (asin, size_label, measurement)
+);
+
+CREATE INDEX IF NOT EXISTS idx_size_measurements_lookup ON size_measurements(canonical_size, measurement);
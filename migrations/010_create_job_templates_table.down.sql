@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_scraper_jobs_pending_priority;
+DROP INDEX IF EXISTS idx_scraper_jobs_template_id;
+
+ALTER TABLE scraper_jobs DROP COLUMN IF EXISTS priority;
+ALTER TABLE scraper_jobs DROP COLUMN IF EXISTS filters;
+ALTER TABLE scraper_jobs DROP COLUMN IF EXISTS marketplace;
+ALTER TABLE scraper_jobs DROP COLUMN IF EXISTS template_id;
+
+DROP TABLE IF EXISTS job_templates;
@@ -0,0 +1,24 @@
+-- Saved search definitions operators can run repeatedly as jobs
+CREATE TABLE IF NOT EXISTS job_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL UNIQUE,
+    search_query TEXT NOT NULL,
+    category VARCHAR(50),
+    marketplace VARCHAR(20) NOT NULL DEFAULT 'amazon.de',
+    max_pages INT NOT NULL DEFAULT 10,
+    filters JSONB NOT NULL DEFAULT '{}',
+    priority INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Jobs carry the template settings and reference the template for reporting
+ALTER TABLE scraper_jobs ADD COLUMN IF NOT EXISTS template_id UUID REFERENCES job_templates(id) ON DELETE SET NULL;
+ALTER TABLE scraper_jobs ADD COLUMN IF NOT EXISTS marketplace VARCHAR(20) NOT NULL DEFAULT 'amazon.de';
+ALTER TABLE scraper_jobs ADD COLUMN IF NOT EXISTS filters JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE scraper_jobs ADD COLUMN IF NOT EXISTS priority INT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_scraper_jobs_template_id ON scraper_jobs(template_id);
+CREATE INDEX IF NOT EXISTS idx_scraper_jobs_pending_priority ON scraper_jobs(priority DESC, created_at) WHERE status = 'pending';
+
+COMMENT ON TABLE job_templates IS 'Saved search definitions used to create scraping jobs';
@@ -0,0 +1,4 @@
+ALTER TABLE job_templates DROP COLUMN IF EXISTS product_filter;
+
+ALTER TABLE scraper_jobs DROP COLUMN IF EXISTS products_filtered;
+ALTER TABLE scraper_jobs DROP COLUMN IF EXISTS product_filter;
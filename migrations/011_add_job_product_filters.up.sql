@@ -0,0 +1,5 @@
+-- Product filters applied to search results before deep scraping
+ALTER TABLE scraper_jobs ADD COLUMN IF NOT EXISTS product_filter JSONB;
+ALTER TABLE scraper_jobs ADD COLUMN IF NOT EXISTS products_filtered INT DEFAULT 0;
+
+ALTER TABLE job_templates ADD COLUMN IF NOT EXISTS product_filter JSONB;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_product_links_canonical;
+DROP TABLE IF EXISTS product_links;
+
+DROP INDEX IF EXISTS idx_product_fingerprints_match_keys;
+DROP TABLE IF EXISTS product_fingerprints;
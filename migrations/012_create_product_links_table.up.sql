@@ -0,0 +1,23 @@
+-- Fingerprints identifying the same physical product across ASINs and marketplaces
+CREATE TABLE IF NOT EXISTS product_fingerprints (
+    asin VARCHAR(20) PRIMARY KEY,
+    brand VARCHAR(255) NOT NULL DEFAULT '',
+    title_key TEXT NOT NULL DEFAULT '',
+    image_hash VARCHAR(32) NOT NULL DEFAULT '',
+    size_signature VARCHAR(32) NOT NULL DEFAULT '',
+    match_keys TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_fingerprints_match_keys ON product_fingerprints USING GIN(match_keys);
+
+-- Duplicate ASINs linked to the canonical ASIN of their group
+CREATE TABLE IF NOT EXISTS product_links (
+    asin VARCHAR(20) PRIMARY KEY,
+    canonical_asin VARCHAR(20) NOT NULL,
+    match_key TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_links_canonical ON product_links(canonical_asin);
@@ -0,0 +1 @@
+ALTER TABLE scraper_jobs DROP COLUMN IF EXISTS not_before;
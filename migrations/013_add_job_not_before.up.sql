@@ -0,0 +1,4 @@
+-- Jobs returned to the queue after exhausting their daily fetch budget wait until the budget resets
+ALTER TABLE scraper_jobs ADD COLUMN IF NOT EXISTS not_before TIMESTAMP;
+
+COMMENT ON COLUMN scraper_jobs.not_before IS 'Earliest time a pending job may be picked up again';
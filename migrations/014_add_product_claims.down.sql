@@ -0,0 +1,10 @@
+-- Remove index first
+DROP INDEX IF EXISTS idx_products_processing_lease;
+
+-- Claimed products go back to the queue
+UPDATE products SET status = 'pending' WHERE status = 'processing';
+
+-- Remove claim columns from products table
+ALTER TABLE products
+DROP COLUMN IF EXISTS lease_expires_at,
+DROP COLUMN IF EXISTS claimed_by;
@@ -0,0 +1,12 @@
+-- Per-row claims so concurrent size-scraper daemons never scrape the same product twice
+ALTER TABLE products
+ADD COLUMN IF NOT EXISTS claimed_by TEXT,
+ADD COLUMN IF NOT EXISTS lease_expires_at TIMESTAMP;
+
+-- Expired leases of crashed workers are stolen back by the lease expiry
+CREATE INDEX idx_products_processing_lease ON products(lease_expires_at)
+    WHERE status = 'processing';
+
+-- Add comments
+COMMENT ON COLUMN products.claimed_by IS 'Worker ID of the scraper currently processing the product';
+COMMENT ON COLUMN products.lease_expires_at IS 'When the claim expires and other workers may take the product over';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_scraper_workers_heartbeat;
+DROP TABLE IF EXISTS scraper_workers;
@@ -0,0 +1,9 @@
+-- Size-scraper workers and their last heartbeat, a worker renews the leases of its claimed products on every beat
+CREATE TABLE IF NOT EXISTS scraper_workers (
+    worker_id TEXT PRIMARY KEY,
+    hostname VARCHAR(255) NOT NULL DEFAULT '',
+    started_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_heartbeat TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_scraper_workers_heartbeat ON scraper_workers(last_heartbeat);
@@ -0,0 +1,2 @@
+-- Trace ID of the request or job that emitted the event, forwarded to stream consumers for log correlation
+ALTER TABLE outbox_event ADD COLUMN IF NOT EXISTS trace_id VARCHAR(64);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_crawl_results_scraped_at;
+DROP TABLE IF EXISTS crawl_results;
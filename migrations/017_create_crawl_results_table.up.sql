@@ -0,0 +1,11 @@
+-- Products scraped by the crawler process mode, written by its postgres output sink
+CREATE TABLE IF NOT EXISTS crawl_results (
+    asin VARCHAR(20) PRIMARY KEY,
+    url TEXT NOT NULL DEFAULT '',
+    data JSONB NOT NULL,
+    scraped_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_crawl_results_scraped_at ON crawl_results(scraped_at);
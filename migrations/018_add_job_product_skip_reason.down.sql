@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_job_products_skip_reason;
+DELETE FROM job_products WHERE skip_reason IS NOT NULL;
+ALTER TABLE job_products DROP COLUMN IF EXISTS skip_reason;
+ALTER TABLE job_products ADD CONSTRAINT job_products_asin_fkey
+    FOREIGN KEY (asin) REFERENCES products(asin) ON DELETE CASCADE NOT VALID;
@@ -0,0 +1,9 @@
+-- Products a job found but did not store are kept with the reason they were skipped
+ALTER TABLE job_products ADD COLUMN IF NOT EXISTS skip_reason VARCHAR(30);
+
+-- Saved products live in the product lifecycle table and skipped ones in no product table at all
+ALTER TABLE job_products DROP CONSTRAINT IF EXISTS job_products_asin_fkey;
+
+CREATE INDEX IF NOT EXISTS idx_job_products_skip_reason ON job_products(job_id, skip_reason) WHERE skip_reason IS NOT NULL;
+
+COMMENT ON COLUMN job_products.skip_reason IS 'Why the product was not stored: no_size_table, missing_length, captcha, parse_error or timeout; NULL for stored products';
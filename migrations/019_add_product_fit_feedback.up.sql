@@ -0,0 +1,2 @@
+-- Customer fit rating of the "Passform" widget, e.g. {"summary": "Fällt normal aus", "fit": "true_to_size", ...}
+ALTER TABLE products ADD COLUMN IF NOT EXISTS fit_feedback JSONB;
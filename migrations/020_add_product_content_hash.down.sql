@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_products_last_checked_at;
+
+ALTER TABLE products DROP COLUMN IF EXISTS last_changed_at;
+ALTER TABLE products DROP COLUMN IF EXISTS last_checked_at;
+ALTER TABLE products DROP COLUMN IF EXISTS content_hash;
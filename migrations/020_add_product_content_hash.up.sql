@@ -0,0 +1,7 @@
+-- Hash of title, price and size table to skip writes and events when a re-scrape finds no change
+ALTER TABLE products ADD COLUMN IF NOT EXISTS content_hash VARCHAR(64);
+-- last_checked_at moves on every scrape, last_changed_at only when the content hash changes
+ALTER TABLE products ADD COLUMN IF NOT EXISTS last_checked_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS last_changed_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_products_last_checked_at ON products(last_checked_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_size_conversions_lookup;
+
+DROP TABLE IF EXISTS size_conversions;
@@ -0,0 +1,12 @@
+-- International size mappings of a size table, e.g. size M is 50 in DE and 40 in UK
+CREATE TABLE IF NOT EXISTS size_conversions (
+    asin VARCHAR(20) NOT NULL,
+    size_label VARCHAR(50) NOT NULL,
+    canonical_size VARCHAR(20) NOT NULL,
+    system VARCHAR(10) NOT NULL,
+    size VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (asin, size_label, system)
+);
+
+CREATE INDEX IF NOT EXISTS idx_size_conversions_lookup ON size_conversions(system, size);
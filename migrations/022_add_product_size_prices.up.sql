@@ -0,0 +1,2 @@
+-- Price and availability per size of size-variant offers, e.g. {"M": {"price": 19.99, "currency": "EUR", "available": true}}
+ALTER TABLE products ADD COLUMN IF NOT EXISTS size_prices JSONB;
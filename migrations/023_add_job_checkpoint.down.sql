@@ -0,0 +1,3 @@
+ALTER TABLE scraper_jobs DROP COLUMN IF EXISTS heartbeat_at;
+ALTER TABLE scraper_jobs DROP COLUMN IF EXISTS checkpoint_asins;
+ALTER TABLE scraper_jobs DROP COLUMN IF EXISTS checkpoint_page;
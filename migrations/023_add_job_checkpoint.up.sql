@@ -0,0 +1,8 @@
+-- Running jobs store how far they got, so a job orphaned by a restart resumes instead of starting over
+ALTER TABLE scraper_jobs ADD COLUMN IF NOT EXISTS checkpoint_page INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE scraper_jobs ADD COLUMN IF NOT EXISTS checkpoint_asins TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE scraper_jobs ADD COLUMN IF NOT EXISTS heartbeat_at TIMESTAMP;
+
+COMMENT ON COLUMN scraper_jobs.checkpoint_page IS 'Last search result page whose products were all processed';
+COMMENT ON COLUMN scraper_jobs.checkpoint_asins IS 'Products of the pages after checkpoint_page that were already processed';
+COMMENT ON COLUMN scraper_jobs.heartbeat_at IS 'Last sign of life of the worker running the job, running jobs without one for too long are recovered';
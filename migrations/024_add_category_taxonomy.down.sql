@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS category_mappings;
+DROP INDEX IF EXISTS idx_products_category_code;
+ALTER TABLE products DROP COLUMN IF EXISTS category_code;
+ALTER TABLE products DROP COLUMN IF EXISTS category_path;
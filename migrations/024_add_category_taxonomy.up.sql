@@ -0,0 +1,17 @@
+-- Breadcrumb path and the internal category code it maps to, e.g. "Herren > Tops > T-Shirts" is tshirt
+ALTER TABLE products ADD COLUMN IF NOT EXISTS category_path TEXT;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS category_code VARCHAR(30);
+
+CREATE INDEX IF NOT EXISTS idx_products_category_code ON products(category_code) WHERE category_code IS NOT NULL;
+
+-- Mappings edited through the API, extending and overriding the built-in breadcrumb terms
+CREATE TABLE IF NOT EXISTS category_mappings (
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('breadcrumb', 'node')),
+    value VARCHAR(200) NOT NULL,
+    category VARCHAR(30) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (kind, value)
+);
+
+COMMENT ON COLUMN products.category_code IS 'Internal category such as tshirt, hoodie or pants; NULL if no mapping matched';
@@ -0,0 +1,4 @@
+-- Milliseconds spent per extraction stage of the last scrape, e.g. {"navigate": 2400, "size_table": 3100}
+ALTER TABLE products ADD COLUMN IF NOT EXISTS stage_timings JSONB;
+
+COMMENT ON COLUMN products.stage_timings IS 'Milliseconds per extraction stage of the last scrape; disabled stages are missing';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS review_summaries;
+DROP TABLE IF EXISTS product_reviews;
@@ -0,0 +1,22 @@
+-- Review texts of the last review extraction per product, the input of the review summary
+CREATE TABLE IF NOT EXISTS product_reviews (
+    asin VARCHAR(20) NOT NULL,
+    position INTEGER NOT NULL,
+    rating SMALLINT NOT NULL,
+    title TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL,
+    verified_buyer BOOLEAN NOT NULL DEFAULT FALSE,
+    review_date VARCHAR(200) NOT NULL DEFAULT '',
+    scraped_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (asin, position)
+);
+
+-- Fit summaries a language model derived from the stored reviews
+CREATE TABLE IF NOT EXISTS review_summaries (
+    asin VARCHAR(20) PRIMARY KEY,
+    fit_summary JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON COLUMN review_summaries.fit_summary IS 'runs_small_score (-1 runs large to 1 runs small), fabric_quality, tall_fit_notes, review_count, model';
@@ -0,0 +1,4 @@
+-- Source per basic field of the last scrape, e.g. {"title": "scraper", "price": "pa-api"}
+ALTER TABLE products ADD COLUMN IF NOT EXISTS data_sources JSONB;
+
+COMMENT ON COLUMN products.data_sources IS 'Source of title, brand, price and images: scraper, or pa-api when filled by the Product Advertising API fallback';
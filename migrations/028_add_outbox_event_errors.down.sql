@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_outbox_event_type_created;
+DROP TABLE IF EXISTS outbox_event_error;
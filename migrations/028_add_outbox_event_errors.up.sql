@@ -0,0 +1,13 @@
+-- One row per failed publish attempt of an outbox event, outbox_event.error_message keeps only the last
+CREATE TABLE IF NOT EXISTS outbox_event_error (
+    id BIGSERIAL PRIMARY KEY,
+    event_id UUID NOT NULL REFERENCES outbox_event(id) ON DELETE CASCADE,
+    attempt INT NOT NULL,
+    error_message TEXT NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_event_error_event ON outbox_event_error(event_id, attempt);
+CREATE INDEX IF NOT EXISTS idx_outbox_event_type_created ON outbox_event(event_type, created_at);
+
+COMMENT ON TABLE outbox_event_error IS 'Error history of outbox events, shown by GET /api/v1/outbox/events/{id}';
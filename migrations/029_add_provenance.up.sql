@@ -0,0 +1,4 @@
+-- Origin per major field, e.g. {"size_table": {"source": "ocr", "detail": "ocr", "extracted_at": "..."}}
+ALTER TABLE products ADD COLUMN IF NOT EXISTS provenance JSONB;
+
+COMMENT ON COLUMN products.provenance IS 'Extraction source and time of price, size_table and material: structured_table, ocr, description_text, regex, page or pa-api';
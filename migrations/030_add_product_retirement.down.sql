@@ -0,0 +1,2 @@
+UPDATE products SET status = 'failed' WHERE status = 'retired';
+ALTER TABLE products DROP COLUMN IF EXISTS retired_at;
@@ -0,0 +1,5 @@
+-- Products whose listing was deleted on Amazon (404/410 or the "not a functioning page" dog page) get
+-- status 'retired' and are never claimed again
+ALTER TABLE products ADD COLUMN IF NOT EXISTS retired_at TIMESTAMP;
+
+COMMENT ON COLUMN products.retired_at IS 'When the listing was found deleted and the product retired';
@@ -0,0 +1,11 @@
+-- Spellings of a brand mapped to its canonical name, edited through the API. alias_key is the folded
+-- alias (lower case letters and digits) the lookup matches on.
+CREATE TABLE IF NOT EXISTS brand_aliases (
+    alias_key VARCHAR(200) PRIMARY KEY,
+    alias VARCHAR(200) NOT NULL,
+    brand VARCHAR(200) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_brand_aliases_brand ON brand_aliases(brand);
@@ -0,0 +1,42 @@
+-- Previous values of a product, appended by a trigger whenever an update changes the title, price,
+-- size prices, status or size table
+CREATE TABLE IF NOT EXISTS products_history (
+    id BIGSERIAL PRIMARY KEY,
+    asin VARCHAR(20) NOT NULL REFERENCES products(asin) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    price DECIMAL(10,2),
+    size_prices JSONB,
+    status VARCHAR(20) NOT NULL,
+    size_table JSONB,
+    changed_fields TEXT[] NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_products_history_asin ON products_history(asin, changed_at);
+
+COMMENT ON COLUMN products_history.changed_fields IS 'Fields the update replaced: title, price, size_prices, status, size_table';
+COMMENT ON COLUMN products_history.changed_at IS 'When these values were replaced, the end of their validity';
+
+-- The row is read as JSON so the trigger does not depend on optional columns such as current_price
+CREATE OR REPLACE FUNCTION record_product_history()
+RETURNS TRIGGER AS $$
+DECLARE
+    old_row JSONB := to_jsonb(OLD);
+    new_row JSONB := to_jsonb(NEW);
+    changed TEXT[];
+BEGIN
+    SELECT COALESCE(array_agg(field), '{}') INTO changed
+    FROM unnest(ARRAY['title', 'current_price', 'size_prices', 'status', 'size_table']) AS field
+    WHERE old_row -> field IS DISTINCT FROM new_row -> field;
+
+    IF cardinality(changed) > 0 THEN
+        INSERT INTO products_history (asin, title, price, size_prices, status, size_table, changed_fields)
+        VALUES (OLD.asin, OLD.title, (old_row ->> 'current_price')::DECIMAL, OLD.size_prices, OLD.status,
+                OLD.size_table, array_replace(changed, 'current_price', 'price'));
+    END IF;
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER record_products_history AFTER UPDATE
+    ON products FOR EACH ROW EXECUTE FUNCTION record_product_history();
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_scraper_jobs_dedup_key;
+ALTER TABLE scraper_jobs DROP COLUMN IF EXISTS dedup_key;
@@ -0,0 +1,7 @@
+-- Search jobs are keyed by a hash of marketplace, query and category, a job created while an identical
+-- one is recent and not failed returns that job instead of crawling the search again
+ALTER TABLE scraper_jobs ADD COLUMN IF NOT EXISTS dedup_key VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_scraper_jobs_dedup_key ON scraper_jobs(dedup_key, created_at DESC) WHERE dedup_key IS NOT NULL;
+
+COMMENT ON COLUMN scraper_jobs.dedup_key IS 'SHA-256 of marketplace, search query and category, NULL for imports';
@@ -0,0 +1,7 @@
+-- Attributes of the product overview grid (Material, Passform, Ärmeltyp, ...) merged with "Label: value"
+-- feature bullets, by canonical key, e.g. {"material": "100% Baumwolle", "fit": "Regular Fit"}
+ALTER TABLE products ADD COLUMN IF NOT EXISTS attributes JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_products_attributes ON products USING GIN (attributes jsonb_path_ops);
+
+COMMENT ON COLUMN products.attributes IS 'Product overview attributes by canonical key: material, fit, sleeve_type, collar_style, neckline, ...';
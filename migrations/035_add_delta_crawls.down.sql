@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS template_crawl_frontier;
+ALTER TABLE scraper_jobs DROP COLUMN IF EXISTS delta_threshold;
+ALTER TABLE scraper_jobs DROP COLUMN IF EXISTS delta;
+ALTER TABLE job_templates DROP COLUMN IF EXISTS delta_threshold;
+ALTER TABLE job_templates DROP COLUMN IF EXISTS delta;
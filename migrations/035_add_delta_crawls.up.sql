@@ -0,0 +1,20 @@
+-- Delta crawls sort a saved search by newest listings and stop at the first page that lists mostly
+-- known products
+ALTER TABLE job_templates ADD COLUMN IF NOT EXISTS delta BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE job_templates ADD COLUMN IF NOT EXISTS delta_threshold REAL NOT NULL DEFAULT 0.8;
+ALTER TABLE scraper_jobs ADD COLUMN IF NOT EXISTS delta BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE scraper_jobs ADD COLUMN IF NOT EXISTS delta_threshold REAL NOT NULL DEFAULT 0.8;
+
+-- Crawl frontier of each saved search, the ASINs its delta crawls listed count as known next time
+CREATE TABLE IF NOT EXISTS template_crawl_frontier (
+    template_id UUID PRIMARY KEY REFERENCES job_templates(id) ON DELETE CASCADE,
+    asins TEXT[] NOT NULL DEFAULT '{}',
+    pages_crawled INT NOT NULL DEFAULT 0,
+    stop_page INT,
+    known_share REAL,
+    job_id UUID,
+    crawled_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON COLUMN job_templates.delta_threshold IS 'Share of known ASINs on a result page that ends a delta crawl';
+COMMENT ON TABLE template_crawl_frontier IS 'ASINs listed by the delta crawls of a template, newest first, and where the last one stopped';
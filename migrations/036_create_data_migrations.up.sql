@@ -0,0 +1,14 @@
+-- Progress of resumable data migrations run by the CLI, e.g. the conversion of legacy product rows.
+-- The watermark is the last ASIN a run committed, an interrupted run continues after it.
+CREATE TABLE IF NOT EXISTS data_migrations (
+    name VARCHAR(64) PRIMARY KEY,
+    watermark VARCHAR(20) NOT NULL DEFAULT '',
+    migrated INT NOT NULL DEFAULT 0,
+    skipped INT NOT NULL DEFAULT 0,
+    events INT NOT NULL DEFAULT 0,
+    started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP
+);
+
+COMMENT ON TABLE data_migrations IS 'Watermark and counts of resumable data migrations such as legacy_products';
@@ -0,0 +1,4 @@
+UPDATE scraper_jobs SET status = 'failed', error = COALESCE(error, 'cancelled') WHERE status = 'cancelled';
+ALTER TABLE scraper_jobs DROP CONSTRAINT IF EXISTS scraper_jobs_status_check;
+ALTER TABLE scraper_jobs ADD CONSTRAINT scraper_jobs_status_check
+    CHECK (status IN ('pending', 'running', 'completed', 'failed'));
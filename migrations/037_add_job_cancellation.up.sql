@@ -0,0 +1,4 @@
+-- Jobs can be cancelled through the control stream, a running job stops at its next heartbeat
+ALTER TABLE scraper_jobs DROP CONSTRAINT IF EXISTS scraper_jobs_status_check;
+ALTER TABLE scraper_jobs ADD CONSTRAINT scraper_jobs_status_check
+    CHECK (status IN ('pending', 'running', 'completed', 'failed', 'cancelled'));
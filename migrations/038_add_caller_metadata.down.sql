@@ -0,0 +1,4 @@
+ALTER TABLE outbox_event DROP COLUMN IF EXISTS metadata;
+ALTER TABLE products DROP COLUMN IF EXISTS metadata;
+ALTER TABLE job_products DROP COLUMN IF EXISTS metadata;
+ALTER TABLE scraper_jobs DROP COLUMN IF EXISTS metadata;
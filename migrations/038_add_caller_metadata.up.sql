@@ -0,0 +1,9 @@
+-- Key/value metadata of the caller, e.g. a campaign ID, passed from jobs and single-ASIN requests through
+-- the products they store into the metadata of the events they emit
+ALTER TABLE scraper_jobs ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE job_products ADD COLUMN IF NOT EXISTS metadata JSONB;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS metadata JSONB;
+ALTER TABLE outbox_event ADD COLUMN IF NOT EXISTS metadata JSONB;
+
+COMMENT ON COLUMN job_products.metadata IS 'Metadata of the job when the product was stored';
+COMMENT ON COLUMN products.metadata IS 'Metadata of all jobs and requests that stored the product, later ones win per key';
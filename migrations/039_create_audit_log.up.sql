@@ -0,0 +1,22 @@
+-- Admin actions taken through the API, e.g. cancelled jobs, manual imports and outbox replays. Rows are
+-- only inserted, never updated.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    actor VARCHAR(64) NOT NULL,
+    action VARCHAR(64) NOT NULL,
+    resource_type VARCHAR(32) NOT NULL,
+    resource_id TEXT NOT NULL DEFAULT '',
+    method VARCHAR(10) NOT NULL,
+    path TEXT NOT NULL,
+    status INT NOT NULL,
+    request_id VARCHAR(64),
+    trace_id VARCHAR(64),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_resource ON audit_log(resource_type, resource_id);
+
+COMMENT ON TABLE audit_log IS 'Who took which admin action on which resource, written by the API audit middleware';
+COMMENT ON COLUMN audit_log.actor IS 'admin for the admin token, api_key:<hash prefix> for API keys, anonymous without either';
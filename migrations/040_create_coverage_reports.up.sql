@@ -0,0 +1,19 @@
+-- Size chart coverage of product samples, one row per analytics job, so coverage can be tracked over time
+CREATE TABLE IF NOT EXISTS coverage_reports (
+    id UUID PRIMARY KEY,
+    job_id UUID REFERENCES scraper_jobs(id) ON DELETE SET NULL,
+    category VARCHAR(32) NOT NULL DEFAULT '',
+    sample_size INT NOT NULL,
+    sampled INT NOT NULL DEFAULT 0,
+    with_size_table INT NOT NULL DEFAULT 0,
+    with_length INT NOT NULL DEFAULT 0,
+    with_width INT NOT NULL DEFAULT 0,
+    complete INT NOT NULL DEFAULT 0,
+    measurement_types JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_coverage_reports_category ON coverage_reports(category, created_at DESC);
+
+COMMENT ON TABLE coverage_reports IS 'Size table presence and measurement coverage of random product samples per category code';
+COMMENT ON COLUMN coverage_reports.category IS 'Category code of the sample, empty for all categories';
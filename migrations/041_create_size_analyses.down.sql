@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS size_analysis_results;
+DROP TABLE IF EXISTS size_analyses;
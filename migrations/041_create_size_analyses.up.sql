@@ -0,0 +1,40 @@
+-- Size table analyses of live search results, one row per analysis job with the counts per status
+CREATE TABLE IF NOT EXISTS size_analyses (
+    id UUID PRIMARY KEY,
+    job_id UUID REFERENCES scraper_jobs(id) ON DELETE SET NULL,
+    search_url TEXT NOT NULL,
+    sample_size INT NOT NULL,
+    total INT NOT NULL DEFAULT 0,
+    complete INT NOT NULL DEFAULT 0,
+    missing_length INT NOT NULL DEFAULT 0,
+    missing_width INT NOT NULL DEFAULT 0,
+    missing_both INT NOT NULL DEFAULT 0,
+    no_table INT NOT NULL DEFAULT 0,
+    errors INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_size_analyses_job ON size_analyses(job_id);
+CREATE INDEX IF NOT EXISTS idx_size_analyses_created ON size_analyses(created_at DESC);
+
+-- The analyzed products of an analysis
+CREATE TABLE IF NOT EXISTS size_analysis_results (
+    analysis_id UUID NOT NULL REFERENCES size_analyses(id) ON DELETE CASCADE,
+    asin VARCHAR(20) NOT NULL,
+    title TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL,
+    has_table BOOLEAN NOT NULL DEFAULT FALSE,
+    has_length BOOLEAN NOT NULL DEFAULT FALSE,
+    has_width BOOLEAN NOT NULL DEFAULT FALSE,
+    measurements TEXT[] NOT NULL DEFAULT '{}',
+    source VARCHAR(32) NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    analyzed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (analysis_id, asin)
+);
+
+CREATE INDEX IF NOT EXISTS idx_size_analysis_results_status ON size_analysis_results(analysis_id, status);
+
+COMMENT ON TABLE size_analyses IS 'Size table analyses of search results: products with length and width, missing measurements or no table';
+COMMENT ON COLUMN size_analysis_results.status IS 'complete, missing_length, missing_width, missing_both, no_table or error';
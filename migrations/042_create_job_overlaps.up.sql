@@ -0,0 +1,12 @@
+-- Cached ASIN overlap of two finished jobs, whose products no longer change
+CREATE TABLE IF NOT EXISTS job_overlaps (
+    left_job_id UUID NOT NULL REFERENCES scraper_jobs(id) ON DELETE CASCADE,
+    right_job_id UUID NOT NULL REFERENCES scraper_jobs(id) ON DELETE CASCADE,
+    result JSONB NOT NULL,
+    computed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (left_job_id, right_job_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_overlaps_right ON job_overlaps(right_job_id);
+
+COMMENT ON TABLE job_overlaps IS 'Shared and unique ASINs with rank differences of two finished jobs, computed once per pair';
@@ -0,0 +1,4 @@
+-- Measurement policy a product's size table was accepted under, set by the worker and the lifecycle consumer
+ALTER TABLE products ADD COLUMN IF NOT EXISTS measurement_policy JSONB;
+
+COMMENT ON COLUMN products.measurement_policy IS 'Required measurements, whether partial tables were accepted and whether the size table satisfied the requirements';
@@ -0,0 +1,4 @@
+-- Remove retry columns from products table
+ALTER TABLE products
+DROP COLUMN IF EXISTS next_attempt_at,
+DROP COLUMN IF EXISTS scrape_attempts;
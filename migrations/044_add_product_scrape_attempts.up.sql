@@ -0,0 +1,8 @@
+-- Failed scrapes are retried with backoff instead of being claimed again right away
+ALTER TABLE products
+ADD COLUMN IF NOT EXISTS scrape_attempts INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP;
+
+-- Add comments
+COMMENT ON COLUMN products.scrape_attempts IS 'Failed scrapes of the product, it is marked failed once the worker limit is reached';
+COMMENT ON COLUMN products.next_attempt_at IS 'Pending products are not claimed before this time, NULL claims them right away';
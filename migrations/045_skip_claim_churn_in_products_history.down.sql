@@ -0,0 +1,20 @@
+-- Record claims of the size scraper again, as created by migration 032
+CREATE OR REPLACE FUNCTION record_product_history()
+RETURNS TRIGGER AS $$
+DECLARE
+    old_row JSONB := to_jsonb(OLD);
+    new_row JSONB := to_jsonb(NEW);
+    changed TEXT[];
+BEGIN
+    SELECT COALESCE(array_agg(field), '{}') INTO changed
+    FROM unnest(ARRAY['title', 'current_price', 'size_prices', 'status', 'size_table']) AS field
+    WHERE old_row -> field IS DISTINCT FROM new_row -> field;
+
+    IF cardinality(changed) > 0 THEN
+        INSERT INTO products_history (asin, title, price, size_prices, status, size_table, changed_fields)
+        VALUES (OLD.asin, OLD.title, (old_row ->> 'current_price')::DECIMAL, OLD.size_prices, OLD.status,
+                OLD.size_table, array_replace(changed, 'current_price', 'price'));
+    END IF;
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
@@ -0,0 +1,24 @@
+-- Claims of the size scraper move a product from pending to processing and back without changing it.
+-- Processing is compared and recorded as pending, so claims, releases and retries add no versions and
+-- the version a worker completes is recorded with the status it had before the claim.
+CREATE OR REPLACE FUNCTION record_product_history()
+RETURNS TRIGGER AS $$
+DECLARE
+    old_status TEXT := CASE WHEN OLD.status = 'processing' THEN 'pending' ELSE OLD.status END;
+    new_status TEXT := CASE WHEN NEW.status = 'processing' THEN 'pending' ELSE NEW.status END;
+    old_row JSONB := to_jsonb(OLD) || jsonb_build_object('status', old_status);
+    new_row JSONB := to_jsonb(NEW) || jsonb_build_object('status', new_status);
+    changed TEXT[];
+BEGIN
+    SELECT COALESCE(array_agg(field), '{}') INTO changed
+    FROM unnest(ARRAY['title', 'current_price', 'size_prices', 'status', 'size_table']) AS field
+    WHERE old_row -> field IS DISTINCT FROM new_row -> field;
+
+    IF cardinality(changed) > 0 THEN
+        INSERT INTO products_history (asin, title, price, size_prices, status, size_table, changed_fields)
+        VALUES (OLD.asin, OLD.title, (old_row ->> 'current_price')::DECIMAL, OLD.size_prices, old_status,
+                OLD.size_table, array_replace(changed, 'current_price', 'price'));
+    END IF;
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
@@ -0,0 +1,11 @@
+-- Rows are derived from products.size_table, rows whose labels do not fit are dropped instead of truncated
+DELETE FROM size_measurements WHERE length(size_label) > 50 OR length(canonical_size) > 20;
+DELETE FROM size_conversions WHERE length(size_label) > 50 OR length(canonical_size) > 20;
+
+ALTER TABLE size_measurements
+ALTER COLUMN size_label TYPE VARCHAR(50),
+ALTER COLUMN canonical_size TYPE VARCHAR(20);
+
+ALTER TABLE size_conversions
+ALTER COLUMN size_label TYPE VARCHAR(50),
+ALTER COLUMN canonical_size TYPE VARCHAR(20);
@@ -0,0 +1,10 @@
+-- Size labels are stored as they appear in the size chart, and canonical sizes of labels that are not
+-- recognized are the label itself. A label longer than the columns failed the insert and lost the
+-- measurements of the whole product.
+ALTER TABLE size_measurements
+ALTER COLUMN size_label TYPE TEXT,
+ALTER COLUMN canonical_size TYPE TEXT;
+
+ALTER TABLE size_conversions
+ALTER COLUMN size_label TYPE TEXT,
+ALTER COLUMN canonical_size TYPE TEXT;
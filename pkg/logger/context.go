@@ -0,0 +1,130 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+)
+
+// Correlation keys added to every record logged with a context carrying them
+const (
+	KeyRequestID = "request_id"
+	KeyJobID     = "job_id"
+	KeyASIN      = "asin"
+	KeyTraceID   = "trace_id"
+)
+
+type contextKey struct{}
+
+// WithAttrs returns a context whose log records carry attrs, replacing earlier values of the same keys
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	parent := Attrs(ctx)
+	merged := make([]slog.Attr, 0, len(parent)+len(attrs))
+	for _, a := range parent {
+		if !hasKey(attrs, a.Key) {
+			merged = append(merged, a)
+		}
+	}
+	merged = append(merged, attrs...)
+	return context.WithValue(ctx, contextKey{}, merged)
+}
+
+// WithRequestID correlates log records with an HTTP request
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return WithAttrs(ctx, slog.String(KeyRequestID, id))
+}
+
+// WithJobID correlates log records with a scraper job
+func WithJobID(ctx context.Context, id string) context.Context {
+	return WithAttrs(ctx, slog.String(KeyJobID, id))
+}
+
+// WithASIN correlates log records with a product
+func WithASIN(ctx context.Context, asin string) context.Context {
+	return WithAttrs(ctx, slog.String(KeyASIN, asin))
+}
+
+// WithTraceID correlates log records across services, from the HTTP request to the lifecycle consumer
+func WithTraceID(ctx context.Context, id string) context.Context {
+	return WithAttrs(ctx, slog.String(KeyTraceID, id))
+}
+
+// Attrs returns the correlation attributes of ctx
+func Attrs(ctx context.Context) []slog.Attr {
+	attrs, _ := ctx.Value(contextKey{}).([]slog.Attr)
+	return attrs
+}
+
+// TraceID returns the trace ID of ctx, or "" when there is none
+func TraceID(ctx context.Context) string {
+	for _, a := range Attrs(ctx) {
+		if a.Key == KeyTraceID {
+			return a.Value.String()
+		}
+	}
+	return ""
+}
+
+// EnsureTraceID returns ctx with a new trace ID unless it already carries one
+func EnsureTraceID(ctx context.Context) context.Context {
+	if TraceID(ctx) != "" {
+		return ctx
+	}
+	return WithTraceID(ctx, NewTraceID())
+}
+
+// NewTraceID returns a random 32 hex digit trace ID as used by W3C traceparent
+func NewTraceID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ContextHandler adds the correlation attributes of the record's context to each record.
+// Attributes the call already passes, e.g. an explicit "asin", are not duplicated.
+type ContextHandler struct {
+	slog.Handler
+}
+
+// NewContextHandler wraps h with correlation attributes from the context
+func NewContextHandler(h slog.Handler) *ContextHandler {
+	return &ContextHandler{Handler: h}
+}
+
+// Handle implements slog.Handler
+func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	attrs := Attrs(ctx)
+	if len(attrs) > 0 {
+		present := make(map[string]bool, r.NumAttrs())
+		r.Attrs(func(a slog.Attr) bool {
+			present[a.Key] = true
+			return true
+		})
+		for _, a := range attrs {
+			if !present[a.Key] {
+				r.AddAttrs(a)
+			}
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs implements slog.Handler
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup implements slog.Handler
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+func hasKey(attrs []slog.Attr, key string) bool {
+	for _, a := range attrs {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
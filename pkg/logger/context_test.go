@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+func TestContextHandler(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(NewContextHandler(slog.NewJSONHandler(&buf, nil)))
+
+	ctx := WithJobID(WithTraceID(context.Background(), "trace-1"), "job-1")
+	ctx = WithASIN(WithASIN(ctx, "B000000001"), "B000000002")
+	log.InfoContext(ctx, "scraping", "asin", "B000000003")
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("invalid JSON record %q: %v", buf.String(), err)
+	}
+	if record[KeyTraceID] != "trace-1" || record[KeyJobID] != "job-1" {
+		t.Errorf("correlation IDs missing: %v", record)
+	}
+	// The explicit attribute wins and is not duplicated
+	if record[KeyASIN] != "B000000003" || bytes.Count(buf.Bytes(), []byte(`"asin"`)) != 1 {
+		t.Errorf("asin = %v in %s", record[KeyASIN], buf.String())
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(NewContextHandler(slog.NewJSONHandler(&buf, nil)))
+
+	var traceID string
+	handler := middleware.RequestID(Middleware(log)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceID = TraceID(r.Context())
+	})))
+
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if traceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace ID = %q", traceID)
+	}
+	if rec.Header().Get(TraceHeader) != traceID || rec.Header().Get(middleware.RequestIDHeader) == "" {
+		t.Errorf("response headers = %v", rec.Header())
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"request_id"`)) {
+		t.Errorf("request log without request_id: %s", buf.String())
+	}
+}
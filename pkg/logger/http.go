@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// TraceHeader carries the trace ID between services, W3C traceparent is accepted as well
+const TraceHeader = "X-Trace-ID"
+
+// Middleware adds the request ID set by chi's middleware.RequestID and a trace ID, taken from the
+// X-Trace-ID or traceparent header or newly created, to the request context and logs every request
+// with them. Both IDs are echoed in the response headers.
+func Middleware(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			traceID := traceIDFromRequest(r)
+			if traceID == "" {
+				traceID = NewTraceID()
+			}
+
+			ctx := WithTraceID(r.Context(), traceID)
+			if requestID := middleware.GetReqID(ctx); requestID != "" {
+				ctx = WithRequestID(ctx, requestID)
+				w.Header().Set(middleware.RequestIDHeader, requestID)
+			}
+			w.Header().Set(TraceHeader, traceID)
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			start := time.Now()
+			next.ServeHTTP(ww, r.WithContext(ctx))
+
+			logger.InfoContext(ctx, "http request",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", ww.Status(),
+				"bytes", ww.BytesWritten(),
+				"duration", time.Since(start),
+				"remote", r.RemoteAddr)
+		})
+	}
+}
+
+// traceIDFromRequest returns the trace ID of X-Trace-ID or the trace-id field of traceparent
+func traceIDFromRequest(r *http.Request) string {
+	if id := r.Header.Get(TraceHeader); id != "" {
+		return id
+	}
+	// traceparent: version-traceid-parentid-flags
+	if parts := strings.Split(r.Header.Get("traceparent"), "-"); len(parts) == 4 && len(parts[1]) == 32 {
+		return parts[1]
+	}
+	return ""
+}
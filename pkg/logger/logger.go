@@ -6,6 +6,7 @@ import (
 	"strings"
 )
 
+// New creates a logger writing JSON or text records that carry the correlation IDs of their context
 func New(level, format string) *slog.Logger {
 	var logLevel slog.Level
 	switch strings.ToLower(level) {
@@ -36,7 +37,7 @@ func New(level, format string) *slog.Logger {
 		handler = slog.NewJSONHandler(os.Stdout, opts)
 	}
 	
-	return slog.New(handler)
+	return slog.New(NewContextHandler(handler))
 }
 
 func NewWithDefaults() *slog.Logger {